@@ -52,13 +52,21 @@ GOTO REGISTRY
     GOTO SKIP
 
 :MAIN
+{{- if .DockerContext }}
+:: use the docker context that was in use when the scripts were generated, unless another daemon is chosen
+IF "%CONTAINER_RUNTIME%"=="docker" IF "%DOCKER_CONTEXT%"=="" IF "%DOCKER_HOST%"=="" SET DOCKER_CONTEXT={{ .DockerContext }}
+{{- end }}
+{{- if .PodmanConnection }}
+:: use the podman connection that was in use when the scripts were generated, unless another one is chosen
+IF "%CONTAINER_RUNTIME%"=="podman" IF "%CONTAINER_CONNECTION%"=="" IF "%CONTAINER_HOST%"=="" SET CONTAINER_CONNECTION={{ .PodmanConnection }}
+{{- end }}
 :: Uncomment the below line if you want to enable login before pushing
 :: %CONTAINER_RUNTIME% login %REGISTRY_URL%
 {{- range $image := .Images }}
 
 echo "pushing image {{ $image }}"
-%CONTAINER_RUNTIME% tag {{ $image }} %REGISTRY_URL%/%REGISTRY_NAMESPACE%/{{ $image }}
-%CONTAINER_RUNTIME% push %REGISTRY_URL%/%REGISTRY_NAMESPACE%/{{ $image }}
+%CONTAINER_RUNTIME% tag {{ $image }} %REGISTRY_URL%/%REGISTRY_NAMESPACE%/{{ index $.PushImageNames $image }}
+%CONTAINER_RUNTIME% push %REGISTRY_URL%/%REGISTRY_NAMESPACE%/{{ index $.PushImageNames $image }}
 {{- end }}
 
 echo "done"
@@ -33,13 +33,25 @@ if [ "${CONTAINER_RUNTIME}" != "docker" ] && [ "${CONTAINER_RUNTIME}" != "podman
    echo 'Unsupported container runtime passed as an argument for pushing the images: '"${CONTAINER_RUNTIME}"
    exit 1
 fi
+{{- if .DockerContext }}
+# use the docker context that was in use when the scripts were generated, unless another daemon is chosen
+if [ "${CONTAINER_RUNTIME}" == "docker" ] && [ -z "${DOCKER_CONTEXT}" ] && [ -z "${DOCKER_HOST}" ]; then
+  export DOCKER_CONTEXT={{ .DockerContext }}
+fi
+{{- end }}
+{{- if .PodmanConnection }}
+# use the podman connection that was in use when the scripts were generated, unless another one is chosen
+if [ "${CONTAINER_RUNTIME}" == "podman" ] && [ -z "${CONTAINER_CONNECTION}" ] && [ -z "${CONTAINER_HOST}" ]; then
+  export CONTAINER_CONNECTION={{ .PodmanConnection }}
+fi
+{{- end }}
 # Uncomment the below line if you want to enable login before pushing
 # ${CONTAINER_RUNTIME} login ${REGISTRY_URL}
 {{- range $image := .Images }}
 
 echo 'pushing image {{ $image }}'
-${CONTAINER_RUNTIME} tag {{ $image }} ${REGISTRY_URL}/${REGISTRY_NAMESPACE}/{{ $image }}
-${CONTAINER_RUNTIME} push ${REGISTRY_URL}/${REGISTRY_NAMESPACE}/{{ $image }}
+${CONTAINER_RUNTIME} tag {{ $image }} ${REGISTRY_URL}/${REGISTRY_NAMESPACE}/{{ index $.PushImageNames $image }}
+${CONTAINER_RUNTIME} push ${REGISTRY_URL}/${REGISTRY_NAMESPACE}/{{ index $.PushImageNames $image }}
 {{- end }}
 
 echo 'done'
@@ -50,13 +50,17 @@ GOTO REGISTRY
 	GOTO MAIN
 
 :MAIN
+{{- if .DockerContext }}
+:: use the docker context that was in use when the scripts were generated, unless another daemon is chosen
+IF "%DOCKER_CONTEXT%"=="" IF "%DOCKER_HOST%"=="" SET DOCKER_CONTEXT={{ .DockerContext }}
+{{- end }}
 :: Uncomment the below line if you want to enable login before pushing
 :: docker login %REGISTRY_URL%
 {{- range $dockerfile := .DockerfilesConfig }}
 
 echo "building and pushing image {{ $dockerfile.ImageName }}"
 pushd {{ $dockerfile.ContextWindows }}
-docker buildx build --platform ${PLATFORMS} -f {{ $dockerfile.DockerfileName }} --push --tag ${REGISTRY_URL}/${REGISTRY_NAMESPACE}/{{ $dockerfile.ImageName }} .
+docker buildx build --platform ${PLATFORMS} -f {{ $dockerfile.DockerfileName }}{{ $dockerfile.BuildOptionsWindows }} --push --tag ${REGISTRY_URL}/${REGISTRY_NAMESPACE}/{{ $dockerfile.PushImageName }} .
 popd
 {{- end }}
 
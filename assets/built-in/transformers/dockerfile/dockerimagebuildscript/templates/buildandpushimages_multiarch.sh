@@ -23,6 +23,12 @@ if [[ "$(basename "$PWD")" != 'scripts' ]] ; then
   echo 'please run this script from the "scripts" directory'
   exit 1
 fi
+{{- if .DockerContext }}
+# use the docker context that was in use when the scripts were generated, unless another daemon is chosen
+if [ -z "${DOCKER_CONTEXT}" ] && [ -z "${DOCKER_HOST}" ]; then
+  export DOCKER_CONTEXT={{ .DockerContext }}
+fi
+{{- end }}
 
 cd {{ .RelParentOfSourceDir }} # go to the parent directory so that all the relative paths will be correct
 
@@ -42,7 +48,7 @@ fi
 
 echo 'building and pushing image {{ $dockerfile.ImageName }}'
 cd {{ $dockerfile.ContextUnix }}
-docker buildx build --platform ${PLATFORMS} -f {{ $dockerfile.DockerfileName }}  --push --tag ${REGISTRY_URL}/${REGISTRY_NAMESPACE}/{{ $dockerfile.ImageName }} .
+docker buildx build --platform ${PLATFORMS} -f {{ $dockerfile.DockerfileName }}{{ $dockerfile.BuildOptionsUnix }} --push --tag ${REGISTRY_URL}/${REGISTRY_NAMESPACE}/{{ $dockerfile.PushImageName }} .
 cd -
 {{- end }}
 
@@ -46,6 +46,14 @@ GOTO DOCKER_CONTAINER_RUNTIME
     GOTO SKIP
 
 :MAIN
+{{- if .DockerContext }}
+:: use the docker context that was in use when the scripts were generated, unless another daemon is chosen
+IF "%CONTAINER_RUNTIME%"=="docker" IF "%DOCKER_CONTEXT%"=="" IF "%DOCKER_HOST%"=="" SET DOCKER_CONTEXT={{ .DockerContext }}
+{{- end }}
+{{- if .PodmanConnection }}
+:: use the podman connection that was in use when the scripts were generated, unless another one is chosen
+IF "%CONTAINER_RUNTIME%"=="podman" IF "%CONTAINER_CONNECTION%"=="" IF "%CONTAINER_HOST%"=="" SET CONTAINER_CONNECTION={{ .PodmanConnection }}
+{{- end }}
 REM go to the parent directory so that all the relative paths will be correct
 cd {{ .RelParentOfSourceDir }}
 
@@ -53,7 +61,7 @@ cd {{ .RelParentOfSourceDir }}
 
 echo "building image {{ $dockerfile.ImageName }}"
 pushd {{ $dockerfile.ContextWindows }}
-%CONTAINER_RUNTIME% build -f {{ $dockerfile.DockerfileName }} -t {{ $dockerfile.ImageName }} .
+%CONTAINER_RUNTIME% build -f {{ $dockerfile.DockerfileName }}{{ $dockerfile.BuildOptionsWindows }} -t {{ $dockerfile.ImageName }} .
 popd
 {{- end }}
 
@@ -30,13 +30,25 @@ if [ "${CONTAINER_RUNTIME}" != "docker" ] && [ "${CONTAINER_RUNTIME}" != "podman
    echo 'Unsupported container runtime passed as an argument for building the images: '"${CONTAINER_RUNTIME}"
    exit 1
 fi
+{{- if .DockerContext }}
+# use the docker context that was in use when the scripts were generated, unless another daemon is chosen
+if [ "${CONTAINER_RUNTIME}" == "docker" ] && [ -z "${DOCKER_CONTEXT}" ] && [ -z "${DOCKER_HOST}" ]; then
+  export DOCKER_CONTEXT={{ .DockerContext }}
+fi
+{{- end }}
+{{- if .PodmanConnection }}
+# use the podman connection that was in use when the scripts were generated, unless another one is chosen
+if [ "${CONTAINER_RUNTIME}" == "podman" ] && [ -z "${CONTAINER_CONNECTION}" ] && [ -z "${CONTAINER_HOST}" ]; then
+  export CONTAINER_CONNECTION={{ .PodmanConnection }}
+fi
+{{- end }}
 cd {{ .RelParentOfSourceDir }} # go to the parent directory so that all the relative paths will be correct
 
 {{- range $dockerfile := .DockerfilesConfig }}
 
 echo 'building image {{ $dockerfile.ImageName }}'
 cd {{ $dockerfile.ContextUnix }}
-${CONTAINER_RUNTIME} build -f {{ $dockerfile.DockerfileName }} -t {{ $dockerfile.ImageName }} .
+${CONTAINER_RUNTIME} build -f {{ $dockerfile.DockerfileName }}{{ $dockerfile.BuildOptionsUnix }} -t {{ $dockerfile.ImageName }} .
 cd -
 {{- end }}
 
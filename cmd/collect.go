@@ -21,17 +21,25 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/konveyor/move2kube/common"
 	"github.com/konveyor/move2kube/lib"
 	"github.com/konveyor/move2kube/types"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 type collectFlags struct {
-	annotations string
-	outpath     string
-	srcpath     string
+	annotations       string
+	outpath           string
+	srcpath           string
+	parallel          int
+	registryRateLimit float64
+	imageCacheDir     string
+	namespace         string
+	bundle            string
+	bundleKey         string
 }
 
 func collectHandler(flags collectFlags) {
@@ -59,13 +67,41 @@ func collectHandler(flags collectFlags) {
 			logrus.Fatalf("Source path is a file, expected '%s' to be a directory.", srcpath)
 		}
 	}
-	outpath = filepath.Join(filepath.Clean(outpath), types.AppNameShort+"_collect")
+	if flags.bundle != "" {
+		if flags.bundle, err = filepath.Abs(flags.bundle); err != nil {
+			logrus.Fatalf("Failed to make the bundle path '%s' absolute. Error: %q", flags.bundle, err)
+		}
+	}
+	if flags.parallel < 1 {
+		logrus.Fatalf("The number of images to collect the metadata of at the same time must be at least 1. Actual: %d", flags.parallel)
+	}
+	if flags.imageCacheDir == "" {
+		if userCacheDir, err := os.UserCacheDir(); err != nil {
+			logrus.Debugf("failed to get the user cache directory. Image metadata will not be cached. Error: %q", err)
+		} else {
+			flags.imageCacheDir = filepath.Join(userCacheDir, types.AppName, "images")
+		}
+	} else if flags.imageCacheDir, err = filepath.Abs(flags.imageCacheDir); err != nil {
+		logrus.Fatalf("Failed to make the image cache directory path '%s' absolute. Error: %q", flags.imageCacheDir, err)
+	}
+	// Global settings
+	common.MaxParallelImageLookups = flags.parallel
+	common.RegistryRequestsPerSecond = flags.registryRateLimit
+	common.ImageMetadataCacheDir = flags.imageCacheDir
+	common.CollectNamespace = flags.namespace
+	// Global settings
+	outpath = filepath.Join(filepath.Clean(outpath), collecttypes.CollectOutputDirName)
 	if annotations == "" {
 		lib.Collect(srcpath, outpath, []string{})
 	} else {
 		lib.Collect(srcpath, outpath, strings.Split(annotations, ","))
 	}
 	logrus.Infof("Collect Output in [%s]. Copy this directory into the source directory to be used for planning.", outpath)
+	if flags.bundle != "" {
+		if err := lib.ExportCollectBundle(outpath, flags.bundle, flags.bundleKey); err != nil {
+			logrus.Fatalf("failed to export the collect output. Error: %q", err)
+		}
+	}
 }
 
 // GetCollectCommand returns a command to collect information from running applications
@@ -80,9 +116,15 @@ func GetCollectCommand() *cobra.Command {
 		Run:   func(*cobra.Command, []string) { collectHandler(flags) },
 	}
 
-	collectCmd.Flags().StringVarP(&flags.annotations, "annotations", "a", "", "Specify annotations to select collector subset.")
+	collectCmd.Flags().StringVarP(&flags.annotations, "annotations", "a", "", "Specify annotations to select collector subset. The collectors talking to a local daemon, like the dockercontainers collector, only run when they are selected.")
 	collectCmd.Flags().StringVarP(&flags.outpath, outputFlag, "o", ".", "Specify output directory for collect.")
 	collectCmd.Flags().StringVarP(&flags.srcpath, sourceFlag, "s", "", "Specify source directory for the artifacts to be considered while collecting.")
+	collectCmd.Flags().IntVar(&flags.parallel, parallelFlag, common.MaxParallelImageLookups, "The maximum number of images to collect the metadata of at the same time.")
+	collectCmd.Flags().Float64Var(&flags.registryRateLimit, registryRateLimitFlag, common.RegistryRequestsPerSecond, "The maximum number of requests per second to a single image registry. 0 means no limit.")
+	collectCmd.Flags().StringVar(&flags.imageCacheDir, imageCacheDirFlag, "", "Specify a directory to cache the image metadata fetched from the registries in. By default the user cache directory is used.")
+	collectCmd.Flags().StringVar(&flags.namespace, namespaceFlag, "", "Specify a Kubernetes namespace to take a snapshot of. The Deployments, Services, ConfigMaps, Secrets and Ingresses in it are collected as Kubernetes yamls, which are transformed like the yamls in the source directory, and the resource usage of its pods is sampled.")
+	collectCmd.Flags().StringVar(&flags.bundle, bundleFlag, "", "Specify a path to export the collect output to as a portable bundle, to be used with the --collect-bundle flag of plan and transform on another machine.")
+	collectCmd.Flags().StringVar(&flags.bundleKey, bundleKeyFlag, "", "Specify an ed25519 private key in PEM format to sign the bundle with. If the file does not exist, a new key pair is generated.")
 
 	return collectCmd
 }
@@ -16,6 +16,12 @@
 
 package cmd
 
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
 const (
 	// sourceFlag is the name of the flag that contains path to the source folder
 	sourceFlag = "source"
@@ -25,8 +31,10 @@ const (
 	nameFlag = "name"
 	// planFlag is the name of the flag that contains the path to the plan file
 	planFlag = "plan"
-	// profileFlag is the name of the flag that contains the path where the CPU profile file should be generated
+	// profileFlag is the name of the flag that contains the type of profile to generate
 	profileFlag = "profile"
+	// profileOutputFlag is the name of the flag that contains the path where the profile file should be generated
+	profileOutputFlag = "profile-output"
 	// ignoreEnvFlag is the name of the flag that tells us whether to use data collected from the local machine
 	ignoreEnvFlag = "ignore-env"
 	// qaSkipFlag is the name of the flag that lets you skip all the question answers
@@ -56,8 +64,55 @@ const (
 	transformerSelectorFlag  = "transformer-selector"
 	qaEnabledCategoriesFlag  = "qa-enable"
 	qaDisabledCategoriesFlag = "qa-disable"
+	serverHostFlag           = "host"
+	serverPortFlag           = "port"
+	grpcPortFlag             = "grpc-port"
+	parallelFlag             = "parallel"
+	incrementalFlag          = "incremental"
+	planCacheDirFlag         = "plan-cache-dir"
+	planMaxDepthFlag         = "plan-max-depth"
+	planMaxSizeFlag          = "plan-max-size"
+	serviceNameMaxLengthFlag = "service-name-max-length"
+	symlinkPolicyFlag        = "symlink-policy"
+	maxEmbeddedFileSizeFlag  = "max-embedded-file-size"
+	workDirFlag              = "work-dir"
+	offlineFlag              = "offline"
+	remoteCacheDirFlag       = "remote-cache-dir"
+	remoteCacheTTLFlag       = "remote-cache-ttl"
+	registryRateLimitFlag    = "registry-rate-limit"
+	imageCacheDirFlag        = "image-cache-dir"
+	namespaceFlag            = "namespace"
+	bundleFlag               = "bundle"
+	bundleKeyFlag            = "bundle-key"
+	collectBundleFlag        = "collect-bundle"
+	collectBundleKeyFlag     = "collect-bundle-key"
+	allowUnsignedFlag        = "collect-bundle-allow-unsigned"
+	catalogFlag              = "catalog"
+	installDirFlag           = "install-dir"
+	disableProvenanceFlag    = "disable-provenance-annotations"
+	dumpIRFlag               = "dump-ir"
+	previewDiffsFlag         = "preview-diffs"
+	editFlag                 = "edit"
 )
 
+type remoteCacheFlags struct {
+	// offline reads remote customizations and sources only from the cache
+	offline bool
+	// remoteCacheDir is the directory where remote customizations and sources are cached
+	remoteCacheDir string
+	// remoteCacheTTL is the duration after which the cached remote content is fetched again
+	remoteCacheTTL time.Duration
+}
+
+type collectBundleFlags struct {
+	// collectBundle is the path to a bundle exported by the collect command
+	collectBundle string
+	// collectBundleKey is the path to the public key used to verify the signature of the collect bundle
+	collectBundleKey string
+	// collectBundleAllowUnsigned allows importing a collect bundle without verifying its signature
+	collectBundleAllowUnsigned bool
+}
+
 type qaflags struct {
 	// qadisablecli disables the CLI engine. To be used with HTTP REST engine
 	qadisablecli bool
@@ -78,3 +133,15 @@ type qaflags struct {
 	// persistPasswords sets whether to persist the password or not
 	persistPasswords bool
 }
+
+func addRemoteCacheFlags(cmd *cobra.Command, flags *remoteCacheFlags) {
+	cmd.Flags().BoolVar(&flags.offline, offlineFlag, false, "Do not access the network to fetch remote customizations and sources. Fails if they are not available in the cache.")
+	cmd.Flags().StringVar(&flags.remoteCacheDir, remoteCacheDirFlag, "", "Specify a directory to cache remote customizations and sources in. By default remote content is not cached.")
+	cmd.Flags().DurationVar(&flags.remoteCacheTTL, remoteCacheTTLFlag, 0, "The duration after which cached remote customizations and sources at branches, tags and other changing references are fetched again. By default only the content pinned to a commit hash or digest is cached.")
+}
+
+func addCollectBundleFlags(cmd *cobra.Command, flags *collectBundleFlags) {
+	cmd.Flags().StringVar(&flags.collectBundle, collectBundleFlag, "", "Specify a bundle exported by the collect command. Its contents are verified and extracted into the collect output directory of the source directory before planning.")
+	cmd.Flags().StringVar(&flags.collectBundleKey, collectBundleKeyFlag, "", "Specify the public key to verify the signature of the collect bundle with. Required, unless unsigned bundles are allowed.")
+	cmd.Flags().BoolVar(&flags.collectBundleAllowUnsigned, allowUnsignedFlag, false, "Allow importing the collect bundle without a public key. The bundle is then only checked for corruption, not for tampering.")
+}
@@ -45,6 +45,16 @@ type planFlags struct {
 	transformerSelector   string
 	disableLocalExecution bool
 	failOnEmptyPlan       bool
+	edit                  bool
+	planCacheDir          string
+	planMaxDepth          int
+	planMaxSize           int64
+	serviceNameMaxLength  int
+	symlinkPolicy         string
+	profile               string
+	profileOutput         string
+	remoteCacheFlags
+	collectBundleFlags
 	//Configs contains a list of config files
 	configs []string
 	//Configs contains a list of key-value configs
@@ -54,6 +64,7 @@ type planFlags struct {
 }
 
 func planHandler(cmd *cobra.Command, flags planFlags) {
+	defer startProfiling(flags.profile, flags.profileOutput)()
 	ctx, cancel := context.WithCancel(cmd.Context())
 	logrus.AddHook(common.NewCleanupHook(cancel))
 	logrus.AddHook(common.NewCleanupHook(lib.Destroy))
@@ -67,6 +78,7 @@ func planHandler(cmd *cobra.Command, flags planFlags) {
 	defer lib.Destroy()
 
 	vcs.SetMaxRepoCloneSize(flags.maxVCSRepoCloneSize)
+	setupRemoteCache(flags.remoteCacheFlags)
 
 	var err error
 	planfile := flags.planfile
@@ -105,6 +117,13 @@ func planHandler(cmd *cobra.Command, flags planFlags) {
 	customizationsPath := flags.customizationsPath
 	// Global settings
 	common.DisableLocalExecution = flags.disableLocalExecution
+	common.PlanCacheDir = flags.planCacheDir
+	common.PlanMaxDepth = flags.planMaxDepth
+	common.PlanMaxSizeBytes = flags.planMaxSize
+	common.ServiceNameMaxLength = flags.serviceNameMaxLength
+	if common.SymlinkPolicy, err = common.ParseSymlinkPolicy(flags.symlinkPolicy); err != nil {
+		logrus.Fatalf("invalid value for the --%s flag. Error: %q", symlinkPolicyFlag, err)
+	}
 	// Global settings
 
 	planfile, err = filepath.Abs(planfile)
@@ -137,6 +156,14 @@ func planHandler(cmd *cobra.Command, flags planFlags) {
 	} else if fi.IsDir() {
 		planfile = filepath.Join(planfile, common.DefaultPlanFile)
 	}
+	if flags.edit {
+		if _, err := os.Stat(planfile); err != nil {
+			logrus.Fatalf("failed to access the plan file to edit at path %s . Error: %q", planfile, err)
+		}
+		editPlan(planfile)
+		return
+	}
+	importCollectBundle(flags.collectBundleFlags, srcpath, isRemotePath)
 	qaengine.StartEngine(true, 0, true)
 	qaengine.SetupConfigFile("", flags.setconfigs, flags.configs, flags.preSets, false)
 	if flags.progressServerPort != 0 {
@@ -179,7 +206,7 @@ func GetPlanCommand() *cobra.Command {
 	planCmd.Flags().StringVarP(&flags.srcpath, sourceFlag, "s", "", "Specify source directory or a git url (see https://move2kube.konveyor.io/concepts/git-support).")
 	planCmd.Flags().StringVarP(&flags.planfile, planFlag, "p", common.DefaultPlanFile, "Specify a file path to save plan to.")
 	planCmd.Flags().StringVarP(&flags.name, nameFlag, "n", common.DefaultProjectName, "Specify the project name.")
-	planCmd.Flags().StringVarP(&flags.customizationsPath, customizationsFlag, "c", "", "Specify directory or a git url (see https://move2kube.konveyor.io/concepts/git-support) where customizations are stored. By default we look for "+common.DefaultCustomizationDir)
+	planCmd.Flags().StringVarP(&flags.customizationsPath, customizationsFlag, "c", "", "Specify directory, git url (see https://move2kube.konveyor.io/concepts/git-support), https url of a zip/tar(.gz) archive (optionally pinned using #sha256=<digest>) or OCI artifact oci://<registry>/<repo>[:<tag>|@<digest>] where customizations and external transformers are stored. Credentials are taken from the M2K_GIT_* env vars for the M2K_GIT_HOST host, the M2K_HTTP_TOKEN env var for the M2K_HTTP_HOST host, the ssh agent, the netrc file or the docker config. By default we look for "+common.DefaultCustomizationDir)
 	planCmd.Flags().StringSliceVarP(&flags.configs, configFlag, "f", []string{}, "Specify config file locations. By default we look for "+common.DefaultConfigFilePath)
 	planCmd.Flags().StringVarP(&flags.transformerSelector, transformerSelectorFlag, "t", "", "Specify the transformer selector.")
 	planCmd.Flags().StringSliceVar(&flags.preSets, preSetFlag, []string{}, "Specify preset config to use.")
@@ -187,8 +214,19 @@ func GetPlanCommand() *cobra.Command {
 	planCmd.Flags().IntVar(&flags.progressServerPort, planProgressPortFlag, 0, "Port for the plan progress server. If not provided, the server won't be started.")
 	planCmd.Flags().Int64Var(&flags.maxVCSRepoCloneSize, maxCloneSizeBytesFlag, -1, "Max size in bytes when cloning a git repo. Default -1 is infinite")
 	planCmd.Flags().BoolVar(&flags.disableLocalExecution, common.DisableLocalExecutionFlag, false, "Allow files to be executed locally.")
+	planCmd.Flags().BoolVar(&flags.edit, editFlag, false, "Edit the services, containerization options and transformers of an existing plan file in the terminal instead of creating a new plan.")
 	planCmd.Flags().BoolVar(&flags.failOnEmptyPlan, common.FailOnEmptyPlan, false, "If true, planning will exit with a failure exit code if no services are detected (and no default transformers are found).")
 
+	planCmd.Flags().StringVar(&flags.planCacheDir, planCacheDirFlag, "", "Specify a directory to cache the results of analyzing the source directory. Re-planning an unchanged source directory uses the cached results. Caching is disabled by default.")
+	planCmd.Flags().IntVar(&flags.planMaxDepth, planMaxDepthFlag, -1, "The maximum depth of sub directories to look for services in. Default -1 is infinite")
+	planCmd.Flags().Int64Var(&flags.planMaxSize, planMaxSizeFlag, -1, "The maximum total size in bytes of the files to look for services in. Default -1 is infinite")
+	planCmd.Flags().IntVar(&flags.serviceNameMaxLength, serviceNameMaxLengthFlag, common.MaxMetadataNameLength, "The maximum length of the service names. Longer or colliding names are shortened and given a hash suffix.")
+	planCmd.Flags().StringVar(&flags.symlinkPolicy, symlinkPolicyFlag, string(common.SkipSymlinkPolicy), "How to handle the symbolic links in the source directory. One of skip, follow (walk the targets and keep the links) or copy (walk and copy the targets in place of the links). The targets are read even if they are outside the source directory, so only follow or copy the links of trusted sources.")
+	planCmd.Flags().StringVar(&flags.profile, profileFlag, "", "Type of profile to generate. One of cpu, mem or trace. By default we don't profile.")
+	planCmd.Flags().StringVar(&flags.profileOutput, profileOutputFlag, "", "Path where the profile file should be generated. By default it is generated in the current directory.")
+	addRemoteCacheFlags(planCmd, &flags.remoteCacheFlags)
+	addCollectBundleFlags(planCmd, &flags.collectBundleFlags)
+
 	must(planCmd.Flags().MarkHidden(planProgressPortFlag))
 
 	return planCmd
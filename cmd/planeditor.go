@@ -0,0 +1,193 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/AlecAivazis/survey/v2"
+	plantypes "github.com/konveyor/move2kube/types/plan"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	planEditorTransformersOption = "Select the transformers generating the target artifacts"
+	planEditorSaveOption         = "Save and exit"
+	planEditorQuitOption         = "Exit without saving"
+	planEditorBackOption         = "Back"
+)
+
+// editPlan lets the user browse and change the services and transformers of the plan in the terminal
+func editPlan(planfile string) {
+	p, err := plantypes.ReadPlan(planfile, "")
+	if err != nil {
+		logrus.Fatalf("failed to read the plan file at path %s . Error: %q", planfile, err)
+	}
+	for {
+		serviceNames, serviceOptions := getPlanEditorServiceOptions(p)
+		options := append(serviceOptions, planEditorTransformersOption, planEditorSaveOption, planEditorQuitOption)
+		selected := askPlanEditorSelect(fmt.Sprintf("Plan '%s' - select a service to edit:", p.Name), options)
+		if selected < len(serviceOptions) {
+			editPlanService(&p, serviceNames[selected])
+			continue
+		}
+		switch options[selected] {
+		case planEditorTransformersOption:
+			editPlanTransformers(&p)
+		case planEditorSaveOption:
+			warnServicesWithDisabledTransformers(p)
+			if err := plantypes.WritePlan(planfile, p); err != nil {
+				logrus.Fatalf("failed to write the plan to file at path %s . Error: %q", planfile, err)
+			}
+			logrus.Infof("Plan was saved to [%s].", planfile)
+			return
+		case planEditorQuitOption:
+			logrus.Infof("Exiting without saving the plan.")
+			return
+		}
+	}
+}
+
+// getPlanEditorServiceOptions returns the sorted service names and their menu entries
+func getPlanEditorServiceOptions(p plantypes.Plan) ([]string, []string) {
+	serviceNames := []string{}
+	for serviceName := range p.Spec.Services {
+		serviceNames = append(serviceNames, serviceName)
+	}
+	for serviceName := range p.Spec.DisabledServices {
+		serviceNames = append(serviceNames, serviceName)
+	}
+	sort.Strings(serviceNames)
+	serviceOptions := []string{}
+	for _, serviceName := range serviceNames {
+		if options, ok := p.Spec.Services[serviceName]; ok {
+			serviceOptions = append(serviceOptions, fmt.Sprintf("[✓] %s (%s)", serviceName, getPlanArtifactSummary(options[0])))
+		} else {
+			serviceOptions = append(serviceOptions, fmt.Sprintf("[ ] %s (%s)", serviceName, getPlanArtifactSummary(p.Spec.DisabledServices[serviceName][0])))
+		}
+	}
+	return serviceNames, serviceOptions
+}
+
+// editPlanService lets the user toggle the conversion of the service and choose its transformation option
+func editPlanService(p *plantypes.Plan, serviceName string) {
+	for {
+		options, enabled := p.Spec.Services[serviceName]
+		if !enabled {
+			options = p.Spec.DisabledServices[serviceName]
+		}
+		toggleOption := "Do not convert this service"
+		if !enabled {
+			toggleOption = "Convert this service"
+		}
+		chooseOption := fmt.Sprintf("Change the containerization (current: %s)", getPlanArtifactSummary(options[0]))
+		menu := []string{toggleOption}
+		if len(options) > 1 {
+			menu = append(menu, chooseOption)
+		}
+		menu = append(menu, planEditorBackOption)
+		switch menu[askPlanEditorSelect(fmt.Sprintf("Service '%s':", serviceName), menu)] {
+		case toggleOption:
+			if err := p.SetServiceEnabled(serviceName, !enabled); err != nil {
+				logrus.Errorf("failed to change the conversion of the service '%s' . Error: %q", serviceName, err)
+			}
+		case chooseOption:
+			// the options are selected by their index since different options can have the same summary
+			summaries := []string{}
+			for i, option := range options {
+				summary := fmt.Sprintf("%d. %s", i+1, getPlanArtifactSummary(option))
+				if _, ok := p.Spec.DisabledTransformers[option.TransformerName]; ok {
+					summary += " (transformer disabled)"
+				}
+				summaries = append(summaries, summary)
+			}
+			selected := askPlanEditorSelect(fmt.Sprintf("Select the containerization of the service '%s':", serviceName), summaries)
+			if err := p.SelectServiceOption(serviceName, selected); err != nil {
+				logrus.Errorf("failed to select the containerization of the service '%s' . Error: %q", serviceName, err)
+			}
+		default:
+			return
+		}
+	}
+}
+
+// editPlanTransformers lets the user choose the transformers used during transformation
+func editPlanTransformers(p *plantypes.Plan) {
+	transformerNames, enabledTransformerNames := []string{}, []string{}
+	for transformerName := range p.Spec.Transformers {
+		transformerNames = append(transformerNames, transformerName)
+		enabledTransformerNames = append(enabledTransformerNames, transformerName)
+	}
+	for transformerName := range p.Spec.DisabledTransformers {
+		transformerNames = append(transformerNames, transformerName)
+	}
+	sort.Strings(transformerNames)
+	selected := []string{}
+	prompt := &survey.MultiSelect{
+		Message:  planEditorTransformersOption + ":",
+		Options:  transformerNames,
+		Default:  enabledTransformerNames,
+		PageSize: 20,
+	}
+	tickIcon := func(icons *survey.IconSet) { icons.MarkedOption.Text = "[✓]" }
+	if err := survey.AskOne(prompt, &selected, survey.WithIcons(tickIcon)); err != nil {
+		logrus.Fatalf("Error while asking a question : %s", err)
+	}
+	selectedSet := map[string]bool{}
+	for _, transformerName := range selected {
+		selectedSet[transformerName] = true
+	}
+	for _, transformerName := range transformerNames {
+		if err := p.SetTransformerEnabled(transformerName, selectedSet[transformerName]); err != nil {
+			logrus.Errorf("failed to change the transformer '%s' . Error: %q", transformerName, err)
+		}
+	}
+	warnServicesWithDisabledTransformers(*p)
+}
+
+// warnServicesWithDisabledTransformers warns about the services whose selected containerization uses a disabled transformer
+func warnServicesWithDisabledTransformers(p plantypes.Plan) {
+	services := p.GetServicesWithDisabledTransformers()
+	serviceNames := []string{}
+	for serviceName := range services {
+		serviceNames = append(serviceNames, serviceName)
+	}
+	sort.Strings(serviceNames)
+	for _, serviceName := range serviceNames {
+		logrus.Warnf("The selected containerization of the service '%s' uses the disabled transformer '%s'. The next option with an enabled transformer will be used instead, if any.", serviceName, services[serviceName])
+	}
+}
+
+// getPlanArtifactSummary returns the transformer and the artifact type of a transformation option
+func getPlanArtifactSummary(option plantypes.PlanArtifact) string {
+	return fmt.Sprintf("%s -> %s", option.TransformerName, option.Type)
+}
+
+// askPlanEditorSelect returns the index of the selected option
+func askPlanEditorSelect(message string, options []string) int {
+	selected := 0
+	prompt := &survey.Select{
+		Message:  message,
+		Options:  options,
+		PageSize: 20,
+	}
+	if err := survey.AskOne(prompt, &selected); err != nil {
+		logrus.Fatalf("Error while asking a question : %s", err)
+	}
+	return selected
+}
@@ -67,5 +67,7 @@ func GetRootCmd() *cobra.Command {
 	rootCmd.AddCommand(GetTransformCommand())
 	rootCmd.AddCommand(GetGenerateDocsCommand())
 	rootCmd.AddCommand(GetGraphCommand())
+	rootCmd.AddCommand(GetServeCommand())
+	rootCmd.AddCommand(GetTransformerCommand())
 	return rootCmd
 }
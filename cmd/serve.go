@@ -0,0 +1,70 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package cmd
+
+import (
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/lib"
+	"github.com/konveyor/move2kube/server"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+type serveFlags struct {
+	host                  string
+	port                  int
+	grpcPort              int
+	workDir               string
+	disableLocalExecution bool
+}
+
+func serveHandler(flags serveFlags) {
+	defer lib.Destroy()
+	// Global settings
+	common.DisableLocalExecution = flags.disableLocalExecution
+	// Global settings
+	s, err := server.NewServer(flags.workDir)
+	if err != nil {
+		logrus.Fatalf("failed to create the API server. Error: %q", err)
+	}
+	if flags.grpcPort != 0 {
+		go func() {
+			logrus.Fatalf("gRPC server stopped. Error: %q", s.ServeGRPC(flags.host, flags.grpcPort))
+		}()
+	}
+	logrus.Fatalf("API server stopped. Error: %q", s.ListenAndServe(flags.host, flags.port))
+}
+
+// GetServeCommand returns a command to start the REST API server
+func GetServeCommand() *cobra.Command {
+	viper.AutomaticEnv()
+	flags := serveFlags{}
+	serveCmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Start the move2kube REST API server",
+		Long: `Start a REST API server to upload sources, create plans, answer questions and download the transformed output.
+	Jobs are run asynchronously, one at a time. Use GET /api/v1/jobs/{id} to track the status of a job.`,
+		Run: func(_ *cobra.Command, __ []string) { serveHandler(flags) },
+	}
+	serveCmd.Flags().StringVar(&flags.host, serverHostFlag, "127.0.0.1", "Address to start the servers on. The API is not authenticated, so only listen on the other interfaces behind an authenticating proxy.")
+	serveCmd.Flags().IntVarP(&flags.port, serverPortFlag, "p", 8080, "Port to start the server on.")
+	serveCmd.Flags().IntVar(&flags.grpcPort, grpcPortFlag, 0, "Port to start the gRPC server on. If not provided, the gRPC server won't be started.")
+	serveCmd.Flags().StringVar(&flags.workDir, workDirFlag, "m2k-jobs", "Directory where the sources, plans and outputs of the jobs are stored.")
+	serveCmd.Flags().BoolVar(&flags.disableLocalExecution, common.DisableLocalExecutionFlag, false, "Allow files to be executed locally.")
+	return serveCmd
+}
@@ -21,7 +21,6 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
-	"runtime/pprof"
 
 	"github.com/konveyor/move2kube/assets"
 	"github.com/konveyor/move2kube/common"
@@ -38,16 +37,42 @@ import (
 
 type transformFlags struct {
 	qaflags
+	remoteCacheFlags
+	collectBundleFlags
 	// maxVCSRepoCloneSize is the maximum size in bytes for cloning repos
 	maxVCSRepoCloneSize int64
 	// ignoreEnv tells us whether to use data collected from the local machine
 	ignoreEnv bool
 	// disableLocalExecution disables execution of executables locally
 	disableLocalExecution bool
+	// disableProvenance disables the annotations linking the generated resources to their sources
+	disableProvenance bool
+	// dumpIR is the path where the IR loaded from the sources is written
+	dumpIR string
+	// previewDiffs previews the files written by each transformer as a diff and asks before writing them
+	previewDiffs bool
+	// parallel is the maximum number of transformers to run at the same time
+	parallel int
+	// incremental only transforms the services that changed since the last transformation
+	incremental bool
+	// planCacheDir is the directory where the results of analyzing the source directory are cached
+	planCacheDir string
+	// planMaxDepth is the maximum depth of sub directories to plan in
+	planMaxDepth int
+	// planMaxSize is the maximum total size of the files to plan in
+	planMaxSize int64
+	// serviceNameMaxLength is the maximum length of the service names
+	serviceNameMaxLength int
+	// symlinkPolicy is how the symbolic links in the source directory are handled
+	symlinkPolicy string
+	// maxEmbeddedFileSize is the maximum size of a file that can be embedded in a ConfigMap or Secret
+	maxEmbeddedFileSize int64
 	// planfile is contains the path to the plan file
 	planfile string
-	// profilepath contains the path to the CPU profile file
-	profilepath string
+	// profile contains the type of profile to generate
+	profile string
+	// profileOutput contains the path to the profile file
+	profileOutput string
 	// outpath contains the path to the output folder
 	outpath string
 	// SourceFlag contains path to the source folder
@@ -66,15 +91,9 @@ type transformFlags struct {
 }
 
 func transformHandler(cmd *cobra.Command, flags transformFlags) {
-	if flags.profilepath != "" {
-		if f, err := os.Create(flags.profilepath); err != nil {
-			panic(err)
-		} else if err := pprof.StartCPUProfile(f); err != nil {
-			panic(err)
-		}
-		defer pprof.StopCPUProfile()
-	}
+	defer startProfiling(flags.profile, flags.profileOutput)()
 	vcs.SetMaxRepoCloneSize(flags.maxVCSRepoCloneSize)
+	setupRemoteCache(flags.remoteCacheFlags)
 
 	ctx, cancel := context.WithCancel(cmd.Context())
 	logrus.AddHook(common.NewCleanupHook(cancel))
@@ -159,6 +178,27 @@ func transformHandler(cmd *cobra.Command, flags transformFlags) {
 	// Global settings
 	common.IgnoreEnvironment = flags.ignoreEnv
 	common.DisableLocalExecution = flags.disableLocalExecution
+	common.DisableProvenanceAnnotations = flags.disableProvenance
+	if flags.dumpIR != "" {
+		dumpIRPath, err := filepath.Abs(flags.dumpIR)
+		if err != nil {
+			logrus.Fatalf("failed to make the IR dump path '%s' absolute. Error: %q", flags.dumpIR, err)
+		}
+		common.DumpIRPath = dumpIRPath
+	}
+	common.PreviewTransformerDiffs = flags.previewDiffs
+	if flags.parallel < 1 {
+		logrus.Fatalf("the value of the --%s flag must be at least 1. Actual: %d", parallelFlag, flags.parallel)
+	}
+	common.MaxParallelTransforms = flags.parallel
+	common.PlanCacheDir = flags.planCacheDir
+	common.PlanMaxDepth = flags.planMaxDepth
+	common.PlanMaxSizeBytes = flags.planMaxSize
+	common.ServiceNameMaxLength = flags.serviceNameMaxLength
+	if common.SymlinkPolicy, err = common.ParseSymlinkPolicy(flags.symlinkPolicy); err != nil {
+		logrus.Fatalf("invalid value for the --%s flag. Error: %q", symlinkPolicyFlag, err)
+	}
+	common.MaxEmbeddedFileSizeBytes = flags.maxEmbeddedFileSize
 	// if --qa-enable is passed, all categories are disabled by default. Otherwise, only categories passed to --qa-disable
 	// are disabled
 	if len(flags.qaEnabledCategories) > 0 {
@@ -190,7 +230,7 @@ func transformHandler(cmd *cobra.Command, flags transformFlags) {
 		// Global settings
 		if !isRemoteOutPath {
 			flags.outpath = filepath.Join(flags.outpath, flags.name)
-			checkOutputPath(flags.outpath, flags.overwrite)
+			checkOutputPath(flags.outpath, flags.overwrite || flags.incremental)
 			if flags.srcpath != "" && !isRemotePath {
 				checkSourcePath(flags.srcpath)
 				if flags.srcpath == flags.outpath || common.IsParent(flags.outpath, flags.srcpath) || common.IsParent(flags.srcpath, flags.outpath) {
@@ -201,6 +241,7 @@ func transformHandler(cmd *cobra.Command, flags transformFlags) {
 				logrus.Fatalf("Failed to create the output directory at path %s Error: %q", flags.outpath, err)
 			}
 		}
+		importCollectBundle(flags.collectBundleFlags, flags.srcpath, isRemotePath)
 		startQA(flags.qaflags)
 		logrus.Debugf("Creating a new plan.")
 		transformationPlan, err = lib.CreatePlan(ctx, flags.srcpath, flags.outpath, flags.customizationsPath, flags.transformerSelector, flags.name)
@@ -240,10 +281,11 @@ func transformHandler(cmd *cobra.Command, flags transformFlags) {
 		if transformationPlan.Spec.SourceDir != "" {
 			checkSourcePath(transformationPlan.Spec.SourceDir)
 		}
-		lib.CheckAndCopyCustomizations(transformationPlan.Spec.CustomizationsDir)
+		importCollectBundle(flags.collectBundleFlags, transformationPlan.Spec.SourceDir, vcs.IsRemotePath(transformationPlan.Spec.SourceDir))
+		lib.CheckAndCopyCustomizations(ctx, transformationPlan.Spec.CustomizationsDir)
 		if !isRemoteOutPath {
 			flags.outpath = filepath.Join(flags.outpath, transformationPlan.Name)
-			checkOutputPath(flags.outpath, flags.overwrite)
+			checkOutputPath(flags.outpath, flags.overwrite || flags.incremental)
 			if transformationPlan.Spec.SourceDir != "" && (transformationPlan.Spec.SourceDir == flags.outpath || common.IsParent(flags.outpath, transformationPlan.Spec.SourceDir) || common.IsParent(transformationPlan.Spec.SourceDir, flags.outpath)) {
 				logrus.Fatalf("The source path %s and output path %s overlap.", transformationPlan.Spec.SourceDir, flags.outpath)
 			}
@@ -253,7 +295,11 @@ func transformHandler(cmd *cobra.Command, flags transformFlags) {
 		}
 		startQA(flags.qaflags)
 	}
-	if err := lib.Transform(
+	transform := lib.Transform
+	if flags.incremental {
+		transform = lib.TransformIncremental
+	}
+	if err := transform(
 		ctx,
 		transformationPlan,
 		preExistingPlan,
@@ -285,7 +331,8 @@ func GetTransformCommand() *cobra.Command {
 	}
 
 	// Basic options
-	transformCmd.Flags().StringVar(&flags.profilepath, profileFlag, "", "Path where the CPU profile file should be generated. By default we don't profile.")
+	transformCmd.Flags().StringVar(&flags.profile, profileFlag, "", "Type of profile to generate. One of cpu, mem or trace. By default we don't profile.")
+	transformCmd.Flags().StringVar(&flags.profileOutput, profileOutputFlag, "", "Path where the profile file should be generated. By default it is generated in the current directory.")
 	transformCmd.Flags().StringVarP(&flags.planfile, planFlag, "p", common.DefaultPlanFile, "Specify a plan file to execute.")
 	transformCmd.Flags().BoolVar(&flags.overwrite, overwriteFlag, false, "Overwrite the output directory if it exists. By default we don't overwrite.")
 	transformCmd.Flags().StringVarP(&flags.srcpath, sourceFlag, "s", "", "Specify source directory or a git url (see https://move2kube.konveyor.io/concepts/git-support) to transform. If you already have a m2k.plan then this will override the sourceDir value specified in that plan.")
@@ -297,7 +344,7 @@ func GetTransformCommand() *cobra.Command {
 	transformCmd.Flags().StringSliceVar(&flags.preSets, preSetFlag, []string{}, "Specify preset config to use.")
 	transformCmd.Flags().BoolVar(&flags.persistPasswords, qaPersistPasswords, false, "Store passwords in the config and cache. By default passwords are not persisted.")
 	transformCmd.Flags().StringArrayVar(&flags.setconfigs, setConfigFlag, []string{}, "Specify config key-value pairs.")
-	transformCmd.Flags().StringVarP(&flags.customizationsPath, customizationsFlag, "c", "", "Specify directory or a git url (see https://move2kube.konveyor.io/concepts/git-support) where customizations are stored. By default we look for "+common.DefaultCustomizationDir)
+	transformCmd.Flags().StringVarP(&flags.customizationsPath, customizationsFlag, "c", "", "Specify directory, git url (see https://move2kube.konveyor.io/concepts/git-support), https url of a zip/tar(.gz) archive (optionally pinned using #sha256=<digest>) or OCI artifact oci://<registry>/<repo>[:<tag>|@<digest>] where customizations and external transformers are stored. Credentials are taken from the M2K_GIT_* env vars for the M2K_GIT_HOST host, the M2K_HTTP_TOKEN env var for the M2K_HTTP_HOST host, the ssh agent, the netrc file or the docker config. By default we look for "+common.DefaultCustomizationDir)
 	transformCmd.Flags().StringVarP(&flags.transformerSelector, transformerSelectorFlag, "t", "", "Specify the transformer selector.")
 	transformCmd.Flags().BoolVar(&flags.qaskip, qaSkipFlag, false, "Enable/disable the default answers to questions posed in QA Cli sub-system. If disabled, you will have to answer the questions posed by QA during interaction.")
 	transformCmd.Flags().Int64Var(&flags.maxVCSRepoCloneSize, maxCloneSizeBytesFlag, -1, "Max size in bytes when cloning a git repo. Default -1 is infinite")
@@ -309,7 +356,20 @@ func GetTransformCommand() *cobra.Command {
 	// Advanced options
 	transformCmd.Flags().BoolVar(&flags.ignoreEnv, ignoreEnvFlag, false, "Ignore data from local machine.")
 	transformCmd.Flags().BoolVar(&flags.disableLocalExecution, common.DisableLocalExecutionFlag, false, "Allow files to be executed locally.")
+	transformCmd.Flags().BoolVar(&flags.disableProvenance, disableProvenanceFlag, false, "Do not annotate the generated resources with their source files, transformers and the move2kube version.")
+	transformCmd.Flags().StringVar(&flags.dumpIR, dumpIRFlag, "", "Write the IR loaded from the source artifacts to this file. The IR is written as JSON if the file has a .json extension and as YAML otherwise.")
+	transformCmd.Flags().BoolVar(&flags.previewDiffs, previewDiffsFlag, false, "Show the files each transformer is about to write as a diff against the output directory and ask whether to write them.")
 	transformCmd.Flags().IntVar(&flags.maxIterations, maxIterationsFlag, -1, "The maximum number of iterations to allow. Negative value means infinite. Default is -1.")
+	transformCmd.Flags().IntVar(&flags.parallel, parallelFlag, 1, "The maximum number of transformers to run at the same time. The transformers run one at a time when the questions are asked interactively. Default is 1.")
+	transformCmd.Flags().BoolVar(&flags.incremental, incrementalFlag, false, "Only transform the services that changed since the last transformation into the same output directory, and only rewrite the output files that changed. Implies --"+overwriteFlag+".")
+	transformCmd.Flags().StringVar(&flags.planCacheDir, planCacheDirFlag, "", "Specify a directory to cache the results of analyzing the source directory when planning. Caching is disabled by default.")
+	transformCmd.Flags().IntVar(&flags.planMaxDepth, planMaxDepthFlag, -1, "The maximum depth of sub directories to look for services in when planning. Default -1 is infinite")
+	transformCmd.Flags().Int64Var(&flags.planMaxSize, planMaxSizeFlag, -1, "The maximum total size in bytes of the files to look for services in when planning. Default -1 is infinite")
+	transformCmd.Flags().IntVar(&flags.serviceNameMaxLength, serviceNameMaxLengthFlag, common.MaxMetadataNameLength, "The maximum length of the service names when planning. Longer or colliding names are shortened and given a hash suffix.")
+	transformCmd.Flags().StringVar(&flags.symlinkPolicy, symlinkPolicyFlag, string(common.SkipSymlinkPolicy), "How to handle the symbolic links in the source directory. One of skip, follow (walk the targets and keep the links) or copy (walk and copy the targets in place of the links). The targets are read even if they are outside the source directory, so only follow or copy the links of trusted sources.")
+	addRemoteCacheFlags(transformCmd, &flags.remoteCacheFlags)
+	addCollectBundleFlags(transformCmd, &flags.collectBundleFlags)
+	transformCmd.Flags().Int64Var(&flags.maxEmbeddedFileSize, maxEmbeddedFileSizeFlag, common.MaxEmbeddedFileSizeBytes, "The maximum size in bytes of a file whose contents can be embedded in a ConfigMap or Secret. Larger files are skipped. -1 is infinite")
 
 	// Hidden options
 	transformCmd.Flags().BoolVar(&flags.qadisablecli, qadisablecliFlag, false, "Enable/disable the QA Cli sub-system. Without this system, you will have to use the REST API to interact.")
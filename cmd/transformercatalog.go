@@ -0,0 +1,143 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/konveyor/move2kube/lib"
+	"github.com/konveyor/move2kube/types"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	// defaultTransformerCatalog is the catalog of community transformers used when no catalog is specified
+	defaultTransformerCatalog = "git+https://github.com/konveyor/move2kube-transformers.git"
+)
+
+type transformerCatalogFlags struct {
+	// catalog is the git url, OCI artifact ref, archive url or directory of the transformer catalog
+	catalog string
+	// installDir is the directory where the transformers are installed
+	installDir string
+	remoteCacheFlags
+}
+
+func transformerSearchHandler(flags transformerCatalogFlags, args []string) {
+	setupRemoteCache(flags.remoteCacheFlags)
+	defer lib.Destroy()
+	query := strings.Join(args, " ")
+	entries, err := lib.SearchCatalog(context.Background(), flags.catalog, query)
+	if err != nil {
+		logrus.Fatalf("failed to search the transformer catalog. Error: %q", err)
+	}
+	if len(entries) == 0 {
+		logrus.Infof("No transformers matching '%s' found in the catalog %s", query, flags.catalog)
+		return
+	}
+	for _, entry := range entries {
+		versions := []string{}
+		for _, v := range entry.Versions {
+			versions = append(versions, v.Version)
+		}
+		fmt.Printf("%s\t%s\t%s\n", entry.Name, strings.Join(versions, ","), entry.Description)
+	}
+}
+
+func transformerInstallHandler(flags transformerCatalogFlags, args []string) {
+	setupRemoteCache(flags.remoteCacheFlags)
+	defer lib.Destroy()
+	installDir := getTransformerInstallDir(flags.installDir)
+	for _, transformer := range args {
+		installed, err := lib.InstallTransformer(context.Background(), flags.catalog, transformer, installDir)
+		if err != nil {
+			logrus.Fatalf("failed to install the transformer %s . Error: %q", transformer, err)
+		}
+		logrus.Infof("Installed the version %s of the transformer %s into %s", installed.Version, installed.Name, filepath.Join(installDir, installed.Name))
+	}
+	logrus.Infof("Use --%s %s with the plan and transform commands to use the installed transformers.", customizationsFlag, installDir)
+}
+
+func transformerListHandler(flags transformerCatalogFlags) {
+	installDir := getTransformerInstallDir(flags.installDir)
+	installed, err := lib.ListInstalledTransformers(installDir)
+	if err != nil {
+		logrus.Fatalf("failed to list the installed transformers. Error: %q", err)
+	}
+	for _, t := range installed {
+		fmt.Printf("%s\t%s\t%s\n", t.Name, t.Version, t.Source)
+	}
+}
+
+// getTransformerInstallDir returns the absolute install directory, defaulting to a directory in the user config directory
+func getTransformerInstallDir(installDir string) string {
+	if installDir == "" {
+		userConfigDir, err := os.UserConfigDir()
+		if err != nil {
+			logrus.Fatalf("failed to get the user config directory. Specify the install directory using --%s . Error: %q", installDirFlag, err)
+		}
+		return filepath.Join(userConfigDir, types.AppName, "transformers")
+	}
+	absInstallDir, err := filepath.Abs(installDir)
+	if err != nil {
+		logrus.Fatalf("failed to make the install directory path %s absolute. Error: %q", installDir, err)
+	}
+	return absInstallDir
+}
+
+// GetTransformerCommand returns a command to search, install and list transformers from catalogs
+func GetTransformerCommand() *cobra.Command {
+	viper.AutomaticEnv()
+	flags := transformerCatalogFlags{}
+	transformerCmd := &cobra.Command{
+		Use:   "transformer",
+		Short: "Search, install and list transformers from a transformer catalog",
+		Long: `Search, install and list community transformers and customizations published in a transformer catalog.
+	A catalog is a git repo, OCI artifact, archive or directory with an index.yaml listing the versions of the transformers.`,
+	}
+	searchCmd := &cobra.Command{
+		Use:   "search [query]",
+		Short: "Search the transformers in the catalog",
+		Run:   func(_ *cobra.Command, args []string) { transformerSearchHandler(flags, args) },
+	}
+	installCmd := &cobra.Command{
+		Use:   "install name[@version]...",
+		Short: "Install the latest or the given version of the transformers from the catalog",
+		Args:  cobra.MinimumNArgs(1),
+		Run:   func(_ *cobra.Command, args []string) { transformerInstallHandler(flags, args) },
+	}
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the installed transformers",
+		Run:   func(_ *cobra.Command, __ []string) { transformerListHandler(flags) },
+	}
+	for _, cmd := range []*cobra.Command{searchCmd, installCmd} {
+		cmd.Flags().StringVar(&flags.catalog, catalogFlag, defaultTransformerCatalog, "Specify the git url, OCI artifact ref, archive url or directory of the transformer catalog.")
+		addRemoteCacheFlags(cmd, &flags.remoteCacheFlags)
+	}
+	for _, cmd := range []*cobra.Command{installCmd, listCmd} {
+		cmd.Flags().StringVar(&flags.installDir, installDirFlag, "", "Specify the directory to install the transformers in. By default a directory in the user config directory is used.")
+	}
+	transformerCmd.AddCommand(searchCmd, installCmd, listCmd)
+	return transformerCmd
+}
@@ -18,14 +18,22 @@ package cmd
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"os"
 	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
 	"strings"
+	"sync"
 
 	"github.com/gorilla/mux"
 	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/common/remotecache"
+	"github.com/konveyor/move2kube/lib"
 	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/types"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cast"
 )
@@ -82,6 +90,115 @@ Exiting.`, outpath, overwriteFlag, outputFlag)
 	logrus.Infof("Output directory '%s' exists. The contents might get overwritten.", outpath)
 }
 
+const (
+	cpuProfile   = "cpu"
+	memProfile   = "mem"
+	traceProfile = "trace"
+)
+
+// startProfiling starts collecting the given type of profile and returns a function that stops it and writes the profile.
+// The profile is also written if the command exits with a fatal error.
+func startProfiling(profileType, profilePath string) func() {
+	if profileType == "" {
+		return func() {}
+	}
+	stop, err := startProfile(getProfileTypeAndPath(profileType, profilePath))
+	if err != nil {
+		logrus.Fatalf("%s", err)
+	}
+	logrus.AddHook(common.NewCleanupHook(stop))
+	return stop
+}
+
+// getProfileTypeAndPath returns the type of the profile and the path of the profile file, defaulting to a file in the current directory.
+// A type that is not one of cpu, mem or trace is treated as the path of a cpu profile, for backward compatibility.
+func getProfileTypeAndPath(profileType, profilePath string) (string, string) {
+	if profileType != cpuProfile && profileType != memProfile && profileType != traceProfile {
+		logrus.Warnf("Passing a file path to --%s is deprecated. Use --%s %s --%s %s instead.", profileFlag, profileFlag, cpuProfile, profileOutputFlag, profileType)
+		profileType, profilePath = cpuProfile, profileType
+	}
+	if profilePath == "" {
+		profilePath = fmt.Sprintf("%s.%s.pprof", types.AppNameShort, profileType)
+		if profileType == traceProfile {
+			profilePath = types.AppNameShort + ".trace"
+		}
+	}
+	return profileType, profilePath
+}
+
+// startProfile creates the profile file and starts collecting the profile. The file is removed if the profile cannot be started.
+// The returned function stops the profile and writes it to the file. It can be called more than once.
+func startProfile(profileType, profilePath string) (func(), error) {
+	f, err := os.Create(profilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the %s profile file at path '%s' . Error: %w", profileType, profilePath, err)
+	}
+	switch profileType {
+	case cpuProfile:
+		err = pprof.StartCPUProfile(f)
+	case traceProfile:
+		err = trace.Start(f)
+	}
+	if err != nil {
+		if err := f.Close(); err != nil {
+			logrus.Debugf("failed to close the %s profile file at path '%s' . Error: %q", profileType, profilePath, err)
+		}
+		if err := os.Remove(profilePath); err != nil {
+			logrus.Debugf("failed to remove the %s profile file at path '%s' . Error: %q", profileType, profilePath, err)
+		}
+		return nil, fmt.Errorf("failed to start the %s profile. Error: %w", profileType, err)
+	}
+	once := sync.Once{}
+	return func() {
+		once.Do(func() {
+			switch profileType {
+			case cpuProfile:
+				pprof.StopCPUProfile()
+			case memProfile:
+				runtime.GC()
+				if err := pprof.WriteHeapProfile(f); err != nil {
+					logrus.Errorf("failed to write the memory profile to the file at path '%s' . Error: %q", profilePath, err)
+				}
+			case traceProfile:
+				trace.Stop()
+			}
+			if err := f.Close(); err != nil {
+				logrus.Errorf("failed to close the %s profile file at path '%s' . Error: %q", profileType, profilePath, err)
+			}
+			logrus.Infof("The %s profile can be found at [%s].", profileType, profilePath)
+		})
+	}, nil
+}
+
+// setupRemoteCache configures the cache for remote customizations and sources
+func setupRemoteCache(flags remoteCacheFlags) {
+	if flags.remoteCacheDir != "" {
+		var err error
+		if flags.remoteCacheDir, err = filepath.Abs(flags.remoteCacheDir); err != nil {
+			logrus.Fatalf("failed to make the remote cache directory path %q absolute. Error: %q", flags.remoteCacheDir, err)
+		}
+	}
+	remotecache.SetCacheDir(flags.remoteCacheDir)
+	remotecache.SetTTL(flags.remoteCacheTTL)
+	remotecache.SetOffline(flags.offline)
+}
+
+// importCollectBundle extracts the collect bundle, if any, into the collect output directory of the source directory
+func importCollectBundle(flags collectBundleFlags, srcpath string, isRemotePath bool) {
+	if flags.collectBundle == "" {
+		return
+	}
+	if srcpath == "" || isRemotePath {
+		logrus.Fatalf("A local source directory is required to import the collect bundle at path %s into.", flags.collectBundle)
+	}
+	if flags.collectBundleKey == "" && !flags.collectBundleAllowUnsigned {
+		logrus.Fatalf("A public key is required to verify the collect bundle at path %s . Use the --%s flag to specify it or the --%s flag to import the bundle without verifying its signature.", flags.collectBundle, collectBundleKeyFlag, allowUnsignedFlag)
+	}
+	if err := lib.ImportCollectBundle(flags.collectBundle, flags.collectBundleKey, flags.collectBundleAllowUnsigned, srcpath); err != nil {
+		logrus.Fatalf("failed to import the collect bundle. Error: %q", err)
+	}
+}
+
 func startQA(flags qaflags) {
 	qaengine.StartEngine(flags.qaskip, flags.qaport, flags.qadisablecli)
 	if flags.configOut == "" {
@@ -123,6 +240,9 @@ func startQA(flags qaflags) {
 	if err := qaengine.WriteStoresToDisk(); err != nil {
 		logrus.Warnf("Failed to write the stores to disk. Error: %q", err)
 	}
+	if common.MaxParallelTransforms > 1 && qaengine.IsInteractive() {
+		logrus.Warnf("The transformers will run one at a time since the questions are asked interactively. Use the --%s flag or a config file to run them in parallel.", qaSkipFlag)
+	}
 }
 
 func startPlanProgressServer(port int) {
@@ -0,0 +1,106 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestGetProfileTypeAndPath(t *testing.T) {
+	testcases := []struct {
+		profileType  string
+		profilePath  string
+		wantType     string
+		wantFilePath string
+	}{
+		{profileType: cpuProfile, wantType: cpuProfile, wantFilePath: "m2k.cpu.pprof"},
+		{profileType: memProfile, wantType: memProfile, wantFilePath: "m2k.mem.pprof"},
+		{profileType: traceProfile, wantType: traceProfile, wantFilePath: "m2k.trace"},
+		{profileType: memProfile, profilePath: "out/heap.pprof", wantType: memProfile, wantFilePath: "out/heap.pprof"},
+		{profileType: "old.pprof", wantType: cpuProfile, wantFilePath: "old.pprof"},
+	}
+	for _, testcase := range testcases {
+		profileType, profilePath := getProfileTypeAndPath(testcase.profileType, testcase.profilePath)
+		if profileType != testcase.wantType || profilePath != testcase.wantFilePath {
+			t.Fatalf("failed to get the profile for --profile %q --profile-output %q . Expected: %s %s Actual: %s %s",
+				testcase.profileType, testcase.profilePath, testcase.wantType, testcase.wantFilePath, profileType, profilePath)
+		}
+	}
+}
+
+func TestStartProfile(t *testing.T) {
+	for _, profileType := range []string{cpuProfile, memProfile, traceProfile} {
+		t.Run(profileType, func(t *testing.T) {
+			profilePath := filepath.Join(t.TempDir(), profileType+".pprof")
+			stop, err := startProfile(profileType, profilePath)
+			if err != nil {
+				t.Fatalf("failed to start the profile. Error: %q", err)
+			}
+			stop()
+			stop()
+			info, err := os.Stat(profilePath)
+			if err != nil {
+				t.Fatalf("expected the profile file to be created. Error: %q", err)
+			}
+			if info.Size() == 0 {
+				t.Fatalf("expected the profile to be written to the file %s", profilePath)
+			}
+		})
+	}
+	t.Run("invalid path", func(t *testing.T) {
+		if _, err := startProfile(memProfile, filepath.Join(t.TempDir(), "missing", "mem.pprof")); err == nil {
+			t.Fatalf("expected an error when the profile file cannot be created")
+		}
+	})
+	t.Run("remove the file when the profile cannot be started", func(t *testing.T) {
+		stop, err := startProfile(cpuProfile, filepath.Join(t.TempDir(), "first.pprof"))
+		if err != nil {
+			t.Fatalf("failed to start the profile. Error: %q", err)
+		}
+		defer stop()
+		profilePath := filepath.Join(t.TempDir(), "second.pprof")
+		if _, err := startProfile(cpuProfile, profilePath); err == nil {
+			t.Fatalf("expected an error when a cpu profile is already running")
+		}
+		if _, err := os.Stat(profilePath); !os.IsNotExist(err) {
+			t.Fatalf("expected the profile file %s to be removed. Error: %v", profilePath, err)
+		}
+	})
+}
+
+func TestStartProfilingWritesOnPanic(t *testing.T) {
+	hooks := logrus.StandardLogger().ReplaceHooks(logrus.LevelHooks{})
+	defer logrus.StandardLogger().ReplaceHooks(hooks)
+	if stop := startProfiling("", ""); stop == nil {
+		t.Fatalf("expected a no-op stop function when profiling is disabled")
+	}
+	profilePath := filepath.Join(t.TempDir(), "mem.pprof")
+	stop := startProfiling(memProfile, profilePath)
+	defer stop()
+	func() {
+		defer func() { _ = recover() }()
+		logrus.Panic("stop the command")
+	}()
+	info, err := os.Stat(profilePath)
+	if err != nil || info.Size() == 0 {
+		t.Fatalf("expected the profile to be written when the command stops with a panic. Error: %v", err)
+	}
+}
@@ -17,10 +17,13 @@
 package collector
 
 import (
+	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
 
+	cfclient "github.com/cloudfoundry-community/go-cfclient/v2"
 	"github.com/konveyor/move2kube/common"
 	collecttypes "github.com/konveyor/move2kube/types/collection"
 
@@ -60,6 +63,12 @@ func (c *CfServicesCollector) Collect(inputPath string, outputPath string) error
 	cfservices := collecttypes.NewCfServices()
 	cfservices.Name = common.NormalizeForMetadataName(strings.TrimSpace(cfInfo.Name))
 	cfservices.Spec.CfServices = services
+	if bindings, err := getCfServiceBindings(client, services); err != nil {
+		logrus.Warnf("Unable to get the service bindings. The credentials of the bound services will not be collected. Error: %q", err)
+	} else if len(bindings) != 0 {
+		cfservices.Spec.ServiceBindings = bindings
+		logrus.Warnf("The collected cf services contain the credentials of %d service bindings. Treat the collect output as a secret.", len(bindings))
+	}
 	fileName := "cfservices-" + cfservices.Name
 	if fileName != "" {
 		outputPath = filepath.Join(outputPath, common.NormalizeForFilename(fileName)+".yaml")
@@ -72,3 +81,78 @@ func (c *CfServicesCollector) Collect(inputPath string, outputPath string) error
 
 	return nil
 }
+
+// getCfServiceBindings returns the service instances bound to the apps, along with the credentials of the bindings
+func getCfServiceBindings(client *cfclient.Client, services []cfclient.Service) ([]collecttypes.CfServiceBinding, error) {
+	apps, err := client.ListAppsByQuery(url.Values{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the apps. Error: %w", err)
+	}
+	plans, err := client.ListServicePlans()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the service plans. Error: %w", err)
+	}
+	instances, err := client.ListServiceInstances()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the service instances. Error: %w", err)
+	}
+	userProvidedInstances, err := client.ListUserProvidedServiceInstances()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the user provided service instances. Error: %w", err)
+	}
+	bindings, err := client.ListServiceBindings()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the service bindings. Error: %w", err)
+	}
+	return getServiceBindings(apps, services, plans, instances, userProvidedInstances, bindings), nil
+}
+
+// getServiceBindings joins the bindings with the apps, service instances, plans and services they refer to
+func getServiceBindings(apps []cfclient.App, services []cfclient.Service, plans []cfclient.ServicePlan, instances []cfclient.ServiceInstance,
+	userProvidedInstances []cfclient.UserProvidedServiceInstance, bindings []cfclient.ServiceBinding) []collecttypes.CfServiceBinding {
+	appNames := map[string]string{}
+	for _, app := range apps {
+		appNames[app.Guid] = app.Name
+	}
+	serviceLabels := map[string]string{}
+	for _, service := range services {
+		serviceLabels[service.Guid] = service.Label
+	}
+	servicePlans := map[string]cfclient.ServicePlan{}
+	for _, plan := range plans {
+		servicePlans[plan.Guid] = plan
+	}
+	bindableInstances := map[string]collecttypes.CfServiceBinding{}
+	for _, instance := range instances {
+		plan := servicePlans[instance.ServicePlanGuid]
+		label := serviceLabels[instance.ServiceGuid]
+		if label == "" {
+			label = serviceLabels[plan.ServiceGuid]
+		}
+		bindableInstances[instance.Guid] = collecttypes.CfServiceBinding{Name: instance.Name, InstanceName: instance.Name, Label: label, Plan: plan.Name, Tags: instance.Tags}
+	}
+	for _, instance := range userProvidedInstances {
+		bindableInstances[instance.Guid] = collecttypes.CfServiceBinding{Name: instance.Name, InstanceName: instance.Name, Label: "user-provided", Tags: instance.Tags, Credentials: instance.Credentials}
+	}
+	serviceBindings := []collecttypes.CfServiceBinding{}
+	for _, binding := range bindings {
+		serviceBinding, ok := bindableInstances[binding.ServiceInstanceGuid]
+		if !ok {
+			logrus.Debugf("The service instance with guid %s of the binding %s was not found", binding.ServiceInstanceGuid, binding.Guid)
+			continue
+		}
+		serviceBinding.AppName = appNames[binding.AppGuid]
+		if binding.Name != "" {
+			serviceBinding.Name = binding.Name
+			serviceBinding.BindingName = binding.Name
+		}
+		if credentials, ok := binding.Credentials.(map[string]interface{}); ok && len(credentials) != 0 {
+			serviceBinding.Credentials = credentials
+		}
+		if serviceBinding.Tags == nil {
+			serviceBinding.Tags = []string{}
+		}
+		serviceBindings = append(serviceBindings, serviceBinding)
+	}
+	return serviceBindings
+}
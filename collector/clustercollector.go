@@ -39,6 +39,11 @@ import (
 	cgclientcmd "k8s.io/client-go/tools/clientcmd"
 )
 
+const (
+	// systemPriorityClassPrefix is the prefix of the priority classes reserved for the critical system pods
+	systemPriorityClassPrefix = "system-"
+)
+
 //ClusterCollector Implements Collector interface
 type ClusterCollector struct {
 	clusterCmd string
@@ -52,7 +57,7 @@ func (c ClusterCollector) GetAnnotations() []string {
 //Collect gets the cluster metadata by querying the cluster. Assumes that the authentication with cluster is already done.
 func (c *ClusterCollector) Collect(inputPath string, outputPath string) error {
 	//Creating the output sub-directory if it does not exist
-	outputPath = filepath.Join(outputPath, "clusters")
+	outputPath = filepath.Join(outputPath, collecttypes.ClustersDirName)
 	err := os.MkdirAll(outputPath, common.DefaultDirectoryPermission)
 	if err != nil {
 		logrus.Errorf("Unable to create output directory at path %q Error: %q", outputPath, err)
@@ -70,10 +75,23 @@ func (c *ClusterCollector) Collect(inputPath string, outputPath string) error {
 		return err
 	}
 	clusterMd := collecttypes.NewClusterMetadata(name)
-	if clusterMd.Spec.StorageClasses, err = c.getStorageClasses(); err != nil {
+	if clusterMd.Spec.StorageClasses, clusterMd.Spec.DefaultStorageClass, clusterMd.Spec.StorageClassAccessModes, err = c.getStorageClasses(); err != nil {
 		//If no storage classes, this will be an empty array
 		clusterMd.Spec.StorageClasses = []string{}
 	}
+	if clusterMd.Spec.IngressClasses, clusterMd.Spec.DefaultIngressClass, err = c.getIngressClasses(); err != nil {
+		logrus.Debugf("Unable to get the ingress classes of the cluster. Error: %q", err)
+	}
+	if clusterMd.Spec.PriorityClasses, err = c.getPriorityClasses(); err != nil {
+		logrus.Debugf("Unable to get the priority classes of the cluster. Error: %q", err)
+	}
+	if clusterMd.Spec.KubernetesVersion, clusterMd.Spec.APIGroups, err = c.getVersionAndGroupsUsingAPI(); err != nil {
+		logrus.Warnf("Unable to get the version and API groups of the cluster. Error: %q", err)
+	}
+	clusterMd.Spec.LoadBalancerSupported = c.getLoadBalancerSupport(clusterMd.Spec.APIGroups)
+	clusterMd.Spec.SecurityContextConstraints = c.getSecurityContextConstraints(clusterMd.Spec.APIGroups)
+	clusterMd.Spec.PodSecurityLevel = c.getPodSecurityLevel()
+	clusterMd.Spec.CapabilityPolicies = c.getCapabilityPolicies(clusterMd.Spec.APIGroups)
 
 	clusterMd.Spec.APIKindVersionMap, err = c.collectUsingAPI()
 	if err != nil {
@@ -116,45 +134,342 @@ func (c *ClusterCollector) getClusterCommand() string {
 	return ""
 }
 
+// getNamespace returns the namespace that is collected, the namespace of the current context or the default namespace
+func (c *ClusterCollector) getNamespace() string {
+	if common.CollectNamespace != "" {
+		return common.CollectNamespace
+	}
+	output, err := exec.Command(c.getClusterCommand(), "config", "view", "--minify", "-o", "jsonpath={..namespace}").Output()
+	namespace := strings.TrimSpace(string(output))
+	if err != nil || namespace == "" {
+		return "default"
+	}
+	return namespace
+}
+
 func (c *ClusterCollector) getClusterContextName() (string, error) {
 	cmd := exec.Command(c.getClusterCommand(), "config", "current-context")
 	name, err := cmd.Output()
 	return strings.TrimSpace(string(name)), err
 }
 
-func (c *ClusterCollector) getStorageClasses() ([]string, error) {
+func (c *ClusterCollector) getStorageClasses() ([]string, string, map[string][]string, error) {
+	items, err := c.getClasses("sc")
+	if err != nil {
+		return nil, "", nil, err
+	}
+	names, defaultName := getClassNames(items, "storageclass.kubernetes.io/is-default-class", "storageclass.beta.kubernetes.io/is-default-class")
+	accessModes := map[string][]string{}
+	for _, item := range items {
+		if modes := getProvisionerAccessModes(item.Provisioner); modes != nil {
+			accessModes[item.Metadata.Name] = modes
+		} else {
+			logrus.Debugf("The access modes supported by the provisioner '%s' of the storage class '%s' are not known", item.Provisioner, item.Metadata.Name)
+		}
+	}
+	return names, defaultName, accessModes, nil
+}
+
+func (c *ClusterCollector) getIngressClasses() ([]string, string, error) {
+	items, err := c.getClasses("ingressclass")
+	if err != nil {
+		return nil, "", err
+	}
+	names, defaultName := getClassNames(items, "ingressclass.kubernetes.io/is-default-class")
+	return names, defaultName, nil
+}
+
+// getPriorityClasses returns the priority classes, except the ones reserved for the system
+func (c *ClusterCollector) getPriorityClasses() ([]string, error) {
+	items, err := c.getClasses("priorityclass")
+	if err != nil {
+		return nil, err
+	}
+	names, _ := getClassNames(items)
+	priorityClasses := []string{}
+	for _, name := range names {
+		if !strings.HasPrefix(name, systemPriorityClassPrefix) {
+			priorityClasses = append(priorityClasses, name)
+		}
+	}
+	return priorityClasses, nil
+}
+
+// classItem stores the fields of storage classes and ingress classes that are collected
+type classItem struct {
+	Metadata struct {
+		Name        string            `yaml:"name"`
+		Annotations map[string]string `yaml:"annotations"`
+	} `yaml:"metadata"`
+	Provisioner string `yaml:"provisioner"`
+}
+
+// getClasses returns all the objects of the given resource type
+func (c *ClusterCollector) getClasses(resource string) ([]classItem, error) {
 	ccmd := c.getClusterCommand()
-	cmd := exec.Command(ccmd, "get", "sc", "-o", "yaml")
+	cmd := exec.Command(ccmd, "get", resource, "-o", "yaml")
 	yamlOutput, err := cmd.CombinedOutput()
 	if err != nil {
 		errDesc := c.interpretError(string(yamlOutput))
 		if errDesc != "" {
 			logrus.Warnf("Error while running %s. %s", ccmd, errDesc)
 		} else {
-			logrus.Warnf("Error while fetching storage classes using command [%s]", cmd)
+			logrus.Warnf("Error while fetching %s using command [%s]", resource, cmd)
 		}
 		return nil, err
 	}
 
-	fileContents := map[string]interface{}{}
+	fileContents := struct {
+		Items []classItem `yaml:"items"`
+	}{}
 	err = yaml.Unmarshal(yamlOutput, &fileContents)
 	if err != nil {
 		logrus.Errorf("Error in unmarshalling yaml: %s. Skipping.", err)
 		return nil, err
 	}
+	return fileContents.Items, nil
+}
+
+// getClassNames returns the names of all the objects
+// and the name of the object that is marked as the default using any of the given annotations
+func getClassNames(items []classItem, defaultAnnotations ...string) ([]string, string) {
+	names := []string{}
+	defaultName := ""
+	for _, item := range items {
+		names = append(names, item.Metadata.Name)
+		for _, annotation := range defaultAnnotations {
+			if item.Metadata.Annotations[annotation] == "true" && defaultName == "" {
+				defaultName = item.Metadata.Name
+			}
+		}
+	}
+	return names, defaultName
+}
+
+// getProvisionerAccessModes returns the access modes supported by the volumes of well known provisioners.
+// It returns nil if the provisioner is not known.
+func getProvisionerAccessModes(provisioner string) []string {
+	fileAccessModes := []string{"ReadWriteOnce", "ReadOnlyMany", "ReadWriteMany"}
+	blockAccessModes := []string{"ReadWriteOnce"}
+	switch provisioner {
+	case "efs.csi.aws.com", "file.csi.azure.com", "kubernetes.io/azure-file", "filestore.csi.storage.gke.io", "cephfs.csi.ceph.com", "openshift-storage.cephfs.csi.ceph.com", "kubernetes.io/cephfs", "kubernetes.io/glusterfs", "vpc.file.csi.ibm.io", "ibm.io/ibmc-file":
+		return fileAccessModes
+	case "ebs.csi.aws.com", "kubernetes.io/aws-ebs", "disk.csi.azure.com", "kubernetes.io/azure-disk", "pd.csi.storage.gke.io", "kubernetes.io/gce-pd", "rbd.csi.ceph.com", "openshift-storage.rbd.csi.ceph.com", "kubernetes.io/rbd", "vpc.block.csi.ibm.io", "ibm.io/ibmc-block", "rancher.io/local-path", "kubernetes.io/no-provisioner", "csi.vsphere.vmware.com", "kubernetes.io/vsphere-volume", "cinder.csi.openstack.org", "kubernetes.io/cinder":
+		return blockAccessModes
+	}
+	if strings.Contains(provisioner, "nfs") {
+		return fileAccessModes
+	}
+	return nil
+}
+
+// getLoadBalancerSupport returns whether the cluster provisions the load balancers for Services of type LoadBalancer.
+// Load balancers are provisioned by the cloud provider or by an add-on like MetalLB. It returns nil if it cannot be determined.
+func (c *ClusterCollector) getLoadBalancerSupport(apiGroups []string) *bool {
+	supported := true
+	unsupported := false
+	services := struct {
+		Items []struct {
+			Spec struct {
+				Type string `yaml:"type"`
+			} `yaml:"spec"`
+			Status struct {
+				LoadBalancer struct {
+					Ingress []interface{} `yaml:"ingress"`
+				} `yaml:"loadBalancer"`
+			} `yaml:"status"`
+		} `yaml:"items"`
+	}{}
+	if yamlOutput, err := exec.Command(c.getClusterCommand(), "get", "services", "--all-namespaces", "-o", "yaml").Output(); err != nil {
+		logrus.Debugf("Unable to get the services in the cluster. Error: %q", err)
+	} else if err := yaml.Unmarshal(yamlOutput, &services); err != nil {
+		logrus.Debugf("Unable to parse the services in the cluster. Error: %q", err)
+	} else {
+		numLoadBalancers := 0
+		for _, service := range services.Items {
+			if service.Spec.Type != "LoadBalancer" {
+				continue
+			}
+			if len(service.Status.LoadBalancer.Ingress) != 0 {
+				return &supported
+			}
+			numLoadBalancers++
+		}
+		if numLoadBalancers != 0 {
+			logrus.Debugf("None of the %d services of type LoadBalancer in the cluster have a load balancer", numLoadBalancers)
+			return &unsupported
+		}
+	}
+	for _, apiGroup := range apiGroups {
+		if apiGroup == "metallb.io" {
+			return &supported
+		}
+	}
+	nodes := struct {
+		Items []struct {
+			Spec struct {
+				ProviderID string `yaml:"providerID"`
+			} `yaml:"spec"`
+		} `yaml:"items"`
+	}{}
+	yamlOutput, err := exec.Command(c.getClusterCommand(), "get", "nodes", "-o", "yaml").Output()
+	if err != nil {
+		logrus.Debugf("Unable to get the nodes in the cluster. Error: %q", err)
+		return nil
+	}
+	if err := yaml.Unmarshal(yamlOutput, &nodes); err != nil || len(nodes.Items) == 0 {
+		logrus.Debugf("Unable to parse the nodes in the cluster. Error: %q", err)
+		return nil
+	}
+	cloudProviders := []string{"aws", "gce", "azure", "ibm", "openstack", "digitalocean", "linode", "oci", "alicloud", "huaweicloud", "hcloud", "k3s"}
+	for _, cloudProvider := range cloudProviders {
+		if strings.HasPrefix(nodes.Items[0].Spec.ProviderID, cloudProvider+"://") {
+			return &supported
+		}
+	}
+	if nodes.Items[0].Spec.ProviderID == "" || strings.HasPrefix(nodes.Items[0].Spec.ProviderID, "kind://") {
+		return &unsupported
+	}
+	return nil
+}
+
+// getSecurityContextConstraints returns the OpenShift security context constraints that the default service account of the namespace can use.
+// The namespace being collected is used, or the namespace of the current context if no namespace is being collected.
+func (c *ClusterCollector) getSecurityContextConstraints(apiGroups []string) []string {
+	if !common.IsPresent(apiGroups, "security.openshift.io") {
+		return nil
+	}
+	sccNames := []string{}
+	if items, err := c.getClasses("scc"); err == nil {
+		sccNames, _ = getClassNames(items)
+	} else {
+		logrus.Debugf("Unable to list the security context constraints. Checking the well known ones. Error: %q", err)
+		sccNames = []string{"restricted-v2", "restricted", "nonroot-v2", "nonroot", "anyuid", "hostmount-anyuid", "privileged"}
+	}
+	namespace := c.getNamespace()
+	serviceAccount := "system:serviceaccount:" + namespace + ":default"
+	usableSCCNames := []string{}
+	for _, sccName := range sccNames {
+		output, err := exec.Command(c.getClusterCommand(), "auth", "can-i", "use", "securitycontextconstraints/"+sccName, "--as", serviceAccount, "--namespace", namespace).Output()
+		answer := strings.TrimSpace(string(output))
+		if err != nil && answer != "no" {
+			logrus.Debugf("Unable to check if the service account '%s' can use the security context constraint '%s' . Error: %q", serviceAccount, sccName, err)
+			return nil
+		}
+		if answer == "yes" {
+			usableSCCNames = append(usableSCCNames, sccName)
+		}
+	}
+	return usableSCCNames
+}
 
-	scArray := fileContents["items"].([]interface{})
-	storageClasses := []string{}
+// getPodSecurityLevel returns the Pod Security Standard level enforced in the namespace being collected, or in the namespace of the current context
+func (c *ClusterCollector) getPodSecurityLevel() string {
+	namespace := c.getNamespace()
+	output, err := exec.Command(c.getClusterCommand(), "get", "namespace", namespace, "-o", `jsonpath={.metadata.labels.pod-security\.kubernetes\.io/enforce}`).Output()
+	if err != nil {
+		logrus.Debugf("Unable to get the Pod Security Standard level of the namespace '%s' . Error: %q", namespace, err)
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
 
-	for _, sc := range scArray {
-		if mapSC, ok := sc.(map[string]interface{}); ok {
-			storageClasses = append(storageClasses, mapSC["metadata"].(map[string]interface{})["name"].(string))
+// getCapabilityPolicies returns the Kyverno and Gatekeeper policies which restrict the capabilities added to the containers, along with the capabilities they allow.
+// The Kyverno policies are recognized using the names of the policies in the Pod Security Standard library of Kyverno.
+func (c *ClusterCollector) getCapabilityPolicies(apiGroups []string) map[string][]string {
+	policies := map[string][]string{}
+	if common.IsPresent(apiGroups, "kyverno.io") {
+		kyvernoPolicies := struct {
+			Items []struct {
+				Metadata struct {
+					Name string `yaml:"name"`
+				} `yaml:"metadata"`
+				Spec struct {
+					ValidationFailureAction string `yaml:"validationFailureAction"`
+				} `yaml:"spec"`
+			} `yaml:"items"`
+		}{}
+		if yamlOutput, err := exec.Command(c.getClusterCommand(), "get", "clusterpolicies.kyverno.io", "-o", "yaml").Output(); err != nil {
+			logrus.Debugf("Unable to get the Kyverno cluster policies. Error: %q", err)
+		} else if err := yaml.Unmarshal(yamlOutput, &kyvernoPolicies); err != nil {
+			logrus.Debugf("Unable to parse the Kyverno cluster policies. Error: %q", err)
 		} else {
-			logrus.Warnf("Unknown type detected in cluster metadata [%T]", mapSC)
+			for _, policy := range kyvernoPolicies.Items {
+				if !strings.EqualFold(policy.Spec.ValidationFailureAction, "enforce") {
+					continue
+				}
+				switch policy.Metadata.Name {
+				case "disallow-capabilities":
+					policies[collecttypes.KyvernoPolicyPrefix+policy.Metadata.Name] = collecttypes.PodSecurityBaselineCapabilities
+				case "disallow-capabilities-strict":
+					policies[collecttypes.KyvernoPolicyPrefix+policy.Metadata.Name] = collecttypes.PodSecurityRestrictedCapabilities
+				}
+			}
 		}
 	}
+	if common.IsPresent(apiGroups, "constraints.gatekeeper.sh") {
+		constraints := struct {
+			Items []struct {
+				Metadata struct {
+					Name string `yaml:"name"`
+				} `yaml:"metadata"`
+				Spec struct {
+					EnforcementAction string `yaml:"enforcementAction"`
+					Parameters        struct {
+						AllowedCapabilities []string `yaml:"allowedCapabilities"`
+					} `yaml:"parameters"`
+				} `yaml:"spec"`
+			} `yaml:"items"`
+		}{}
+		if yamlOutput, err := exec.Command(c.getClusterCommand(), "get", "k8spspcapabilities.constraints.gatekeeper.sh", "-o", "yaml").Output(); err != nil {
+			logrus.Debugf("Unable to get the Gatekeeper capability constraints. Error: %q", err)
+		} else if err := yaml.Unmarshal(yamlOutput, &constraints); err != nil {
+			logrus.Debugf("Unable to parse the Gatekeeper capability constraints. Error: %q", err)
+		} else {
+			for _, constraint := range constraints.Items {
+				if constraint.Spec.EnforcementAction != "" && constraint.Spec.EnforcementAction != "deny" {
+					continue
+				}
+				allowedCapabilities := constraint.Spec.Parameters.AllowedCapabilities
+				if common.IsPresent(allowedCapabilities, "*") {
+					continue
+				}
+				if allowedCapabilities == nil {
+					allowedCapabilities = []string{}
+				}
+				policies[collecttypes.GatekeeperPolicyPrefix+constraint.Metadata.Name] = allowedCapabilities
+			}
+		}
+	}
+	if len(policies) == 0 {
+		return nil
+	}
+	return policies
+}
 
-	return storageClasses, nil
+// getVersionAndGroupsUsingAPI returns the Kubernetes version of the cluster and all the API groups,
+// including the groups added by custom resource definitions
+func (c *ClusterCollector) getVersionAndGroupsUsingAPI() (string, []string, error) {
+	api, err := c.getAPI()
+	if err != nil {
+		return "", nil, err
+	}
+	version, err := api.ServerVersion()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get the server version. Error: %w", err)
+	}
+	groupList, err := api.ServerGroups()
+	if err != nil {
+		return version.GitVersion, nil, fmt.Errorf("failed to get the server groups. Error: %w", err)
+	}
+	groups := []string{}
+	for _, group := range groupList.Groups {
+		if group.Name != "" {
+			groups = append(groups, group.Name)
+		}
+	}
+	sort.Strings(groups)
+	return version.GitVersion, groups, nil
 }
 
 func (c *ClusterCollector) interpretError(cmdOutput string) string {
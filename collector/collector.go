@@ -22,8 +22,20 @@ type Collector interface {
 	GetAnnotations() []string
 }
 
+// OptInCollector is a collector that only runs when it is selected using its annotations, like a collector talking to a local daemon
+type OptInCollector interface {
+	Collector
+	IsOptIn() bool
+}
+
+// IsOptIn returns true if the collector only runs when it is selected using its annotations
+func IsOptIn(c Collector) bool {
+	optInCollector, ok := c.(OptInCollector)
+	return ok && optInCollector.IsOptIn()
+}
+
 // GetCollectors returns different collectors
 func GetCollectors() ([]Collector, error) {
-	collectors := []Collector{new(ClusterCollector), new(ImagesCollector), new(CfAppsCollector), new(CfServicesCollector)}
+	collectors := []Collector{new(ClusterCollector), new(ImagesCollector), new(CfAppsCollector), new(CfServicesCollector), new(DockerContainersCollector), new(NamespaceCollector), new(MetricsCollector)}
 	return collectors, nil
 }
@@ -0,0 +1,240 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package collector
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	sourcetypes "github.com/konveyor/move2kube/collector/sourcetypes"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment/container"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cast"
+)
+
+const (
+	dockerContainersDirName  = "dockercontainers"
+	dockerContainersFileName = "dockercontainers.yaml"
+)
+
+// DockerContainersCollector collects the containers running in a docker daemon.
+// The daemon is chosen by the docker cli, so the current docker context, DOCKER_CONTEXT and DOCKER_HOST are honoured.
+// It only runs when it is selected using its annotations, since it talks to the local daemon.
+type DockerContainersCollector struct {
+}
+
+// IsOptIn returns true since the collector has to be selected using its annotations
+func (c *DockerContainersCollector) IsOptIn() bool {
+	return true
+}
+
+// GetAnnotations returns annotations on which this collector should be invoked
+func (c *DockerContainersCollector) GetAnnotations() []string {
+	annotations := []string{"docker", "dockercontainers"}
+	return annotations
+}
+
+// Collect gets the configuration of the running containers using docker inspect
+func (c *DockerContainersCollector) Collect(inputDirectory string, outputPath string) error {
+	containerIDs, err := getRunningContainerIDs()
+	if err != nil {
+		return err
+	}
+	if len(containerIDs) == 0 {
+		logrus.Infof("No running containers found in the docker daemon")
+		return nil
+	}
+	inspectOutput, err := dockerCommand(append([]string{"container", "inspect"}, containerIDs...)...).Output()
+	if err != nil {
+		return fmt.Errorf("failed to inspect the running containers. Error: %w", err)
+	}
+	inspectedContainers := []sourcetypes.DockerContainer{}
+	if err := json.Unmarshal(inspectOutput, &inspectedContainers); err != nil {
+		return fmt.Errorf("failed to parse the output of docker container inspect. Error: %w", err)
+	}
+	containers := collecttypes.NewDockerContainers()
+	imageConfigs := map[string]*sourcetypes.ContainerConfig{}
+	for _, inspectedContainer := range inspectedContainers {
+		imageConfig, ok := imageConfigs[inspectedContainer.Image]
+		if !ok {
+			imageConfig = getImageConfig(inspectedContainer.Image)
+			imageConfigs[inspectedContainer.Image] = imageConfig
+		}
+		containers.Spec.Containers = append(containers.Spec.Containers, getDockerContainer(inspectedContainer, imageConfig))
+	}
+	outputPath = filepath.Join(outputPath, dockerContainersDirName)
+	if err := os.MkdirAll(outputPath, common.DefaultDirectoryPermission); err != nil {
+		return fmt.Errorf("failed to create the output directory '%s' . Error: %w", outputPath, err)
+	}
+	outputFilePath := filepath.Join(outputPath, dockerContainersFileName)
+	if err := common.WriteYaml(outputFilePath, containers); err != nil {
+		return fmt.Errorf("failed to write the docker containers to the file '%s' . Error: %w", outputFilePath, err)
+	}
+	return nil
+}
+
+// dockerCommand returns the docker cli command for the daemon of the docker context.
+// The docker cli picks the context itself, but it has to be pointed to the podman socket when there is no docker daemon.
+func dockerCommand(args ...string) *exec.Cmd {
+	cmd := exec.Command("docker", args...)
+	if os.Getenv("DOCKER_HOST") == "" && container.GetDockerContext() == "" {
+		if host := container.GetDockerHost(); host != "" {
+			cmd.Env = append(os.Environ(), "DOCKER_HOST="+host)
+		}
+	}
+	return cmd
+}
+
+func getRunningContainerIDs() ([]string, error) {
+	if contextName := container.GetDockerContext(); contextName != "" {
+		logrus.Infof("Collecting the containers from the docker daemon of the docker context %s", contextName)
+	}
+	output, err := dockerCommand("container", "ls", "--quiet", "--no-trunc").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the running containers. Error: %w", err)
+	}
+	return strings.Fields(string(output)), nil
+}
+
+// getImageConfig returns the default config of the image, so that only the settings a container overrides are collected
+func getImageConfig(imageID string) *sourcetypes.ContainerConfig {
+	data, err := getDockerInspectResult(imageID)
+	if err != nil || data == nil {
+		return nil
+	}
+	images := []sourcetypes.DockerImage{}
+	if err := json.Unmarshal(data, &images); err != nil || len(images) == 0 {
+		logrus.Debugf("failed to parse the metadata of the image '%s' . Error: %q", imageID, err)
+		return nil
+	}
+	config := images[0].Config
+	if config.Env == nil && config.Entrypoint == nil && config.Cmd == nil {
+		config = images[0].CConfig
+	}
+	return &config
+}
+
+// getDockerContainer converts the docker inspect output of a container.
+// Settings that are the same as the defaults of the image are left out.
+func getDockerContainer(inspectedContainer sourcetypes.DockerContainer, imageConfig *sourcetypes.ContainerConfig) collecttypes.DockerContainer {
+	if imageConfig == nil {
+		imageConfig = &sourcetypes.ContainerConfig{}
+	}
+	config := inspectedContainer.Config
+	container := collecttypes.DockerContainer{
+		Name:          strings.TrimPrefix(inspectedContainer.Name, "/"),
+		Image:         config.Image,
+		RestartPolicy: inspectedContainer.HostConfig.RestartPolicy.Name,
+		Privileged:    inspectedContainer.HostConfig.Privileged,
+		CapAdd:        inspectedContainer.HostConfig.CapAdd,
+		CapDrop:       inspectedContainer.HostConfig.CapDrop,
+	}
+	if !reflect.DeepEqual(config.Entrypoint, imageConfig.Entrypoint) {
+		container.Entrypoint = config.Entrypoint
+	}
+	if !reflect.DeepEqual(config.Cmd, imageConfig.Cmd) {
+		container.Cmd = config.Cmd
+	}
+	for _, env := range config.Env {
+		if !common.IsPresent(imageConfig.Env, env) {
+			container.Env = append(container.Env, env)
+		}
+	}
+	if config.WorkingDir != imageConfig.WorkingDir {
+		container.WorkingDir = config.WorkingDir
+	}
+	if config.User != imageConfig.User {
+		container.User = config.User
+	}
+	if config.Hostname != "" && !strings.HasPrefix(inspectedContainer.ID, config.Hostname) {
+		container.Hostname = config.Hostname
+	}
+	for key, value := range config.Labels {
+		if imageValue, ok := imageConfig.Labels[key]; ok && imageValue == value {
+			continue
+		}
+		if container.Labels == nil {
+			container.Labels = map[string]string{}
+		}
+		container.Labels[key] = value
+	}
+	container.Ports = getDockerContainerPorts(config.EPorts, inspectedContainer.HostConfig.PortBindings)
+	for _, mount := range inspectedContainer.Mounts {
+		container.Mounts = append(container.Mounts, collecttypes.DockerContainerMount{
+			Type:        mount.Type,
+			Name:        mount.Name,
+			Source:      mount.Source,
+			Destination: mount.Destination,
+			ReadOnly:    !mount.RW,
+		})
+	}
+	for destination := range inspectedContainer.HostConfig.Tmpfs {
+		container.Mounts = append(container.Mounts, collecttypes.DockerContainerMount{Type: "tmpfs", Destination: destination})
+	}
+	sort.SliceStable(container.Mounts, func(i, j int) bool { return container.Mounts[i].Destination < container.Mounts[j].Destination })
+	for network := range inspectedContainer.NetworkSettings.Networks {
+		container.Networks = append(container.Networks, network)
+	}
+	sort.Strings(container.Networks)
+	return container
+}
+
+// getDockerContainerPorts returns the exposed ports of the container along with the host ports they are published on
+func getDockerContainerPorts(exposedPorts map[string]interface{}, portBindings map[string][]sourcetypes.PortBinding) []collecttypes.DockerContainerPort {
+	portKeys := map[string]bool{}
+	for key := range exposedPorts {
+		portKeys[key] = true
+	}
+	for key := range portBindings {
+		portKeys[key] = true
+	}
+	ports := []collecttypes.DockerContainerPort{}
+	for key := range portKeys {
+		portAndProtocol := strings.SplitN(key, "/", 2)
+		containerPort, err := cast.ToInt32E(portAndProtocol[0])
+		if err != nil {
+			logrus.Debugf("failed to parse the container port '%s' . Error: %q", key, err)
+			continue
+		}
+		port := collecttypes.DockerContainerPort{ContainerPort: containerPort, Protocol: "tcp"}
+		if len(portAndProtocol) == 2 {
+			port.Protocol = portAndProtocol[1]
+		}
+		for _, binding := range portBindings[key] {
+			if hostPort, err := cast.ToInt32E(binding.HostPort); err == nil && hostPort != 0 {
+				port.HostPort = hostPort
+				break
+			}
+		}
+		ports = append(ports, port)
+	}
+	sort.Slice(ports, func(i, j int) bool {
+		if ports[i].ContainerPort == ports[j].ContainerPort {
+			return ports[i].Protocol < ports[j].Protocol
+		}
+		return ports[i].ContainerPort < ports[j].ContainerPort
+	})
+	return ports
+}
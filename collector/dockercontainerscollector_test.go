@@ -0,0 +1,75 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package collector
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	sourcetypes "github.com/konveyor/move2kube/collector/sourcetypes"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+)
+
+func TestGetDockerContainer(t *testing.T) {
+	inspectOutput := `[{
+		"Id": "4f1c2e6a9b7d",
+		"Name": "/web",
+		"Image": "sha256:1234",
+		"Config": {
+			"Hostname": "4f1c2e6a9b7d",
+			"User": "1001",
+			"Env": ["PATH=/usr/bin", "MODE=production"],
+			"Cmd": ["nginx", "-g", "daemon off;"],
+			"Image": "nginx:1.23",
+			"WorkingDir": "/",
+			"Labels": {"maintainer": "nginx", "tier": "frontend"},
+			"ExposedPorts": {"80/tcp": {}, "443/tcp": {}}
+		},
+		"HostConfig": {
+			"PortBindings": {"80/tcp": [{"HostIp": "", "HostPort": "8080"}]},
+			"RestartPolicy": {"Name": "unless-stopped"},
+			"Tmpfs": {"/run": ""}
+		},
+		"Mounts": [{"Type": "volume", "Name": "data", "Source": "/var/lib/docker/volumes/data/_data", "Destination": "/data", "RW": true}],
+		"NetworkSettings": {"Networks": {"bridge": {}, "backend": {}}}
+	}]`
+	inspectedContainers := []sourcetypes.DockerContainer{}
+	if err := json.Unmarshal([]byte(inspectOutput), &inspectedContainers); err != nil {
+		t.Fatal(err)
+	}
+	imageConfig := &sourcetypes.ContainerConfig{
+		Env:        []string{"PATH=/usr/bin"},
+		Cmd:        []string{"nginx", "-g", "daemon off;"},
+		WorkingDir: "/",
+		Labels:     map[string]string{"maintainer": "nginx"},
+	}
+	want := collecttypes.DockerContainer{
+		Name:          "web",
+		Image:         "nginx:1.23",
+		Env:           []string{"MODE=production"},
+		User:          "1001",
+		Labels:        map[string]string{"tier": "frontend"},
+		Ports:         []collecttypes.DockerContainerPort{{ContainerPort: 80, HostPort: 8080, Protocol: "tcp"}, {ContainerPort: 443, Protocol: "tcp"}},
+		Mounts:        []collecttypes.DockerContainerMount{{Type: "volume", Name: "data", Source: "/var/lib/docker/volumes/data/_data", Destination: "/data"}, {Type: "tmpfs", Destination: "/run"}},
+		RestartPolicy: "unless-stopped",
+		Networks:      []string{"backend", "bridge"},
+	}
+	if got := getDockerContainer(inspectedContainers[0], imageConfig); !cmp.Equal(got, want) {
+		t.Fatalf("failed to convert the docker inspect output. Difference:\n%s", cmp.Diff(want, got))
+	}
+}
@@ -0,0 +1,195 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/konveyor/move2kube/common"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cast"
+	"golang.org/x/time/rate"
+)
+
+// imageMetadataFetcher fetches the image configs from the registries.
+// Requests to a registry are rate limited and share the same auth token.
+type imageMetadataFetcher struct {
+	cacheDir          string
+	requestsPerSecond float64
+	mutex             sync.Mutex
+	registries        map[string]*registryEndpoint
+}
+
+// registryEndpoint stores the shared state for all the requests to a registry
+type registryEndpoint struct {
+	once      sync.Once
+	transport http.RoundTripper
+	err       error
+}
+
+// rateLimitedTransport waits for the limiter before every request
+type rateLimitedTransport struct {
+	limiter *rate.Limiter
+	base    http.RoundTripper
+}
+
+// RoundTrip implements the http.RoundTripper interface
+func (t *rateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.limiter.Wait(req.Context()); err != nil {
+		return nil, err
+	}
+	return t.base.RoundTrip(req)
+}
+
+func newImageMetadataFetcher(cacheDir string, requestsPerSecond float64) *imageMetadataFetcher {
+	return &imageMetadataFetcher{cacheDir: cacheDir, requestsPerSecond: requestsPerSecond, registries: map[string]*registryEndpoint{}}
+}
+
+// getTransport returns the transport shared by all the requests to the registry of the repository
+func (f *imageMetadataFetcher) getTransport(ctx context.Context, repo name.Repository) (http.RoundTripper, error) {
+	registryName := repo.RegistryStr()
+	f.mutex.Lock()
+	endpoint, ok := f.registries[registryName]
+	if !ok {
+		endpoint = &registryEndpoint{}
+		f.registries[registryName] = endpoint
+	}
+	f.mutex.Unlock()
+	endpoint.once.Do(func() {
+		limit := rate.Limit(f.requestsPerSecond)
+		if f.requestsPerSecond <= 0 {
+			limit = rate.Inf
+		}
+		base := &rateLimitedTransport{limiter: rate.NewLimiter(limit, 1), base: remote.DefaultTransport}
+		auth, err := authn.DefaultKeychain.Resolve(repo.Registry)
+		if err != nil {
+			logrus.Debugf("failed to get the credentials for the registry '%s' . Using anonymous access. Error: %q", registryName, err)
+			auth = authn.Anonymous
+		}
+		// the token is refreshed with the scopes of the other repositories when required, so it is shared by all of them
+		endpoint.transport, endpoint.err = transport.NewWithContext(ctx, repo.Registry, auth, base, []string{repo.Scope(transport.PullScope)})
+		if endpoint.err != nil {
+			endpoint.err = fmt.Errorf("failed to create a transport for the registry '%s' . Error: %w", registryName, endpoint.err)
+		}
+	})
+	return endpoint.transport, endpoint.err
+}
+
+// fetch returns the config of the image. The configs are cached on disk by the digest of the image.
+func (f *imageMetadataFetcher) fetch(ctx context.Context, imageName string) (*v1.ConfigFile, error) {
+	ref, err := name.ParseReference(imageName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the image name '%s' . Error: %w", imageName, err)
+	}
+	t, err := f.getTransport(ctx, ref.Context())
+	if err != nil {
+		return nil, err
+	}
+	options := []remote.Option{remote.WithTransport(t), remote.WithContext(ctx)}
+	desc, err := remote.Head(ref, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the manifest descriptor of the image '%s' . Error: %w", imageName, err)
+	}
+	cachePath := ""
+	if f.cacheDir != "" {
+		cachePath = filepath.Join(f.cacheDir, desc.Digest.Algorithm, desc.Digest.Hex+".json")
+		if configFile, err := readCachedImageConfig(cachePath); err == nil {
+			logrus.Debugf("using the cached config for the image '%s' with the digest '%s'", imageName, desc.Digest)
+			return configFile, nil
+		}
+	}
+	img, err := remote.Image(ref.Context().Digest(desc.Digest.String()), options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the image '%s' . Error: %w", imageName, err)
+	}
+	configFile, err := img.ConfigFile()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the config of the image '%s' . Error: %w", imageName, err)
+	}
+	if cachePath != "" {
+		if err := writeCachedImageConfig(cachePath, configFile); err != nil {
+			logrus.Debugf("failed to cache the config of the image '%s' . Error: %q", imageName, err)
+		}
+	}
+	return configFile, nil
+}
+
+func readCachedImageConfig(cachePath string) (*v1.ConfigFile, error) {
+	configBytes, err := os.ReadFile(cachePath)
+	if err != nil {
+		return nil, err
+	}
+	configFile := &v1.ConfigFile{}
+	if err := json.Unmarshal(configBytes, configFile); err != nil {
+		return nil, err
+	}
+	return configFile, nil
+}
+
+func writeCachedImageConfig(cachePath string, configFile *v1.ConfigFile) error {
+	configBytes, err := json.Marshal(configFile)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(cachePath), common.DefaultDirectoryPermission); err != nil {
+		return err
+	}
+	return os.WriteFile(cachePath, configBytes, common.DefaultFilePermission)
+}
+
+// getImageInfoFromConfig converts the config fetched from the registry into the image info
+func getImageInfoFromConfig(imageName string, configFile *v1.ConfigFile) collecttypes.ImageInfo {
+	imageInfo := collecttypes.NewImageInfo()
+	imageInfo.Spec.Tags = []string{imageName}
+	imageInfo.Spec.User = configFile.Config.User
+	imageInfo.Spec.UserID = -1
+	if userID, err := getImageUserID(configFile.Config.User); err == nil {
+		imageInfo.Spec.UserID = userID
+	} else {
+		logrus.Debugf("UserID not available in image metadata for [%s]", imageName)
+	}
+	imageInfo.Spec.Entrypoint = configFile.Config.Entrypoint
+	imageInfo.Spec.Cmd = configFile.Config.Cmd
+	imageInfo.Spec.Env = configFile.Config.Env
+	if configFile.Config.WorkingDir != "" {
+		imageInfo.Spec.AccessedDirs = append(imageInfo.Spec.AccessedDirs, configFile.Config.WorkingDir)
+	}
+	for port := range configFile.Config.ExposedPorts {
+		portNumber, err := cast.ToInt32E(strings.Split(port, "/")[0])
+		if err != nil {
+			logrus.Debugf("PortNumber not available in image metadata for [%s]", imageName)
+			continue
+		}
+		imageInfo.Spec.PortsToExpose = append(imageInfo.Spec.PortsToExpose, portNumber)
+	}
+	sort.Slice(imageInfo.Spec.PortsToExpose, func(i, j int) bool { return imageInfo.Spec.PortsToExpose[i] < imageInfo.Spec.PortsToExpose[j] })
+	return imageInfo
+}
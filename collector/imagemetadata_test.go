@@ -0,0 +1,86 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package collector
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+func TestImageMetadataFetcher(t *testing.T) {
+	blobRequests := int32(0)
+	registryHandler := registry.New()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/blobs/") && r.Method == http.MethodGet {
+			atomic.AddInt32(&blobRequests, 1)
+		}
+		registryHandler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	img, err := random.Image(16, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	configFile, err := img.ConfigFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	configFile.Config.User = "1001"
+	configFile.Config.WorkingDir = "/app"
+	configFile.Config.ExposedPorts = map[string]struct{}{"8080/tcp": {}}
+	if img, err = mutate.ConfigFile(img, configFile); err != nil {
+		t.Fatal(err)
+	}
+	imageName := strings.TrimPrefix(server.URL, "http://") + "/myproject/app:latest"
+	ref, err := name.ParseReference(imageName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Write(ref, img); err != nil {
+		t.Fatal(err)
+	}
+	atomic.StoreInt32(&blobRequests, 0)
+
+	fetcher := newImageMetadataFetcher(t.TempDir(), 0)
+	for i := 0; i < 2; i++ {
+		actual, err := fetcher.fetch(context.Background(), imageName)
+		if err != nil {
+			t.Fatalf("failed to fetch the image config. Error: %q", err)
+		}
+		imageInfo := getImageInfoFromConfig(imageName, actual)
+		if imageInfo.Spec.UserID != 1001 || len(imageInfo.Spec.AccessedDirs) != 1 || imageInfo.Spec.AccessedDirs[0] != "/app" {
+			t.Fatalf("the image info does not match the image config. Actual: %+v", imageInfo.Spec)
+		}
+		if len(imageInfo.Spec.PortsToExpose) != 1 || imageInfo.Spec.PortsToExpose[0] != 8080 {
+			t.Fatalf("expected the port 8080 to be exposed. Actual: %+v", imageInfo.Spec.PortsToExpose)
+		}
+	}
+	if blobRequests != 1 {
+		t.Fatalf("expected the image config to be fetched once and then read from the cache. Actual: %d", blobRequests)
+	}
+}
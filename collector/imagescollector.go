@@ -17,12 +17,15 @@
 package collector
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
 
 	sourcetypes "github.com/konveyor/move2kube/collector/sourcetypes"
 	"github.com/konveyor/move2kube/common"
@@ -41,7 +44,7 @@ func (c ImagesCollector) GetAnnotations() []string {
 	return annotations
 }
 
-//Collect gets the image metadata using docker inspect
+//Collect gets the image metadata using docker inspect or from the image registries
 func (c *ImagesCollector) Collect(inputDirectory string, outputPath string) error {
 	//Creating the output sub-directory if it does not exist
 	outputPath = filepath.Join(outputPath, "images")
@@ -55,43 +58,84 @@ func (c *ImagesCollector) Collect(inputDirectory string, outputPath string) erro
 		return err
 	}
 	logrus.Debugf("Images : %s", imageNames)
-	for _, imageName := range imageNames {
-		imagedata, err := getDockerInspectResult(imageName)
-		if err != nil {
+	imageInfos := collectImageInfos(context.Background(), imageNames)
+	for _, imageInfo := range imageInfos {
+		if imageInfo == nil {
 			continue
 		}
-		if imagedata != nil {
-			imageInfo := getImageInfo(imagedata)
-			shortesttag := ""
-			for _, tag := range imageInfo.Spec.Tags {
-				if shortesttag == "" {
+		shortesttag := ""
+		for _, tag := range imageInfo.Spec.Tags {
+			if shortesttag == "" {
+				shortesttag = tag
+			} else {
+				if len(shortesttag) > len(tag) {
 					shortesttag = tag
-				} else {
-					if len(shortesttag) > len(tag) {
-						shortesttag = tag
-					}
 				}
 			}
-			imagefile := filepath.Join(outputPath, common.NormalizeForFilename(shortesttag)+".yaml")
-			err := common.WriteYaml(imagefile, imageInfo)
-			if err != nil {
-				logrus.Errorf("Unable to write file %s : %s", imagefile, err)
-			}
+		}
+		imagefile := filepath.Join(outputPath, common.NormalizeForFilename(shortesttag)+".yaml")
+		err := common.WriteYaml(imagefile, imageInfo)
+		if err != nil {
+			logrus.Errorf("Unable to write file %s : %s", imagefile, err)
 		}
 	}
 
 	return nil
 }
 
+// collectImageInfos collects the metadata of the images concurrently.
+// Images that are not available locally are looked up in their registries.
+// The returned list has the same order as the image names and contains nil for images whose metadata could not be collected.
+func collectImageInfos(ctx context.Context, imageNames []string) []*collecttypes.ImageInfo {
+	imageInfos := make([]*collecttypes.ImageInfo, len(imageNames))
+	fetcher := newImageMetadataFetcher(common.ImageMetadataCacheDir, common.RegistryRequestsPerSecond)
+	workers := common.MaxParallelImageLookups
+	if workers < 1 {
+		workers = 1
+	}
+	semaphore := make(chan struct{}, workers)
+	wg := sync.WaitGroup{}
+	seen := map[string]bool{}
+	for i, imageName := range imageNames {
+		if imageName == "" || seen[imageName] {
+			continue
+		}
+		seen[imageName] = true
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(i int, imageName string) {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+			imagedata, err := getDockerInspectResult(imageName)
+			if err == nil && imagedata != nil {
+				imageInfo := getImageInfo(imagedata)
+				imageInfos[i] = &imageInfo
+				return
+			}
+			configFile, err := fetcher.fetch(ctx, imageName)
+			if err != nil {
+				logrus.Warnf("Unable to get the metadata of the image [%s] from the registry : %s", imageName, err)
+				return
+			}
+			imageInfo := getImageInfoFromConfig(imageName, configFile)
+			imageInfos[i] = &imageInfo
+		}(i, imageName)
+	}
+	wg.Wait()
+	return imageInfos
+}
+
 func getDockerInspectResult(imageName string) ([]byte, error) {
-	cmd := exec.Command("docker", "inspect", imageName)
+	cmd := dockerCommand("inspect", imageName)
 	jsonOutput, err := cmd.CombinedOutput()
 	if err != nil {
 		if strings.Contains(string(jsonOutput), "permission denied") {
 			logrus.Warnf("Error while running docker-inspect due to lack of permissions")
 			logrus.Warnf("Please refer to [https://docs.docker.com/engine/install/linux-postinstall/] to fix this issue")
 		} else if strings.Contains(string(jsonOutput), "No such object") {
-			logrus.Warnf("Image [%s] not available in local image repo. Run \"docker pull %s\"", imageName, imageName)
+			logrus.Debugf("Image [%s] not available in local image repo. Looking it up in the registry.", imageName)
 			return nil, nil
 		} else {
 			logrus.Warnf("Error while running docker-inspect: %s", err)
@@ -109,14 +153,23 @@ func getImageInfo(data []byte) collecttypes.ImageInfo {
 		logrus.Errorf("Unable to unmarshal image info : %s", err)
 	}
 	for _, image := range imgLayerInfo {
+		config := image.Config
+		if config.Env == nil && config.Entrypoint == nil && config.Cmd == nil {
+			// older versions of docker only fill the container config
+			config = image.CConfig
+		}
 		imageInfo.Spec.Tags = image.RepoTags
-		imageInfo.Spec.UserID, err = cast.ToIntE(image.CConfig.User)
+		imageInfo.Spec.User = config.User
+		imageInfo.Spec.UserID, err = getImageUserID(config.User)
 		if err != nil {
 			logrus.Debugf("UserID not available in image metadata for [%s]", image.RepoTags[0])
 			imageInfo.Spec.UserID = -1
 		}
-		imageInfo.Spec.AccessedDirs = append(imageInfo.Spec.AccessedDirs, image.CConfig.WorkingDir)
-		for key := range image.CConfig.EPorts {
+		imageInfo.Spec.Entrypoint = config.Entrypoint
+		imageInfo.Spec.Cmd = config.Cmd
+		imageInfo.Spec.Env = config.Env
+		imageInfo.Spec.AccessedDirs = append(imageInfo.Spec.AccessedDirs, config.WorkingDir)
+		for key := range config.EPorts {
 			regex := regexp.MustCompile("[0-9]+")
 			portNumber, err := cast.ToInt32E(string(regex.FindAll([]byte(key), -1)[0]))
 			if err != nil {
@@ -129,6 +182,15 @@ func getImageInfo(data []byte) collecttypes.ImageInfo {
 	return imageInfo
 }
 
+// getImageUserID returns the numeric user id from the user specified in the image config.
+// The user can be of the form user, uid, user:group or uid:gid
+func getImageUserID(user string) (int, error) {
+	if user == "" {
+		return -1, fmt.Errorf("the image does not specify a user")
+	}
+	return cast.ToIntE(strings.Split(user, ":")[0])
+}
+
 func getImageNames(inputPath string) ([]string, error) {
 	if inputPath == "" {
 		return getAllImageNames()
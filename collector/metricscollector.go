@@ -0,0 +1,325 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package collector
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	units "github.com/docker/go-units"
+	"github.com/konveyor/move2kube/common"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cast"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	metricsDirName = "metrics"
+	// metricsSamples is the number of times the resource usage is sampled
+	metricsSamples = 5
+	// metricsSampleInterval is the time between two samples
+	metricsSampleInterval = 5 * time.Second
+	// composeServiceLabel is the label docker compose adds to the containers of a service
+	composeServiceLabel = "com.docker.compose.service"
+	// podTemplateHashLabel is the label a deployment adds to the pods and the name of its replicasets
+	podTemplateHashLabel = "pod-template-hash"
+)
+
+// MetricsCollector samples the resource usage of the containers running in a docker daemon
+// and of the pods in a Kubernetes namespace using the metrics server.
+type MetricsCollector struct {
+}
+
+// metricsSampler samples the resource usage of all the containers of a single source
+type metricsSampler struct {
+	source    string
+	namespace string
+	sample    func() ([]containerUsage, error)
+}
+
+// containerUsage is a single sample of the resource usage of a container
+type containerUsage struct {
+	workload  string
+	container string
+	// cpu is in millicores
+	cpu int64
+	// memory is in bytes
+	memory int64
+}
+
+// usageTotals accumulates the samples of a container
+type usageTotals struct {
+	samples  int64
+	cpu      int64
+	memory   int64
+	metrics  collecttypes.ContainerMetrics
+	position int
+}
+
+// GetAnnotations returns annotations on which this collector should be invoked
+func (c *MetricsCollector) GetAnnotations() []string {
+	return []string{"metrics", "docker", "k8s"}
+}
+
+// Collect samples the resource usage a few times and stores the average and the peak usage of every container
+func (c *MetricsCollector) Collect(inputPath string, outputPath string) error {
+	samplers := []metricsSampler{}
+	if sampler, err := getDockerMetricsSampler(); err != nil {
+		logrus.Debugf("Skipping the docker metrics. Error: %q", err)
+	} else {
+		samplers = append(samplers, sampler)
+	}
+	if sampler, err := getKubernetesMetricsSampler(); err != nil {
+		logrus.Debugf("Skipping the Kubernetes metrics. Error: %q", err)
+	} else {
+		samplers = append(samplers, sampler)
+	}
+	if len(samplers) == 0 {
+		return fmt.Errorf("no source of metrics found. Either the docker daemon must have running containers or the cluster must have a metrics server")
+	}
+	logrus.Infof("Sampling the resource usage %d times, every %s", metricsSamples, metricsSampleInterval)
+	totals := make([]map[string]*usageTotals, len(samplers))
+	successfulSamples := make([]int, len(samplers))
+	for i := 0; i < metricsSamples; i++ {
+		if i != 0 {
+			time.Sleep(metricsSampleInterval)
+		}
+		for si, sampler := range samplers {
+			usages, err := sampler.sample()
+			if err != nil {
+				logrus.Warnf("Failed to sample the resource usage from %s . Error: %q", sampler.source, err)
+				continue
+			}
+			successfulSamples[si]++
+			if totals[si] == nil {
+				totals[si] = map[string]*usageTotals{}
+			}
+			for _, usage := range usages {
+				key := usage.workload + "/" + usage.container
+				total, ok := totals[si][key]
+				if !ok {
+					total = &usageTotals{
+						metrics:  collecttypes.ContainerMetrics{Workload: usage.workload, Container: usage.container},
+						position: len(totals[si]),
+					}
+					totals[si][key] = total
+				}
+				total.samples++
+				total.cpu += usage.cpu
+				total.memory += usage.memory
+				if usage.cpu > total.metrics.CPU.Peak {
+					total.metrics.CPU.Peak = usage.cpu
+				}
+				if usage.memory > total.metrics.Memory.Peak {
+					total.metrics.Memory.Peak = usage.memory
+				}
+			}
+		}
+	}
+	outputPath = filepath.Join(outputPath, metricsDirName)
+	for si, sampler := range samplers {
+		if successfulSamples[si] == 0 {
+			continue
+		}
+		metrics := collecttypes.NewResourceMetrics(sampler.source)
+		metrics.Spec.Namespace = sampler.namespace
+		metrics.Spec.Samples = successfulSamples[si]
+		metrics.Spec.Containers = getContainerMetrics(totals[si])
+		if err := os.MkdirAll(outputPath, common.DefaultDirectoryPermission); err != nil {
+			return fmt.Errorf("failed to create the output directory '%s' . Error: %w", outputPath, err)
+		}
+		outputFilePath := filepath.Join(outputPath, sampler.source+".yaml")
+		if err := common.WriteYaml(outputFilePath, metrics); err != nil {
+			return fmt.Errorf("failed to write the resource metrics to the file '%s' . Error: %w", outputFilePath, err)
+		}
+	}
+	return nil
+}
+
+// getContainerMetrics computes the averages, in the order in which the containers were first seen
+func getContainerMetrics(totals map[string]*usageTotals) []collecttypes.ContainerMetrics {
+	ordered := []*usageTotals{}
+	for _, total := range totals {
+		ordered = append(ordered, total)
+	}
+	sort.Slice(ordered, func(i, j int) bool { return ordered[i].position < ordered[j].position })
+	containerMetrics := []collecttypes.ContainerMetrics{}
+	for _, total := range ordered {
+		total.metrics.CPU.Average = total.cpu / total.samples
+		total.metrics.Memory.Average = total.memory / total.samples
+		containerMetrics = append(containerMetrics, total.metrics)
+	}
+	return containerMetrics
+}
+
+// getDockerMetricsSampler returns a sampler that uses docker stats
+func getDockerMetricsSampler() (metricsSampler, error) {
+	containerIDs, err := getRunningContainerIDs()
+	if err != nil {
+		return metricsSampler{}, err
+	}
+	if len(containerIDs) == 0 {
+		return metricsSampler{}, fmt.Errorf("no running containers found in the docker daemon")
+	}
+	output, err := dockerCommand(append([]string{"container", "inspect", "--format", `{{.Name}} {{index .Config.Labels "` + composeServiceLabel + `"}}`}, containerIDs...)...).Output()
+	if err != nil {
+		return metricsSampler{}, fmt.Errorf("failed to get the compose services of the running containers. Error: %w", err)
+	}
+	workloads := map[string]string{}
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 {
+			workloads[strings.TrimPrefix(fields[0], "/")] = fields[1]
+		}
+	}
+	return metricsSampler{
+		source: collecttypes.DockerMetricsSource,
+		sample: func() ([]containerUsage, error) {
+			output, err := dockerCommand("stats", "--no-stream", "--format", "{{json .}}").Output()
+			if err != nil {
+				return nil, fmt.Errorf("failed to get the stats of the running containers. Error: %w", err)
+			}
+			return parseDockerStats(output, workloads), nil
+		},
+	}, nil
+}
+
+// parseDockerStats parses the output of docker stats.
+// The containers that are not part of a compose service are their own workload.
+func parseDockerStats(output []byte, workloads map[string]string) []containerUsage {
+	usages := []containerUsage{}
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		stats := struct {
+			Name     string
+			CPUPerc  string
+			MemUsage string
+		}{}
+		if err := json.Unmarshal(scanner.Bytes(), &stats); err != nil {
+			logrus.Debugf("Failed to parse the docker stats line '%s' . Error: %q", scanner.Text(), err)
+			continue
+		}
+		cpuPercent, err := cast.ToFloat64E(strings.TrimSuffix(stats.CPUPerc, "%"))
+		if err != nil {
+			logrus.Debugf("Failed to parse the cpu usage '%s' of the container '%s' . Error: %q", stats.CPUPerc, stats.Name, err)
+			continue
+		}
+		// the memory usage is of the form "1.5MiB / 7.6GiB"
+		memory, err := units.RAMInBytes(strings.TrimSpace(strings.Split(stats.MemUsage, "/")[0]))
+		if err != nil {
+			logrus.Debugf("Failed to parse the memory usage '%s' of the container '%s' . Error: %q", stats.MemUsage, stats.Name, err)
+			continue
+		}
+		workload := workloads[stats.Name]
+		if workload == "" {
+			workload = stats.Name
+		}
+		usages = append(usages, containerUsage{
+			workload:  workload,
+			container: stats.Name,
+			// 100% is one core
+			cpu:    int64(cpuPercent * 10),
+			memory: memory,
+		})
+	}
+	return usages
+}
+
+// getKubernetesMetricsSampler returns a sampler that uses kubectl top, if the cluster has a metrics server
+func getKubernetesMetricsSampler() (metricsSampler, error) {
+	clusterCollector := new(ClusterCollector)
+	cmd := clusterCollector.getClusterCommand()
+	if cmd == "" {
+		return metricsSampler{}, fmt.Errorf("no kubectl or oc in path")
+	}
+	namespace := clusterCollector.getNamespace()
+	sample := func() ([]containerUsage, error) {
+		output, err := exec.Command(cmd, "top", "pod", "--containers", "--no-headers", "--namespace", namespace).Output()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the resource usage of the pods in the namespace '%s' using the metrics server. Error: %w", namespace, err)
+		}
+		podsOutput, err := exec.Command(cmd, "get", "pods", "--namespace", namespace, "--output", "json").Output()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the pods in the namespace '%s' . Error: %w", namespace, err)
+		}
+		pods := corev1.PodList{}
+		if err := json.Unmarshal(podsOutput, &pods); err != nil {
+			return nil, fmt.Errorf("failed to parse the pods in the namespace '%s' . Error: %w", namespace, err)
+		}
+		workloads := map[string]string{}
+		for _, pod := range pods.Items {
+			workloads[pod.Name] = getPodWorkload(pod)
+		}
+		return parseKubernetesTop(output, workloads), nil
+	}
+	if _, err := sample(); err != nil {
+		return metricsSampler{}, err
+	}
+	return metricsSampler{source: collecttypes.KubernetesMetricsSource, namespace: namespace, sample: sample}, nil
+}
+
+// parseKubernetesTop parses the output of kubectl top pod --containers --no-headers
+func parseKubernetesTop(output []byte, workloads map[string]string) []containerUsage {
+	usages := []containerUsage{}
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 4 {
+			continue
+		}
+		podName, containerName := fields[0], fields[1]
+		cpu, err := resource.ParseQuantity(fields[2])
+		if err != nil {
+			logrus.Debugf("Failed to parse the cpu usage '%s' of the container '%s' in the pod '%s' . Error: %q", fields[2], containerName, podName, err)
+			continue
+		}
+		memory, err := resource.ParseQuantity(fields[3])
+		if err != nil {
+			logrus.Debugf("Failed to parse the memory usage '%s' of the container '%s' in the pod '%s' . Error: %q", fields[3], containerName, podName, err)
+			continue
+		}
+		workload, ok := workloads[podName]
+		if !ok {
+			workload = podName
+		}
+		usages = append(usages, containerUsage{workload: workload, container: containerName, cpu: cpu.MilliValue(), memory: memory.Value()})
+	}
+	return usages
+}
+
+// getPodWorkload returns the name of the deployment, statefulset, daemonset etc. that controls the pod
+func getPodWorkload(pod corev1.Pod) string {
+	for _, owner := range pod.OwnerReferences {
+		if owner.Controller == nil || !*owner.Controller {
+			continue
+		}
+		if hash, ok := pod.Labels[podTemplateHashLabel]; ok && owner.Kind == "ReplicaSet" {
+			return strings.TrimSuffix(owner.Name, "-"+hash)
+		}
+		return owner.Name
+	}
+	return pod.Name
+}
@@ -0,0 +1,78 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package collector
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParseDockerStats(t *testing.T) {
+	output := `{"BlockIO":"0B / 0B","CPUPerc":"12.50%","Container":"4f1c2e6a9b7d","ID":"4f1c2e6a9b7d","MemPerc":"0.80%","MemUsage":"64MiB / 7.6GiB","Name":"app-web-1","NetIO":"1kB / 0B","PIDs":"5"}
+{"BlockIO":"0B / 0B","CPUPerc":"0.00%","Container":"8a2b","ID":"8a2b","MemPerc":"0.01%","MemUsage":"1.5MiB / 7.6GiB","Name":"redis","NetIO":"0B / 0B","PIDs":"4"}
+`
+	usages := parseDockerStats([]byte(output), map[string]string{"app-web-1": "web"})
+	want := []containerUsage{
+		{workload: "web", container: "app-web-1", cpu: 125, memory: 64 * 1024 * 1024},
+		{workload: "redis", container: "redis", cpu: 0, memory: 1024 * 1024 * 3 / 2},
+	}
+	if diff := cmp.Diff(want, usages, cmp.AllowUnexported(containerUsage{})); diff != "" {
+		t.Fatalf("unexpected docker stats usage. Difference:\n%s", diff)
+	}
+}
+
+func TestParseKubernetesTop(t *testing.T) {
+	output := `web-5d8f7c9b4-x2x7k   web       3m    21Mi
+web-5d8f7c9b4-x2x7k   sidecar   1m    8Mi
+db-0                  postgres  250m  1Gi
+`
+	usages := parseKubernetesTop([]byte(output), map[string]string{"web-5d8f7c9b4-x2x7k": "web", "db-0": "db"})
+	want := []containerUsage{
+		{workload: "web", container: "web", cpu: 3, memory: 21 * 1024 * 1024},
+		{workload: "web", container: "sidecar", cpu: 1, memory: 8 * 1024 * 1024},
+		{workload: "db", container: "postgres", cpu: 250, memory: 1024 * 1024 * 1024},
+	}
+	if diff := cmp.Diff(want, usages, cmp.AllowUnexported(containerUsage{})); diff != "" {
+		t.Fatalf("unexpected kubectl top usage. Difference:\n%s", diff)
+	}
+}
+
+func TestGetPodWorkload(t *testing.T) {
+	controller := true
+	pod := corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:            "web-5d8f7c9b4-x2x7k",
+		Labels:          map[string]string{podTemplateHashLabel: "5d8f7c9b4"},
+		OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "web-5d8f7c9b4", Controller: &controller}},
+	}}
+	if workload := getPodWorkload(pod); workload != "web" {
+		t.Fatalf("expected the deployment of the pod. Actual: %s", workload)
+	}
+	pod = corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:            "db-0",
+		OwnerReferences: []metav1.OwnerReference{{Kind: "StatefulSet", Name: "db", Controller: &controller}},
+	}}
+	if workload := getPodWorkload(pod); workload != "db" {
+		t.Fatalf("expected the statefulset of the pod. Actual: %s", workload)
+	}
+	pod = corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "debug"}}
+	if workload := getPodWorkload(pod); workload != "debug" {
+		t.Fatalf("expected the pod without an owner to be its own workload. Actual: %s", workload)
+	}
+}
@@ -0,0 +1,160 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package collector
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	namespacesDirName = "namespaces"
+	// namespaceResources are the resources collected from the namespace
+	namespaceResources = "deployments,services,configmaps,secrets,ingresses"
+)
+
+var (
+	// clusterAssignedMetadataFields are set by the cluster and must not be applied to another cluster
+	clusterAssignedMetadataFields = []string{"uid", "resourceVersion", "generation", "creationTimestamp", "deletionTimestamp", "managedFields", "selfLink"}
+	// clusterAssignedAnnotations are added by the cluster and the cli tools
+	clusterAssignedAnnotations = []string{"kubectl.kubernetes.io/last-applied-configuration", "deployment.kubernetes.io/revision"}
+	// skippedSecretTypes are the types of secrets that are created by the cluster or other tools
+	skippedSecretTypes = []string{"kubernetes.io/service-account-token", "helm.sh/release.v1"}
+)
+
+// NamespaceCollector takes a snapshot of the workloads in a Kubernetes namespace.
+// The snapshot is written as Kubernetes yamls and does not go into the IR. When the collected data is in the source directory,
+// the KubernetesVersionChanger transformer detects the yamls and converts them for the target cluster,
+// and the Parameterizer transformer re-emits them as Helm charts, Kustomize overlays and OpenShift templates.
+type NamespaceCollector struct {
+}
+
+// GetAnnotations returns annotations on which this collector should be invoked
+func (c *NamespaceCollector) GetAnnotations() []string {
+	return []string{"k8s", "namespace"}
+}
+
+// Collect gets the workloads in the namespace by querying the cluster. Assumes that the authentication with cluster is already done.
+// The objects are stripped of the fields assigned by the cluster, so that they can be applied to another cluster.
+func (c *NamespaceCollector) Collect(inputPath string, outputPath string) error {
+	namespace := common.CollectNamespace
+	if namespace == "" {
+		logrus.Debugf("No namespace specified. Skipping the namespace snapshot.")
+		return nil
+	}
+	cmd := new(ClusterCollector).getClusterCommand()
+	if cmd == "" {
+		return fmt.Errorf("no kubectl or oc in path. Add kubectl to path and rerun to collect the namespace '%s'", namespace)
+	}
+	jsonOutput, err := exec.Command(cmd, "get", namespaceResources, "--namespace", namespace, "--output", "json").Output()
+	if err != nil {
+		return fmt.Errorf("failed to get the objects in the namespace '%s' . Error: %w", namespace, err)
+	}
+	snapshotPath, err := writeNamespaceSnapshot(namespace, jsonOutput, outputPath)
+	if err != nil {
+		return err
+	}
+	logrus.Infof("The snapshot of the namespace '%s' is at '%s' . Keep it in the source directory to transform it using the Kubernetes yamls transformers.", namespace, snapshotPath)
+	return nil
+}
+
+// writeNamespaceSnapshot writes the objects in the json output of kubectl get to the namespaces directory in the output path.
+// It returns the directory containing the snapshot.
+func writeNamespaceSnapshot(namespace string, jsonOutput []byte, outputPath string) (string, error) {
+	objs := unstructured.UnstructuredList{}
+	if err := objs.UnmarshalJSON(jsonOutput); err != nil {
+		return "", fmt.Errorf("failed to parse the objects in the namespace '%s' . Error: %w", namespace, err)
+	}
+	outputPath = filepath.Join(outputPath, namespacesDirName, common.NormalizeForFilename(namespace))
+	if err := os.MkdirAll(outputPath, common.DefaultDirectoryPermission); err != nil {
+		return "", fmt.Errorf("failed to create the output directory '%s' . Error: %w", outputPath, err)
+	}
+	numSecrets := 0
+	for _, obj := range objs.Items {
+		if !cleanNamespaceObject(&obj) {
+			logrus.Debugf("Skipping the %s '%s' since it is managed by the cluster", obj.GetKind(), obj.GetName())
+			continue
+		}
+		if obj.GetKind() == "Secret" {
+			numSecrets++
+		}
+		objPath := filepath.Join(outputPath, common.NormalizeForFilename(strings.ToLower(obj.GetKind())+"-"+obj.GetName())+".yaml")
+		if err := common.WriteYaml(objPath, obj.Object); err != nil {
+			logrus.Errorf("failed to write the %s '%s' to the file '%s' . Error: %q", obj.GetKind(), obj.GetName(), objPath, err)
+		}
+	}
+	if numSecrets > 0 {
+		logrus.Warnf("The snapshot of the namespace '%s' at '%s' contains %d secrets. Handle it with care.", namespace, outputPath, numSecrets)
+	}
+	return outputPath, nil
+}
+
+// cleanNamespaceObject removes the fields that are assigned by the cluster.
+// It returns false if the object is created by the cluster or a controller and should not be collected.
+func cleanNamespaceObject(obj *unstructured.Unstructured) bool {
+	if len(obj.GetOwnerReferences()) != 0 {
+		return false
+	}
+	switch obj.GetKind() {
+	case "ConfigMap":
+		if obj.GetName() == "kube-root-ca.crt" || obj.GetName() == "openshift-service-ca.crt" {
+			return false
+		}
+	case "Secret":
+		secretType, _, _ := unstructured.NestedString(obj.Object, "type")
+		if common.IsPresent(skippedSecretTypes, secretType) {
+			return false
+		}
+	case "Service":
+		if obj.GetName() == "kubernetes" && obj.GetNamespace() == "default" {
+			return false
+		}
+		unstructured.RemoveNestedField(obj.Object, "spec", "clusterIP")
+		unstructured.RemoveNestedField(obj.Object, "spec", "clusterIPs")
+		unstructured.RemoveNestedField(obj.Object, "spec", "healthCheckNodePort")
+		if ports, ok, _ := unstructured.NestedSlice(obj.Object, "spec", "ports"); ok {
+			for _, port := range ports {
+				if port, ok := port.(map[string]interface{}); ok {
+					delete(port, "nodePort")
+				}
+			}
+			_ = unstructured.SetNestedSlice(obj.Object, ports, "spec", "ports")
+		}
+	}
+	for _, field := range clusterAssignedMetadataFields {
+		unstructured.RemoveNestedField(obj.Object, "metadata", field)
+	}
+	unstructured.RemoveNestedField(obj.Object, "metadata", "namespace")
+	annotations := obj.GetAnnotations()
+	for _, annotation := range clusterAssignedAnnotations {
+		delete(annotations, annotation)
+	}
+	if len(annotations) == 0 {
+		unstructured.RemoveNestedField(obj.Object, "metadata", "annotations")
+	} else {
+		obj.SetAnnotations(annotations)
+	}
+	unstructured.RemoveNestedField(obj.Object, "status")
+	return true
+}
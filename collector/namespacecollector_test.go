@@ -0,0 +1,174 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package collector
+
+import (
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/transformer/kubernetes/k8sschema"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const testNamespaceOutput = `{
+	"apiVersion": "v1",
+	"kind": "List",
+	"items": [
+		{
+			"apiVersion": "apps/v1",
+			"kind": "Deployment",
+			"metadata": {
+				"name": "web",
+				"namespace": "shop",
+				"uid": "1234",
+				"resourceVersion": "42",
+				"generation": 3,
+				"creationTimestamp": "2023-01-01T00:00:00Z",
+				"annotations": {"deployment.kubernetes.io/revision": "3"},
+				"labels": {"app": "web"}
+			},
+			"spec": {
+				"selector": {"matchLabels": {"app": "web"}},
+				"template": {
+					"metadata": {"labels": {"app": "web"}},
+					"spec": {"containers": [{"name": "web", "image": "nginx:1.25"}]}
+				}
+			},
+			"status": {"replicas": 1}
+		},
+		{
+			"apiVersion": "apps/v1",
+			"kind": "ReplicaSet",
+			"metadata": {
+				"name": "web-5d4f8",
+				"namespace": "shop",
+				"ownerReferences": [{"apiVersion": "apps/v1", "kind": "Deployment", "name": "web", "uid": "1234"}]
+			}
+		},
+		{
+			"apiVersion": "v1",
+			"kind": "Service",
+			"metadata": {
+				"name": "web",
+				"namespace": "shop",
+				"annotations": {"kubectl.kubernetes.io/last-applied-configuration": "{}", "team": "shop"}
+			},
+			"spec": {
+				"type": "NodePort",
+				"clusterIP": "10.0.0.10",
+				"clusterIPs": ["10.0.0.10"],
+				"selector": {"app": "web"},
+				"ports": [{"port": 80, "targetPort": 8080, "nodePort": 30080}]
+			}
+		},
+		{
+			"apiVersion": "v1",
+			"kind": "ConfigMap",
+			"metadata": {"name": "kube-root-ca.crt", "namespace": "shop"},
+			"data": {"ca.crt": "cert"}
+		},
+		{
+			"apiVersion": "v1",
+			"kind": "Secret",
+			"metadata": {"name": "default-token-abcde", "namespace": "shop"},
+			"type": "kubernetes.io/service-account-token"
+		},
+		{
+			"apiVersion": "v1",
+			"kind": "Secret",
+			"metadata": {"name": "db", "namespace": "shop"},
+			"type": "Opaque",
+			"data": {"password": "cGFzc3dvcmQ="}
+		}
+	]
+}`
+
+func TestCleanNamespaceObject(t *testing.T) {
+	objs := unstructured.UnstructuredList{}
+	if err := objs.UnmarshalJSON([]byte(testNamespaceOutput)); err != nil {
+		t.Fatal(err)
+	}
+	cleaned := map[string]bool{}
+	for _, obj := range objs.Items {
+		cleaned[obj.GetKind()+"/"+obj.GetName()] = cleanNamespaceObject(&obj)
+		switch obj.GetKind() + "/" + obj.GetName() {
+		case "Deployment/web":
+			want := map[string]interface{}{"name": "web", "labels": map[string]interface{}{"app": "web"}}
+			if got := obj.Object["metadata"]; !cmp.Equal(got, want) {
+				t.Fatalf("failed to remove the cluster assigned metadata of the deployment. Difference:\n%s", cmp.Diff(want, got))
+			}
+			if _, ok := obj.Object["status"]; ok {
+				t.Fatalf("expected the status of the deployment to be removed. Actual: %+v", obj.Object["status"])
+			}
+		case "Service/web":
+			wantAnnotations := map[string]string{"team": "shop"}
+			if got := obj.GetAnnotations(); !cmp.Equal(got, wantAnnotations) {
+				t.Fatalf("failed to remove the cluster assigned annotations of the service. Difference:\n%s", cmp.Diff(wantAnnotations, got))
+			}
+			wantSpec := map[string]interface{}{
+				"type":     "NodePort",
+				"selector": map[string]interface{}{"app": "web"},
+				"ports":    []interface{}{map[string]interface{}{"port": int64(80), "targetPort": int64(8080)}},
+			}
+			if got := obj.Object["spec"]; !cmp.Equal(got, wantSpec) {
+				t.Fatalf("failed to remove the cluster assigned ips and ports of the service. Difference:\n%s", cmp.Diff(wantSpec, got))
+			}
+		}
+	}
+	want := map[string]bool{
+		"Deployment/web":             true,
+		"ReplicaSet/web-5d4f8":       false,
+		"Service/web":                true,
+		"ConfigMap/kube-root-ca.crt": false,
+		"Secret/default-token-abcde": false,
+		"Secret/db":                  true,
+	}
+	if !cmp.Equal(cleaned, want) {
+		t.Fatalf("failed to skip the objects managed by the cluster. Difference:\n%s", cmp.Diff(want, cleaned))
+	}
+}
+
+func TestWriteNamespaceSnapshot(t *testing.T) {
+	outputPath := t.TempDir()
+	snapshotPath, err := writeNamespaceSnapshot("shop", []byte(testNamespaceOutput), outputPath)
+	if err != nil {
+		t.Fatalf("failed to write the snapshot of the namespace. Error: %q", err)
+	}
+	if dir := filepath.Dir(snapshotPath); dir != filepath.Join(outputPath, namespacesDirName) {
+		t.Fatalf("expected the snapshot to be in the directory '%s' . Actual: '%s'", filepath.Join(outputPath, namespacesDirName), snapshotPath)
+	}
+	// the snapshot must be readable as kubernetes yamls, since that is how the transformers pick it up from the source directory
+	got := []string{}
+	for _, obj := range k8sschema.GetKubernetesObjsInDir(snapshotPath) {
+		metaObj, err := meta.Accessor(obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, obj.GetObjectKind().GroupVersionKind().Kind+"/"+metaObj.GetName())
+	}
+	sort.Strings(got)
+	want := []string{"Deployment/web", "Secret/db", "Service/web"}
+	if !cmp.Equal(got, want) {
+		t.Fatalf("failed to write the snapshot as kubernetes yamls. Difference:\n%s", cmp.Diff(want, got))
+	}
+	if _, err := writeNamespaceSnapshot("shop", []byte("not json"), outputPath); err == nil {
+		t.Fatalf("expected an error for an invalid kubectl output")
+	}
+}
@@ -20,6 +20,7 @@ package sourcetypes
 type DockerImage struct {
 	RepoTags []string        `json:"RepoTags"`
 	CConfig  ContainerConfig `json:"ContainerConfig"`
+	Config   ContainerConfig `json:"Config"`
 }
 
 // ContainerConfig loads container config
@@ -28,4 +29,55 @@ type ContainerConfig struct {
 	User       string                 `json:"User"`
 	Env        []string               `json:"Env"`
 	WorkingDir string                 `json:"WorkingDir"`
+	Entrypoint []string               `json:"Entrypoint"`
+	Cmd        []string               `json:"Cmd"`
+	Hostname   string                 `json:"Hostname"`
+	Image      string                 `json:"Image"`
+	Labels     map[string]string      `json:"Labels"`
+}
+
+// DockerContainer loads the docker inspect output of a container
+type DockerContainer struct {
+	ID              string                 `json:"Id"`
+	Name            string                 `json:"Name"`
+	Image           string                 `json:"Image"`
+	Config          ContainerConfig        `json:"Config"`
+	HostConfig      HostConfig             `json:"HostConfig"`
+	Mounts          []ContainerMount       `json:"Mounts"`
+	NetworkSettings ContainerNetworkConfig `json:"NetworkSettings"`
+}
+
+// HostConfig loads the host specific config of a container
+type HostConfig struct {
+	PortBindings  map[string][]PortBinding `json:"PortBindings"`
+	RestartPolicy RestartPolicy            `json:"RestartPolicy"`
+	Privileged    bool                     `json:"Privileged"`
+	CapAdd        []string                 `json:"CapAdd"`
+	CapDrop       []string                 `json:"CapDrop"`
+	Tmpfs         map[string]string        `json:"Tmpfs"`
+}
+
+// PortBinding loads the host port a container port is published on
+type PortBinding struct {
+	HostIP   string `json:"HostIp"`
+	HostPort string `json:"HostPort"`
+}
+
+// RestartPolicy loads the restart policy of a container
+type RestartPolicy struct {
+	Name string `json:"Name"`
+}
+
+// ContainerMount loads a mount of a container
+type ContainerMount struct {
+	Type        string `json:"Type"`
+	Name        string `json:"Name"`
+	Source      string `json:"Source"`
+	Destination string `json:"Destination"`
+	RW          bool   `json:"RW"`
+}
+
+// ContainerNetworkConfig loads the networks a container is attached to
+type ContainerNetworkConfig struct {
+	Networks map[string]interface{} `json:"Networks"`
 }
@@ -0,0 +1,175 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package common
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ExtractArchive extracts a zip or tar(.gz) archive into the destination directory.
+// The filename is used to detect the format of the archive.
+func ExtractArchive(archivePath, filename, destDir string) error {
+	lowerFilename := strings.ToLower(filename)
+	switch {
+	case strings.HasSuffix(lowerFilename, ".zip"):
+		return extractZip(archivePath, destDir)
+	case strings.HasSuffix(lowerFilename, ".tar.gz"), strings.HasSuffix(lowerFilename, ".tgz"):
+		f, err := os.Open(archivePath)
+		if err != nil {
+			return fmt.Errorf("failed to open the archive at path '%s' . Error: %w", archivePath, err)
+		}
+		defer f.Close()
+		gzipReader, err := gzip.NewReader(f)
+		if err != nil {
+			return fmt.Errorf("failed to read the archive at path '%s' as gzip. Error: %w", archivePath, err)
+		}
+		defer gzipReader.Close()
+		return extractTar(gzipReader, destDir)
+	case strings.HasSuffix(lowerFilename, ".tar"):
+		f, err := os.Open(archivePath)
+		if err != nil {
+			return fmt.Errorf("failed to open the archive at path '%s' . Error: %w", archivePath, err)
+		}
+		defer f.Close()
+		return extractTar(f, destDir)
+	}
+	return fmt.Errorf("unsupported archive '%s' . Supported formats are .zip, .tar, .tar.gz and .tgz", filename)
+}
+
+// getSafePath joins the archive entry name to the destination directory, rejecting entries outside it
+func getSafePath(destDir, name string) (string, error) {
+	path := filepath.Join(destDir, filepath.FromSlash(name))
+	if path != destDir && !strings.HasPrefix(path, destDir+string(os.PathSeparator)) {
+		return "", fmt.Errorf("the archive entry '%s' is outside the destination directory", name)
+	}
+	return path, nil
+}
+
+func extractZip(archivePath, destDir string) error {
+	zipReader, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return fmt.Errorf("failed to open the zip archive at path '%s' . Error: %w", archivePath, err)
+	}
+	defer zipReader.Close()
+	for _, f := range zipReader.File {
+		path, err := getSafePath(destDir, f.Name)
+		if err != nil {
+			return err
+		}
+		if f.FileInfo().IsDir() {
+			if err := os.MkdirAll(path, DefaultDirectoryPermission); err != nil {
+				return fmt.Errorf("failed to create the directory '%s' . Error: %w", path, err)
+			}
+			continue
+		}
+		if err := writeArchiveFile(path, f.Mode(), func() (io.ReadCloser, error) { return f.Open() }); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func extractTar(r io.Reader, destDir string) error {
+	tarReader := tar.NewReader(r)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read the tar archive. Error: %w", err)
+		}
+		path, err := getSafePath(destDir, header.Name)
+		if err != nil {
+			return err
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(path, DefaultDirectoryPermission); err != nil {
+				return fmt.Errorf("failed to create the directory '%s' . Error: %w", path, err)
+			}
+		case tar.TypeReg:
+			if err := writeArchiveFile(path, header.FileInfo().Mode(), func() (io.ReadCloser, error) { return io.NopCloser(tarReader), nil }); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func writeArchiveFile(path string, mode os.FileMode, open func() (io.ReadCloser, error)) error {
+	if err := os.MkdirAll(filepath.Dir(path), DefaultDirectoryPermission); err != nil {
+		return fmt.Errorf("failed to create the directory '%s' . Error: %w", filepath.Dir(path), err)
+	}
+	src, err := open()
+	if err != nil {
+		return fmt.Errorf("failed to open the archive entry for '%s' . Error: %w", path, err)
+	}
+	defer src.Close()
+	dst, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode.Perm()|0600)
+	if err != nil {
+		return fmt.Errorf("failed to create the file '%s' . Error: %w", path, err)
+	}
+	defer dst.Close()
+	if _, err := io.Copy(dst, src); err != nil {
+		return fmt.Errorf("failed to write the file '%s' . Error: %w", path, err)
+	}
+	return nil
+}
+
+// WriteZip writes all the files in the source directory to the writer as a zip archive
+func WriteZip(srcDir string, w io.Writer) error {
+	zipWriter := zip.NewWriter(w)
+	defer zipWriter.Close()
+	return filepath.Walk(srcDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		relPath, err := filepath.Rel(srcDir, path)
+		if err != nil {
+			return fmt.Errorf("failed to make the path '%s' relative to '%s' . Error: %w", path, srcDir, err)
+		}
+		header, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return fmt.Errorf("failed to create the zip header for '%s' . Error: %w", path, err)
+		}
+		header.Name = filepath.ToSlash(relPath)
+		header.Method = zip.Deflate
+		entryWriter, err := zipWriter.CreateHeader(header)
+		if err != nil {
+			return fmt.Errorf("failed to create the zip entry for '%s' . Error: %w", path, err)
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to open the file '%s' . Error: %w", path, err)
+		}
+		defer f.Close()
+		if _, err := io.Copy(entryWriter, f); err != nil {
+			return fmt.Errorf("failed to write the file '%s' to the zip archive. Error: %w", path, err)
+		}
+		return nil
+	})
+}
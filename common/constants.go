@@ -32,6 +32,8 @@ const (
 )
 
 const (
+	// MaxMetadataNameLength is the maximum length of a DNS-1123 label, which is used for service/metadata names
+	MaxMetadataNameLength = 63
 	// DefaultProjectName represents the short app name
 	DefaultProjectName = "myproject"
 	// VolumePrefix defines the prefix to be used for volumes
@@ -54,6 +56,22 @@ const (
 	AnnotationLabelValue = "true"
 	// DefaultServicePort is the default port that will be added to a service.
 	DefaultServicePort int32 = 8080
+	// SourceFilesAnnotation records the source files a generated resource was converted from
+	SourceFilesAnnotation = types.GroupName + "/source-files"
+	// SourceTransformerAnnotation records the transformer that converted the source files of a generated resource
+	SourceTransformerAnnotation = types.GroupName + "/source-transformer"
+	// GeneratedByAnnotation records the transformer that generated a resource
+	GeneratedByAnnotation = types.GroupName + "/generated-by"
+	// GeneratedByVersionAnnotation records the version of the transformer that generated a resource
+	GeneratedByVersionAnnotation = types.GroupName + "/generated-by-version"
+	// BuiltInTransformerLabel marks the transformers that are built into move2kube
+	BuiltInTransformerLabel = types.GroupName + "/built-in"
+	// TransformerVersionLabel records the version of an external transformer
+	TransformerVersionLabel = types.GroupName + "/version"
+	// InstanceLabel is the recommended label identifying the instance of an application, like a compose project, a resource belongs to
+	InstanceLabel = "app.kubernetes.io/instance"
+	// Move2KubeVersionAnnotation records the major and minor version of move2kube that generated a resource
+	Move2KubeVersionAnnotation = types.GroupName + "/version"
 	// TODOAnnotation is used to annotate with TODO tasks
 	TODOAnnotation = types.GroupName + "/todo."
 	// ShExt is the extension of sh file
@@ -89,12 +107,170 @@ const (
 	ConfigStoragesKey = BaseKey + d + "storages"
 	//ConfigMinReplicasKey represents Ingress host Key
 	ConfigMinReplicasKey = BaseKey + d + "minreplicas"
+	//ConfigPriorityClassesKey represents the priority classes that can be assigned to the services
+	ConfigPriorityClassesKey = BaseKey + d + "priorityclasses"
+	//ConfigPriorityClassNamesKey represents the names of the priority classes given when they are not collected from the cluster
+	ConfigPriorityClassNamesKey = ConfigPriorityClassesKey + d + "names"
+	//ConfigPriorityClassesGenerateKey represents the generation of the priority classes missing in the cluster
+	ConfigPriorityClassesGenerateKey = ConfigPriorityClassesKey + d + "generate"
+	//ConfigPriorityClassValuesKey represents the values of the generated priority classes
+	ConfigPriorityClassValuesKey = ConfigPriorityClassesKey + d + "values"
+	//ConfigMonitoringKey represents the key for the monitoring resources generated for the services
+	ConfigMonitoringKey = BaseKey + d + "monitoring"
+	//ConfigMonitoringAlertsKey represents the key for the generation of the alerting rules
+	ConfigMonitoringAlertsKey = ConfigMonitoringKey + d + "alerts"
+	//ConfigMonitoringDashboardsKey represents the key for the generation of the Grafana dashboards
+	ConfigMonitoringDashboardsKey = ConfigMonitoringKey + d + "dashboards"
+	//ConfigMonitoringInstrumentationKey represents the key for the OpenTelemetry instrumentation of the services
+	ConfigMonitoringInstrumentationKey = ConfigMonitoringKey + d + "instrumentation"
+	//ConfigMonitoringCollectorEndpointKey represents the key for the endpoint the OpenTelemetry collector sidecars export to
+	ConfigMonitoringCollectorEndpointKey = ConfigMonitoringKey + d + "collectorendpoint"
+	//ConfigLoggingKey represents the key for the shipping of the container logs to a centralized logging backend
+	ConfigLoggingKey = BaseKey + d + "logging"
+	//ConfigLoggingBackendKey represents the key for the logging backend the container logs are shipped to
+	ConfigLoggingBackendKey = ConfigLoggingKey + d + "backend"
+	//ConfigLoggingEndpointKey represents the key for the endpoint of the logging backend
+	ConfigLoggingEndpointKey = ConfigLoggingKey + d + "endpoint"
+	//ConfigBackupKey represents the key for the Velero backups of the persistent volumes of the services
+	ConfigBackupKey = BaseKey + d + "backup"
+	//ConfigBackupEnableKey represents the key for enabling the Velero backups
+	ConfigBackupEnableKey = ConfigBackupKey + d + "enable"
+	//ConfigBackupMethodKey represents the key for the way Velero backs up the persistent volumes
+	ConfigBackupMethodKey = ConfigBackupKey + d + "method"
+	//ConfigBackupScheduleKey represents the key for the cron schedule of the Velero backups
+	ConfigBackupScheduleKey = ConfigBackupKey + d + "schedule"
+	//ConfigBackupTTLKey represents the key for the time the Velero backups are kept
+	ConfigBackupTTLKey = ConfigBackupKey + d + "ttl"
+	//ConfigBackupNamespaceKey represents the key for the namespace of the services backed up by Velero
+	ConfigBackupNamespaceKey = ConfigBackupKey + d + "namespace"
+	//ConfigCertManagerKey represents the key for the cert-manager issuers of the TLS certificates of the Ingresses and Routes
+	ConfigCertManagerKey = BaseKey + d + "certmanager"
+	//ConfigCertManagerIssuerTypeKey represents the key for the way the cert-manager issuer gets the certificates
+	ConfigCertManagerIssuerTypeKey = ConfigCertManagerKey + d + "issuertype"
+	//ConfigCertManagerIssuerKindKey represents the key for the kind of the cert-manager issuer (Issuer/ClusterIssuer)
+	ConfigCertManagerIssuerKindKey = ConfigCertManagerKey + d + "issuerkind"
+	//ConfigCertManagerACMEServerKey represents the key for the directory URL of the ACME server
+	ConfigCertManagerACMEServerKey = ConfigCertManagerKey + d + "acme" + d + "server"
+	//ConfigCertManagerACMEEmailKey represents the key for the email of the ACME account
+	ConfigCertManagerACMEEmailKey = ConfigCertManagerKey + d + "acme" + d + "email"
+	//ConfigCertManagerDNS01ProviderKey represents the key for the DNS provider solving the ACME DNS01 challenges
+	ConfigCertManagerDNS01ProviderKey = ConfigCertManagerKey + d + "acme" + d + "dns01provider"
+	//ConfigCertManagerDNS01Key represents the key for the settings of the DNS provider solving the ACME DNS01 challenges
+	ConfigCertManagerDNS01Key = ConfigCertManagerKey + d + "acme" + d + "dns01"
+	//ConfigCertManagerDNS01SecretKey represents the key for the secret containing the credentials of the DNS provider
+	ConfigCertManagerDNS01SecretKey = ConfigCertManagerDNS01Key + d + "secret"
+	//ConfigCertManagerDNS01RegionKey represents the key for the AWS region of the Route53 DNS provider
+	ConfigCertManagerDNS01RegionKey = ConfigCertManagerDNS01Key + d + "region"
+	//ConfigCertManagerDNS01HostedZoneKey represents the key for the id of the hosted zone of the Route53 DNS provider
+	ConfigCertManagerDNS01HostedZoneKey = ConfigCertManagerDNS01Key + d + "hostedzoneid"
+	//ConfigCertManagerDNS01ProjectKey represents the key for the Google Cloud project of the Cloud DNS provider
+	ConfigCertManagerDNS01ProjectKey = ConfigCertManagerDNS01Key + d + "project"
+	//ConfigCertManagerCASecretKey represents the key for the secret containing the CA that signs the certificates
+	ConfigCertManagerCASecretKey = ConfigCertManagerKey + d + "ca" + d + "secret"
+	//ConfigExternalDNSKey represents the key for the external-dns annotations of the Ingress and the LoadBalancer Services
+	ConfigExternalDNSKey = BaseKey + d + "externaldns"
+	//ConfigExternalDNSEnableKey represents the key for enabling the external-dns annotations
+	ConfigExternalDNSEnableKey = ConfigExternalDNSKey + d + "enable"
+	//ConfigExternalDNSTTLKey represents the key for the TTL of the DNS records created by external-dns
+	ConfigExternalDNSTTLKey = ConfigExternalDNSKey + d + "ttl"
+	//ConfigPodSecurityKey represents the key for the Pod Security Standards the generated workloads comply with
+	ConfigPodSecurityKey = BaseKey + d + "podsecurity"
+	//ConfigPodSecurityRestrictedKey represents the key for making the pods comply with the restricted Pod Security Standard
+	ConfigPodSecurityRestrictedKey = ConfigPodSecurityKey + d + "restricted"
+	//ConfigPodSecurityNamespaceKey represents the key for the namespace generated with the restricted enforcement level
+	ConfigPodSecurityNamespaceKey = ConfigPodSecurityKey + d + "namespace"
+	//ConfigSecurityBaselineKey represents the key for the security baseline applied to all the containers
+	ConfigSecurityBaselineKey = BaseKey + d + "securitybaseline"
+	//ConfigSecurityBaselineSeccompProfileKey represents the key for the seccomp profile of the containers
+	ConfigSecurityBaselineSeccompProfileKey = ConfigSecurityBaselineKey + d + "seccompprofile"
+	//ConfigSecurityBaselineDropAllCapabilitiesKey represents the key for dropping all the capabilities not added in the source
+	ConfigSecurityBaselineDropAllCapabilitiesKey = ConfigSecurityBaselineKey + d + "dropallcapabilities"
+	//ConfigNamespaceKey represents the key for the namespace the generated resources are deployed to
+	ConfigNamespaceKey = BaseKey + d + "namespace"
+	//ConfigNamingKey represents the key for the naming convention of the generated resources
+	ConfigNamingKey = BaseKey + d + "naming"
+	//ConfigNamingPrefixKey represents the key for the prefix added to the names of the generated resources
+	ConfigNamingPrefixKey = ConfigNamingKey + d + "prefix"
+	//ConfigNamingSuffixKey represents the key for the suffix added to the names of the generated resources
+	ConfigNamingSuffixKey = ConfigNamingKey + d + "suffix"
+	//ConfigNamingSeparatorKey represents the key for the separator between the prefix, the name and the suffix
+	ConfigNamingSeparatorKey = ConfigNamingKey + d + "separator"
+	//ConfigNamingMaxLengthKey represents the key for the maximum length of the names of the generated resources
+	ConfigNamingMaxLengthKey = ConfigNamingKey + d + "maxlength"
+	//ConfigNamingProjectsKey represents the key for the naming of the services of the projects, like compose projects
+	ConfigNamingProjectsKey = ConfigNamingKey + d + "projects"
+	//ConfigParameterizationKey represents the key for the parameterization of the generated artifacts
+	ConfigParameterizationKey = BaseKey + d + "parameterization"
+	//ConfigParameterizationEnvsKey represents the key for the environments to generate the parameterized artifacts for
+	ConfigParameterizationEnvsKey = ConfigParameterizationKey + d + "envs"
+	//ConfigParameterizationSecretsKey represents the key for the externalization of the generated secrets
+	ConfigParameterizationSecretsKey = ConfigParameterizationKey + d + "secrets"
+	//ConfigParameterizationHelmOperatorKey represents the key for the Helm operator wrapping the generated Helm chart
+	ConfigParameterizationHelmOperatorKey = ConfigParameterizationKey + d + "helmoperator"
+	//ConfigEnableForHelmOperatorKeySegment represents whether the Helm operator of a project is generated
+	ConfigEnableForHelmOperatorKeySegment = "enable"
+	//ConfigDomainForHelmOperatorKeySegment represents the domain of the API group of the Helm operator of a project
+	ConfigDomainForHelmOperatorKeySegment = "domain"
+	//ConfigImageForHelmOperatorKeySegment represents the image of the Helm operator of a project
+	ConfigImageForHelmOperatorKeySegment = "image"
+	//ConfigPrefixForProjectKeySegment represents whether the names of the services of a project are prefixed with the project name
+	ConfigPrefixForProjectKeySegment = "prefix"
+	//ConfigMechanismForSecretKeySegment represents the mechanism used to externalize a secret
+	ConfigMechanismForSecretKeySegment = "mechanism"
+	//ConfigStoreForSecretKeySegment represents the store, provider or role used to externalize a secret
+	ConfigStoreForSecretKeySegment = "store"
+	//ConfigPathForSecretKeySegment represents the path of an externalized secret in the external secret manager
+	ConfigPathForSecretKeySegment = "path"
+	//ConfigEnvSpecificForParameterKeySegment represents whether a parameter has a different value in each environment
+	ConfigEnvSpecificForParameterKeySegment = "envspecific"
+	//ConfigValuesForParameterKeySegment represents the environment specific values of a parameter
+	ConfigValuesForParameterKeySegment = "values"
 	//ConfigDeploymentTypeKey represents which type of Deployment should be generated
 	ConfigDeploymentTypeKey = "deployment"
 	//ConfigPortsForServiceKeySegment represents the ports used for service
 	ConfigPortsForServiceKeySegment = "ports"
 	//ConfigPortForServiceKeySegment represents the port used for service
 	ConfigPortForServiceKeySegment = "port"
+	//ConfigPortRangeForServiceKeySegment represents whether a huge port range of service is expanded into individual ports
+	ConfigPortRangeForServiceKeySegment = "portrange"
+	//ConfigResourcesForServiceKeySegment represents the resource requests and limits used for service
+	ConfigResourcesForServiceKeySegment = "resources"
+	//ConfigResourcePresetForServiceKeySegment represents the resource preset used for service
+	ConfigResourcePresetForServiceKeySegment = "resourcepreset"
+	//ConfigPodAntiAffinityForServiceKeySegment represents the topology across which the replicas of the service are spread
+	ConfigPodAntiAffinityForServiceKeySegment = "podantiaffinity"
+	//ConfigTopologySpreadForServiceKeySegment represents the topology spread constraints of service
+	ConfigTopologySpreadForServiceKeySegment = "topologyspread"
+	//ConfigPriorityClassNameForServiceKeySegment represents the priority class of service
+	ConfigPriorityClassNameForServiceKeySegment = "priorityclassname"
+	//ConfigDownwardAPIEnvForServiceKeySegment represents the injection of the downward API env vars into the containers of service
+	ConfigDownwardAPIEnvForServiceKeySegment = "downwardapienv"
+	//ConfigPreStopDelayForServiceKeySegment represents the seconds the containers of service wait before stopping
+	ConfigPreStopDelayForServiceKeySegment = "prestopdelay"
+	//ConfigCentralizedLoggingForServiceKeySegment represents the shipping of the logs of service to the logging backend
+	ConfigCentralizedLoggingForServiceKeySegment = "centralizedlogging"
+	//ConfigDependencyWaitStrategyForServiceKeySegment represents the way the init containers of service wait for its dependencies
+	ConfigDependencyWaitStrategyForServiceKeySegment = "dependencywaitstrategy"
+	//ConfigCPURequestForServiceKeySegment represents the cpu request of service when it has cpu shares
+	ConfigCPURequestForServiceKeySegment = "cpurequest"
+	//ConfigPrivilegedForServiceKeySegment represents the downgrade of the privileged containers of service
+	ConfigPrivilegedForServiceKeySegment = "privileged"
+	//ConfigCapabilitiesForServiceKeySegment represents the capabilities replacing the privileged mode of service
+	ConfigCapabilitiesForServiceKeySegment = "capabilities"
+	//ConfigCapabilityPolicyForServiceKeySegment represents the handling of the capabilities of service that are not allowed by the admission policies of the cluster
+	ConfigCapabilityPolicyForServiceKeySegment = "capabilitypolicy"
+	//ConfigHostNetworkForServiceKeySegment represents the downgrade of the host networking of service
+	ConfigHostNetworkForServiceKeySegment = "hostnetwork"
+	//ConfigDevicesForServiceKeySegment represents the way the devices of service are provided
+	ConfigDevicesForServiceKeySegment = "devices"
+	//ConfigDevicePluginResourceForServiceKeySegment represents the resource of the device plugin providing the devices of service
+	ConfigDevicePluginResourceForServiceKeySegment = "devicepluginresource"
+	//ConfigPrivilegedDevicesForServiceKeySegment represents whether service is run privileged to access the devices mounted from the host
+	ConfigPrivilegedDevicesForServiceKeySegment = "privilegeddevices"
+	//ConfigOnlyNameserversForServiceKeySegment represents whether service only uses its nameservers instead of the DNS of the cluster
+	ConfigOnlyNameserversForServiceKeySegment = "onlynameservers"
+	//ConfigUnsafeSysctlsForServiceKeySegment represents whether the unsafe sysctls of service are set
+	ConfigUnsafeSysctlsForServiceKeySegment = "unsafesysctls"
 	//ConfigMainPythonFileForServiceKeySegment represents the main file used for service
 	ConfigMainPythonFileForServiceKeySegment = "pythonmainfile"
 	//ConfigStartingPythonFileForServiceKeySegment represents the starting python file used for service
@@ -103,12 +279,18 @@ const (
 	ConfigCsprojFileForServiceKeySegment = "csprojfile"
 	//ConfigPublishProfileForServiceKeySegment represents the publish profile used for service
 	ConfigPublishProfileForServiceKeySegment = "publishprofile"
+	//ConfigVcapServicesForServiceKeySegment represents the construction of VCAP_SERVICES from the service bindings
+	ConfigVcapServicesForServiceKeySegment = "vcapservices"
+	//ConfigEnvForServiceKeySegment represents the values of the env vars that are not set for service
+	ConfigEnvForServiceKeySegment = "env"
 	//ConfigContainerizationOptionServiceKeySegment represents containerization option to use
 	ConfigContainerizationOptionServiceKeySegment = "containerizationoption"
 	//ConfigApacheConfFileForServiceKeySegment represents the conf file used for service
 	ConfigApacheConfFileForServiceKeySegment = "apacheconfig"
 	//ConfigSpawnContainersKey represents spwan containers option Key
 	ConfigSpawnContainersKey = BaseKey + d + "spawncontainers"
+	//ConfigApplyChangesForTransformerKeySegment represents whether the previewed changes of a transformer are written to the output directory
+	ConfigApplyChangesForTransformerKeySegment = "applychanges"
 	//ConfigTransformersKey represents transformers Key
 	ConfigTransformersKey = BaseKey + d + "transformers"
 	//ConfigTargetKey represents Target Key
@@ -135,6 +317,24 @@ const (
 	ConfigTransformerTypesKey = ConfigTransformersKey + d + "types"
 	//VolQaPrefixKey represents the storage QA
 	VolQaPrefixKey = BaseKey + d + "storage.type"
+	//VolHostPathQaPrefixKey represents the QA for the node paths used in place of the Windows host paths
+	VolHostPathQaPrefixKey = BaseKey + d + "storage.hostpath"
+	//ConfigSharedEnvKey represents the QA for moving the env vars shared by the services into common config maps and secrets
+	ConfigSharedEnvKey = BaseKey + d + "sharedenv"
+	//ConfigComposeProfilesKey represents the QA for the compose profiles whose services are converted
+	ConfigComposeProfilesKey = BaseKey + d + "compose.profiles"
+	//ConfigComposeOverridesKey represents the QA for the override files merged into the compose files
+	ConfigComposeOverridesKey = BaseKey + d + "compose.overrides"
+	//ConfigComposeHealthCheckProbesKey represents the QA for the probes generated from the health checks of the compose services
+	ConfigComposeHealthCheckProbesKey = BaseKey + d + "compose.healthcheckprobes"
+	//ConfigComposeVariablesKey represents the QA for the values of the variables used in the compose files that are not set
+	ConfigComposeVariablesKey = BaseKey + d + "compose.variables"
+	//ConfigDependencyWaitKey represents the QA for the init containers waiting for the dependencies of the services
+	ConfigDependencyWaitKey = BaseKey + d + "dependencywait"
+	//ConfigDependencyWaitImageKey represents the image of the init containers waiting for the dependencies
+	ConfigDependencyWaitImageKey = ConfigDependencyWaitKey + d + "image"
+	//ConfigDependencyWaitCommandKey represents the command the init containers run to wait for the dependencies
+	ConfigDependencyWaitCommandKey = ConfigDependencyWaitKey + d + "command"
 	//IngressKey represents ingress keyword
 	IngressKey = "ingress"
 	// ConfigIngressClassNameKeySuffix represents the ingress class name
@@ -173,6 +373,14 @@ const (
 	ConfigImageRegistryURLKey = ConfigImageRegistryKey + d + "url"
 	//ConfigImageRegistryNamespaceKey represents image registry namespace Key
 	ConfigImageRegistryNamespaceKey = ConfigImageRegistryKey + d + "namespace"
+	//ConfigImageTagPolicyKey represents the key for how the new images are tagged
+	ConfigImageTagPolicyKey = ConfigImageRegistryKey + d + "tagpolicy"
+	//ConfigImageTagKey represents the key for the tag used for all the new images
+	ConfigImageTagKey = ConfigImageRegistryKey + d + "tag"
+	//ConfigDockerContextKey represents the key for the docker context used by the generated scripts
+	ConfigDockerContextKey = ConfigTargetKey + d + "dockercontext"
+	//ConfigPodmanConnectionKey represents the key for the podman system connection used by the generated scripts
+	ConfigPodmanConnectionKey = ConfigTargetKey + d + "podmanconnection"
 	//ConfigImageRegistryLoginTypeKey represents image registry login type Key
 	ConfigImageRegistryLoginTypeKey = ConfigImageRegistryKey + d + "%s" + d + "logintype"
 	//ConfigImageRegistryPullSecretKey represents image registry pull secret Key
@@ -185,6 +393,14 @@ const (
 	ConfigStoragesPVCForHostPathKey = ConfigStoragesKey + d + "pvcforhostpath"
 	//ConfigStoragesPerClaimStorageClassKey represents key for having different storage class for claim
 	ConfigStoragesPerClaimStorageClassKey = ConfigStoragesKey + d + "perclaimstorageclass"
+	//ConfigStoragesStorageClassKey represents key for the storage class of a claim
+	ConfigStoragesStorageClassKey = ConfigStoragesKey + d + "%s" + d + "storageclass"
+	//ConfigStoragesSizeKey represents key for the size of a claim
+	ConfigStoragesSizeKey = ConfigStoragesKey + d + "%s" + d + "size"
+	//ConfigStoragesOversizedKey represents key for how to handle ConfigMaps and Secrets that are too large
+	ConfigStoragesOversizedKey = ConfigStoragesKey + d + "%s" + d + "oversized"
+	//ConfigStoragesObjectStoreURLKey represents key for the object store url to download oversized content from
+	ConfigStoragesObjectStoreURLKey = ConfigStoragesKey + d + "%s" + d + "objectstoreurl"
 	//ConfigServicesNamesKey is true if a detected service is enabled for transformation
 	ConfigServicesNamesKey = ConfigServicesKey + d + Special + d + "enable"
 	//ConfigContainerizationTypesKey represents source type Key
@@ -242,12 +458,43 @@ var (
 	IgnoreEnvironment = false
 	// DisableLocalExecution indicates whether to allow execution of local executables
 	DisableLocalExecution = false
+	// DisableProvenanceAnnotations indicates whether to skip annotating the generated resources with their sources
+	DisableProvenanceAnnotations = false
+	// DumpIRPath is the path where the IR loaded from the source artifacts is written during transformation. The IR is not written if empty.
+	DumpIRPath = ""
+	// PreviewTransformerDiffs indicates whether to preview the files written by each transformer as a diff and ask before writing them
+	PreviewTransformerDiffs = false
+	// MaxParallelTransforms is the maximum number of transformers that can run at the same time during transformation
+	MaxParallelTransforms = 1
+	// PlanCacheDir is the directory where the directory detect results are cached during planning. Caching is disabled if empty.
+	PlanCacheDir = ""
+	// PlanMaxDepth is the maximum depth of sub directories to plan in. Negative value means infinite.
+	PlanMaxDepth = -1
+	// PlanMaxSizeBytes is the maximum total size of the files in the directories to plan in. Negative value means infinite.
+	PlanMaxSizeBytes int64 = -1
+	// ServiceNameMaxLength is the maximum length of the service names when they are normalized during planning
+	ServiceNameMaxLength = MaxMetadataNameLength
+	// MaxEmbeddedFileSizeBytes is the maximum size of a file whose contents can be embedded in an artifact like a ConfigMap or Secret.
+	// Negative value means infinite.
+	MaxEmbeddedFileSizeBytes int64 = 10 * 1024 * 1024
+	// MaxParallelImageLookups is the maximum number of images whose metadata is collected at the same time
+	MaxParallelImageLookups = 4
+	// RegistryRequestsPerSecond is the maximum rate of requests to a single image registry. Zero or negative value means no limit.
+	RegistryRequestsPerSecond float64 = 5
+	// ImageMetadataCacheDir is the directory where the image metadata fetched from the registries is cached. Caching is disabled if empty.
+	ImageMetadataCacheDir = ""
+	// CollectNamespace is the Kubernetes namespace whose workloads are collected. The namespace is not collected if empty.
+	CollectNamespace = ""
 	// DefaultIgnoreDirRegexps specifies directory name regexes that would be ignored
 	DefaultIgnoreDirRegexps = []*regexp.Regexp{regexp.MustCompile("^[.].*")}
+	// SecretEnvNameRegex matches the names of the env vars which usually hold sensitive values
+	SecretEnvNameRegex = regexp.MustCompile(`(?i)(password|passwd|secret|token|api_?key|private_?key|credential)`)
 	// DisabledCategories is a list of QA categories that are disabled
 	DisabledCategories = []string{}
 	// QACategoryMap maps category names to problem IDs
 	QACategoryMap = map[string][]string{}
+	// metadataNameHashLength is the length of the hash suffix used to disambiguate colliding metadata names
+	metadataNameHashLength = 8
 	// disallowedDNSCharactersRegex provides pattern for characters not allowed in a DNS Name
 	disallowedDNSCharactersRegex = regexp.MustCompile(`[^a-z0-9\-]`)
 	// disallowedEnvironmentCharactersRegex provides pattern for characters not allowed in a DNS Name
@@ -0,0 +1,189 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package common
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// diffContextLines is the number of unchanged lines shown around the changes in a unified diff
+	diffContextLines = 3
+	// maxDiffTableSize is the largest number of line pairs compared when computing a diff.
+	// Larger files are shown as completely replaced instead.
+	maxDiffTableSize = 16 * 1024 * 1024
+)
+
+type diffOpType int
+
+const (
+	diffEqual diffOpType = iota
+	diffDelete
+	diffInsert
+)
+
+type diffOp struct {
+	opType diffOpType
+	line   string
+}
+
+// UnifiedDiff returns the unified diff between the old and new texts.
+// It returns an empty string if the texts are the same.
+func UnifiedDiff(oldName, newName, oldText, newText string) string {
+	if oldText == newText {
+		return ""
+	}
+	ops := diffLines(splitLines(oldText), splitLines(newText))
+	diff := strings.Builder{}
+	diff.WriteString(fmt.Sprintf("--- %s\n+++ %s\n", oldName, newName))
+	oldLine, newLine := 1, 1
+	for start := 0; start < len(ops); {
+		// find the next change
+		for start < len(ops) && ops[start].opType == diffEqual {
+			start++
+			oldLine++
+			newLine++
+		}
+		if start == len(ops) {
+			break
+		}
+		// include the context before the change
+		hunkStart := start - diffContextLines
+		if hunkStart < 0 {
+			hunkStart = 0
+		}
+		hunkOldLine, hunkNewLine := oldLine-(start-hunkStart), newLine-(start-hunkStart)
+		// extend the hunk while the changes are separated by at most twice the context
+		end, equalRun := start, 0
+		for end < len(ops) {
+			if ops[end].opType == diffEqual {
+				equalRun++
+				if equalRun > 2*diffContextLines {
+					// the change is far enough from the next one to end the hunk
+					equalRun--
+					break
+				}
+			} else {
+				equalRun = 0
+			}
+			end++
+		}
+		if equalRun > diffContextLines {
+			end -= equalRun - diffContextLines
+		}
+		oldCount, newCount := 0, 0
+		hunk := strings.Builder{}
+		for _, op := range ops[hunkStart:end] {
+			switch op.opType {
+			case diffEqual:
+				oldCount++
+				newCount++
+				hunk.WriteString(" " + op.line + "\n")
+			case diffDelete:
+				oldCount++
+				hunk.WriteString("-" + op.line + "\n")
+			case diffInsert:
+				newCount++
+				hunk.WriteString("+" + op.line + "\n")
+			}
+		}
+		diff.WriteString(fmt.Sprintf("@@ -%s +%s @@\n", hunkRange(hunkOldLine, oldCount), hunkRange(hunkNewLine, newCount)))
+		diff.WriteString(hunk.String())
+		for _, op := range ops[start:end] {
+			if op.opType != diffInsert {
+				oldLine++
+			}
+			if op.opType != diffDelete {
+				newLine++
+			}
+		}
+		start = end
+	}
+	return diff.String()
+}
+
+// hunkRange formats the start line and the number of lines of a hunk
+func hunkRange(start, count int) string {
+	if count == 0 {
+		// an empty range starts at the line before the hunk
+		return fmt.Sprintf("%d,0", start-1)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// diffLines returns the edits that turn the old lines into the new lines using the longest common subsequence
+func diffLines(oldLines, newLines []string) []diffOp {
+	n, m := len(oldLines), len(newLines)
+	ops := []diffOp{}
+	if (n+1)*(m+1) > maxDiffTableSize {
+		for _, line := range oldLines {
+			ops = append(ops, diffOp{opType: diffDelete, line: line})
+		}
+		for _, line := range newLines {
+			ops = append(ops, diffOp{opType: diffInsert, line: line})
+		}
+		return ops
+	}
+	// lcs[i][j] is the length of the longest common subsequence of oldLines[i:] and newLines[j:]
+	lcs := make([][]int32, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if oldLines[i] == newLines[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	i, j := 0, 0
+	for i < n && j < m {
+		if oldLines[i] == newLines[j] {
+			ops = append(ops, diffOp{opType: diffEqual, line: oldLines[i]})
+			i++
+			j++
+		} else if lcs[i+1][j] >= lcs[i][j+1] {
+			ops = append(ops, diffOp{opType: diffDelete, line: oldLines[i]})
+			i++
+		} else {
+			ops = append(ops, diffOp{opType: diffInsert, line: newLines[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		ops = append(ops, diffOp{opType: diffDelete, line: oldLines[i]})
+	}
+	for ; j < m; j++ {
+		ops = append(ops, diffOp{opType: diffInsert, line: newLines[j]})
+	}
+	return ops
+}
@@ -0,0 +1,56 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package common_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	testcases := []struct {
+		name    string
+		oldText string
+		newText string
+		want    string
+	}{
+		{name: "same texts", oldText: "a\nb\n", newText: "a\nb\n", want: ""},
+		{name: "new file", oldText: "", newText: "a\nb\n", want: "--- old\n+++ new\n@@ -0,0 +1,2 @@\n+a\n+b\n"},
+		{name: "deleted file", oldText: "a\n", newText: "", want: "--- old\n+++ new\n@@ -1 +0,0 @@\n-a\n"},
+		{
+			name:    "distant changes are in separate hunks",
+			oldText: "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n16\n17\n18\n19\n20\n",
+			newText: "1\n2\nX\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n16\n17\n18\n20\nY\n",
+			want:    "--- old\n+++ new\n@@ -1,6 +1,6 @@\n 1\n 2\n-3\n+X\n 4\n 5\n 6\n@@ -16,5 +16,5 @@\n 16\n 17\n 18\n-19\n 20\n+Y\n",
+		},
+		{
+			name:    "nearby changes are in the same hunk",
+			oldText: "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+			newText: "1\nX\n3\n4\n5\n6\n7\nY\n9\n10\n",
+			want:    "--- old\n+++ new\n@@ -1,10 +1,10 @@\n 1\n-2\n+X\n 3\n 4\n 5\n 6\n 7\n-8\n+Y\n 9\n 10\n",
+		},
+	}
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			if got := common.UnifiedDiff("old", "new", testcase.oldText, testcase.newText); got != testcase.want {
+				t.Fatalf("the diff is wrong. Difference:\n%s", cmp.Diff(testcase.want, got))
+			}
+		})
+	}
+}
@@ -17,6 +17,8 @@
 package download
 
 import (
+	"context"
+
 	"github.com/konveyor/move2kube/common"
 	"github.com/sirupsen/logrus"
 )
@@ -30,7 +32,7 @@ type DownloadOptions struct {
 
 // Downloader defines interface for downloaders
 type Downloader interface {
-	Download(DownloadOptions) (string, error)
+	Download(context.Context, DownloadOptions) (string, error)
 }
 
 // IsRemotePath checks if the provided string is a valid remote path or not
@@ -40,12 +42,17 @@ func IsRemotePath(str string) bool {
 
 // GetDownloadedPath downloads the content using suitable downloader and then returns the downloaded file path
 func GetDownloadedPath(contentURL string, downloadDestinationPath string, overwrite bool) string {
+	return GetDownloadedPathWithContext(context.Background(), contentURL, downloadDestinationPath, overwrite)
+}
+
+// GetDownloadedPathWithContext is the same as GetDownloadedPath but the download is aborted when the context is cancelled
+func GetDownloadedPathWithContext(ctx context.Context, contentURL string, downloadDestinationPath string, overwrite bool) string {
 	var err error
 	downloadedPath := ""
 	if common.IsHTTPURL(contentURL) {
 		content := HTTPContent{}
 		downloadOpts := DownloadOptions{ContentURL: contentURL, DownloadDestinationPath: downloadDestinationPath, Overwrite: overwrite}
-		downloadedPath, err = content.Download(downloadOpts)
+		downloadedPath, err = content.Download(ctx, downloadOpts)
 		if err != nil {
 			logrus.Fatalf("failed to download the content using http downloader. Error : %+v", err)
 		}
@@ -17,21 +17,34 @@
 package download
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"strings"
 
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/common/remotecache"
 	"github.com/sirupsen/logrus"
 )
 
+const (
+	// digestFragmentPrefix is the url fragment prefix used to pin the sha256 digest of the content
+	digestFragmentPrefix = "#sha256="
+	// httpTokenEnvKey is the environment variable containing the bearer token used for the downloads
+	httpTokenEnvKey = "M2K_HTTP_TOKEN"
+	// httpHostEnvKey is the environment variable containing the only host the bearer token is sent to
+	httpHostEnvKey = "M2K_HTTP_HOST"
+)
+
 // HTTPContent stores remote content config
 type HTTPContent struct {
 	ContentFilePath string
 }
 
 // Download downloads content from the given content URL
-func (content *HTTPContent) Download(downloadOptions DownloadOptions) (string, error) {
+func (content *HTTPContent) Download(ctx context.Context, downloadOptions DownloadOptions) (string, error) {
 	if downloadOptions.DownloadDestinationPath == "" {
 		return "", fmt.Errorf("the path where the content has to be downloaded is empty - %s", downloadOptions.DownloadDestinationPath)
 	}
@@ -48,29 +61,70 @@ func (content *HTTPContent) Download(downloadOptions DownloadOptions) (string, e
 	} else {
 		return downloadOptions.DownloadDestinationPath, nil
 	}
-	logrus.Infof("Downloading the content using http downloader into %s. This might take some time.", downloadOptions.DownloadDestinationPath)
+	contentURL, digest := getURLAndDigest(downloadOptions.ContentURL)
+	cacheOpts := remotecache.Options{Immutable: digest != "", Digest: digest}
+	if err := remotecache.Fetch(contentURL, downloadOptions.DownloadDestinationPath, cacheOpts, func() error {
+		return download(ctx, contentURL, downloadOptions.DownloadDestinationPath)
+	}); err != nil {
+		return "", err
+	}
+	content.ContentFilePath = downloadOptions.DownloadDestinationPath
+	return downloadOptions.DownloadDestinationPath, nil
+}
+
+// getURLAndDigest splits the optional sha256 digest from a url of the form <url>#sha256=<digest>
+func getURLAndDigest(contentURL string) (string, string) {
+	i := strings.LastIndex(contentURL, digestFragmentPrefix)
+	if i == -1 {
+		return contentURL, ""
+	}
+	return contentURL[:i], strings.ToLower(contentURL[i+len(digestFragmentPrefix):])
+}
+
+// setAuth sets the bearer token from the environment variable, if the content is on the host given by M2K_HTTP_HOST,
+// or the credentials for the host from the netrc file
+func setAuth(req *http.Request) {
+	if token := os.Getenv(httpTokenEnvKey); token != "" {
+		if common.IsCredentialsHost(httpHostEnvKey, req.URL.Hostname()) {
+			logrus.Debugf("using the token from the environment variable %s to download the content", httpTokenEnvKey)
+			req.Header.Set("Authorization", "Bearer "+token)
+			return
+		}
+		logrus.Debugf("not using the token from the environment variable %s to download the content since it is not on the host given by %s", httpTokenEnvKey, httpHostEnvKey)
+	}
+	if login, password, ok := common.GetNetrcCredentials(req.URL.Hostname()); ok {
+		logrus.Debugf("using the credentials from the netrc file to download the content from the host '%s'", req.URL.Hostname())
+		req.SetBasicAuth(login, password)
+	}
+}
+
+func download(ctx context.Context, contentURL, destinationPath string) error {
+	logrus.Infof("Downloading the content using http downloader into %s. This might take some time.", destinationPath)
 
-	out, err := os.Create(downloadOptions.DownloadDestinationPath)
+	out, err := os.Create(destinationPath)
 	if err != nil {
-		return "", fmt.Errorf("failed to create a file for the provided path - %s. Error : %+v", downloadOptions.DownloadDestinationPath, err)
+		return fmt.Errorf("failed to create a file for the provided path - %s. Error : %+v", destinationPath, err)
 	}
 	defer out.Close()
 
-	resp, err := http.Get(downloadOptions.ContentURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, contentURL, nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to http get content from the provided content url - %s. Error : %+v", downloadOptions.ContentURL, err)
+		return fmt.Errorf("failed to create a http request for the provided content url - %s. Error : %+v", contentURL, err)
+	}
+	setAuth(req)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to http get content from the provided content url - %s. Error : %+v", contentURL, err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("failed to http get content from the provided content url - %s. Received status code %d", downloadOptions.ContentURL, resp.StatusCode)
+		return fmt.Errorf("failed to http get content from the provided content url - %s. Received status code %d", contentURL, resp.StatusCode)
 	}
 
 	_, err = io.Copy(out, resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("failed to copy content from response to file. Error : %+v", err)
+		return fmt.Errorf("failed to copy content from response to file. Error : %+v", err)
 	}
-	content.ContentFilePath = downloadOptions.DownloadDestinationPath
-	return downloadOptions.DownloadDestinationPath, nil
-
+	return nil
 }
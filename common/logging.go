@@ -18,6 +18,8 @@ package common
 
 import (
 	"context"
+	"io"
+	"sync"
 
 	"github.com/sirupsen/logrus"
 )
@@ -45,3 +47,178 @@ func (hook *CleanupHook) Levels() []logrus.Level {
 		logrus.FatalLevel,
 	}
 }
+
+// LogLevel is the verbosity of a log entry
+type LogLevel uint32
+
+const (
+	// LogLevelPanic is the level of the logs that stop the run with a panic
+	LogLevelPanic LogLevel = iota
+	// LogLevelFatal is the level of the logs that stop the process
+	LogLevelFatal
+	// LogLevelError is the level of the errors
+	LogLevelError
+	// LogLevelWarn is the level of the warnings
+	LogLevelWarn
+	// LogLevelInfo is the level of the progress messages
+	LogLevelInfo
+	// LogLevelDebug is the level of the debugging messages
+	LogLevelDebug
+	// LogLevelTrace is the most verbose level
+	LogLevelTrace
+)
+
+var logrusLevels = map[LogLevel]logrus.Level{
+	LogLevelPanic: logrus.PanicLevel,
+	LogLevelFatal: logrus.FatalLevel,
+	LogLevelError: logrus.ErrorLevel,
+	LogLevelWarn:  logrus.WarnLevel,
+	LogLevelInfo:  logrus.InfoLevel,
+	LogLevelDebug: logrus.DebugLevel,
+	LogLevelTrace: logrus.TraceLevel,
+}
+
+// String returns the name of the level
+func (level LogLevel) String() string {
+	return level.toLogrus().String()
+}
+
+func (level LogLevel) toLogrus() logrus.Level {
+	if logrusLevel, ok := logrusLevels[level]; ok {
+		return logrusLevel
+	}
+	return logrus.TraceLevel
+}
+
+func logLevelFromLogrus(logrusLevel logrus.Level) LogLevel {
+	for level, l := range logrusLevels {
+		if l == logrusLevel {
+			return level
+		}
+	}
+	return LogLevelTrace
+}
+
+// Logger is the interface through which the logs generated by move2kube can be routed
+// into the structured logging system of a library consumer.
+type Logger interface {
+	// Log is called once for every log entry whose level is enabled
+	Log(level LogLevel, message string, fields map[string]interface{})
+}
+
+// LogrusLogger adapts a logrus logger to the Logger interface
+type LogrusLogger struct {
+	logger *logrus.Logger
+}
+
+// NewLogrusLogger returns a Logger that writes the log entries to the given logrus logger
+func NewLogrusLogger(logger *logrus.Logger) *LogrusLogger {
+	return &LogrusLogger{logger: logger}
+}
+
+// Log writes the entry to the underlying logrus logger
+func (l *LogrusLogger) Log(level LogLevel, message string, fields map[string]interface{}) {
+	l.logger.WithFields(fields).Log(level.toLogrus(), message)
+}
+
+type loggerContextKey struct{}
+
+type logLevelContextKey struct{}
+
+// WithLogger returns a context that makes the run started with it write its logs to the given logger
+func WithLogger(ctx context.Context, logger Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey{}, logger)
+}
+
+// WithLogLevel returns a context that makes the run started with it log at the given verbosity
+func WithLogLevel(ctx context.Context, level LogLevel) context.Context {
+	return context.WithValue(ctx, logLevelContextKey{}, level)
+}
+
+// UseContextLogger routes the logs to the logger and the level of the context until the returned function is called.
+// The logs of move2kube are written to the standard logrus logger, so only one run can use its own logger at a time.
+func UseContextLogger(ctx context.Context) (restore func()) {
+	logger, hasLogger := ctx.Value(loggerContextKey{}).(Logger)
+	level, hasLevel := ctx.Value(logLevelContextKey{}).(LogLevel)
+	if !hasLogger && !hasLevel {
+		return func() {}
+	}
+	loggerMutex.Lock()
+	defer loggerMutex.Unlock()
+	std := logrus.StandardLogger()
+	oldForwarder := activeForwarder
+	oldLevel := std.GetLevel()
+	if hasLogger {
+		setLogger(logger)
+	}
+	if hasLevel {
+		std.SetLevel(level.toLogrus())
+	}
+	return func() {
+		loggerMutex.Lock()
+		defer loggerMutex.Unlock()
+		if hasLogger {
+			if oldForwarder == nil {
+				setLogger(nil)
+			} else {
+				setLogger(oldForwarder.logger)
+			}
+		}
+		if hasLevel {
+			std.SetLevel(oldLevel)
+		}
+	}
+}
+
+// forwardingHook forwards the entries of the standard logrus logger to a Logger
+type forwardingHook struct {
+	logger Logger
+}
+
+var (
+	loggerMutex     sync.Mutex
+	activeForwarder *forwardingHook
+	originalOutput  io.Writer
+)
+
+// Fire forwards the entry
+func (hook *forwardingHook) Fire(entry *logrus.Entry) error {
+	hook.logger.Log(logLevelFromLogrus(entry.Level), entry.Message, entry.Data)
+	return nil
+}
+
+// Levels returns the levels on which the forwarding hook gets called
+func (hook *forwardingHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// setLogger routes all the logs to the given logger instead of the output of the standard logrus logger.
+// The logs of move2kube are written to the standard logrus logger, so they are forwarded using a hook on it.
+// The logger must not write to the standard logrus logger since its entries would be forwarded to it again.
+// Passing nil, or a LogrusLogger of the standard logrus logger, restores the default behaviour of writing to the standard logrus logger.
+func setLogger(logger Logger) {
+	std := logrus.StandardLogger()
+	if activeForwarder != nil {
+		hooks := logrus.LevelHooks{}
+		for level, levelHooks := range std.Hooks {
+			for _, hook := range levelHooks {
+				if hook != activeForwarder {
+					hooks[level] = append(hooks[level], hook)
+				}
+			}
+		}
+		std.ReplaceHooks(hooks)
+		std.SetOutput(originalOutput)
+		activeForwarder = nil
+	}
+	if logger == nil {
+		return
+	}
+	if logrusLogger, ok := logger.(*LogrusLogger); ok && logrusLogger.logger == std {
+		return
+	}
+	activeForwarder = &forwardingHook{logger: logger}
+	std.AddHook(activeForwarder)
+	originalOutput = std.Out
+	std.SetOutput(io.Discard)
+}
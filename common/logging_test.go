@@ -0,0 +1,122 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package common_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/sirupsen/logrus"
+)
+
+type recordingLogger struct {
+	messages []string
+	levels   []common.LogLevel
+}
+
+func (l *recordingLogger) Log(level common.LogLevel, message string, fields map[string]interface{}) {
+	l.messages = append(l.messages, message)
+	l.levels = append(l.levels, level)
+}
+
+func TestUseContextLogger(t *testing.T) {
+	std := logrus.StandardLogger()
+	output := &bytes.Buffer{}
+	oldOutput := std.Out
+	std.SetOutput(output)
+	defer std.SetOutput(oldOutput)
+	oldLevel := std.GetLevel()
+	defer std.SetLevel(oldLevel)
+	std.SetLevel(logrus.InfoLevel)
+
+	t.Run("the logs are forwarded to the logger of the context instead of the output", func(t *testing.T) {
+		logger := &recordingLogger{}
+		restore := common.UseContextLogger(common.WithLogger(context.Background(), logger))
+		logrus.Warn("forwarded")
+		restore()
+		if len(logger.messages) != 1 || logger.messages[0] != "forwarded" || logger.levels[0] != common.LogLevelWarn {
+			t.Fatalf("expected the log to be forwarded as a warning. Actual: %+v %+v", logger.messages, logger.levels)
+		}
+		if output.Len() != 0 {
+			t.Fatalf("expected nothing to be written to the output. Actual: %s", output.String())
+		}
+	})
+
+	t.Run("the output and the hooks are restored", func(t *testing.T) {
+		logger := &recordingLogger{}
+		common.UseContextLogger(common.WithLogger(context.Background(), logger))()
+		logrus.Warn("not forwarded")
+		if len(logger.messages) != 0 {
+			t.Fatalf("expected the log to not be forwarded. Actual: %+v", logger.messages)
+		}
+		if !strings.Contains(output.String(), "not forwarded") {
+			t.Fatalf("expected the log to be written to the output. Actual: %s", output.String())
+		}
+	})
+
+	t.Run("the level of the context is used only during the run", func(t *testing.T) {
+		logger := &recordingLogger{}
+		ctx := common.WithLogLevel(common.WithLogger(context.Background(), logger), common.LogLevelDebug)
+		restore := common.UseContextLogger(ctx)
+		logrus.Debug("debug message")
+		restore()
+		logrus.Debug("after the run")
+		if len(logger.messages) != 1 || logger.messages[0] != "debug message" || logger.levels[0] != common.LogLevelDebug {
+			t.Fatalf("expected only the debug log of the run to be forwarded. Actual: %+v %+v", logger.messages, logger.levels)
+		}
+		if std.GetLevel() != logrus.InfoLevel {
+			t.Fatalf("expected the level to be restored. Actual: %s", std.GetLevel())
+		}
+	})
+
+	t.Run("the logger of an outer run is restored", func(t *testing.T) {
+		outer := &recordingLogger{}
+		inner := &recordingLogger{}
+		restoreOuter := common.UseContextLogger(common.WithLogger(context.Background(), outer))
+		common.UseContextLogger(common.WithLogger(context.Background(), inner))()
+		logrus.Warn("outer")
+		restoreOuter()
+		if len(outer.messages) != 1 || len(inner.messages) != 0 {
+			t.Fatalf("expected the log to be forwarded to the outer logger. Actual: %+v %+v", outer.messages, inner.messages)
+		}
+	})
+
+	t.Run("the standard logger is not forwarded to itself", func(t *testing.T) {
+		output.Reset()
+		restore := common.UseContextLogger(common.WithLogger(context.Background(), common.NewLogrusLogger(std)))
+		logrus.Warn("written once")
+		restore()
+		if count := strings.Count(output.String(), "written once"); count != 1 {
+			t.Fatalf("expected the log to be written once. Actual: %d times in %s", count, output.String())
+		}
+	})
+
+	t.Run("the logs are written to another logrus logger", func(t *testing.T) {
+		otherOutput := &bytes.Buffer{}
+		other := logrus.New()
+		other.SetOutput(otherOutput)
+		restore := common.UseContextLogger(common.WithLogger(context.Background(), common.NewLogrusLogger(other)))
+		logrus.WithField("service", "web").Warn("to the other logger")
+		restore()
+		if !strings.Contains(otherOutput.String(), "to the other logger") || !strings.Contains(otherOutput.String(), "service=web") || !strings.Contains(otherOutput.String(), "level=warning") {
+			t.Fatalf("expected the log to be written to the other logger. Actual: %s", otherOutput.String())
+		}
+	})
+}
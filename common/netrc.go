@@ -0,0 +1,134 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package common
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// netrcEnvKey is the environment variable that can be used to override the path of the netrc file
+	netrcEnvKey = "NETRC"
+)
+
+// getNetrcPath returns the path of the netrc file
+func getNetrcPath() string {
+	if netrcPath := os.Getenv(netrcEnvKey); netrcPath != "" {
+		return netrcPath
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		logrus.Debugf("failed to get the home directory. Error: %q", err)
+		return ""
+	}
+	if runtime.GOOS == "windows" {
+		return filepath.Join(homeDir, "_netrc")
+	}
+	return filepath.Join(homeDir, ".netrc")
+}
+
+// GetNetrcCredentials returns the login and password for the host from the netrc file.
+// The default entry is ignored, so that the credentials are only sent to the hosts they are given for.
+func GetNetrcCredentials(host string) (login string, password string, found bool) {
+	netrcPath := getNetrcPath()
+	if netrcPath == "" {
+		return "", "", false
+	}
+	netrcBytes, err := os.ReadFile(netrcPath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logrus.Debugf("failed to read the netrc file at path '%s' . Error: %q", netrcPath, err)
+		}
+		return "", "", false
+	}
+	return parseNetrc(string(netrcBytes), host)
+}
+
+// parseNetrc returns the login and password for the host from the contents of a netrc file
+func parseNetrc(netrc, host string) (string, string, bool) {
+	type netrcEntry struct {
+		login    string
+		password string
+	}
+	var current, hostEntry *netrcEntry
+	inMacro := false
+	for _, line := range strings.Split(netrc, "\n") {
+		fields := strings.Fields(line)
+		if inMacro {
+			// a macro definition ends at an empty line
+			inMacro = len(fields) != 0
+			continue
+		}
+		for i := 0; i < len(fields); i++ {
+			switch fields[i] {
+			case "machine":
+				current = nil
+				if i+1 < len(fields) {
+					i++
+					if hostEntry == nil && strings.EqualFold(fields[i], host) {
+						hostEntry = &netrcEntry{}
+						current = hostEntry
+					}
+				}
+			case "default":
+				current = nil
+			case "login", "password", "account":
+				if i+1 >= len(fields) {
+					continue
+				}
+				i++
+				if current == nil {
+					continue
+				}
+				switch fields[i-1] {
+				case "login":
+					current.login = fields[i]
+				case "password":
+					current.password = fields[i]
+				}
+			case "macdef":
+				current = nil
+				inMacro = true
+				i = len(fields)
+			}
+		}
+	}
+	if hostEntry != nil {
+		return hostEntry.login, hostEntry.password, true
+	}
+	return "", "", false
+}
+
+// IsCredentialsHost returns true if the host is the one given in the environment variable.
+// The credentials given in the environment variables are only sent to that host, since the urls of the remote sources
+// can come from third parties, like the remote transformer catalog indexes.
+func IsCredentialsHost(hostEnvKey, host string) bool {
+	credentialsHost := strings.TrimSpace(os.Getenv(hostEnvKey))
+	if credentialsHost == "" {
+		return false
+	}
+	if parsedURL, err := url.Parse(credentialsHost); err == nil && parsedURL.Hostname() != "" {
+		credentialsHost = parsedURL.Hostname()
+	}
+	return strings.EqualFold(credentialsHost, host)
+}
@@ -0,0 +1,46 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package common
+
+import "testing"
+
+func TestParseNetrc(t *testing.T) {
+	netrc := "machine github.com\n  login user\n  password secret\n\ndefault login anonymous password leaked\n"
+	if login, password, ok := parseNetrc(netrc, "GitHub.com"); !ok || login != "user" || password != "secret" {
+		t.Fatalf("expected the credentials of the host. Actual: %s %s %t", login, password, ok)
+	}
+	if login, password, ok := parseNetrc(netrc, "example.com"); ok {
+		t.Fatalf("expected the default entry to be ignored for the other hosts. Actual: %s %s", login, password)
+	}
+}
+
+func TestIsCredentialsHost(t *testing.T) {
+	const hostEnvKey = "M2K_TEST_CREDENTIALS_HOST"
+	t.Setenv(hostEnvKey, "")
+	if IsCredentialsHost(hostEnvKey, "github.com") {
+		t.Fatalf("expected no host to get the credentials when the host is not given")
+	}
+	for _, credentialsHost := range []string{"github.com", "https://GitHub.com/"} {
+		t.Setenv(hostEnvKey, credentialsHost)
+		if !IsCredentialsHost(hostEnvKey, "github.com") {
+			t.Fatalf("expected the host github.com to get the credentials for %s", credentialsHost)
+		}
+		if IsCredentialsHost(hostEnvKey, "github.com.example.com") {
+			t.Fatalf("expected the other hosts not to get the credentials for %s", credentialsHost)
+		}
+	}
+}
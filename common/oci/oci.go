@@ -0,0 +1,154 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package oci
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/common/remotecache"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// ociPathPrefix is the prefix of the paths that refer to OCI artifacts
+	ociPathPrefix = "oci://"
+	// titleAnnotation is the annotation containing the file name of a layer, as set by oras
+	titleAnnotation = "org.opencontainers.image.title"
+	// unpackAnnotation marks the layers that contain a directory archived by oras
+	unpackAnnotation = "io.deis.oras.content.unpack"
+)
+
+// IsRemotePath returns true if the path refers to an OCI artifact of the form oci://<registry>/<repository>[:<tag>|@<digest>]
+func IsRemotePath(str string) bool {
+	return strings.HasPrefix(str, ociPathPrefix)
+}
+
+// GetPulledPathWithContext pulls the files in the OCI artifact into the directory with the given name inside the remote temp directory.
+// The files are the layers that have a title annotation, the same as the ones pushed by oras. Directories pushed by oras are extracted.
+// The artifact is treated as immutable if it is pinned using a digest.
+func GetPulledPathWithContext(ctx context.Context, ociPath, destDirName string) (string, error) {
+	ref, err := name.ParseReference(strings.TrimPrefix(ociPath, ociPathPrefix))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse the OCI artifact reference '%s' . Error: %w", ociPath, err)
+	}
+	tempPath, err := filepath.Abs(common.RemoteTempPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to make the temp path '%s' absolute. Error: %w", common.RemoteTempPath, err)
+	}
+	destPath := filepath.Join(tempPath, destDirName)
+	if err := os.RemoveAll(destPath); err != nil {
+		return "", fmt.Errorf("failed to remove the directory at path '%s' . Error: %w", destPath, err)
+	}
+	_, isDigest := ref.(name.Digest)
+	if err := remotecache.Fetch(ociPathPrefix+ref.Name(), destPath, remotecache.Options{Immutable: isDigest}, func() error {
+		return pull(ctx, ref, destPath)
+	}); err != nil {
+		return "", err
+	}
+	return destPath, nil
+}
+
+// pull writes the files in the OCI artifact to the destination directory
+func pull(ctx context.Context, ref name.Reference, destPath string) error {
+	logrus.Infof("Pulling the OCI artifact '%s' into '%s' . This might take some time.", ref.Name(), destPath)
+	options := []remote.Option{remote.WithContext(ctx), remote.WithAuthFromKeychain(authn.DefaultKeychain)}
+	desc, err := remote.Get(ref, options...)
+	if err != nil {
+		return fmt.Errorf("failed to get the manifest of the OCI artifact '%s' . Error: %w", ref.Name(), err)
+	}
+	manifest, err := v1.ParseManifest(bytes.NewReader(desc.Manifest))
+	if err != nil {
+		return fmt.Errorf("failed to parse the manifest of the OCI artifact '%s' . Error: %w", ref.Name(), err)
+	}
+	if err := os.MkdirAll(destPath, common.DefaultDirectoryPermission); err != nil {
+		return fmt.Errorf("failed to create the directory at path '%s' . Error: %w", destPath, err)
+	}
+	numFiles := 0
+	for _, layerDesc := range manifest.Layers {
+		title := layerDesc.Annotations[titleAnnotation]
+		if title == "" {
+			logrus.Debugf("skipping the layer '%s' of the OCI artifact '%s' since it does not have a title", layerDesc.Digest, ref.Name())
+			continue
+		}
+		if title != filepath.Base(title) || title == "." || title == ".." {
+			return fmt.Errorf("the title '%s' of the layer '%s' in the OCI artifact '%s' is not a valid file name", title, layerDesc.Digest, ref.Name())
+		}
+		layer, err := remote.Layer(ref.Context().Digest(layerDesc.Digest.String()), options...)
+		if err != nil {
+			return fmt.Errorf("failed to get the layer '%s' of the OCI artifact '%s' . Error: %w", layerDesc.Digest, ref.Name(), err)
+		}
+		if layerDesc.Annotations[unpackAnnotation] == "true" {
+			if err := extractLayer(layer, string(layerDesc.MediaType), destPath); err != nil {
+				return fmt.Errorf("failed to extract the directory '%s' from the OCI artifact '%s' . Error: %w", title, ref.Name(), err)
+			}
+		} else if err := writeLayer(layer, filepath.Join(destPath, title)); err != nil {
+			return fmt.Errorf("failed to write the file '%s' from the OCI artifact '%s' . Error: %w", title, ref.Name(), err)
+		}
+		numFiles++
+	}
+	if numFiles == 0 {
+		return fmt.Errorf("the OCI artifact '%s' does not contain any files", ref.Name())
+	}
+	return nil
+}
+
+// writeLayer writes the contents of the layer to the file. The digest of the contents is verified while reading.
+func writeLayer(layer v1.Layer, path string) error {
+	rc, err := layer.Compressed()
+	if err != nil {
+		return fmt.Errorf("failed to fetch the layer. Error: %w", err)
+	}
+	defer rc.Close()
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, common.DefaultFilePermission)
+	if err != nil {
+		return fmt.Errorf("failed to create the file at path '%s' . Error: %w", path, err)
+	}
+	defer f.Close()
+	if _, err := io.Copy(f, rc); err != nil {
+		return fmt.Errorf("failed to write the file at path '%s' . Error: %w", path, err)
+	}
+	return nil
+}
+
+// extractLayer extracts the tar archive in the layer into the destination directory
+func extractLayer(layer v1.Layer, mediaType, destPath string) error {
+	archive, err := os.CreateTemp(filepath.Dir(destPath), "oci-layer-*")
+	if err != nil {
+		return fmt.Errorf("failed to create a temporary file. Error: %w", err)
+	}
+	archive.Close()
+	defer os.Remove(archive.Name())
+	if err := writeLayer(layer, archive.Name()); err != nil {
+		return err
+	}
+	archiveFilename := "layer.tar"
+	if strings.Contains(mediaType, "gzip") {
+		archiveFilename = "layer.tar.gz"
+	}
+	return common.ExtractArchive(archive.Name(), archiveFilename, destPath)
+}
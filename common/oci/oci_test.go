@@ -0,0 +1,113 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package oci
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/konveyor/move2kube/common"
+)
+
+func TestGetPulledPathWithContext(t *testing.T) {
+	server := httptest.NewServer(registry.New())
+	defer server.Close()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get the working directory. Error: %q", err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatalf("failed to change the working directory. Error: %q", err)
+	}
+	defer os.Chdir(wd)
+
+	var dirArchive bytes.Buffer
+	gzipWriter := gzip.NewWriter(&dirArchive)
+	tarWriter := tar.NewWriter(gzipWriter)
+	transformerYaml := []byte("kind: Transformer\n")
+	if err := tarWriter.WriteHeader(&tar.Header{Name: "transformers/transformer.yaml", Mode: 0644, Size: int64(len(transformerYaml)), Typeflag: tar.TypeReg}); err != nil {
+		t.Fatalf("failed to write the tar header. Error: %q", err)
+	}
+	if _, err := tarWriter.Write(transformerYaml); err != nil {
+		t.Fatalf("failed to write the tar entry. Error: %q", err)
+	}
+	tarWriter.Close()
+	gzipWriter.Close()
+
+	img, err := mutate.Append(empty.Image,
+		mutate.Addendum{
+			Layer:       static.NewLayer([]byte("kind: TransformerOrdering\n"), types.MediaType("application/vnd.oci.image.layer.v1.tar")),
+			Annotations: map[string]string{titleAnnotation: "ordering.yaml"},
+		},
+		mutate.Addendum{
+			Layer:       static.NewLayer(dirArchive.Bytes(), types.OCILayer),
+			Annotations: map[string]string{titleAnnotation: "transformers", unpackAnnotation: "true"},
+		},
+		mutate.Addendum{
+			Layer: static.NewLayer([]byte("untitled"), types.OCILayer),
+		},
+	)
+	if err != nil {
+		t.Fatalf("failed to create the artifact. Error: %q", err)
+	}
+	repo := strings.TrimPrefix(server.URL, "http://") + "/policies/customizations"
+	ref, err := name.ParseReference(repo + ":v1")
+	if err != nil {
+		t.Fatalf("failed to parse the reference. Error: %q", err)
+	}
+	if err := remote.Write(ref, img); err != nil {
+		t.Fatalf("failed to push the artifact. Error: %q", err)
+	}
+	digest, err := img.Digest()
+	if err != nil {
+		t.Fatalf("failed to get the digest of the artifact. Error: %q", err)
+	}
+
+	for _, ociPath := range []string{"oci://" + repo + ":v1", "oci://" + repo + "@" + digest.String()} {
+		pulledPath, err := GetPulledPathWithContext(context.Background(), ociPath, common.RemoteCustomizationsFolder)
+		if err != nil {
+			t.Fatalf("failed to pull the artifact '%s' . Error: %q", ociPath, err)
+		}
+		entries, err := os.ReadDir(pulledPath)
+		if err != nil {
+			t.Fatalf("failed to read the pulled directory. Error: %q", err)
+		}
+		if len(entries) != 2 {
+			t.Fatalf("expected only the titled layers to be pulled. Actual: %+v", entries)
+		}
+		if data, err := os.ReadFile(filepath.Join(pulledPath, "transformers", "transformer.yaml")); err != nil || !bytes.Equal(data, transformerYaml) {
+			t.Fatalf("failed to extract the directory. Data: %q Error: %q", data, err)
+		}
+	}
+	if _, err := GetPulledPathWithContext(context.Background(), "oci://"+repo+"@sha256:"+strings.Repeat("0", 64), common.RemoteCustomizationsFolder); err == nil {
+		t.Fatalf("expected pulling a missing digest to fail")
+	}
+}
@@ -0,0 +1,289 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package remotecache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/filesystem"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	indexDirName   = "index"
+	contentDirName = "content"
+)
+
+// Options stores the options for fetching remote content through the cache
+type Options struct {
+	// Immutable is true if the content at the url never changes. Immutable entries never expire.
+	Immutable bool
+	// Digest is the expected sha256 digest of the content. The content is rejected if the digest does not match.
+	Digest string
+}
+
+// OfflineError is the error when remote content is required but we are not allowed to access the network
+type OfflineError struct {
+	URL string
+}
+
+// DigestMismatchError is the error when the digest of the fetched content does not match the expected digest
+type DigestMismatchError struct {
+	URL      string
+	Expected string
+	Actual   string
+}
+
+type indexEntry struct {
+	URL       string    `json:"url"`
+	Digest    string    `json:"digest"`
+	FetchedAt time.Time `json:"fetchedAt"`
+}
+
+var (
+	// cacheDir is the directory where the remote content is cached
+	// default empty string means caching is disabled
+	cacheDir = ""
+	// ttl is the duration after which the cached content is fetched again.
+	// The default zero value means only the immutable content is cached.
+	ttl = time.Duration(0)
+	// offline is true if remote content should only be read from the cache
+	offline = false
+)
+
+// Error returns the error message for offline mode
+func (e *OfflineError) Error() string {
+	return fmt.Sprintf("the remote content at '%s' is not available in the cache and network access is disabled in offline mode", e.URL)
+}
+
+// Error returns the error message for a digest mismatch
+func (e *DigestMismatchError) Error() string {
+	return fmt.Sprintf("the digest '%s' of the content fetched from '%s' does not match the expected digest '%s'", e.Actual, e.URL, e.Expected)
+}
+
+// SetCacheDir sets the directory where the remote content is cached. Empty string disables caching.
+func SetCacheDir(dir string) {
+	cacheDir = dir
+}
+
+// SetTTL sets the duration after which the cached content is fetched again
+func SetTTL(duration time.Duration) {
+	ttl = duration
+}
+
+// SetOffline sets whether remote content should only be read from the cache
+func SetOffline(isOffline bool) {
+	offline = isOffline
+}
+
+// IsOffline returns true if network access is disabled
+func IsOffline() bool {
+	return offline
+}
+
+// Fetch makes the content at the url available at the destination path.
+// If a valid cache entry exists, the cached content is copied to the destination.
+// Otherwise the fetch function is called to fetch the content into the destination and the result is cached.
+func Fetch(url, destination string, opts Options, fetch func() error) error {
+	if found, err := get(url, destination, opts); err != nil {
+		return err
+	} else if found {
+		return nil
+	}
+	if offline {
+		return &OfflineError{URL: url}
+	}
+	if err := fetch(); err != nil {
+		return err
+	}
+	digest, err := Digest(destination)
+	if err != nil {
+		return fmt.Errorf("failed to compute the digest of the content at '%s' . Error: %w", destination, err)
+	}
+	if opts.Digest != "" && digest != opts.Digest {
+		if err := os.RemoveAll(destination); err != nil {
+			logrus.Warnf("failed to remove the content fetched from '%s' at '%s' . Error: %q", url, destination, err)
+		}
+		return &DigestMismatchError{URL: url, Expected: opts.Digest, Actual: digest}
+	}
+	if cacheDir == "" || (ttl <= 0 && !opts.Immutable) {
+		return nil
+	}
+	if err := put(url, destination, digest); err != nil {
+		logrus.Warnf("failed to cache the content fetched from '%s' . Error: %q", url, err)
+	}
+	return nil
+}
+
+func get(url, destination string, opts Options) (bool, error) {
+	if cacheDir == "" {
+		return false, nil
+	}
+	indexPath := getIndexPath(url)
+	indexBytes, err := os.ReadFile(indexPath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logrus.Debugf("failed to read the cache index file at '%s' . Error: %q", indexPath, err)
+		}
+		return false, nil
+	}
+	entry := indexEntry{}
+	if err := json.Unmarshal(indexBytes, &entry); err != nil {
+		logrus.Debugf("failed to parse the cache index file at '%s' . Error: %q", indexPath, err)
+		return false, nil
+	}
+	if opts.Digest != "" && entry.Digest != opts.Digest {
+		logrus.Debugf("the cached content for '%s' has the digest '%s' instead of '%s'", url, entry.Digest, opts.Digest)
+		return false, nil
+	}
+	if !opts.Immutable {
+		if time.Since(entry.FetchedAt) > ttl {
+			if !offline {
+				logrus.Debugf("the cached content for '%s' has expired", url)
+				return false, nil
+			}
+			logrus.Warnf("Using the expired cached content for '%s' since we are in offline mode", url)
+		}
+		logrus.Warnf("The content at '%s' can change. Using the content cached at %s , which might be out of date.", url, entry.FetchedAt.Format(time.RFC3339))
+	}
+	contentPath := filepath.Join(cacheDir, contentDirName, entry.Digest)
+	if digest, err := Digest(contentPath); err != nil || digest != entry.Digest {
+		logrus.Warnf("The cached content for '%s' at '%s' is missing or corrupted. Removing it from the cache.", url, contentPath)
+		if err := os.RemoveAll(contentPath); err != nil {
+			logrus.Debugf("failed to remove the cached content at '%s' . Error: %q", contentPath, err)
+		}
+		if err := os.Remove(indexPath); err != nil {
+			logrus.Debugf("failed to remove the cache index file at '%s' . Error: %q", indexPath, err)
+		}
+		return false, nil
+	}
+	if err := copyContent(contentPath, destination); err != nil {
+		return false, fmt.Errorf("failed to copy the cached content for '%s' from '%s' to '%s' . Error: %w", url, contentPath, destination, err)
+	}
+	logrus.Infof("Using the cached content for '%s'", url)
+	return true, nil
+}
+
+func put(url, path, digest string) error {
+	contentPath := filepath.Join(cacheDir, contentDirName, digest)
+	if _, err := os.Stat(contentPath); os.IsNotExist(err) {
+		tempPath := contentPath + ".tmp"
+		if err := os.RemoveAll(tempPath); err != nil {
+			return fmt.Errorf("failed to remove the directory at '%s' . Error: %w", tempPath, err)
+		}
+		if err := copyContent(path, tempPath); err != nil {
+			return fmt.Errorf("failed to copy the content from '%s' to '%s' . Error: %w", path, tempPath, err)
+		}
+		if err := os.Rename(tempPath, contentPath); err != nil {
+			return fmt.Errorf("failed to rename '%s' to '%s' . Error: %w", tempPath, contentPath, err)
+		}
+	} else if err != nil {
+		return fmt.Errorf("failed to stat the cached content at '%s' . Error: %w", contentPath, err)
+	}
+	indexBytes, err := json.Marshal(indexEntry{URL: url, Digest: digest, FetchedAt: time.Now()})
+	if err != nil {
+		return fmt.Errorf("failed to marshal the cache index entry to json. Error: %w", err)
+	}
+	indexPath := getIndexPath(url)
+	if err := os.MkdirAll(filepath.Dir(indexPath), common.DefaultDirectoryPermission); err != nil {
+		return fmt.Errorf("failed to create the cache index directory at '%s' . Error: %w", filepath.Dir(indexPath), err)
+	}
+	if err := os.WriteFile(indexPath, indexBytes, common.DefaultFilePermission); err != nil {
+		return fmt.Errorf("failed to write the cache index file at '%s' . Error: %w", indexPath, err)
+	}
+	return nil
+}
+
+func getIndexPath(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(cacheDir, indexDirName, hex.EncodeToString(sum[:])+".json")
+}
+
+func copyContent(source, destination string) error {
+	fi, err := os.Stat(source)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(destination), common.DefaultDirectoryPermission); err != nil {
+		return err
+	}
+	if fi.IsDir() {
+		return filesystem.Replicate(source, destination)
+	}
+	return common.CopyFile(destination, source)
+}
+
+// Digest returns the sha256 digest of a file or a directory.
+// The digest of a directory covers the relative paths and the contents of all the files in it.
+func Digest(path string) (string, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if !fi.IsDir() {
+		return hashFile(path)
+	}
+	files := []string{}
+	if err := filepath.WalkDir(path, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			files = append(files, filePath)
+		}
+		return nil
+	}); err != nil {
+		return "", err
+	}
+	sort.Strings(files)
+	hasher := sha256.New()
+	for _, filePath := range files {
+		fileHash, err := hashFile(filePath)
+		if err != nil {
+			return "", err
+		}
+		relPath, err := filepath.Rel(path, filePath)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(hasher, "%s %s\n", filepath.ToSlash(relPath), fileHash)
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
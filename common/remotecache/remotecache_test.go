@@ -0,0 +1,148 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package remotecache
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFetch(t *testing.T) {
+	setup := func(t *testing.T) (string, *int, func() error) {
+		SetCacheDir(t.TempDir())
+		SetTTL(time.Hour)
+		SetOffline(false)
+		t.Cleanup(func() {
+			SetCacheDir("")
+			SetTTL(0)
+			SetOffline(false)
+		})
+		dest := filepath.Join(t.TempDir(), "repo")
+		fetches := 0
+		fetch := func() error {
+			fetches++
+			if err := os.MkdirAll(dest, 0755); err != nil {
+				return err
+			}
+			return os.WriteFile(filepath.Join(dest, "m2k.yaml"), []byte("foo: bar\n"), 0644)
+		}
+		return dest, &fetches, fetch
+	}
+
+	t.Run("cached content is reused", func(t *testing.T) {
+		dest, fetches, fetch := setup(t)
+		if err := Fetch("https://example.com/repo", dest, Options{}, fetch); err != nil {
+			t.Fatalf("failed to fetch. Error: %q", err)
+		}
+		if err := os.RemoveAll(dest); err != nil {
+			t.Fatal(err)
+		}
+		if err := Fetch("https://example.com/repo", dest, Options{}, fetch); err != nil {
+			t.Fatalf("failed to fetch. Error: %q", err)
+		}
+		if *fetches != 1 {
+			t.Fatalf("expected the content to be fetched once. Actual: %d", *fetches)
+		}
+		if data, err := os.ReadFile(filepath.Join(dest, "m2k.yaml")); err != nil || string(data) != "foo: bar\n" {
+			t.Fatalf("the cached content was not copied to the destination. Error: %v", err)
+		}
+	})
+
+	t.Run("expired content is fetched again", func(t *testing.T) {
+		dest, fetches, fetch := setup(t)
+		SetTTL(time.Nanosecond)
+		if err := Fetch("https://example.com/repo", dest, Options{}, fetch); err != nil {
+			t.Fatalf("failed to fetch. Error: %q", err)
+		}
+		time.Sleep(time.Millisecond)
+		if err := Fetch("https://example.com/repo", dest, Options{}, fetch); err != nil {
+			t.Fatalf("failed to fetch. Error: %q", err)
+		}
+		if *fetches != 2 {
+			t.Fatalf("expected the content to be fetched twice. Actual: %d", *fetches)
+		}
+	})
+
+	t.Run("offline mode fails fast when content is not cached", func(t *testing.T) {
+		dest, fetches, fetch := setup(t)
+		SetOffline(true)
+		err := Fetch("https://example.com/repo", dest, Options{}, fetch)
+		offlineErr := &OfflineError{}
+		if !errors.As(err, &offlineErr) {
+			t.Fatalf("expected an offline error. Actual: %v", err)
+		}
+		if *fetches != 0 {
+			t.Fatalf("expected the content to not be fetched. Actual: %d", *fetches)
+		}
+	})
+
+	t.Run("corrupted cache content is fetched again", func(t *testing.T) {
+		dest, fetches, fetch := setup(t)
+		if err := Fetch("https://example.com/repo", dest, Options{}, fetch); err != nil {
+			t.Fatalf("failed to fetch. Error: %q", err)
+		}
+		digest, err := Digest(dest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(cacheDir, contentDirName, digest, "m2k.yaml"), []byte("corrupted"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := Fetch("https://example.com/repo", dest, Options{}, fetch); err != nil {
+			t.Fatalf("failed to fetch. Error: %q", err)
+		}
+		if *fetches != 2 {
+			t.Fatalf("expected the content to be fetched twice. Actual: %d", *fetches)
+		}
+	})
+
+	t.Run("content with the wrong digest is rejected", func(t *testing.T) {
+		dest, _, fetch := setup(t)
+		err := Fetch("https://example.com/repo", dest, Options{Digest: "1234"}, fetch)
+		mismatchErr := &DigestMismatchError{}
+		if !errors.As(err, &mismatchErr) {
+			t.Fatalf("expected a digest mismatch error. Actual: %v", err)
+		}
+		if _, err := os.Stat(dest); !os.IsNotExist(err) {
+			t.Fatalf("expected the rejected content to be removed. Error: %v", err)
+		}
+	})
+
+	t.Run("only the immutable content is cached by default", func(t *testing.T) {
+		dest, fetches, fetch := setup(t)
+		SetTTL(0)
+		for i := 0; i < 2; i++ {
+			if err := Fetch("https://example.com/repo@main", dest, Options{}, fetch); err != nil {
+				t.Fatalf("failed to fetch. Error: %q", err)
+			}
+		}
+		if *fetches != 2 {
+			t.Fatalf("expected the mutable content to be fetched every time. Actual: %d", *fetches)
+		}
+		for i := 0; i < 2; i++ {
+			if err := Fetch("https://example.com/repo@0123abc", dest, Options{Immutable: true}, fetch); err != nil {
+				t.Fatalf("failed to fetch. Error: %q", err)
+			}
+		}
+		if *fetches != 3 {
+			t.Fatalf("expected the immutable content to be fetched once. Actual: %d", *fetches-2)
+		}
+	})
+}
@@ -0,0 +1,303 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/konveyor/move2kube/common"
+	reporttypes "github.com/konveyor/move2kube/types/report"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// ReportFileBaseName is the name of the migration report files without the extension
+	ReportFileBaseName = "m2kreport"
+	// redactedAnswer replaces the answers to the password questions in the report
+	redactedAnswer = "<redacted>"
+	// sourceDateEpochEnvKey is the environment variable with the unix time to stamp on the report
+	sourceDateEpochEnvKey = "SOURCE_DATE_EPOCH"
+)
+
+var (
+	mutex         sync.Mutex
+	currentReport = reporttypes.NewMigrationReport()
+)
+
+// Reset clears the report recorded so far
+func Reset() {
+	mutex.Lock()
+	defer mutex.Unlock()
+	currentReport = reporttypes.NewMigrationReport()
+}
+
+// AddService records the transformer and the source artifacts of a service
+func AddService(serviceName, transformerName string, sourcePaths map[transformertypes.PathType][]string) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	currentReport.Spec.Services = append(currentReport.Spec.Services, reporttypes.ServiceReport{Name: serviceName, Transformer: transformerName, SourcePaths: flattenPaths(sourcePaths)})
+}
+
+// AddGeneratedArtifact records an artifact produced by a transformer
+func AddGeneratedArtifact(transformerName string, artifact transformertypes.Artifact) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	currentReport.Spec.GeneratedArtifacts = append(currentReport.Spec.GeneratedArtifacts, reporttypes.GeneratedArtifact{
+		Transformer: transformerName,
+		Name:        artifact.Name,
+		Type:        string(artifact.Type),
+		Paths:       flattenPaths(artifact.Paths),
+	})
+}
+
+// AddDroppedField records a field of a service that could not be converted, along with the reason
+func AddDroppedField(serviceName, field, reason string) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	currentReport.Spec.DroppedFields = append(currentReport.Spec.DroppedFields, reporttypes.DroppedField{Service: serviceName, Field: field, Reason: reason})
+}
+
+// AddFollowUp records a manual step that must be done before deploying the output
+func AddFollowUp(serviceName, description string) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	currentReport.Spec.FollowUps = append(currentReport.Spec.FollowUps, reporttypes.FollowUp{Service: serviceName, Description: description})
+}
+
+// AddQAAnswer records the answer used for a question. The answers to password questions are redacted.
+// A question that is asked again only keeps its latest answer.
+func AddQAAnswer(id, question string, answer interface{}, isPassword bool) {
+	if isPassword {
+		answer = redactedAnswer
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	qaAnswer := reporttypes.QAAnswer{ID: id, Question: question, Answer: answer}
+	for i, a := range currentReport.Spec.QAAnswers {
+		if a.ID == id {
+			currentReport.Spec.QAAnswers[i] = qaAnswer
+			return
+		}
+	}
+	currentReport.Spec.QAAnswers = append(currentReport.Spec.QAAnswers, qaAnswer)
+}
+
+// Get returns the report recorded so far.
+// The paths inside the source and output directories are made relative to them.
+func Get(sourceDir, outputDir string) reporttypes.MigrationReport {
+	mutex.Lock()
+	defer mutex.Unlock()
+	r := reporttypes.NewMigrationReport()
+	r.Name = common.ProjectName
+	r.Spec = currentReport.Spec
+	r.Spec.GeneratedAt = getGeneratedAt()
+	r.Spec.Services = make([]reporttypes.ServiceReport, len(currentReport.Spec.Services))
+	for i, s := range currentReport.Spec.Services {
+		s.SourcePaths = relPaths(s.SourcePaths, sourceDir)
+		r.Spec.Services[i] = s
+	}
+	sort.SliceStable(r.Spec.Services, func(i, j int) bool { return r.Spec.Services[i].Name < r.Spec.Services[j].Name })
+	r.Spec.GeneratedArtifacts = make([]reporttypes.GeneratedArtifact, len(currentReport.Spec.GeneratedArtifacts))
+	for i, a := range currentReport.Spec.GeneratedArtifacts {
+		a.Paths = relPaths(relPaths(a.Paths, outputDir), sourceDir)
+		r.Spec.GeneratedArtifacts[i] = a
+	}
+	r.Spec.DroppedFields = append([]reporttypes.DroppedField{}, currentReport.Spec.DroppedFields...)
+	r.Spec.QAAnswers = append([]reporttypes.QAAnswer{}, currentReport.Spec.QAAnswers...)
+	r.Spec.FollowUps = append([]reporttypes.FollowUp{}, currentReport.Spec.FollowUps...)
+	return r
+}
+
+// getGeneratedAt returns the time given by SOURCE_DATE_EPOCH.
+// The report is not stamped with the current time, so that identical runs produce identical reports.
+func getGeneratedAt() string {
+	epoch := os.Getenv(sourceDateEpochEnvKey)
+	if epoch == "" {
+		return ""
+	}
+	seconds, err := strconv.ParseInt(epoch, 10, 64)
+	if err != nil {
+		logrus.Warnf("ignoring the invalid value '%s' of the environment variable %s . Error: %q", epoch, sourceDateEpochEnvKey, err)
+		return ""
+	}
+	return time.Unix(seconds, 0).UTC().Format(time.RFC3339)
+}
+
+// Write writes the report as JSON, Markdown and HTML into the output directory
+func Write(r reporttypes.MigrationReport, outputDir string) error {
+	jsonBytes, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal the migration report to json. Error: %w", err)
+	}
+	markdown := bytes.Buffer{}
+	if err := markdownTemplate.Execute(&markdown, r); err != nil {
+		return fmt.Errorf("failed to render the migration report as markdown. Error: %w", err)
+	}
+	html := bytes.Buffer{}
+	if err := htmlTemplate.Execute(&html, r); err != nil {
+		return fmt.Errorf("failed to render the migration report as html. Error: %w", err)
+	}
+	for ext, data := range map[string][]byte{".json": jsonBytes, ".md": markdown.Bytes(), ".html": html.Bytes()} {
+		path := filepath.Join(outputDir, ReportFileBaseName+ext)
+		if err := os.WriteFile(path, data, common.DefaultFilePermission); err != nil {
+			return fmt.Errorf("failed to write the migration report to '%s' . Error: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// flattenPaths returns the sorted unique paths of all the path types
+func flattenPaths(paths map[transformertypes.PathType][]string) []string {
+	uniquePaths := map[string]bool{}
+	for _, ps := range paths {
+		for _, p := range ps {
+			uniquePaths[p] = true
+		}
+	}
+	flattened := []string{}
+	for p := range uniquePaths {
+		flattened = append(flattened, p)
+	}
+	sort.Strings(flattened)
+	return flattened
+}
+
+// relPaths makes the paths inside the base directory relative to it
+func relPaths(paths []string, baseDir string) []string {
+	if baseDir == "" || len(paths) == 0 {
+		return paths
+	}
+	rels := []string{}
+	for _, path := range paths {
+		if rel, err := filepath.Rel(baseDir, path); err == nil && !strings.HasPrefix(rel, "..") {
+			path = filepath.ToSlash(rel)
+		}
+		rels = append(rels, path)
+	}
+	return rels
+}
+
+// FormatAnswer formats an answer for display in the reports
+func FormatAnswer(answer interface{}) string {
+	switch a := answer.(type) {
+	case []string:
+		return strings.Join(a, ", ")
+	case []interface{}:
+		parts := []string{}
+		for _, v := range a {
+			parts = append(parts, fmt.Sprintf("%v", v))
+		}
+		return strings.Join(parts, ", ")
+	}
+	return fmt.Sprintf("%v", answer)
+}
+
+var templateFuncs = map[string]interface{}{
+	"join":   strings.Join,
+	"answer": FormatAnswer,
+}
+
+var markdownTemplate = template.Must(template.New("markdown").Funcs(templateFuncs).Parse(`# Migration report{{if .Name}} for {{.Name}}{{end}}
+
+Generated{{if .Spec.GeneratedAt}} at {{.Spec.GeneratedAt}}{{end}}{{if .Spec.Move2KubeVersion}} by move2kube {{.Spec.Move2KubeVersion}}{{end}}.
+
+## Services
+
+| Service | Transformer | Source artifacts |
+| --- | --- | --- |
+{{range .Spec.Services}}| {{.Name}} | {{.Transformer}} | {{join .SourcePaths ", "}} |
+{{end}}
+## Generated artifacts
+
+| Transformer | Artifact | Type | Paths |
+| --- | --- | --- | --- |
+{{range .Spec.GeneratedArtifacts}}| {{.Transformer}} | {{.Name}} | {{.Type}} | {{join .Paths ", "}} |
+{{end}}
+## Dropped and unsupported fields
+{{if .Spec.DroppedFields}}
+| Service | Field | Reason |
+| --- | --- | --- |
+{{range .Spec.DroppedFields}}| {{.Service}} | {{.Field}} | {{.Reason}} |
+{{end}}{{else}}
+None.
+{{end}}
+## Manual follow-up
+{{if .Spec.FollowUps}}
+{{range .Spec.FollowUps}}- [ ] {{if .Service}}**{{.Service}}**: {{end}}{{.Description}}
+{{end}}{{else}}
+None.
+{{end}}
+## Answers used
+
+| Question | Answer |
+| --- | --- |
+{{range .Spec.QAAnswers}}| {{.ID}} | {{answer .Answer}} |
+{{end}}`))
+
+var htmlTemplate = htmltemplate.Must(htmltemplate.New("html").Funcs(templateFuncs).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Migration report{{if .Name}} for {{.Name}}{{end}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; vertical-align: top; }
+</style>
+</head>
+<body>
+<h1>Migration report{{if .Name}} for {{.Name}}{{end}}</h1>
+<p>Generated{{if .Spec.GeneratedAt}} at {{.Spec.GeneratedAt}}{{end}}{{if .Spec.Move2KubeVersion}} by move2kube {{.Spec.Move2KubeVersion}}{{end}}.</p>
+<h2>Services</h2>
+<table>
+<tr><th>Service</th><th>Transformer</th><th>Source artifacts</th></tr>
+{{range .Spec.Services}}<tr><td>{{.Name}}</td><td>{{.Transformer}}</td><td>{{join .SourcePaths ", "}}</td></tr>
+{{end}}</table>
+<h2>Generated artifacts</h2>
+<table>
+<tr><th>Transformer</th><th>Artifact</th><th>Type</th><th>Paths</th></tr>
+{{range .Spec.GeneratedArtifacts}}<tr><td>{{.Transformer}}</td><td>{{.Name}}</td><td>{{.Type}}</td><td>{{join .Paths ", "}}</td></tr>
+{{end}}</table>
+<h2>Dropped and unsupported fields</h2>
+{{if .Spec.DroppedFields}}<table>
+<tr><th>Service</th><th>Field</th><th>Reason</th></tr>
+{{range .Spec.DroppedFields}}<tr><td>{{.Service}}</td><td>{{.Field}}</td><td>{{.Reason}}</td></tr>
+{{end}}</table>{{else}}<p>None.</p>{{end}}
+<h2>Manual follow-up</h2>
+{{if .Spec.FollowUps}}<ul>
+{{range .Spec.FollowUps}}<li>{{if .Service}}<b>{{.Service}}</b>: {{end}}{{.Description}}</li>
+{{end}}</ul>{{else}}<p>None.</p>{{end}}
+<h2>Answers used</h2>
+<table>
+<tr><th>Question</th><th>Answer</th></tr>
+{{range .Spec.QAAnswers}}<tr><td title="{{.Question}}">{{.ID}}</td><td>{{answer .Answer}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
@@ -0,0 +1,123 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package report
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	reporttypes "github.com/konveyor/move2kube/types/report"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+)
+
+func TestReport(t *testing.T) {
+	sourceDir := filepath.Join(string(filepath.Separator), "src")
+	outputDir := filepath.Join(string(filepath.Separator), "out")
+	setup := func() {
+		Reset()
+		AddService("web", "ComposeAnalyser", map[transformertypes.PathType][]string{"DockerCompose": {filepath.Join(sourceDir, "docker-compose.yaml")}})
+		AddGeneratedArtifact("Kubernetes", transformertypes.Artifact{
+			Name:  "web",
+			Type:  "KubernetesYamls",
+			Paths: map[transformertypes.PathType][]string{"KubernetesYamls": {filepath.Join(outputDir, "deploy", "yamls")}},
+		})
+		AddDroppedField("web", "restart", "not supported")
+		AddFollowUp("web", "Fill in the secret <db-password>")
+		AddQAAnswer("move2kube.services.web.port", "Select the port", 8080, false)
+		AddQAAnswer("move2kube.registry.password", "Enter the password", "hunter2", true)
+	}
+
+	t.Run("paths are made relative and passwords are redacted", func(t *testing.T) {
+		setup()
+		r := Get(sourceDir, outputDir)
+		if !reflect.DeepEqual(r.Spec.Services[0].SourcePaths, []string{"docker-compose.yaml"}) {
+			t.Fatalf("expected the source paths to be relative to the source directory. Actual: %+v", r.Spec.Services[0].SourcePaths)
+		}
+		if !reflect.DeepEqual(r.Spec.GeneratedArtifacts[0].Paths, []string{"deploy/yamls"}) {
+			t.Fatalf("expected the artifact paths to be relative to the output directory. Actual: %+v", r.Spec.GeneratedArtifacts[0].Paths)
+		}
+		if r.Spec.QAAnswers[1].Answer != redactedAnswer {
+			t.Fatalf("expected the password to be redacted. Actual: %v", r.Spec.QAAnswers[1].Answer)
+		}
+		Reset()
+		if r := Get(sourceDir, outputDir); len(r.Spec.Services)+len(r.Spec.DroppedFields)+len(r.Spec.QAAnswers) != 0 {
+			t.Fatalf("expected an empty report after reset. Actual: %+v", r.Spec)
+		}
+	})
+
+	t.Run("questions asked again keep only the latest answer", func(t *testing.T) {
+		setup()
+		AddQAAnswer("move2kube.services.web.port", "Select the port", 9090, false)
+		r := Get(sourceDir, outputDir)
+		if len(r.Spec.QAAnswers) != 2 || r.Spec.QAAnswers[0].Answer != 9090 {
+			t.Fatalf("expected the answer to be replaced. Actual: %+v", r.Spec.QAAnswers)
+		}
+	})
+
+	t.Run("report is stamped only with the time given by SOURCE_DATE_EPOCH", func(t *testing.T) {
+		setup()
+		t.Setenv(sourceDateEpochEnvKey, "")
+		if r := Get(sourceDir, outputDir); r.Spec.GeneratedAt != "" {
+			t.Fatalf("expected the report to not have a timestamp. Actual: %s", r.Spec.GeneratedAt)
+		}
+		t.Setenv(sourceDateEpochEnvKey, "1700000000")
+		if r := Get(sourceDir, outputDir); r.Spec.GeneratedAt != "2023-11-14T22:13:20Z" {
+			t.Fatalf("expected the report to be stamped with the time given by %s . Actual: %s", sourceDateEpochEnvKey, r.Spec.GeneratedAt)
+		}
+		t.Setenv(sourceDateEpochEnvKey, "yesterday")
+		if r := Get(sourceDir, outputDir); r.Spec.GeneratedAt != "" {
+			t.Fatalf("expected an invalid %s to be ignored. Actual: %s", sourceDateEpochEnvKey, r.Spec.GeneratedAt)
+		}
+	})
+
+	t.Run("report is written as json, markdown and html", func(t *testing.T) {
+		setup()
+		dir := t.TempDir()
+		if err := Write(Get(sourceDir, outputDir), dir); err != nil {
+			t.Fatalf("failed to write the report. Error: %q", err)
+		}
+		jsonBytes, err := os.ReadFile(filepath.Join(dir, ReportFileBaseName+".json"))
+		if err != nil {
+			t.Fatalf("failed to read the json report. Error: %q", err)
+		}
+		r := reporttypes.MigrationReport{}
+		if err := json.Unmarshal(jsonBytes, &r); err != nil {
+			t.Fatalf("failed to parse the json report. Error: %q", err)
+		}
+		if r.Kind != string(reporttypes.MigrationReportKind) || len(r.Spec.DroppedFields) != 1 || r.Spec.DroppedFields[0].Field != "restart" {
+			t.Fatalf("unexpected json report: %+v", r)
+		}
+		markdown, err := os.ReadFile(filepath.Join(dir, ReportFileBaseName+".md"))
+		if err != nil {
+			t.Fatalf("failed to read the markdown report. Error: %q", err)
+		}
+		if !strings.Contains(string(markdown), "| web | restart | not supported |") || !strings.Contains(string(markdown), "- [ ] **web**: Fill in the secret <db-password>") {
+			t.Fatalf("unexpected markdown report:\n%s", markdown)
+		}
+		html, err := os.ReadFile(filepath.Join(dir, ReportFileBaseName+".html"))
+		if err != nil {
+			t.Fatalf("failed to read the html report. Error: %q", err)
+		}
+		if !strings.Contains(string(html), "Fill in the secret &lt;db-password&gt;") || strings.Contains(string(html), "hunter2") {
+			t.Fatalf("expected the html report to be escaped and redacted:\n%s", html)
+		}
+	})
+}
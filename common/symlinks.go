@@ -0,0 +1,122 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package common
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// SymlinkPolicyType is how the symbolic links in the source directories are handled
+type SymlinkPolicyType string
+
+const (
+	// FollowSymlinkPolicy walks the targets of the symbolic links and keeps the links as links when copying
+	FollowSymlinkPolicy SymlinkPolicyType = "follow"
+	// CopySymlinkPolicy walks the targets of the symbolic links and copies the targets in place of the links
+	CopySymlinkPolicy SymlinkPolicyType = "copy"
+	// SkipSymlinkPolicy skips the symbolic links with a warning
+	SkipSymlinkPolicy SymlinkPolicyType = "skip"
+)
+
+// SymlinkPolicy is the policy for the symbolic links found in the source directories.
+// The links are skipped by default, since their targets can be anywhere on the machine.
+var SymlinkPolicy = SkipSymlinkPolicy
+
+// warnedSymlinks are the symbolic links that have been warned about
+var warnedSymlinks = sync.Map{}
+
+// WarnAboutSymlink logs the warning about the symbolic link only once, since the source directories are walked by many transformers
+func WarnAboutSymlink(linkPath string, format string, args ...interface{}) {
+	if _, warned := warnedSymlinks.LoadOrStore(linkPath, true); warned {
+		logrus.Debugf(format, args...)
+		return
+	}
+	logrus.Warnf(format, args...)
+}
+
+// ParseSymlinkPolicy returns the symlink policy with the given name
+func ParseSymlinkPolicy(policy string) (SymlinkPolicyType, error) {
+	switch p := SymlinkPolicyType(policy); p {
+	case FollowSymlinkPolicy, CopySymlinkPolicy, SkipSymlinkPolicy:
+		return p, nil
+	}
+	return "", fmt.Errorf("the symlink policy '%s' is not one of %s, %s or %s", policy, FollowSymlinkPolicy, CopySymlinkPolicy, SkipSymlinkPolicy)
+}
+
+// WalkDir walks the directory tree like filepath.WalkDir while handling the symbolic links as per the SymlinkPolicy.
+// The targets of the links are walked as if they were at the paths of the links.
+// Links to one of the directories being walked are skipped since walking them would never end.
+func WalkDir(root string, fn fs.WalkDirFunc) error {
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return filepath.WalkDir(root, fn)
+	}
+	return walkDir(root, realRoot, nil, fn)
+}
+
+// walkDir walks the real directory realRoot reporting the paths relative to root.
+// linkedDirs are the real directories being walked that led to this directory through symbolic links.
+func walkDir(root, realRoot string, linkedDirs []string, fn fs.WalkDirFunc) error {
+	return filepath.WalkDir(realRoot, func(path string, d fs.DirEntry, err error) error {
+		reportedPath := root
+		if relPath, relErr := filepath.Rel(realRoot, path); relErr == nil && relPath != "." {
+			reportedPath = filepath.Join(root, relPath)
+		}
+		if err != nil || d.Type()&fs.ModeSymlink == 0 {
+			return fn(reportedPath, d, err)
+		}
+		if SymlinkPolicy == SkipSymlinkPolicy {
+			WarnAboutSymlink(reportedPath, "Skipping the symbolic link '%s' as per the symlink policy", reportedPath)
+			return nil
+		}
+		target, err := filepath.EvalSymlinks(path)
+		if err != nil {
+			WarnAboutSymlink(reportedPath, "Skipping the symbolic link '%s' since its target could not be resolved. Error: %q", reportedPath, err)
+			return nil
+		}
+		info, err := os.Stat(target)
+		if err != nil {
+			WarnAboutSymlink(reportedPath, "Skipping the symbolic link '%s' since its target '%s' could not be found. Error: %q", reportedPath, target, err)
+			return nil
+		}
+		if !info.IsDir() {
+			return fn(reportedPath, fs.FileInfoToDirEntry(info), nil)
+		}
+		walkedDirs := append(append([]string{}, linkedDirs...), realRoot)
+		if IsSymlinkLoop(path, target, walkedDirs...) {
+			WarnAboutSymlink(reportedPath, "Skipping the symbolic link '%s' since its target '%s' contains the link", reportedPath, target)
+			return nil
+		}
+		return walkDir(reportedPath, target, walkedDirs, fn)
+	})
+}
+
+// IsSymlinkLoop returns true if the target directory of the symbolic link contains the link or any of the given directories
+func IsSymlinkLoop(linkPath, target string, dirs ...string) bool {
+	for _, dir := range append(dirs, linkPath) {
+		if IsParent(dir, target) {
+			return true
+		}
+	}
+	return false
+}
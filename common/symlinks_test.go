@@ -0,0 +1,91 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package common_test
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+)
+
+func TestWalkDir(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "app", "config"), common.DefaultDirectoryPermission); err != nil {
+		t.Fatalf("failed to create the directories. Error: %q", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "app", "config", "app.env"), []byte("A=1"), common.DefaultFilePermission); err != nil {
+		t.Fatalf("failed to write the file. Error: %q", err)
+	}
+	for link, target := range map[string]string{
+		filepath.Join(root, "config"):                filepath.Join("app", "config"),
+		filepath.Join(root, ".env"):                  filepath.Join("app", "config", "app.env"),
+		filepath.Join(root, "app", "config", "loop"): filepath.Join("..", ".."),
+		filepath.Join(root, "missing"):               "doesnotexist",
+	} {
+		if err := os.Symlink(target, link); err != nil {
+			t.Fatalf("failed to create the symbolic link. Error: %q", err)
+		}
+	}
+	walk := func(policy common.SymlinkPolicyType) []string {
+		oldPolicy := common.SymlinkPolicy
+		defer func() { common.SymlinkPolicy = oldPolicy }()
+		common.SymlinkPolicy = policy
+		files := []string{}
+		if err := common.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() {
+				relPath, _ := filepath.Rel(root, path)
+				files = append(files, relPath)
+			}
+			return nil
+		}); err != nil {
+			t.Fatalf("failed to walk the directory. Error: %q", err)
+		}
+		sort.Strings(files)
+		return files
+	}
+	t.Run("follow the symbolic links without looping", func(t *testing.T) {
+		want := []string{".env", filepath.Join("app", "config", "app.env"), filepath.Join("config", "app.env")}
+		if diff := cmp.Diff(want, walk(common.FollowSymlinkPolicy)); diff != "" {
+			t.Fatalf("wrong files. Difference:\n%s", diff)
+		}
+	})
+	t.Run("skip the symbolic links by default", func(t *testing.T) {
+		want := []string{filepath.Join("app", "config", "app.env")}
+		if diff := cmp.Diff(want, walk(common.SymlinkPolicy)); diff != "" {
+			t.Fatalf("wrong files. Difference:\n%s", diff)
+		}
+		if diff := cmp.Diff(want, walk(common.SkipSymlinkPolicy)); diff != "" {
+			t.Fatalf("wrong files. Difference:\n%s", diff)
+		}
+	})
+	t.Run("parse the policy", func(t *testing.T) {
+		if _, err := common.ParseSymlinkPolicy("copy"); err != nil {
+			t.Fatalf("failed to parse a valid policy. Error: %q", err)
+		}
+		if _, err := common.ParseSymlinkPolicy("resolve"); err == nil {
+			t.Fatalf("expected an error for an invalid policy")
+		}
+	})
+}
@@ -30,11 +30,11 @@ import (
 	"io"
 	"math"
 	"math/rand"
-	"net/url"
 	"os"
 	"path/filepath"
 	"reflect"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"text/template"
@@ -42,7 +42,6 @@ import (
 
 	"github.com/Masterminds/sprig"
 	"github.com/docker/docker/pkg/ioutils"
-	"github.com/go-git/go-git/v5"
 	"github.com/konveyor/move2kube/types"
 	"github.com/mitchellh/mapstructure"
 	"github.com/sirupsen/logrus"
@@ -130,7 +129,7 @@ func GetFilesByExt(inputPath string, exts []string) ([]string, error) {
 	} else if !info.IsDir() {
 		logrus.Warnf("The path '%s' is not a directory.", inputPath)
 	}
-	err := filepath.WalkDir(inputPath, func(path string, info os.DirEntry, err error) error {
+	err := WalkDir(inputPath, func(path string, info os.DirEntry, err error) error {
 		if err != nil {
 			if path == inputPath {
 				// if the root directory returns an error then stop walking and return this error
@@ -216,7 +215,7 @@ func GetFilesByName(inputPath string, names []string, nameRegexes []string) ([]s
 		}
 		compiledNameRegexes = append(compiledNameRegexes, compiledNameRegex)
 	}
-	err := filepath.WalkDir(inputPath, func(path string, info os.DirEntry, err error) error {
+	err := WalkDir(inputPath, func(path string, info os.DirEntry, err error) error {
 		if err != nil {
 			if path == inputPath {
 				// if the root directory returns an error then stop walking and return this error
@@ -366,6 +365,49 @@ func WriteYaml(outputPath string, data interface{}) error {
 	return os.WriteFile(outputPath, yamlBytes, DefaultFilePermission)
 }
 
+// FileTooLargeError indicates that a file is larger than the allowed size
+type FileTooLargeError struct {
+	Path  string
+	Size  int64
+	Limit int64
+}
+
+// Error implements the interface required for Error
+func (e *FileTooLargeError) Error() string {
+	return fmt.Sprintf("the file at path '%s' has a size of %d bytes which is larger than the limit of %d bytes", e.Path, e.Size, e.Limit)
+}
+
+// ReadFileWithLimit reads the file but returns a FileTooLargeError instead of reading files larger than the limit into memory.
+// A negative limit means there is no limit.
+// It is meant for the contents that are embedded whole in an artifact, like the data of a ConfigMap or Secret, which can not be streamed.
+// The callers must report the files that are skipped because of a FileTooLargeError, since their contents are missing from the output.
+func ReadFileWithLimit(path string, limit int64) ([]byte, error) {
+	if limit < 0 {
+		return os.ReadFile(path)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() > limit {
+		return nil, &FileTooLargeError{Path: path, Size: info.Size(), Limit: limit}
+	}
+	// the file might grow after the stat, so never read more than the limit
+	data, err := io.ReadAll(io.LimitReader(f, limit+1))
+	if err != nil {
+		return data, err
+	}
+	if int64(len(data)) > limit {
+		return nil, &FileTooLargeError{Path: path, Size: int64(len(data)), Limit: limit}
+	}
+	return data, nil
+}
+
 // ReadYaml reads an yaml into an object
 func ReadYaml(file string, data interface{}) error {
 	yamlFile, err := os.ReadFile(file)
@@ -587,6 +629,81 @@ func NormalizeForMetadataName(metadataName string) string {
 	return newName
 }
 
+// NormalizeForMetadataNames converts the names to be compatible for service/metadata names of at most maxLength characters.
+// Distinct names that normalize to the same name (like "web_api" and "web.api") are disambiguated with a
+// deterministic suffix derived from the hash of the original name. A name that is already compliant keeps its name.
+// Returns a mapping from each of the original names to its normalized name.
+func NormalizeForMetadataNames(names []string, maxLength int) map[string]string {
+	if maxLength <= metadataNameHashLength+1 {
+		logrus.Errorf("the maximum length %d for the metadata names is too small. Using %d instead", maxLength, MaxMetadataNameLength)
+		maxLength = MaxMetadataNameLength
+	}
+	sortedNames := []string{}
+	for _, name := range names {
+		if name == "" {
+			logrus.Errorf("failed to normalize for service/metadata name because it is an empty string")
+			continue
+		}
+		sortedNames = AppendIfNotPresent(sortedNames, name)
+	}
+	sort.Strings(sortedNames)
+	normalizedNames := map[string]string{}
+	normalizedToNames := map[string][]string{}
+	for _, name := range sortedNames {
+		normalizedName := truncateMetadataName(disallowedDNSCharactersRegex.ReplaceAllLiteralString(strings.ToLower(name), "-"), maxLength)
+		normalizedNames[name] = normalizedName
+		normalizedToNames[normalizedName] = append(normalizedToNames[normalizedName], name)
+	}
+	newNames := map[string]string{}
+	usedNames := map[string]bool{}
+	collidingNames := []string{}
+	for _, name := range sortedNames {
+		normalizedName := normalizedNames[name]
+		names := normalizedToNames[normalizedName]
+		// out of the colliding names, the one that is already compliant, or else the first one, keeps the normalized name
+		if len(names) == 1 || name == normalizedName || (!IsStringPresent(names, normalizedName) && names[0] == name) {
+			newNames[name] = normalizedName
+			usedNames[normalizedName] = true
+			continue
+		}
+		collidingNames = append(collidingNames, name)
+	}
+	for _, name := range collidingNames {
+		normalizedName := normalizedNames[name]
+		prefix := truncateMetadataName(normalizedName, maxLength-metadataNameHashLength-1)
+		hashInput := name
+		newName := ""
+		for {
+			newName = prefix + "-" + GetSHA256Hash(hashInput)[:metadataNameHashLength]
+			if !usedNames[newName] {
+				break
+			}
+			hashInput += "-"
+		}
+		logrus.Warnf("The names %+v all normalize to %s . Using the name %s for %s", normalizedToNames[normalizedName], normalizedName, newName, name)
+		newNames[name] = newName
+		usedNames[newName] = true
+	}
+	renames := []string{}
+	for _, name := range sortedNames {
+		if newNames[name] != name {
+			renames = append(renames, fmt.Sprintf("%s -> %s", name, newNames[name]))
+		}
+	}
+	if len(renames) > 0 {
+		logrus.Infof("Changed the metadata names:\n%s", strings.Join(renames, "\n"))
+	}
+	return newNames
+}
+
+// truncateMetadataName truncates the name to maxLength characters and replaces the starting and terminating hyphens
+func truncateMetadataName(name string, maxLength int) string {
+	if len(name) > maxLength {
+		name = name[0:maxLength]
+	}
+	return ReplaceStartingTerminatingHyphens(name, "a", "z")
+}
+
 // NormalizeForEnvironmentVariableName converts the string to be compatible for environment variable name convention specified below:
 // https://pubs.opengroup.org/onlinepubs/9699919799/
 func NormalizeForEnvironmentVariableName(envName string) string {
@@ -611,6 +728,16 @@ func IsPresent[C comparable](list []C, value C) bool {
 	return false
 }
 
+// GetSortedServiceNames returns the names of the services in the map in sorted order
+func GetSortedServiceNames[V any](services map[string]V) []string {
+	serviceNames := make([]string, 0, len(services))
+	for serviceName := range services {
+		serviceNames = append(serviceNames, serviceName)
+	}
+	sort.Strings(serviceNames)
+	return serviceNames
+}
+
 // IsStringPresent is like IsPresent but does case-insensitive comparison of strings
 func IsStringPresent(list []string, value string) bool {
 	for _, val := range list {
@@ -636,10 +763,39 @@ func MergeSlices[C comparable](slice1 []C, slice2 []C) []C {
 	return AppendIfNotPresent(slice1, slice2...)
 }
 
+// GetTemplateFuncMap returns the functions available in the go templates used by the customizations.
+// It consists of the sprig function library along with toYaml and fromYaml.
+func GetTemplateFuncMap() template.FuncMap {
+	funcMap := sprig.TxtFuncMap()
+	funcMap["toYaml"] = toYamlTemplateFunc
+	funcMap["fromYaml"] = fromYamlTemplateFunc
+	return funcMap
+}
+
+// toYamlTemplateFunc converts the value to a yaml string without the trailing newline
+func toYamlTemplateFunc(v interface{}) string {
+	data, err := ObjectToYamlBytes(v)
+	if err != nil {
+		logrus.Errorf("failed to convert the value %+v to yaml in the template. Error: %q", v, err)
+		return ""
+	}
+	return strings.TrimSuffix(string(data), "\n")
+}
+
+// fromYamlTemplateFunc converts the yaml string to a map.
+// On failure the error is returned in the map under the key Error.
+func fromYamlTemplateFunc(str string) map[string]interface{} {
+	m := map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(str), &m); err != nil {
+		m["Error"] = err.Error()
+	}
+	return m
+}
+
 // GetStringFromTemplate returns string for a template
 func GetStringFromTemplate(tpl string, config interface{}) (string, error) {
 	var tplbuffer bytes.Buffer
-	packageTemplate, err := template.New("").Funcs(sprig.TxtFuncMap()).Parse(tpl)
+	packageTemplate, err := template.New("").Funcs(GetTemplateFuncMap()).Parse(tpl)
 	if err != nil {
 		return "", fmt.Errorf("failed to parse the template. Error: %w", err)
 	}
@@ -1195,92 +1351,6 @@ func ConvertInterfaceToSliceOfStrings(xI interface{}) ([]string, error) {
 	return vs, nil
 }
 
-// GatherGitInfo tries to find the git repo for the path if one exists.
-func GatherGitInfo(path string) (repoName, repoDir, repoHostName, repoURL, repoBranch string, err error) {
-	if finfo, err := os.Stat(path); err != nil {
-		return "", "", "", "", "", fmt.Errorf("failed to stat the path '%s' . Error %w", path, err)
-	} else if !finfo.IsDir() {
-		pathDir := filepath.Dir(path)
-		logrus.Debugf("The path '%s' is not a directory. Using the path '%s' instead.", path, pathDir)
-		path = pathDir
-	}
-	repo, err := git.PlainOpenWithOptions(path, &git.PlainOpenOptions{DetectDotGit: true})
-	if err != nil {
-		return "", "", "", "", "", fmt.Errorf("failed to open the path '%s' as a git repo. Error: %w", path, err)
-	}
-	workTree, err := repo.Worktree()
-	if err != nil {
-		return "", "", "", "", "", fmt.Errorf("failed to get the repo working tree/directory. Error: %w", err)
-	}
-	repoDir = workTree.Filesystem.Root()
-	ref, err := repo.Head()
-	if err != nil {
-		return "", "", "", "", "", fmt.Errorf("failed to get the current branch. Error: %w", err)
-	}
-	logrus.Debugf("current branch/tag: %#v", ref)
-	repoBranch = filepath.Base(string(ref.Name()))
-	remotes, err := repo.Remotes()
-	if err != nil || len(remotes) == 0 {
-		logrus.Debugf("failed to find any remote repo urls for the repo at path '%s' . Error: %q", path, err)
-		logrus.Debugf("git no remotes case - repoName '%s', repoDir '%s', repoHostName '%s', repoURL '%s', repoBranch '%s'", repoName, repoDir, repoHostName, repoURL, repoBranch)
-		return repoName, repoDir, repoHostName, repoURL, repoBranch, nil
-	}
-	var preferredRemote *git.Remote
-	if preferredRemote = getGitRemoteByName(remotes, "upstream"); preferredRemote == nil {
-		if preferredRemote = getGitRemoteByName(remotes, "origin"); preferredRemote == nil {
-			preferredRemote = remotes[0]
-		}
-	}
-	if len(preferredRemote.Config().URLs) == 0 {
-		err = fmt.Errorf("unable to get origins")
-		logrus.Debugf("%s", err)
-	}
-	u := preferredRemote.Config().URLs[0]
-	repoURL = u
-	if strings.HasPrefix(u, "git@") {
-		// Example: git@github.com:konveyor/move2kube.git
-		withoutGitAt := strings.TrimPrefix(u, "git@")
-		idx := strings.Index(withoutGitAt, ":")
-		if idx < 0 {
-			return "", "", "", "", "", fmt.Errorf("failed to parse the remote host url '%s' as a git ssh url. Error: %w", u, err)
-		}
-		domain := withoutGitAt[:idx]
-		rest := withoutGitAt[idx+1:]
-		newUrl := "https://" + domain + "/" + rest
-		logrus.Debugf("final parsed git ssh url to normal url: '%s'", newUrl)
-		giturl, err := url.Parse(newUrl)
-		if err != nil {
-			return "", "", "", "", "", fmt.Errorf("failed to parse the remote host url '%s' . Error: %w", newUrl, err)
-		}
-		logrus.Debugf("parsed ssh case - giturl: %#v", giturl)
-		repoHostName = giturl.Host
-		repoName = filepath.Base(giturl.Path)
-		repoName = strings.TrimSuffix(repoName, filepath.Ext(repoName))
-		logrus.Debugf("git ssh case - repoName '%s', repoDir '%s', repoHostName '%s', repoURL '%s', repoBranch '%s'", repoName, repoDir, repoHostName, repoURL, repoBranch)
-		return repoName, repoDir, repoHostName, repoURL, repoBranch, nil
-	}
-
-	giturl, err := url.Parse(u)
-	if err != nil {
-		return "", "", "", "", "", fmt.Errorf("failed to parse the remote host url '%s' . Error: %w", u, err)
-	}
-	logrus.Debugf("parsed normal case - giturl: %#v", giturl)
-	repoHostName = giturl.Host
-	repoName = filepath.Base(giturl.Path)
-	repoName = strings.TrimSuffix(repoName, filepath.Ext(repoName))
-	logrus.Debugf("git normal case - repoName '%s', repoDir '%s', repoHostName '%s', repoURL '%s', repoBranch '%s'", repoName, repoDir, repoHostName, repoURL, repoBranch)
-	return repoName, repoDir, repoHostName, repoURL, repoBranch, nil
-}
-
-func getGitRemoteByName(remotes []*git.Remote, remoteName string) *git.Remote {
-	for _, r := range remotes {
-		if r.Config().Name == remoteName {
-			return r
-		}
-	}
-	return nil
-}
-
 // StringToK8sQuantityHookFunc returns a DecodeHookFunc that converts strings to a Kubernetes resource limits quantity.
 func StringToK8sQuantityHookFunc() mapstructure.DecodeHookFunc {
 	return func(from reflect.Type, to reflect.Type, data interface{}) (interface{}, error) {
@@ -18,6 +18,7 @@ package common_test
 
 import (
 	"bytes"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
@@ -193,3 +194,102 @@ func TestConvertUtf8AndUtf16ToUtf8(t *testing.T) {
 		}
 	})
 }
+
+func TestReadFileWithLimit(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "config.txt")
+	if err := os.WriteFile(filePath, []byte("0123456789"), 0644); err != nil {
+		t.Fatalf("failed to write the file. Error: %q", err)
+	}
+	t.Run("file within the limit", func(t *testing.T) {
+		data, err := common.ReadFileWithLimit(filePath, 10)
+		if err != nil {
+			t.Fatalf("failed to read the file. Error: %q", err)
+		}
+		if string(data) != "0123456789" {
+			t.Fatalf("wrong file contents. Actual: %q", data)
+		}
+	})
+	t.Run("no limit", func(t *testing.T) {
+		if _, err := common.ReadFileWithLimit(filePath, -1); err != nil {
+			t.Fatalf("failed to read the file. Error: %q", err)
+		}
+	})
+	t.Run("file larger than the limit", func(t *testing.T) {
+		data, err := common.ReadFileWithLimit(filePath, 5)
+		var tooLargeErr *common.FileTooLargeError
+		if !errors.As(err, &tooLargeErr) {
+			t.Fatalf("expected a FileTooLargeError. Actual: %q", err)
+		}
+		if tooLargeErr.Size != 10 || data != nil {
+			t.Fatalf("wrong size in the error or data was returned. Error: %+v Data: %q", tooLargeErr, data)
+		}
+	})
+}
+
+func TestGetStringFromTemplate(t *testing.T) {
+	config := map[string]interface{}{
+		"name":    "myapp",
+		"version": "1.5.0",
+		"ports":   map[string]interface{}{"http": 8080},
+	}
+	testcases := []struct {
+		name string
+		tpl  string
+		want string
+	}{
+		{name: "default", tpl: `{{ .namespace | default "dev" }}`, want: "dev"},
+		{name: "toYaml", tpl: `{{ toYaml .ports }}`, want: "http: 8080"},
+		{name: "fromYaml", tpl: `{{ (fromYaml "replicas: 3").replicas }}`, want: "3"},
+		{name: "b64enc", tpl: `{{ .name | b64enc }}`, want: "bXlhcHA="},
+		{name: "semverCompare", tpl: `{{ semverCompare ">=1.2.0" .version }}`, want: "true"},
+	}
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			actual, err := common.GetStringFromTemplate(testcase.tpl, config)
+			if err != nil {
+				t.Fatalf("failed to fill the template. Error: %q", err)
+			}
+			if actual != testcase.want {
+				t.Fatalf("wrong output. Expected: %q Actual: %q", testcase.want, actual)
+			}
+		})
+	}
+}
+
+func TestNormalizeForMetadataNames(t *testing.T) {
+	t.Run("names that do not collide", func(t *testing.T) {
+		actual := common.NormalizeForMetadataNames([]string{"web", "My_DB"}, 63)
+		want := map[string]string{"web": "web", "My_DB": "my-db"}
+		if !cmp.Equal(actual, want) {
+			t.Fatalf("wrong names. Expected: %+v Actual: %+v", want, actual)
+		}
+	})
+	t.Run("names that collide after normalization", func(t *testing.T) {
+		names := []string{"web_api", "web.api", "web-api"}
+		actual := common.NormalizeForMetadataNames(names, 63)
+		if actual["web-api"] != "web-api" {
+			t.Fatalf("the already compliant name should not change. Actual: %+v", actual)
+		}
+		if actual["web_api"] == actual["web.api"] || actual["web_api"] == "web-api" || actual["web.api"] == "web-api" {
+			t.Fatalf("the colliding names were not disambiguated. Actual: %+v", actual)
+		}
+		for _, name := range names {
+			if newName := actual[name]; newName != common.MakeStringDNSLabelNameCompliant(newName) {
+				t.Fatalf("the name %s is not DNS-1123 compliant", newName)
+			}
+		}
+		again := common.NormalizeForMetadataNames([]string{"web-api", "web.api", "web_api"}, 63)
+		if !cmp.Equal(actual, again) {
+			t.Fatalf("the names are not deterministic. First: %+v Second: %+v", actual, again)
+		}
+	})
+	t.Run("names longer than the max length", func(t *testing.T) {
+		actual := common.NormalizeForMetadataNames([]string{"a-very-long-service-name-a", "a-very-long-service-name-b"}, 20)
+		if len(actual["a-very-long-service-name-a"]) > 20 || len(actual["a-very-long-service-name-b"]) > 20 {
+			t.Fatalf("the names were not truncated. Actual: %+v", actual)
+		}
+		if actual["a-very-long-service-name-a"] == actual["a-very-long-service-name-b"] {
+			t.Fatalf("the truncated names were not disambiguated. Actual: %+v", actual)
+		}
+	})
+}
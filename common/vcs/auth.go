@@ -0,0 +1,124 @@
+//go:build !wasip1
+// +build !wasip1
+
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package vcs
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/konveyor/move2kube/common"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	gitUsernameEnvKey       = "M2K_GIT_USERNAME"
+	gitPasswordEnvKey       = "M2K_GIT_PASSWORD"
+	gitTokenEnvKey          = "M2K_GIT_TOKEN"
+	gitHostEnvKey           = "M2K_GIT_HOST"
+	gitSSHKeyEnvKey         = "M2K_GIT_SSH_KEY"
+	gitSSHKeyPasswordEnvKey = "M2K_GIT_SSH_KEY_PASSWORD"
+	sshAuthSockEnvKey       = "SSH_AUTH_SOCK"
+	// defaultGitTokenUsername is the username sent along with a token when no username is given
+	defaultGitTokenUsername = "x-access-token"
+)
+
+// getAuth returns the credentials for cloning the git repo.
+// For https urls they are taken from the environment variables, if the repo is on the host given by M2K_GIT_HOST, or from the netrc file.
+// For ssh urls the private key from the environment variable, the ssh agent or the default private keys are used.
+// It returns nil if there are no credentials available.
+func (gvcsrepo *GitVCSRepo) getAuth() (transport.AuthMethod, error) {
+	if strings.HasPrefix(gvcsrepo.URL, "https://") {
+		return getHTTPSAuth(gvcsrepo.URL)
+	}
+	user := "git"
+	if idx := strings.Index(gvcsrepo.URL, "@"); idx > 0 {
+		user = gvcsrepo.URL[:idx]
+	}
+	return getSSHAuth(user)
+}
+
+func getHTTPSAuth(repoURL string) (transport.AuthMethod, error) {
+	parsedURL, err := url.Parse(repoURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the git repo url '%s' . Error: %w", repoURL, err)
+	}
+	if common.IsCredentialsHost(gitHostEnvKey, parsedURL.Hostname()) {
+		username := os.Getenv(gitUsernameEnvKey)
+		if token := os.Getenv(gitTokenEnvKey); token != "" {
+			logrus.Debugf("using the token from the environment variable %s to clone the repo '%s'", gitTokenEnvKey, repoURL)
+			if username == "" {
+				username = defaultGitTokenUsername
+			}
+			return &http.BasicAuth{Username: username, Password: token}, nil
+		}
+		if password := os.Getenv(gitPasswordEnvKey); password != "" {
+			logrus.Debugf("using the credentials from the environment variables %s and %s to clone the repo '%s'", gitUsernameEnvKey, gitPasswordEnvKey, repoURL)
+			return &http.BasicAuth{Username: username, Password: password}, nil
+		}
+	} else if os.Getenv(gitTokenEnvKey) != "" || os.Getenv(gitPasswordEnvKey) != "" {
+		logrus.Debugf("not using the credentials from the environment variables to clone the repo '%s' since it is not on the host given by %s", repoURL, gitHostEnvKey)
+	}
+	if login, password, ok := common.GetNetrcCredentials(parsedURL.Hostname()); ok {
+		logrus.Debugf("using the credentials from the netrc file to clone the repo '%s'", repoURL)
+		return &http.BasicAuth{Username: login, Password: password}, nil
+	}
+	return nil, nil
+}
+
+func getSSHAuth(user string) (transport.AuthMethod, error) {
+	if keyPath := os.Getenv(gitSSHKeyEnvKey); keyPath != "" {
+		auth, err := ssh.NewPublicKeysFromFile(user, keyPath, os.Getenv(gitSSHKeyPasswordEnvKey))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the ssh private key at path '%s' given by %s . Error: %w", keyPath, gitSSHKeyEnvKey, err)
+		}
+		return auth, nil
+	}
+	if os.Getenv(sshAuthSockEnvKey) != "" {
+		auth, err := ssh.NewSSHAgentAuth(user)
+		if err == nil {
+			return auth, nil
+		}
+		logrus.Debugf("failed to use the ssh agent. Error: %q", err)
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		logrus.Debugf("failed to get the home directory. Error: %q", err)
+		return nil, nil
+	}
+	for _, keyName := range []string{"id_ed25519", "id_ecdsa", "id_rsa"} {
+		keyPath := filepath.Join(homeDir, ".ssh", keyName)
+		if _, err := os.Stat(keyPath); err != nil {
+			continue
+		}
+		auth, err := ssh.NewPublicKeysFromFile(user, keyPath, os.Getenv(gitSSHKeyPasswordEnvKey))
+		if err != nil {
+			logrus.Debugf("failed to load the ssh private key at path '%s' . Error: %q", keyPath, err)
+			continue
+		}
+		return auth, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,61 @@
+//go:build !wasip1
+// +build !wasip1
+
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package vcs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+)
+
+func TestGetHTTPSAuth(t *testing.T) {
+	netrcPath := filepath.Join(t.TempDir(), ".netrc")
+	if err := os.WriteFile(netrcPath, []byte("machine gitlab.example.com login netrcuser password netrcsecret\n"), 0600); err != nil {
+		t.Fatalf("failed to write the netrc file. Error: %q", err)
+	}
+	t.Setenv("NETRC", netrcPath)
+	t.Setenv(gitUsernameEnvKey, "")
+	t.Setenv(gitPasswordEnvKey, "")
+	t.Setenv(gitTokenEnvKey, "token")
+	t.Setenv(gitHostEnvKey, "github.com")
+	auth, err := getHTTPSAuth("https://github.com/konveyor/move2kube.git")
+	if err != nil {
+		t.Fatalf("failed to get the credentials. Error: %q", err)
+	}
+	if basicAuth, ok := auth.(*http.BasicAuth); !ok || basicAuth.Username != defaultGitTokenUsername || basicAuth.Password != "token" {
+		t.Fatalf("expected the token to be used for the host given by %s . Actual: %+v", gitHostEnvKey, auth)
+	}
+	if auth, err := getHTTPSAuth("https://attacker.example.com/catalog.git"); err != nil || auth != nil {
+		t.Fatalf("expected the token not to be sent to the other hosts. Actual: %+v Error: %v", auth, err)
+	}
+	auth, err = getHTTPSAuth("https://gitlab.example.com/team/repo.git")
+	if err != nil {
+		t.Fatalf("failed to get the credentials. Error: %q", err)
+	}
+	if basicAuth, ok := auth.(*http.BasicAuth); !ok || basicAuth.Username != "netrcuser" || basicAuth.Password != "netrcsecret" {
+		t.Fatalf("expected the netrc credentials of the host to be used. Actual: %+v", auth)
+	}
+	t.Setenv(gitHostEnvKey, "")
+	if auth, err := getHTTPSAuth("https://github.com/konveyor/move2kube.git"); err != nil || auth != nil {
+		t.Fatalf("expected the token not to be used when %s is not set. Actual: %+v Error: %v", gitHostEnvKey, auth, err)
+	}
+}
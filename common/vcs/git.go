@@ -1,3 +1,6 @@
+//go:build !wasip1
+// +build !wasip1
+
 /*
  *  Copyright IBM Corporation 2023
  *
@@ -17,6 +20,7 @@
 package vcs
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -35,6 +39,7 @@ import (
 	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
 	"github.com/go-git/go-git/v5/storage/filesystem"
 	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/common/remotecache"
 	"github.com/konveyor/move2kube/qaengine"
 	"github.com/sirupsen/logrus"
 )
@@ -51,11 +56,6 @@ type GitVCSRepo struct {
 	GitRepoPath    string
 }
 
-var (
-	// for https or ssh git repo urls
-	gitVCSRegex = regexp.MustCompile(`^git\+(https|ssh)://[a-zA-Z0-9]+([\-\.]{1}[a-zA-Z0-9]+)*\.[a-zA-Z]{2,5}(:[0-9]{1,5})?(\/.*)?$`)
-)
-
 func isGitCommitHash(commithash string) bool {
 	gitCommitHashRegex := regexp.MustCompile(`^[a-fA-F0-9]{40}$`)
 	return gitCommitHashRegex.MatchString(commithash)
@@ -109,23 +109,21 @@ func getGitRepoStruct(vcsurl string) (*GitVCSRepo, error) {
 	}
 
 	if len(partsSplitByAt) == 2 {
-		if isGitBranch(partsSplitByAt[1]) {
-			gitRepoStruct.Branch = partsSplitByAt[1]
-		} else if isGitCommitHash(partsSplitByAt[1]) {
+		// a commit hash or a tag can also be a valid branch name, so they are checked first
+		if isGitCommitHash(partsSplitByAt[1]) {
 			gitRepoStruct.CommitHash = partsSplitByAt[1]
 		} else if isGitTag(partsSplitByAt[1]) {
 			gitRepoStruct.Tag = partsSplitByAt[1]
+		} else if isGitBranch(partsSplitByAt[1]) {
+			gitRepoStruct.Branch = partsSplitByAt[1]
+		} else {
+			return nil, fmt.Errorf("the ref '%s' in the git remote path '%s' is not a valid tag, commit hash or branch", partsSplitByAt[1], vcsurl)
 		}
 	}
 	return &gitRepoStruct, nil
 
 }
 
-// isGitVCS checks if the given vcs url is a git repo url
-func isGitVCS(vcsurl string) bool {
-	return gitVCSRegex.MatchString(vcsurl)
-}
-
 func pushGitVCS(remotePath, folderName string, maxSize int64) error {
 	if !common.IgnoreEnvironment {
 		logrus.Warnf("push to remote git repositories using credentials from the environment is not yet supported.")
@@ -210,7 +208,7 @@ func pushGitVCS(remotePath, folderName string, maxSize int64) error {
 
 // Clone clones a git repository with the given commit depth
 // and path where it is to be cloned and returns the final path inside the repo
-func (gvcsrepo *GitVCSRepo) Clone(cloneOptions VCSCloneOptions) (string, error) {
+func (gvcsrepo *GitVCSRepo) Clone(ctx context.Context, cloneOptions VCSCloneOptions) (string, error) {
 	if cloneOptions.CloneDestinationPath == "" {
 		return "", fmt.Errorf("the path where the repository has to be cloned cannot be empty")
 	}
@@ -234,6 +232,34 @@ func (gvcsrepo *GitVCSRepo) Clone(cloneOptions VCSCloneOptions) (string, error)
 			return "", fmt.Errorf("failed to remove the files/directories at '%s' . error: %w", repoPath, err)
 		}
 	}
+	if cloneOptions.DisableCache {
+		if err := gvcsrepo.clone(ctx, repoPath, cloneOptions); err != nil {
+			return "", err
+		}
+		return filepath.Join(repoPath, gvcsrepo.PathWithinRepo), nil
+	}
+	cacheURL := gvcsrepo.URL
+	if ref := gvcsrepo.Branch + gvcsrepo.Tag + gvcsrepo.CommitHash; ref != "" {
+		cacheURL += "@" + ref
+	}
+	cacheOpts := remotecache.Options{Immutable: gvcsrepo.CommitHash != ""}
+	if err := remotecache.Fetch(cacheURL, repoPath, cacheOpts, func() error {
+		return gvcsrepo.clone(ctx, repoPath, cloneOptions)
+	}); err != nil {
+		return "", err
+	}
+	if gvcsrepo.GitRepository == nil {
+		// the repo was copied from the cache instead of being cloned
+		if gvcsrepo.GitRepository, err = git.PlainOpen(repoPath); err != nil {
+			return "", fmt.Errorf("failed to open the cached git repo at '%s' . Error: %w", repoPath, err)
+		}
+	}
+	return filepath.Join(repoPath, gvcsrepo.PathWithinRepo), nil
+}
+
+// clone clones the git repository into the given path
+func (gvcsrepo *GitVCSRepo) clone(ctx context.Context, repoPath string, cloneOptions VCSCloneOptions) error {
+	var err error
 	logrus.Infof("Cloning the repository using git into '%s' . This might take some time.", cloneOptions.CloneDestinationPath)
 
 	// ------------
@@ -244,6 +270,10 @@ func (gvcsrepo *GitVCSRepo) Clone(cloneOptions VCSCloneOptions) (string, error)
 	limitStorer := Limit(fStorer, cloneOptions.MaxSize)
 	// ------------
 
+	auth, err := gvcsrepo.getAuth()
+	if err != nil {
+		return fmt.Errorf("failed to get the credentials for the git repo '%s' . Error: %w", gvcsrepo.URL, err)
+	}
 	commitDepth := 1
 	if cloneOptions.CommitDepth != 0 {
 		commitDepth = cloneOptions.CommitDepth
@@ -251,76 +281,80 @@ func (gvcsrepo *GitVCSRepo) Clone(cloneOptions VCSCloneOptions) (string, error)
 	if gvcsrepo.Branch != "" {
 		cloneOpts := git.CloneOptions{
 			URL:           gvcsrepo.URL,
+			Auth:          auth,
 			Depth:         commitDepth,
 			SingleBranch:  true,
 			ReferenceName: plumbing.ReferenceName(fmt.Sprintf("refs/heads/%s", gvcsrepo.Branch)),
 		}
-		gvcsrepo.GitRepository, err = git.Clone(limitStorer, repoDirWt, &cloneOpts)
+		gvcsrepo.GitRepository, err = git.CloneContext(ctx, limitStorer, repoDirWt, &cloneOpts)
 		if err != nil {
 			logrus.Debugf("failed to clone the given branch '%s' . Will clone the entire repo and try again.", gvcsrepo.Branch)
 			cloneOpts := git.CloneOptions{
 				URL:   gvcsrepo.URL,
+				Auth:  auth,
 				Depth: commitDepth,
 			}
-			gvcsrepo.GitRepository, err = git.Clone(limitStorer, repoDirWt, &cloneOpts)
+			gvcsrepo.GitRepository, err = git.CloneContext(ctx, limitStorer, repoDirWt, &cloneOpts)
 			if err != nil {
-				return "", fmt.Errorf("failed to perform clone operation using git. Error: %w", err)
+				return fmt.Errorf("failed to perform clone operation using git. Error: %w", err)
 			}
 			branch := fmt.Sprintf("refs/heads/%s", gvcsrepo.Branch)
 			b := plumbing.ReferenceName(branch)
 			w, err := gvcsrepo.GitRepository.Worktree()
 			if err != nil {
-				return "", fmt.Errorf("failed return a worktree for the repostiory. Error: %w", err)
+				return fmt.Errorf("failed return a worktree for the repostiory. Error: %w", err)
 			}
 			if err := w.Checkout(&git.CheckoutOptions{Create: false, Force: false, Branch: b}); err != nil {
 				logrus.Debugf("failed to checkout the branch '%s', creating it...", b)
 				if err := w.Checkout(&git.CheckoutOptions{Create: true, Force: false, Branch: b}); err != nil {
-					return "", fmt.Errorf("failed checkout a new branch. Error : %+v", err)
+					return fmt.Errorf("failed checkout a new branch. Error : %+v", err)
 				}
 			}
 		}
 	} else if gvcsrepo.CommitHash != "" {
 		commitHash := plumbing.NewHash(gvcsrepo.CommitHash)
 		cloneOpts := git.CloneOptions{
-			URL: gvcsrepo.URL,
+			URL:  gvcsrepo.URL,
+			Auth: auth,
 		}
-		gvcsrepo.GitRepository, err = git.Clone(limitStorer, repoDirWt, &cloneOpts)
+		gvcsrepo.GitRepository, err = git.CloneContext(ctx, limitStorer, repoDirWt, &cloneOpts)
 		if err != nil {
-			return "", fmt.Errorf("failed to perform clone operation using git with options %+v. Error: %w", cloneOpts, err)
+			return fmt.Errorf("failed to perform clone operation using git with options %+v. Error: %w", cloneOpts, err)
 		}
 		r, err := git.PlainOpen(repoPath)
 		if err != nil {
-			return "", fmt.Errorf("failed to open the git repository at the given path '%s' . Error: %w", repoPath, err)
+			return fmt.Errorf("failed to open the git repository at the given path '%s' . Error: %w", repoPath, err)
 		}
 		w, err := r.Worktree()
 		if err != nil {
-			return "", fmt.Errorf("failed return a worktree for the repostiory %+v. Error: %w", r, err)
+			return fmt.Errorf("failed return a worktree for the repostiory %+v. Error: %w", r, err)
 		}
 		checkoutOpts := git.CheckoutOptions{Hash: commitHash}
 		if err := w.Checkout(&checkoutOpts); err != nil {
-			return "", fmt.Errorf("failed to checkout commit hash '%s' on work tree. Error: %w", commitHash, err)
+			return fmt.Errorf("failed to checkout commit hash '%s' on work tree. Error: %w", commitHash, err)
 		}
 	} else if gvcsrepo.Tag != "" {
 		cloneOpts := git.CloneOptions{
 			URL:           gvcsrepo.URL,
+			Auth:          auth,
 			ReferenceName: plumbing.ReferenceName(fmt.Sprintf("refs/tags/%s", gvcsrepo.Tag)),
 		}
-		gvcsrepo.GitRepository, err = git.Clone(limitStorer, repoDirWt, &cloneOpts)
+		gvcsrepo.GitRepository, err = git.CloneContext(ctx, limitStorer, repoDirWt, &cloneOpts)
 		if err != nil {
-			return "", fmt.Errorf("failed to perform clone operation using git with options %+v. Error: %w", cloneOpts, err)
+			return fmt.Errorf("failed to perform clone operation using git with options %+v. Error: %w", cloneOpts, err)
 		}
 	} else {
 		cloneOpts := git.CloneOptions{
 			URL:           gvcsrepo.URL,
+			Auth:          auth,
 			Depth:         commitDepth,
 			SingleBranch:  true,
 			ReferenceName: "refs/heads/main",
 		}
-		gvcsrepo.GitRepository, err = git.Clone(limitStorer, repoDirWt, &cloneOpts)
+		gvcsrepo.GitRepository, err = git.CloneContext(ctx, limitStorer, repoDirWt, &cloneOpts)
 		if err != nil {
-			return "", fmt.Errorf("failed to perform clone operation using git with options %+v and %+v. Error: %w", cloneOpts, cloneOptions, err)
+			return fmt.Errorf("failed to perform clone operation using git with options %+v and %+v. Error: %w", cloneOpts, cloneOptions, err)
 		}
 	}
-	return filepath.Join(repoPath, gvcsrepo.PathWithinRepo), nil
-
+	return nil
 }
@@ -1,3 +1,6 @@
+//go:build !wasip1
+// +build !wasip1
+
 /*
  *  Copyright IBM Corporation 2023
  *
@@ -17,6 +20,8 @@
 package vcs
 
 import (
+	"context"
+	"fmt"
 	"path/filepath"
 	"testing"
 
@@ -88,6 +93,40 @@ func TestGetGitRepoStruct(t *testing.T) {
 				Tag:            "",
 			},
 		},
+		{
+			inputURL:      "git+https://github.com/konveyor/move2kube.git@abcd1234ef56789012abcd1234ef56789012abcd",
+			expectedError: nil,
+			expectedGitVCSRepoStruct: &GitVCSRepo{
+				InputURL:    "git+https://github.com/konveyor/move2kube.git@abcd1234ef56789012abcd1234ef56789012abcd",
+				GitRepoPath: "konveyor/move2kube.git",
+				URL:         "https://github.com/konveyor/move2kube.git",
+				CommitHash:  "abcd1234ef56789012abcd1234ef56789012abcd",
+			},
+		},
+		{
+			inputURL:      "git+https://github.com/konveyor/move2kube.git@v0.3.0",
+			expectedError: nil,
+			expectedGitVCSRepoStruct: &GitVCSRepo{
+				InputURL:    "git+https://github.com/konveyor/move2kube.git@v0.3.0",
+				GitRepoPath: "konveyor/move2kube.git",
+				URL:         "https://github.com/konveyor/move2kube.git",
+				Tag:         "v0.3.0",
+			},
+		},
+		{
+			inputURL:      "git+https://github.com/konveyor/move2kube.git@release-0.3",
+			expectedError: nil,
+			expectedGitVCSRepoStruct: &GitVCSRepo{
+				InputURL:    "git+https://github.com/konveyor/move2kube.git@release-0.3",
+				GitRepoPath: "konveyor/move2kube.git",
+				URL:         "https://github.com/konveyor/move2kube.git",
+				Branch:      "release-0.3",
+			},
+		},
+		{
+			inputURL:      "git+https://github.com/konveyor/move2kube.git@-invalid",
+			expectedError: fmt.Errorf("the ref '-invalid' in the git remote path 'git+https://github.com/konveyor/move2kube.git@-invalid' is not a valid tag, commit hash or branch"),
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -140,7 +179,7 @@ func TestClone(t *testing.T) {
 		CloneDestinationPath: cloneDestPath,
 		MaxSize:              infiniteSize,
 	}
-	clonedPath, err := repo.Clone(cloneOpts)
+	clonedPath, err := repo.Clone(context.Background(), cloneOpts)
 	if err != nil {
 		t.Fatalf("failed to clone the git repo. Error : %+v", err)
 	}
@@ -158,7 +197,7 @@ func TestClone(t *testing.T) {
 		CloneDestinationPath: cloneDestPath,
 		MaxSize:              infiniteSize,
 	}
-	clonedPathWithoutOverwrite, err := repo.Clone(cloneOpts)
+	clonedPathWithoutOverwrite, err := repo.Clone(context.Background(), cloneOpts)
 	if err != nil {
 		t.Fatalf("failed to clone the git repo. Error : %+v", err)
 	}
@@ -0,0 +1,28 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package vcs
+
+import "fmt"
+
+// getGitRepoStruct always fails in WASI, since there is no network to clone the repo from
+func getGitRepoStruct(vcsurl string) (VCS, error) {
+	return nil, fmt.Errorf("cloning the git repo '%s' is not supported in WASI", vcsurl)
+}
+
+func pushGitVCS(remotePath, folderName string, maxSize int64) error {
+	return fmt.Errorf("pushing to the git repo '%s' is not supported in WASI", remotePath)
+}
@@ -1,3 +1,6 @@
+//go:build !wasip1
+// +build !wasip1
+
 /*
  *  Copyright IBM Corporation 2023
  *
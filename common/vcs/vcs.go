@@ -17,8 +17,10 @@
 package vcs
 
 import (
+	"context"
 	"fmt"
 	"path/filepath"
+	"regexp"
 
 	"github.com/konveyor/move2kube/common"
 	"github.com/sirupsen/logrus"
@@ -30,11 +32,13 @@ type VCSCloneOptions struct {
 	Overwrite            bool
 	MaxSize              int64
 	CloneDestinationPath string
+	// DisableCache clones the repo without using the remote content cache
+	DisableCache bool
 }
 
 // VCS defines interface for version control system
 type VCS interface {
-	Clone(VCSCloneOptions) (string, error)
+	Clone(context.Context, VCSCloneOptions) (string, error)
 }
 
 // NoCompatibleVCSFound is the error when no VCS is found suitable for the given remote input path
@@ -49,6 +53,8 @@ type FailedVCSPush struct {
 }
 
 var (
+	// for https or ssh git repo urls
+	gitVCSRegex = regexp.MustCompile(`^git\+(https|ssh)://[a-zA-Z0-9]+([\-\.]{1}[a-zA-Z0-9]+)*\.[a-zA-Z]{2,5}(:[0-9]{1,5})?(\/.*)?$`)
 	// maxRepoCloneSize is the maximum size (in bytes) allowed when cloning VCS repos
 	// default -1 means infinite
 	maxRepoCloneSize int64 = -1
@@ -79,6 +85,11 @@ func PushVCSRepo(remotePath, folderName string) error {
 	return pushGitVCS(remotePath, folderName, maxRepoCloneSize)
 }
 
+// isGitVCS checks if the given vcs url is a git repo url
+func isGitVCS(vcsurl string) bool {
+	return gitVCSRegex.MatchString(vcsurl)
+}
+
 // GetVCSRepo extracts information from the given vcsurl and returns a relevant vcs repo struct
 func GetVCSRepo(vcsurl string) (VCS, error) {
 	if isGitVCS(vcsurl) {
@@ -96,6 +107,11 @@ func GetVCSRepo(vcsurl string) (VCS, error) {
 // and then returns the file system and remote paths.
 // If the VCS is not supported, the returned path will be an empty string.
 func GetClonedPath(vcsurl, destDirName string, overwrite bool) (string, error) {
+	return GetClonedPathWithContext(context.Background(), vcsurl, destDirName, overwrite)
+}
+
+// GetClonedPathWithContext is the same as GetClonedPath but the clone is aborted when the context is cancelled.
+func GetClonedPathWithContext(ctx context.Context, vcsurl, destDirName string, overwrite bool) (string, error) {
 	vcsRepo, err := GetVCSRepo(vcsurl)
 	if err != nil {
 		if _, ok := err.(*NoCompatibleVCSFound); ok {
@@ -114,8 +130,10 @@ func GetClonedPath(vcsurl, destDirName string, overwrite bool) (string, error) {
 		Overwrite:            overwrite,
 		MaxSize:              maxRepoCloneSize,
 		CloneDestinationPath: filepath.Join(tempPath, destDirName),
+		// the outputs are pushed back to the remote repo, so they must be cloned afresh
+		DisableCache: destDirName == common.RemoteOutputsFolder,
 	}
-	vcsSrcPath, err := vcsRepo.Clone(cloneOpts)
+	vcsSrcPath, err := vcsRepo.Clone(ctx, cloneOpts)
 	if err != nil {
 		return "", fmt.Errorf("failed to clone using vcs url '%s' and clone options %+v. Error: %w", vcsurl, cloneOpts, err)
 	}
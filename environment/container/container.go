@@ -94,6 +94,13 @@ func GetContainerEngine(spawnContainers bool) (ContainerEngine, error) {
 	return workingEngine, nil
 }
 
+// Reset forgets the container engine, so that the next run asks again whether to spawn containers
+func Reset() {
+	inited = false
+	enabled = false
+	workingEngine = nil
+}
+
 // IsDisabled returns whether the container environment is disabled
 func IsDisabled() bool {
 	return !enabled
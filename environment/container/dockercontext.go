@@ -0,0 +1,129 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package container
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	dockercliconfig "github.com/docker/cli/cli/config"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// dockerHostEnv, dockerContextEnv and containerConnectionEnv are the env vars choosing the daemon used by the docker and podman clis
+	dockerHostEnv          = "DOCKER_HOST"
+	dockerContextEnv       = "DOCKER_CONTEXT"
+	containerConnectionEnv = "CONTAINER_CONNECTION"
+	defaultDockerContext   = "default"
+	defaultDockerSocket    = "/var/run/docker.sock"
+	rootPodmanSocket       = "/run/podman/podman.sock"
+)
+
+// dockerContextMetadata is the metadata of a docker context, stored by the docker cli
+type dockerContextMetadata struct {
+	Endpoints map[string]struct {
+		Host string `json:"Host"`
+	} `json:"Endpoints"`
+}
+
+// GetDockerContext returns the docker context used by the docker cli, or an empty string for the default context.
+// DOCKER_HOST takes precedence over the contexts, like in the docker cli.
+func GetDockerContext() string {
+	if os.Getenv(dockerHostEnv) != "" {
+		return ""
+	}
+	contextName := os.Getenv(dockerContextEnv)
+	if contextName == "" {
+		config, err := dockercliconfig.Load(dockercliconfig.Dir())
+		if err != nil {
+			logrus.Debugf("failed to load the docker cli config. Error: %q", err)
+			return ""
+		}
+		contextName = config.CurrentContext
+	}
+	if contextName == defaultDockerContext {
+		return ""
+	}
+	return contextName
+}
+
+// GetPodmanConnection returns the podman system connection chosen using CONTAINER_CONNECTION, if any
+func GetPodmanConnection() string {
+	return os.Getenv(containerConnectionEnv)
+}
+
+// GetDockerHost returns the docker daemon of the docker context, or a podman socket if the docker daemon socket does not exist.
+// It returns an empty string to use the default docker daemon.
+func GetDockerHost() string {
+	if host := os.Getenv(dockerHostEnv); host != "" {
+		return host
+	}
+	if contextName := GetDockerContext(); contextName != "" {
+		host, err := getDockerContextHost(contextName)
+		if err != nil {
+			logrus.Warnf("Failed to get the docker daemon of the docker context %s . Using the default daemon. Error: %q", contextName, err)
+			return ""
+		}
+		return host
+	}
+	if _, err := os.Stat(defaultDockerSocket); err == nil {
+		return ""
+	}
+	if socket := getPodmanSocket(); socket != "" {
+		logrus.Debugf("Using the podman socket %s in place of the docker daemon", socket)
+		return "unix://" + socket
+	}
+	return ""
+}
+
+// getDockerContextHost returns the docker endpoint of the docker context
+func getDockerContextHost(contextName string) (string, error) {
+	// the docker cli stores the metadata of each context in a directory named after the digest of the context name
+	metaPath := filepath.Join(dockercliconfig.Dir(), "contexts", "meta", fmt.Sprintf("%x", sha256.Sum256([]byte(contextName))), "meta.json")
+	metaBytes, err := os.ReadFile(metaPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the metadata of the docker context at path %s . Error: %w", metaPath, err)
+	}
+	meta := dockerContextMetadata{}
+	if err := json.Unmarshal(metaBytes, &meta); err != nil {
+		return "", fmt.Errorf("failed to parse the metadata of the docker context at path %s . Error: %w", metaPath, err)
+	}
+	endpoint, ok := meta.Endpoints["docker"]
+	if !ok || endpoint.Host == "" {
+		return "", fmt.Errorf("the docker context %s does not have a docker endpoint", contextName)
+	}
+	return endpoint.Host, nil
+}
+
+// getPodmanSocket returns the path of the podman socket of the user, or of root, if it exists
+func getPodmanSocket() string {
+	sockets := []string{}
+	if runtimeDir := os.Getenv("XDG_RUNTIME_DIR"); runtimeDir != "" {
+		sockets = append(sockets, filepath.Join(runtimeDir, "podman", "podman.sock"))
+	}
+	sockets = append(sockets, rootPodmanSocket)
+	for _, socket := range sockets {
+		if finfo, err := os.Stat(socket); err == nil && finfo.Mode()&os.ModeSocket != 0 {
+			return socket
+		}
+	}
+	return ""
+}
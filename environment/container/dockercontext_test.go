@@ -0,0 +1,61 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package container
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	dockercliconfig "github.com/docker/cli/cli/config"
+)
+
+func TestDockerContext(t *testing.T) {
+	configDir := t.TempDir()
+	oldConfigDir := dockercliconfig.Dir()
+	dockercliconfig.SetDir(configDir)
+	defer dockercliconfig.SetDir(oldConfigDir)
+	t.Setenv(dockerHostEnv, "")
+	t.Setenv(dockerContextEnv, "")
+	if err := os.WriteFile(filepath.Join(configDir, "config.json"), []byte(`{"currentContext": "remote"}`), 0644); err != nil {
+		t.Fatalf("failed to write the docker cli config. Error: %q", err)
+	}
+	metaDir := filepath.Join(configDir, "contexts", "meta", fmt.Sprintf("%x", sha256.Sum256([]byte("remote"))))
+	if err := os.MkdirAll(metaDir, 0755); err != nil {
+		t.Fatalf("failed to create the directory. Error: %q", err)
+	}
+	meta := `{"Name":"remote","Metadata":{},"Endpoints":{"docker":{"Host":"ssh://builder@build.example.com","SkipTLSVerify":false}}}`
+	if err := os.WriteFile(filepath.Join(metaDir, "meta.json"), []byte(meta), 0644); err != nil {
+		t.Fatalf("failed to write the metadata of the docker context. Error: %q", err)
+	}
+	if contextName := GetDockerContext(); contextName != "remote" {
+		t.Fatalf("expected the current docker context. Actual: %s", contextName)
+	}
+	if host := GetDockerHost(); host != "ssh://builder@build.example.com" {
+		t.Fatalf("expected the docker daemon of the docker context. Actual: %s", host)
+	}
+	t.Setenv(dockerContextEnv, "default")
+	if contextName := GetDockerContext(); contextName != "" {
+		t.Fatalf("expected DOCKER_CONTEXT to take precedence over the current context. Actual: %s", contextName)
+	}
+	t.Setenv(dockerHostEnv, "tcp://127.0.0.1:2375")
+	if contextName, host := GetDockerContext(), GetDockerHost(); contextName != "" || host != "tcp://127.0.0.1:2375" {
+		t.Fatalf("expected DOCKER_HOST to take precedence over the docker contexts. Actual: %s %s", contextName, host)
+	}
+}
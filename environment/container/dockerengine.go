@@ -1,3 +1,6 @@
+//go:build !wasm
+// +build !wasm
+
 /*
  *  Copyright IBM Corporation 2021
  *
@@ -25,6 +28,7 @@ import (
 	"io/fs"
 	"strings"
 
+	"github.com/docker/cli/cli/connhelper"
 	"github.com/docker/docker/api/types"
 	containertypes "github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/mount"
@@ -51,7 +55,7 @@ type dockerEngine struct {
 // newDockerEngine creates a new docker engine instance
 func newDockerEngine() (*dockerEngine, error) {
 	ctx := context.Background()
-	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	cli, err := newDockerClient()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create the docker client. Error: %w", err)
 	}
@@ -300,7 +304,7 @@ func (e *dockerEngine) RunContainer(image string, cmd environmenttypes.Command,
 		return "", false, fmt.Errorf("failed to pull the image '%s'. Error: %w", image, err)
 	}
 	ctx := context.Background()
-	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	cli, err := newDockerClient()
 	if err != nil {
 		return "", false, fmt.Errorf("failed to create a docker client. Error: %w", err)
 	}
@@ -389,3 +393,22 @@ func (e *dockerEngine) RunContainer(image string, cmd environmenttypes.Command,
 	}
 	return "", false, nil
 }
+
+// newDockerClient creates a docker client for the daemon of the docker context, including the remote daemons reached over ssh
+func newDockerClient() (*client.Client, error) {
+	opts := []client.Opt{client.FromEnv, client.WithAPIVersionNegotiation()}
+	host := GetDockerHost()
+	if host == "" {
+		return client.NewClientWithOpts(opts...)
+	}
+	if strings.HasPrefix(host, "ssh://") {
+		helper, err := connhelper.GetConnectionHelper(host)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to the docker daemon %s . Error: %w", host, err)
+		}
+		opts = append(opts, client.WithHost(helper.Host), client.WithDialContext(helper.Dialer))
+	} else {
+		opts = append(opts, client.WithHost(host))
+	}
+	return client.NewClientWithOpts(opts...)
+}
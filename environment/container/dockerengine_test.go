@@ -1,3 +1,6 @@
+//go:build !wasm
+// +build !wasm
+
 /*
  *  Copyright IBM Corporation 2021
  *
@@ -324,7 +324,8 @@ func (e *Environment) DownloadAndDecode(obj interface{}, downloadSource bool) in
 				return filepath.Join(e.Source, relPath), nil
 			}
 		}
-		if common.IsParent(path, e.GetEnvironmentOutput()) {
+		// an empty output directory would resolve to the working directory, which is the root in the browser
+		if e.GetEnvironmentOutput() != "" && common.IsParent(path, e.GetEnvironmentOutput()) {
 			relPath, err := filepath.Rel(e.GetEnvironmentOutput(), path)
 			if err != nil {
 				return path, fmt.Errorf("failed to make the path %s relative to the output directory %s . Error: %q", path, e.GetEnvironmentOutput(), err)
@@ -373,7 +374,7 @@ func (e *Environment) ProcessPathMappings(pathMappings []transformertypes.PathMa
 				"TempRoot":     e.CreateTempRoot,
 				"FilePathBase": filepath.Base,
 			}
-			tpl, err := template.New("pathTpl").Funcs(methodMap).Parse(pm.SrcPath)
+			tpl, err := template.New("pathTpl").Funcs(common.GetTemplateFuncMap()).Funcs(methodMap).Parse(pm.SrcPath)
 			if err != nil {
 				logrus.Errorf("Error while parsing path template : %s", err)
 				continue
@@ -21,6 +21,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/konveyor/move2kube/common"
 	"github.com/sirupsen/logrus"
 )
 
@@ -43,10 +44,31 @@ func newProcessor(options options) *processor {
 }
 
 func (p *processor) process(source, destination string) error {
-	si, err := os.Stat(source)
+	si, err := os.Lstat(source)
 	if err != nil {
 		return fmt.Errorf("failed to stat the source path '%s' . Error: %w", source, err)
 	}
+	if si.Mode()&os.ModeSymlink != 0 {
+		switch common.SymlinkPolicy {
+		case common.SkipSymlinkPolicy:
+			common.WarnAboutSymlink(source, "Skipping the symbolic link '%s' as per the symlink policy", source)
+			return nil
+		case common.FollowSymlinkPolicy:
+			return p.processSymLink(source, destination)
+		}
+		// copy the target of the link in place of the link
+		target, err := filepath.EvalSymlinks(source)
+		if err != nil {
+			return fmt.Errorf("failed to resolve the symbolic link '%s' . Error: %w", source, err)
+		}
+		if si, err = os.Stat(target); err != nil {
+			return fmt.Errorf("failed to stat the target '%s' of the symbolic link '%s' . Error: %w", target, source, err)
+		}
+		if si.IsDir() && common.IsSymlinkLoop(source, target) {
+			common.WarnAboutSymlink(source, "Skipping the symbolic link '%s' since its target '%s' contains the link", source, target)
+			return nil
+		}
+	}
 	switch si.Mode() & os.ModeType {
 	case os.ModeDir:
 		if err := p.processDirectory(source, destination); err != nil {
@@ -134,5 +156,11 @@ func (p *processor) processSymLink(source, destination string) error {
 	if err != nil {
 		return err
 	}
+	if destLink, err := os.Readlink(destination); err == nil && destLink == link {
+		return nil
+	}
+	if err := os.RemoveAll(destination); err != nil {
+		return fmt.Errorf("failed to remove the path '%s' to create the symbolic link. Error: %w", destination, err)
+	}
 	return os.Symlink(link, destination)
 }
@@ -24,7 +24,6 @@ import (
 	"strings"
 	"text/template"
 
-	"github.com/Masterminds/sprig"
 	"github.com/konveyor/move2kube/common"
 	"github.com/sirupsen/logrus"
 )
@@ -166,7 +165,7 @@ func writeTemplateToFile(tpl string, config interface{}, writepath string,
 		"archTarGZipStr": common.CreateTarArchiveGZipStringWrapper,
 		"archTarStr":     common.CreateTarArchiveNoCompressionStringWrapper,
 	}
-	template.Must(packageTemplate.Delims(openingDelimiter, closingDelimiter).Funcs(sprig.TxtFuncMap()).Funcs(methodMap).Parse(tpl))
+	template.Must(packageTemplate.Delims(openingDelimiter, closingDelimiter).Funcs(common.GetTemplateFuncMap()).Funcs(methodMap).Parse(tpl))
 	if err != nil {
 		logrus.Errorf("Unable to parse the template : %s", err)
 		return err
@@ -0,0 +1,113 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package lib
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/konveyor/move2kube/common"
+	plantypes "github.com/konveyor/move2kube/types/plan"
+)
+
+const (
+	archiveSourceDirName = "source"
+	archiveOutputDirName = "output"
+)
+
+// PlanArchive creates the plan for the sources in the zip or tar(.gz) archive and returns the plan as yaml.
+// The archive name is used to detect the format of the archive.
+func PlanArchive(ctx context.Context, archive []byte, archiveName, transformerSelector, prjName string) ([]byte, error) {
+	workDir, err := os.MkdirTemp("", "move2kube-archive-plan-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create a temporary directory for the plan. Error: %w", err)
+	}
+	defer os.RemoveAll(workDir)
+	sourceDir, err := extractArchiveBytes(archive, archiveName, workDir)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := CreatePlan(ctx, sourceDir, "", "", transformerSelector, prjName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the plan. Error: %w", err)
+	}
+	planPath := filepath.Join(workDir, common.DefaultPlanFile)
+	if err := plantypes.WritePlan(planPath, plan); err != nil {
+		return nil, fmt.Errorf("failed to write the plan. Error: %w", err)
+	}
+	// the paths are now relative to the source directory, which is temporary and replaced by TransformArchive
+	relPlan := plantypes.Plan{}
+	if err := common.ReadMove2KubeYaml(planPath, &relPlan); err != nil {
+		return nil, fmt.Errorf("failed to read the plan. Error: %w", err)
+	}
+	relPlan.Spec.SourceDir = ""
+	if err := common.WriteYaml(planPath, relPlan); err != nil {
+		return nil, fmt.Errorf("failed to write the plan. Error: %w", err)
+	}
+	return os.ReadFile(planPath)
+}
+
+// TransformArchive transforms the sources in the zip or tar(.gz) archive using the plan yaml
+// and returns the generated output as a zip archive.
+func TransformArchive(ctx context.Context, archive []byte, archiveName string, planYaml []byte, transformerSelector string, maxIterations int) ([]byte, error) {
+	workDir, err := os.MkdirTemp("", "move2kube-archive-transform-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create a temporary directory for the transformation. Error: %w", err)
+	}
+	defer os.RemoveAll(workDir)
+	sourceDir, err := extractArchiveBytes(archive, archiveName, workDir)
+	if err != nil {
+		return nil, err
+	}
+	planPath := filepath.Join(workDir, common.DefaultPlanFile)
+	if err := os.WriteFile(planPath, planYaml, common.DefaultFilePermission); err != nil {
+		return nil, fmt.Errorf("failed to write the plan to '%s' . Error: %w", planPath, err)
+	}
+	plan, err := plantypes.ReadPlan(planPath, sourceDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the plan. Error: %w", err)
+	}
+	outputDir := filepath.Join(workDir, archiveOutputDirName)
+	if err := os.MkdirAll(outputDir, common.DefaultDirectoryPermission); err != nil {
+		return nil, fmt.Errorf("failed to create the output directory at '%s' . Error: %w", outputDir, err)
+	}
+	if err := Transform(ctx, plan, true, outputDir, transformerSelector, maxIterations); err != nil {
+		return nil, fmt.Errorf("failed to transform. Error: %w", err)
+	}
+	output := bytes.Buffer{}
+	if err := common.WriteZip(outputDir, &output); err != nil {
+		return nil, fmt.Errorf("failed to archive the output directory. Error: %w", err)
+	}
+	return output.Bytes(), nil
+}
+
+// extractArchiveBytes extracts the archive into the source directory inside the work directory
+func extractArchiveBytes(archive []byte, archiveName, workDir string) (string, error) {
+	archivePath := filepath.Join(workDir, filepath.Base(archiveName))
+	if err := os.WriteFile(archivePath, archive, common.DefaultFilePermission); err != nil {
+		return "", fmt.Errorf("failed to write the archive to '%s' . Error: %w", archivePath, err)
+	}
+	defer os.Remove(archivePath)
+	sourceDir := filepath.Join(workDir, archiveSourceDirName)
+	if err := common.ExtractArchive(archivePath, archiveName, sourceDir); err != nil {
+		return "", fmt.Errorf("failed to extract the source archive. Error: %w", err)
+	}
+	return sourceDir, nil
+}
@@ -0,0 +1,234 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package lib
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/filesystem"
+	catalogtypes "github.com/konveyor/move2kube/types/catalog"
+)
+
+const (
+	// CatalogIndexFileName is the name of the index file at the root of a transformer catalog
+	CatalogIndexFileName = "index.yaml"
+	// installedTransformersFileName is the name of the file listing the installed transformers in the install directory
+	installedTransformersFileName = "installed.yaml"
+)
+
+// ReadCatalog fetches and reads the index of the transformer catalog.
+// The catalog can be a git url, an OCI artifact ref, an archive url or a local directory.
+func ReadCatalog(ctx context.Context, catalogPath string) (catalogtypes.TransformerCatalog, error) {
+	catalog := catalogtypes.NewTransformerCatalog()
+	catalogDir, err := getCatalogDir(ctx, catalogPath)
+	if err != nil {
+		return catalog, err
+	}
+	indexPath := filepath.Join(catalogDir, CatalogIndexFileName)
+	if err := common.ReadMove2KubeYamlStrict(indexPath, &catalog, string(catalogtypes.TransformerCatalogKind)); err != nil {
+		return catalog, fmt.Errorf("failed to read the catalog index at path '%s' . Error: %w", indexPath, err)
+	}
+	return catalog, nil
+}
+
+// SearchCatalog returns the catalog entries whose name, description or tags contain the query, sorted by name.
+// An empty query matches all the entries.
+func SearchCatalog(ctx context.Context, catalogPath, query string) ([]catalogtypes.CatalogEntry, error) {
+	catalog, err := ReadCatalog(ctx, catalogPath)
+	if err != nil {
+		return nil, err
+	}
+	query = strings.ToLower(query)
+	entries := []catalogtypes.CatalogEntry{}
+	for _, entry := range catalog.Spec.Transformers {
+		if query == "" || strings.Contains(strings.ToLower(entry.Name), query) || strings.Contains(strings.ToLower(entry.Description), query) || isTagPresent(entry.Tags, query) {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries, nil
+}
+
+// InstallTransformer installs the transformer from the catalog into the install directory.
+// The transformer is given as "name" to install the latest version or "name@version" to pin the version.
+// The install directory can be given as the customizations directory to plan and transform.
+func InstallTransformer(ctx context.Context, catalogPath, transformer, installDir string) (catalogtypes.InstalledTransformer, error) {
+	name, version, _ := strings.Cut(transformer, "@")
+	// the name is used as the install directory, so it must not point outside the install directory
+	if !isCleanPathElement(name) {
+		return catalogtypes.InstalledTransformer{}, fmt.Errorf("the transformer name '%s' is not valid. It must not be empty or contain path separators", name)
+	}
+	catalog, err := ReadCatalog(ctx, catalogPath)
+	if err != nil {
+		return catalogtypes.InstalledTransformer{}, err
+	}
+	var entry *catalogtypes.CatalogEntry
+	for i, e := range catalog.Spec.Transformers {
+		if e.Name == name {
+			entry = &catalog.Spec.Transformers[i]
+			break
+		}
+	}
+	if entry == nil {
+		return catalogtypes.InstalledTransformer{}, fmt.Errorf("the transformer '%s' is not present in the catalog '%s'", name, catalogPath)
+	}
+	catalogVersion, err := selectCatalogVersion(*entry, version)
+	if err != nil {
+		return catalogtypes.InstalledTransformer{}, err
+	}
+	source := catalogVersion.Source
+	sourceDir, err := getRemoteCustomizationsPath(ctx, source)
+	if err != nil {
+		return catalogtypes.InstalledTransformer{}, fmt.Errorf("failed to fetch the transformer '%s' from '%s' . Error: %w", name, source, err)
+	}
+	if sourceDir == "" {
+		// local sources are relative to the catalog directory and must stay inside it
+		catalogDir, err := getCatalogDir(ctx, catalogPath)
+		if err != nil {
+			return catalogtypes.InstalledTransformer{}, err
+		}
+		if sourceDir, err = getCatalogSourceDir(catalogDir, source); err != nil {
+			return catalogtypes.InstalledTransformer{}, fmt.Errorf("failed to get the source of the transformer '%s' . Error: %w", name, err)
+		}
+	}
+	destDir := filepath.Join(installDir, name)
+	if err := os.RemoveAll(destDir); err != nil {
+		return catalogtypes.InstalledTransformer{}, fmt.Errorf("failed to remove the old version of the transformer at path '%s' . Error: %w", destDir, err)
+	}
+	if err := filesystem.Replicate(sourceDir, destDir); err != nil {
+		return catalogtypes.InstalledTransformer{}, fmt.Errorf("failed to copy the transformer '%s' into '%s' . Error: %w", name, destDir, err)
+	}
+	installed := catalogtypes.InstalledTransformer{
+		Name:        name,
+		Version:     catalogVersion.Version,
+		Source:      source,
+		Catalog:     catalogPath,
+		InstalledAt: time.Now().UTC().Format(time.RFC3339),
+	}
+	installedTransformers, err := ListInstalledTransformers(installDir)
+	if err != nil {
+		return installed, err
+	}
+	transformers := []catalogtypes.InstalledTransformer{installed}
+	for _, t := range installedTransformers {
+		if t.Name != name {
+			transformers = append(transformers, t)
+		}
+	}
+	sort.Slice(transformers, func(i, j int) bool { return transformers[i].Name < transformers[j].Name })
+	installedFile := catalogtypes.NewInstalledTransformers()
+	installedFile.Spec.Transformers = transformers
+	installedPath := filepath.Join(installDir, installedTransformersFileName)
+	if err := common.WriteYaml(installedPath, installedFile); err != nil {
+		return installed, fmt.Errorf("failed to write the list of installed transformers to '%s' . Error: %w", installedPath, err)
+	}
+	return installed, nil
+}
+
+// ListInstalledTransformers returns the transformers installed in the install directory
+func ListInstalledTransformers(installDir string) ([]catalogtypes.InstalledTransformer, error) {
+	installedPath := filepath.Join(installDir, installedTransformersFileName)
+	if _, err := os.Stat(installedPath); os.IsNotExist(err) {
+		return nil, nil
+	}
+	installedFile := catalogtypes.NewInstalledTransformers()
+	if err := common.ReadMove2KubeYamlStrict(installedPath, &installedFile, string(catalogtypes.InstalledTransformersKind)); err != nil {
+		return nil, fmt.Errorf("failed to read the list of installed transformers at path '%s' . Error: %w", installedPath, err)
+	}
+	return installedFile.Spec.Transformers, nil
+}
+
+// selectCatalogVersion returns the requested version of the transformer or the latest version if none was requested
+func selectCatalogVersion(entry catalogtypes.CatalogEntry, version string) (catalogtypes.CatalogVersion, error) {
+	if len(entry.Versions) == 0 {
+		return catalogtypes.CatalogVersion{}, fmt.Errorf("the transformer '%s' does not have any versions in the catalog", entry.Name)
+	}
+	if version != "" {
+		for _, v := range entry.Versions {
+			if strings.TrimPrefix(v.Version, "v") == strings.TrimPrefix(version, "v") {
+				return v, nil
+			}
+		}
+		return catalogtypes.CatalogVersion{}, fmt.Errorf("the version '%s' of the transformer '%s' is not present in the catalog", version, entry.Name)
+	}
+	latest := entry.Versions[0]
+	var latestVersion *semver.Version
+	for _, v := range entry.Versions {
+		parsed, err := semver.NewVersion(v.Version)
+		if err != nil {
+			continue
+		}
+		if latestVersion == nil || parsed.GreaterThan(latestVersion) {
+			latest, latestVersion = v, parsed
+		}
+	}
+	return latest, nil
+}
+
+// getCatalogDir returns the local directory containing the catalog index
+func getCatalogDir(ctx context.Context, catalogPath string) (string, error) {
+	catalogDir, err := getRemoteCustomizationsPath(ctx, catalogPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch the catalog '%s' . Error: %w", catalogPath, err)
+	}
+	if catalogDir == "" {
+		catalogDir = catalogPath
+	}
+	return catalogDir, nil
+}
+
+// getCatalogSourceDir returns the directory of the local source, after checking that it is inside the catalog directory
+func getCatalogSourceDir(catalogDir, source string) (string, error) {
+	if filepath.IsAbs(source) {
+		return "", fmt.Errorf("the source '%s' must be relative to the catalog directory", source)
+	}
+	realCatalogDir, err := filepath.EvalSymlinks(catalogDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve the catalog directory '%s' . Error: %w", catalogDir, err)
+	}
+	realSourceDir, err := filepath.EvalSymlinks(filepath.Join(catalogDir, source))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve the source '%s' in the catalog directory '%s' . Error: %w", source, catalogDir, err)
+	}
+	relSourceDir, err := filepath.Rel(realCatalogDir, realSourceDir)
+	if err != nil || relSourceDir == ".." || strings.HasPrefix(relSourceDir, ".."+string(os.PathSeparator)) {
+		return "", fmt.Errorf("the source '%s' is outside the catalog directory '%s'", source, catalogDir)
+	}
+	return realSourceDir, nil
+}
+
+// isCleanPathElement returns true if the name is a single path element which does not refer to a parent or the current directory
+func isCleanPathElement(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.ContainsAny(name, `/\`) && filepath.Base(name) == name
+}
+
+func isTagPresent(tags []string, query string) bool {
+	for _, tag := range tags {
+		if strings.Contains(strings.ToLower(tag), query) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,183 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package lib
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testCatalogIndex = `apiVersion: move2kube.konveyor.io/v1alpha1
+kind: TransformerCatalog
+spec:
+  transformers:
+    - name: nodejs-extra
+      description: Adds extra nodejs customizations
+      tags: [nodejs]
+      versions:
+        - version: 1.2.0
+          source: nodejs-extra/v1.2.0
+        - version: 1.10.0
+          source: nodejs-extra/v1.10.0
+    - name: java-tomcat
+      description: Deploys war files on tomcat
+      versions:
+        - version: 0.1.0
+          source: java-tomcat
+`
+
+const testUnsafeCatalogIndex = `apiVersion: move2kube.konveyor.io/v1alpha1
+kind: TransformerCatalog
+spec:
+  transformers:
+    - name: ../escape
+      versions:
+        - version: 0.1.0
+          source: safe
+    - name: parent-source
+      versions:
+        - version: 0.1.0
+          source: ../outside
+    - name: absolute-source
+      versions:
+        - version: 0.1.0
+          source: %s
+    - name: symlink-source
+      versions:
+        - version: 0.1.0
+          source: link
+`
+
+func TestTransformerCatalog(t *testing.T) {
+	catalogDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(catalogDir, CatalogIndexFileName), []byte(testCatalogIndex), 0644); err != nil {
+		t.Fatalf("failed to write the catalog index. Error: %q", err)
+	}
+	for _, version := range []string{"v1.2.0", "v1.10.0"} {
+		transformerDir := filepath.Join(catalogDir, "nodejs-extra", version)
+		if err := os.MkdirAll(transformerDir, 0755); err != nil {
+			t.Fatalf("failed to create the transformer dir. Error: %q", err)
+		}
+		if err := os.WriteFile(filepath.Join(transformerDir, "transformer.yaml"), []byte("version: "+version+"\n"), 0644); err != nil {
+			t.Fatalf("failed to write the transformer. Error: %q", err)
+		}
+	}
+	ctx := context.Background()
+
+	t.Run("search matches the name, description and tags", func(t *testing.T) {
+		entries, err := SearchCatalog(ctx, catalogDir, "")
+		if err != nil {
+			t.Fatalf("failed to search the catalog. Error: %q", err)
+		}
+		if len(entries) != 2 || entries[0].Name != "java-tomcat" || entries[1].Name != "nodejs-extra" {
+			t.Fatalf("expected all the entries sorted by name. Actual: %+v", entries)
+		}
+		entries, err = SearchCatalog(ctx, catalogDir, "NodeJS")
+		if err != nil {
+			t.Fatalf("failed to search the catalog. Error: %q", err)
+		}
+		if len(entries) != 1 || entries[0].Name != "nodejs-extra" {
+			t.Fatalf("expected only the nodejs entry. Actual: %+v", entries)
+		}
+		if entries, _ = SearchCatalog(ctx, catalogDir, "tomcat"); len(entries) != 1 || entries[0].Name != "java-tomcat" {
+			t.Fatalf("expected only the tomcat entry. Actual: %+v", entries)
+		}
+	})
+
+	t.Run("install picks the latest version unless pinned", func(t *testing.T) {
+		installDir := t.TempDir()
+		installed, err := InstallTransformer(ctx, catalogDir, "nodejs-extra", installDir)
+		if err != nil {
+			t.Fatalf("failed to install the transformer. Error: %q", err)
+		}
+		if installed.Version != "1.10.0" {
+			t.Fatalf("expected the latest version 1.10.0 . Actual: %s", installed.Version)
+		}
+		data, err := os.ReadFile(filepath.Join(installDir, "nodejs-extra", "transformer.yaml"))
+		if err != nil || string(data) != "version: v1.10.0\n" {
+			t.Fatalf("expected the files of the latest version to be installed. Actual: %q Error: %v", string(data), err)
+		}
+		if _, err := InstallTransformer(ctx, catalogDir, "nodejs-extra@v1.2.0", installDir); err != nil {
+			t.Fatalf("failed to install the pinned version of the transformer. Error: %q", err)
+		}
+		data, err = os.ReadFile(filepath.Join(installDir, "nodejs-extra", "transformer.yaml"))
+		if err != nil || string(data) != "version: v1.2.0\n" {
+			t.Fatalf("expected the files of the pinned version to be installed. Actual: %q Error: %v", string(data), err)
+		}
+		list, err := ListInstalledTransformers(installDir)
+		if err != nil {
+			t.Fatalf("failed to list the installed transformers. Error: %q", err)
+		}
+		if len(list) != 1 || list[0].Name != "nodejs-extra" || list[0].Version != "1.2.0" {
+			t.Fatalf("expected only the pinned version to be listed. Actual: %+v", list)
+		}
+	})
+
+	t.Run("unknown transformers and versions are rejected", func(t *testing.T) {
+		installDir := t.TempDir()
+		if _, err := InstallTransformer(ctx, catalogDir, "missing", installDir); err == nil {
+			t.Fatalf("expected an error for a transformer missing from the catalog")
+		}
+		if _, err := InstallTransformer(ctx, catalogDir, "nodejs-extra@9.9.9", installDir); err == nil {
+			t.Fatalf("expected an error for a version missing from the catalog")
+		}
+		if list, err := ListInstalledTransformers(installDir); err != nil || len(list) != 0 {
+			t.Fatalf("expected no installed transformers. Actual: %+v Error: %v", list, err)
+		}
+	})
+
+	t.Run("names and sources outside the catalog are rejected", func(t *testing.T) {
+		rootDir := t.TempDir()
+		unsafeCatalogDir := filepath.Join(rootDir, "catalog")
+		outsideDir := filepath.Join(rootDir, "outside")
+		for _, dir := range []string{filepath.Join(unsafeCatalogDir, "safe"), outsideDir} {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				t.Fatalf("failed to create the directory. Error: %q", err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, "transformer.yaml"), []byte("kind: Transformer\n"), 0644); err != nil {
+				t.Fatalf("failed to write the transformer. Error: %q", err)
+			}
+		}
+		if err := os.Symlink(outsideDir, filepath.Join(unsafeCatalogDir, "link")); err != nil {
+			t.Fatalf("failed to create the symlink. Error: %q", err)
+		}
+		index := fmt.Sprintf(testUnsafeCatalogIndex, outsideDir)
+		if err := os.WriteFile(filepath.Join(unsafeCatalogDir, CatalogIndexFileName), []byte(index), 0644); err != nil {
+			t.Fatalf("failed to write the catalog index. Error: %q", err)
+		}
+		installDir := filepath.Join(rootDir, "install")
+		for _, name := range []string{"../escape", "", ".", "..", "a/b"} {
+			if _, err := InstallTransformer(ctx, unsafeCatalogDir, name, installDir); err == nil {
+				t.Fatalf("expected an error for the transformer name '%s'", name)
+			}
+		}
+		for _, name := range []string{"parent-source", "absolute-source", "symlink-source"} {
+			if _, err := InstallTransformer(ctx, unsafeCatalogDir, name, installDir); err == nil {
+				t.Fatalf("expected an error for the source of the transformer '%s' outside the catalog", name)
+			}
+		}
+		if _, err := os.Stat(filepath.Join(rootDir, "escape")); !os.IsNotExist(err) {
+			t.Fatalf("expected nothing to be installed outside the install directory. Error: %v", err)
+		}
+		if list, err := ListInstalledTransformers(installDir); err != nil || len(list) != 0 {
+			t.Fatalf("expected no installed transformers. Actual: %+v Error: %v", list, err)
+		}
+	})
+}
@@ -0,0 +1,474 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package lib
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/konveyor/move2kube/common"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	"github.com/konveyor/move2kube/types/info"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	collectBundleManifestFileName  = "manifest.yaml"
+	collectBundleSignatureFileName = collectBundleManifestFileName + ".sig"
+	privateKeyPemType              = "PRIVATE KEY"
+	publicKeyPemType               = "PUBLIC KEY"
+	// maxCollectBundleFileSize is the maximum size of a single file extracted from a collect bundle
+	maxCollectBundleFileSize = 1 << 30
+	// maxCollectBundleManifestSize is the maximum size of the manifest and the signature of a collect bundle
+	maxCollectBundleManifestSize = 64 << 20
+)
+
+// ExportCollectBundle packs the collect output directory into a portable gzipped tar bundle.
+// The bundle contains a manifest with the checksums of all the files.
+// If a key path is given, the manifest is signed with the ed25519 private key at that path.
+// If there is no key at that path, a new key pair is generated and the public key is written next to it.
+// The files are streamed into the bundle, so the collect output does not have to fit in memory.
+func ExportCollectBundle(collectDir, bundlePath, keyPath string) error {
+	manifest := collecttypes.NewCollectBundle()
+	manifest.Spec.CreatedAt = time.Now().UTC().Format(time.RFC3339)
+	manifest.Spec.Move2KubeVersion = info.GetVersion()
+	filePaths := map[string]string{}
+	if err := filepath.WalkDir(collectDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		relPath, err := filepath.Rel(collectDir, p)
+		if err != nil {
+			return fmt.Errorf("failed to make the path '%s' relative to the collect output directory '%s' . Error: %w", p, collectDir, err)
+		}
+		checksum, err := getFileSHA256(p)
+		if err != nil {
+			return err
+		}
+		name := path.Join(collecttypes.CollectOutputDirName, filepath.ToSlash(relPath))
+		filePaths[name] = p
+		manifest.Spec.Files = append(manifest.Spec.Files, collecttypes.CollectBundleFile{Path: name, SHA256: checksum})
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to walk the collect output directory '%s' . Error: %w", collectDir, err)
+	}
+	if len(manifest.Spec.Files) == 0 {
+		return fmt.Errorf("the collect output directory '%s' does not contain any files", collectDir)
+	}
+	sort.Slice(manifest.Spec.Files, func(i, j int) bool { return manifest.Spec.Files[i].Path < manifest.Spec.Files[j].Path })
+	manifestBytes, err := common.ObjectToYamlBytes(manifest)
+	if err != nil {
+		return fmt.Errorf("failed to marshal the collect bundle manifest to yaml. Error: %w", err)
+	}
+	var signature []byte
+	if keyPath == "" {
+		logrus.Warnf("No key was given to sign the collect bundle with. The bundle at path '%s' can only be imported if unsigned bundles are explicitly allowed.", bundlePath)
+	} else {
+		privateKey, err := getOrCreateBundlePrivateKey(keyPath)
+		if err != nil {
+			return err
+		}
+		signature = ed25519.Sign(privateKey, manifestBytes)
+	}
+	if err := writeCollectBundle(bundlePath, manifestBytes, signature, manifest.Spec.Files, filePaths); err != nil {
+		return fmt.Errorf("failed to write the collect bundle to the path '%s' . Error: %w", bundlePath, err)
+	}
+	logrus.Infof("Exported the collect output to the bundle at path '%s'", bundlePath)
+	return nil
+}
+
+// ImportCollectBundle verifies a bundle created by ExportCollectBundle and imports the collect output into the collect
+// output directory of the source directory, where the transformers look for the collected metadata.
+// The bundle must be signed by the private key corresponding to the public key, unless unsigned bundles are explicitly allowed.
+// The files are streamed into a temporary directory and only moved into the source directory once all of them are verified.
+// An existing collect output directory is not overwritten, unless it already has the same contents as the bundle.
+func ImportCollectBundle(bundlePath, publicKeyPath string, allowUnsigned bool, sourceDir string) error {
+	if publicKeyPath == "" && !allowUnsigned {
+		return fmt.Errorf("a public key is required to verify the signature of the collect bundle at path '%s'", bundlePath)
+	}
+	var publicKey ed25519.PublicKey
+	if publicKeyPath != "" {
+		var err error
+		if publicKey, err = readBundlePublicKey(publicKeyPath); err != nil {
+			return err
+		}
+	}
+	destDir := filepath.Join(filepath.Clean(sourceDir), collecttypes.CollectOutputDirName)
+	if err := os.MkdirAll(filepath.Dir(destDir), common.DefaultDirectoryPermission); err != nil {
+		return fmt.Errorf("failed to create the directory '%s' . Error: %w", filepath.Dir(destDir), err)
+	}
+	// the temporary directory is next to the destination, so that the verified files can be moved instead of copied
+	stagingDir, err := os.MkdirTemp(filepath.Dir(destDir), "."+collecttypes.CollectOutputDirName+"-bundle-*")
+	if err != nil {
+		return fmt.Errorf("failed to create a temporary directory to extract the collect bundle into. Error: %w", err)
+	}
+	defer os.RemoveAll(stagingDir)
+	manifest, err := extractCollectBundle(bundlePath, publicKey, publicKeyPath, stagingDir)
+	if err != nil {
+		return fmt.Errorf("failed to extract the collect bundle at path '%s' . Error: %w", bundlePath, err)
+	}
+	if _, err := os.Stat(destDir); err == nil {
+		if !hasCollectBundleFiles(destDir, manifest) {
+			return fmt.Errorf("the collect output directory '%s' already exists and does not match the collect bundle at path '%s' . Remove it to import the bundle", destDir, bundlePath)
+		}
+		logrus.Infof("The collect bundle at path '%s' was already imported into the directory '%s'", bundlePath, destDir)
+		return nil
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to stat the collect output directory '%s' . Error: %w", destDir, err)
+	}
+	if err := os.Rename(filepath.Join(stagingDir, collecttypes.CollectOutputDirName), destDir); err != nil {
+		return fmt.Errorf("failed to move the verified collect output into the directory '%s' . Error: %w", destDir, err)
+	}
+	logrus.Infof("Imported the collect bundle created at %s into the directory '%s'", manifest.Spec.CreatedAt, destDir)
+	return nil
+}
+
+// extractCollectBundle streams the files of the bundle into the directory and verifies them against the manifest.
+// The manifest and its signature come before the files, so that the manifest is verified before any file is written.
+func extractCollectBundle(bundlePath string, publicKey ed25519.PublicKey, publicKeyPath string, destDir string) (collecttypes.CollectBundle, error) {
+	manifest := collecttypes.CollectBundle{}
+	f, err := os.Open(bundlePath)
+	if err != nil {
+		return manifest, err
+	}
+	defer f.Close()
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		return manifest, fmt.Errorf("failed to create a gzip reader. Error: %w", err)
+	}
+	defer gr.Close()
+	tr := tar.NewReader(gr)
+	var manifestBytes, signature []byte
+	checksums := map[string]string{}
+	verifyManifest := func() error {
+		if manifestBytes == nil {
+			return fmt.Errorf("the bundle does not start with a %s", collectBundleManifestFileName)
+		}
+		if publicKey != nil {
+			if signature == nil {
+				return fmt.Errorf("the bundle is not signed")
+			}
+			if !ed25519.Verify(publicKey, manifestBytes, signature) {
+				return fmt.Errorf("the signature of the bundle does not match the public key at path '%s'", publicKeyPath)
+			}
+		} else if signature != nil {
+			logrus.Warnf("The collect bundle at path '%s' is signed, but no public key was given to verify it with.", bundlePath)
+		} else {
+			logrus.Warnf("Importing the unsigned collect bundle at path '%s' . It is only checked for corruption, not for tampering.", bundlePath)
+		}
+		if err := yaml.Unmarshal(manifestBytes, &manifest); err != nil {
+			return fmt.Errorf("failed to parse the manifest. Error: %w", err)
+		}
+		if manifest.Kind != string(collecttypes.CollectBundleKind) {
+			return fmt.Errorf("the manifest has the kind '%s' . Expected: '%s'", manifest.Kind, collecttypes.CollectBundleKind)
+		}
+		for _, f := range manifest.Spec.Files {
+			if !strings.HasPrefix(f.Path, collecttypes.CollectOutputDirName+"/") {
+				return fmt.Errorf("the file '%s' is outside the %s directory", f.Path, collecttypes.CollectOutputDirName)
+			}
+			p := filepath.Join(destDir, filepath.FromSlash(f.Path))
+			if !strings.HasPrefix(p, filepath.Join(destDir, collecttypes.CollectOutputDirName)+string(os.PathSeparator)) {
+				return fmt.Errorf("the file '%s' is outside the %s directory", f.Path, collecttypes.CollectOutputDirName)
+			}
+			checksums[f.Path] = f.SHA256
+		}
+		return nil
+	}
+	verified := false
+	extracted := map[string]bool{}
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return manifest, fmt.Errorf("failed to read the tar archive. Error: %w", err)
+		}
+		if header.Typeflag == tar.TypeDir {
+			continue
+		}
+		if header.Typeflag != tar.TypeReg {
+			return manifest, fmt.Errorf("the entry '%s' is not a regular file", header.Name)
+		}
+		if !verified && (header.Name == collectBundleManifestFileName || header.Name == collectBundleSignatureFileName) {
+			data, err := io.ReadAll(io.LimitReader(tr, maxCollectBundleManifestSize+1))
+			if err != nil {
+				return manifest, fmt.Errorf("failed to read the entry '%s' . Error: %w", header.Name, err)
+			}
+			if len(data) > maxCollectBundleManifestSize {
+				return manifest, fmt.Errorf("the entry '%s' is larger than %d bytes", header.Name, maxCollectBundleManifestSize)
+			}
+			if header.Name == collectBundleManifestFileName {
+				if manifestBytes != nil {
+					return manifest, fmt.Errorf("the entry '%s' occurs more than once", header.Name)
+				}
+				manifestBytes = data
+			} else {
+				if signature != nil {
+					return manifest, fmt.Errorf("the entry '%s' occurs more than once", header.Name)
+				}
+				signature = data
+			}
+			continue
+		}
+		if !verified {
+			if err := verifyManifest(); err != nil {
+				return manifest, err
+			}
+			verified = true
+		}
+		checksum, ok := checksums[header.Name]
+		if !ok {
+			return manifest, fmt.Errorf("the file '%s' is not listed in the manifest", header.Name)
+		}
+		if extracted[header.Name] {
+			return manifest, fmt.Errorf("the entry '%s' occurs more than once", header.Name)
+		}
+		if err := extractCollectBundleFile(tr, filepath.Join(destDir, filepath.FromSlash(header.Name)), checksum); err != nil {
+			return manifest, fmt.Errorf("failed to extract the file '%s' . Error: %w", header.Name, err)
+		}
+		extracted[header.Name] = true
+	}
+	if !verified {
+		if err := verifyManifest(); err != nil {
+			return manifest, err
+		}
+	}
+	for _, f := range manifest.Spec.Files {
+		if !extracted[f.Path] {
+			return manifest, fmt.Errorf("the file '%s' listed in the manifest is missing from the bundle", f.Path)
+		}
+	}
+	return manifest, nil
+}
+
+// extractCollectBundleFile streams a file of the bundle to the path and checks its checksum
+func extractCollectBundleFile(r io.Reader, filePath, checksum string) error {
+	if err := os.MkdirAll(filepath.Dir(filePath), common.DefaultDirectoryPermission); err != nil {
+		return fmt.Errorf("failed to create the directory '%s' . Error: %w", filepath.Dir(filePath), err)
+	}
+	f, err := os.OpenFile(filePath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, common.DefaultFilePermission)
+	if err != nil {
+		return fmt.Errorf("failed to create the file at path '%s' . Error: %w", filePath, err)
+	}
+	defer f.Close()
+	hash := sha256.New()
+	n, err := io.Copy(io.MultiWriter(f, hash), io.LimitReader(r, maxCollectBundleFileSize+1))
+	if err != nil {
+		return fmt.Errorf("failed to write the file at path '%s' . Error: %w", filePath, err)
+	}
+	if n > maxCollectBundleFileSize {
+		return fmt.Errorf("the file is larger than %d bytes", maxCollectBundleFileSize)
+	}
+	if hex.EncodeToString(hash.Sum(nil)) != checksum {
+		return fmt.Errorf("the checksum of the file does not match the manifest")
+	}
+	return nil
+}
+
+// hasCollectBundleFiles checks whether the directory has exactly the files listed in the manifest
+func hasCollectBundleFiles(collectDir string, manifest collecttypes.CollectBundle) bool {
+	numFiles := 0
+	if err := filepath.WalkDir(collectDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			numFiles++
+		}
+		return nil
+	}); err != nil || numFiles != len(manifest.Spec.Files) {
+		return false
+	}
+	baseDir := filepath.Dir(collectDir)
+	for _, f := range manifest.Spec.Files {
+		checksum, err := getFileSHA256(filepath.Join(baseDir, filepath.FromSlash(f.Path)))
+		if err != nil || checksum != f.SHA256 {
+			return false
+		}
+	}
+	return true
+}
+
+func getFileSHA256(filePath string) (string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open the file at path '%s' . Error: %w", filePath, err)
+	}
+	defer f.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", fmt.Errorf("failed to read the file at path '%s' . Error: %w", filePath, err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func getOrCreateBundlePrivateKey(keyPath string) (ed25519.PrivateKey, error) {
+	keyBytes, err := os.ReadFile(keyPath)
+	if err == nil {
+		block, _ := pem.Decode(keyBytes)
+		if block == nil || block.Type != privateKeyPemType {
+			return nil, fmt.Errorf("the file at path '%s' does not contain a PEM encoded private key", keyPath)
+		}
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the private key at path '%s' . Error: %w", keyPath, err)
+		}
+		privateKey, ok := key.(ed25519.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("the private key at path '%s' is of type %T . Expected an ed25519 key", keyPath, key)
+		}
+		return privateKey, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read the private key at path '%s' . Error: %w", keyPath, err)
+	}
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate a key pair for signing the collect bundle. Error: %w", err)
+	}
+	privateKeyBytes, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal the generated private key. Error: %w", err)
+	}
+	publicKeyBytes, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal the generated public key. Error: %w", err)
+	}
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: privateKeyPemType, Bytes: privateKeyBytes}), 0600); err != nil {
+		return nil, fmt.Errorf("failed to write the private key to the path '%s' . Error: %w", keyPath, err)
+	}
+	publicKeyPath := keyPath + ".pub"
+	if err := os.WriteFile(publicKeyPath, pem.EncodeToMemory(&pem.Block{Type: publicKeyPemType, Bytes: publicKeyBytes}), common.DefaultFilePermission); err != nil {
+		return nil, fmt.Errorf("failed to write the public key to the path '%s' . Error: %w", publicKeyPath, err)
+	}
+	logrus.Infof("Generated a new key pair for signing collect bundles. Private key: '%s' Public key: '%s'", keyPath, publicKeyPath)
+	return privateKey, nil
+}
+
+func readBundlePublicKey(publicKeyPath string) (ed25519.PublicKey, error) {
+	keyBytes, err := os.ReadFile(publicKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the public key at path '%s' . Error: %w", publicKeyPath, err)
+	}
+	block, _ := pem.Decode(keyBytes)
+	if block == nil || block.Type != publicKeyPemType {
+		return nil, fmt.Errorf("the file at path '%s' does not contain a PEM encoded public key", publicKeyPath)
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the public key at path '%s' . Error: %w", publicKeyPath, err)
+	}
+	publicKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("the public key at path '%s' is of type %T . Expected an ed25519 key", publicKeyPath, key)
+	}
+	return publicKey, nil
+}
+
+// writeCollectBundle streams the manifest, its signature and the files into the bundle.
+// The bundle is written to a temporary file first, so that a failed export does not leave a partial bundle behind.
+func writeCollectBundle(bundlePath string, manifestBytes, signature []byte, files []collecttypes.CollectBundleFile, filePaths map[string]string) (err error) {
+	if err := os.MkdirAll(filepath.Dir(bundlePath), common.DefaultDirectoryPermission); err != nil {
+		return fmt.Errorf("failed to create the directory '%s' . Error: %w", filepath.Dir(bundlePath), err)
+	}
+	bundleFile, err := os.CreateTemp(filepath.Dir(bundlePath), "."+filepath.Base(bundlePath)+"-*")
+	if err != nil {
+		return fmt.Errorf("failed to create a temporary file for the bundle. Error: %w", err)
+	}
+	defer func() {
+		bundleFile.Close()
+		if err != nil {
+			os.Remove(bundleFile.Name())
+		}
+	}()
+	gw := gzip.NewWriter(bundleFile)
+	tw := tar.NewWriter(gw)
+	writeEntry := func(name string, size int64, r io.Reader) error {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: int64(common.DefaultFilePermission), Size: size, Typeflag: tar.TypeReg}); err != nil {
+			return fmt.Errorf("failed to write the tar header for '%s' . Error: %w", name, err)
+		}
+		if _, err := io.Copy(tw, r); err != nil {
+			return fmt.Errorf("failed to write '%s' to the tar archive. Error: %w", name, err)
+		}
+		return nil
+	}
+	if err := writeEntry(collectBundleManifestFileName, int64(len(manifestBytes)), bytes.NewReader(manifestBytes)); err != nil {
+		return err
+	}
+	if signature != nil {
+		if err := writeEntry(collectBundleSignatureFileName, int64(len(signature)), bytes.NewReader(signature)); err != nil {
+			return err
+		}
+	}
+	for _, file := range files {
+		if err := writeCollectBundleFile(writeEntry, file, filePaths[file.Path]); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to close the tar writer. Error: %w", err)
+	}
+	if err := gw.Close(); err != nil {
+		return fmt.Errorf("failed to close the gzip writer. Error: %w", err)
+	}
+	if err := bundleFile.Close(); err != nil {
+		return fmt.Errorf("failed to close the bundle file. Error: %w", err)
+	}
+	return os.Rename(bundleFile.Name(), bundlePath)
+}
+
+// writeCollectBundleFile streams a file into the bundle and checks that it did not change since the manifest was created
+func writeCollectBundleFile(writeEntry func(string, int64, io.Reader) error, file collecttypes.CollectBundleFile, filePath string) error {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to open the file at path '%s' . Error: %w", filePath, err)
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat the file at path '%s' . Error: %w", filePath, err)
+	}
+	hash := sha256.New()
+	if err := writeEntry(file.Path, fi.Size(), io.TeeReader(f, hash)); err != nil {
+		return err
+	}
+	if hex.EncodeToString(hash.Sum(nil)) != file.SHA256 {
+		return fmt.Errorf("the file at path '%s' changed while the bundle was being written", filePath)
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package lib
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+)
+
+func TestCollectBundle(t *testing.T) {
+	setup := func(t *testing.T) (string, string, string) {
+		t.Helper()
+		tmpDir := t.TempDir()
+		collectDir := filepath.Join(tmpDir, collecttypes.CollectOutputDirName)
+		if err := os.MkdirAll(filepath.Join(collectDir, "images"), 0755); err != nil {
+			t.Fatalf("failed to create the collect output dir. Error: %q", err)
+		}
+		if err := os.WriteFile(filepath.Join(collectDir, "images", "nginx.yaml"), []byte("kind: ImageInfo\n"), 0644); err != nil {
+			t.Fatalf("failed to write the collect output. Error: %q", err)
+		}
+		if err := os.WriteFile(filepath.Join(collectDir, "clusterinfo.yaml"), []byte("kind: ClusterMetadata\n"), 0644); err != nil {
+			t.Fatalf("failed to write the collect output. Error: %q", err)
+		}
+		return tmpDir, collectDir, filepath.Join(tmpDir, "bundle.tar.gz")
+	}
+
+	t.Run("signed bundle is verified and extracted into the source directory", func(t *testing.T) {
+		tmpDir, collectDir, bundlePath := setup(t)
+		keyPath := filepath.Join(tmpDir, "bundle.key")
+		if err := ExportCollectBundle(collectDir, bundlePath, keyPath); err != nil {
+			t.Fatalf("failed to export the bundle. Error: %q", err)
+		}
+		if _, err := os.Stat(keyPath + ".pub"); err != nil {
+			t.Fatalf("expected a public key to be generated. Error: %q", err)
+		}
+		sourceDir := t.TempDir()
+		if err := ImportCollectBundle(bundlePath, keyPath+".pub", false, sourceDir); err != nil {
+			t.Fatalf("failed to import the bundle. Error: %q", err)
+		}
+		data, err := os.ReadFile(filepath.Join(sourceDir, collecttypes.CollectOutputDirName, "images", "nginx.yaml"))
+		if err != nil {
+			t.Fatalf("failed to read the extracted file. Error: %q", err)
+		}
+		if string(data) != "kind: ImageInfo\n" {
+			t.Fatalf("unexpected content of the extracted file: %q", string(data))
+		}
+		// importing the same bundle again leaves the collect output as it is
+		if err := ImportCollectBundle(bundlePath, keyPath+".pub", false, sourceDir); err != nil {
+			t.Fatalf("failed to import the bundle again. Error: %q", err)
+		}
+		if err := os.WriteFile(filepath.Join(sourceDir, collecttypes.CollectOutputDirName, "clusterinfo.yaml"), []byte("kind: Changed\n"), 0644); err != nil {
+			t.Fatalf("failed to change the extracted file. Error: %q", err)
+		}
+		if err := ImportCollectBundle(bundlePath, keyPath+".pub", false, sourceDir); err == nil {
+			t.Fatalf("expected the changed collect output not to be overwritten")
+		}
+		// the existing key is reused for the next export
+		if err := ExportCollectBundle(collectDir, bundlePath, keyPath); err != nil {
+			t.Fatalf("failed to export the bundle with the existing key. Error: %q", err)
+		}
+		if err := ImportCollectBundle(bundlePath, keyPath+".pub", false, t.TempDir()); err != nil {
+			t.Fatalf("failed to import the bundle signed with the existing key. Error: %q", err)
+		}
+	})
+
+	t.Run("bundle signed with a different key is rejected", func(t *testing.T) {
+		tmpDir, collectDir, bundlePath := setup(t)
+		otherKeyPath := filepath.Join(tmpDir, "other.key")
+		if err := ExportCollectBundle(collectDir, filepath.Join(tmpDir, "other.tar.gz"), otherKeyPath); err != nil {
+			t.Fatalf("failed to export the bundle. Error: %q", err)
+		}
+		if err := ExportCollectBundle(collectDir, bundlePath, filepath.Join(tmpDir, "bundle.key")); err != nil {
+			t.Fatalf("failed to export the bundle. Error: %q", err)
+		}
+		sourceDir := t.TempDir()
+		if err := ImportCollectBundle(bundlePath, otherKeyPath+".pub", false, sourceDir); err == nil {
+			t.Fatalf("expected the bundle signed with a different key to be rejected")
+		}
+		if _, err := os.Stat(filepath.Join(sourceDir, collecttypes.CollectOutputDirName)); !os.IsNotExist(err) {
+			t.Fatalf("expected nothing to be extracted from a rejected bundle")
+		}
+	})
+
+	t.Run("unsigned bundle is only imported when explicitly allowed", func(t *testing.T) {
+		tmpDir, collectDir, bundlePath := setup(t)
+		if err := ExportCollectBundle(collectDir, bundlePath, ""); err != nil {
+			t.Fatalf("failed to export the bundle. Error: %q", err)
+		}
+		if err := ImportCollectBundle(bundlePath, "", false, t.TempDir()); err == nil {
+			t.Fatalf("expected the unsigned bundle to be rejected when unsigned bundles are not allowed")
+		}
+		if err := ImportCollectBundle(bundlePath, "", true, t.TempDir()); err != nil {
+			t.Fatalf("failed to import the unsigned bundle. Error: %q", err)
+		}
+		keyPath := filepath.Join(tmpDir, "bundle.key")
+		if err := ExportCollectBundle(collectDir, filepath.Join(tmpDir, "signed.tar.gz"), keyPath); err != nil {
+			t.Fatalf("failed to export the bundle. Error: %q", err)
+		}
+		if err := ImportCollectBundle(bundlePath, keyPath+".pub", false, t.TempDir()); err == nil {
+			t.Fatalf("expected the unsigned bundle to be rejected")
+		}
+	})
+
+	t.Run("tampered bundle is rejected", func(t *testing.T) {
+		_, collectDir, bundlePath := setup(t)
+		if err := ExportCollectBundle(collectDir, bundlePath, ""); err != nil {
+			t.Fatalf("failed to export the bundle. Error: %q", err)
+		}
+		files, entries := readTestCollectBundle(t, bundlePath)
+		files[collecttypes.CollectOutputDirName+"/clusterinfo.yaml"] = []byte("kind: Tampered\n")
+		writeTestCollectBundle(t, bundlePath, entries, files)
+		sourceDir := t.TempDir()
+		if err := ImportCollectBundle(bundlePath, "", true, sourceDir); err == nil {
+			t.Fatalf("expected the bundle with a modified file to be rejected")
+		}
+		if entries, err := os.ReadDir(sourceDir); err != nil || len(entries) != 0 {
+			t.Fatalf("expected nothing to be left in the source directory after a rejected bundle. Actual: %+v", entries)
+		}
+		files[collecttypes.CollectOutputDirName+"/clusterinfo.yaml"] = []byte("kind: ClusterMetadata\n")
+		files[collecttypes.CollectOutputDirName+"/extra.yaml"] = []byte("kind: Extra\n")
+		writeTestCollectBundle(t, bundlePath, append(entries, collecttypes.CollectOutputDirName+"/extra.yaml"), files)
+		if err := ImportCollectBundle(bundlePath, "", true, t.TempDir()); err == nil {
+			t.Fatalf("expected the bundle with a file not listed in the manifest to be rejected")
+		}
+		writeTestCollectBundle(t, bundlePath, append(entries[1:], entries[0]), files)
+		if err := ImportCollectBundle(bundlePath, "", true, t.TempDir()); err == nil {
+			t.Fatalf("expected the bundle with files before the manifest to be rejected")
+		}
+	})
+}
+
+func readTestCollectBundle(t *testing.T, bundlePath string) (map[string][]byte, []string) {
+	t.Helper()
+	f, err := os.Open(bundlePath)
+	if err != nil {
+		t.Fatalf("failed to open the bundle. Error: %q", err)
+	}
+	defer f.Close()
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("failed to create a gzip reader. Error: %q", err)
+	}
+	tr := tar.NewReader(gr)
+	files := map[string][]byte{}
+	entries := []string{}
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("failed to read the bundle. Error: %q", err)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatalf("failed to read the entry '%s' . Error: %q", header.Name, err)
+		}
+		files[header.Name] = data
+		entries = append(entries, header.Name)
+	}
+	return files, entries
+}
+
+func writeTestCollectBundle(t *testing.T, bundlePath string, entries []string, files map[string][]byte) {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	gw := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gw)
+	for _, name := range entries {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(files[name])), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatalf("failed to write the tar header for '%s' . Error: %q", name, err)
+		}
+		if _, err := tw.Write(files[name]); err != nil {
+			t.Fatalf("failed to write '%s' to the tar archive. Error: %q", name, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("failed to close the tar writer. Error: %q", err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatalf("failed to close the gzip writer. Error: %q", err)
+	}
+	if err := os.WriteFile(bundlePath, buf.Bytes(), 0644); err != nil {
+		t.Fatalf("failed to write the bundle. Error: %q", err)
+	}
+}
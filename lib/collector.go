@@ -35,12 +35,7 @@ func Collect(inputPath string, outputPath string, annotations []string) {
 		logrus.Fatalf("Unable to create output directory at path %q Error: %q", outputPath, err)
 	}
 	logrus.Infoln("Begin collection")
-	for _, collector := range collectors {
-		if len(annotations) > 0 {
-			if !hasOverlap(annotations, collector.GetAnnotations()) {
-				continue
-			}
-		}
+	for _, collector := range getSelectedCollectors(collectors, annotations) {
 		logrus.Infof("[%T] Begin collection", collector)
 		if err = collector.Collect(inputPath, outputPath); err != nil {
 			logrus.Warnf("[%T] failed. Error: %q", collector, err)
@@ -51,6 +46,24 @@ func Collect(inputPath string, outputPath string, annotations []string) {
 	logrus.Infoln("Collection done")
 }
 
+// getSelectedCollectors returns the collectors selected by the annotations.
+// All the collectors, except the opt in ones, are selected if there are no annotations.
+func getSelectedCollectors(collectors []collector.Collector, annotations []string) []collector.Collector {
+	selectedCollectors := []collector.Collector{}
+	for _, c := range collectors {
+		if len(annotations) == 0 {
+			if collector.IsOptIn(c) {
+				logrus.Infof("[%T] Skipping the collector since it only runs when it is selected using the annotations %+v", c, c.GetAnnotations())
+				continue
+			}
+		} else if !hasOverlap(annotations, c.GetAnnotations()) {
+			continue
+		}
+		selectedCollectors = append(selectedCollectors, c)
+	}
+	return selectedCollectors
+}
+
 func hasOverlap(a []string, b []string) bool {
 	for _, val1 := range a {
 		for _, val2 := range b {
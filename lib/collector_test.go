@@ -0,0 +1,43 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package lib
+
+import (
+	"testing"
+
+	collector "github.com/konveyor/move2kube/collector"
+)
+
+func TestGetSelectedCollectors(t *testing.T) {
+	collectors := []collector.Collector{new(collector.ImagesCollector), new(collector.DockerContainersCollector)}
+	isSelected := func(selectedCollectors []collector.Collector, c collector.Collector) bool {
+		for _, selectedCollector := range selectedCollectors {
+			if selectedCollector == c {
+				return true
+			}
+		}
+		return false
+	}
+	selectedCollectors := getSelectedCollectors(collectors, nil)
+	if !isSelected(selectedCollectors, collectors[0]) || isSelected(selectedCollectors, collectors[1]) {
+		t.Fatalf("expected only the collectors that are not opt in to be selected by default. Actual: %+v", selectedCollectors)
+	}
+	selectedCollectors = getSelectedCollectors(collectors, []string{"dockercontainers"})
+	if isSelected(selectedCollectors, collectors[0]) || !isSelected(selectedCollectors, collectors[1]) {
+		t.Fatalf("expected the opt in collector to be selected using its annotation. Actual: %+v", selectedCollectors)
+	}
+}
@@ -0,0 +1,49 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package lib
+
+import (
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/transformer"
+)
+
+// OnServiceDetected registers a callback that is called for every service detected during planning
+func OnServiceDetected(hook transformer.ServiceDetectedHook) {
+	transformer.AddServiceDetectedHook(hook)
+}
+
+// OnArtifactGenerated registers a callback that can audit, modify or veto every artifact produced by a transformer.
+// When the transformers run in parallel the callbacks are called from different goroutines, but never concurrently.
+func OnArtifactGenerated(hook transformer.ArtifactGeneratedHook) {
+	transformer.AddArtifactGeneratedHook(hook)
+}
+
+// OnQuestionAsked registers a callback that is called with every question and its answer
+func OnQuestionAsked(hook qaengine.QuestionHook) {
+	qaengine.AddQuestionHook(hook)
+}
+
+// OnTransformDone registers a callback that is called when the transformation finishes
+func OnTransformDone(hook transformer.TransformDoneHook) {
+	transformer.AddTransformDoneHook(hook)
+}
+
+// ResetHooks removes all the registered callbacks
+func ResetHooks() {
+	transformer.ResetHooks()
+	qaengine.ResetQuestionHooks()
+}
@@ -0,0 +1,453 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package lib
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	plantypes "github.com/konveyor/move2kube/types/plan"
+	qatypes "github.com/konveyor/move2kube/types/qaengine"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// IncrementalStateFileName is the name of the file in the output directory that stores the fingerprints of the last transformation
+	IncrementalStateFileName = ".m2kincremental.json"
+	incrementalStagingDir    = "incremental-output"
+)
+
+// incrementalState stores the fingerprints of the inputs and outputs of a transformation
+type incrementalState struct {
+	// Services maps the service name to the fingerprint of its plan entry and source files
+	Services map[string]string `json:"services"`
+	// Answers maps the id of each question asked during the transformation to the fingerprint of its answer
+	Answers map[string]string `json:"answers"`
+	// Outputs maps the output file path, relative to the output directory, to the fingerprint of its contents
+	Outputs map[string]string `json:"outputs"`
+	// OutputServices maps the output file path to the services whose transformation generated the file
+	OutputServices map[string][]string `json:"outputServices"`
+}
+
+// answerRecorder records the answers to all the questions asked during the transformation
+type answerRecorder struct {
+	mutex   sync.Mutex
+	answers map[string]interface{}
+}
+
+var (
+	recorder        = &answerRecorder{answers: map[string]interface{}{}}
+	addRecorderHook sync.Once
+)
+
+func (r *answerRecorder) reset() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.answers = map[string]interface{}{}
+}
+
+func (r *answerRecorder) record(prob qatypes.Problem) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.answers[prob.ID] = prob.Answer
+}
+
+func (r *answerRecorder) fingerprints() (map[string]string, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	fingerprints := map[string]string{}
+	for id, answer := range r.answers {
+		// json encodes maps with sorted keys
+		answerBytes, err := json.Marshal(answer)
+		if err != nil {
+			return fingerprints, fmt.Errorf("failed to marshal the answer to the question '%s' to json. Error: %w", id, err)
+		}
+		fingerprints[id] = common.GetSHA256Hash(string(answerBytes))
+	}
+	return fingerprints, nil
+}
+
+// readIncrementalState reads the state of the last transformation from the output directory.
+// It returns an empty state if the output directory has not been incrementally transformed before.
+func readIncrementalState(outputPath string) incrementalState {
+	state := newIncrementalState()
+	stateBytes, err := os.ReadFile(filepath.Join(outputPath, IncrementalStateFileName))
+	if err != nil {
+		logrus.Debugf("failed to read the incremental state from the output directory '%s' . Error: %q", outputPath, err)
+		return state
+	}
+	if err := json.Unmarshal(stateBytes, &state); err != nil {
+		logrus.Warnf("failed to parse the incremental state in the output directory '%s' . Doing a full transformation. Error: %q", outputPath, err)
+		return newIncrementalState()
+	}
+	if state.Services == nil {
+		state.Services = map[string]string{}
+	}
+	if state.Answers == nil {
+		state.Answers = map[string]string{}
+	}
+	if state.Outputs == nil {
+		state.Outputs = map[string]string{}
+	}
+	if state.OutputServices == nil {
+		state.OutputServices = map[string][]string{}
+	}
+	return state
+}
+
+func newIncrementalState() incrementalState {
+	return incrementalState{Services: map[string]string{}, Answers: map[string]string{}, Outputs: map[string]string{}, OutputServices: map[string][]string{}}
+}
+
+func writeIncrementalState(outputPath string, state incrementalState) error {
+	stateBytes, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal the incremental state to json. Error: %w", err)
+	}
+	stateFilePath := filepath.Join(outputPath, IncrementalStateFileName)
+	if err := os.WriteFile(stateFilePath, stateBytes, common.DefaultFilePermission); err != nil {
+		return fmt.Errorf("failed to write the incremental state to the file at path '%s' . Error: %w", stateFilePath, err)
+	}
+	return nil
+}
+
+// getServiceFingerprints returns the fingerprints of the plan entries and source files of the selected services
+func getServiceFingerprints(sourceDir string, planArtifacts []plantypes.PlanArtifact) (map[string]string, error) {
+	artifactsByService := map[string][]plantypes.PlanArtifact{}
+	for _, planArtifact := range planArtifacts {
+		artifactsByService[planArtifact.ServiceName] = append(artifactsByService[planArtifact.ServiceName], planArtifact)
+	}
+	fingerprints := map[string]string{}
+	for serviceName, serviceArtifacts := range artifactsByService {
+		hasher := sha256.New()
+		planBytes, err := json.Marshal(serviceArtifacts)
+		if err != nil {
+			return fingerprints, fmt.Errorf("failed to marshal the plan entries of the service '%s' to json. Error: %w", serviceName, err)
+		}
+		hasher.Write(planBytes)
+		paths := []string{}
+		for _, serviceArtifact := range serviceArtifacts {
+			for _, artifactPaths := range serviceArtifact.Paths {
+				for _, artifactPath := range artifactPaths {
+					if !filepath.IsAbs(artifactPath) {
+						artifactPath = filepath.Join(sourceDir, artifactPath)
+					}
+					paths = append(paths, artifactPath)
+				}
+			}
+		}
+		sort.Strings(paths)
+		for _, path := range paths {
+			if err := hashPath(hasher, path); err != nil {
+				return fingerprints, fmt.Errorf("failed to compute the fingerprint of the service '%s' . Error: %w", serviceName, err)
+			}
+		}
+		fingerprints[serviceName] = fmt.Sprintf("%x", hasher.Sum(nil))
+	}
+	return fingerprints, nil
+}
+
+// hashPath writes the names and contents of all the files at the path to the hasher
+func hashPath(hasher io.Writer, path string) error {
+	return filepath.WalkDir(path, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		f, err := os.Open(filePath)
+		if err != nil {
+			return fmt.Errorf("failed to open the file at path '%s' . Error: %w", filePath, err)
+		}
+		defer f.Close()
+		io.WriteString(hasher, filePath)
+		if _, err := io.Copy(hasher, f); err != nil {
+			return fmt.Errorf("failed to read the file at path '%s' . Error: %w", filePath, err)
+		}
+		return nil
+	})
+}
+
+// getFileFingerprint returns the fingerprint of the contents of the file
+func getFileFingerprint(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, f); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", hasher.Sum(nil)), nil
+}
+
+// getChangedServices returns the services that were added, removed or whose inputs changed since the last transformation
+func getChangedServices(oldState, newState incrementalState) []string {
+	changedServices := []string{}
+	for serviceName, fingerprint := range newState.Services {
+		if oldFingerprint, ok := oldState.Services[serviceName]; !ok || oldFingerprint != fingerprint {
+			changedServices = append(changedServices, serviceName)
+		}
+	}
+	for serviceName := range oldState.Services {
+		if _, ok := newState.Services[serviceName]; !ok {
+			changedServices = append(changedServices, serviceName)
+		}
+	}
+	sort.Strings(changedServices)
+	return changedServices
+}
+
+// getChangedAnswers returns the questions that were answered differently than in the last transformation
+func getChangedAnswers(oldAnswers, newAnswers map[string]string) []string {
+	changedAnswers := []string{}
+	for id, fingerprint := range newAnswers {
+		if oldFingerprint, ok := oldAnswers[id]; ok && oldFingerprint != fingerprint {
+			changedAnswers = append(changedAnswers, id)
+		}
+	}
+	sort.Strings(changedAnswers)
+	return changedAnswers
+}
+
+// getStagedOutputs returns the fingerprints of the files in the staging directory, keyed by their paths relative to the staging directory
+func getStagedOutputs(stagingPath string) (map[string]string, error) {
+	stagedOutputs := map[string]string{}
+	err := filepath.WalkDir(stagingPath, func(stagedFilePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		relFilePath, err := filepath.Rel(stagingPath, stagedFilePath)
+		if err != nil {
+			return fmt.Errorf("failed to make the path '%s' relative to the staging directory '%s' . Error: %w", stagedFilePath, stagingPath, err)
+		}
+		fingerprint, err := getFileFingerprint(stagedFilePath)
+		if err != nil {
+			return fmt.Errorf("failed to compute the fingerprint of the file at path '%s' . Error: %w", stagedFilePath, err)
+		}
+		stagedOutputs[relFilePath] = fingerprint
+		return nil
+	})
+	return stagedOutputs, err
+}
+
+// getSharedOutputs returns the staged files which depend on the services that were not transformed again.
+// They cannot be rewritten by transforming only some of the services.
+func getSharedOutputs(stagedOutputs map[string]string, oldState incrementalState, transformedServices []string) []string {
+	sharedOutputs := []string{}
+	for relFilePath := range stagedOutputs {
+		for _, serviceName := range oldState.OutputServices[relFilePath] {
+			if !common.IsPresent(transformedServices, serviceName) {
+				sharedOutputs = append(sharedOutputs, relFilePath)
+				break
+			}
+		}
+	}
+	sort.Strings(sharedOutputs)
+	return sharedOutputs
+}
+
+// getOutputServices returns the services that each of the output files depends on.
+// A file generated for the first time depends on all the transformed services. When all the services had to be transformed
+// after transforming only the changed services, partialOutputs has the files generated by the changed services alone.
+// The files they generated with the same contents do not depend on the other services, and
+// the files they did not generate do not depend on the changed services.
+func getOutputServices(outputs, stagedOutputs, partialOutputs map[string]string, oldState incrementalState, transformedServices, changedServices []string) map[string][]string {
+	outputServices := map[string][]string{}
+	for relFilePath := range outputs {
+		services, ok := oldState.OutputServices[relFilePath]
+		fingerprint, staged := stagedOutputs[relFilePath]
+		if !staged {
+			outputServices[relFilePath] = services
+			continue
+		}
+		if !ok {
+			services = transformedServices
+		}
+		if partialOutputs != nil {
+			partialFingerprint, generated := partialOutputs[relFilePath]
+			dependencies := []string{}
+			for _, serviceName := range services {
+				if generated == common.IsPresent(changedServices, serviceName) {
+					dependencies = append(dependencies, serviceName)
+				}
+			}
+			if generated && partialFingerprint == fingerprint || !generated && len(dependencies) != 0 {
+				services = dependencies
+			}
+		}
+		outputServices[relFilePath] = services
+	}
+	return outputServices
+}
+
+// syncIncrementalOutput copies the files from the staging directory to the output directory.
+// Files whose contents did not change are left untouched. Files that depend only on the transformed
+// services and were not generated again are removed. The files of the other services are kept as is.
+func syncIncrementalOutput(stagingPath, outputPath string, oldState incrementalState, transformedServices []string, fullTransform bool) (outputs map[string]string, stagedOutputs map[string]string, err error) {
+	outputs = map[string]string{}
+	numWritten, numUntouched, numRemoved, numKept := 0, 0, 0, 0
+	if stagedOutputs, err = getStagedOutputs(stagingPath); err != nil {
+		return outputs, stagedOutputs, err
+	}
+	for relFilePath, fingerprint := range stagedOutputs {
+		outputs[relFilePath] = fingerprint
+		outputFilePath := filepath.Join(outputPath, relFilePath)
+		if existingFingerprint, err := getFileFingerprint(outputFilePath); err == nil && existingFingerprint == fingerprint {
+			numUntouched++
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(outputFilePath), common.DefaultDirectoryPermission); err != nil {
+			return outputs, stagedOutputs, fmt.Errorf("failed to create the directory for the output file at path '%s' . Error: %w", outputFilePath, err)
+		}
+		stagedFilePath := filepath.Join(stagingPath, relFilePath)
+		if err := common.CopyFile(outputFilePath, stagedFilePath); err != nil {
+			return outputs, stagedOutputs, fmt.Errorf("failed to copy the file from '%s' to '%s' . Error: %w", stagedFilePath, outputFilePath, err)
+		}
+		numWritten++
+	}
+	for relFilePath, fingerprint := range oldState.Outputs {
+		if _, ok := outputs[relFilePath]; ok {
+			continue
+		}
+		stale := fullTransform
+		if !fullTransform {
+			services := oldState.OutputServices[relFilePath]
+			stale = len(services) != 0
+			for _, serviceName := range services {
+				if !common.IsPresent(transformedServices, serviceName) {
+					stale = false
+					break
+				}
+			}
+		}
+		if !stale {
+			outputs[relFilePath] = fingerprint
+			numKept++
+			continue
+		}
+		if err := os.Remove(filepath.Join(outputPath, relFilePath)); err != nil && !os.IsNotExist(err) {
+			logrus.Warnf("failed to remove the stale output file '%s' . Error: %q", relFilePath, err)
+			continue
+		}
+		numRemoved++
+	}
+	logrus.Infof("Incremental transform: wrote %d files, left %d files untouched, kept %d files of the unchanged services and removed %d stale files", numWritten, numUntouched, numKept, numRemoved)
+	return outputs, stagedOutputs, nil
+}
+
+// transformIncrementally only transforms the services whose plan entries or source files changed since the last transformation,
+// in a staging directory, and only updates the output files whose contents changed. The output of the other services is kept.
+// All the services are transformed again if the answers to the questions changed, or if the changed services generate files
+// which also depend on the other services.
+func transformIncrementally(sourceDir, outputPath string, planArtifacts []plantypes.PlanArtifact, runTransform func(stagingPath string, planArtifacts []plantypes.PlanArtifact) error) error {
+	oldState := readIncrementalState(outputPath)
+	newState := newIncrementalState()
+	var err error
+	if newState.Services, err = getServiceFingerprints(sourceDir, planArtifacts); err != nil {
+		return fmt.Errorf("failed to compute the fingerprints of the services. Error: %w", err)
+	}
+	changedServices := getChangedServices(oldState, newState)
+	if len(changedServices) == 0 {
+		logrus.Infof("Incremental transform: none of the %d services changed since the last transformation", len(newState.Services))
+		return nil
+	}
+	logrus.Infof("Incremental transform: %d of %d services changed since the last transformation: %+v", len(changedServices), len(newState.Services), changedServices)
+	changedPlanArtifacts := []plantypes.PlanArtifact{}
+	for _, planArtifact := range planArtifacts {
+		if common.IsPresent(changedServices, planArtifact.ServiceName) {
+			changedPlanArtifacts = append(changedPlanArtifacts, planArtifact)
+		}
+	}
+	addRecorderHook.Do(func() { qaengine.AddQuestionHook(recorder.record) })
+	recorder.reset()
+	stagingPath := filepath.Join(common.TempPath, incrementalStagingDir)
+	if err := os.RemoveAll(stagingPath); err != nil {
+		return fmt.Errorf("failed to remove the staging directory '%s' . Error: %w", stagingPath, err)
+	}
+	defer os.RemoveAll(stagingPath)
+	if err := runTransform(stagingPath, changedPlanArtifacts); err != nil {
+		return err
+	}
+	if newState.Answers, err = recorder.fingerprints(); err != nil {
+		return fmt.Errorf("failed to compute the fingerprints of the answers. Error: %w", err)
+	}
+	fullTransform := len(changedPlanArtifacts) == len(planArtifacts)
+	var partialOutputs map[string]string
+	if !fullTransform {
+		if partialOutputs, err = getStagedOutputs(stagingPath); err != nil {
+			return fmt.Errorf("failed to list the files in the staging directory '%s' . Error: %w", stagingPath, err)
+		}
+		changedAnswers := getChangedAnswers(oldState.Answers, newState.Answers)
+		sharedOutputs := getSharedOutputs(partialOutputs, oldState, changedServices)
+		if len(changedAnswers) != 0 || len(sharedOutputs) != 0 {
+			logrus.Infof("Incremental transform: transforming all the services. Changed answers: %+v Files shared with the unchanged services: %+v", changedAnswers, sharedOutputs)
+			if err := os.RemoveAll(stagingPath); err != nil {
+				return fmt.Errorf("failed to remove the staging directory '%s' . Error: %w", stagingPath, err)
+			}
+			if err := runTransform(stagingPath, planArtifacts); err != nil {
+				return err
+			}
+			if newState.Answers, err = recorder.fingerprints(); err != nil {
+				return fmt.Errorf("failed to compute the fingerprints of the answers. Error: %w", err)
+			}
+			fullTransform = true
+		} else {
+			partialOutputs = nil
+		}
+	}
+	transformedServices := changedServices
+	if fullTransform {
+		transformedServices = getChangedServices(newIncrementalState(), newState)
+	} else {
+		// the questions of the unchanged services were not asked again
+		for id, fingerprint := range oldState.Answers {
+			if _, ok := newState.Answers[id]; !ok {
+				newState.Answers[id] = fingerprint
+			}
+		}
+	}
+	if err := os.MkdirAll(outputPath, common.DefaultDirectoryPermission); err != nil {
+		return fmt.Errorf("failed to create the output directory '%s' . Error: %w", outputPath, err)
+	}
+	stagedOutputs := map[string]string{}
+	if newState.Outputs, stagedOutputs, err = syncIncrementalOutput(stagingPath, outputPath, oldState, transformedServices, fullTransform); err != nil {
+		return fmt.Errorf("failed to copy the transformed artifacts to the output directory '%s' . Error: %w", outputPath, err)
+	}
+	newState.OutputServices = getOutputServices(newState.Outputs, stagedOutputs, partialOutputs, oldState, transformedServices, changedServices)
+	return writeIncrementalState(outputPath, newState)
+}
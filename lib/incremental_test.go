@@ -0,0 +1,188 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	plantypes "github.com/konveyor/move2kube/types/plan"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+)
+
+func TestSyncIncrementalOutput(t *testing.T) {
+	stagingPath := t.TempDir()
+	outputPath := t.TempDir()
+	writeFile := func(path, contents string) {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create the directory for '%s' . Error: %q", path, err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatalf("failed to write the file '%s' . Error: %q", path, err)
+		}
+	}
+	writeFile(filepath.Join(stagingPath, "deploy", "svc1.yaml"), "svc1")
+	writeFile(filepath.Join(stagingPath, "deploy", "svc2.yaml"), "svc2")
+	oldState := readIncrementalState(outputPath)
+	outputs, _, err := syncIncrementalOutput(stagingPath, outputPath, oldState, []string{"svc1", "svc2"}, true)
+	if err != nil {
+		t.Fatalf("failed to sync the output. Error: %q", err)
+	}
+	oldTime := time.Now().Add(-time.Hour)
+	untouchedFilePath := filepath.Join(outputPath, "deploy", "svc1.yaml")
+	if err := os.Chtimes(untouchedFilePath, oldTime, oldTime); err != nil {
+		t.Fatalf("failed to change the modification time of '%s' . Error: %q", untouchedFilePath, err)
+	}
+
+	// second run: svc1 is unchanged, svc2 is removed and svc3 is added
+	if err := os.RemoveAll(stagingPath); err != nil {
+		t.Fatalf("failed to clear the staging directory. Error: %q", err)
+	}
+	writeFile(filepath.Join(stagingPath, "deploy", "svc1.yaml"), "svc1")
+	writeFile(filepath.Join(stagingPath, "deploy", "svc3.yaml"), "svc3")
+	writeFile(filepath.Join(outputPath, "user-notes.txt"), "not generated by move2kube")
+	oldState.Outputs = outputs
+	if _, _, err := syncIncrementalOutput(stagingPath, outputPath, oldState, []string{"svc1", "svc2", "svc3"}, true); err != nil {
+		t.Fatalf("failed to sync the output. Error: %q", err)
+	}
+	info, err := os.Stat(untouchedFilePath)
+	if err != nil {
+		t.Fatalf("the unchanged file is missing. Error: %q", err)
+	}
+	if !info.ModTime().Equal(oldTime) {
+		t.Fatalf("the unchanged file was rewritten. Expected modification time %v Actual: %v", oldTime, info.ModTime())
+	}
+	if _, err := os.Stat(filepath.Join(outputPath, "deploy", "svc2.yaml")); !os.IsNotExist(err) {
+		t.Fatalf("expected the stale file to be removed. Error: %q", err)
+	}
+	for _, path := range []string{"deploy/svc3.yaml", "user-notes.txt"} {
+		if _, err := os.Stat(filepath.Join(outputPath, path)); err != nil {
+			t.Fatalf("expected the file '%s' to exist. Error: %q", path, err)
+		}
+	}
+}
+
+func TestGetChangedServices(t *testing.T) {
+	oldState := incrementalState{Services: map[string]string{"svc1": "a", "svc2": "b", "svc3": "c"}}
+	newState := incrementalState{Services: map[string]string{"svc1": "a", "svc2": "changed", "svc4": "d"}}
+	want := []string{"svc2", "svc3", "svc4"}
+	if diff := cmp.Diff(want, getChangedServices(oldState, newState)); diff != "" {
+		t.Fatalf("wrong changed services. Differences:\n%s", diff)
+	}
+}
+
+func TestTransformIncrementally(t *testing.T) {
+	oldTempPath := common.TempPath
+	common.TempPath = t.TempDir()
+	defer func() { common.TempPath = oldTempPath }()
+	sourceDir := t.TempDir()
+	outputPath := t.TempDir()
+	for _, serviceName := range []string{"svc1", "svc2"} {
+		if err := os.WriteFile(filepath.Join(sourceDir, serviceName+".txt"), []byte(serviceName), 0644); err != nil {
+			t.Fatalf("failed to write the source file of '%s' . Error: %q", serviceName, err)
+		}
+	}
+	planArtifacts := []plantypes.PlanArtifact{
+		{ServiceName: "svc1", Artifact: transformertypes.Artifact{Paths: map[transformertypes.PathType][]string{"File": {"svc1.txt"}}}},
+		{ServiceName: "svc2", Artifact: transformertypes.Artifact{Paths: map[transformertypes.PathType][]string{"File": {"svc2.txt"}}}},
+	}
+	transformedServices := []string{}
+	runTransform := func(stagingPath string, planArtifacts []plantypes.PlanArtifact) error {
+		for _, planArtifact := range planArtifacts {
+			transformedServices = append(transformedServices, planArtifact.ServiceName)
+			contents, err := os.ReadFile(filepath.Join(sourceDir, planArtifact.ServiceName+".txt"))
+			if err != nil {
+				return err
+			}
+			if err := os.MkdirAll(filepath.Join(stagingPath, "deploy"), 0755); err != nil {
+				return err
+			}
+			if err := os.WriteFile(filepath.Join(stagingPath, "deploy", planArtifact.ServiceName+".yaml"), contents, 0644); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := transformIncrementally(sourceDir, outputPath, planArtifacts, runTransform); err != nil {
+		t.Fatalf("failed to do the first incremental transformation. Error: %q", err)
+	}
+	if diff := cmp.Diff([]string{"svc1", "svc2"}, transformedServices); diff != "" {
+		t.Fatalf("all the services should be transformed the first time. Differences:\n%s", diff)
+	}
+
+	changeSource := func(serviceName, contents string) {
+		if err := os.WriteFile(filepath.Join(sourceDir, serviceName+".txt"), []byte(contents), 0644); err != nil {
+			t.Fatalf("failed to change the source file of '%s' . Error: %q", serviceName, err)
+		}
+	}
+
+	t.Run("all the services are transformed until it is known which services the files depend on", func(t *testing.T) {
+		transformedServices = []string{}
+		changeSource("svc2", "svc2 changed")
+		if err := transformIncrementally(sourceDir, outputPath, planArtifacts, runTransform); err != nil {
+			t.Fatalf("failed to do the second incremental transformation. Error: %q", err)
+		}
+		if diff := cmp.Diff([]string{"svc2", "svc1", "svc2"}, transformedServices); diff != "" {
+			t.Fatalf("expected the changed service to be transformed alone and then with all the services. Differences:\n%s", diff)
+		}
+	})
+
+	t.Run("only the changed service is transformed and the output of the others is kept", func(t *testing.T) {
+		transformedServices = []string{}
+		changeSource("svc1", "svc1 changed")
+		if err := transformIncrementally(sourceDir, outputPath, planArtifacts, runTransform); err != nil {
+			t.Fatalf("failed to do the third incremental transformation. Error: %q", err)
+		}
+		if diff := cmp.Diff([]string{"svc1"}, transformedServices); diff != "" {
+			t.Fatalf("the transformer of the unchanged service should not run. Differences:\n%s", diff)
+		}
+		transformedServices = []string{}
+		changeSource("svc2", "svc2 changed again")
+		if err := transformIncrementally(sourceDir, outputPath, planArtifacts, runTransform); err != nil {
+			t.Fatalf("failed to do the fourth incremental transformation. Error: %q", err)
+		}
+		if diff := cmp.Diff([]string{"svc2"}, transformedServices); diff != "" {
+			t.Fatalf("the transformer of the unchanged service should not run. Differences:\n%s", diff)
+		}
+		for serviceName, want := range map[string]string{"svc1": "svc1 changed", "svc2": "svc2 changed again"} {
+			contents, err := os.ReadFile(filepath.Join(outputPath, "deploy", serviceName+".yaml"))
+			if err != nil {
+				t.Fatalf("the output of '%s' is missing. Error: %q", serviceName, err)
+			}
+			if string(contents) != want {
+				t.Fatalf("wrong output for '%s' . Expected: %q Actual: %q", serviceName, want, string(contents))
+			}
+		}
+	})
+
+	t.Run("nothing is transformed when nothing changed", func(t *testing.T) {
+		transformedServices = []string{}
+		if err := transformIncrementally(sourceDir, outputPath, planArtifacts, runTransform); err != nil {
+			t.Fatalf("failed to do the fifth incremental transformation. Error: %q", err)
+		}
+		if len(transformedServices) != 0 {
+			t.Fatalf("expected no services to be transformed. Actual: %+v", transformedServices)
+		}
+		if _, err := os.Stat(filepath.Join(outputPath, "deploy", "svc1.yaml")); err != nil {
+			t.Fatalf("the output of 'svc1' is missing. Error: %q", err)
+		}
+	})
+}
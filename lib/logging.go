@@ -0,0 +1,34 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package lib
+
+import (
+	"context"
+
+	"github.com/konveyor/move2kube/common"
+)
+
+// WithLogger returns a context that routes the logs of the plan and transform runs started with it to the given logger.
+// The runs use the default logrus logger when the context does not have a logger.
+func WithLogger(ctx context.Context, logger common.Logger) context.Context {
+	return common.WithLogger(ctx, logger)
+}
+
+// WithLogLevel returns a context that sets the verbosity of the logs of the plan and transform runs started with it
+func WithLogLevel(ctx context.Context, level common.LogLevel) context.Context {
+	return common.WithLogLevel(ctx, level)
+}
@@ -30,14 +30,15 @@ import (
 
 // CreatePlan creates the plan using all the tranformers.
 func CreatePlan(ctx context.Context, inputPath, outputPath string, customizationsPath, transformerSelector, prjName string) (plantypes.Plan, error) {
+	defer common.UseContextLogger(ctx)()
 	logrus.Trace("CreatePlan start")
 	defer logrus.Trace("CreatePlan end")
 	plan := plantypes.NewPlan()
-	remoteInputFSPath, err := vcs.GetClonedPath(inputPath, common.RemoteSourcesFolder, true)
+	remoteInputFSPath, err := vcs.GetClonedPathWithContext(ctx, inputPath, common.RemoteSourcesFolder, true)
 	if err != nil {
 		return plan, fmt.Errorf("failed to clone the repo '%s'. Error: %w", inputPath, err)
 	}
-	remoteOutputFSPath, err := vcs.GetClonedPath(outputPath, common.RemoteOutputsFolder, true)
+	remoteOutputFSPath, err := vcs.GetClonedPathWithContext(ctx, outputPath, common.RemoteOutputsFolder, true)
 	if err != nil {
 		return plan, fmt.Errorf("failed to clone the repo '%s'. Error: %w", outputPath, err)
 	}
@@ -55,7 +56,7 @@ func CreatePlan(ctx context.Context, inputPath, outputPath string, customization
 		outputFSPath = remoteOutputFSPath
 	}
 	if customizationsPath != "" {
-		if err := CheckAndCopyCustomizations(customizationsPath); err != nil {
+		if err := CheckAndCopyCustomizations(ctx, customizationsPath); err != nil {
 			return plan, fmt.Errorf("failed to check and copy the customizations. Error: %w", err)
 		}
 	}
@@ -87,7 +88,7 @@ func CreatePlan(ctx context.Context, inputPath, outputPath string, customization
 
 	logrus.Info("Start planning")
 	if inputFSPath != "" {
-		plan.Spec.Services, err = transformer.GetServices(plan.Name, inputFSPath, nil)
+		plan.Spec.Services, err = transformer.GetServices(ctx, plan.Name, inputFSPath, nil)
 		if err != nil {
 			return plan, fmt.Errorf("failed to get services from the input directory '%s' . Error: %w", inputFSPath, err)
 		}
@@ -0,0 +1,37 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package lib
+
+import (
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/common/remotecache"
+)
+
+const (
+	// SandboxedPreset is the preset config that disables the transformers spawning containers
+	SandboxedPreset = "sandboxed"
+)
+
+// ApplySandbox restricts the transform engine to what a sandboxed host like a wasm runtime provides.
+// Local executables are never run and the environment of the current machine is ignored.
+// Remote sources and customizations are only read from the cache unless the host grants network access.
+// Use the SandboxedPreset while setting up the config to also disable the containerized transformers.
+func ApplySandbox(allowNetwork bool) {
+	common.DisableLocalExecution = true
+	common.IgnoreEnvironment = true
+	remotecache.SetOffline(!allowNetwork)
+}
@@ -20,13 +20,14 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"sort"
 
 	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/common/report"
 	"github.com/konveyor/move2kube/common/vcs"
 	"github.com/konveyor/move2kube/qaengine"
 	"github.com/konveyor/move2kube/transformer"
 	"github.com/konveyor/move2kube/transformer/external"
+	"github.com/konveyor/move2kube/types/info"
 	plantypes "github.com/konveyor/move2kube/types/plan"
 	"github.com/sirupsen/logrus"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -41,10 +42,37 @@ func Transform(
 	transformerSelector string,
 	maxIterations int,
 ) error {
+	return transform(ctx, plan, preExistingPlan, outputPath, transformerSelector, maxIterations, false)
+}
+
+// TransformIncremental is like Transform but only rewrites the output files whose contents changed since the last
+// transformation into the same output directory. Untouched output files keep their modification times.
+func TransformIncremental(
+	ctx context.Context,
+	plan plantypes.Plan,
+	preExistingPlan bool,
+	outputPath string,
+	transformerSelector string,
+	maxIterations int,
+) error {
+	return transform(ctx, plan, preExistingPlan, outputPath, transformerSelector, maxIterations, true)
+}
+
+func transform(
+	ctx context.Context,
+	plan plantypes.Plan,
+	preExistingPlan bool,
+	outputPath string,
+	transformerSelector string,
+	maxIterations int,
+	incremental bool,
+) error {
+	defer common.UseContextLogger(ctx)()
 	logrus.Infof("Starting transformation")
 	defer logrus.Infof("Transformation done")
 	common.ProjectName = plan.Name
 	logrus.Debugf("common.TempPath: '%s'", common.TempPath)
+	report.Reset()
 
 	transformerSelectorObj, err := common.ConvertStringSelectorsToSelectors(transformerSelector)
 	if err != nil {
@@ -57,7 +85,7 @@ func Transform(
 	requirements, _ := selectorsInPlan.Requirements()
 	transformerSelectorObj = transformerSelectorObj.Add(requirements...)
 
-	remoteOutputFSPath, err := vcs.GetClonedPath(outputPath, common.RemoteOutputsFolder, true)
+	remoteOutputFSPath, err := vcs.GetClonedPathWithContext(ctx, outputPath, common.RemoteOutputsFolder, true)
 	if err != nil {
 		return fmt.Errorf("failed to clone the repo '%s'. Error: %w", outputPath, err)
 	}
@@ -79,11 +107,7 @@ func Transform(
 	}
 
 	// select only the services the user is interested in
-	serviceNames := []string{}
-	for serviceName := range plan.Spec.Services {
-		serviceNames = append(serviceNames, serviceName)
-	}
-	sort.Strings(serviceNames)
+	serviceNames := common.GetSortedServiceNames(plan.Spec.Services)
 	selectedServiceNames := qaengine.FetchMultiSelectAnswer(
 		common.ConfigServicesNamesKey,
 		"Select all services that are needed:",
@@ -96,6 +120,9 @@ func Transform(
 	// select the first valid transformation option for each selected service
 	selectedTransformationOptions := []plantypes.PlanArtifact{}
 	for _, selectedServiceName := range selectedServiceNames {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("the transformation was stopped. Error: %w", err)
+		}
 		options := plan.Spec.Services[selectedServiceName]
 		found := false
 		if len(options) > 1 {
@@ -108,6 +135,7 @@ func Transform(
 			}
 			option.ServiceName = selectedServiceName
 			selectedTransformationOptions = append(selectedTransformationOptions, option)
+			report.AddService(selectedServiceName, option.TransformerName, option.Paths)
 			logrus.Infof("Using the transformation option '%s' for the service '%s'.", option.TransformerName, selectedServiceName)
 			found = true
 			break
@@ -117,10 +145,37 @@ func Transform(
 		}
 	}
 
+	if common.PreviewTransformerDiffs {
+		if err := transformer.StartDiffPreview(outputFSPath); err != nil {
+			return fmt.Errorf("failed to start previewing the changes of the transformers. Error: %w", err)
+		}
+		defer transformer.StopDiffPreview()
+	}
+
 	// transform the selected services using the selected transformation options
-	if err := transformer.Transform(selectedTransformationOptions, plan.Spec.SourceDir, outputFSPath, maxIterations); err != nil {
+	if incremental {
+		if err := transformIncrementally(plan.Spec.SourceDir, outputFSPath, selectedTransformationOptions, func(stagingPath string, planArtifacts []plantypes.PlanArtifact) error {
+			if err := transformer.Transform(ctx, planArtifacts, plan.Spec.SourceDir, stagingPath, maxIterations); err != nil {
+				return fmt.Errorf("failed to transform using the plan. Error: %w", err)
+			}
+			return nil
+		}); err != nil {
+			return fmt.Errorf("failed to do an incremental transformation. Error: %w", err)
+		}
+	} else if err := transformer.Transform(ctx, selectedTransformationOptions, plan.Spec.SourceDir, outputFSPath, maxIterations); err != nil {
 		return fmt.Errorf("failed to transform using the plan. Error: %w", err)
 	}
+	if common.DumpIRPath != "" {
+		if err := transformer.WriteLoadedIR(common.DumpIRPath); err != nil {
+			return fmt.Errorf("failed to write the IR to the path '%s' . Error: %w", common.DumpIRPath, err)
+		}
+		logrus.Infof("The IR loaded from the source artifacts was written to the path '%s'", common.DumpIRPath)
+	}
+	migrationReport := report.Get(plan.Spec.SourceDir, outputFSPath)
+	migrationReport.Spec.Move2KubeVersion = info.GetVersion()
+	if err := report.Write(migrationReport, outputFSPath); err != nil {
+		logrus.Warnf("failed to write the migration report. Error: %q", err)
+	}
 
 	if vcs.IsRemotePath(outputPath) {
 		if err := vcs.PushVCSRepo(outputPath, common.RemoteOutputsFolder); err != nil {
@@ -0,0 +1,127 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package lib
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/assets"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/transformer"
+	"gopkg.in/yaml.v3"
+)
+
+func readOutputTree(t *testing.T, outputPath string) map[string]string {
+	files := map[string]string{}
+	if err := filepath.WalkDir(outputPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		relPath, err := filepath.Rel(outputPath, path)
+		if err != nil {
+			return err
+		}
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		files[relPath] = string(contents)
+		return nil
+	}); err != nil {
+		t.Fatalf("failed to read the output directory '%s' . Error: %q", outputPath, err)
+	}
+	return files
+}
+
+func TestTransformIsReproducible(t *testing.T) {
+	assetsFilePermissions := map[string]int{}
+	if err := yaml.Unmarshal([]byte(assets.AssetFilePermissions), &assetsFilePermissions); err != nil {
+		t.Fatalf("failed to unmarshal the assets permissions file as YAML. Error: %q", err)
+	}
+	oldTempPath, oldAssetsPath, oldRemoteTempPath, oldIgnoreEnvironment := common.TempPath, common.AssetsPath, common.RemoteTempPath, common.IgnoreEnvironment
+	defer func() {
+		common.TempPath, common.AssetsPath, common.RemoteTempPath, common.IgnoreEnvironment = oldTempPath, oldAssetsPath, oldRemoteTempPath, oldIgnoreEnvironment
+	}()
+	assetsPath, tempPath, remoteTempPath, err := common.CreateAssetsData(assets.AssetsDir, assetsFilePermissions)
+	if err != nil {
+		t.Fatalf("failed to create the assets directory. Error: %q", err)
+	}
+	defer os.RemoveAll(tempPath)
+	defer os.RemoveAll(remoteTempPath)
+	common.TempPath, common.AssetsPath, common.RemoteTempPath = tempPath, assetsPath, remoteTempPath
+	common.IgnoreEnvironment = true
+	defer qaengine.ResetEngines()
+	defer transformer.Reset()
+
+	sourcePath, err := filepath.Abs(filepath.Join("..", "samples", "docker-compose"))
+	if err != nil {
+		t.Fatalf("failed to make the source path absolute. Error: %q", err)
+	}
+	// the transformation writes the graph to the working directory
+	workingDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get the working directory. Error: %q", err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatalf("failed to change the working directory. Error: %q", err)
+	}
+	defer os.Chdir(workingDir)
+	// both runs write to the same output path, so that the paths inside the output are identical
+	outputPath := filepath.Join(t.TempDir(), "myproject")
+	runPlanAndTransform := func() map[string]string {
+		ctx := context.Background()
+		transformer.Reset()
+		qaengine.ResetEngines()
+		if err := os.MkdirAll(outputPath, common.DefaultDirectoryPermission); err != nil {
+			t.Fatalf("failed to create the output directory. Error: %q", err)
+		}
+		qaengine.StartEngine(true, 0, true)
+		qaengine.SetupConfigFile(filepath.Join(outputPath, common.ConfigFile), nil, nil, nil, false)
+		qaengine.SetupWriteCacheFile(filepath.Join(outputPath, common.QACacheFile), false)
+		plan, err := CreatePlan(ctx, sourcePath, outputPath, "", "", "myproject")
+		if err != nil {
+			t.Fatalf("failed to create the plan. Error: %q", err)
+		}
+		if err := Transform(ctx, plan, false, outputPath, "", -1); err != nil {
+			t.Fatalf("failed to transform. Error: %q", err)
+		}
+		if err := qaengine.WriteStoresToDisk(); err != nil {
+			t.Fatalf("failed to write the config and the qa cache. Error: %q", err)
+		}
+		files := readOutputTree(t, outputPath)
+		if err := os.RemoveAll(outputPath); err != nil {
+			t.Fatalf("failed to remove the output directory. Error: %q", err)
+		}
+		return files
+	}
+	first := runPlanAndTransform()
+	second := runPlanAndTransform()
+	for _, file := range []string{common.ConfigFile, common.QACacheFile} {
+		if _, ok := first[file]; !ok {
+			t.Fatalf("expected the file '%s' in the output. Actual: %d files", file, len(first))
+		}
+	}
+	if !cmp.Equal(first, second) {
+		t.Fatalf("the outputs of two runs on the same source differ. Difference:\n%s", cmp.Diff(first, second))
+	}
+}
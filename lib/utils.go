@@ -17,11 +17,16 @@
 package lib
 
 import (
+	"context"
 	"fmt"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 
 	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/common/download"
+	"github.com/konveyor/move2kube/common/oci"
 	"github.com/konveyor/move2kube/common/vcs"
 	"github.com/konveyor/move2kube/filesystem"
 	"github.com/sirupsen/logrus"
@@ -31,10 +36,10 @@ import (
 const TransformerTypeMeta = "Transformer"
 
 // CheckAndCopyCustomizations checks if the customizations path is an existing directory and copies to assets
-func CheckAndCopyCustomizations(customizationsPath string) error {
-	remoteCustomizationsPath, err := vcs.GetClonedPath(customizationsPath, common.RemoteCustomizationsFolder, true)
+func CheckAndCopyCustomizations(ctx context.Context, customizationsPath string) error {
+	remoteCustomizationsPath, err := getRemoteCustomizationsPath(ctx, customizationsPath)
 	if err != nil {
-		return fmt.Errorf("failed to clone the repo. Error: %w", err)
+		return err
 	}
 	customizationsFSPath := customizationsPath
 	if remoteCustomizationsPath != "" {
@@ -75,6 +80,63 @@ func CheckAndCopyCustomizations(customizationsPath string) error {
 	return nil
 }
 
+// getRemoteCustomizationsPath clones the git repo, pulls the OCI artifact or downloads and extracts the archive containing the customizations.
+// It returns an empty string if the customizations path is not a remote path.
+func getRemoteCustomizationsPath(ctx context.Context, customizationsPath string) (string, error) {
+	if oci.IsRemotePath(customizationsPath) {
+		customizationsDir, err := oci.GetPulledPathWithContext(ctx, customizationsPath, common.RemoteCustomizationsFolder)
+		if err != nil {
+			return "", fmt.Errorf("failed to pull the customizations from '%s' . Error: %w", customizationsPath, err)
+		}
+		return getSingleTopLevelDir(customizationsDir)
+	}
+	if !download.IsRemotePath(customizationsPath) {
+		remoteCustomizationsPath, err := vcs.GetClonedPathWithContext(ctx, customizationsPath, common.RemoteCustomizationsFolder, true)
+		if err != nil {
+			return "", fmt.Errorf("failed to clone the repo. Error: %w", err)
+		}
+		return remoteCustomizationsPath, nil
+	}
+	tempPath, err := filepath.Abs(common.RemoteTempPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to make the temp path '%s' absolute. Error: %w", common.RemoteTempPath, err)
+	}
+	archiveURL, err := url.Parse(customizationsPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse the customizations url '%s' . Error: %w", customizationsPath, err)
+	}
+	archiveFilename := path.Base(archiveURL.Path)
+	if err := os.MkdirAll(tempPath, common.DefaultDirectoryPermission); err != nil {
+		return "", fmt.Errorf("failed to create the temp directory at path '%s' . Error: %w", tempPath, err)
+	}
+	archivePath := filepath.Join(tempPath, common.RemoteCustomizationsFolder+"-"+archiveFilename)
+	content := download.HTTPContent{}
+	if _, err := content.Download(ctx, download.DownloadOptions{ContentURL: customizationsPath, DownloadDestinationPath: archivePath, Overwrite: true}); err != nil {
+		return "", fmt.Errorf("failed to download the customizations archive from '%s' . Error: %w", customizationsPath, err)
+	}
+	customizationsDir := filepath.Join(tempPath, common.RemoteCustomizationsFolder)
+	if err := os.RemoveAll(customizationsDir); err != nil {
+		return "", fmt.Errorf("failed to remove the directory at path '%s' . Error: %w", customizationsDir, err)
+	}
+	if err := common.ExtractArchive(archivePath, archiveFilename, customizationsDir); err != nil {
+		return "", fmt.Errorf("failed to extract the customizations archive downloaded from '%s' . Error: %w", customizationsPath, err)
+	}
+	return getSingleTopLevelDir(customizationsDir)
+}
+
+// getSingleTopLevelDir returns the only sub directory, if the directory contains nothing else.
+// Archives of git repos and directories pushed as OCI artifacts usually have all the files inside a single top level directory.
+func getSingleTopLevelDir(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the directory at path '%s' . Error: %w", dir, err)
+	}
+	if len(entries) == 1 && entries[0].IsDir() {
+		return filepath.Join(dir, entries[0].Name()), nil
+	}
+	return dir, nil
+}
+
 // CopyCustomizationsAssetsData copies an customizations to the assets directory
 func CopyCustomizationsAssetsData(customizationsPath string) (err error) {
 	if customizationsPath == "" {
@@ -17,10 +17,20 @@
 package lib
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
 	"errors"
+	"fmt"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/konveyor/move2kube/common"
@@ -76,17 +86,17 @@ func TestLibUtils(t *testing.T) {
 			t.Fatalf("failed to create customizations dir: %v", err)
 		}
 
-		err = CheckAndCopyCustomizations("")
+		err = CheckAndCopyCustomizations(context.Background(), "")
 		if err != nil {
 			t.Errorf("failed to check and copy customizations. Error : %v", err)
 		}
 
-		err = CheckAndCopyCustomizations("invalid")
+		err = CheckAndCopyCustomizations(context.Background(), "invalid")
 		if !errors.Is(err, os.ErrNotExist) {
 			t.Errorf("Expected error %v, but got: %v", os.ErrNotExist, err)
 		}
 
-		err = CheckAndCopyCustomizations(customizationsPath)
+		err = CheckAndCopyCustomizations(context.Background(), customizationsPath)
 		if err != nil {
 			t.Errorf("failed to check and copy customizations. Error : %v", err)
 		}
@@ -101,3 +111,69 @@ func TestLibUtils(t *testing.T) {
 	})
 
 }
+
+func TestGetRemoteCustomizationsPath(t *testing.T) {
+	var archive bytes.Buffer
+	gzipWriter := gzip.NewWriter(&archive)
+	tarWriter := tar.NewWriter(gzipWriter)
+	contents := []byte("kind: Transformer\n")
+	if err := tarWriter.WriteHeader(&tar.Header{Name: "customizations-main/transformer.yaml", Mode: 0644, Size: int64(len(contents)), Typeflag: tar.TypeReg}); err != nil {
+		t.Fatalf("failed to write the tar header. Error: %q", err)
+	}
+	if _, err := tarWriter.Write(contents); err != nil {
+		t.Fatalf("failed to write the tar entry. Error: %q", err)
+	}
+	tarWriter.Close()
+	gzipWriter.Close()
+	digest := fmt.Sprintf("%x", sha256.Sum256(archive.Bytes()))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if username, password, ok := r.BasicAuth(); !ok || username != "user" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write(archive.Bytes())
+	}))
+	defer server.Close()
+	serverURL, _ := url.Parse(server.URL)
+	tmpDir := t.TempDir()
+	netrcPath := filepath.Join(tmpDir, ".netrc")
+	if err := os.WriteFile(netrcPath, []byte("machine "+serverURL.Hostname()+"\n  login user\n  password secret\n"), 0600); err != nil {
+		t.Fatalf("failed to write the netrc file. Error: %q", err)
+	}
+	t.Setenv("NETRC", netrcPath)
+	t.Setenv("M2K_HTTP_TOKEN", "")
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get the working directory. Error: %q", err)
+	}
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("failed to change the working directory. Error: %q", err)
+	}
+	defer os.Chdir(wd)
+
+	t.Run("archive with the correct digest", func(t *testing.T) {
+		customizationsPath, err := getRemoteCustomizationsPath(context.Background(), server.URL+"/customizations.tar.gz#sha256="+digest)
+		if err != nil {
+			t.Fatalf("failed to get the customizations. Error: %q", err)
+		}
+		data, err := os.ReadFile(filepath.Join(customizationsPath, "transformer.yaml"))
+		if err != nil {
+			t.Fatalf("failed to read the extracted customizations. Error: %q", err)
+		}
+		if string(data) != string(contents) {
+			t.Fatalf("wrong contents. Actual: %q", data)
+		}
+	})
+	t.Run("archive with the wrong digest", func(t *testing.T) {
+		if _, err := getRemoteCustomizationsPath(context.Background(), server.URL+"/customizations.tar.gz#sha256="+strings.Repeat("0", 64)); err == nil {
+			t.Fatalf("expected the digest mismatch to fail")
+		}
+	})
+	t.Run("archive without credentials", func(t *testing.T) {
+		t.Setenv("NETRC", filepath.Join(tmpDir, "missing"))
+		if _, err := getRemoteCustomizationsPath(context.Background(), server.URL+"/customizations.tar.gz"); err == nil {
+			t.Fatalf("expected the download without credentials to fail")
+		}
+	})
+}
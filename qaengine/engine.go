@@ -19,9 +19,11 @@ package qaengine
 import (
 	"fmt"
 	"path/filepath"
+	"sync"
 
 	"github.com/konveyor/move2kube/common"
 	"github.com/konveyor/move2kube/common/download"
+	"github.com/konveyor/move2kube/common/report"
 	qatypes "github.com/konveyor/move2kube/types/qaengine"
 	"github.com/sirupsen/logrus"
 )
@@ -33,12 +35,28 @@ type Engine interface {
 	FetchAnswer(prob qatypes.Problem) (ans qatypes.Problem, err error)
 }
 
+// QuestionHook is called with the problem and its answer every time a question is answered
+type QuestionHook func(prob qatypes.Problem)
+
 var (
 	engines       []Engine
 	stores        []qatypes.Store
 	defaultEngine = NewDefaultEngine()
+	questionHooks []QuestionHook
+	// fetchMutex makes sure that questions asked by transformers running in parallel are answered one at a time
+	fetchMutex sync.Mutex
 )
 
+// AddQuestionHook registers a hook that is called every time a question is answered
+func AddQuestionHook(hook QuestionHook) {
+	questionHooks = append(questionHooks, hook)
+}
+
+// ResetQuestionHooks removes all the registered question hooks
+func ResetQuestionHooks() {
+	questionHooks = nil
+}
+
 // StartEngine starts the QA Engines
 func StartEngine(qaskip bool, qaport int, qadisablecli bool) {
 	var e Engine
@@ -61,6 +79,17 @@ func AddEngine(e Engine) {
 	}
 }
 
+// ResetEngines removes all the engines and stores
+func ResetEngines() {
+	engines = nil
+	stores = nil
+}
+
+// IsInteractive returns true if the unanswered questions are asked to the user
+func IsInteractive() bool {
+	return len(engines) != 0 && engines[len(engines)-1].IsInteractiveEngine()
+}
+
 // AddEngineHighestPriority adds an engine to the list and sets it at highest priority
 func AddEngineHighestPriority(e Engine) error {
 	if err := e.StartEngine(); err != nil {
@@ -122,6 +151,8 @@ func SetupConfigFile(writeConfigFile string, configStrings, configFiles, presets
 func FetchAnswer(prob qatypes.Problem) (qatypes.Problem, error) {
 	logrus.Trace("FetchAnswer start")
 	defer logrus.Trace("FetchAnswer end")
+	fetchMutex.Lock()
+	defer fetchMutex.Unlock()
 	logrus.Debugf("Fetching answer for the problem: %#v", prob)
 	if prob.Answer != nil {
 		logrus.Debugf("Problem already solved.")
@@ -173,6 +204,10 @@ func FetchAnswer(prob qatypes.Problem) (qatypes.Problem, error) {
 	for _, store := range stores {
 		store.AddSolution(prob)
 	}
+	report.AddQAAnswer(prob.ID, prob.Desc, prob.Answer, prob.Type == qatypes.PasswordSolutionFormType)
+	for _, hook := range questionHooks {
+		hook(prob)
+	}
 	return prob, err
 }
 
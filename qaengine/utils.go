@@ -18,20 +18,15 @@ package qaengine
 
 import (
 	"fmt"
-
-	tsize "github.com/kopoli/go-terminal-size"
 )
 
+// defaultTerminalWidth is the width used when the width of the terminal window is not known
+// TODO: is 100 a good default for terminal width?
+const defaultTerminalWidth = 100
+
 // AddRightAlignedString adds a new string to the right of the original, with max width the size of the current
 // terminal window
 func AddRightAlignedString(original, addition string) string {
-	termSize, err := tsize.GetSize()
-	if err != nil {
-		termSize = tsize.Size{
-			Height: 100, // the height here doesn't matter
-			Width:  100, // TODO: is 100 a good default for terminal width?
-		}
-	}
-	width := termSize.Width - len(original)
+	width := getTerminalWidth() - len(original)
 	return fmt.Sprintf("%s%*s", original, width, addition)
 }
@@ -0,0 +1,238 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+
+	"github.com/konveyor/move2kube/common"
+	qatypes "github.com/konveyor/move2kube/types/qaengine"
+	"github.com/konveyor/move2kube/types/server/servergrpc"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cast"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const artifactChunkSize = 64 * 1024
+
+// grpcServer implements the move2kube gRPC service on top of the same jobs as the REST API
+type grpcServer struct {
+	servergrpc.UnimplementedMove2KubeServer
+	jobs *jobStore
+}
+
+// NewGRPCServer returns a gRPC server for the plan and transform operations of the server
+func (s *Server) NewGRPCServer() *grpc.Server {
+	grpcSrv := grpc.NewServer(grpc.MaxRecvMsgSize(maxUploadSizeMiB * 1024 * 1024))
+	servergrpc.RegisterMove2KubeServer(grpcSrv, &grpcServer{jobs: s.jobs})
+	return grpcSrv
+}
+
+// ServeGRPC starts the gRPC server on the given address and port
+func (s *Server) ServeGRPC(host string, port int) error {
+	addr := net.JoinHostPort(host, cast.ToString(port))
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("unable to listen on port %d . Error: %w", port, err)
+	}
+	logrus.Infof("Starting the move2kube gRPC server on: %s", addr)
+	return s.NewGRPCServer().Serve(listener)
+}
+
+// CreatePlan uploads the source archive and returns the job once the planning has finished
+func (g *grpcServer) CreatePlan(ctx context.Context, req *servergrpc.CreatePlanRequest) (*servergrpc.Job, error) {
+	job, err := g.jobs.createFromArchive(req.GetName(), req.GetArchiveFilename(), bytes.NewReader(req.GetSourceArchive()))
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	if err := g.jobs.plan(job.ID); err != nil {
+		return nil, toGRPCError(err)
+	}
+	job, err = g.jobs.wait(ctx, job.ID)
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return toGRPCJob(job), nil
+}
+
+// GetJob returns the status of a job
+func (g *grpcServer) GetJob(ctx context.Context, req *servergrpc.JobRequest) (*servergrpc.Job, error) {
+	job, ok := g.jobs.get(req.GetJobId())
+	if !ok {
+		return nil, toGRPCError(errJobNotFound)
+	}
+	return toGRPCJob(job), nil
+}
+
+// StartTransform starts transforming a planned job
+func (g *grpcServer) StartTransform(ctx context.Context, req *servergrpc.StartTransformRequest) (*servergrpc.Job, error) {
+	options := TransformOptions{
+		QASkip:              req.GetQaSkip(),
+		Configs:             req.GetConfigs(),
+		TransformerSelector: req.GetTransformerSelector(),
+	}
+	if req.GetMaxIterations() > 0 {
+		maxIterations := int(req.GetMaxIterations())
+		options.MaxIterations = &maxIterations
+	}
+	if err := g.jobs.transform(req.GetJobId(), options); err != nil {
+		return nil, toGRPCError(err)
+	}
+	job, _ := g.jobs.get(req.GetJobId())
+	return toGRPCJob(job), nil
+}
+
+// StreamQuestions sends the questions asked during the transformation and receives their solutions.
+// An invalid solution ends the stream with InvalidArgument, since the client would otherwise wait forever for the next question.
+// The question is sent again on the next stream.
+func (g *grpcServer) StreamQuestions(stream servergrpc.Move2Kube_StreamQuestionsServer) error {
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	jobID := first.GetJobId()
+	qa, err := g.jobs.getQAEngine(jobID)
+	if err != nil {
+		return toGRPCError(err)
+	}
+	ctx := stream.Context()
+	solutions := make(chan *servergrpc.Solution)
+	recvErr := make(chan error, 1)
+	go func() {
+		for {
+			solution, err := stream.Recv()
+			if err != nil {
+				recvErr <- err
+				return
+			}
+			select {
+			case solutions <- solution:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	problemNumber := 0
+	for {
+		prob, newProblemNumber, ok := qa.waitForProblem(ctx, problemNumber)
+		if !ok {
+			return nil
+		}
+		problemNumber = newProblemNumber
+		defaults, err := qatypes.InterfaceToArray(prob.Default, prob.Type)
+		if err != nil {
+			logrus.Debugf("failed to convert the default answer of the problem '%s' . Error: %q", prob.ID, err)
+		}
+		if err := stream.Send(&servergrpc.Question{
+			JobId:       jobID,
+			Id:          prob.ID,
+			Type:        string(prob.Type),
+			Description: prob.Desc,
+			Hints:       prob.Hints,
+			Options:     prob.Options,
+			Default:     defaults,
+		}); err != nil {
+			return err
+		}
+		select {
+		case solution := <-solutions:
+			answer, err := qatypes.ArrayToInterface(solution.GetAnswer(), prob.Type)
+			if err == nil {
+				err = qa.setAnswer(solution.GetQuestionId(), answer)
+			}
+			if err != nil {
+				return status.Errorf(codes.InvalidArgument, "failed to set the answer for the question '%s' . Error: %v", prob.ID, err)
+			}
+		case err := <-recvErr:
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// FetchArtifacts streams the transformed output as a zip archive
+func (g *grpcServer) FetchArtifacts(req *servergrpc.JobRequest, stream servergrpc.Move2Kube_FetchArtifactsServer) error {
+	job, ok := g.jobs.get(req.GetJobId())
+	if !ok {
+		return toGRPCError(errJobNotFound)
+	}
+	if job.Status != JobDone {
+		return status.Errorf(codes.FailedPrecondition, "the output of the job '%s' is not ready. Status: '%s'", job.ID, job.Status)
+	}
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(common.WriteZip(filepath.Join(job.dir, outputDirName), pw))
+	}()
+	defer pr.Close()
+	buf := make([]byte, artifactChunkSize)
+	for {
+		n, err := pr.Read(buf)
+		if n > 0 {
+			if err := stream.Send(&servergrpc.ArtifactChunk{Data: append([]byte{}, buf[:n]...)}); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return status.Errorf(codes.Internal, "failed to archive the output of the job '%s' . Error: %v", job.ID, err)
+		}
+	}
+}
+
+func toGRPCJob(job Job) *servergrpc.Job {
+	grpcJob := &servergrpc.Job{Id: job.ID, Name: job.Name, Status: string(job.Status), Error: job.Error}
+	if job.plan != nil {
+		planBytes, err := os.ReadFile(filepath.Join(job.dir, common.DefaultPlanFile))
+		if err != nil {
+			logrus.Errorf("failed to read the plan of the job '%s' . Error: %q", job.ID, err)
+		} else {
+			grpcJob.Plan = string(planBytes)
+		}
+	}
+	return grpcJob
+}
+
+func toGRPCError(err error) error {
+	switch {
+	case errors.Is(err, errJobNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, errJobConflict):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, errInvalidSource), errors.Is(err, errInvalidConfig):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, errQueueFull):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	}
+	return status.Error(codes.Internal, err.Error())
+}
@@ -0,0 +1,168 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"testing"
+
+	qatypes "github.com/konveyor/move2kube/types/qaengine"
+	"github.com/konveyor/move2kube/types/server/servergrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func newTestGRPCClient(t *testing.T, s *Server) servergrpc.Move2KubeClient {
+	listener := bufconn.Listen(1024 * 1024)
+	grpcSrv := s.NewGRPCServer()
+	go grpcSrv.Serve(listener)
+	t.Cleanup(grpcSrv.Stop)
+	conn, err := grpc.Dial(
+		"bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("failed to dial the gRPC server. Error: %q", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return servergrpc.NewMove2KubeClient(conn)
+}
+
+func TestGRPCServer(t *testing.T) {
+	s, err := NewServer(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create the server. Error: %q", err)
+	}
+	client := newTestGRPCClient(t, s)
+	ctx := context.Background()
+	t.Run("create plan with an invalid archive", func(t *testing.T) {
+		_, err := client.CreatePlan(ctx, &servergrpc.CreatePlanRequest{Name: "myproject", ArchiveFilename: "src.rar", SourceArchive: []byte("foo")})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Fatalf("expected the code %s, actual error: %v", codes.InvalidArgument, err)
+		}
+	})
+	t.Run("get an unknown job", func(t *testing.T) {
+		_, err := client.GetJob(ctx, &servergrpc.JobRequest{JobId: "foo"})
+		if status.Code(err) != codes.NotFound {
+			t.Fatalf("expected the code %s, actual error: %v", codes.NotFound, err)
+		}
+	})
+	t.Run("transform and fetch artifacts of an unplanned job", func(t *testing.T) {
+		job, err := s.jobs.createFromArchive("myproject", "src.zip", bytes.NewReader(createZip(t, map[string]string{"main.py": ""})))
+		if err != nil {
+			t.Fatalf("failed to create the job. Error: %q", err)
+		}
+		if _, err := client.StartTransform(ctx, &servergrpc.StartTransformRequest{JobId: job.ID}); status.Code(err) != codes.FailedPrecondition {
+			t.Fatalf("expected the code %s, actual error: %v", codes.FailedPrecondition, err)
+		}
+		stream, err := client.FetchArtifacts(ctx, &servergrpc.JobRequest{JobId: job.ID})
+		if err != nil {
+			t.Fatalf("failed to start the stream. Error: %q", err)
+		}
+		if _, err := stream.Recv(); status.Code(err) != codes.FailedPrecondition {
+			t.Fatalf("expected the code %s, actual error: %v", codes.FailedPrecondition, err)
+		}
+	})
+	t.Run("an invalid solution ends the question stream", func(t *testing.T) {
+		job, err := s.jobs.createFromArchive("myproject", "src.zip", bytes.NewReader(createZip(t, map[string]string{"main.py": ""})))
+		if err != nil {
+			t.Fatalf("failed to create the job. Error: %q", err)
+		}
+		qaCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		qa := newJobQAEngine(qaCtx)
+		s.jobs.mutex.Lock()
+		s.jobs.jobs[job.ID].qa = qa
+		s.jobs.mutex.Unlock()
+		prob, err := qatypes.NewConfirmProblem("move2kube.test.confirm", "Are you sure?", nil, false, nil)
+		if err != nil {
+			t.Fatalf("failed to create the problem. Error: %q", err)
+		}
+		answers := make(chan qatypes.Problem)
+		go func() {
+			answered, _ := qa.FetchAnswer(prob)
+			answers <- answered
+		}()
+		startStream := func() servergrpc.Move2Kube_StreamQuestionsClient {
+			stream, err := client.StreamQuestions(ctx)
+			if err != nil {
+				t.Fatalf("failed to start the stream. Error: %q", err)
+			}
+			if err := stream.Send(&servergrpc.Solution{JobId: job.ID}); err != nil {
+				t.Fatalf("failed to send the job id. Error: %q", err)
+			}
+			question, err := stream.Recv()
+			if err != nil || question.GetId() != prob.ID {
+				t.Fatalf("expected the question '%s' . Actual: %+v Error: %v", prob.ID, question, err)
+			}
+			return stream
+		}
+		stream := startStream()
+		if err := stream.Send(&servergrpc.Solution{JobId: job.ID, QuestionId: "some.other.id", Answer: []string{"true"}}); err != nil {
+			t.Fatalf("failed to send the solution. Error: %q", err)
+		}
+		if _, err := stream.Recv(); status.Code(err) != codes.InvalidArgument {
+			t.Fatalf("expected the code %s, actual error: %v", codes.InvalidArgument, err)
+		}
+		// the question is asked again on a new stream
+		stream = startStream()
+		if err := stream.Send(&servergrpc.Solution{JobId: job.ID, QuestionId: prob.ID, Answer: []string{"true"}}); err != nil {
+			t.Fatalf("failed to send the solution. Error: %q", err)
+		}
+		if answered := <-answers; answered.Answer != true {
+			t.Fatalf("expected the answer to be true. Actual: %+v", answered.Answer)
+		}
+	})
+}
+
+func TestJobQAEngine(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	qa := newJobQAEngine(ctx)
+	prob, err := qatypes.NewConfirmProblem("move2kube.test.confirm", "Are you sure?", nil, false, nil)
+	if err != nil {
+		t.Fatalf("failed to create the problem. Error: %q", err)
+	}
+	answers := make(chan qatypes.Problem)
+	go func() {
+		answered, _ := qa.FetchAnswer(prob)
+		answers <- answered
+	}()
+	current, problemNumber, ok := qa.waitForProblem(ctx, 0)
+	if !ok || current.ID != prob.ID || problemNumber != 1 {
+		t.Fatalf("expected the problem '%s' to be waiting. Actual: %+v %d %t", prob.ID, current, problemNumber, ok)
+	}
+	if err := qa.setAnswer("some.other.id", true); err == nil {
+		t.Fatalf("expected an error when answering a different problem")
+	}
+	if err := qa.setAnswer(prob.ID, true); err != nil {
+		t.Fatalf("failed to set the answer. Error: %q", err)
+	}
+	if answered := <-answers; answered.Answer != true {
+		t.Fatalf("expected the answer to be true. Actual: %+v", answered.Answer)
+	}
+	cancel()
+	if _, _, ok := qa.waitForProblem(context.Background(), problemNumber); ok {
+		t.Fatalf("expected no more problems after the job is done")
+	}
+}
@@ -0,0 +1,416 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/lib"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/transformer"
+	plantypes "github.com/konveyor/move2kube/types/plan"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// JobStatus is the status of a job
+type JobStatus string
+
+const (
+	// JobCreated is the status of a job whose source has been uploaded
+	JobCreated JobStatus = "created"
+	// JobQueued is the status of a job that is waiting for the running job to finish
+	JobQueued JobStatus = "queued"
+	// JobPlanning is the status of a job that is being planned
+	JobPlanning JobStatus = "planning"
+	// JobPlanned is the status of a job that has a plan
+	JobPlanned JobStatus = "planned"
+	// JobTransforming is the status of a job that is being transformed
+	JobTransforming JobStatus = "transforming"
+	// JobDone is the status of a job whose output is ready for download
+	JobDone JobStatus = "done"
+	// JobFailed is the status of a job that failed
+	JobFailed JobStatus = "failed"
+)
+
+const (
+	sourceDirName = "source"
+	outputDirName = "output"
+	// archiveFileName is the name of the uploaded archive, which must not collide with the other files of the job
+	archiveFileName = "source.upload"
+	// configsDirName is the directory containing the uploaded config files of the job
+	configsDirName = "configs"
+	// maxQueuedJobs is the number of jobs that can wait to run before new ones are rejected
+	maxQueuedJobs = 100
+)
+
+// TransformOptions contains the options for transforming a job
+type TransformOptions struct {
+	// QASkip uses the default answers instead of waiting for answers through the API
+	QASkip bool `json:"qaSkip,omitempty"`
+	// Configs are the contents of the config files, like m2kconfig.yaml.
+	// Paths and urls are not accepted, since the server would read them on behalf of the caller.
+	Configs             []string `json:"configs,omitempty"`
+	TransformerSelector string   `json:"transformerSelector,omitempty"`
+	MaxIterations       *int     `json:"maxIterations,omitempty"`
+}
+
+// Job is a planning and transformation job
+type Job struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Status    JobStatus `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	dir    string
+	plan   *plantypes.Plan
+	qa     *jobQAEngine
+	cancel context.CancelFunc
+	done   chan struct{}
+	// running is true while the plan or transform of the job runs
+	running bool
+}
+
+// jobStore keeps track of the jobs and runs them one at a time, since the transformers and QA engine are global
+type jobStore struct {
+	mutex   sync.Mutex
+	workDir string
+	jobs    map[string]*Job
+	queue   chan func()
+}
+
+func newJobStore(workDir string) *jobStore {
+	store := &jobStore{workDir: workDir, jobs: map[string]*Job{}, queue: make(chan func(), maxQueuedJobs)}
+	go func() {
+		for run := range store.queue {
+			run()
+		}
+	}()
+	return store
+}
+
+func (s *jobStore) create(name string) (*Job, error) {
+	id := uuid.New().String()
+	dir := filepath.Join(s.workDir, id)
+	if err := os.MkdirAll(filepath.Join(dir, sourceDirName), common.DefaultDirectoryPermission); err != nil {
+		return nil, fmt.Errorf("failed to create the directory for the job '%s' . Error: %w", id, err)
+	}
+	if name == "" {
+		name = common.DefaultProjectName
+	}
+	now := time.Now()
+	job := &Job{ID: id, Name: name, Status: JobCreated, CreatedAt: now, UpdatedAt: now, dir: dir}
+	s.mutex.Lock()
+	s.jobs[id] = job
+	s.mutex.Unlock()
+	return job, nil
+}
+
+// createFromArchive creates a job whose source is extracted from the given archive
+func (s *jobStore) createFromArchive(name, archiveFilename string, archive io.Reader) (Job, error) {
+	job, err := s.create(name)
+	if err != nil {
+		return Job{}, err
+	}
+	archivePath := filepath.Join(job.dir, archiveFileName)
+	if err := saveUpload(archive, archivePath); err != nil {
+		s.delete(job.ID)
+		return Job{}, err
+	}
+	defer os.Remove(archivePath)
+	if err := common.ExtractArchive(archivePath, archiveFilename, filepath.Join(job.dir, sourceDirName)); err != nil {
+		s.delete(job.ID)
+		return Job{}, fmt.Errorf("%w: failed to extract the source archive. Error: %v", errInvalidSource, err)
+	}
+	createdJob, _ := s.get(job.ID)
+	return createdJob, nil
+}
+
+// wait blocks until the last plan or transform started on the job finishes
+func (s *jobStore) wait(ctx context.Context, id string) (Job, error) {
+	s.mutex.Lock()
+	job, ok := s.jobs[id]
+	var done chan struct{}
+	if ok {
+		done = job.done
+	}
+	s.mutex.Unlock()
+	if !ok {
+		return Job{}, errJobNotFound
+	}
+	if done != nil {
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return Job{}, ctx.Err()
+		}
+	}
+	finishedJob, ok := s.get(id)
+	if !ok {
+		return Job{}, errJobNotFound
+	}
+	return finishedJob, nil
+}
+
+func (s *jobStore) get(id string) (Job, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	job, ok := s.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	return *job, true
+}
+
+func (s *jobStore) list() []Job {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	jobs := []Job{}
+	for _, job := range s.jobs {
+		jobs = append(jobs, *job)
+	}
+	return jobs
+}
+
+// delete cancels the job and removes it. A running job is waited for so that its directory is not removed while it is being used.
+func (s *jobStore) delete(id string) error {
+	s.mutex.Lock()
+	job, ok := s.jobs[id]
+	if !ok {
+		s.mutex.Unlock()
+		return fmt.Errorf("the job '%s' does not exist", id)
+	}
+	delete(s.jobs, id)
+	if job.cancel != nil {
+		job.cancel()
+	}
+	running, done := job.running, job.done
+	s.mutex.Unlock()
+	if running {
+		<-done
+	}
+	return os.RemoveAll(job.dir)
+}
+
+func (s *jobStore) setStatus(job *Job, status JobStatus, err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	job.Status = status
+	job.Error = ""
+	if err != nil {
+		job.Error = err.Error()
+	}
+	job.UpdatedAt = time.Now()
+}
+
+// startJob moves the job to the queued status and runs it once the previous jobs have finished
+// The optional setup function is called before the job is queued. The job is rejected if too many jobs are already queued.
+func (s *jobStore) startJob(id string, allowed []JobStatus, setup func(ctx context.Context, job *Job), run func(ctx context.Context, job *Job) (JobStatus, error)) error {
+	s.mutex.Lock()
+	job, ok := s.jobs[id]
+	if !ok {
+		s.mutex.Unlock()
+		return errJobNotFound
+	}
+	if !isStatusPresent(allowed, job.Status) {
+		status := job.Status
+		s.mutex.Unlock()
+		return fmt.Errorf("%w: the job '%s' has the status '%s'", errJobConflict, id, status)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	queuedRun := func() {
+		defer close(done)
+		defer cancel()
+		s.mutex.Lock()
+		if ctx.Err() != nil {
+			s.mutex.Unlock()
+			return
+		}
+		job.running = true
+		s.mutex.Unlock()
+		defer func() {
+			s.mutex.Lock()
+			job.running = false
+			s.mutex.Unlock()
+		}()
+		status, err := run(ctx, job)
+		if err != nil {
+			logrus.Errorf("the job '%s' failed. Error: %q", job.ID, err)
+		}
+		s.setStatus(job, status, err)
+	}
+	select {
+	case s.queue <- queuedRun:
+	default:
+		s.mutex.Unlock()
+		cancel()
+		return fmt.Errorf("%w: the job '%s' was not queued", errQueueFull, id)
+	}
+	job.cancel = cancel
+	job.done = done
+	job.Status = JobQueued
+	job.UpdatedAt = time.Now()
+	if setup != nil {
+		setup(ctx, job)
+	}
+	s.mutex.Unlock()
+	return nil
+}
+
+func (s *jobStore) plan(id string) error {
+	return s.startJob(id, []JobStatus{JobCreated, JobPlanned, JobDone, JobFailed}, nil, func(ctx context.Context, job *Job) (JobStatus, error) {
+		s.setStatus(job, JobPlanning, nil)
+		resetGlobalState()
+		qaengine.StartEngine(true, 0, true)
+		qaengine.SetupConfigFile("", nil, nil, nil, false)
+		p, err := lib.CreatePlan(ctx, filepath.Join(job.dir, sourceDirName), "", "", "", job.Name)
+		if err != nil {
+			return JobFailed, fmt.Errorf("failed to create the plan. Error: %w", err)
+		}
+		if err := plantypes.WritePlan(filepath.Join(job.dir, common.DefaultPlanFile), p); err != nil {
+			return JobFailed, fmt.Errorf("failed to write the plan. Error: %w", err)
+		}
+		s.mutex.Lock()
+		job.plan = &p
+		s.mutex.Unlock()
+		return JobPlanned, nil
+	})
+}
+
+func (s *jobStore) transform(id string, options TransformOptions) error {
+	if err := validateConfigs(options.Configs); err != nil {
+		return err
+	}
+	setup := func(ctx context.Context, job *Job) {
+		job.qa = nil
+		if !options.QASkip {
+			job.qa = newJobQAEngine(ctx)
+		}
+	}
+	return s.startJob(id, []JobStatus{JobPlanned, JobDone, JobFailed}, setup, func(ctx context.Context, job *Job) (JobStatus, error) {
+		s.mutex.Lock()
+		p := job.plan
+		qa := job.qa
+		s.mutex.Unlock()
+		defer func() {
+			s.mutex.Lock()
+			job.qa = nil
+			s.mutex.Unlock()
+		}()
+		if p == nil {
+			return JobFailed, fmt.Errorf("the job '%s' does not have a plan", job.ID)
+		}
+		s.setStatus(job, JobTransforming, nil)
+		resetGlobalState()
+		outputPath := filepath.Join(job.dir, outputDirName)
+		if err := os.RemoveAll(outputPath); err != nil {
+			return JobFailed, fmt.Errorf("failed to remove the old output directory. Error: %w", err)
+		}
+		if err := os.MkdirAll(outputPath, common.DefaultDirectoryPermission); err != nil {
+			return JobFailed, fmt.Errorf("failed to create the output directory. Error: %w", err)
+		}
+		if qa == nil {
+			qaengine.StartEngine(true, 0, true)
+		} else {
+			qaengine.AddEngine(qa)
+		}
+		configPaths, err := writeConfigs(filepath.Join(job.dir, configsDirName), options.Configs)
+		if err != nil {
+			return JobFailed, err
+		}
+		qaengine.SetupConfigFile(filepath.Join(job.dir, common.ConfigFile), nil, configPaths, nil, false)
+		maxIterations := -1
+		if options.MaxIterations != nil {
+			maxIterations = *options.MaxIterations
+		}
+		if err := lib.Transform(ctx, *p, true, outputPath, options.TransformerSelector, maxIterations); err != nil {
+			return JobFailed, fmt.Errorf("failed to transform. Error: %w", err)
+		}
+		return JobDone, nil
+	})
+}
+
+func (s *jobStore) getQAEngine(id string) (*jobQAEngine, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, errJobNotFound
+	}
+	if job.qa == nil {
+		return nil, fmt.Errorf("%w: the job '%s' is not waiting for any answers", errJobConflict, id)
+	}
+	return job.qa, nil
+}
+
+// validateConfigs returns an error if any of the uploaded configs is not a yaml map
+func validateConfigs(configs []string) error {
+	for i, config := range configs {
+		if err := yaml.Unmarshal([]byte(config), &map[string]interface{}{}); err != nil {
+			return fmt.Errorf("%w: the config at index %d is not the yaml contents of a config file. Error: %v", errInvalidConfig, i, err)
+		}
+	}
+	return nil
+}
+
+// writeConfigs writes the uploaded configs to the directory and returns the paths of the config files
+func writeConfigs(configsDir string, configs []string) ([]string, error) {
+	if err := os.RemoveAll(configsDir); err != nil {
+		return nil, fmt.Errorf("failed to remove the old configs directory. Error: %w", err)
+	}
+	if len(configs) == 0 {
+		return nil, nil
+	}
+	if err := os.MkdirAll(configsDir, common.DefaultDirectoryPermission); err != nil {
+		return nil, fmt.Errorf("failed to create the configs directory. Error: %w", err)
+	}
+	configPaths := []string{}
+	for i, config := range configs {
+		configPath := filepath.Join(configsDir, fmt.Sprintf("m2kconfig-%d.yaml", i))
+		if err := os.WriteFile(configPath, []byte(config), common.DefaultFilePermission); err != nil {
+			return nil, fmt.Errorf("failed to write the config at index %d . Error: %w", i, err)
+		}
+		configPaths = append(configPaths, configPath)
+	}
+	return configPaths, nil
+}
+
+// resetGlobalState clears the transformers and QA engines left behind by the previous job
+func resetGlobalState() {
+	transformer.Reset()
+	qaengine.ResetEngines()
+}
+
+func isStatusPresent(statuses []JobStatus, status JobStatus) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,128 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/konveyor/move2kube/qaengine"
+	qatypes "github.com/konveyor/move2kube/types/qaengine"
+	"github.com/sirupsen/logrus"
+)
+
+// jobQAEngine passes the questions asked during a job to the REST API and waits for the answers
+type jobQAEngine struct {
+	ctx            context.Context
+	mutex          sync.Mutex
+	currentProblem *qatypes.Problem
+	problemNumber  int
+	problemSignal  chan struct{}
+	answerChan     chan qatypes.Problem
+}
+
+func newJobQAEngine(ctx context.Context) *jobQAEngine {
+	return &jobQAEngine{ctx: ctx, problemSignal: make(chan struct{}), answerChan: make(chan qatypes.Problem, 1)}
+}
+
+// StartEngine starts the QA Engine
+func (*jobQAEngine) StartEngine() error {
+	return nil
+}
+
+// IsInteractiveEngine returns true if the engine interacts with the user
+func (*jobQAEngine) IsInteractiveEngine() bool {
+	return true
+}
+
+// FetchAnswer blocks until the problem is answered through the REST API.
+// If the job is cancelled, the default answer is used.
+func (e *jobQAEngine) FetchAnswer(prob qatypes.Problem) (qatypes.Problem, error) {
+	if prob.Answer != nil {
+		return prob, nil
+	}
+	e.mutex.Lock()
+	e.currentProblem = &prob
+	e.problemNumber++
+	close(e.problemSignal)
+	e.problemSignal = make(chan struct{})
+	e.mutex.Unlock()
+	defer func() {
+		e.mutex.Lock()
+		e.currentProblem = nil
+		e.mutex.Unlock()
+	}()
+	select {
+	case answered := <-e.answerChan:
+		return answered, nil
+	case <-e.ctx.Done():
+		logrus.Debugf("the job was stopped, using the default answer for the problem '%s'", prob.ID)
+		return qaengine.NewDefaultEngine().FetchAnswer(prob)
+	}
+}
+
+// getCurrentProblem returns the problem that is waiting for an answer, if there is one
+func (e *jobQAEngine) getCurrentProblem() (qatypes.Problem, bool) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if e.currentProblem == nil {
+		return qatypes.Problem{}, false
+	}
+	return *e.currentProblem, true
+}
+
+// waitForProblem blocks until a problem newer than the given problem number is waiting for an answer.
+// It returns false if the context or the job is done.
+func (e *jobQAEngine) waitForProblem(ctx context.Context, lastProblemNumber int) (qatypes.Problem, int, bool) {
+	for {
+		e.mutex.Lock()
+		if e.currentProblem != nil && e.problemNumber > lastProblemNumber {
+			prob, problemNumber := *e.currentProblem, e.problemNumber
+			e.mutex.Unlock()
+			return prob, problemNumber, true
+		}
+		signal := e.problemSignal
+		e.mutex.Unlock()
+		select {
+		case <-signal:
+		case <-ctx.Done():
+			return qatypes.Problem{}, lastProblemNumber, false
+		case <-e.ctx.Done():
+			return qatypes.Problem{}, lastProblemNumber, false
+		}
+	}
+}
+
+// setAnswer answers the problem that is waiting for an answer
+func (e *jobQAEngine) setAnswer(id string, answer interface{}) error {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if e.currentProblem == nil {
+		return fmt.Errorf("there is no question waiting for an answer")
+	}
+	if e.currentProblem.ID != id {
+		return fmt.Errorf("the solution's problem ID doesn't match the current problem. Expected: '%s' Actual '%s'", e.currentProblem.ID, id)
+	}
+	prob := *e.currentProblem
+	if err := prob.SetAnswer(answer, true); err != nil {
+		return fmt.Errorf("failed to set the given solution as the answer. Error: %w", err)
+	}
+	e.currentProblem = nil
+	e.answerChan <- prob
+	return nil
+}
@@ -0,0 +1,265 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/gorilla/mux"
+	"github.com/konveyor/move2kube/common"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cast"
+)
+
+const (
+	apiURLPrefix     = "/api/v1"
+	jobsURLPrefix    = apiURLPrefix + "/jobs"
+	jobURLPrefix     = jobsURLPrefix + "/{id}"
+	sourceFormField  = "source"
+	nameFormField    = "name"
+	maxUploadSizeMiB = 1024
+)
+
+var (
+	errJobNotFound   = errors.New("job not found")
+	errJobConflict   = errors.New("conflict")
+	errInvalidSource = errors.New("invalid source")
+	errInvalidConfig = errors.New("invalid config")
+	errQueueFull     = errors.New("too many jobs are waiting to run")
+)
+
+// Server serves the move2kube REST API
+type Server struct {
+	jobs *jobStore
+}
+
+// NewServer creates a new REST API server that stores the job data in the given directory
+func NewServer(workDir string) (*Server, error) {
+	workDir, err := filepath.Abs(workDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make the work directory path '%s' absolute. Error: %w", workDir, err)
+	}
+	if err := os.MkdirAll(workDir, common.DefaultDirectoryPermission); err != nil {
+		return nil, fmt.Errorf("failed to create the work directory '%s' . Error: %w", workDir, err)
+	}
+	return &Server{jobs: newJobStore(workDir)}, nil
+}
+
+// Handler returns the HTTP handler for the REST API
+func (s *Server) Handler() http.Handler {
+	r := mux.NewRouter()
+	r.HandleFunc(jobsURLPrefix, s.listJobsHandler).Methods("GET")
+	r.HandleFunc(jobsURLPrefix, s.createJobHandler).Methods("POST")
+	r.HandleFunc(jobURLPrefix, s.getJobHandler).Methods("GET")
+	r.HandleFunc(jobURLPrefix, s.deleteJobHandler).Methods("DELETE")
+	r.HandleFunc(jobURLPrefix+"/plan", s.startPlanHandler).Methods("POST")
+	r.HandleFunc(jobURLPrefix+"/plan", s.getPlanHandler).Methods("GET")
+	r.HandleFunc(jobURLPrefix+"/transform", s.startTransformHandler).Methods("POST")
+	r.HandleFunc(jobURLPrefix+"/problems/current", s.getQuestionHandler).Methods("GET")
+	r.HandleFunc(jobURLPrefix+"/problems/current/solution", s.postSolutionHandler).Methods("POST")
+	r.HandleFunc(jobURLPrefix+"/output", s.getOutputHandler).Methods("GET")
+	return r
+}
+
+// ListenAndServe starts the REST API server on the given address and port
+func (s *Server) ListenAndServe(host string, port int) error {
+	addr := net.JoinHostPort(host, cast.ToString(port))
+	logrus.Infof("Starting the move2kube API server on: %s", addr)
+	return http.ListenAndServe(addr, s.Handler())
+}
+
+func (s *Server) listJobsHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.jobs.list())
+}
+
+func (s *Server) createJobHandler(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadSizeMiB*1024*1024)
+	file, fileHeader, err := r.FormFile(sourceFormField)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("failed to read the '%s' field of the form. Error: %w", sourceFormField, err))
+		return
+	}
+	defer file.Close()
+	createdJob, err := s.jobs.createFromArchive(r.FormValue(nameFormField), fileHeader.Filename, file)
+	if err != nil {
+		if errors.Is(err, errInvalidSource) {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, createdJob)
+}
+
+func (s *Server) getJobHandler(w http.ResponseWriter, r *http.Request) {
+	job, ok := s.jobs.get(mux.Vars(r)["id"])
+	if !ok {
+		writeError(w, http.StatusNotFound, errJobNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
+
+func (s *Server) deleteJobHandler(w http.ResponseWriter, r *http.Request) {
+	if err := s.jobs.delete(mux.Vars(r)["id"]); err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) startPlanHandler(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	if err := s.jobs.plan(id); err != nil {
+		writeJobError(w, err)
+		return
+	}
+	job, _ := s.jobs.get(id)
+	writeJSON(w, http.StatusAccepted, job)
+}
+
+func (s *Server) getPlanHandler(w http.ResponseWriter, r *http.Request) {
+	job, ok := s.jobs.get(mux.Vars(r)["id"])
+	if !ok {
+		writeError(w, http.StatusNotFound, errJobNotFound)
+		return
+	}
+	if job.plan == nil {
+		writeError(w, http.StatusConflict, fmt.Errorf("the job '%s' does not have a plan yet", job.ID))
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-yaml")
+	http.ServeFile(w, r, filepath.Join(job.dir, common.DefaultPlanFile))
+}
+
+func (s *Server) startTransformHandler(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	options := TransformOptions{}
+	if err := json.NewDecoder(r.Body).Decode(&options); err != nil && err != io.EOF {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("failed to decode the request body as transform options json. Error: %w", err))
+		return
+	}
+	if err := s.jobs.transform(id, options); err != nil {
+		writeJobError(w, err)
+		return
+	}
+	job, _ := s.jobs.get(id)
+	writeJSON(w, http.StatusAccepted, job)
+}
+
+// getQuestionHandler returns the question the job is waiting on, or no content if there isn't one right now
+func (s *Server) getQuestionHandler(w http.ResponseWriter, r *http.Request) {
+	qa, err := s.jobs.getQAEngine(mux.Vars(r)["id"])
+	if err != nil {
+		writeJobError(w, err)
+		return
+	}
+	prob, ok := qa.getCurrentProblem()
+	if !ok {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeJSON(w, http.StatusOK, prob)
+}
+
+// postSolutionHandler accepts the solution for the current question
+func (s *Server) postSolutionHandler(w http.ResponseWriter, r *http.Request) {
+	qa, err := s.jobs.getQAEngine(mux.Vars(r)["id"])
+	if err != nil {
+		writeJobError(w, err)
+		return
+	}
+	solution := struct {
+		ID     string      `json:"id"`
+		Answer interface{} `json:"answer"`
+	}{}
+	if err := json.NewDecoder(r.Body).Decode(&solution); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("failed to decode the request body as solution json. Error: %w", err))
+		return
+	}
+	if err := qa.setAnswer(solution.ID, solution.Answer); err != nil {
+		writeError(w, http.StatusNotAcceptable, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) getOutputHandler(w http.ResponseWriter, r *http.Request) {
+	job, ok := s.jobs.get(mux.Vars(r)["id"])
+	if !ok {
+		writeError(w, http.StatusNotFound, errJobNotFound)
+		return
+	}
+	if job.Status != JobDone {
+		writeError(w, http.StatusConflict, fmt.Errorf("the output of the job '%s' is not ready. Status: '%s'", job.ID, job.Status))
+		return
+	}
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", job.Name+".zip"))
+	if err := common.WriteZip(filepath.Join(job.dir, outputDirName), w); err != nil {
+		logrus.Errorf("failed to send the output of the job '%s' . Error: %q", job.ID, err)
+	}
+}
+
+func saveUpload(src io.Reader, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create the file '%s' . Error: %w", path, err)
+	}
+	defer f.Close()
+	if _, err := io.Copy(f, src); err != nil {
+		return fmt.Errorf("failed to save the uploaded file to '%s' . Error: %w", path, err)
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, obj interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(obj); err != nil {
+		logrus.Errorf("failed to encode the response as json. Error: %q", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	logrus.Debugf("request failed with status %d . Error: %q", status, err)
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+func writeJobError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, errJobNotFound):
+		writeError(w, http.StatusNotFound, err)
+	case errors.Is(err, errJobConflict):
+		writeError(w, http.StatusConflict, err)
+	case errors.Is(err, errInvalidConfig):
+		writeError(w, http.StatusBadRequest, err)
+	case errors.Is(err, errQueueFull):
+		w.Header().Set("Retry-After", "60")
+		writeError(w, http.StatusServiceUnavailable, err)
+	default:
+		writeError(w, http.StatusInternalServerError, err)
+	}
+}
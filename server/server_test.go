@@ -0,0 +1,225 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package server
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func createZip(t *testing.T, files map[string]string) []byte {
+	buf := &bytes.Buffer{}
+	zipWriter := zip.NewWriter(buf)
+	for name, content := range files {
+		w, err := zipWriter.Create(name)
+		if err != nil {
+			t.Fatalf("failed to create the zip entry '%s' . Error: %q", name, err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatalf("failed to write the zip entry '%s' . Error: %q", name, err)
+		}
+	}
+	if err := zipWriter.Close(); err != nil {
+		t.Fatalf("failed to close the zip writer. Error: %q", err)
+	}
+	return buf.Bytes()
+}
+
+func uploadSource(t *testing.T, handler http.Handler, filename string, archive []byte) *httptest.ResponseRecorder {
+	body := &bytes.Buffer{}
+	formWriter := multipart.NewWriter(body)
+	if err := formWriter.WriteField(nameFormField, "myproject"); err != nil {
+		t.Fatalf("failed to write the name field. Error: %q", err)
+	}
+	fileWriter, err := formWriter.CreateFormFile(sourceFormField, filename)
+	if err != nil {
+		t.Fatalf("failed to create the source field. Error: %q", err)
+	}
+	if _, err := fileWriter.Write(archive); err != nil {
+		t.Fatalf("failed to write the source field. Error: %q", err)
+	}
+	formWriter.Close()
+	req := httptest.NewRequest("POST", jobsURLPrefix, body)
+	req.Header.Set("Content-Type", formWriter.FormDataContentType())
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestServer(t *testing.T) {
+	t.Run("create a job by uploading a zip archive", func(t *testing.T) {
+		s, err := NewServer(t.TempDir())
+		if err != nil {
+			t.Fatalf("failed to create the server. Error: %q", err)
+		}
+		handler := s.Handler()
+		rec := uploadSource(t, handler, "src.zip", createZip(t, map[string]string{"app/main.py": "print('hello')"}))
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("expected status %d, actual %d . Body: %s", http.StatusCreated, rec.Code, rec.Body.String())
+		}
+		job := Job{}
+		if err := json.Unmarshal(rec.Body.Bytes(), &job); err != nil {
+			t.Fatalf("failed to decode the job. Error: %q", err)
+		}
+		if job.Name != "myproject" || job.Status != JobCreated {
+			t.Fatalf("unexpected job: %+v", job)
+		}
+		storedJob, ok := s.jobs.get(job.ID)
+		if !ok {
+			t.Fatalf("the job '%s' was not stored", job.ID)
+		}
+		if _, err := os.Stat(filepath.Join(storedJob.dir, sourceDirName, "app", "main.py")); err != nil {
+			t.Fatalf("the source was not extracted. Error: %q", err)
+		}
+
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest("GET", jobsURLPrefix+"/"+job.ID+"/output", nil))
+		if rec.Code != http.StatusConflict {
+			t.Fatalf("expected status %d for the output of an untransformed job, actual %d", http.StatusConflict, rec.Code)
+		}
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest("POST", jobsURLPrefix+"/"+job.ID+"/transform", nil))
+		if rec.Code != http.StatusConflict {
+			t.Fatalf("expected status %d when transforming a job without a plan, actual %d", http.StatusConflict, rec.Code)
+		}
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest("DELETE", jobsURLPrefix+"/"+job.ID, nil))
+		if rec.Code != http.StatusNoContent {
+			t.Fatalf("expected status %d when deleting the job, actual %d", http.StatusNoContent, rec.Code)
+		}
+		if _, err := os.Stat(storedJob.dir); !os.IsNotExist(err) {
+			t.Fatalf("the job directory was not removed. Error: %q", err)
+		}
+	})
+	t.Run("reject archives with entries outside the source directory", func(t *testing.T) {
+		s, err := NewServer(t.TempDir())
+		if err != nil {
+			t.Fatalf("failed to create the server. Error: %q", err)
+		}
+		handler := s.Handler()
+		rec := uploadSource(t, handler, "src.zip", createZip(t, map[string]string{"../../evil.sh": "rm -rf /"}))
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("expected status %d, actual %d . Body: %s", http.StatusBadRequest, rec.Code, rec.Body.String())
+		}
+		if len(s.jobs.list()) != 0 {
+			t.Fatalf("expected the job to be removed")
+		}
+	})
+	t.Run("unknown job", func(t *testing.T) {
+		s, err := NewServer(t.TempDir())
+		if err != nil {
+			t.Fatalf("failed to create the server. Error: %q", err)
+		}
+		rec := httptest.NewRecorder()
+		s.Handler().ServeHTTP(rec, httptest.NewRequest("POST", jobsURLPrefix+"/foo/plan", nil))
+		if rec.Code != http.StatusNotFound {
+			t.Fatalf("expected status %d, actual %d", http.StatusNotFound, rec.Code)
+		}
+	})
+}
+
+func TestJobStore(t *testing.T) {
+	t.Run("reject the jobs when the queue is full", func(t *testing.T) {
+		store := &jobStore{workDir: t.TempDir(), jobs: map[string]*Job{}, queue: make(chan func())}
+		job, err := store.create("myproject")
+		if err != nil {
+			t.Fatalf("failed to create the job. Error: %q", err)
+		}
+		err = store.startJob(job.ID, []JobStatus{JobCreated}, nil, func(ctx context.Context, job *Job) (JobStatus, error) {
+			return JobDone, nil
+		})
+		if !errors.Is(err, errQueueFull) {
+			t.Fatalf("expected the job to be rejected. Actual: %v", err)
+		}
+		if rejectedJob, _ := store.get(job.ID); rejectedJob.Status != JobCreated {
+			t.Fatalf("expected the rejected job to keep its status. Actual: %s", rejectedJob.Status)
+		}
+		rec := httptest.NewRecorder()
+		writeJobError(rec, err)
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Fatalf("expected status %d, actual %d", http.StatusServiceUnavailable, rec.Code)
+		}
+	})
+	t.Run("delete a running job after it stops", func(t *testing.T) {
+		store := newJobStore(t.TempDir())
+		job, err := store.create("myproject")
+		if err != nil {
+			t.Fatalf("failed to create the job. Error: %q", err)
+		}
+		started := make(chan struct{})
+		removedWhileRunning := false
+		err = store.startJob(job.ID, []JobStatus{JobCreated}, nil, func(ctx context.Context, job *Job) (JobStatus, error) {
+			close(started)
+			<-ctx.Done()
+			if _, err := os.Stat(job.dir); err != nil {
+				removedWhileRunning = true
+			}
+			return JobFailed, ctx.Err()
+		})
+		if err != nil {
+			t.Fatalf("failed to start the job. Error: %q", err)
+		}
+		<-started
+		if err := store.delete(job.ID); err != nil {
+			t.Fatalf("failed to delete the job. Error: %q", err)
+		}
+		if removedWhileRunning {
+			t.Fatalf("the job directory was removed while the job was running")
+		}
+		if _, err := os.Stat(job.dir); !os.IsNotExist(err) {
+			t.Fatalf("the job directory was not removed. Error: %q", err)
+		}
+	})
+	t.Run("accept the configs only as the contents of config files", func(t *testing.T) {
+		store := newJobStore(t.TempDir())
+		job, err := store.create("myproject")
+		if err != nil {
+			t.Fatalf("failed to create the job. Error: %q", err)
+		}
+		for _, config := range []string{"/etc/passwd", "https://example.com/m2kconfig.yaml"} {
+			err := store.transform(job.ID, TransformOptions{Configs: []string{config}})
+			if !errors.Is(err, errInvalidConfig) {
+				t.Fatalf("expected the config '%s' to be rejected. Actual: %v", config, err)
+			}
+			rec := httptest.NewRecorder()
+			writeJobError(rec, err)
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("expected status %d, actual %d", http.StatusBadRequest, rec.Code)
+			}
+		}
+		configsDir := filepath.Join(t.TempDir(), configsDirName)
+		configPaths, err := writeConfigs(configsDir, []string{"move2kube:\n  minreplicas: \"2\"\n"})
+		if err != nil {
+			t.Fatalf("failed to write the configs. Error: %q", err)
+		}
+		if len(configPaths) != 1 || filepath.Dir(configPaths[0]) != configsDir {
+			t.Fatalf("expected the config to be written to the directory '%s' . Actual: %+v", configsDir, configPaths)
+		}
+		if err := validateConfigs([]string{"move2kube:\n  minreplicas: \"2\"\n"}); err != nil {
+			t.Fatalf("failed to validate the config. Error: %q", err)
+		}
+	})
+}
@@ -1,3 +1,6 @@
+//go:build !wasm
+// +build !wasm
+
 /*
  *  Copyright IBM Corporation 2021
  *
@@ -20,8 +23,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 
 	"code.cloudfoundry.org/cli/util/manifest"
@@ -30,12 +35,14 @@ import (
 	"github.com/konveyor/move2kube/common"
 	"github.com/konveyor/move2kube/environment"
 	"github.com/konveyor/move2kube/qaengine"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
 	irtypes "github.com/konveyor/move2kube/types/ir"
 	transformertypes "github.com/konveyor/move2kube/types/transformer"
 	"github.com/konveyor/move2kube/types/transformer/artifacts"
 	"github.com/sirupsen/logrus"
 	"gopkg.in/yaml.v3"
 	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation"
 	core "k8s.io/kubernetes/pkg/apis/core"
 	"k8s.io/kubernetes/pkg/apis/networking"
 )
@@ -43,6 +50,13 @@ import (
 const (
 	// ResourceRequestKey is the config key for resource requests
 	ResourceRequestKey = "ResourceRequest"
+	// vcapServicesExportFileName is the name of the file, next to the manifest or in the app directory, containing an export of the VCAP_SERVICES of the app
+	vcapServicesExportFileName = "vcap_services.json"
+	// serviceBindingRoot is the directory the service binding secrets are mounted in, following the servicebinding.io spec
+	serviceBindingRoot         = "/bindings"
+	serviceBindingRootEnvName  = "SERVICE_BINDING_ROOT"
+	serviceBindingSecretSuffix = "-binding"
+	userProvidedServiceLabel   = "user-provided"
 )
 
 // variableLiteralPattern to identify variable literals in environment names
@@ -88,6 +102,17 @@ func (t *CloudFoundry) DirectoryDetect(dir string) (map[string][]transformertype
 		cfInstanceApps[filePath] = append(cfInstanceApps[filePath], fileCfInstanceApps.Spec.CfApps...)
 	}
 	logrus.Debugf("Cf Instances %+v", cfInstanceApps)
+	// Load the service bindings, if available
+	cfServiceBindings := map[string][]collecttypes.CfServiceBinding{} //path
+	for _, filePath := range filePaths {
+		fileCfServices := collecttypes.CfServices{}
+		if err := common.ReadMove2KubeYaml(filePath, &fileCfServices); err != nil || fileCfServices.Kind != string(collecttypes.CfServicesMetadataKind) {
+			continue
+		}
+		if len(fileCfServices.Spec.ServiceBindings) != 0 {
+			cfServiceBindings[filePath] = fileCfServices.Spec.ServiceBindings
+		}
+	}
 	for _, filePath := range filePaths {
 		applications, _, err := t.readApplicationManifest(filePath, "")
 		if err != nil {
@@ -141,6 +166,20 @@ func (t *CloudFoundry) DirectoryDetect(dir string) (map[string][]transformertype
 			if runningManifestPath != "" {
 				ct.Paths[artifacts.CfRunningManifestPathType] = append(ct.Paths[artifacts.CfRunningManifestPathType], runningManifestPath)
 			}
+			for servicesPath, bindings := range cfServiceBindings {
+				for _, binding := range bindings {
+					if binding.AppName == applicationName {
+						ct.Paths[artifacts.CfServicesPathType] = common.AppendIfNotPresent(ct.Paths[artifacts.CfServicesPathType], servicesPath)
+						break
+					}
+				}
+			}
+			for _, vcapServicesDir := range []string{servicedirectory, filepath.Dir(filePath)} {
+				vcapServicesPath := filepath.Join(vcapServicesDir, vcapServicesExportFileName)
+				if _, err := os.Stat(vcapServicesPath); err == nil {
+					ct.Paths[artifacts.CfVcapServicesPathType] = common.AppendIfNotPresent(ct.Paths[artifacts.CfVcapServicesPathType], vcapServicesPath)
+				}
+			}
 			normalizedServiceName := common.MakeStringK8sServiceNameCompliant(applicationName)
 			services[normalizedServiceName] = []transformertypes.Artifact{ct}
 		}
@@ -199,6 +238,33 @@ func (t *CloudFoundry) Transform(newArtifacts []transformertypes.Artifact, alrea
 			} else if application.Instances.IsSet {
 				irService.Replicas = application.Instances.Value
 			}
+			if bindings := getCfServiceBindings(a, cfConfig.ServiceName, cfinstanceapp); len(bindings) != 0 {
+				storages, volumes, volumeMounts := getServiceBindingSecrets(serviceConfig.ServiceName, bindings)
+				ir.Storages = append(ir.Storages, storages...)
+				irService.Volumes = append(irService.Volumes, volumes...)
+				serviceContainer.VolumeMounts = append(serviceContainer.VolumeMounts, volumeMounts...)
+				serviceContainer.Env = append(serviceContainer.Env, core.EnvVar{Name: serviceBindingRootEnvName, Value: serviceBindingRoot})
+				if _, ok := cfinstanceapp.Environment.SystemEnv[common.VcapServiceEnvName]; !ok {
+					quesKey := common.JoinQASubKeys(common.ConfigServicesKey, `"`+serviceConfig.ServiceName+`"`, common.ConfigVcapServicesForServiceKeySegment)
+					if qaengine.FetchBoolAnswer(
+						quesKey,
+						fmt.Sprintf("Construct the VCAP_SERVICES environment variable of the '%s' service from its service bindings?", serviceConfig.ServiceName),
+						[]string{"Apps that read the credentials of their services from VCAP_SERVICES can then run unmodified on Kubernetes"},
+						true,
+						nil,
+					) {
+						vcapServices, err := getVcapServices(bindings)
+						if err != nil {
+							logrus.Errorf("failed to construct the VCAP_SERVICES of the service '%s' . Error: %q", serviceConfig.ServiceName, err)
+						} else {
+							if cfinstanceapp.Environment.SystemEnv == nil {
+								cfinstanceapp.Environment.SystemEnv = map[string]interface{}{}
+							}
+							cfinstanceapp.Environment.SystemEnv[common.VcapServiceEnvName] = vcapServices
+						}
+					}
+				}
+			}
 			secretName := cfConfig.ServiceName + common.VcapCfSecretSuffix
 			envList, vcapEnvMap := t.prioritizeAndAddEnvironmentVariables(cfinstanceapp, application.EnvironmentVariables,
 				secretName, cfConfig.ServiceName)
@@ -299,8 +365,14 @@ func (t *CloudFoundry) prioritizeAndAddEnvironmentVariables(cfApp collector.CfAp
 	for varname, value := range cfApp.Environment.Environment {
 		envOrderMap[varname] = core.EnvVar{Name: varname, Value: fmt.Sprintf("%s", value)}
 	}
+	envNames := []string{}
+	for envName := range envOrderMap {
+		envNames = append(envNames, envName)
+	}
+	sort.Strings(envNames)
 	var envList []core.EnvVar
-	for _, env := range envOrderMap {
+	for _, envName := range envNames {
+		env := envOrderMap[envName]
 		if _, ok := vcapEnvMap[env.Name]; ok {
 			vcapEnvMap[env.Name] = []byte(env.Value)
 			secretKeyRef := core.SecretKeySelector{}
@@ -362,6 +434,129 @@ func flattenVcapServiceVariables(vcapService string, serviceName string) []core.
 	return flattenedEnvList
 }
 
+// getCfServiceBindings returns the service bindings of the app, from the collected app environment,
+// an exported VCAP_SERVICES or the collected cf services, in that order of preference
+func getCfServiceBindings(a transformertypes.Artifact, appName string, cfApp collector.CfApp) []collecttypes.CfServiceBinding {
+	if vcapServices, ok := cfApp.Environment.SystemEnv[common.VcapServiceEnvName]; ok {
+		if bindings, err := parseVcapServices([]byte(fmt.Sprintf("%s", vcapServices))); err != nil {
+			logrus.Debugf("Failed to parse the VCAP_SERVICES of the app %s . Error: %q", appName, err)
+		} else if len(bindings) != 0 {
+			return bindings
+		}
+	}
+	for _, vcapServicesPath := range a.Paths[artifacts.CfVcapServicesPathType] {
+		vcapServices, err := os.ReadFile(vcapServicesPath)
+		if err != nil {
+			logrus.Errorf("failed to read the exported VCAP_SERVICES at path %s . Error: %q", vcapServicesPath, err)
+			continue
+		}
+		bindings, err := parseVcapServices(vcapServices)
+		if err != nil {
+			logrus.Errorf("failed to parse the exported VCAP_SERVICES at path %s . Error: %q", vcapServicesPath, err)
+			continue
+		}
+		if len(bindings) != 0 {
+			return bindings
+		}
+	}
+	bindings := []collecttypes.CfServiceBinding{}
+	for _, servicesPath := range a.Paths[artifacts.CfServicesPathType] {
+		cfServices := collecttypes.CfServices{}
+		if err := common.ReadMove2KubeYaml(servicesPath, &cfServices); err != nil {
+			logrus.Errorf("failed to read the cf services at path %s . Error: %q", servicesPath, err)
+			continue
+		}
+		for _, binding := range cfServices.Spec.ServiceBindings {
+			if binding.AppName == appName {
+				bindings = append(bindings, binding)
+			}
+		}
+	}
+	return bindings
+}
+
+// parseVcapServices parses the service bindings in VCAP_SERVICES
+func parseVcapServices(vcapServices []byte) ([]collecttypes.CfServiceBinding, error) {
+	serviceInstanceMap := map[string][]collecttypes.CfServiceBinding{}
+	if err := json.Unmarshal(vcapServices, &serviceInstanceMap); err != nil {
+		return nil, err
+	}
+	labels := []string{}
+	for label := range serviceInstanceMap {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	bindings := []collecttypes.CfServiceBinding{}
+	for _, label := range labels {
+		for _, binding := range serviceInstanceMap[label] {
+			if binding.Label == "" {
+				binding.Label = label
+			}
+			bindings = append(bindings, binding)
+		}
+	}
+	return bindings, nil
+}
+
+// getVcapServices constructs VCAP_SERVICES from the service bindings
+func getVcapServices(bindings []collecttypes.CfServiceBinding) (string, error) {
+	serviceInstanceMap := map[string][]collecttypes.CfServiceBinding{}
+	for _, binding := range bindings {
+		label := binding.Label
+		if label == "" {
+			label = userProvidedServiceLabel
+		}
+		if binding.Tags == nil {
+			binding.Tags = []string{}
+		}
+		serviceInstanceMap[label] = append(serviceInstanceMap[label], binding)
+	}
+	vcapServices, err := json.Marshal(serviceInstanceMap)
+	if err != nil {
+		return "", err
+	}
+	return string(vcapServices), nil
+}
+
+// getServiceBindingSecrets creates a secret with the credentials of each service binding and mounts it in the service binding root
+func getServiceBindingSecrets(serviceName string, bindings []collecttypes.CfServiceBinding) ([]irtypes.Storage, []core.Volume, []core.VolumeMount) {
+	storages := []irtypes.Storage{}
+	volumes := []core.Volume{}
+	volumeMounts := []core.VolumeMount{}
+	for _, binding := range bindings {
+		secretName := common.MakeStringK8sServiceNameCompliant(serviceName + "-" + binding.Name + serviceBindingSecretSuffix)
+		content := map[string][]byte{}
+		for key, value := range binding.Credentials {
+			if errs := validation.IsConfigMapKey(key); len(errs) != 0 {
+				logrus.Warnf("Ignoring the credential '%s' of the service binding '%s' since it is not a valid secret key. Error: %s", key, binding.Name, strings.Join(errs, ", "))
+				continue
+			}
+			if valueStr, ok := value.(string); ok {
+				content[key] = []byte(valueStr)
+				continue
+			}
+			valueBytes, err := json.Marshal(value)
+			if err != nil {
+				logrus.Warnf("Ignoring the credential '%s' of the service binding '%s' . Error: %q", key, binding.Name, err)
+				continue
+			}
+			content[key] = valueBytes
+		}
+		// the type is required by the servicebinding.io spec
+		if _, ok := content["type"]; !ok && binding.Label != "" {
+			content["type"] = []byte(binding.Label)
+		}
+		storages = append(storages, irtypes.Storage{Name: secretName, StorageType: irtypes.SecretKind, Content: content})
+		volumes = append(volumes, core.Volume{Name: secretName, VolumeSource: core.VolumeSource{Secret: &core.SecretVolumeSource{SecretName: secretName}}})
+		volumeMounts = append(volumeMounts, core.VolumeMount{
+			Name:      secretName,
+			MountPath: path.Join(serviceBindingRoot, common.MakeStringK8sServiceNameCompliant(binding.Name)),
+			ReadOnly:  true,
+		})
+	}
+	return storages, volumes, volumeMounts
+}
+
 // readApplicationManifest reads an application manifest
 func (t *CloudFoundry) readApplicationManifest(path string, serviceName string) ([]manifest.Application, []string, error) { // manifest, parameters
 	trimmedvariables, err := getMissingVariables(path)
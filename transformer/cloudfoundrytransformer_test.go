@@ -0,0 +1,65 @@
+//go:build !wasm
+// +build !wasm
+
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+)
+
+func TestVcapServices(t *testing.T) {
+	bindings := []collecttypes.CfServiceBinding{
+		{Name: "ordersdb", InstanceName: "ordersdb", Label: "elephantsql", Plan: "turtle", Tags: []string{"postgres"},
+			Credentials: map[string]interface{}{"uri": "postgres://user:pass@db:5432/orders", "port": float64(5432)}},
+		{Name: "smtp", InstanceName: "smtp", Label: "user-provided", Tags: []string{}, Credentials: map[string]interface{}{"host": "mail"}},
+	}
+	vcapServices, err := getVcapServices(bindings)
+	if err != nil {
+		t.Fatalf("failed to construct VCAP_SERVICES. Error: %q", err)
+	}
+	parsedBindings, err := parseVcapServices([]byte(vcapServices))
+	if err != nil {
+		t.Fatalf("failed to parse the constructed VCAP_SERVICES %s . Error: %q", vcapServices, err)
+	}
+	if diff := cmp.Diff(bindings, parsedBindings); diff != "" {
+		t.Fatalf("the bindings changed when round tripped through VCAP_SERVICES. Difference:\n%s", diff)
+	}
+}
+
+func TestGetServiceBindingSecrets(t *testing.T) {
+	bindings := []collecttypes.CfServiceBinding{{Name: "ordersdb", Label: "elephantsql",
+		Credentials: map[string]interface{}{"uri": "postgres://db/orders", "port": 5432, "hosts": []interface{}{"db1", "db2"}, "not/valid": "x"}}}
+	storages, volumes, volumeMounts := getServiceBindingSecrets("orders", bindings)
+	if len(storages) != 1 || len(volumes) != 1 || len(volumeMounts) != 1 {
+		t.Fatalf("expected one secret, volume and volume mount. Actual: %+v %+v %+v", storages, volumes, volumeMounts)
+	}
+	want := map[string][]byte{"uri": []byte("postgres://db/orders"), "port": []byte("5432"), "hosts": []byte(`["db1","db2"]`), "type": []byte("elephantsql")}
+	if diff := cmp.Diff(want, storages[0].Content); diff != "" {
+		t.Fatalf("unexpected content of the binding secret. Difference:\n%s", diff)
+	}
+	if storages[0].Name != "orders-ordersdb-binding" || volumes[0].Secret.SecretName != storages[0].Name {
+		t.Fatalf("expected the volume to refer to the binding secret. Actual: %+v", volumes[0])
+	}
+	if volumeMounts[0].MountPath != "/bindings/ordersdb" || !volumeMounts[0].ReadOnly {
+		t.Fatalf("expected the binding secret to be mounted read only in the service binding root. Actual: %+v", volumeMounts[0])
+	}
+}
@@ -19,13 +19,16 @@ package compose
 import (
 	"fmt"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/konveyor/move2kube/common"
 	"github.com/konveyor/move2kube/environment"
+	"github.com/konveyor/move2kube/qaengine"
 	collecttypes "github.com/konveyor/move2kube/types/collection"
 	irtypes "github.com/konveyor/move2kube/types/ir"
 	plantypes "github.com/konveyor/move2kube/types/plan"
+	"github.com/konveyor/move2kube/types/qaengine/commonqa"
 	transformertypes "github.com/konveyor/move2kube/types/transformer"
 	"github.com/konveyor/move2kube/types/transformer/artifacts"
 	"github.com/sirupsen/logrus"
@@ -41,6 +44,8 @@ const (
 const (
 	// imageInfoPathType defines the source artifact type of image info
 	imageInfoPathType transformertypes.PathType = "ImageInfo"
+	// resourceMetricsPathType defines the source artifact type of the resource usage collected from the running containers
+	resourceMetricsPathType transformertypes.PathType = "ResourceMetrics"
 	// dockerComposeContextPathType defines the docker-compose directory context type
 	dockerComposeContextPathType transformertypes.PathType = "DockerComposeContextPathType"
 )
@@ -55,11 +60,19 @@ type ComposeAnalyser struct {
 // ComposeAnalyzerConfig represents the configuration of the compose analyzer
 type ComposeAnalyzerConfig struct {
 	EnableNetworkParsing bool `yaml:"enableNetworkParsing"`
+	// LocalComposeOutputPath is the directory of the compose file with all the services for local development
+	LocalComposeOutputPath string `yaml:"localComposeOutputPath"`
+	// DevLoopOutputPath is the directory of the Skaffold and Tilt configs generated from the compose watch rules
+	DevLoopOutputPath string `yaml:"devLoopOutputPath"`
 }
 
 // ComposeConfig stores the config for compose service
 type ComposeConfig struct {
 	ServiceName string `yaml:"serviceName,omitempty"`
+	// Profiles are the compose profiles enabling the service. The service is always enabled if it has none.
+	Profiles []string `yaml:"profiles,omitempty"`
+	// NamePrefix is the compose project name that the names of the service and of the storages it uses are prefixed with
+	NamePrefix string `yaml:"namePrefix,omitempty"`
 }
 
 // Init Initializes the transformer
@@ -71,6 +84,12 @@ func (t *ComposeAnalyser) Init(tc transformertypes.Transformer, env *environment
 	if err := common.GetObjFromInterface(t.Config.Spec.Config, t.ComposeAnalyzerConfig); err != nil {
 		return fmt.Errorf("unable to load config for Transformer %+v into %T . Error: %q", t.Config.Spec.Config, t.ComposeAnalyzerConfig, err)
 	}
+	if t.ComposeAnalyzerConfig.LocalComposeOutputPath == "" {
+		t.ComposeAnalyzerConfig.LocalComposeOutputPath = defaultLocalComposeOutputPath
+	}
+	if t.ComposeAnalyzerConfig.DevLoopOutputPath == "" {
+		t.ComposeAnalyzerConfig.DevLoopOutputPath = defaultDevLoopOutputPath
+	}
 	return nil
 }
 
@@ -94,14 +113,52 @@ func (t *ComposeAnalyser) DirectoryDetect(dir string) (services map[string][]tra
 			continue
 		}
 		for _, imageTag := range im.Spec.Tags {
-			imageMetadataPaths[imageTag] = yamlPath
+			imageMetadataPaths[getImageInfoKey(imageTag)] = yamlPath
 		}
 	}
 	services = map[string][]transformertypes.Artifact{}
+	overrideFiles := getComposeOverrideFiles(yamlPaths)
+	isOverrideFile := map[string]bool{}
+	for _, overrideFilePaths := range overrideFiles {
+		for _, overrideFilePath := range overrideFilePaths {
+			isOverrideFile[overrideFilePath] = true
+		}
+	}
+	projectServices := map[string]map[string][]transformertypes.Artifact{}
 	for _, yamlPath := range yamlPaths {
-		currServices := t.getServicesFromComposeFile(yamlPath, imageMetadataPaths)
+		if isOverrideFile[yamlPath] {
+			continue
+		}
+		currServices := t.getServicesFromComposeFile(yamlPath, overrideFiles[yamlPath], imageMetadataPaths)
+		if len(currServices) == 0 {
+			continue
+		}
+		project := getComposeProjectName(yamlPath)
+		projectServices[project] = plantypes.MergeServicesT(projectServices[project], currServices)
+	}
+	projects := []string{}
+	for project := range projectServices {
+		projects = append(projects, project)
+	}
+	sort.Strings(projects)
+	for _, project := range projects {
+		currServices := projectServices[project]
+		// the same named services of multiple projects would otherwise be merged into one service
+		if len(projects) > 1 && commonqa.ProjectNamePrefix(project, true) {
+			currServices = getProjectPrefixedServices(project, currServices)
+		}
 		services = plantypes.MergeServicesT(services, currServices)
 	}
+	resourceMetrics := getDockerResourceMetrics(yamlPaths)
+	for serviceName, serviceArtifacts := range services {
+		metricsPaths := getResourceMetricsPaths(resourceMetrics, serviceName, "")
+		if len(metricsPaths) == 0 {
+			continue
+		}
+		for _, serviceArtifact := range serviceArtifacts {
+			serviceArtifact.Paths[resourceMetricsPathType] = metricsPaths
+		}
+	}
 	logrus.Debugf("Docker compose services : %+v", services)
 	return services, nil
 }
@@ -112,12 +169,20 @@ func (t *ComposeAnalyser) Transform(newArtifacts []transformertypes.Artifact, al
 	defer logrus.Trace("ComposeAnalyser.Transform end")
 	pathMappings := []transformertypes.PathMapping{}
 	createdArtifacts := []transformertypes.Artifact{}
+	activeProfiles := getActiveProfiles(newArtifacts)
+	localCompose := newLocalCompose(t.Env.GetEnvironmentSource(), t.ComposeAnalyzerConfig.LocalComposeOutputPath)
+	localCompose.activeProfiles = activeProfiles
+	devLoop := newDevLoop(t.Env.GetEnvironmentSource(), t.ComposeAnalyzerConfig.DevLoopOutputPath)
 	for _, newArtifact := range newArtifacts {
 		config := ComposeConfig{}
 		if err := newArtifact.GetConfig(ComposeServiceConfigType, &config); err != nil {
 			logrus.Errorf("failed to load config for Transformer into %T . Error: %q", config, err)
 			continue
 		}
+		if !isProfileActive(config.Profiles, activeProfiles) {
+			logrus.Infof("Ignoring the compose service %s since none of its profiles %+v are enabled", config.ServiceName, config.Profiles)
+			continue
+		}
 		serviceConfig := artifacts.ServiceConfig{}
 		if err := newArtifact.GetConfig(artifacts.ServiceConfigType, &serviceConfig); err != nil {
 			logrus.Errorf("failed to load config for Transformer into %T . Error: %q", serviceConfig, err)
@@ -128,31 +193,65 @@ func (t *ComposeAnalyser) Transform(newArtifacts []transformertypes.Artifact, al
 			logrus.Debugf("failed to load config for Transformer into %T . Error: %q", imageName, err)
 		}
 		ir := irtypes.NewIR()
+		imageInfos := []collecttypes.ImageInfo{}
+		for _, path := range newArtifact.Paths[imageInfoPathType] {
+			imgMD := collecttypes.ImageInfo{}
+			if err := common.ReadMove2KubeYaml(path, &imgMD); err != nil {
+				logrus.Errorf("failed to read image info yaml at path '%s' . Error: %q", path, err)
+				continue
+			}
+			imageInfos = append(imageInfos, imgMD)
+		}
+		var imageInfo *collecttypes.ImageInfoSpec
+		if len(imageInfos) != 0 {
+			// fill the details missing in the compose file, like the ports and the user, from the image
+			imageInfo = &imageInfos[0].Spec
+		}
+		watchRules := []composeWatchRule{}
 		composeFiles := []string{}
 		if err := newArtifact.GetConfig(ComposeFileConfigType, &composeFiles); err != nil {
 			logrus.Errorf("failed to get the compose files from the artifact. Error: %+q", err)
 			continue
 		}
-		for _, composeFileName := range composeFiles {
-			composeFilePath := filepath.Join(newArtifact.Paths[dockerComposeContextPathType][0], composeFileName)
-			logrus.Debugf("file at path '%s' being loaded from the compose service name '%s'", composeFilePath, config.ServiceName)
-			// Try v3 first and if it fails try v1v2
-			if cir, errV3 := new(v3Loader).ConvertToIR(composeFilePath, config.ServiceName, t.ComposeAnalyzerConfig.EnableNetworkParsing); errV3 == nil {
-				ir.Merge(cir)
-				logrus.Debugf("compose v3 transformer returned %d services", len(ir.Services))
-			} else if cir, errV1V2 := new(v1v2Loader).ConvertToIR(composeFilePath, config.ServiceName, t.ComposeAnalyzerConfig.EnableNetworkParsing); errV1V2 == nil {
-				ir.Merge(cir)
-				logrus.Debugf("compose v1v2 transformer returned %d services", len(ir.Services))
-			} else {
-				logrus.Errorf("failed to parse the docker compose file at path '%s' . Error V3: %q Error V1V2: %q", composeFilePath, errV3, errV1V2)
-			}
+		if len(composeFiles) == 0 {
+			logrus.Errorf("the artifact of the compose service '%s' does not have any compose files", config.ServiceName)
+			continue
 		}
-		for _, path := range newArtifact.Paths[imageInfoPathType] {
-			imgMD := collecttypes.ImageInfo{}
-			if err := common.ReadMove2KubeYaml(path, &imgMD); err != nil {
-				logrus.Errorf("failed to read image info yaml at path '%s' . Error: %q", path, err)
-				continue
+		// the first file is the compose file and the rest are its override files
+		composeFilePath := filepath.Join(newArtifact.Paths[dockerComposeContextPathType][0], composeFiles[0])
+		overrideFilePaths := t.selectOverrideFiles(composeFilePath, composeFiles[1:])
+		composeFiles = []string{composeFiles[0]}
+		for _, overrideFilePath := range overrideFilePaths {
+			composeFiles = append(composeFiles, filepath.Base(overrideFilePath))
+		}
+		logrus.Debugf("file at path '%s' with the override files %+v being loaded from the compose service name '%s'", composeFilePath, overrideFilePaths, config.ServiceName)
+		// Try v3 first and if it fails try v1v2
+		serviceNames := getPlannedServiceNames(newArtifacts, composeFilePath)
+		if cir, errV3 := (&v3Loader{imageInfo: imageInfo, overrideFilePaths: overrideFilePaths, activeProfiles: activeProfiles, serviceNames: serviceNames}).ConvertToIR(composeFilePath, config.ServiceName, t.ComposeAnalyzerConfig.EnableNetworkParsing); errV3 == nil {
+			ir.Merge(cir)
+			if localCompose.addService(composeFilePath, config.ServiceName, overrideFilePaths...) {
+				pathMappings = append(pathMappings, transformertypes.PathMapping{
+					Type:     transformertypes.SourcePathMappingType,
+					SrcPath:  newArtifact.Paths[dockerComposeContextPathType][0],
+					DestPath: common.DefaultSourceDir,
+				})
+			}
+			watchRules = append(watchRules, getComposeWatchRules(composeFilePath, config.ServiceName, overrideFilePaths...)...)
+			logrus.Debugf("compose v3 transformer returned %d services", len(ir.Services))
+		} else if cir, errV1V2 := (&v1v2Loader{imageInfo: imageInfo, overrideFilePaths: overrideFilePaths, serviceNames: serviceNames}).ConvertToIR(composeFilePath, config.ServiceName, t.ComposeAnalyzerConfig.EnableNetworkParsing); errV1V2 == nil {
+			ir.Merge(cir)
+			if localCompose.addServiceV2(composeFilePath, config.ServiceName, overrideFilePaths...) {
+				pathMappings = append(pathMappings, transformertypes.PathMapping{
+					Type:     transformertypes.SourcePathMappingType,
+					SrcPath:  newArtifact.Paths[dockerComposeContextPathType][0],
+					DestPath: common.DefaultSourceDir,
+				})
 			}
+			logrus.Debugf("compose v1v2 transformer returned %d services", len(ir.Services))
+		} else {
+			logrus.Errorf("failed to parse the docker compose file at path '%s' . Error V3: %q Error V1V2: %q", composeFilePath, errV3, errV1V2)
+		}
+		for _, imgMD := range imageInfos {
 			ir.AddContainer(imageName.ImageName, newContainerFromImageInfo(imgMD))
 		}
 		if imageName.ImageName == "" {
@@ -181,11 +280,34 @@ func (t *ComposeAnalyser) Transform(newArtifacts []transformertypes.Artifact, al
 				}
 			}
 		}
+		storageNames := map[string]string{}
+		if config.NamePrefix != "" {
+			for i, storage := range ir.Storages {
+				storageNames[storage.Name] = getProjectPrefixedName(config.NamePrefix, storage.Name)
+				ir.Storages[i].Name = storageNames[storage.Name]
+			}
+		}
 		for name, service := range ir.Services {
 			delete(ir.Services, name)
+			// the compose service name might have been disambiguated from other services, or prefixed with the project name, during planning
+			service.Name = serviceConfig.ServiceName
+			service.RenameServiceReferences(serviceNames)
+			service.PodSpec.RenameStorageReferences(storageNames)
+			service.SourceFiles = getSourceFiles(t.Env.GetEnvironmentSource(), newArtifact.Paths[dockerComposeContextPathType][0], composeFiles, ir.ContainerImages)
+			service.SourceTransformer = t.Config.Name
+			if len(composeFiles) != 0 {
+				service.Project = getComposeProjectName(filepath.Join(newArtifact.Paths[dockerComposeContextPathType][0], composeFiles[0]))
+			}
 			ir.Services[serviceConfig.ServiceName] = service
 			break
 		}
+		if metricsPaths := newArtifact.Paths[resourceMetricsPathType]; len(metricsPaths) != 0 {
+			for _, service := range ir.Services {
+				for ci := range service.Containers {
+					applyResourceMetrics(serviceConfig.ServiceName, &service.Containers[ci], metricsPaths, config.ServiceName, "")
+				}
+			}
+		}
 		if len(ir.ContainerImages) > 0 {
 			pathMappings = append(pathMappings, transformertypes.PathMapping{
 				Type:     transformertypes.SourcePathMappingType,
@@ -202,7 +324,8 @@ func (t *ComposeAnalyser) Transform(newArtifacts []transformertypes.Artifact, al
 			if contextPath == "" && dockerfilePath != common.DefaultDockerfileName {
 				contextPath = filepath.Dir(dockerfilePath)
 			}
-			createdArtifacts = append(createdArtifacts, transformertypes.Artifact{
+			devLoop.addArtifact(name, contextPath, dockerfilePath, watchRules)
+			dockerfileArtifact := transformertypes.Artifact{
 				Name: name,
 				Type: artifacts.DockerfileArtifactType,
 				Paths: map[transformertypes.PathType][]string{artifacts.DockerfilePathType: {dockerfilePath},
@@ -213,7 +336,15 @@ func (t *ComposeAnalyser) Transform(newArtifacts []transformertypes.Artifact, al
 						ImageName: name,
 					},
 				},
-			})
+			}
+			if len(containerImage.Build.BuildArgs) != 0 || containerImage.Build.Target != "" || len(containerImage.Build.Labels) != 0 {
+				dockerfileArtifact.Configs[artifacts.DockerfileBuildOptionsConfigType] = artifacts.DockerfileBuildOptions{
+					BuildArgs: containerImage.Build.BuildArgs,
+					Target:    containerImage.Build.Target,
+					Labels:    containerImage.Build.Labels,
+				}
+			}
+			createdArtifacts = append(createdArtifacts, dockerfileArtifact)
 		}
 		createdArtifact := transformertypes.Artifact{
 			Name:    t.Env.GetProjectName(),
@@ -222,15 +353,35 @@ func (t *ComposeAnalyser) Transform(newArtifacts []transformertypes.Artifact, al
 		}
 		createdArtifacts = append(createdArtifacts, createdArtifact)
 	}
+	if len(localCompose.Services) != 0 {
+		pathMapping, err := localCompose.write(t.Env.TempPath)
+		if err != nil {
+			logrus.Errorf("failed to write the compose file for local development. Error: %q", err)
+		} else {
+			pathMappings = append(pathMappings, pathMapping)
+		}
+	}
+	if len(devLoop.artifacts) != 0 {
+		pathMapping, err := devLoop.write(t.Env.TempPath, t.Env.GetProjectName())
+		if err != nil {
+			logrus.Errorf("failed to write the Skaffold and Tilt configs for the compose watch rules. Error: %q", err)
+		} else {
+			pathMappings = append(pathMappings, pathMapping)
+		}
+	}
 	return pathMappings, createdArtifacts, nil
 }
 
-func (t *ComposeAnalyser) getService(composeFilePath string, serviceName string, serviceImage string, relContextPath string, relDockerfilePath string, imageMetadataPaths map[string]string) transformertypes.Artifact {
+func (t *ComposeAnalyser) getService(composeFilePath string, overrideFilePaths []string, serviceName string, profiles []string, serviceImage string, relContextPath string, relDockerfilePath string, imageMetadataPaths map[string]string) transformertypes.Artifact {
+	composeFiles := []string{filepath.Base(composeFilePath)}
+	for _, overrideFilePath := range overrideFilePaths {
+		composeFiles = append(composeFiles, filepath.Base(overrideFilePath))
+	}
 	ct := transformertypes.Artifact{
-		Configs: map[transformertypes.ConfigType]interface{}{ComposeServiceConfigType: ComposeConfig{ServiceName: serviceName}, ComposeFileConfigType: []string{filepath.Base(composeFilePath)}},
+		Configs: map[transformertypes.ConfigType]interface{}{ComposeServiceConfigType: ComposeConfig{ServiceName: serviceName, Profiles: profiles}, ComposeFileConfigType: composeFiles},
 		Paths:   map[transformertypes.PathType][]string{dockerComposeContextPathType: {filepath.Dir(composeFilePath)}},
 	}
-	if imagepath, ok := imageMetadataPaths[serviceImage]; ok {
+	if imagepath, ok := imageMetadataPaths[getImageInfoKey(serviceImage)]; ok && serviceImage != "" {
 		ct.Paths[imageInfoPathType] = common.AppendIfNotPresent(ct.Paths[imageInfoPathType], imagepath)
 	}
 	logrus.Debugf("Found a docker compose service : %s", serviceName)
@@ -254,14 +405,33 @@ func (t *ComposeAnalyser) getService(composeFilePath string, serviceName string,
 	return ct
 }
 
-func (t *ComposeAnalyser) getServicesFromComposeFile(composeFilePath string, imageMetadataPaths map[string]string) map[string][]transformertypes.Artifact {
+// getServicesFromComposeFile returns the services of the compose file merged with all of its override files,
+// so that the services defined only in the override files are found too
+func (t *ComposeAnalyser) getServicesFromComposeFile(composeFilePath string, overrideFilePaths []string, imageMetadataPaths map[string]string) map[string][]transformertypes.Artifact {
 	services := map[string][]transformertypes.Artifact{}
+	// the services are detected using the override files merged by default during the transformation.
+	// All the override files are recorded in the artifacts, so that the others can be selected then.
+	defaultOverrideFilePaths := []string{}
+	for _, overrideFilePath := range overrideFilePaths {
+		if isDefaultOverrideFile(overrideFilePath) {
+			defaultOverrideFilePaths = append(defaultOverrideFilePaths, overrideFilePath)
+		}
+	}
 	// Try v3 first and if it fails try v1v2
-	dcV3, errV3 := parseV3(composeFilePath)
+	dcV3, errV3 := getParsedV3(composeFilePath, defaultOverrideFilePaths...)
 	if errV3 == nil {
 		logrus.Debugf("Found a docker compose file at path %s", composeFilePath)
+		profiles := []string{}
 		for _, service := range dcV3.Services {
-			services[service.Name] = []transformertypes.Artifact{t.getService(composeFilePath, service.Name, service.Image, service.Build.Context, service.Build.Dockerfile, imageMetadataPaths)}
+			serviceProfiles := getProfilesV3(service)
+			for _, profile := range serviceProfiles {
+				profiles = common.AppendIfNotPresent(profiles, profile)
+			}
+			services[service.Name] = []transformertypes.Artifact{t.getService(composeFilePath, overrideFilePaths, service.Name, serviceProfiles, service.Image, service.Build.Context, service.Build.Dockerfile, imageMetadataPaths)}
+		}
+		if len(profiles) != 0 {
+			sort.Strings(profiles)
+			logrus.Infof("Found the profiles %+v in the docker compose file at path %s", profiles, composeFilePath)
 		}
 		return services
 	}
@@ -270,18 +440,175 @@ func (t *ComposeAnalyser) getServicesFromComposeFile(composeFilePath string, ima
 		// With interpolation error v2 parser panics. This prevents the panic. TODO: Is this still relevant? https://github.com/compose-spec/compose-go
 		interpolate = false
 	}
-	dcV1V2, errV1V2 := parseV2(composeFilePath, interpolate)
+	dcV1V2, errV1V2 := getParsedV2(composeFilePath, interpolate, defaultOverrideFilePaths...)
 	if errV1V2 != nil {
 		logrus.Debugf("Failed to parse file at path %s as a docker compose file. Error V3: %q Error V1V2: %q", composeFilePath, errV3, errV1V2)
 		return services
 	}
 	logrus.Debugf("Found a docker compose file at path %s", composeFilePath)
 	for serviceName, serviceConfig := range dcV1V2.ServiceConfigs.All() {
-		services[serviceName] = []transformertypes.Artifact{t.getService(composeFilePath, serviceName, serviceConfig.Image, serviceConfig.Build.Context, serviceConfig.Build.Dockerfile, imageMetadataPaths)}
+		services[serviceName] = []transformertypes.Artifact{t.getService(composeFilePath, overrideFilePaths, serviceName, nil, serviceConfig.Image, serviceConfig.Build.Context, serviceConfig.Build.Dockerfile, imageMetadataPaths)}
 	}
 	return services
 }
 
+// selectOverrideFiles asks for the override files to merge into the compose file, in order.
+// Like docker compose, the .override file is used by default.
+func (t *ComposeAnalyser) selectOverrideFiles(composeFilePath string, overrideFileNames []string) []string {
+	if len(overrideFileNames) == 0 {
+		return nil
+	}
+	defaultOverrideFileNames := []string{}
+	for _, overrideFileName := range overrideFileNames {
+		if isDefaultOverrideFile(overrideFileName) {
+			defaultOverrideFileNames = append(defaultOverrideFileNames, overrideFileName)
+		}
+	}
+	relComposeFilePath, err := filepath.Rel(t.Env.GetEnvironmentSource(), composeFilePath)
+	if err != nil {
+		relComposeFilePath = filepath.Base(composeFilePath)
+	}
+	relComposeFilePath = filepath.ToSlash(relComposeFilePath)
+	selectedOverrideFileNames := qaengine.FetchMultiSelectAnswer(
+		common.JoinQASubKeys(common.ConfigComposeOverridesKey, `"`+relComposeFilePath+`"`),
+		fmt.Sprintf("Select the override files to merge into the compose file %s :", relComposeFilePath),
+		[]string{"The override files are merged in order, like using docker compose -f. By default docker compose only uses the .override file."},
+		defaultOverrideFileNames,
+		overrideFileNames,
+		nil,
+	)
+	overrideFilePaths := []string{}
+	for _, overrideFileName := range overrideFileNames {
+		if common.IsPresent(selectedOverrideFileNames, overrideFileName) {
+			overrideFilePaths = append(overrideFilePaths, filepath.Join(filepath.Dir(composeFilePath), overrideFileName))
+		}
+	}
+	return overrideFilePaths
+}
+
+// getComposeOverrideFiles returns the override files of each of the compose files, like docker-compose.override.yml and
+// docker-compose.prod.yml next to docker-compose.yml. The .override file comes first.
+func getComposeOverrideFiles(yamlPaths []string) map[string][]string {
+	overrideFiles := map[string][]string{}
+	for _, yamlPath := range yamlPaths {
+		stem := strings.TrimSuffix(filepath.Base(yamlPath), filepath.Ext(yamlPath))
+		if !common.IsPresent(composeFileStems, stem) {
+			continue
+		}
+		overrideFilePaths := []string{}
+		for _, otherPath := range yamlPaths {
+			if otherPath != yamlPath && filepath.Dir(otherPath) == filepath.Dir(yamlPath) &&
+				strings.HasPrefix(strings.TrimSuffix(filepath.Base(otherPath), filepath.Ext(otherPath)), stem+".") {
+				overrideFilePaths = append(overrideFilePaths, otherPath)
+			}
+		}
+		if len(overrideFilePaths) == 0 {
+			continue
+		}
+		sort.SliceStable(overrideFilePaths, func(i, j int) bool {
+			if isDefaultOverrideFile(overrideFilePaths[i]) != isDefaultOverrideFile(overrideFilePaths[j]) {
+				return isDefaultOverrideFile(overrideFilePaths[i])
+			}
+			return overrideFilePaths[i] < overrideFilePaths[j]
+		})
+		overrideFiles[yamlPath] = overrideFilePaths
+	}
+	return overrideFiles
+}
+
+// isDefaultOverrideFile returns true for the override files used by docker compose by default, like docker-compose.override.yml
+func isDefaultOverrideFile(overrideFilePath string) bool {
+	name := filepath.Base(overrideFilePath)
+	return strings.HasSuffix(strings.TrimSuffix(name, filepath.Ext(name)), "."+overrideFileSuffix)
+}
+
+// getProjectPrefixedServices returns the services of the compose project with their names prefixed with the project name
+func getProjectPrefixedServices(project string, services map[string][]transformertypes.Artifact) map[string][]transformertypes.Artifact {
+	prefixedServices := map[string][]transformertypes.Artifact{}
+	for serviceName, serviceArtifacts := range services {
+		for _, serviceArtifact := range serviceArtifacts {
+			if config, ok := serviceArtifact.Configs[ComposeServiceConfigType].(ComposeConfig); ok {
+				config.NamePrefix = project
+				serviceArtifact.Configs[ComposeServiceConfigType] = config
+			}
+		}
+		prefixedServices[getProjectPrefixedName(project, serviceName)] = serviceArtifacts
+	}
+	return prefixedServices
+}
+
+// getProjectPrefixedName returns the name prefixed with the project name, unless it already is
+func getProjectPrefixedName(project, name string) string {
+	if strings.HasPrefix(name, project+"-") {
+		return name
+	}
+	return common.MakeStringDNSNameCompliantWithoutDots(project + "-" + name)
+}
+
+// getPlannedServiceNames returns the names given during planning to the services of the compose file, keyed by the compose service names
+func getPlannedServiceNames(newArtifacts []transformertypes.Artifact, composeFilePath string) map[string]string {
+	serviceNames := map[string]string{}
+	for _, newArtifact := range newArtifacts {
+		config := ComposeConfig{}
+		if err := newArtifact.GetConfig(ComposeServiceConfigType, &config); err != nil {
+			continue
+		}
+		serviceConfig := artifacts.ServiceConfig{}
+		if err := newArtifact.GetConfig(artifacts.ServiceConfigType, &serviceConfig); err != nil || serviceConfig.ServiceName == "" {
+			continue
+		}
+		composeFiles := []string{}
+		if err := newArtifact.GetConfig(ComposeFileConfigType, &composeFiles); err != nil || len(composeFiles) == 0 || len(newArtifact.Paths[dockerComposeContextPathType]) == 0 {
+			continue
+		}
+		if filepath.Join(newArtifact.Paths[dockerComposeContextPathType][0], composeFiles[0]) == composeFilePath {
+			serviceNames[config.ServiceName] = serviceConfig.ServiceName
+		}
+	}
+	return serviceNames
+}
+
+// getActiveProfiles asks for the compose profiles to enable among the profiles of the services.
+// The profiles activated using COMPOSE_PROFILES are enabled by default.
+func getActiveProfiles(newArtifacts []transformertypes.Artifact) []string {
+	profiles := []string{}
+	defaultProfiles := []string{}
+	for _, newArtifact := range newArtifacts {
+		config := ComposeConfig{}
+		if err := newArtifact.GetConfig(ComposeServiceConfigType, &config); err != nil || len(config.Profiles) == 0 {
+			continue
+		}
+		for _, profile := range config.Profiles {
+			profiles = common.AppendIfNotPresent(profiles, profile)
+		}
+		composeFiles := []string{}
+		if err := newArtifact.GetConfig(ComposeFileConfigType, &composeFiles); err != nil || len(composeFiles) == 0 || len(newArtifact.Paths[dockerComposeContextPathType]) == 0 {
+			continue
+		}
+		for _, profile := range getDefaultActiveProfiles(filepath.Join(newArtifact.Paths[dockerComposeContextPathType][0], composeFiles[0])) {
+			if profile == "*" || common.IsPresent(config.Profiles, profile) {
+				defaultProfiles = common.AppendIfNotPresent(defaultProfiles, profile)
+			}
+		}
+	}
+	if len(profiles) == 0 {
+		return nil
+	}
+	sort.Strings(profiles)
+	if common.IsPresent(defaultProfiles, "*") {
+		defaultProfiles = profiles
+	}
+	sort.Strings(defaultProfiles)
+	return qaengine.FetchMultiSelectAnswer(
+		common.ConfigComposeProfilesKey,
+		"Select the compose profiles whose services should be converted :",
+		[]string{"The services without any profiles are always converted. The profiles set in " + composeProfilesEnv + " are selected by default."},
+		defaultProfiles,
+		profiles,
+		nil,
+	)
+}
+
 // newContainerFromImageInfo creates a new container from image info
 func newContainerFromImageInfo(i collecttypes.ImageInfo) irtypes.ContainerImage {
 	c := irtypes.NewContainer()
@@ -17,6 +17,7 @@
 package compose
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 
@@ -104,10 +105,9 @@ func (t *ComposeGenerator) Transform(newArtifacts []transformertypes.Artifact, a
 		ir.Name = a.Name
 		preprocessedIR, err := irpreprocessor.Preprocess(ir, clusterConfig)
 		if err != nil {
-			logrus.Errorf("Unable to prepreocess IR : %s", err)
-		} else {
-			ir = preprocessedIR
+			return nil, nil, fmt.Errorf("failed to preprocess the IR of the artifact '%s' . Error: %w", a.Name, err)
 		}
+		ir = preprocessedIR
 		logrus.Debugf("Starting Compose transform")
 		logrus.Debugf("Total services to be transformed : %d", len(ir.Services))
 		c := composeObj{
@@ -115,7 +115,8 @@ func (t *ComposeGenerator) Transform(newArtifacts []transformertypes.Artifact, a
 			Services: map[string]composetypes.ServiceConfig{},
 		}
 		var exposedPort uint32 = 8080
-		for _, service := range ir.Services {
+		for _, serviceName := range ir.GetSortedServiceNames() {
+			service := ir.Services[serviceName]
 			for _, container := range service.Containers {
 				ports := []composetypes.ServicePortConfig{}
 				for _, port := range container.Ports {
@@ -127,7 +128,8 @@ func (t *ComposeGenerator) Transform(newArtifacts []transformertypes.Artifact, a
 				}
 				env := composetypes.MappingWithEquals{}
 				for _, e := range container.Env {
-					env[e.Name] = &e.Value
+					value := e.Value
+					env[e.Name] = &value
 				}
 				serviceConfig := composetypes.ServiceConfig{
 					ContainerName: container.Name,
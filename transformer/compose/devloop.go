@@ -0,0 +1,324 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package compose
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cast"
+)
+
+const (
+	defaultDevLoopOutputPath = common.DeployDir + string(os.PathSeparator) + "dev"
+	devLoopK8sYamlsPath      = common.DeployDir + string(os.PathSeparator) + "yamls"
+	skaffoldFileName         = "skaffold.yaml"
+	tiltFileName             = "Tiltfile"
+	skaffoldAPIVersion       = "skaffold/v4beta6"
+	watchKey                 = "watch"
+	// watchSyncAction, watchSyncRestartAction and watchRebuildAction are the actions of the compose watch rules
+	watchSyncAction        = "sync"
+	watchSyncRestartAction = "sync+restart"
+	watchRebuildAction     = "rebuild"
+)
+
+// composeWatchRule is a rule in the develop.watch section of a compose service
+type composeWatchRule struct {
+	Action string
+	// Path is the absolute path of the watched files
+	Path   string
+	Target string
+}
+
+// devLoopArtifact is an image built from the sources along with the rules to update it during development
+type devLoopArtifact struct {
+	imageName      string
+	contextPath    string
+	dockerfilePath string
+	rules          []composeWatchRule
+}
+
+// devLoop generates the Skaffold and Tilt configs for live updating the containers from the compose watch rules
+type devLoop struct {
+	// sourceDir is the directory that gets copied to the source directory of the output
+	sourceDir string
+	// outputPath is the directory of the configs relative to the output directory
+	outputPath string
+	artifacts  []devLoopArtifact
+}
+
+type skaffoldConfig struct {
+	APIVersion string            `yaml:"apiVersion"`
+	Kind       string            `yaml:"kind"`
+	Metadata   map[string]string `yaml:"metadata"`
+	Build      struct {
+		Artifacts []skaffoldArtifact `yaml:"artifacts"`
+	} `yaml:"build"`
+	Manifests struct {
+		RawYaml []string `yaml:"rawYaml"`
+	} `yaml:"manifests"`
+	Deploy struct {
+		Kubectl map[string]interface{} `yaml:"kubectl"`
+	} `yaml:"deploy"`
+}
+
+type skaffoldArtifact struct {
+	Image   string `yaml:"image"`
+	Context string `yaml:"context"`
+	Docker  struct {
+		Dockerfile string `yaml:"dockerfile"`
+	} `yaml:"docker"`
+	Sync *skaffoldSync `yaml:"sync,omitempty"`
+}
+
+type skaffoldSync struct {
+	Manual []skaffoldSyncRule `yaml:"manual"`
+}
+
+type skaffoldSyncRule struct {
+	Src   string `yaml:"src"`
+	Dest  string `yaml:"dest"`
+	Strip string `yaml:"strip,omitempty"`
+}
+
+func newDevLoop(sourceDir string, outputPath string) *devLoop {
+	return &devLoop{sourceDir: sourceDir, outputPath: outputPath}
+}
+
+// getComposeWatchRules returns the watch rules of the service in the version 3 compose file merged with its override files
+func getComposeWatchRules(composeFilePath string, serviceName string, overrideFilePaths ...string) []composeWatchRule {
+	config, err := getParsedV3(composeFilePath, overrideFilePaths...)
+	if err != nil {
+		logrus.Debugf("the file %s is not a version 3 compose file . Error: %q", composeFilePath, err)
+		return nil
+	}
+	for _, service := range config.Services {
+		if service.Name == serviceName {
+			return parseComposeWatchRules(filepath.Dir(composeFilePath), serviceName, service.Extras[developKey])
+		}
+	}
+	return nil
+}
+
+// parseComposeWatchRules parses the develop section of a service, making the watched paths absolute
+func parseComposeWatchRules(composeFileDir string, serviceName string, develop interface{}) []composeWatchRule {
+	developMap, ok := develop.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	watch, ok := developMap[watchKey].([]interface{})
+	if !ok {
+		return nil
+	}
+	rules := []composeWatchRule{}
+	for _, val := range watch {
+		vals, ok := val.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		rule := composeWatchRule{
+			Action: cast.ToString(vals["action"]),
+			Path:   cast.ToString(vals["path"]),
+			Target: cast.ToString(vals["target"]),
+		}
+		if rule.Path == "" {
+			logrus.Warnf("Ignoring a watch rule without a path in the service %s", serviceName)
+			continue
+		}
+		if !filepath.IsAbs(rule.Path) {
+			rule.Path = filepath.Join(composeFileDir, rule.Path)
+		}
+		switch rule.Action {
+		case watchSyncAction, watchSyncRestartAction:
+			if rule.Target == "" {
+				logrus.Warnf("Ignoring the %s watch rule for the path %s without a target in the service %s", rule.Action, rule.Path, serviceName)
+				continue
+			}
+		case watchRebuildAction:
+		default:
+			logrus.Warnf("Ignoring the watch rule for the path %s with the unsupported action '%s' in the service %s", rule.Path, rule.Action, serviceName)
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// addArtifact adds an image built from the sources along with its watch rules
+func (dl *devLoop) addArtifact(imageName, contextPath, dockerfilePath string, rules []composeWatchRule) {
+	if len(rules) == 0 {
+		return
+	}
+	if !common.IsParent(contextPath, dl.sourceDir) {
+		logrus.Debugf("not adding the image %s to the dev loop configs since its build context %s is not in the sources", imageName, contextPath)
+		return
+	}
+	dl.artifacts = append(dl.artifacts, devLoopArtifact{imageName: imageName, contextPath: contextPath, dockerfilePath: dockerfilePath, rules: rules})
+	sort.Slice(dl.artifacts, func(i, j int) bool { return dl.artifacts[i].imageName < dl.artifacts[j].imageName })
+}
+
+// getLocalPath returns the path relative to the dev loop configs in the output
+func (dl *devLoop) getLocalPath(srcPath string) (string, error) {
+	relPath, err := filepath.Rel(dl.sourceDir, srcPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to make the path %s relative to the source directory %s . Error: %w", srcPath, dl.sourceDir, err)
+	}
+	localPath, err := filepath.Rel(dl.outputPath, filepath.Join(common.DefaultSourceDir, relPath))
+	if err != nil {
+		return "", fmt.Errorf("failed to make the path %s relative to the directory %s . Error: %w", relPath, dl.outputPath, err)
+	}
+	return common.GetUnixPath(localPath), nil
+}
+
+// getSkaffoldConfig returns the Skaffold config that syncs the watched files into the running containers
+func (dl *devLoop) getSkaffoldConfig(projectName string) (skaffoldConfig, error) {
+	config := skaffoldConfig{
+		APIVersion: skaffoldAPIVersion,
+		Kind:       "Config",
+		Metadata:   map[string]string{"name": projectName},
+	}
+	yamlsPath, err := filepath.Rel(dl.outputPath, devLoopK8sYamlsPath)
+	if err != nil {
+		return config, fmt.Errorf("failed to make the path %s relative to the directory %s . Error: %w", devLoopK8sYamlsPath, dl.outputPath, err)
+	}
+	config.Manifests.RawYaml = []string{path.Join(common.GetUnixPath(yamlsPath), "*.yaml")}
+	config.Deploy.Kubectl = map[string]interface{}{}
+	for _, artifact := range dl.artifacts {
+		context, err := dl.getLocalPath(artifact.contextPath)
+		if err != nil {
+			return config, err
+		}
+		skaffoldArtifact := skaffoldArtifact{Image: artifact.imageName, Context: context}
+		if dockerfile, err := filepath.Rel(artifact.contextPath, artifact.dockerfilePath); err == nil {
+			skaffoldArtifact.Docker.Dockerfile = common.GetUnixPath(dockerfile)
+		} else {
+			skaffoldArtifact.Docker.Dockerfile = common.DefaultDockerfileName
+		}
+		sync := skaffoldSync{}
+		for _, rule := range artifact.rules {
+			// Skaffold rebuilds the image when any file outside the sync rules changes
+			if rule.Action == watchRebuildAction {
+				continue
+			}
+			relPath, err := filepath.Rel(artifact.contextPath, rule.Path)
+			if err != nil || relPath == ".." || strings.HasPrefix(relPath, ".."+string(os.PathSeparator)) {
+				logrus.Warnf("Skaffold can only sync the files in the build context %s . Ignoring the watch rule for the path %s", artifact.contextPath, rule.Path)
+				continue
+			}
+			sync.Manual = append(sync.Manual, getSkaffoldSyncRule(common.GetUnixPath(relPath), rule))
+		}
+		if len(sync.Manual) != 0 {
+			skaffoldArtifact.Sync = &sync
+		}
+		config.Build.Artifacts = append(config.Build.Artifacts, skaffoldArtifact)
+	}
+	return config, nil
+}
+
+// getSkaffoldSyncRule returns the Skaffold rule that copies the watched path, relative to the build context, to the target
+func getSkaffoldSyncRule(relPath string, rule composeWatchRule) skaffoldSyncRule {
+	if finfo, err := os.Stat(rule.Path); err == nil && !finfo.IsDir() {
+		syncRule := skaffoldSyncRule{Src: relPath, Dest: path.Dir(rule.Target)}
+		if dir := path.Dir(relPath); dir != "." {
+			syncRule.Strip = dir + "/"
+		}
+		return syncRule
+	}
+	if relPath == "." {
+		return skaffoldSyncRule{Src: "**", Dest: rule.Target}
+	}
+	return skaffoldSyncRule{Src: relPath + "/**", Dest: rule.Target, Strip: relPath + "/"}
+}
+
+// getTiltfile returns the Tiltfile that live updates the running containers with the watched files
+func (dl *devLoop) getTiltfile() (string, error) {
+	yamlsPath, err := filepath.Rel(dl.outputPath, devLoopK8sYamlsPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to make the path %s relative to the directory %s . Error: %w", devLoopK8sYamlsPath, dl.outputPath, err)
+	}
+	tiltfile := strings.Builder{}
+	tiltfile.WriteString(fmt.Sprintf("k8s_yaml(listdir('%s'))\n", common.GetUnixPath(yamlsPath)))
+	for _, artifact := range dl.artifacts {
+		context, err := dl.getLocalPath(artifact.contextPath)
+		if err != nil {
+			return "", err
+		}
+		dockerfile, err := dl.getLocalPath(artifact.dockerfilePath)
+		if err != nil {
+			return "", err
+		}
+		// the fall back rules have to come before the sync rules
+		fallBackPaths, syncSteps := []string{}, []string{}
+		for _, rule := range artifact.rules {
+			localPath, err := dl.getLocalPath(rule.Path)
+			if err != nil {
+				return "", err
+			}
+			if rule.Action == watchRebuildAction {
+				fallBackPaths = append(fallBackPaths, fmt.Sprintf("'%s'", localPath))
+				continue
+			}
+			syncSteps = append(syncSteps, fmt.Sprintf("        sync('%s', '%s'),\n", localPath, rule.Target))
+			if rule.Action == watchSyncRestartAction {
+				logrus.Infof("Tilt does not restart the containers of the image %s after syncing the path %s . Use the restart_process extension if required.", artifact.imageName, rule.Path)
+			}
+		}
+		tiltfile.WriteString(fmt.Sprintf("\ndocker_build(\n    '%s',\n    '%s',\n    dockerfile='%s',\n    live_update=[\n", artifact.imageName, context, dockerfile))
+		if len(fallBackPaths) != 0 {
+			tiltfile.WriteString(fmt.Sprintf("        fall_back_on([%s]),\n", strings.Join(fallBackPaths, ", ")))
+		}
+		for _, syncStep := range syncSteps {
+			tiltfile.WriteString(syncStep)
+		}
+		tiltfile.WriteString("    ],\n)\n")
+	}
+	return tiltfile.String(), nil
+}
+
+// write writes the Skaffold and Tilt configs to the temporary directory and returns the path mapping that copies them to the output
+func (dl *devLoop) write(tempPath string, projectName string) (transformertypes.PathMapping, error) {
+	absOutputPath := filepath.Join(tempPath, dl.outputPath)
+	if err := os.MkdirAll(absOutputPath, common.DefaultDirectoryPermission); err != nil {
+		return transformertypes.PathMapping{}, fmt.Errorf("failed to create the directory %s . Error: %w", absOutputPath, err)
+	}
+	skaffold, err := dl.getSkaffoldConfig(projectName)
+	if err != nil {
+		return transformertypes.PathMapping{}, fmt.Errorf("failed to create the Skaffold config. Error: %w", err)
+	}
+	if err := common.WriteYaml(filepath.Join(absOutputPath, skaffoldFileName), skaffold); err != nil {
+		return transformertypes.PathMapping{}, fmt.Errorf("failed to write the Skaffold config to the directory %s . Error: %w", absOutputPath, err)
+	}
+	tiltfile, err := dl.getTiltfile()
+	if err != nil {
+		return transformertypes.PathMapping{}, fmt.Errorf("failed to create the Tiltfile. Error: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(absOutputPath, tiltFileName), []byte(tiltfile), common.DefaultFilePermission); err != nil {
+		return transformertypes.PathMapping{}, fmt.Errorf("failed to write the Tiltfile to the directory %s . Error: %w", absOutputPath, err)
+	}
+	return transformertypes.PathMapping{
+		Type:     transformertypes.DefaultPathMappingType,
+		SrcPath:  absOutputPath,
+		DestPath: dl.outputPath,
+	}, nil
+}
@@ -0,0 +1,107 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package compose
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+)
+
+func TestDevLoop(t *testing.T) {
+	sourceDir := t.TempDir()
+	composeFilePath := filepath.Join(sourceDir, "app", "docker-compose.yaml")
+	if err := os.MkdirAll(filepath.Join(sourceDir, "app", "web", "src"), common.DefaultDirectoryPermission); err != nil {
+		t.Fatalf("failed to create the directory. Error: %q", err)
+	}
+	if err := os.WriteFile(filepath.Join(sourceDir, "app", "web", "package.json"), []byte("{}"), common.DefaultFilePermission); err != nil {
+		t.Fatalf("failed to write the package.json. Error: %q", err)
+	}
+	composeFile := `version: "3.8"
+services:
+  web:
+    build: ./web
+    develop:
+      watch:
+        - action: sync
+          path: ./web/src
+          target: /app/src
+        - action: rebuild
+          path: ./web/package.json
+        - action: sync+restart
+          path: ./web/package.json
+          target: /app/package.json
+        - action: unknown
+          path: ./web
+`
+	if err := os.WriteFile(composeFilePath, []byte(composeFile), common.DefaultFilePermission); err != nil {
+		t.Fatalf("failed to write the compose file. Error: %q", err)
+	}
+	rules := getComposeWatchRules(composeFilePath, "web")
+	if len(rules) != 3 {
+		t.Fatalf("expected the rule with the unknown action to be ignored. Actual: %+v", rules)
+	}
+	dl := newDevLoop(sourceDir, defaultDevLoopOutputPath)
+	contextPath := filepath.Join(sourceDir, "app", "web")
+	dl.addArtifact("web", contextPath, filepath.Join(contextPath, common.DefaultDockerfileName), rules)
+
+	skaffold, err := dl.getSkaffoldConfig("myproject")
+	if err != nil {
+		t.Fatalf("failed to get the Skaffold config. Error: %q", err)
+	}
+	if diff := cmp.Diff([]string{"../yamls/*.yaml"}, skaffold.Manifests.RawYaml); diff != "" {
+		t.Fatalf("wrong manifests. Differences:\n%s", diff)
+	}
+	if len(skaffold.Build.Artifacts) != 1 {
+		t.Fatalf("expected a single artifact. Actual: %+v", skaffold.Build.Artifacts)
+	}
+	artifact := skaffold.Build.Artifacts[0]
+	if artifact.Context != "../../source/app/web" || artifact.Docker.Dockerfile != "Dockerfile" {
+		t.Fatalf("wrong build context or Dockerfile. Actual: %+v", artifact)
+	}
+	want := &skaffoldSync{Manual: []skaffoldSyncRule{
+		{Src: "src/**", Dest: "/app/src", Strip: "src/"},
+		{Src: "package.json", Dest: "/app"},
+	}}
+	if diff := cmp.Diff(want, artifact.Sync); diff != "" {
+		t.Fatalf("wrong sync rules. Differences:\n%s", diff)
+	}
+
+	tiltfile, err := dl.getTiltfile()
+	if err != nil {
+		t.Fatalf("failed to get the Tiltfile. Error: %q", err)
+	}
+	wantTiltfile := `k8s_yaml(listdir('../yamls'))
+
+docker_build(
+    'web',
+    '../../source/app/web',
+    dockerfile='../../source/app/web/Dockerfile',
+    live_update=[
+        fall_back_on(['../../source/app/web/package.json']),
+        sync('../../source/app/web/src', '/app/src'),
+        sync('../../source/app/web/package.json', '/app/package.json'),
+    ],
+)
+`
+	if diff := cmp.Diff(wantTiltfile, tiltfile); diff != "" {
+		t.Fatalf("wrong Tiltfile. Differences:\n%s", diff)
+	}
+}
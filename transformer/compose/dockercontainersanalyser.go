@@ -0,0 +1,281 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package compose
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/konveyor/move2kube/types/transformer/artifacts"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cast"
+	core "k8s.io/kubernetes/pkg/apis/core"
+	"k8s.io/kubernetes/pkg/apis/networking"
+)
+
+const (
+	// DockerContainerConfigType represents the config type of a container running in a docker daemon
+	DockerContainerConfigType transformertypes.ConfigType = "DockerContainer"
+)
+
+const (
+	// dockerContainersPathType defines the path type of the file containing the collected docker containers
+	dockerContainersPathType transformertypes.PathType = "DockerContainers"
+	// dockerComposeLabelPrefix is the prefix of the labels docker compose adds to the containers it creates
+	dockerComposeLabelPrefix = "com.docker.compose."
+)
+
+// DockerContainersAnalyser implements Transformer interface
+type DockerContainersAnalyser struct {
+	Config                transformertypes.Transformer
+	Env                   *environment.Environment
+	ComposeAnalyzerConfig *ComposeAnalyzerConfig
+}
+
+// DockerContainerConfig stores the config for a container running in a docker daemon
+type DockerContainerConfig struct {
+	ContainerName string `yaml:"containerName,omitempty"`
+}
+
+// Init Initializes the transformer
+func (t *DockerContainersAnalyser) Init(tc transformertypes.Transformer, env *environment.Environment) (err error) {
+	t.Config = tc
+	t.Env = env
+	t.ComposeAnalyzerConfig = &ComposeAnalyzerConfig{}
+	if err := common.GetObjFromInterface(t.Config.Spec.Config, t.ComposeAnalyzerConfig); err != nil {
+		return fmt.Errorf("unable to load config for Transformer %+v into %T . Error: %q", t.Config.Spec.Config, t.ComposeAnalyzerConfig, err)
+	}
+	return nil
+}
+
+// GetConfig returns the config
+func (t *DockerContainersAnalyser) GetConfig() (transformertypes.Transformer, *environment.Environment) {
+	return t.Config, t.Env
+}
+
+// DirectoryDetect detects the docker containers collected from a docker daemon
+func (t *DockerContainersAnalyser) DirectoryDetect(dir string) (map[string][]transformertypes.Artifact, error) {
+	yamlPaths, err := common.GetFilesByExt(dir, []string{".yaml", ".yml"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch yaml files at path '%s' . Error: %w", dir, err)
+	}
+	services := map[string][]transformertypes.Artifact{}
+	resourceMetrics := getDockerResourceMetrics(yamlPaths)
+	for _, yamlPath := range yamlPaths {
+		containers := collecttypes.DockerContainers{}
+		if err := common.ReadMove2KubeYaml(yamlPath, &containers); err != nil || containers.Kind != string(collecttypes.DockerContainersMetadataKind) {
+			continue
+		}
+		for _, container := range containers.Spec.Containers {
+			// the service names are normalized, without merging distinct containers, once all the services have been found
+			serviceName := container.Name
+			logrus.Debugf("Found a docker container : %s", container.Name)
+			paths := map[transformertypes.PathType][]string{dockerContainersPathType: {yamlPath}}
+			if metricsPaths := getResourceMetricsPaths(resourceMetrics, "", container.Name); len(metricsPaths) != 0 {
+				paths[resourceMetricsPathType] = metricsPaths
+			}
+			services[serviceName] = append(services[serviceName], transformertypes.Artifact{
+				Configs: map[transformertypes.ConfigType]interface{}{DockerContainerConfigType: DockerContainerConfig{ContainerName: container.Name}},
+				Paths:   paths,
+			})
+		}
+	}
+	return services, nil
+}
+
+// Transform transforms the artifacts
+func (t *DockerContainersAnalyser) Transform(newArtifacts []transformertypes.Artifact, alreadySeenArtifacts []transformertypes.Artifact) ([]transformertypes.PathMapping, []transformertypes.Artifact, error) {
+	createdArtifacts := []transformertypes.Artifact{}
+	for _, newArtifact := range newArtifacts {
+		config := DockerContainerConfig{}
+		if err := newArtifact.GetConfig(DockerContainerConfigType, &config); err != nil {
+			logrus.Errorf("failed to load config for Transformer into %T . Error: %q", config, err)
+			continue
+		}
+		serviceConfig := artifacts.ServiceConfig{}
+		if err := newArtifact.GetConfig(artifacts.ServiceConfigType, &serviceConfig); err != nil {
+			logrus.Errorf("failed to load config for Transformer into %T . Error: %q", serviceConfig, err)
+			continue
+		}
+		if len(newArtifact.Paths[dockerContainersPathType]) == 0 {
+			logrus.Errorf("the artifact for the docker container '%s' does not have the path to the collected containers", config.ContainerName)
+			continue
+		}
+		containersPath := newArtifact.Paths[dockerContainersPathType][0]
+		containers := collecttypes.DockerContainers{}
+		if err := common.ReadMove2KubeYaml(containersPath, &containers); err != nil {
+			logrus.Errorf("failed to read the docker containers yaml at path '%s' . Error: %q", containersPath, err)
+			continue
+		}
+		ir := irtypes.NewIR()
+		for _, container := range containers.Spec.Containers {
+			if container.Name != config.ContainerName {
+				continue
+			}
+			service, storages := t.convertToIRService(filepath.Dir(containersPath), serviceConfig.ServiceName, container)
+			for ci := range service.Containers {
+				applyResourceMetrics(serviceConfig.ServiceName, &service.Containers[ci], newArtifact.Paths[resourceMetricsPathType], "", container.Name)
+			}
+			ir.Services[serviceConfig.ServiceName] = service
+			for _, storage := range storages {
+				ir.AddStorage(storage)
+			}
+			break
+		}
+		if len(ir.Services) == 0 {
+			logrus.Errorf("the docker container '%s' was not found in the file at path '%s'", config.ContainerName, containersPath)
+			continue
+		}
+		createdArtifacts = append(createdArtifacts, transformertypes.Artifact{
+			Name:    t.Env.GetProjectName(),
+			Type:    irtypes.IRArtifactType,
+			Configs: map[transformertypes.ConfigType]interface{}{irtypes.IRConfigType: ir},
+		})
+	}
+	return nil, createdArtifacts, nil
+}
+
+// convertToIRService converts a running docker container into an IR service
+func (t *DockerContainersAnalyser) convertToIRService(filedir string, serviceName string, container collecttypes.DockerContainer) (irtypes.Service, []irtypes.Storage) {
+	serviceConfig := irtypes.NewServiceWithName(serviceName)
+	serviceContainer := core.Container{
+		Name:       common.NormalizeForMetadataName(container.Name),
+		Image:      container.Image,
+		Command:    container.Entrypoint,
+		Args:       container.Cmd,
+		WorkingDir: container.WorkingDir,
+	}
+	for _, env := range container.Env {
+		nameAndValue := strings.SplitN(env, "=", 2)
+		envVar := core.EnvVar{Name: nameAndValue[0]}
+		if len(nameAndValue) == 2 {
+			envVar.Value = nameAndValue[1]
+		}
+		serviceContainer.Env = append(serviceContainer.Env, envVar)
+	}
+	for _, port := range container.Ports {
+		protocol := core.ProtocolTCP
+		if strings.EqualFold(string(core.ProtocolUDP), port.Protocol) {
+			protocol = core.ProtocolUDP
+		}
+		serviceContainer.Ports = append(serviceContainer.Ports, core.ContainerPort{ContainerPort: port.ContainerPort, Protocol: protocol})
+		servicePort := port.HostPort
+		if servicePort == 0 {
+			servicePort = port.ContainerPort
+		}
+		serviceConfig.AddPortForwarding(networking.ServiceBackendPort{Number: servicePort}, networking.ServiceBackendPort{Number: port.ContainerPort}, "")
+	}
+	securityContext := &core.SecurityContext{}
+	if container.Privileged {
+		securityContext.Privileged = &container.Privileged
+	}
+	if container.User != "" {
+		uid, err := cast.ToInt64E(strings.Split(container.User, ":")[0])
+		if err != nil {
+			logrus.Warnf("Ignoring the user '%s' of the container '%s' . User to be specified as a UID (numeric).", container.User, container.Name)
+		} else {
+			securityContext.RunAsUser = &uid
+		}
+	}
+	if len(container.CapAdd) > 0 || len(container.CapDrop) > 0 {
+		securityContext.Capabilities = &core.Capabilities{}
+		for _, capAdd := range container.CapAdd {
+			securityContext.Capabilities.Add = append(securityContext.Capabilities.Add, core.Capability(capAdd))
+		}
+		for _, capDrop := range container.CapDrop {
+			securityContext.Capabilities.Drop = append(securityContext.Capabilities.Drop, core.Capability(capDrop))
+		}
+	}
+	if *securityContext != (core.SecurityContext{}) {
+		serviceContainer.SecurityContext = securityContext
+	}
+	if container.Hostname != "" {
+		serviceConfig.Hostname = container.Hostname
+	}
+	for key, value := range container.Labels {
+		if strings.HasPrefix(key, dockerComposeLabelPrefix) {
+			continue
+		}
+		if serviceConfig.Annotations == nil {
+			serviceConfig.Annotations = map[string]string{}
+		}
+		serviceConfig.Annotations[key] = value
+	}
+	switch container.RestartPolicy {
+	case "on-failure":
+		serviceConfig.RestartPolicy = core.RestartPolicyOnFailure
+	case "always", "unless-stopped":
+		serviceConfig.RestartPolicy = core.RestartPolicyAlways
+	}
+	if t.ComposeAnalyzerConfig.EnableNetworkParsing {
+		for _, network := range container.Networks {
+			if network == "bridge" || network == "host" || network == "none" {
+				continue
+			}
+			serviceConfig.Networks = append(serviceConfig.Networks, network)
+		}
+	}
+	storages := []irtypes.Storage{}
+	storageMap := map[string]bool{}
+	tmpfsDestinations := []string{}
+	for _, mount := range container.Mounts {
+		volSource := mount.Name
+		switch mount.Type {
+		case "tmpfs":
+			tmpfsDestinations = append(tmpfsDestinations, mount.Destination)
+			continue
+		case "bind":
+			volSource = mount.Source
+		}
+		volMode := modeReadWrite
+		if mount.ReadOnly {
+			volMode = modeReadOnly
+		}
+		volumeMount, volume, storage, err := applyVolumePolicy(filedir, serviceName, volSource, mount.Destination, volMode, storageMap)
+		if err != nil {
+			logrus.Errorf("Could not create storage: [%s]", err)
+			continue
+		}
+		if volumeMount != nil {
+			serviceContainer.VolumeMounts = append(serviceContainer.VolumeMounts, *volumeMount)
+		}
+		if volume != nil {
+			if volume.HostPath != nil {
+				// the bind mount refers to a path on the docker host and not in the collected source
+				volume.HostPath.Path = mount.Source
+			}
+			serviceConfig.AddVolume(*volume)
+		}
+		if storage != nil && storage.Name != "" {
+			storages = append(storages, *storage)
+			storageMap[storage.Name] = true
+		}
+	}
+	volumeMounts, volumes := makeVolumesFromTmpFS(serviceName, tmpfsDestinations)
+	for _, volume := range volumes {
+		serviceConfig.AddVolume(volume)
+	}
+	serviceContainer.VolumeMounts = append(serviceContainer.VolumeMounts, volumeMounts...)
+	serviceConfig.Containers = []core.Container{serviceContainer}
+	return serviceConfig, storages
+}
@@ -0,0 +1,357 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package compose
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/cli/cli/compose/loader"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/common/deepcopy"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cast"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// extendsKey is the key of a service containing the service it extends, which can be in another compose file
+	extendsKey = "extends"
+	// extendsServiceKey and extendsFileKey are the keys of extends containing the extended service and its compose file
+	extendsServiceKey = "service"
+	extendsFileKey    = "file"
+)
+
+var (
+	// extendsMappingKeys are the keys whose values are merged with the values of the extended service, with the keys of the service
+	// taking precedence. They can be mappings or lists of key=value pairs.
+	extendsMappingKeys = []string{"environment", "labels", annotationsKey, "sysctls", "args"}
+	// extendsSequenceKeys are the keys whose values are appended to the values of the extended service
+	extendsSequenceKeys = []string{"ports", "expose", "dns", "dns_search", "dns_opt", "tmpfs", "cap_add", "cap_drop", envFile, "secrets", "configs", "security_opt", "external_links", "extra_hosts", "group_add"}
+	// extendsMountKeys are the keys whose values are merged with the values of the extended service by the mount path in the container
+	extendsMountKeys = []string{"volumes", "devices"}
+	// extendsNotSharedKeys are the keys of the extended service which are not inherited, since they introduce dependencies on other services
+	extendsNotSharedKeys = []string{"links", "volumes_from", dependsOnKey}
+)
+
+// resolveExtends merges the services extended using extends, from the same compose file or from other files, into the services
+// extending them and removes the extends, since the parsers do not support them or lose some of the inherited keys
+func resolveExtends(path string, parsedComposeFile map[string]interface{}) (map[string]interface{}, error) {
+	services := getComposeFileServices(parsedComposeFile)
+	for serviceName := range services {
+		service, err := getExtendedService(path, services, serviceName, nil)
+		if err != nil {
+			return parsedComposeFile, err
+		}
+		services[serviceName] = service
+	}
+	return parsedComposeFile, nil
+}
+
+// resolveExtendsV2 resolves the extends in the version 1 or 2 compose file. The contents are returned as is if there are no extends.
+func resolveExtendsV2(path string, fileData []byte) ([]byte, error) {
+	parsedComposeFile, err := loader.ParseYAML(fileData)
+	if err != nil {
+		// let the parser report the error
+		return fileData, nil
+	}
+	found := false
+	for _, val := range getComposeFileServices(parsedComposeFile) {
+		if vals, ok := val.(map[string]interface{}); ok {
+			if _, ok := vals[extendsKey]; ok {
+				found = true
+				break
+			}
+		}
+	}
+	if !found {
+		return fileData, nil
+	}
+	if parsedComposeFile, err = resolveExtends(path, parsedComposeFile); err != nil {
+		return fileData, err
+	}
+	return yaml.Marshal(parsedComposeFile)
+}
+
+// mergeComposeFiles merges the override file into the compose file. The services are merged like the extended services,
+// and the volumes, networks, secrets and configs with the same names are replaced.
+func mergeComposeFiles(parsedComposeFile, overrideComposeFile map[string]interface{}) map[string]interface{} {
+	for key, val := range overrideComposeFile {
+		overrides, ok := val.(map[string]interface{})
+		if !ok {
+			parsedComposeFile[key] = val
+			continue
+		}
+		existing, ok := parsedComposeFile[key].(map[string]interface{})
+		if !ok {
+			parsedComposeFile[key] = overrides
+			continue
+		}
+		for name, override := range overrides {
+			existingVal, existingOk := existing[name].(map[string]interface{})
+			overrideVal, overrideOk := override.(map[string]interface{})
+			if key == "services" && existingOk && overrideOk {
+				existing[name] = mergeExtendedService(existingVal, overrideVal)
+				continue
+			}
+			existing[name] = override
+		}
+	}
+	return parsedComposeFile
+}
+
+// getComposeFileServices returns the services of the compose file, which are at the top level in the version 1 compose files
+func getComposeFileServices(parsedComposeFile map[string]interface{}) map[string]interface{} {
+	if services, ok := parsedComposeFile["services"].(map[string]interface{}); ok {
+		return services
+	}
+	if _, ok := parsedComposeFile["version"]; ok {
+		return nil
+	}
+	return parsedComposeFile
+}
+
+// getExtendedService returns the service of the compose file merged with the services it extends
+func getExtendedService(path string, services map[string]interface{}, serviceName string, extendedBy []string) (map[string]interface{}, error) {
+	serviceID := path + ":" + serviceName
+	if common.IsPresent(extendedBy, serviceID) {
+		return nil, fmt.Errorf("the service %s in the file %s extends itself through %+v", serviceName, path, extendedBy)
+	}
+	service, ok := services[serviceName].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("failed to find the service %s to extend in the file %s", serviceName, path)
+	}
+	extends, ok := service[extendsKey]
+	if !ok {
+		return service, nil
+	}
+	baseServiceName, baseFile := "", ""
+	switch extends := extends.(type) {
+	case string:
+		baseServiceName = extends
+	case map[string]interface{}:
+		baseServiceName = cast.ToString(extends[extendsServiceKey])
+		baseFile = cast.ToString(extends[extendsFileKey])
+	}
+	if baseServiceName == "" {
+		return nil, fmt.Errorf("the service %s in the file %s does not specify the service it extends", serviceName, path)
+	}
+	basePath := path
+	baseServices := services
+	if baseFile != "" {
+		basePath = baseFile
+		if !filepath.IsAbs(basePath) {
+			basePath = filepath.Join(filepath.Dir(path), basePath)
+		}
+		data, err := readComposeFile(basePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the file %s extended by the service %s in the file %s . Error: %w", basePath, serviceName, path, err)
+		}
+		baseComposeFile, err := loader.ParseYAML(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the file %s extended by the service %s in the file %s . Error: %w", basePath, serviceName, path, err)
+		}
+		baseServices = getComposeFileServices(baseComposeFile)
+	}
+	baseService, err := getExtendedService(basePath, baseServices, baseServiceName, append(extendedBy, serviceID))
+	if err != nil {
+		return nil, err
+	}
+	baseService = deepcopy.DeepCopy(baseService).(map[string]interface{})
+	for _, key := range extendsNotSharedKeys {
+		if _, ok := baseService[key]; ok {
+			logrus.Warnf("The service %s in the file %s does not inherit the %s of the service %s it extends", serviceName, path, key, baseServiceName)
+			delete(baseService, key)
+		}
+	}
+	if filepath.Dir(basePath) != filepath.Dir(path) {
+		rebaseServicePaths(filepath.Dir(basePath), filepath.Dir(path), baseService)
+	}
+	// the service is not changed, since the other services extending it have to see its extends too
+	overrides := map[string]interface{}{}
+	for key, val := range service {
+		if key != extendsKey {
+			overrides[key] = val
+		}
+	}
+	logrus.Debugf("The service %s in the file %s extends the service %s in the file %s", serviceName, path, baseServiceName, basePath)
+	return mergeExtendedService(baseService, overrides), nil
+}
+
+// mergeExtendedService merges the keys of the service into the service it extends
+func mergeExtendedService(baseService, service map[string]interface{}) map[string]interface{} {
+	for key, val := range service {
+		baseVal, ok := baseService[key]
+		if !ok {
+			baseService[key] = val
+			continue
+		}
+		switch {
+		case common.IsPresent(extendsMappingKeys, key):
+			baseMapping, baseOk := getExtendsMapping(baseVal)
+			mapping, ok := getExtendsMapping(val)
+			if !baseOk || !ok {
+				baseService[key] = val
+				continue
+			}
+			for k, v := range mapping {
+				baseMapping[k] = v
+			}
+			baseService[key] = baseMapping
+		case common.IsPresent(extendsSequenceKeys, key):
+			baseService[key] = appendUnique(toSequence(baseVal), toSequence(val))
+		case common.IsPresent(extendsMountKeys, key):
+			baseSequence, sequence := toSequence(baseVal), toSequence(val)
+			targets := []string{}
+			for _, mount := range sequence {
+				targets = append(targets, getMountTarget(mount))
+			}
+			mounts := []interface{}{}
+			for _, mount := range baseSequence {
+				if !common.IsPresent(targets, getMountTarget(mount)) {
+					mounts = append(mounts, mount)
+				}
+			}
+			baseService[key] = append(mounts, sequence...)
+		default:
+			baseMap, baseOk := baseVal.(map[string]interface{})
+			valMap, ok := val.(map[string]interface{})
+			if baseOk && ok {
+				baseService[key] = mergeExtendedService(baseMap, valMap)
+				continue
+			}
+			// the scalars and the sequences like the command are overridden
+			baseService[key] = val
+		}
+	}
+	return baseService
+}
+
+// rebaseServicePaths makes the relative paths of the service, which are relative to the directory of the compose file it is defined in,
+// relative to the directory of the compose file extending it
+func rebaseServicePaths(baseDir, dir string, service map[string]interface{}) {
+	rebase := func(p string) string {
+		if p == "" || filepath.IsAbs(p) || strings.HasPrefix(p, "~") {
+			return p
+		}
+		relPath, err := filepath.Rel(dir, filepath.Join(baseDir, p))
+		if err != nil {
+			return filepath.Join(baseDir, p)
+		}
+		// the relative paths of the bind mounts have to start with a dot to not be mistaken for named volumes
+		if !strings.HasPrefix(relPath, ".") {
+			relPath = "." + string(filepath.Separator) + relPath
+		}
+		return relPath
+	}
+	switch build := service["build"].(type) {
+	case string:
+		if !isRemoteBuildContext(build) {
+			service["build"] = rebase(build)
+		}
+	case map[string]interface{}:
+		if context := cast.ToString(build["context"]); !isRemoteBuildContext(context) {
+			build["context"] = rebase(context)
+		}
+	}
+	switch envFiles := service[envFile].(type) {
+	case string:
+		service[envFile] = rebase(envFiles)
+	case []interface{}:
+		for i, envFilePath := range envFiles {
+			envFiles[i] = rebase(cast.ToString(envFilePath))
+		}
+	}
+	if volumes, ok := service["volumes"].([]interface{}); ok {
+		for i, volume := range volumes {
+			switch volume := volume.(type) {
+			case string:
+				parts := strings.SplitN(volume, ":", 2)
+				if len(parts) == 2 && isPath(parts[0]) {
+					volumes[i] = rebase(parts[0]) + ":" + parts[1]
+				}
+			case map[string]interface{}:
+				if cast.ToString(volume["type"]) == "bind" {
+					volume["source"] = rebase(cast.ToString(volume["source"]))
+				}
+			}
+		}
+	}
+}
+
+// getExtendsMapping returns the mapping, which can be a map or a list of key=value pairs, as a map
+func getExtendsMapping(val interface{}) (map[string]interface{}, bool) {
+	switch val := val.(type) {
+	case map[string]interface{}:
+		mapping := map[string]interface{}{}
+		for k, v := range val {
+			mapping[k] = v
+		}
+		return mapping, true
+	case []interface{}:
+		mapping := map[string]interface{}{}
+		for _, item := range val {
+			parts := strings.SplitN(cast.ToString(item), "=", 2)
+			if len(parts) == 2 {
+				mapping[parts[0]] = parts[1]
+			} else {
+				mapping[parts[0]] = nil
+			}
+		}
+		return mapping, true
+	}
+	return nil, false
+}
+
+// toSequence returns the value as a list, since some of the sequences can also be a single value, like the env files
+func toSequence(val interface{}) []interface{} {
+	if sequence, ok := val.([]interface{}); ok {
+		return sequence
+	}
+	return []interface{}{val}
+}
+
+// appendUnique appends the items which are not already in the sequence
+func appendUnique(sequence []interface{}, items []interface{}) []interface{} {
+	merged := append([]interface{}{}, sequence...)
+	for _, item := range items {
+		found := false
+		for _, existing := range merged {
+			if fmt.Sprintf("%v", existing) == fmt.Sprintf("%v", item) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			merged = append(merged, item)
+		}
+	}
+	return merged
+}
+
+// getMountTarget returns the path in the container of a volume or a device in the short or the long syntax
+func getMountTarget(mount interface{}) string {
+	if mount, ok := mount.(map[string]interface{}); ok {
+		return cast.ToString(mount["target"])
+	}
+	parts := strings.Split(cast.ToString(mount), ":")
+	if len(parts) == 1 {
+		return parts[0]
+	}
+	return parts[1]
+}
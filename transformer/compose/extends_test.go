@@ -0,0 +1,269 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package compose
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+)
+
+func writeComposeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), common.DefaultDirectoryPermission); err != nil {
+			t.Fatalf("failed to create the directory. Error: %q", err)
+		}
+		if err := os.WriteFile(path, []byte(content), common.DefaultFilePermission); err != nil {
+			t.Fatalf("failed to write the file %s . Error: %q", name, err)
+		}
+	}
+}
+
+func TestExtendsV3(t *testing.T) {
+	dir := t.TempDir()
+	writeComposeFiles(t, dir, map[string]string{
+		"docker-compose.yaml": `version: "3.8"
+services:
+  web:
+    extends:
+      file: common/common.yml
+      service: app
+    environment:
+      MODE: prod
+    ports:
+      - "9090:9090"
+    volumes:
+      - ./logs:/var/log/app
+  worker:
+    extends: web
+    command: ["worker"]
+`,
+		"common/common.yml": `version: "3.8"
+services:
+  base:
+    image: base
+    environment:
+      - LOG_LEVEL=info
+  app:
+    extends:
+      service: base
+    build: ./app
+    depends_on: [db]
+    command: ["serve"]
+    environment:
+      - MODE=dev
+    ports:
+      - "8080:8080"
+    volumes:
+      - ./data:/data
+      - ./logs:/var/log/app
+`,
+		"common/app/Dockerfile": "FROM scratch\n",
+	})
+	config, err := parseV3(filepath.Join(dir, "docker-compose.yaml"))
+	if err != nil {
+		t.Fatalf("failed to parse the compose file. Error: %q", err)
+	}
+	sort.Slice(config.Services, func(i, j int) bool { return config.Services[i].Name < config.Services[j].Name })
+	if len(config.Services) != 2 {
+		t.Fatalf("expected 2 services. Actual: %+v", config.Services)
+	}
+	for _, service := range config.Services {
+		if service.Image != "base" || filepath.Clean(service.Build.Context) != filepath.Join("common", "app") {
+			t.Fatalf("expected the image and the build of the extended service relative to its file. Actual: %s %+v", service.Image, service.Build)
+		}
+		env := map[string]string{}
+		for k, v := range service.Environment {
+			env[k] = *v
+		}
+		if diff := cmp.Diff(map[string]string{"LOG_LEVEL": "info", "MODE": "prod"}, env); diff != "" {
+			t.Fatalf("wrong environment of the service %s . Differences:\n%s", service.Name, diff)
+		}
+		if len(service.Ports) != 2 {
+			t.Fatalf("expected the ports to be appended. Actual: %+v", service.Ports)
+		}
+		sources := []string{}
+		for _, volume := range service.Volumes {
+			sources = append(sources, volume.Source)
+		}
+		if diff := cmp.Diff([]string{filepath.Join(dir, "common", "data"), filepath.Join(dir, "logs")}, sources); diff != "" {
+			t.Fatalf("wrong volumes of the service %s . Differences:\n%s", service.Name, diff)
+		}
+		if len(service.DependsOn) != 0 {
+			t.Fatalf("expected depends_on to not be inherited. Actual: %+v", service.DependsOn)
+		}
+	}
+	if diff := cmp.Diff([]string{"serve"}, []string(config.Services[0].Command)); diff != "" {
+		t.Fatalf("wrong command. Differences:\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"worker"}, []string(config.Services[1].Command)); diff != "" {
+		t.Fatalf("expected the command to be overridden. Differences:\n%s", diff)
+	}
+}
+
+func TestExtendsV3Cycle(t *testing.T) {
+	dir := t.TempDir()
+	writeComposeFiles(t, dir, map[string]string{
+		"docker-compose.yaml": `version: "3.8"
+services:
+  a:
+    image: a
+    extends: b
+  b:
+    image: b
+    extends: a
+`,
+	})
+	if _, err := parseV3(filepath.Join(dir, "docker-compose.yaml")); err == nil {
+		t.Fatalf("expected the services extending each other to fail")
+	}
+}
+
+func TestExtendsV2(t *testing.T) {
+	dir := t.TempDir()
+	writeComposeFiles(t, dir, map[string]string{
+		"docker-compose.yaml": `version: "2"
+services:
+  web:
+    extends:
+      file: common/common.yml
+      service: app
+    environment:
+      MODE: prod
+`,
+		"common/common.yml": `version: "2"
+services:
+  app:
+    build: ./app
+    environment:
+      - MODE=dev
+      - LOG_LEVEL=info
+    volumes:
+      - ./data:/data
+`,
+	})
+	proj, err := parseV2(filepath.Join(dir, "docker-compose.yaml"), true)
+	if err != nil {
+		t.Fatalf("failed to parse the compose file. Error: %q", err)
+	}
+	service, ok := proj.ServiceConfigs.Get("web")
+	if !ok {
+		t.Fatalf("failed to find the service web")
+	}
+	if service.Build.Context != filepath.Join(dir, "common", "app") {
+		t.Fatalf("expected the build of the extended service relative to its file. Actual: %+v", service.Build)
+	}
+	env := service.Environment.ToMap()
+	if diff := cmp.Diff(map[string]string{"LOG_LEVEL": "info", "MODE": "prod"}, env); diff != "" {
+		t.Fatalf("wrong environment. Differences:\n%s", diff)
+	}
+	if service.Volumes == nil || len(service.Volumes.Volumes) != 1 || filepath.Clean(service.Volumes.Volumes[0].Source) != filepath.Join("common", "data") {
+		t.Fatalf("expected the volumes of the extended service relative to its file. Actual: %+v", service.Volumes)
+	}
+}
+
+func TestComposeOverrideFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeComposeFiles(t, dir, map[string]string{
+		"docker-compose.yml": `version: "3.8"
+services:
+  web:
+    image: web:dev
+    environment:
+      MODE: dev
+      LOG_LEVEL: info
+    ports:
+      - "8080:8080"
+`,
+		"docker-compose.prod.yml": `version: "3.8"
+services:
+  web:
+    image: web:prod
+    environment:
+      MODE: prod
+  cache:
+    image: redis
+`,
+		"docker-compose.override.yml": `version: "3.8"
+services:
+  web:
+    ports:
+      - "9090:9090"
+`,
+		"other.yml": "foo: bar\n",
+	})
+	composeFilePath := filepath.Join(dir, "docker-compose.yml")
+	overrideFiles := getComposeOverrideFiles([]string{
+		filepath.Join(dir, "docker-compose.prod.yml"),
+		composeFilePath,
+		filepath.Join(dir, "other.yml"),
+		filepath.Join(dir, "docker-compose.override.yml"),
+	})
+	want := map[string][]string{composeFilePath: {filepath.Join(dir, "docker-compose.override.yml"), filepath.Join(dir, "docker-compose.prod.yml")}}
+	if diff := cmp.Diff(want, overrideFiles); diff != "" {
+		t.Fatalf("wrong override files. Differences:\n%s", diff)
+	}
+	config, err := parseV3(composeFilePath, overrideFiles[composeFilePath]...)
+	if err != nil {
+		t.Fatalf("failed to parse the compose files. Error: %q", err)
+	}
+	sort.Slice(config.Services, func(i, j int) bool { return config.Services[i].Name < config.Services[j].Name })
+	if len(config.Services) != 2 || config.Services[0].Name != "cache" {
+		t.Fatalf("expected the service of the override file to be added. Actual: %+v", config.Services)
+	}
+	web := config.Services[1]
+	if web.Image != "web:prod" {
+		t.Fatalf("expected the image to be overridden. Actual: %s", web.Image)
+	}
+	env := map[string]string{}
+	for k, v := range web.Environment {
+		env[k] = *v
+	}
+	if diff := cmp.Diff(map[string]string{"LOG_LEVEL": "info", "MODE": "prod"}, env); diff != "" {
+		t.Fatalf("wrong environment. Differences:\n%s", diff)
+	}
+	if len(web.Ports) != 2 {
+		t.Fatalf("expected the ports to be appended. Actual: %+v", web.Ports)
+	}
+	detectedServices := (&ComposeAnalyser{}).getServicesFromComposeFile(composeFilePath, overrideFiles[composeFilePath], nil)
+	if len(detectedServices) != 1 || len(detectedServices["web"]) != 1 {
+		t.Fatalf("expected only the services of the default override file to be detected. Actual: %+v", detectedServices)
+	}
+	composeFiles := []string{}
+	if err := detectedServices["web"][0].GetConfig(ComposeFileConfigType, &composeFiles); err != nil {
+		t.Fatalf("failed to get the compose files of the detected service. Error: %q", err)
+	}
+	if diff := cmp.Diff([]string{"docker-compose.yml", "docker-compose.override.yml", "docker-compose.prod.yml"}, composeFiles); diff != "" {
+		t.Fatalf("expected all the override files to be recorded. Differences:\n%s", diff)
+	}
+	writeComposeFiles(t, dir, map[string]string{
+		"v2/docker-compose.yml":          "version: \"2\"\nservices:\n  web:\n    image: web:dev\n",
+		"v2/docker-compose.override.yml": "version: \"2\"\nservices:\n  web:\n    image: web:prod\n",
+	})
+	proj, err := parseV2(filepath.Join(dir, "v2", "docker-compose.yml"), true, filepath.Join(dir, "v2", "docker-compose.override.yml"))
+	if err != nil {
+		t.Fatalf("failed to parse the compose files as version 2. Error: %q", err)
+	}
+	if service, ok := proj.ServiceConfigs.Get("web"); !ok || service.Image != "web:prod" {
+		t.Fatalf("expected the image to be overridden. Actual: %+v", service)
+	}
+}
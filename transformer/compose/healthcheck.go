@@ -0,0 +1,212 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package compose
+
+import (
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+var (
+	// healthCheckLocalHosts are the hosts which refer to the container itself
+	healthCheckLocalHosts = []string{"localhost", "127.0.0.1", "0.0.0.0", "::1"}
+	// healthCheckShellSuffixes are the suffixes of the shell health checks which only change the exit code on failure
+	healthCheckShellSuffixes = []string{"|| exit 1", "||exit 1", "|| false"}
+	// healthCheckShellOperators are the characters which make a shell health check more than a single command
+	healthCheckShellOperators = "|&;<>$`(){}\"'\\*?"
+	// curlQuietFlags and wgetQuietFlags are the flags of curl and wget which do not change the request
+	curlQuietFlags = []string{"-s", "--silent", "-S", "--show-error", "-L", "--location", "-k", "--insecure", "-q"}
+	wgetQuietFlags = []string{"-q", "--quiet", "--spider", "-nv", "--no-verbose", "-S", "--server-response", "--no-check-certificate"}
+	// ncQuietFlags are the flags of nc which do not change the connection check
+	ncQuietFlags = []string{"-z", "-v", "-zv", "-vz", "-n", "-zn", "-nz", "-zvn", "-znv"}
+)
+
+// getHealthCheckProbeHandler returns an HTTP GET or a TCP socket probe handler for a health check which only requests a url
+// using curl or wget, or connects to a port using nc, on the container itself. Otherwise the health check is executed in the container.
+func getHealthCheckProbeHandler(command []string) core.ProbeHandler {
+	execHandler := core.ProbeHandler{Exec: &core.ExecAction{Command: command}}
+	if len(command) == 0 {
+		return execHandler
+	}
+	var handler *core.ProbeHandler
+	switch path.Base(command[0]) {
+	case "curl":
+		handler = getCurlProbeHandler(command[1:])
+	case "wget":
+		handler = getWgetProbeHandler(command[1:])
+	case "nc", "netcat":
+		handler = getNcProbeHandler(command[1:])
+	}
+	if handler == nil {
+		return execHandler
+	}
+	logrus.Debugf("Using a network probe instead of executing the health check %+v", command)
+	return *handler
+}
+
+// getShellHealthCheckCommand splits the shell health check into the command and its arguments.
+// It returns nil if the health check uses any shell features other than ending with || exit 1.
+func getShellHealthCheckCommand(shellCommand string) []string {
+	shellCommand = strings.TrimSpace(shellCommand)
+	for _, suffix := range healthCheckShellSuffixes {
+		shellCommand = strings.TrimSpace(strings.TrimSuffix(shellCommand, suffix))
+	}
+	if shellCommand == "" || strings.ContainsAny(shellCommand, healthCheckShellOperators) {
+		return nil
+	}
+	return strings.Fields(shellCommand)
+}
+
+// getCurlProbeHandler returns an HTTP GET probe handler for curl requesting the url on the container.
+// curl only fails on the HTTP errors when using --fail, like the HTTP GET probes.
+func getCurlProbeHandler(args []string) *core.ProbeHandler {
+	fail := false
+	rawURL := ""
+	headers := []core.HTTPHeader{}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "-f" || arg == "--fail":
+			fail = true
+		case common.IsPresent(curlQuietFlags, arg):
+		case arg == "-o" || arg == "--output":
+			// the output is not used by the probes
+			i++
+		case arg == "-H" || arg == "--header":
+			i++
+			if i >= len(args) {
+				return nil
+			}
+			name, value, ok := strings.Cut(args[i], ":")
+			if !ok {
+				return nil
+			}
+			headers = append(headers, core.HTTPHeader{Name: strings.TrimSpace(name), Value: strings.TrimSpace(value)})
+		case strings.HasPrefix(arg, "-") && !strings.HasPrefix(arg, "--") && strings.Trim(arg[1:], "fsSLk") == "":
+			// combined short flags like -fsS
+			fail = fail || strings.Contains(arg, "f")
+		case strings.HasPrefix(arg, "-") || rawURL != "":
+			return nil
+		default:
+			rawURL = arg
+		}
+	}
+	if !fail {
+		return nil
+	}
+	return getHTTPGetProbeHandler(rawURL, headers)
+}
+
+// getWgetProbeHandler returns an HTTP GET probe handler for wget requesting the url on the container.
+// wget fails on the HTTP errors, like the HTTP GET probes.
+func getWgetProbeHandler(args []string) *core.ProbeHandler {
+	rawURL := ""
+	headers := []core.HTTPHeader{}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case common.IsPresent(wgetQuietFlags, arg):
+		case arg == "-O" || arg == "--output-document":
+			// the output is not used by the probes
+			i++
+		case strings.HasPrefix(arg, "-O") || strings.HasPrefix(arg, "--output-document=") || strings.HasPrefix(arg, "--tries=") || strings.HasPrefix(arg, "--timeout="):
+		case strings.HasPrefix(arg, "--header="):
+			name, value, ok := strings.Cut(strings.TrimPrefix(arg, "--header="), ":")
+			if !ok {
+				return nil
+			}
+			headers = append(headers, core.HTTPHeader{Name: strings.TrimSpace(name), Value: strings.TrimSpace(value)})
+		case strings.HasPrefix(arg, "-") || rawURL != "":
+			return nil
+		default:
+			rawURL = arg
+		}
+	}
+	return getHTTPGetProbeHandler(rawURL, headers)
+}
+
+// getNcProbeHandler returns a TCP socket probe handler for nc checking that the port on the container is open
+func getNcProbeHandler(args []string) *core.ProbeHandler {
+	zeroIO := false
+	operands := []string{}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case common.IsPresent(ncQuietFlags, arg):
+			zeroIO = zeroIO || strings.Contains(arg, "z")
+		case arg == "-w":
+			// the timeout of the probe is used instead
+			i++
+		case strings.HasPrefix(arg, "-"):
+			return nil
+		default:
+			operands = append(operands, arg)
+		}
+	}
+	// without -z nc waits for input instead of only checking the connection
+	if !zeroIO || len(operands) != 2 || !common.IsPresent(healthCheckLocalHosts, operands[0]) {
+		return nil
+	}
+	port, err := strconv.Atoi(operands[1])
+	if err != nil || port <= 0 {
+		return nil
+	}
+	return &core.ProbeHandler{TCPSocket: &core.TCPSocketAction{Port: intstr.FromInt(port)}}
+}
+
+// getHTTPGetProbeHandler returns an HTTP GET probe handler for the url, if it refers to the container itself
+func getHTTPGetProbeHandler(rawURL string, headers []core.HTTPHeader) *core.ProbeHandler {
+	if rawURL == "" {
+		return nil
+	}
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "http://" + rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || !common.IsPresent(healthCheckLocalHosts, u.Hostname()) || u.User != nil {
+		return nil
+	}
+	action := core.HTTPGetAction{Path: u.RequestURI()}
+	switch strings.ToLower(u.Scheme) {
+	case "http":
+		action.Scheme = core.URISchemeHTTP
+		action.Port = intstr.FromInt(80)
+	case "https":
+		action.Scheme = core.URISchemeHTTPS
+		action.Port = intstr.FromInt(443)
+	default:
+		return nil
+	}
+	if u.Port() != "" {
+		port, err := strconv.Atoi(u.Port())
+		if err != nil || port <= 0 {
+			return nil
+		}
+		action.Port = intstr.FromInt(port)
+	}
+	if len(headers) != 0 {
+		action.HTTPHeaders = headers
+	}
+	return &core.ProbeHandler{HTTPGet: &action}
+}
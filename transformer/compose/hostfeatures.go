@@ -0,0 +1,372 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package compose
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/common/report"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/resource"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+const (
+	// keepPrivilegedOpt, capabilitiesOpt and unprivilegedOpt are the choices for a privileged service
+	keepPrivilegedOpt = "Keep the container privileged"
+	capabilitiesOpt   = "Replace the privileged mode with specific capabilities"
+	unprivilegedOpt   = "Run the container unprivileged"
+	// hostNetworkOpt, hostPortOpt and podNetworkOpt are the choices for a service using the host network
+	hostNetworkOpt = "Keep using the host network"
+	hostPortOpt    = "Use the pod network and bind the container ports to the same ports on the host"
+	podNetworkOpt  = "Use the pod network"
+	// hostPathDevicesOpt, devicePluginOpt and ignoreDevicesOpt are the choices for a service using devices of the host
+	hostPathDevicesOpt = "Mount the devices from the host"
+	devicePluginOpt    = "Request the devices from a device plugin"
+	ignoreDevicesOpt   = "Ignore the devices"
+	networkModeHost    = "host"
+	// gpuDeviceCapability is the capability of the devices reserved by a service that are GPUs
+	gpuDeviceCapability = "gpu"
+	// allDevicesCount is the count of the reserved devices when all of them are used
+	allDevicesCount = "all"
+)
+
+var (
+	// privilegedCapabilities are the capabilities commonly needed by the containers that are run privileged
+	privilegedCapabilities = []string{"NET_ADMIN", "NET_RAW", "SYS_ADMIN", "SYS_PTRACE", "SYS_TIME", "SYS_RESOURCE", "SYS_RAWIO", "IPC_LOCK", "MKNOD"}
+	// devicePluginResources are the resources of the well known device plugins, keyed by the prefix of the device paths
+	devicePluginResources = map[string]string{
+		"/dev/nvidia": "nvidia.com/gpu",
+		"/dev/dri":    "gpu.intel.com/i915",
+		"/dev/kvm":    "devices.kubevirt.io/kvm",
+		"/dev/fuse":   "github.com/fuse",
+	}
+	// gpuDriverResources are the resources of the device plugins of the GPUs, keyed by the driver used in the device requests
+	gpuDriverResources = map[string]string{
+		"":       "nvidia.com/gpu",
+		"nvidia": "nvidia.com/gpu",
+		"amd":    "amd.com/gpu",
+		"intel":  "gpu.intel.com/i915",
+		"i915":   "gpu.intel.com/i915",
+	}
+	// safeSysctls are the sysctls that k8s allows by default, since they are isolated between the pods
+	safeSysctls = []string{
+		"kernel.shm_rmid_forced",
+		"net.ipv4.ip_local_port_range",
+		"net.ipv4.ip_local_reserved_ports",
+		"net.ipv4.ip_unprivileged_port_start",
+		"net.ipv4.ping_group_range",
+		"net.ipv4.tcp_syncookies",
+		"net.ipv4.tcp_keepalive_time",
+		"net.ipv4.tcp_fin_timeout",
+		"net.ipv4.tcp_keepalive_intvl",
+		"net.ipv4.tcp_keepalive_probes",
+	}
+	// namespacedSysctlPrefixes are the prefixes of the sysctls which are namespaced, and so can be set for a pod
+	namespacedSysctlPrefixes = []string{"kernel.shm", "kernel.msg", "kernel.sem", "fs.mqueue.", "net."}
+)
+
+// hostFeatures are the features of the host used by a compose service
+type hostFeatures struct {
+	privileged  bool
+	networkMode string
+	devices     []string
+	sysctls     map[string]string
+	// deviceRequests are the devices reserved using the device capabilities, like the GPUs
+	deviceRequests []composeDeviceRequest
+}
+
+// composeDeviceRequest is a device reserved by a compose service, either in the reservations of the deploy section or using gpus
+type composeDeviceRequest struct {
+	driver       string
+	capabilities []string
+	// count is the number of devices, or -1 for all of them
+	count     int64
+	deviceIDs []string
+}
+
+// adviseHostFeatures proposes the least privileged alternatives to the features of the host used by the service,
+// and applies the chosen ones to the service and its container
+func adviseHostFeatures(serviceName string, features hostFeatures, service *irtypes.Service, container *core.Container) {
+	if features.privileged {
+		advisePrivileged(serviceName, container)
+	}
+	if features.networkMode == networkModeHost {
+		adviseHostNetwork(serviceName, service, container)
+	}
+	if len(features.devices) != 0 {
+		adviseDevices(serviceName, features.devices, service, container)
+	}
+	if len(features.deviceRequests) != 0 {
+		addDeviceRequests(serviceName, features.deviceRequests, container)
+	}
+	if len(features.sysctls) != 0 {
+		adviseSysctls(serviceName, features.sysctls, service)
+	}
+}
+
+func advisePrivileged(serviceName string, container *core.Container) {
+	selectedOption := qaengine.FetchSelectAnswer(
+		common.JoinQASubKeys(common.ConfigServicesKey, `"`+serviceName+`"`, common.ConfigPrivilegedForServiceKeySegment),
+		fmt.Sprintf("The service '%s' is privileged, which gives it full access to the nodes. What should be done?", serviceName),
+		[]string{"Most services only need a few capabilities, which are much safer to grant than the privileged mode."},
+		keepPrivilegedOpt,
+		[]string{keepPrivilegedOpt, capabilitiesOpt, unprivilegedOpt},
+		nil,
+	)
+	if selectedOption == keepPrivilegedOpt {
+		logrus.Warnf("The service '%s' is kept privileged, which gives it full access to the nodes.", serviceName)
+		report.AddFollowUp(serviceName, "The container is privileged. Replace the privileged mode with the capabilities the service needs, if possible")
+		return
+	}
+	container.SecurityContext.Privileged = nil
+	if selectedOption == unprivilegedOpt {
+		report.AddFollowUp(serviceName, "The privileged mode of the container was removed. Check that the service works without it")
+		return
+	}
+	capabilities := qaengine.FetchMultiSelectAnswer(
+		common.JoinQASubKeys(common.ConfigServicesKey, `"`+serviceName+`"`, common.ConfigCapabilitiesForServiceKeySegment),
+		fmt.Sprintf("Select the capabilities the service '%s' needs :", serviceName),
+		[]string{"The capabilities are added on top of the capabilities the container runtime grants by default."},
+		[]string{},
+		privilegedCapabilities,
+		nil,
+	)
+	if len(capabilities) == 0 {
+		logrus.Infof("No capabilities were selected for the service '%s' . Running it unprivileged.", serviceName)
+		report.AddFollowUp(serviceName, "The privileged mode of the container was removed without adding any capabilities. Check that the service works without them")
+		return
+	}
+	if container.SecurityContext.Capabilities == nil {
+		container.SecurityContext.Capabilities = &core.Capabilities{}
+	}
+	for _, capability := range capabilities {
+		if !common.IsPresent(container.SecurityContext.Capabilities.Add, core.Capability(capability)) {
+			container.SecurityContext.Capabilities.Add = append(container.SecurityContext.Capabilities.Add, core.Capability(capability))
+		}
+	}
+}
+
+func adviseHostNetwork(serviceName string, service *irtypes.Service, container *core.Container) {
+	selectedOption := qaengine.FetchSelectAnswer(
+		common.JoinQASubKeys(common.ConfigServicesKey, `"`+serviceName+`"`, common.ConfigHostNetworkForServiceKeySegment),
+		fmt.Sprintf("The service '%s' uses the network of the host. What should be done?", serviceName),
+		[]string{"The host network exposes all the network interfaces of the node. Binding only the ports of the container to the host is safer."},
+		hostPortOpt,
+		[]string{hostPortOpt, podNetworkOpt, hostNetworkOpt},
+		nil,
+	)
+	switch selectedOption {
+	case hostNetworkOpt:
+		if service.SecurityContext == nil {
+			service.SecurityContext = &core.PodSecurityContext{}
+		}
+		service.SecurityContext.HostNetwork = true
+	case hostPortOpt:
+		if len(container.Ports) == 0 {
+			logrus.Warnf("The service '%s' does not have any ports to bind to the host. Using the pod network.", serviceName)
+		}
+		for i, port := range container.Ports {
+			if port.HostPort == 0 {
+				container.Ports[i].HostPort = port.ContainerPort
+			}
+		}
+	}
+}
+
+func adviseDevices(serviceName string, devices []string, service *irtypes.Service, container *core.Container) {
+	defaultResource := ""
+	for _, device := range devices {
+		hostPath := strings.SplitN(device, ":", 2)[0]
+		for prefix, resource := range devicePluginResources {
+			if strings.HasPrefix(hostPath, prefix) {
+				defaultResource = resource
+			}
+		}
+	}
+	defaultOption := hostPathDevicesOpt
+	if defaultResource != "" {
+		defaultOption = devicePluginOpt
+	}
+	selectedOption := qaengine.FetchSelectAnswer(
+		common.JoinQASubKeys(common.ConfigServicesKey, `"`+serviceName+`"`, common.ConfigDevicesForServiceKeySegment),
+		fmt.Sprintf("The service '%s' uses the devices %s of the host. How should they be provided?", serviceName, strings.Join(devices, ", ")),
+		[]string{"A device plugin schedules the pods on the nodes having the devices and gives them access without the privileged mode."},
+		defaultOption,
+		[]string{devicePluginOpt, hostPathDevicesOpt, ignoreDevicesOpt},
+		nil,
+	)
+	switch selectedOption {
+	case devicePluginOpt:
+		resourceName := qaengine.FetchStringAnswer(
+			common.JoinQASubKeys(common.ConfigServicesKey, `"`+serviceName+`"`, common.ConfigDevicePluginResourceForServiceKeySegment),
+			fmt.Sprintf("Enter the resource of the device plugin providing the devices of the service '%s' :", serviceName),
+			[]string{"For example nvidia.com/gpu for the NVIDIA GPUs. The device plugin has to be installed in the cluster."},
+			defaultResource,
+			nil,
+		)
+		if resourceName == "" {
+			logrus.Warnf("No device plugin resource was given for the service '%s' . Ignoring its devices.", serviceName)
+			report.AddDroppedField(serviceName, "devices", "no device plugin resource was given for the devices")
+			return
+		}
+		// the extended resources cannot be overcommitted, so the limit is enough
+		if container.Resources.Limits == nil {
+			container.Resources.Limits = core.ResourceList{}
+		}
+		container.Resources.Limits[core.ResourceName(resourceName)] = *resource.NewQuantity(int64(len(devices)), resource.DecimalSI)
+	case hostPathDevicesOpt:
+		for i, device := range devices {
+			parts := strings.Split(device, ":")
+			hostPath, containerPath := parts[0], parts[0]
+			if len(parts) > 1 && parts[1] != "" {
+				containerPath = parts[1]
+			}
+			volumeName := common.MakeStringK8sServiceNameCompliant(fmt.Sprintf("device-%d-%s", i, filepath.Base(hostPath)))
+			// the type is not set, since the devices can be character or block devices, or directories like /dev/snd and /dev/dri
+			service.AddVolume(core.Volume{
+				Name:         volumeName,
+				VolumeSource: core.VolumeSource{HostPath: &core.HostPathVolumeSource{Path: hostPath}},
+			})
+			container.VolumeMounts = append(container.VolumeMounts, core.VolumeMount{Name: volumeName, MountPath: containerPath})
+		}
+		if container.SecurityContext != nil && container.SecurityContext.Privileged != nil && *container.SecurityContext.Privileged {
+			report.AddFollowUp(serviceName, "Check that the devices mounted from the host are present on the nodes")
+			return
+		}
+		// only the privileged containers are allowed to access the devices by the device cgroup of the container runtime
+		if !qaengine.FetchBoolAnswer(
+			common.JoinQASubKeys(common.ConfigServicesKey, `"`+serviceName+`"`, common.ConfigPrivilegedDevicesForServiceKeySegment),
+			fmt.Sprintf("The container of the service '%s' can only access the devices mounted from the host when it is privileged. Run it privileged?", serviceName),
+			[]string{"The privileged mode gives the container full access to the nodes. A device plugin can provide the devices without it."},
+			false,
+			nil,
+		) {
+			logrus.Warnf("The devices of the service '%s' are mounted from the host, but the container is not privileged to access them.", serviceName)
+			report.AddFollowUp(serviceName, "Check that the devices mounted from the host are present on the nodes. The container is not privileged, so the container runtime may deny it access to them, unless a device plugin provides them instead")
+			return
+		}
+		if container.SecurityContext == nil {
+			container.SecurityContext = &core.SecurityContext{}
+		}
+		privileged := true
+		container.SecurityContext.Privileged = &privileged
+		logrus.Warnf("The devices of the service '%s' are mounted from the host and the container is privileged to access them.", serviceName)
+		report.AddFollowUp(serviceName, "Check that the devices mounted from the host are present on the nodes. The container is privileged to access them, unless a device plugin provides them instead")
+	}
+}
+
+// addDeviceRequests requests the GPUs reserved by the service from the device plugins, as extended resources of the container
+func addDeviceRequests(serviceName string, deviceRequests []composeDeviceRequest, container *core.Container) {
+	for _, deviceRequest := range deviceRequests {
+		if len(deviceRequest.capabilities) != 0 && !common.IsPresent(deviceRequest.capabilities, gpuDeviceCapability) {
+			logrus.Warnf("The devices with the capabilities %+v of the service '%s' are not supported. Ignoring them.", deviceRequest.capabilities, serviceName)
+			report.AddDroppedField(serviceName, "deploy.resources.reservations.devices", fmt.Sprintf("only the devices with the %s capability can be requested from a device plugin", gpuDeviceCapability))
+			continue
+		}
+		resourceName, ok := gpuDriverResources[strings.ToLower(deviceRequest.driver)]
+		if !ok {
+			logrus.Warnf("The device plugin for the GPU driver '%s' of the service '%s' is not known. Ignoring the GPUs.", deviceRequest.driver, serviceName)
+			report.AddDroppedField(serviceName, "deploy.resources.reservations.devices", fmt.Sprintf("the device plugin for the GPU driver %s is not known", deviceRequest.driver))
+			continue
+		}
+		count := deviceRequest.count
+		if count == 0 {
+			count = int64(len(deviceRequest.deviceIDs))
+		}
+		if len(deviceRequest.deviceIDs) != 0 {
+			report.AddFollowUp(serviceName, fmt.Sprintf("The device plugin chooses the GPUs of the service instead of the device ids %s", strings.Join(deviceRequest.deviceIDs, ", ")))
+		}
+		if count <= 0 {
+			// the extended resources can only be requested in whole numbers
+			count = 1
+			logrus.Warnf("The service '%s' uses all the GPUs, which can not be requested in k8s. Requesting 1 GPU.", serviceName)
+			report.AddFollowUp(serviceName, fmt.Sprintf("Set the number of %s needed by the service, since it used all the GPUs of the host", resourceName))
+		}
+		if container.Resources.Limits == nil {
+			container.Resources.Limits = core.ResourceList{}
+		}
+		quantity := container.Resources.Limits[core.ResourceName(resourceName)]
+		quantity.Add(*resource.NewQuantity(count, resource.DecimalSI))
+		container.Resources.Limits[core.ResourceName(resourceName)] = quantity
+	}
+}
+
+// adviseSysctls sets the sysctls of the service in the security context of the pod.
+// The sysctls that are not namespaced are dropped, and the unsafe ones are only set if they are allowed on the kubelets.
+func adviseSysctls(serviceName string, sysctls map[string]string, service *irtypes.Service) {
+	names := []string{}
+	for name := range sysctls {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	safe := []string{}
+	unsafe := []string{}
+	for _, name := range names {
+		switch {
+		case common.IsPresent(safeSysctls, name):
+			safe = append(safe, name)
+		case isNamespacedSysctl(name):
+			unsafe = append(unsafe, name)
+		default:
+			logrus.Warnf("The sysctl '%s' of the service '%s' is not namespaced and can only be set on the nodes. Dropping it.", name, serviceName)
+			report.AddDroppedField(serviceName, "sysctls."+name, "the sysctl is not namespaced, so it can only be set on the nodes and not for a pod")
+		}
+	}
+	if len(unsafe) != 0 {
+		if qaengine.FetchBoolAnswer(
+			common.JoinQASubKeys(common.ConfigServicesKey, `"`+serviceName+`"`, common.ConfigUnsafeSysctlsForServiceKeySegment),
+			fmt.Sprintf("The service '%s' uses the unsafe sysctls %s . Set them anyway?", serviceName, strings.Join(unsafe, ", ")),
+			[]string{"The pods using unsafe sysctls fail to start, unless the kubelets on the nodes allow them using --allowed-unsafe-sysctls."},
+			false,
+			nil,
+		) {
+			safe = append(safe, unsafe...)
+			report.AddFollowUp(serviceName, fmt.Sprintf("Allow the unsafe sysctls %s using allowedUnsafeSysctls in the kubelet config of the nodes", strings.Join(unsafe, ", ")))
+		} else {
+			logrus.Warnf("The unsafe sysctls %s of the service '%s' are not set.", strings.Join(unsafe, ", "), serviceName)
+			for _, name := range unsafe {
+				report.AddDroppedField(serviceName, "sysctls."+name, "the sysctl is unsafe and it was chosen not to be set")
+			}
+		}
+	}
+	if len(safe) == 0 {
+		return
+	}
+	sort.Strings(safe)
+	if service.SecurityContext == nil {
+		service.SecurityContext = &core.PodSecurityContext{}
+	}
+	for _, name := range safe {
+		service.SecurityContext.Sysctls = append(service.SecurityContext.Sysctls, core.Sysctl{Name: name, Value: sysctls[name]})
+	}
+}
+
+// isNamespacedSysctl returns true if the sysctl is isolated between the pods
+func isNamespacedSysctl(name string) bool {
+	for _, prefix := range namespacedSysctlPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,231 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package compose
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common/report"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"k8s.io/apimachinery/pkg/api/resource"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestAdviseHostFeatures(t *testing.T) {
+	privileged := true
+	newService := func() (irtypes.Service, core.Container) {
+		return irtypes.NewServiceWithName("svc1"), core.Container{
+			Name:            "svc1",
+			Ports:           []core.ContainerPort{{ContainerPort: 8080}},
+			SecurityContext: &core.SecurityContext{Privileged: &privileged},
+		}
+	}
+
+	t.Run("replace the privileged mode and the host network with capabilities and host ports", func(t *testing.T) {
+		qaengine.ResetEngines()
+		defer qaengine.ResetEngines()
+		qaengine.AddEngine(qaengine.NewDefaultEngine())
+		qaengine.SetupConfigFile("", []string{
+			`move2kube.services."svc1".privileged="` + capabilitiesOpt + `"`,
+			`move2kube.services."svc1".capabilities=["NET_ADMIN","NET_RAW"]`,
+		}, nil, nil, false)
+		service, container := newService()
+		adviseHostFeatures("svc1", hostFeatures{privileged: true, networkMode: networkModeHost}, &service, &container)
+		if container.SecurityContext.Privileged != nil {
+			t.Fatalf("expected the container to not be privileged")
+		}
+		want := &core.Capabilities{Add: []core.Capability{"NET_ADMIN", "NET_RAW"}}
+		if diff := cmp.Diff(want, container.SecurityContext.Capabilities); diff != "" {
+			t.Fatalf("unexpected capabilities. Differences:\n%s", diff)
+		}
+		if container.Ports[0].HostPort != 8080 {
+			t.Fatalf("expected the host port to be 8080. Actual: %d", container.Ports[0].HostPort)
+		}
+		if service.SecurityContext != nil && service.SecurityContext.HostNetwork {
+			t.Fatalf("expected the service to not use the host network")
+		}
+	})
+
+	t.Run("keep the privileged mode and the host network", func(t *testing.T) {
+		qaengine.ResetEngines()
+		defer qaengine.ResetEngines()
+		qaengine.AddEngine(qaengine.NewDefaultEngine())
+		qaengine.SetupConfigFile("", []string{
+			`move2kube.services."svc1".privileged="` + keepPrivilegedOpt + `"`,
+			`move2kube.services."svc1".hostnetwork="` + hostNetworkOpt + `"`,
+		}, nil, nil, false)
+		service, container := newService()
+		adviseHostFeatures("svc1", hostFeatures{privileged: true, networkMode: networkModeHost}, &service, &container)
+		if container.SecurityContext.Privileged == nil || !*container.SecurityContext.Privileged {
+			t.Fatalf("expected the container to stay privileged")
+		}
+		if service.SecurityContext == nil || !service.SecurityContext.HostNetwork {
+			t.Fatalf("expected the service to use the host network")
+		}
+	})
+
+	t.Run("keep the privileged mode by default and report it", func(t *testing.T) {
+		qaengine.ResetEngines()
+		defer qaengine.ResetEngines()
+		qaengine.AddEngine(qaengine.NewDefaultEngine())
+		qaengine.SetupConfigFile("", nil, nil, nil, false)
+		report.Reset()
+		defer report.Reset()
+		service, container := newService()
+		adviseHostFeatures("svc1", hostFeatures{privileged: true}, &service, &container)
+		if container.SecurityContext.Privileged == nil || !*container.SecurityContext.Privileged {
+			t.Fatalf("expected the container to stay privileged")
+		}
+		if len(report.Get("", "").Spec.FollowUps) == 0 {
+			t.Fatalf("expected a follow up for the privileged container")
+		}
+	})
+
+	t.Run("request the gpus from the device plugin", func(t *testing.T) {
+		qaengine.ResetEngines()
+		defer qaengine.ResetEngines()
+		qaengine.AddEngine(qaengine.NewDefaultEngine())
+		service, container := newService()
+		adviseHostFeatures("svc1", hostFeatures{devices: []string{"/dev/nvidia0", "/dev/nvidia1:/dev/nvidia1"}}, &service, &container)
+		want := core.ResourceList{"nvidia.com/gpu": *resource.NewQuantity(2, resource.DecimalSI)}
+		if diff := cmp.Diff(want, container.Resources.Limits); diff != "" {
+			t.Fatalf("unexpected resource limits. Differences:\n%s", diff)
+		}
+		if len(service.Volumes) != 0 {
+			t.Fatalf("expected no volumes. Actual: %+v", service.Volumes)
+		}
+	})
+
+	t.Run("mount the devices from the host", func(t *testing.T) {
+		qaengine.ResetEngines()
+		defer qaengine.ResetEngines()
+		qaengine.AddEngine(qaengine.NewDefaultEngine())
+		qaengine.SetupConfigFile("", nil, nil, nil, false)
+		report.Reset()
+		defer report.Reset()
+		service := irtypes.NewServiceWithName("svc1")
+		container := core.Container{Name: "svc1"}
+		adviseHostFeatures("svc1", hostFeatures{devices: []string{"/dev/ttyUSB0:/dev/serial", "/dev/snd"}}, &service, &container)
+		want := []core.Volume{
+			{Name: "device-0-ttyusb0", VolumeSource: core.VolumeSource{HostPath: &core.HostPathVolumeSource{Path: "/dev/ttyUSB0"}}},
+			{Name: "device-1-snd", VolumeSource: core.VolumeSource{HostPath: &core.HostPathVolumeSource{Path: "/dev/snd"}}},
+		}
+		if diff := cmp.Diff(want, service.Volumes); diff != "" {
+			t.Fatalf("unexpected volumes. Differences:\n%s", diff)
+		}
+		if len(container.VolumeMounts) != 2 || container.VolumeMounts[0].MountPath != "/dev/serial" || container.VolumeMounts[1].MountPath != "/dev/snd" {
+			t.Fatalf("expected the devices to be mounted at /dev/serial and /dev/snd. Actual: %+v", container.VolumeMounts)
+		}
+		if container.SecurityContext != nil && container.SecurityContext.Privileged != nil {
+			t.Fatalf("expected the container to not be privileged by default")
+		}
+		if len(report.Get("", "").Spec.FollowUps) == 0 {
+			t.Fatalf("expected a follow up for the devices mounted from the host")
+		}
+	})
+
+	t.Run("run the container mounting the devices privileged", func(t *testing.T) {
+		qaengine.ResetEngines()
+		defer qaengine.ResetEngines()
+		qaengine.AddEngine(qaengine.NewDefaultEngine())
+		qaengine.SetupConfigFile("", []string{`move2kube.services."svc1".privilegeddevices=true`}, nil, nil, false)
+		service := irtypes.NewServiceWithName("svc1")
+		container := core.Container{Name: "svc1"}
+		adviseHostFeatures("svc1", hostFeatures{devices: []string{"/dev/ttyUSB0"}}, &service, &container)
+		if container.SecurityContext == nil || container.SecurityContext.Privileged == nil || !*container.SecurityContext.Privileged {
+			t.Fatalf("expected the container to be privileged to access the device")
+		}
+	})
+
+	t.Run("set the safe sysctls and drop the unsafe ones by default", func(t *testing.T) {
+		qaengine.ResetEngines()
+		defer qaengine.ResetEngines()
+		qaengine.AddEngine(qaengine.NewDefaultEngine())
+		qaengine.SetupConfigFile("", nil, nil, nil, false)
+		report.Reset()
+		defer report.Reset()
+		service := irtypes.NewServiceWithName("svc1")
+		container := core.Container{Name: "svc1"}
+		sysctls := map[string]string{"net.core.somaxconn": "1024", "net.ipv4.tcp_syncookies": "1", "vm.max_map_count": "262144"}
+		adviseHostFeatures("svc1", hostFeatures{sysctls: sysctls}, &service, &container)
+		want := []core.Sysctl{{Name: "net.ipv4.tcp_syncookies", Value: "1"}}
+		if service.SecurityContext == nil {
+			t.Fatalf("expected the sysctls to be set in the security context of the pod")
+		}
+		if diff := cmp.Diff(want, service.SecurityContext.Sysctls); diff != "" {
+			t.Fatalf("unexpected sysctls. Differences:\n%s", diff)
+		}
+		if len(report.Get("", "").Spec.DroppedFields) != 2 {
+			t.Fatalf("expected the unsafe and the not namespaced sysctls to be reported as dropped. Actual: %+v", report.Get("", "").Spec.DroppedFields)
+		}
+	})
+
+	t.Run("set the unsafe sysctls", func(t *testing.T) {
+		qaengine.ResetEngines()
+		defer qaengine.ResetEngines()
+		qaengine.AddEngine(qaengine.NewDefaultEngine())
+		qaengine.SetupConfigFile("", []string{`move2kube.services."svc1".unsafesysctls=true`}, nil, nil, false)
+		service := irtypes.NewServiceWithName("svc1")
+		container := core.Container{Name: "svc1"}
+		adviseHostFeatures("svc1", hostFeatures{sysctls: map[string]string{"net.core.somaxconn": "1024"}}, &service, &container)
+		want := []core.Sysctl{{Name: "net.core.somaxconn", Value: "1024"}}
+		if service.SecurityContext == nil {
+			t.Fatalf("expected the sysctls to be set in the security context of the pod")
+		}
+		if diff := cmp.Diff(want, service.SecurityContext.Sysctls); diff != "" {
+			t.Fatalf("unexpected sysctls. Differences:\n%s", diff)
+		}
+	})
+}
+
+func TestSysctlsV2(t *testing.T) {
+	qaengine.ResetEngines()
+	defer qaengine.ResetEngines()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", []string{`move2kube.services."web".unsafesysctls=true`}, nil, nil, false)
+	dir := t.TempDir()
+	writeComposeFiles(t, dir, map[string]string{
+		"docker-compose.yml": `version: "2"
+services:
+  web:
+    image: web:latest
+    sysctls:
+      - net.core.somaxconn=1024
+`,
+		"docker-compose.override.yml": `version: "2"
+services:
+  web:
+    sysctls:
+      net.ipv4.tcp_syncookies: 0
+`,
+	})
+	loader := v1v2Loader{overrideFilePaths: []string{filepath.Join(dir, "docker-compose.override.yml")}}
+	ir, err := loader.ConvertToIR(filepath.Join(dir, "docker-compose.yml"), "web", false)
+	if err != nil {
+		t.Fatalf("failed to convert the compose file. Error: %q", err)
+	}
+	service := ir.Services["web"]
+	if service.SecurityContext == nil {
+		t.Fatalf("expected the sysctls to be set in the security context of the pod")
+	}
+	want := []core.Sysctl{{Name: "net.core.somaxconn", Value: "1024"}, {Name: "net.ipv4.tcp_syncookies", Value: "0"}}
+	if diff := cmp.Diff(want, service.SecurityContext.Sysctls); diff != "" {
+		t.Fatalf("unexpected sysctls. Differences:\n%s", diff)
+	}
+}
@@ -0,0 +1,355 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package compose
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/cli/cli/compose/loader"
+	"github.com/docker/cli/cli/compose/types"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/common/deepcopy"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cast"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	defaultLocalComposeOutputPath = common.DeployDir + string(os.PathSeparator) + "compose-local"
+	localComposeFileName          = "docker-compose.yaml"
+	// localComposeV2Version is the version of the local compose file when it only has the services of version 1 or 2 compose files
+	localComposeV2Version = "3.8"
+)
+
+var (
+	// localComposeV2Keys are the keys of the services of the version 1 or 2 compose files that are added to the local compose file
+	localComposeV2Keys = []string{"image", "build", "command", "entrypoint", "environment", "ports", "expose", "volumes", dependsOnKey,
+		"labels", "working_dir", "user", "restart", "hostname", "extra_hosts", "cap_add", "cap_drop", "privileged", "tty", "stdin_open",
+		"dns", "dns_search", "devices", "read_only", "shm_size", "stop_signal", "init"}
+)
+
+// localCompose is a single compose file with all the services, resolved so that it can be used for local development
+type localCompose struct {
+	composeObj
+	// sourceDir is the directory that gets copied to the source directory of the output
+	sourceDir string
+	// outputPath is the directory of the compose file relative to the output directory
+	outputPath string
+	// activeProfiles are the compose profiles whose services are added
+	activeProfiles []string
+	// dependsOnConditions are the dependencies of the services in the long syntax of depends_on, keyed by the service name
+	dependsOnConditions map[string]map[string]interface{}
+}
+
+func newLocalCompose(sourceDir string, outputPath string) *localCompose {
+	return &localCompose{
+		composeObj: composeObj{Services: map[string]types.ServiceConfig{}},
+		sourceDir:  sourceDir,
+		outputPath: outputPath,
+	}
+}
+
+// addService adds the service from the version 3 compose file merged with its override files, with the env vars interpolated
+// and the env files and profiles applied. It returns true if any of the paths of the service refers to the sources.
+func (lc *localCompose) addService(composeFilePath string, serviceName string, overrideFilePaths ...string) (usesSources bool) {
+	config, err := getParsedV3(composeFilePath, overrideFilePaths...)
+	if err != nil {
+		logrus.Debugf("not adding the service %s to the local compose file since the file %s is not a version 3 compose file . Error: %q", serviceName, composeFilePath, err)
+		return false
+	}
+	if _, ok := lc.Services[serviceName]; ok {
+		logrus.Warnf("The service %s in the compose file %s has already been added to the local compose file from another compose file. Ignoring it.", serviceName, composeFilePath)
+		return false
+	}
+	for _, composeService := range config.Services {
+		if composeService.Name != serviceName {
+			continue
+		}
+		if !isProfileActive(getProfilesV3(composeService), lc.activeProfiles) {
+			logrus.Debugf("not adding the service %s to the local compose file since none of its profiles are enabled", serviceName)
+			return false
+		}
+		service := deepcopy.DeepCopy(composeService).(types.ServiceConfig)
+		service.DependsOn = lc.getActiveDependencies(*config, service)
+		lc.setDependsOnConditions(&service)
+		usesSources = lc.addServiceConfig(filepath.Dir(composeFilePath), service)
+	}
+	if lc.Version == "" {
+		lc.Version = config.Version
+	}
+	for name, network := range config.Networks {
+		if lc.Networks == nil {
+			lc.Networks = map[string]types.NetworkConfig{}
+		}
+		if _, ok := lc.Networks[name]; !ok {
+			lc.Networks[name] = network
+		}
+	}
+	for name, volume := range config.Volumes {
+		if lc.Volumes == nil {
+			lc.Volumes = map[string]types.VolumeConfig{}
+		}
+		if _, ok := lc.Volumes[name]; !ok {
+			lc.Volumes[name] = volume
+		}
+	}
+	for name, secret := range config.Secrets {
+		if lc.Secrets == nil {
+			lc.Secrets = map[string]types.SecretConfig{}
+		}
+		if _, ok := lc.Secrets[name]; ok {
+			continue
+		}
+		if secret.File != "" {
+			var isSource bool
+			if secret.File, isSource = lc.getLocalPath(secret.File); isSource {
+				usesSources = true
+			}
+		}
+		lc.Secrets[name] = secret
+	}
+	for name, cfg := range config.Configs {
+		if lc.Configs == nil {
+			lc.Configs = map[string]types.ConfigObjConfig{}
+		}
+		if _, ok := lc.Configs[name]; ok {
+			continue
+		}
+		if cfg.File != "" {
+			var isSource bool
+			if cfg.File, isSource = lc.getLocalPath(cfg.File); isSource {
+				usesSources = true
+			}
+		}
+		lc.Configs[name] = cfg
+	}
+	return usesSources
+}
+
+// setDependsOnConditions records the dependencies of the service with their conditions in the long syntax of depends_on.
+// The conditions are kept in the extras of the service by the parser, which cannot have a depends_on key when it is written.
+func (lc *localCompose) setDependsOnConditions(service *types.ServiceConfig) {
+	conditions, ok := service.Extras[dependsOnKey].(map[string]string)
+	if !ok {
+		return
+	}
+	delete(service.Extras, dependsOnKey)
+	dependsOn := map[string]interface{}{}
+	for _, dependencyName := range service.DependsOn {
+		condition := serviceStartedCondition
+		if c, ok := conditions[dependencyName]; ok {
+			condition = c
+		}
+		dependsOn[dependencyName] = map[string]interface{}{dependsOnConditionKey: condition}
+	}
+	if lc.dependsOnConditions == nil {
+		lc.dependsOnConditions = map[string]map[string]interface{}{}
+	}
+	lc.dependsOnConditions[service.Name] = dependsOn
+}
+
+// getActiveDependencies returns the services in depends_on whose profiles are enabled, since the others are not added
+func (lc *localCompose) getActiveDependencies(config types.Config, service types.ServiceConfig) []string {
+	if len(service.DependsOn) == 0 {
+		return service.DependsOn
+	}
+	dependsOn := []string{}
+	for _, dependencyName := range service.DependsOn {
+		for _, dependency := range config.Services {
+			if dependency.Name != dependencyName {
+				continue
+			}
+			if !isProfileActive(getProfilesV3(dependency), lc.activeProfiles) {
+				logrus.Debugf("not adding the dependency %s of the service %s to the local compose file since none of its profiles are enabled", dependencyName, service.Name)
+				break
+			}
+			dependsOn = append(dependsOn, dependencyName)
+			break
+		}
+	}
+	return dependsOn
+}
+
+// addServiceV2 adds the service from the version 1 or 2 compose file merged with its override files, with the env vars interpolated.
+// The service is converted into a version 3 service, so only the keys that are common to both the versions are kept.
+// It returns true if any of the paths of the service refers to the sources.
+func (lc *localCompose) addServiceV2(composeFilePath string, serviceName string, overrideFilePaths ...string) (usesSources bool) {
+	proj, err := getParsedV2(composeFilePath, true, overrideFilePaths...)
+	if err != nil {
+		logrus.Debugf("not adding the service %s to the local compose file since the file %s is not a version 1 or 2 compose file . Error: %q", serviceName, composeFilePath, err)
+		return false
+	}
+	if _, ok := lc.Services[serviceName]; ok {
+		logrus.Warnf("The service %s in the compose file %s has already been added to the local compose file from another compose file. Ignoring it.", serviceName, composeFilePath)
+		return false
+	}
+	composeService, ok := proj.ServiceConfigs.Get(serviceName)
+	if !ok {
+		return false
+	}
+	serviceData, err := yaml.Marshal(composeService)
+	if err != nil {
+		logrus.Errorf("failed to marshal the service %s in the compose file %s . Error: %q", serviceName, composeFilePath, err)
+		return false
+	}
+	serviceDict, err := loader.ParseYAML(serviceData)
+	if err != nil {
+		logrus.Errorf("failed to parse the service %s in the compose file %s . Error: %q", serviceName, composeFilePath, err)
+		return false
+	}
+	for key := range serviceDict {
+		if !common.IsPresent(localComposeV2Keys, key) {
+			logrus.Debugf("not adding the key %s of the service %s to the local compose file since it is not supported in version 3", key, serviceName)
+			delete(serviceDict, key)
+		}
+	}
+	composeFileDir := filepath.Dir(composeFilePath)
+	service, err := loader.LoadService(serviceName, serviceDict, composeFileDir, func(string) (string, bool) { return "", false })
+	if err != nil {
+		logrus.Errorf("failed to convert the service %s in the compose file %s to version 3 . Error: %q", serviceName, composeFilePath, err)
+		return false
+	}
+	for i, volume := range service.Volumes {
+		if volume.Type != "volume" || volume.Source == "" {
+			continue
+		}
+		// the parser prefixes the named volumes with the project name, which docker compose adds again
+		name := strings.TrimPrefix(volume.Source, proj.Name+"_")
+		if _, ok := proj.VolumeConfigs[name]; ok {
+			service.Volumes[i].Source = name
+			volume.Source = name
+		}
+		if lc.Volumes == nil {
+			lc.Volumes = map[string]types.VolumeConfig{}
+		}
+		if _, ok := lc.Volumes[volume.Source]; !ok {
+			lc.Volumes[volume.Source] = types.VolumeConfig{}
+		}
+	}
+	usesSources = lc.addServiceConfig(composeFileDir, *service)
+	if lc.Version == "" {
+		lc.Version = localComposeV2Version
+	}
+	return usesSources
+}
+
+// addServiceConfig adds the service with its paths in the sources made relative to the local compose file.
+// It returns true if any of the paths of the service refers to the sources.
+func (lc *localCompose) addServiceConfig(composeFileDir string, service types.ServiceConfig) (usesSources bool) {
+	// the env files have already been merged into the environment by the parser
+	service.EnvFile = nil
+	if service.Build.Context != "" && !isRemoteBuildContext(service.Build.Context) {
+		contextPath := service.Build.Context
+		if !filepath.IsAbs(contextPath) {
+			contextPath = filepath.Join(composeFileDir, contextPath)
+		}
+		service.Build.Context, usesSources = lc.getLocalPath(contextPath)
+	}
+	for i, volume := range service.Volumes {
+		if volume.Type != "bind" {
+			continue
+		}
+		var isSource bool
+		if service.Volumes[i].Source, isSource = lc.getLocalPath(volume.Source); isSource {
+			usesSources = true
+		}
+	}
+	if develop, ok := service.Extras[developKey].(map[string]interface{}); ok {
+		if watch, ok := develop[watchKey].([]interface{}); ok {
+			for _, val := range watch {
+				rule, ok := val.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				watchPath := cast.ToString(rule["path"])
+				if watchPath == "" {
+					continue
+				}
+				if !filepath.IsAbs(watchPath) {
+					watchPath = filepath.Join(composeFileDir, watchPath)
+				}
+				var isSource bool
+				if rule["path"], isSource = lc.getLocalPath(watchPath); isSource {
+					usesSources = true
+				}
+			}
+		}
+	}
+	lc.Services[service.Name] = service
+	return usesSources
+}
+
+// getLocalPath returns the path relative to the compose file in the output, if the path is in the sources
+func (lc *localCompose) getLocalPath(path string) (string, bool) {
+	if !common.IsParent(path, lc.sourceDir) {
+		return path, false
+	}
+	relPath, err := filepath.Rel(lc.sourceDir, path)
+	if err != nil {
+		logrus.Errorf("failed to make the path %s relative to the source directory %s . Error: %q", path, lc.sourceDir, err)
+		return path, false
+	}
+	localPath, err := filepath.Rel(lc.outputPath, filepath.Join(common.DefaultSourceDir, relPath))
+	if err != nil {
+		logrus.Errorf("failed to make the path %s relative to the directory %s . Error: %q", relPath, lc.outputPath, err)
+		return path, false
+	}
+	return common.GetUnixPath(localPath), true
+}
+
+// isRemoteBuildContext returns true if the build context is a git repository or a URL
+func isRemoteBuildContext(context string) bool {
+	return strings.Contains(context, "://") || strings.HasPrefix(context, "git@") || strings.HasPrefix(context, "github.com/")
+}
+
+// write writes the compose file to the temporary directory and returns the path mapping that copies it to the output
+func (lc *localCompose) write(tempPath string) (transformertypes.PathMapping, error) {
+	absOutputPath := filepath.Join(tempPath, lc.outputPath)
+	if err := os.MkdirAll(absOutputPath, common.DefaultDirectoryPermission); err != nil {
+		return transformertypes.PathMapping{}, fmt.Errorf("failed to create the directory %s . Error: %w", absOutputPath, err)
+	}
+	var composeFile interface{} = lc.composeObj
+	if len(lc.dependsOnConditions) != 0 {
+		composeFileData, err := yaml.Marshal(lc.composeObj)
+		if err != nil {
+			return transformertypes.PathMapping{}, fmt.Errorf("failed to marshal the local compose file. Error: %w", err)
+		}
+		composeFileMap, err := loader.ParseYAML(composeFileData)
+		if err != nil {
+			return transformertypes.PathMapping{}, fmt.Errorf("failed to parse the local compose file. Error: %w", err)
+		}
+		services, _ := composeFileMap["services"].(map[string]interface{})
+		for serviceName, dependsOn := range lc.dependsOnConditions {
+			if service, ok := services[serviceName].(map[string]interface{}); ok {
+				service[dependsOnKey] = dependsOn
+			}
+		}
+		composeFile = composeFileMap
+	}
+	if err := common.WriteYaml(filepath.Join(absOutputPath, localComposeFileName), composeFile); err != nil {
+		return transformertypes.PathMapping{}, fmt.Errorf("failed to write the local compose file to the directory %s . Error: %w", absOutputPath, err)
+	}
+	return transformertypes.PathMapping{
+		Type:     transformertypes.DefaultPathMappingType,
+		SrcPath:  absOutputPath,
+		DestPath: lc.outputPath,
+	}, nil
+}
@@ -0,0 +1,138 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package compose
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+)
+
+func TestLocalComposeAddService(t *testing.T) {
+	sourceDir := t.TempDir()
+	composeFilePath := filepath.Join(sourceDir, "app", "docker-compose.yaml")
+	if err := os.MkdirAll(filepath.Dir(composeFilePath), common.DefaultDirectoryPermission); err != nil {
+		t.Fatalf("failed to create the directory. Error: %q", err)
+	}
+	composeFile := "version: \"3.8\"\nservices:\n  web:\n    build: ./web\n    environment:\n      MODE: ${MODE:-dev}\n    volumes: [\"./data:/data\"]\n  debug:\n    image: busybox\n    profiles: [\"debug\"]\n"
+	if err := os.WriteFile(composeFilePath, []byte(composeFile), common.DefaultFilePermission); err != nil {
+		t.Fatalf("failed to write the compose file. Error: %q", err)
+	}
+	lc := newLocalCompose(sourceDir, defaultLocalComposeOutputPath)
+	if !lc.addService(composeFilePath, "web") {
+		t.Fatalf("expected the service to use the sources")
+	}
+	if lc.addService(composeFilePath, "debug") {
+		t.Fatalf("expected the service in an inactive profile to be ignored")
+	}
+	if len(lc.Services) != 1 {
+		t.Fatalf("expected only the web service. Actual: %+v", lc.Services)
+	}
+	web := lc.Services["web"]
+	if web.Build.Context != "../../source/app/web" {
+		t.Fatalf("wrong build context. Actual: %s", web.Build.Context)
+	}
+	if len(web.Volumes) != 1 || web.Volumes[0].Source != "../../source/app/data" {
+		t.Fatalf("wrong volumes. Actual: %+v", web.Volumes)
+	}
+	if mode := web.Environment["MODE"]; mode == nil || *mode != "dev" {
+		t.Fatalf("expected the env var to be interpolated. Actual: %v", mode)
+	}
+}
+
+func TestLocalComposeAddServiceV2(t *testing.T) {
+	sourceDir := t.TempDir()
+	composeFilePath := filepath.Join(sourceDir, "app", "docker-compose.yaml")
+	if err := os.MkdirAll(filepath.Dir(composeFilePath), common.DefaultDirectoryPermission); err != nil {
+		t.Fatalf("failed to create the directory. Error: %q", err)
+	}
+	composeFile := "version: \"2\"\nservices:\n  web:\n    build: ./web\n    environment:\n      MODE: dev\n    ports: [\"8080:80\"]\n    volumes: [\"./data:/data\", \"cache:/cache\"]\n    mem_limit: 128m\nvolumes:\n  cache: {}\n"
+	if err := os.WriteFile(composeFilePath, []byte(composeFile), common.DefaultFilePermission); err != nil {
+		t.Fatalf("failed to write the compose file. Error: %q", err)
+	}
+	lc := newLocalCompose(sourceDir, defaultLocalComposeOutputPath)
+	if !lc.addServiceV2(composeFilePath, "web") {
+		t.Fatalf("expected the service to use the sources")
+	}
+	web, ok := lc.Services["web"]
+	if !ok {
+		t.Fatalf("expected the web service. Actual: %+v", lc.Services)
+	}
+	if web.Build.Context != "../../source/app/web" {
+		t.Fatalf("wrong build context. Actual: %s", web.Build.Context)
+	}
+	if len(web.Volumes) != 2 || web.Volumes[0].Source != "../../source/app/data" || web.Volumes[1].Source != "cache" {
+		t.Fatalf("wrong volumes. Actual: %+v", web.Volumes)
+	}
+	if _, ok := lc.Volumes["cache"]; !ok {
+		t.Fatalf("expected the named volume to be declared. Actual: %+v", lc.Volumes)
+	}
+	if len(web.Ports) != 1 || web.Ports[0].Target != 80 || web.Ports[0].Published != 8080 {
+		t.Fatalf("wrong ports. Actual: %+v", web.Ports)
+	}
+	if mode := web.Environment["MODE"]; mode == nil || *mode != "dev" {
+		t.Fatalf("expected the env var to be set. Actual: %v", mode)
+	}
+	if lc.Version != localComposeV2Version {
+		t.Fatalf("expected the version %s . Actual: %s", localComposeV2Version, lc.Version)
+	}
+}
+
+func TestLocalComposeDependsOnConditions(t *testing.T) {
+	sourceDir := t.TempDir()
+	composeFilePath := filepath.Join(sourceDir, "docker-compose.yaml")
+	composeFile := "version: \"3.8\"\nservices:\n  web:\n    image: web\n    depends_on:\n      db:\n        condition: service_healthy\n  db:\n    image: postgres\n"
+	if err := os.WriteFile(composeFilePath, []byte(composeFile), common.DefaultFilePermission); err != nil {
+		t.Fatalf("failed to write the compose file. Error: %q", err)
+	}
+	lc := newLocalCompose(sourceDir, defaultLocalComposeOutputPath)
+	lc.addService(composeFilePath, "web")
+	want := map[string]map[string]interface{}{"web": {"db": map[string]interface{}{dependsOnConditionKey: serviceHealthyCondition}}}
+	if diff := cmp.Diff(want, lc.dependsOnConditions); diff != "" {
+		t.Fatalf("expected the dependencies in the long syntax. Differences:\n%s", diff)
+	}
+	tempPath := t.TempDir()
+	if _, err := lc.write(tempPath); err != nil {
+		t.Fatalf("failed to write the local compose file. Error: %q", err)
+	}
+	data, err := os.ReadFile(filepath.Join(tempPath, defaultLocalComposeOutputPath, localComposeFileName))
+	if err != nil {
+		t.Fatalf("failed to read the local compose file. Error: %q", err)
+	}
+	if !strings.Contains(string(data), "condition: service_healthy") {
+		t.Fatalf("expected the condition in the local compose file. Actual:\n%s", data)
+	}
+}
+
+func TestLocalComposeInactiveDependencies(t *testing.T) {
+	sourceDir := t.TempDir()
+	composeFilePath := filepath.Join(sourceDir, "docker-compose.yaml")
+	composeFile := "version: \"3.8\"\nservices:\n  web:\n    image: web\n    depends_on:\n      db:\n        condition: service_started\n      debug:\n        condition: service_started\n  db:\n    image: postgres\n  debug:\n    image: busybox\n    profiles: [\"debug\"]\n"
+	if err := os.WriteFile(composeFilePath, []byte(composeFile), common.DefaultFilePermission); err != nil {
+		t.Fatalf("failed to write the compose file. Error: %q", err)
+	}
+	lc := newLocalCompose(sourceDir, defaultLocalComposeOutputPath)
+	lc.addService(composeFilePath, "web")
+	want := map[string]interface{}{"db": map[string]interface{}{dependsOnConditionKey: serviceStartedCondition}}
+	if diff := cmp.Diff(want, lc.dependsOnConditions["web"]); diff != "" {
+		t.Fatalf("expected only the dependencies in the enabled profiles. Differences:\n%s", diff)
+	}
+}
@@ -0,0 +1,126 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package compose
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/docker/cli/cli/compose/types"
+	"github.com/docker/libcompose/project"
+	"github.com/konveyor/move2kube/common/deepcopy"
+)
+
+// composeParseCache caches the parsed compose files so that a compose file containing
+// multiple services is parsed only once per run and its warnings are logged only once.
+var composeParseCache = newParseCache()
+
+type parseCacheKey struct {
+	// path is the path of the compose file followed by the paths of its override files, separated by the path list separator
+	path        string
+	version     string
+	interpolate bool
+}
+
+type parseCacheEntry struct {
+	once  sync.Once
+	stamp string
+	value interface{}
+	err   error
+}
+
+type parseCache struct {
+	mutex   sync.Mutex
+	entries map[parseCacheKey]*parseCacheEntry
+}
+
+func newParseCache() *parseCache {
+	return &parseCache{entries: map[parseCacheKey]*parseCacheEntry{}}
+}
+
+// get returns the cached result for the key. The file is parsed again if it or its .env file changed.
+func (c *parseCache) get(key parseCacheKey, parse func() (interface{}, error)) (interface{}, error) {
+	stamp := getParseStamp(key.path)
+	c.mutex.Lock()
+	entry, ok := c.entries[key]
+	if !ok || entry.stamp != stamp {
+		entry = &parseCacheEntry{stamp: stamp}
+		c.entries[key] = entry
+	}
+	c.mutex.Unlock()
+	entry.once.Do(func() {
+		entry.value, entry.err = parse()
+	})
+	return entry.value, entry.err
+}
+
+// getParseStamp returns a string that changes when the compose file, its override files or its .env file changes
+func getParseStamp(path string) string {
+	stamp := ""
+	paths := filepath.SplitList(path)
+	for _, p := range append(paths, filepath.Join(filepath.Dir(paths[0]), defaultEnvFile)) {
+		if fi, err := os.Stat(p); err == nil {
+			stamp += fmt.Sprintf("%d-%d;", fi.ModTime().UnixNano(), fi.Size())
+		} else {
+			stamp += "-;"
+		}
+	}
+	return stamp
+}
+
+// getParsedV3 returns the cached result of parsing a version 3 compose file merged with its override files
+func getParsedV3(path string, overridePaths ...string) (*types.Config, error) {
+	value, err := composeParseCache.get(parseCacheKey{path: getParseCachePath(path, overridePaths), version: "v3"}, func() (interface{}, error) {
+		return parseV3(path, overridePaths...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.(*types.Config), nil
+}
+
+// getParsedV2 returns the cached result of parsing a version 1 or 2 compose file merged with its override files
+func getParsedV2(path string, interpolate bool, overridePaths ...string) (*project.Project, error) {
+	value, err := composeParseCache.get(parseCacheKey{path: getParseCachePath(path, overridePaths), version: "v1v2", interpolate: interpolate}, func() (interface{}, error) {
+		return parseV2(path, interpolate, overridePaths...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.(*project.Project), nil
+}
+
+// getParseCachePath returns the path of the cache key for the compose file along with its override files
+func getParseCachePath(path string, overridePaths []string) string {
+	return strings.Join(append([]string{path}, overridePaths...), string(os.PathListSeparator))
+}
+
+// getServiceViewV3 returns a copy of the config containing only the given service.
+// The service is deep copied so that converting it does not modify the cached config.
+func getServiceViewV3(config *types.Config, serviceName string) types.Config {
+	view := *config
+	view.Services = nil
+	for _, service := range config.Services {
+		if service.Name == serviceName {
+			view.Services = append(view.Services, deepcopy.DeepCopy(service).(types.ServiceConfig))
+		}
+	}
+	return view
+}
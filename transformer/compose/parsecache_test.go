@@ -0,0 +1,73 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package compose
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseCache(t *testing.T) {
+	composeFilePath := filepath.Join(t.TempDir(), "docker-compose.yaml")
+	composeFile := `version: "3"
+services:
+  web:
+    image: web:latest
+    labels:
+      tier: frontend
+  db:
+    image: db:latest
+`
+	if err := os.WriteFile(composeFilePath, []byte(composeFile), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := getParsedV3(composeFilePath)
+	if err != nil {
+		t.Fatalf("failed to parse the compose file. Error: %q", err)
+	}
+	cachedConfig, err := getParsedV3(composeFilePath)
+	if err != nil {
+		t.Fatalf("failed to parse the compose file. Error: %q", err)
+	}
+	if config != cachedConfig {
+		t.Fatalf("expected the compose file to be parsed only once")
+	}
+
+	view := getServiceViewV3(config, "web")
+	if len(view.Services) != 1 || view.Services[0].Name != "web" {
+		t.Fatalf("expected the view to contain only the web service. Actual: %+v", view.Services)
+	}
+	view.Services[0].Labels["tier"] = "changed"
+	for _, service := range config.Services {
+		if service.Name == "web" && service.Labels["tier"] != "frontend" {
+			t.Fatalf("modifying the view changed the cached config")
+		}
+	}
+
+	if err := os.WriteFile(composeFilePath, []byte(composeFile+"  cache:\n    image: cache:latest\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	updatedConfig, err := getParsedV3(composeFilePath)
+	if err != nil {
+		t.Fatalf("failed to parse the compose file. Error: %q", err)
+	}
+	if len(updatedConfig.Services) != 3 {
+		t.Fatalf("expected the changed compose file to be parsed again. Actual services: %d", len(updatedConfig.Services))
+	}
+}
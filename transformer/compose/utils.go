@@ -17,19 +17,39 @@
 package compose
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"hash/fnv"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
 
+	"github.com/docker/cli/cli/compose/loader"
 	"github.com/docker/cli/opts"
+	"github.com/docker/go-connections/nat"
+	units "github.com/docker/go-units"
+	libcomposeconfig "github.com/docker/libcompose/config"
+	libcomposeyaml "github.com/docker/libcompose/yaml"
+	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/common/report"
 	"github.com/konveyor/move2kube/qaengine"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
 	irtypes "github.com/konveyor/move2kube/types/ir"
+	qatypes "github.com/konveyor/move2kube/types/qaengine"
+	"github.com/konveyor/move2kube/types/qaengine/commonqa"
 	"github.com/sirupsen/logrus"
+	"github.com/spf13/cast"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation"
 	core "k8s.io/kubernetes/pkg/apis/core"
+	"k8s.io/kubernetes/pkg/apis/networking"
 )
 
 const (
@@ -39,6 +59,24 @@ const (
 	tmpFsPath             string = "tmpfs"
 	defaultSecretBasePath string = "/var/secrets"
 	envFile               string = "env_file"
+	profilesKey           string = "profiles"
+	// configsKey is the top level key of the compose file containing the configs
+	configsKey string = "configs"
+	// configContentKey and configEnvironmentKey are the keys of a config defined using inline content or an env var
+	configContentKey     string = "content"
+	configEnvironmentKey string = "environment"
+	// inlineConfigContentExtra is the extra field holding the contents of a config defined using inline content or an env var
+	inlineConfigContentExtra string = "x-move2kube-inline-content"
+	// developKey is the key of a service containing the development settings, like the files to watch
+	developKey string = "develop"
+	// annotationsKey is the key of a service containing the annotations of its containers
+	annotationsKey string = "annotations"
+	// projectNameKey is the top level key of the compose file containing the name of the compose project
+	projectNameKey string = "name"
+	// overrideFileSuffix is the suffix of the name of the override file that docker compose uses by default, like docker-compose.override.yml
+	overrideFileSuffix = "override"
+	// composeProfilesEnv is the env var containing the comma separated list of the active profiles
+	composeProfilesEnv    string = "COMPOSE_PROFILES"
 	maxConfigMapSizeLimit int    = 1024 * 1024
 	// cfgMapPrefix defines the prefix to be used for config maps
 	cfgMapPrefix = "configmap"
@@ -48,6 +86,111 @@ const (
 	secretOpt    = "Secret"
 	hostPathOpt  = "HostPath"
 	pvcOpt       = "PVC"
+	// useObservedResourcesOpt and keepResourcesOpt are the choices for the resources of a service with collected metrics
+	useObservedResourcesOpt = "Use the requests and limits suggested by the observed usage"
+	keepResourcesOpt        = "Keep the requests and limits of the source"
+	// minCPURequestMillicores and minMemoryRequestBytes keep idle services from getting requests too small to be scheduled sensibly
+	minCPURequestMillicores = 10
+	minMemoryRequestBytes   = 32 * 1024 * 1024
+	// resourceLimitHeadroomPercent is added on top of the peak usage to get the limits
+	resourceLimitHeadroomPercent = 50
+	// cpuSharesKey, cpuQuotaKey, cpuPeriodKey and blkioConfigKey are the keys of a service containing the legacy cpu and block IO settings
+	cpuSharesKey   string = "cpu_shares"
+	cpuQuotaKey    string = "cpu_quota"
+	cpuPeriodKey   string = "cpu_period"
+	blkioConfigKey string = "blkio_config"
+	// dependsOnKey is the key of a service containing the services it depends on
+	dependsOnKey string = "depends_on"
+	// dependsOnConditionKey is the key of a dependency in the long syntax of depends_on containing the condition to wait for
+	dependsOnConditionKey string = "condition"
+	// the conditions of the dependencies of a service
+	serviceStartedCondition               = "service_started"
+	serviceHealthyCondition               = "service_healthy"
+	serviceCompletedSuccessfullyCondition = "service_completed_successfully"
+	// defaultCPUShares is the weight of a container without cpu shares, which is equivalent to a whole cpu
+	defaultCPUShares = 1024
+	// defaultCPUPeriod is the cpu CFS period in microseconds when it is not specified
+	defaultCPUPeriod = 100000
+	// blkioConfigAnnotation is the annotation recording the block IO settings of a service
+	blkioConfigAnnotation = "move2kube.konveyor.io/blkio-config"
+	// deviceRequestsKey is the key of the extras of a service containing the devices reserved in the deploy section and using gpus
+	deviceRequestsKey = "device_requests"
+	// gpusKey is the key of a service containing the GPUs it uses
+	gpusKey = "gpus"
+	// ulimitsKey is the key of a service containing its ulimits and ulimitsAnnotation is the annotation recording them
+	ulimitsKey        = "ulimits"
+	ulimitsAnnotation = "move2kube.konveyor.io/ulimits"
+	// sysctlsKey is the key of a service containing its sysctls
+	sysctlsKey = "sysctls"
+	// dnsOptKey is the key of a service containing its resolver options
+	dnsOptKey = "dns_opt"
+	// maxPortRangeSize is the number of ports in a port range above which the user is asked before exposing all of them
+	maxPortRangeSize = 100
+	// indentationTabWidth is the number of spaces used in place of each tab used for indentation
+	indentationTabWidth = 2
+	// unsetEnvsSuffix and unsetSecretEnvsSuffix are the suffixes of the config maps and secrets holding the env vars whose values are not set
+	unsetEnvsSuffix       = "-unset-envs"
+	unsetSecretEnvsSuffix = "-unset-secret-envs"
+	// healthCheckNone, healthCheckCmd and healthCheckCmdShell are the forms of the health check tests
+	healthCheckNone     = "NONE"
+	healthCheckCmd      = "CMD"
+	healthCheckCmdShell = "CMD-SHELL"
+	// defaultProbePeriodSeconds is the period of the probes when it is not specified
+	defaultProbePeriodSeconds = 10
+	// the probes that can be generated from the health check of a compose service
+	livenessProbeOpt  = "Liveness probe"
+	readinessProbeOpt = "Readiness probe"
+	startupProbeOpt   = "Startup probe"
+	// the strategies of the init containers waiting for the dependencies of a service
+	noDependencyWaitStrategy      = "none"
+	tcpDependencyWaitStrategy     = "tcp"
+	httpDependencyWaitStrategy    = "http"
+	commandDependencyWaitStrategy = "command"
+	// the images for the init containers waiting for the dependencies of a service
+	busyboxDependencyWaitImage = "busybox:1.36.1"
+	curlDependencyWaitImage    = "curlimages/curl:8.4.0"
+	// dependencyWaitHostEnv and dependencyWaitPortEnv are the env vars holding the address of the dependency to wait for
+	dependencyWaitHostEnv = "WAIT_HOST"
+	dependencyWaitPortEnv = "WAIT_PORT"
+	// dependencyWaitContainerPrefix is the prefix of the names of the init containers waiting for the dependencies
+	dependencyWaitContainerPrefix = "wait-for-"
+)
+
+// dependencyWaitCommands are the shell scripts that wait until the dependency accepts connections for each of the strategies
+var dependencyWaitCommands = map[string]string{
+	tcpDependencyWaitStrategy:     `until nc -z "$` + dependencyWaitHostEnv + `" "$` + dependencyWaitPortEnv + `"; do echo "waiting for $` + dependencyWaitHostEnv + `:$` + dependencyWaitPortEnv + `"; sleep 2; done`,
+	httpDependencyWaitStrategy:    `until curl -s -o /dev/null "http://$` + dependencyWaitHostEnv + `:$` + dependencyWaitPortEnv + `/"; do echo "waiting for $` + dependencyWaitHostEnv + `:$` + dependencyWaitPortEnv + `"; sleep 2; done`,
+	commandDependencyWaitStrategy: `until nc -z "$` + dependencyWaitHostEnv + `" "$` + dependencyWaitPortEnv + `"; do sleep 2; done`,
+}
+
+// composeUlimit is the soft and hard limit of a ulimit of a compose service
+type composeUlimit struct {
+	Soft int64 `json:"soft"`
+	Hard int64 `json:"hard"`
+}
+
+// ulimitFollowUps describe how to get the commonly used ulimits in k8s, where the limits are set for the whole node
+var ulimitFollowUps = map[string]string{
+	"nofile":  "Make sure the open files limit of the container runtime on the nodes, like LimitNOFILE of the containerd service, is at least %d",
+	"nproc":   "Make sure the pids limit of the pods, set using podPidsLimit in the kubelet config of the nodes, is at least %d",
+	"memlock": "Make sure the locked memory limit of the container runtime on the nodes, like LimitMEMLOCK of the containerd service, is at least %d",
+}
+
+// composeDependency is a service that another service depends on
+type composeDependency struct {
+	name string
+	port int32
+	// condition is the state of the dependency to wait for, like service_started or service_healthy
+	condition string
+}
+
+var (
+	// composeFileStems are the names of the compose files, without the extensions, that can have override files next to them
+	composeFileStems = []string{"docker-compose", "compose"}
+	// windowsDrivePathRegex matches the absolute Windows paths starting with a drive letter like C:\data or C:/data
+	windowsDrivePathRegex = regexp.MustCompile(`^([a-zA-Z]):([\\/]|$)`)
+	// composeVariableRegex matches the variables used in the compose files, like $VAR, ${VAR}, ${VAR:-default} and ${VAR:?error}
+	composeVariableRegex = regexp.MustCompile(`\$(?:([A-Za-z_][A-Za-z0-9_]*)|\{([A-Za-z_][A-Za-z0-9_]*)(:?[-?+][^}]*)?\})`)
 )
 
 /*
@@ -129,7 +272,20 @@ func applyVolumePolicy(filedir string, serviceName string, volSource string, vol
 	storageOpt := ""
 	volumeName := ""
 	hPath := ""
-	if isPath(volSource) {
+	if isWindowsAbsPath(volSource) {
+		// the Windows host path does not exist in the source directory or on the Linux nodes
+		hPath = toSlashPath(volSource)
+		opt, err := getUserInputsOnStorageType(hPath, serviceName)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		storageOpt = opt
+		volumeName = createVolumeName(hPath, serviceName)
+		if storageOpt == hostPathOpt {
+			volSource = getNodePathForWindowsPath(volSource, serviceName)
+		}
+	} else if isPath(volSource) {
+		volSource = toSlashPath(volSource)
 		hPath = volSource
 		if filepath.IsAbs(hPath) {
 			relPath, err := filepath.Rel(filedir, hPath)
@@ -238,7 +394,7 @@ func getUserInputsOnStorageType(filePath string, serviceName string) (string, er
 	defAnswer := ignoreDataAnswer
 	desc := "Select the storage type to create"
 	hints := []string{"By default, no storage type will be created. Data source will be ignored"}
-	volQaKey := common.JoinQASubKeys(common.VolQaPrefixKey, `"`+serviceName+`"`, ".options")
+	volQaKey := common.JoinQASubKeys(common.VolQaPrefixKey, `"`+serviceName+`"`, "options")
 	options := []string{pvcOpt, ignoreDataAnswer}
 	if isPath(filePath) {
 		isWithinLimits, err := withinK8sConfigSizeLimit(filePath)
@@ -272,13 +428,14 @@ func createStorage(filePath string, storageName string, storageType irtypes.Stor
 		return irtypes.Storage{}, fmt.Errorf("could not identify the volume source path (%s) because <%s>", filePath, err)
 	}
 	if !fileInfo.IsDir() {
-		content, err := os.ReadFile(filePath)
+		content, err := common.ReadFileWithLimit(filePath, common.MaxEmbeddedFileSizeBytes)
 		if err != nil {
-			return irtypes.Storage{}, fmt.Errorf("could not read the file [%s]. Encountered [%s]", filePath, err)
+			reportFileTooLarge(err, storageType, storageName)
+			return irtypes.Storage{}, fmt.Errorf("could not read the file [%s]. Encountered [%w]", filePath, err)
 		}
 		storage.Content = map[string][]byte{storageName: content}
 	} else {
-		dataMap, err := getAllDirContentAsMap(filePath)
+		dataMap, err := getAllDirContentAsMap(filePath, storageType, storageName)
 		if err != nil {
 			return irtypes.Storage{}, fmt.Errorf("could not read the volume source directory [%s]. Encountered [%s]", filePath, err)
 		}
@@ -287,24 +444,36 @@ func createStorage(filePath string, storageName string, storageType irtypes.Stor
 	return storage, nil
 }
 
-func getAllDirContentAsMap(directoryPath string) (map[string][]byte, error) {
+func getAllDirContentAsMap(directoryPath string, storageType irtypes.StorageKindType, storageName string) (map[string][]byte, error) {
 	fileList, err := os.ReadDir(directoryPath)
 	if err != nil {
 		return nil, err
 	}
 	dataMap := map[string][]byte{}
 	count := 0
+	// the limit applies to the total size of all the files since they all end up in the same object
+	remaining := common.MaxEmbeddedFileSizeBytes
 	for _, file := range fileList {
 		if file.IsDir() {
 			continue
 		}
 		fileName := file.Name()
+		if file.Type()&os.ModeSymlink != 0 && common.SymlinkPolicy == common.SkipSymlinkPolicy {
+			common.WarnAboutSymlink(filepath.Join(directoryPath, fileName), "Skipping the symbolic link [%s] in the directory [%s] as per the symlink policy", fileName, directoryPath)
+			continue
+		}
 		logrus.Debugf("Reading file into the data map: [%s]", fileName)
-		data, err := os.ReadFile(filepath.Join(directoryPath, fileName))
+		data, err := common.ReadFileWithLimit(filepath.Join(directoryPath, fileName), remaining)
 		if err != nil {
+			if reportFileTooLarge(err, storageType, storageName) {
+				continue
+			}
 			logrus.Debugf("Unable to read file data : %s", fileName)
 			continue
 		}
+		if remaining >= 0 {
+			remaining -= int64(len(data))
+		}
 		dataMap[fileName] = data
 		count = count + 1
 	}
@@ -312,6 +481,56 @@ func getAllDirContentAsMap(directoryPath string) (map[string][]byte, error) {
 	return dataMap, nil
 }
 
+// reportFileTooLarge reports a file that is not embedded in the storage because it is larger than the limit.
+// It returns false if the error is not a FileTooLargeError.
+func reportFileTooLarge(err error, storageType irtypes.StorageKindType, storageName string) bool {
+	var tooLargeErr *common.FileTooLargeError
+	if !errors.As(err, &tooLargeErr) {
+		return false
+	}
+	logrus.Warnf("Skipping the file [%s] since it can not be embedded in the %s [%s]. Error: %q", tooLargeErr.Path, storageType, storageName, err)
+	report.AddFollowUp("", fmt.Sprintf("Add the contents of the file %s to the %s %s. The file was skipped since it is larger than the limit of %d bytes for embedded files", tooLargeErr.Path, storageType, storageName, tooLargeErr.Limit))
+	return true
+}
+
+// getComposeFileEnvironment returns the env vars used to interpolate the compose file, which are the values in the .env file
+// next to it, if it exists, along with the env vars of move2kube
+func getComposeFileEnvironment(composeFilePath string) map[string]string {
+	envFilePath := filepath.Join(filepath.Dir(composeFilePath), defaultEnvFile)
+	finfo, err := os.Stat(envFilePath)
+	if os.IsNotExist(err) || finfo.IsDir() {
+		logrus.Debugf("Unable to find .env file %s. Ignoring it.", envFilePath)
+		return getEnvironmentVariables("")
+	}
+	logrus.Debugf("Adding .env file from path [%s] values to environment", envFilePath)
+	return getEnvironmentVariables(envFilePath)
+}
+
+// getDefaultActiveProfiles returns the profiles activated using COMPOSE_PROFILES for the compose file
+func getDefaultActiveProfiles(composeFilePath string) []string {
+	activeProfiles := []string{}
+	for _, profile := range strings.Split(getComposeFileEnvironment(composeFilePath)[composeProfilesEnv], ",") {
+		if profile = strings.TrimSpace(profile); profile != "" {
+			activeProfiles = append(activeProfiles, profile)
+		}
+	}
+	return activeProfiles
+}
+
+// isProfileActive returns true if the service with the profiles is enabled by the active profiles.
+// The services without any profiles are always enabled.
+func isProfileActive(profiles []string, activeProfiles []string) bool {
+	if len(profiles) == 0 || common.IsPresent(activeProfiles, "*") {
+		return true
+	}
+	for _, profile := range profiles {
+		if common.IsPresent(activeProfiles, profile) {
+			return true
+		}
+	}
+	return false
+}
+
 func getEnvironmentVariables(envFile string) map[string]string {
 	result := map[string]string{}
 	if len(envFile) > 0 {
@@ -368,8 +587,711 @@ func makeVolumesFromTmpFS(serviceName string, tfsList []string) ([]core.VolumeMo
 	return vmList, vList
 }
 
+// readComposeFile reads the file, normalizing the quirks of the files authored on Windows that docker-compose accepts,
+// like the byte order mark, the UTF-16 encoding and the CRLF line endings
+func readComposeFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return normalizeComposeFileContent(data)
+}
+
+// normalizeComposeFileContent converts the content to UTF-8 without a byte order mark and with LF line endings
+func normalizeComposeFileContent(data []byte) ([]byte, error) {
+	data, err := common.ConvertUtf8AndUtf16ToUtf8(data)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n")), nil
+}
+
+// expandIndentationTabs replaces the tabs used for indentation, which are not allowed in YAML, with spaces.
+// Returns false if there were no such tabs.
+func expandIndentationTabs(data []byte) ([]byte, bool) {
+	lines := bytes.Split(data, []byte("\n"))
+	expanded := false
+	for i, line := range lines {
+		indentation := line[:len(line)-len(bytes.TrimLeft(line, " \t"))]
+		if bytes.IndexByte(indentation, '\t') == -1 {
+			continue
+		}
+		expandedIndentation := bytes.ReplaceAll(indentation, []byte("\t"), bytes.Repeat([]byte(" "), indentationTabWidth))
+		lines[i] = append(expandedIndentation, line[len(indentation):]...)
+		expanded = true
+	}
+	return bytes.Join(lines, []byte("\n")), expanded
+}
+
+// normalizingResourceLookup normalizes the files, like the env files, that libcompose looks up
+type normalizingResourceLookup struct {
+	libcomposeconfig.ResourceLookup
+}
+
+// Lookup returns the normalized content of the file
+func (l *normalizingResourceLookup) Lookup(file, relativeTo string) ([]byte, string, error) {
+	content, path, err := l.ResourceLookup.Lookup(file, relativeTo)
+	if err != nil {
+		return content, path, err
+	}
+	normalizedContent, err := normalizeComposeFileContent(content)
+	if err != nil {
+		logrus.Debugf("failed to normalize the file at path %s . Error: %q", path, err)
+		return content, path, nil
+	}
+	return normalizedContent, path, nil
+}
+
+// variablesEnvLookup looks up the values given for the variables of the compose file
+type variablesEnvLookup struct {
+	variables map[string]string
+}
+
+// Lookup returns the value of the variable as a key=value pair
+func (l *variablesEnvLookup) Lookup(key string, _ *libcomposeconfig.ServiceConfig) []string {
+	if value, ok := l.variables[key]; ok {
+		return []string{key + "=" + value}
+	}
+	return nil
+}
+
+// getUnsetVariables asks for the values of the variables used in the compose file which are neither set in the environment
+// nor in the .env file next to it, and do not have default values
+func getUnsetVariables(path string, fileData []byte, envMap map[string]string) map[string]string {
+	names := []string{}
+	for _, line := range strings.Split(string(fileData), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		// $$ is an escaped dollar sign
+		line = strings.ReplaceAll(line, "$$", "")
+		for _, match := range composeVariableRegex.FindAllStringSubmatch(line, -1) {
+			name := match[1]
+			if name == "" {
+				name = match[2]
+				// the variables with default or alternative values do not have to be set
+				if modifier := strings.TrimPrefix(match[3], ":"); strings.HasPrefix(modifier, "-") || strings.HasPrefix(modifier, "+") {
+					continue
+				}
+			}
+			if _, ok := envMap[name]; !ok {
+				names = common.AppendIfNotPresent(names, name)
+			}
+		}
+	}
+	values := map[string]string{}
+	for _, name := range names {
+		qaKey := common.JoinQASubKeys(common.ConfigComposeVariablesKey, `"`+name+`"`)
+		desc := fmt.Sprintf("The variable '%s' used in the compose file %s is not set. Enter the value :", name, path)
+		hints := []string{fmt.Sprintf("Set the variable in the environment or in the %s file next to the compose file to not be asked. If left empty, an empty string is used.", defaultEnvFile)}
+		value := ""
+		if common.SecretEnvNameRegex.MatchString(name) {
+			value = fetchSecretEnvValue(qaKey, desc, hints)
+		} else {
+			value = qaengine.FetchStringAnswer(qaKey, desc, hints, "", nil)
+		}
+		if value == "" {
+			logrus.Warnf("The variable %s used in the compose file %s is not set. Using an empty string.", name, path)
+		}
+		values[name] = value
+	}
+	return values
+}
+
+// getUnsetEnv asks for the value of an env var that is not set in the compose file.
+// If no value is given, the env var refers to a key in a config map, or a secret for the sensitive env vars,
+// that gets added to the IR with an empty value for the user to fill in before deploying.
+func getUnsetEnv(serviceName, envName string, ir *irtypes.IR) core.EnvVar {
+	isSecret := common.SecretEnvNameRegex.MatchString(envName)
+	qaKey := common.JoinQASubKeys(common.ConfigServicesKey, `"`+serviceName+`"`, common.ConfigEnvForServiceKeySegment, `"`+envName+`"`)
+	desc := fmt.Sprintf("The value of the env var '%s' of the service '%s' is not set. Enter the value :", envName, serviceName)
+	hints := []string{"If left empty, the env var will refer to a key that has to be filled in before deploying"}
+	value := ""
+	if isSecret {
+		value = fetchSecretEnvValue(qaKey, desc, hints)
+	} else {
+		value = qaengine.FetchStringAnswer(qaKey, desc, hints, "", nil)
+	}
+	if value != "" && !isSecret {
+		return core.EnvVar{Name: envName, Value: value}
+	}
+	storageName := common.MakeStringK8sServiceNameCompliant(serviceName + unsetEnvsSuffix)
+	storageType := irtypes.ConfigMapKind
+	if isSecret {
+		storageName = common.MakeStringK8sServiceNameCompliant(serviceName + unsetSecretEnvsSuffix)
+		storageType = irtypes.SecretKind
+	}
+	if value == "" {
+		logrus.Warnf("The value of the env var '%s' of the service '%s' is not set. Fill in the key '%s' of the %s '%s' before deploying", envName, serviceName, envName, storageType, storageName)
+		report.AddFollowUp(serviceName, fmt.Sprintf("Fill in the key '%s' of the %s '%s' with the value of the env var", envName, storageType, storageName))
+	}
+	addEnvToStorage(ir, storageName, storageType, envName, value)
+	envVar := core.EnvVar{Name: envName, ValueFrom: &core.EnvVarSource{}}
+	if isSecret {
+		envVar.ValueFrom.SecretKeyRef = &core.SecretKeySelector{LocalObjectReference: core.LocalObjectReference{Name: storageName}, Key: envName}
+	} else {
+		envVar.ValueFrom.ConfigMapKeyRef = &core.ConfigMapKeySelector{LocalObjectReference: core.LocalObjectReference{Name: storageName}, Key: envName}
+	}
+	return envVar
+}
+
+// fetchSecretEnvValue asks a password type question which, unlike the other password questions, can be left empty
+func fetchSecretEnvValue(qaKey, desc string, hints []string) string {
+	problem, err := qatypes.NewPasswordProblem(qaKey, desc, hints, nil)
+	if err != nil {
+		logrus.Errorf("failed to create the problem. Error: %q", err)
+		return ""
+	}
+	problem.Default = ""
+	problem, err = qaengine.FetchAnswer(problem)
+	if err != nil {
+		logrus.Errorf("failed to fetch the answer. Error: %q", err)
+		return ""
+	}
+	value, ok := problem.Answer.(string)
+	if !ok {
+		logrus.Errorf("the answer is not of the correct type. Expected string. Actual value is %+v of type %T", problem.Answer, problem.Answer)
+		return ""
+	}
+	return value
+}
+
+// addEnvToStorage adds the env var to the config map or secret in the IR, creating it if necessary
+func addEnvToStorage(ir *irtypes.IR, storageName string, storageType irtypes.StorageKindType, envName, value string) {
+	for i, storage := range ir.Storages {
+		if storage.Name == storageName {
+			ir.Storages[i].Content[envName] = []byte(value)
+			return
+		}
+	}
+	ir.Storages = append(ir.Storages, irtypes.Storage{
+		Name:        storageName,
+		StorageType: storageType,
+		Content:     map[string][]byte{envName: []byte(value)},
+	})
+}
+
+// addDependencyWaitInitContainers adds init containers that wait until the dependencies of the service accept connections
+func addDependencyWaitInitContainers(ir irtypes.IR, serviceName string, dependencies []composeDependency) {
+	service, ok := ir.Services[serviceName]
+	if !ok || len(dependencies) == 0 {
+		return
+	}
+	// the compose file asks to wait for the dependencies to be healthy, so wait for them by default
+	defaultStrategy := noDependencyWaitStrategy
+	for _, dependency := range dependencies {
+		if dependency.condition == serviceHealthyCondition {
+			defaultStrategy = tcpDependencyWaitStrategy
+			break
+		}
+	}
+	strategy := qaengine.FetchSelectAnswer(
+		common.JoinQASubKeys(common.ConfigServicesKey, `"`+serviceName+`"`, common.ConfigDependencyWaitStrategyForServiceKeySegment),
+		fmt.Sprintf("Select how the service '%s' should wait for the services it depends on to start :", serviceName),
+		[]string{
+			"An init container is added for each of the services in depends_on. Choose " + noDependencyWaitStrategy + " to not wait.",
+			"The k8s services only accept connections once the pods are ready, so the dependencies with the condition " + serviceHealthyCondition + " are waited for until their health checks pass.",
+		},
+		defaultStrategy,
+		[]string{noDependencyWaitStrategy, tcpDependencyWaitStrategy, httpDependencyWaitStrategy, commandDependencyWaitStrategy},
+		nil,
+	)
+	command, ok := dependencyWaitCommands[strategy]
+	if !ok {
+		return
+	}
+	defaultImage := busyboxDependencyWaitImage
+	if strategy == httpDependencyWaitStrategy {
+		defaultImage = curlDependencyWaitImage
+	}
+	image := qaengine.FetchSelectAnswer(
+		common.ConfigDependencyWaitImageKey,
+		"Select the image of the init containers waiting for the dependencies :",
+		[]string{"Use an approved utility image if the public images are not allowed in the cluster."},
+		defaultImage,
+		[]string{busyboxDependencyWaitImage, curlDependencyWaitImage, qatypes.OtherAnswer},
+		nil,
+	)
+	if strategy == commandDependencyWaitStrategy {
+		command = qaengine.FetchStringAnswer(
+			common.ConfigDependencyWaitCommandKey,
+			"Enter the shell command that waits for a dependency :",
+			[]string{fmt.Sprintf("The host and the port of the dependency are in the env vars %s and %s", dependencyWaitHostEnv, dependencyWaitPortEnv)},
+			command,
+			nil,
+		)
+	}
+	for _, dependency := range dependencies {
+		service.InitContainers = append(service.InitContainers, core.Container{
+			Name:    common.NormalizeForMetadataName(dependencyWaitContainerPrefix + dependency.name),
+			Image:   image,
+			Command: []string{"sh", "-c", command},
+			Env: []core.EnvVar{
+				{Name: dependencyWaitHostEnv, Value: dependency.name},
+				{Name: dependencyWaitPortEnv, Value: cast.ToString(dependency.port)},
+			},
+		})
+	}
+	ir.Services[serviceName] = service
+}
+
+// setHealthCheckProbes sets the probes of the container chosen to be generated from the health check of the compose service.
+// The startup probe gets the start period of the health check, so that slow starting containers are not restarted by the liveness probe.
+func setHealthCheckProbes(container *core.Container, probe *core.Probe) {
+	if probe == nil {
+		return
+	}
+	probes := qaengine.FetchMultiSelectAnswer(
+		common.ConfigComposeHealthCheckProbesKey,
+		"Select the probes to generate from the health checks of the compose services:",
+		[]string{
+			"The liveness probe restarts the container when the health check fails.",
+			"The readiness probe stops sending traffic to the container when the health check fails.",
+			"The startup probe gives the container the start period of the health check before the liveness probe starts.",
+		},
+		[]string{livenessProbeOpt, readinessProbeOpt, startupProbeOpt},
+		[]string{livenessProbeOpt, readinessProbeOpt, startupProbeOpt},
+		nil,
+	)
+	var startupProbe *core.Probe
+	if common.IsPresent(probes, startupProbeOpt) {
+		probe, startupProbe = getStartupProbe(probe)
+		container.StartupProbe = startupProbe
+	}
+	if common.IsPresent(probes, readinessProbeOpt) {
+		container.ReadinessProbe = probe.DeepCopy()
+	}
+	if common.IsPresent(probes, livenessProbeOpt) {
+		container.LivenessProbe = probe
+	}
+}
+
+// addHealthCheckReadinessProbes uses the health checks of the containers of the service as their readiness probes,
+// so that the services waiting for the service to be healthy only connect to it once the health checks pass
+func addHealthCheckReadinessProbes(ir irtypes.IR, serviceName string) {
+	service, ok := ir.Services[serviceName]
+	if !ok {
+		return
+	}
+	for i, container := range service.Containers {
+		if container.LivenessProbe == nil || container.ReadinessProbe != nil {
+			continue
+		}
+		service.Containers[i].ReadinessProbe = container.LivenessProbe.DeepCopy()
+	}
+	ir.Services[serviceName] = service
+}
+
+// composePortMapping is a container port of a compose service along with the port it is published on
+type composePortMapping struct {
+	// published is 0 if the port is not published
+	published int32
+	target    int32
+	protocol  core.Protocol
+}
+
+// parsePortSpec parses a port in the short syntax, like "3000", "8000:8000", "127.0.0.1:8001:8001", "3000-3010:3000-3010" or "6060:6060/udp".
+// The port ranges are expanded into the individual ports.
+func parsePortSpec(value string) ([]composePortMapping, error) {
+	portMappings, err := nat.ParsePortSpec(value)
+	if err != nil {
+		return nil, err
+	}
+	mappings := []composePortMapping{}
+	for _, portMapping := range portMappings {
+		mapping := composePortMapping{target: int32(portMapping.Port.Int()), protocol: getPortProtocol(portMapping.Port.Proto())}
+		if portMapping.Binding.HostPort != "" {
+			// a host port range with a single container port publishes the container port on one of the host ports
+			startHostPort, _, err := nat.ParsePortRange(portMapping.Binding.HostPort)
+			if err != nil {
+				return nil, err
+			}
+			mapping.published = int32(startHostPort)
+		}
+		mappings = append(mappings, mapping)
+	}
+	return mappings, nil
+}
+
+// getPortProtocol returns the protocol for the protocol suffix of a compose port
+func getPortProtocol(protocol string) core.Protocol {
+	switch strings.ToUpper(protocol) {
+	case string(core.ProtocolUDP):
+		return core.ProtocolUDP
+	case string(core.ProtocolSCTP):
+		return core.ProtocolSCTP
+	}
+	return core.ProtocolTCP
+}
+
+// getPortMappings parses the ports in the short syntax. The unpublished ports are published on the same port if publishUnpublished is true.
+func getPortMappings(serviceName string, values []string, publishUnpublished bool) []composePortMapping {
+	mappings := []composePortMapping{}
+	for _, value := range values {
+		valueMappings, err := parsePortSpec(value)
+		if err != nil {
+			logrus.Warnf("Failed to parse the port %s of the service %s . Ignoring it. Error: %q", value, serviceName, err)
+			continue
+		}
+		for _, mapping := range capPortRange(serviceName, value, valueMappings) {
+			if mapping.published == 0 && publishUnpublished {
+				mapping.published = mapping.target
+			}
+			mappings = append(mappings, mapping)
+		}
+	}
+	return mappings
+}
+
+// capPortRange returns only the first port of a huge port range, unless the user wants to expose all of them
+func capPortRange(serviceName string, portRange string, mappings []composePortMapping) []composePortMapping {
+	if len(mappings) <= maxPortRangeSize || commonqa.ExpandPortRange(serviceName, portRange, len(mappings)) {
+		return mappings
+	}
+	return mappings[:1]
+}
+
+// getContainerPorts returns the container ports for the port mappings
+func getContainerPorts(mappings []composePortMapping) []core.ContainerPort {
+	ports := []core.ContainerPort{}
+	exist := map[composePortMapping]bool{}
+	for _, mapping := range mappings {
+		key := composePortMapping{target: mapping.target, protocol: mapping.protocol}
+		if exist[key] {
+			continue
+		}
+		// Add the port to the k8s pod.
+		ports = append(ports, core.ContainerPort{ContainerPort: mapping.target, Protocol: mapping.protocol})
+		exist[key] = true
+	}
+	return ports
+}
+
+// addPortForwardings forwards the published ports on the k8s service to the container ports.
+// The ports published on a random host port are forwarded from the same port and marked as ephemeral.
+func addPortForwardings(mappings []composePortMapping, service *irtypes.Service) {
+	for _, mapping := range mappings {
+		// Forward the port on the k8s service to the k8s pod.
+		forwarding := irtypes.ServiceToPodPortForwarding{
+			ServicePort: networking.ServiceBackendPort{Number: mapping.published},
+			PodPort:     networking.ServiceBackendPort{Number: mapping.target},
+			Protocol:    mapping.protocol,
+		}
+		if mapping.published == 0 {
+			forwarding.ServicePort.Number = mapping.target
+			forwarding.Ephemeral = true
+		}
+		if err := service.MergePortForwarding(forwarding); err != nil {
+			logrus.Debugf("failed to forward the port %d to the port %d of the service %s . Error: %q", forwarding.ServicePort.Number, mapping.target, service.Name, err)
+		}
+	}
+}
+
+// addLegacyCPUResources converts the cpu quota and period into a cpu limit and asks for a cpu request for the cpu shares,
+// unless the container already has them
+func addLegacyCPUResources(serviceName string, container *core.Container, cpuShares, cpuQuota, cpuPeriod int64) {
+	if cpuQuota > 0 {
+		if cpuPeriod <= 0 {
+			cpuPeriod = defaultCPUPeriod
+		}
+		if _, ok := container.Resources.Limits[core.ResourceCPU]; ok {
+			logrus.Warnf("Ignoring the %s of the service %s since it already has a cpu limit", cpuQuotaKey, serviceName)
+		} else {
+			if container.Resources.Limits == nil {
+				container.Resources.Limits = core.ResourceList{}
+			}
+			container.Resources.Limits[core.ResourceCPU] = *resource.NewMilliQuantity(cpuQuota*1000/cpuPeriod, resource.DecimalSI)
+		}
+	}
+	if cpuShares > 0 {
+		if _, ok := container.Resources.Requests[core.ResourceCPU]; ok {
+			logrus.Warnf("Ignoring the %s of the service %s since it already has a cpu request", cpuSharesKey, serviceName)
+			return
+		}
+		// the cpu shares are a weight relative to the other containers on the same host and not an amount of cpu,
+		// so the cpu request is asked for, with the request the kubelet would convert into the same weight as a hint
+		cpuRequest := qaengine.FetchStringAnswer(
+			common.JoinQASubKeys(common.ConfigServicesKey, `"`+serviceName+`"`, common.ConfigCPURequestForServiceKeySegment),
+			fmt.Sprintf("The service '%s' has the cpu shares %d . Enter the cpu request of the service, like 500m, or leave it empty to not set one :", serviceName, cpuShares),
+			[]string{fmt.Sprintf("The cpu shares are a weight relative to the other containers on the host, where %d is the default weight. The kubelet converts a cpu request of %dm into the same weight.", defaultCPUShares, cpuShares*1000/defaultCPUShares)},
+			"",
+			func(ans interface{}) error {
+				if cast.ToString(ans) == "" {
+					return nil
+				}
+				_, err := resource.ParseQuantity(cast.ToString(ans))
+				return err
+			},
+		)
+		if cpuRequest == "" {
+			report.AddDroppedField(serviceName, cpuSharesKey, "the cpu shares are a relative weight which cannot be converted into a cpu request on their own")
+			return
+		}
+		quantity, err := resource.ParseQuantity(cpuRequest)
+		if err != nil {
+			logrus.Errorf("failed to parse the cpu request %s of the service %s . Error: %q", cpuRequest, serviceName, err)
+			return
+		}
+		if limit, ok := container.Resources.Limits[core.ResourceCPU]; ok && quantity.Cmp(limit) > 0 {
+			logrus.Warnf("The cpu request %s of the service %s is more than its cpu limit. Using the cpu limit %s as the request.", cpuRequest, serviceName, limit.String())
+			quantity = limit
+		}
+		if container.Resources.Requests == nil {
+			container.Resources.Requests = core.ResourceList{}
+		}
+		container.Resources.Requests[core.ResourceCPU] = quantity
+	}
+}
+
+// addBlkioConfigAnnotation records the block IO settings of the service in an annotation, since k8s has no equivalent for them
+func addBlkioConfigAnnotation(serviceName string, service *irtypes.Service, blkioConfig interface{}) {
+	blkioConfigBytes, err := json.Marshal(convertToJSONCompatible(blkioConfig))
+	if err != nil {
+		logrus.Errorf("failed to marshal the %s of the service %s . Error: %q", blkioConfigKey, serviceName, err)
+		return
+	}
+	addServiceAnnotation(service, blkioConfigAnnotation, string(blkioConfigBytes))
+	logrus.Warnf("The %s of the service %s is not supported in k8s. It has been recorded in the annotation %s", blkioConfigKey, serviceName, blkioConfigAnnotation)
+	report.AddDroppedField(serviceName, blkioConfigKey, fmt.Sprintf("k8s does not support block IO weights and limits. They have been recorded in the annotation %s", blkioConfigAnnotation))
+}
+
+// addUlimits records the ulimits of the service in an annotation, since k8s can not set the ulimits of a container.
+// Each ulimit is reported as dropped, along with the node settings to check for the commonly used ones.
+func addUlimits(serviceName string, service *irtypes.Service, ulimits map[string]composeUlimit) {
+	if len(ulimits) == 0 {
+		return
+	}
+	ulimitsBytes, err := json.Marshal(ulimits)
+	if err != nil {
+		logrus.Errorf("failed to marshal the %s of the service %s . Error: %q", ulimitsKey, serviceName, err)
+		return
+	}
+	addServiceAnnotation(service, ulimitsAnnotation, string(ulimitsBytes))
+	logrus.Warnf("The %s of the service %s are not supported in k8s. They have been recorded in the annotation %s", ulimitsKey, serviceName, ulimitsAnnotation)
+	names := []string{}
+	for name := range ulimits {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		ulimit := ulimits[name]
+		report.AddDroppedField(serviceName, ulimitsKey+"."+name, fmt.Sprintf("k8s can not set the ulimits of a container. The soft limit %d and the hard limit %d have been recorded in the annotation %s", ulimit.Soft, ulimit.Hard, ulimitsAnnotation))
+		if followUp, ok := ulimitFollowUps[name]; ok {
+			report.AddFollowUp(serviceName, fmt.Sprintf(followUp, ulimit.Soft))
+		}
+	}
+}
+
+// getIRServiceName returns the name of the IR service for the compose service. The names of the services are normalized during
+// planning, where the services whose names collide after normalization get a hash suffix, so the planned names are used if known.
+func getIRServiceName(serviceNames map[string]string, composeServiceName string) string {
+	if serviceName, ok := serviceNames[composeServiceName]; ok {
+		return serviceName
+	}
+	return common.NormalizeForMetadataName(composeServiceName)
+}
+
+// setDNSConfig sets the nameservers, the search domains and the resolver options of the service in the DNS config of the pod.
+// In compose the embedded DNS server still resolves the names of the other services and only forwards the other names to the
+// nameservers, so the nameservers are added to the DNS of the cluster, unless the service is chosen to use only the nameservers.
+func setDNSConfig(serviceName string, service *irtypes.Service, nameservers, searches, options []string) {
+	if len(nameservers) == 0 && len(searches) == 0 && len(options) == 0 {
+		return
+	}
+	dnsConfig := &core.PodDNSConfig{Nameservers: nameservers, Searches: searches}
+	for _, option := range options {
+		name, value, ok := strings.Cut(option, ":")
+		dnsOption := core.PodDNSConfigOption{Name: name}
+		if ok {
+			dnsOption.Value = &value
+		}
+		dnsConfig.Options = append(dnsConfig.Options, dnsOption)
+	}
+	service.DNSConfig = dnsConfig
+	// without nameservers the search domains and the options are added to the DNS config of the cluster
+	if len(nameservers) == 0 {
+		return
+	}
+	if !qaengine.FetchBoolAnswer(
+		common.JoinQASubKeys(common.ConfigServicesKey, `"`+serviceName+`"`, common.ConfigOnlyNameserversForServiceKeySegment),
+		fmt.Sprintf("The service '%s' uses the nameservers %s . Use only them instead of the DNS of the cluster?", serviceName, strings.Join(nameservers, ", ")),
+		[]string{"The names of the other services can only be resolved using the DNS of the cluster. Otherwise the nameservers are added after the nameserver of the cluster."},
+		false,
+		nil,
+	) {
+		report.AddFollowUp(serviceName, fmt.Sprintf("The nameservers %s are added after the nameserver of the cluster. At most 3 nameservers are used by the pods", strings.Join(nameservers, ", ")))
+		return
+	}
+	service.DNSPolicy = core.DNSNone
+	logrus.Warnf("The service '%s' uses the nameservers %+v instead of the DNS of the cluster.", serviceName, nameservers)
+	report.AddFollowUp(serviceName, fmt.Sprintf("Make sure the nameservers %s resolve the names of the other services, since the service does not use the DNS of the cluster", strings.Join(nameservers, ", ")))
+}
+
+// addServiceAnnotation adds the annotation to the service
+func addServiceAnnotation(service *irtypes.Service, key, value string) {
+	// copy the annotations since they can be shared with the labels
+	annotations := map[string]string{}
+	for k, v := range service.Annotations {
+		annotations[k] = v
+	}
+	annotations[key] = value
+	service.Annotations = annotations
+}
+
+// convertToJSONCompatible converts the maps with interface keys, as parsed from yaml, into maps with string keys
+func convertToJSONCompatible(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		m := map[string]interface{}{}
+		for key, val := range v {
+			m[cast.ToString(key)] = convertToJSONCompatible(val)
+		}
+		return m
+	case map[string]interface{}:
+		m := map[string]interface{}{}
+		for key, val := range v {
+			m[key] = convertToJSONCompatible(val)
+		}
+		return m
+	case []interface{}:
+		vals := []interface{}{}
+		for _, val := range v {
+			vals = append(vals, convertToJSONCompatible(val))
+		}
+		return vals
+	default:
+		return v
+	}
+}
+
+// getLabelsAndAnnotations splits the labels and annotations of a compose service into k8s labels and annotations.
+// The labels which are not valid k8s labels are kept as annotations, since labels are commonly used for descriptions in compose files.
+func getLabelsAndAnnotations(serviceName string, labels, deployLabels, annotations map[string]string) (map[string]string, map[string]string) {
+	k8sLabels := map[string]string{}
+	k8sAnnotations := map[string]string{}
+	for key, value := range annotations {
+		k8sAnnotations[key] = value
+	}
+	allLabels := map[string]string{}
+	for key, value := range labels {
+		allLabels[key] = value
+	}
+	for key, value := range deployLabels {
+		if labelValue, ok := allLabels[key]; ok && labelValue != value {
+			logrus.Warnf("The label %s of the service %s has the value %q in the deploy section and %q otherwise. Using the value in the deploy section.", key, serviceName, value, labelValue)
+		}
+		allLabels[key] = value
+	}
+	keys := []string{}
+	for key := range allLabels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := allLabels[key]
+		annotationValue, isAnnotation := k8sAnnotations[key]
+		if errs := append(validation.IsQualifiedName(key), validation.IsValidLabelValue(value)...); len(errs) != 0 {
+			if isAnnotation {
+				if annotationValue != value {
+					logrus.Warnf("The label %s of the service %s is not a valid k8s label and conflicts with the annotation of the same name. Using the annotation. Errors: %+v", key, serviceName, errs)
+				}
+				continue
+			}
+			logrus.Warnf("The label %s of the service %s is not a valid k8s label. Using it as an annotation. Errors: %+v", key, serviceName, errs)
+			k8sAnnotations[key] = value
+			continue
+		}
+		if isAnnotation && annotationValue != value {
+			logrus.Warnf("The service %s has both a label and an annotation named %s with the values %q and %q . Using both of them.", serviceName, key, value, annotationValue)
+		}
+		k8sLabels[key] = value
+	}
+	return k8sLabels, k8sAnnotations
+}
+
+// getStringMap converts a map or a list of key=value pairs, as parsed from yaml, into a map
+func getStringMap(value interface{}) (map[string]string, error) {
+	stringMap := map[string]string{}
+	switch v := value.(type) {
+	case nil:
+	case map[string]interface{}:
+		for key, val := range v {
+			stringMap[key] = cast.ToString(val)
+		}
+	case map[interface{}]interface{}:
+		for key, val := range v {
+			stringMap[cast.ToString(key)] = cast.ToString(val)
+		}
+	case []interface{}:
+		for _, item := range v {
+			key, val, _ := strings.Cut(cast.ToString(item), "=")
+			stringMap[key] = val
+		}
+	default:
+		return nil, fmt.Errorf("expected a map or a list of key=value pairs. Actual: %T", value)
+	}
+	return stringMap, nil
+}
+
+// getDependencyPort returns the port of the k8s service of the dependency
+func getDependencyPort(dependencyName string, dependency irtypes.Service) (int32, bool) {
+	for _, forwarding := range dependency.ServiceToPodPortForwardings {
+		if forwarding.ServicePort.Number != 0 {
+			return forwarding.ServicePort.Number, true
+		}
+		if forwarding.PodPort.Number != 0 {
+			return forwarding.PodPort.Number, true
+		}
+	}
+	logrus.Warnf("The service %s has no ports. Not waiting for it to start", dependencyName)
+	return 0, false
+}
+
 func isPath(substring string) bool {
-	return strings.Contains(substring, "/") || substring == "."
+	return strings.Contains(substring, "/") || strings.Contains(substring, `\`) || substring == "." || isWindowsAbsPath(substring)
+}
+
+// isWindowsAbsPath returns true if the path is an absolute Windows path with a drive letter or a UNC path
+func isWindowsAbsPath(p string) bool {
+	return windowsDrivePathRegex.MatchString(p) || strings.HasPrefix(p, `\\`)
+}
+
+// toSlashPath converts the Windows path separators in the path to forward slashes
+func toSlashPath(p string) string {
+	return strings.ReplaceAll(p, `\`, "/")
+}
+
+// getNodePathForWindowsPath asks for the path on the Linux nodes to mount in place of a Windows host path.
+// By default C:\data is mapped to /mnt/c/data and \\server\share to /mnt/server/share.
+func getNodePathForWindowsPath(windowsPath string, serviceName string) string {
+	defAnswer := "/mnt/" + strings.TrimPrefix(toSlashPath(windowsPath), "//")
+	if matches := windowsDrivePathRegex.FindStringSubmatch(windowsPath); matches != nil {
+		defAnswer = path.Join("/mnt", strings.ToLower(matches[1]), toSlashPath(windowsPath[len(matches[1])+1:]))
+	}
+	defAnswer = path.Clean(defAnswer)
+	qaKey := common.JoinQASubKeys(common.VolHostPathQaPrefixKey, `"`+serviceName+`"`, `"`+toSlashPath(windowsPath)+`"`)
+	desc := fmt.Sprintf("The host path '%s' of the service '%s' is a Windows path which will not exist on the Linux nodes. Enter the path on the nodes to mount instead :", windowsPath, serviceName)
+	hints := []string{fmt.Sprintf("By default, the path %s will be mounted", defAnswer)}
+	nodePath := qaengine.FetchStringAnswer(qaKey, desc, hints, defAnswer, nil)
+	if !path.IsAbs(nodePath) {
+		logrus.Warnf("The node path '%s' for the Windows path '%s' is not absolute. Using the path %s instead", nodePath, windowsPath, defAnswer)
+		return defAnswer
+	}
+	return nodePath
+}
+
+// fixWindowsVolume fixes a volume whose Windows host path has been split at the drive letter.
+// The volume C:\data:/data:ro gets parsed into the source C, the destination \data and the mode /data:ro .
+func fixWindowsVolume(vol libcomposeyaml.Volume) libcomposeyaml.Volume {
+	if len(vol.Source) != 1 || !windowsDrivePathRegex.MatchString(vol.Source+":"+vol.Destination) || !strings.HasPrefix(vol.AccessMode, "/") {
+		return vol
+	}
+	destinationAndMode := strings.SplitN(vol.AccessMode, ":", 2)
+	fixedVol := libcomposeyaml.Volume{Source: vol.Source + ":" + vol.Destination, Destination: destinationAndMode[0]}
+	if len(destinationAndMode) == 2 {
+		fixedVol.AccessMode = destinationAndMode[1]
+	}
+	return fixedVol
 }
 
 func getHash(data []byte) uint64 {
@@ -377,3 +1299,194 @@ func getHash(data []byte) uint64 {
 	hasher.Write(data)
 	return hasher.Sum64()
 }
+
+// getImageInfoKey returns the fully qualified name of the image.
+// This allows short names like nginx to match tags like index.docker.io/library/nginx:latest
+func getImageInfoKey(imageName string) string {
+	ref, err := name.ParseReference(imageName)
+	if err != nil {
+		return imageName
+	}
+	return ref.Name()
+}
+
+// getImageExposedPorts returns the ports exposed by the image in the format used by the compose expose directive
+func getImageExposedPorts(imageInfo *collecttypes.ImageInfoSpec) []string {
+	ports := []string{}
+	if imageInfo == nil {
+		return ports
+	}
+	for _, port := range imageInfo.PortsToExpose {
+		ports = append(ports, cast.ToString(port))
+	}
+	return ports
+}
+
+// getImageUserID returns the user id the image runs as, or nil if it is not known
+func getImageUserID(imageInfo *collecttypes.ImageInfoSpec) *int64 {
+	if imageInfo == nil || imageInfo.UserID < 0 {
+		return nil
+	}
+	uid := int64(imageInfo.UserID)
+	return &uid
+}
+
+// getDockerResourceMetrics returns the resource metrics collected from a docker daemon, keyed by the path of the file
+func getDockerResourceMetrics(yamlPaths []string) map[string]collecttypes.ResourceMetrics {
+	resourceMetrics := map[string]collecttypes.ResourceMetrics{}
+	for _, yamlPath := range yamlPaths {
+		metrics := collecttypes.ResourceMetrics{}
+		if err := common.ReadMove2KubeYaml(yamlPath, &metrics); err != nil || metrics.Kind != string(collecttypes.ResourceMetricsKind) {
+			continue
+		}
+		if metrics.Spec.Source != collecttypes.DockerMetricsSource {
+			continue
+		}
+		resourceMetrics[yamlPath] = metrics
+	}
+	return resourceMetrics
+}
+
+// getResourceMetricsPaths returns the paths of the resource metrics that contain the given workload or container
+func getResourceMetricsPaths(resourceMetrics map[string]collecttypes.ResourceMetrics, workload, container string) []string {
+	paths := []string{}
+	for path, metrics := range resourceMetrics {
+		if _, ok := metrics.GetContainerMetrics(workload, container); ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// applyResourceMetrics asks whether the requests and limits suggested by the observed usage should replace the resources of the container
+func applyResourceMetrics(serviceName string, container *core.Container, metricsPaths []string, workload, containerName string) {
+	for _, metricsPath := range metricsPaths {
+		metrics := collecttypes.ResourceMetrics{}
+		if err := common.ReadMove2KubeYaml(metricsPath, &metrics); err != nil {
+			logrus.Errorf("failed to read the resource metrics yaml at path '%s' . Error: %q", metricsPath, err)
+			continue
+		}
+		containerMetrics, ok := metrics.GetContainerMetrics(workload, containerName)
+		if !ok {
+			continue
+		}
+		suggested := getSuggestedResources(containerMetrics)
+		options := []string{useObservedResourcesOpt, keepResourcesOpt}
+		hints := []string{
+			fmt.Sprintf("Observed over %d samples: cpu average %dm peak %dm, memory average %s peak %s", metrics.Spec.Samples,
+				containerMetrics.CPU.Average, containerMetrics.CPU.Peak, units.BytesSize(float64(containerMetrics.Memory.Average)), units.BytesSize(float64(containerMetrics.Memory.Peak))),
+			"Suggested " + formatResources(suggested),
+			fmt.Sprintf("The requests cover the average cpu and the peak memory usage. The limits add %d%% to the peak usage.", resourceLimitHeadroomPercent),
+		}
+		if len(container.Resources.Requests) != 0 || len(container.Resources.Limits) != 0 {
+			hints = append(hints, "Source "+formatResources(container.Resources))
+		}
+		selectedOption := qaengine.FetchSelectAnswer(
+			common.JoinQASubKeys(common.ConfigServicesKey, `"`+serviceName+`"`, common.ConfigResourcesForServiceKeySegment),
+			fmt.Sprintf("Select the resource requests and limits for the service '%s' :", serviceName),
+			hints, useObservedResourcesOpt, options, nil,
+		)
+		if selectedOption == useObservedResourcesOpt {
+			container.Resources = suggested
+		}
+		return
+	}
+}
+
+// getSuggestedResources returns the requests and limits for the observed usage
+func getSuggestedResources(containerMetrics collecttypes.ContainerMetrics) core.ResourceRequirements {
+	cpuRequest := containerMetrics.CPU.Average
+	if cpuRequest < minCPURequestMillicores {
+		cpuRequest = minCPURequestMillicores
+	}
+	memoryRequest := roundUpToMebibytes(containerMetrics.Memory.Peak)
+	if memoryRequest < minMemoryRequestBytes {
+		memoryRequest = minMemoryRequestBytes
+	}
+	cpuLimit := containerMetrics.CPU.Peak * (100 + resourceLimitHeadroomPercent) / 100
+	if cpuLimit < cpuRequest {
+		cpuLimit = cpuRequest
+	}
+	memoryLimit := roundUpToMebibytes(containerMetrics.Memory.Peak * (100 + resourceLimitHeadroomPercent) / 100)
+	if memoryLimit < memoryRequest {
+		memoryLimit = memoryRequest
+	}
+	return core.ResourceRequirements{
+		Requests: core.ResourceList{
+			core.ResourceCPU:    *resource.NewMilliQuantity(cpuRequest, resource.DecimalSI),
+			core.ResourceMemory: *resource.NewQuantity(memoryRequest, resource.BinarySI),
+		},
+		Limits: core.ResourceList{
+			core.ResourceCPU:    *resource.NewMilliQuantity(cpuLimit, resource.DecimalSI),
+			core.ResourceMemory: *resource.NewQuantity(memoryLimit, resource.BinarySI),
+		},
+	}
+}
+
+func roundUpToMebibytes(bytes int64) int64 {
+	const mebibyte = 1024 * 1024
+	return (bytes + mebibyte - 1) / mebibyte * mebibyte
+}
+
+func formatResources(resources core.ResourceRequirements) string {
+	format := func(resourceList core.ResourceList) string {
+		values := []string{}
+		for _, name := range []core.ResourceName{core.ResourceCPU, core.ResourceMemory} {
+			if quantity, ok := resourceList[name]; ok {
+				values = append(values, string(name)+"="+quantity.String())
+			}
+		}
+		if len(values) == 0 {
+			return "none"
+		}
+		return strings.Join(values, " ")
+	}
+	return "requests: " + format(resources.Requests) + ", limits: " + format(resources.Limits)
+}
+
+// getSourceFiles returns the compose files and the Dockerfiles of a service relative to the source directory, even if they are outside it
+func getSourceFiles(sourceDir, composeDir string, composeFiles []string, containerImages map[string]irtypes.ContainerImage) []string {
+	paths := []string{}
+	for _, composeFile := range composeFiles {
+		paths = append(paths, filepath.Join(composeDir, composeFile))
+	}
+	imageNames := []string{}
+	for imageName := range containerImages {
+		imageNames = append(imageNames, imageName)
+	}
+	sort.Strings(imageNames)
+	for _, imageName := range imageNames {
+		paths = append(paths, containerImages[imageName].Build.Artifacts[irtypes.DockerfileContainerBuildArtifactTypeValue]...)
+	}
+	sourceFiles := []string{}
+	for _, path := range paths {
+		// the files outside the source directory are also recorded relative to it, so that the paths of the host are not leaked
+		relPath, err := filepath.Rel(sourceDir, path)
+		if err != nil {
+			logrus.Debugf("failed to make the path '%s' relative to the source directory '%s' . Error: %q", path, sourceDir, err)
+			relPath = filepath.Base(path)
+		}
+		sourceFiles = common.AppendIfNotPresent(sourceFiles, filepath.ToSlash(relPath))
+	}
+	return sourceFiles
+}
+
+// getComposeProjectName returns the name of the compose project the compose file belongs to.
+// Like docker compose, the top level name of the compose file is used, falling back to the name of the directory containing it.
+func getComposeProjectName(composeFilePath string) string {
+	projectName := ""
+	if data, err := readComposeFile(composeFilePath); err != nil {
+		logrus.Debugf("failed to read the compose file at path '%s' . Error: %q", composeFilePath, err)
+	} else {
+		if composeFile, err := loader.ParseYAML(data); err != nil {
+			logrus.Debugf("failed to parse the compose file at path '%s' . Error: %q", composeFilePath, err)
+		} else if name, ok := composeFile[projectNameKey].(string); ok {
+			projectName = os.ExpandEnv(name)
+		}
+	}
+	if strings.TrimSpace(projectName) == "" {
+		projectName = filepath.Base(filepath.Dir(composeFilePath))
+	}
+	return common.NormalizeForMetadataName(projectName)
+}
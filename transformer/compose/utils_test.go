@@ -0,0 +1,590 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package compose
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+
+	libcomposeyaml "github.com/docker/libcompose/yaml"
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/common/report"
+	"github.com/konveyor/move2kube/environment"
+	"github.com/konveyor/move2kube/qaengine"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	environmenttypes "github.com/konveyor/move2kube/types/environment"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/konveyor/move2kube/types/transformer/artifacts"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestGetImageInfoKey(t *testing.T) {
+	if getImageInfoKey("nginx") != getImageInfoKey("index.docker.io/library/nginx:latest") {
+		t.Fatalf("expected the short and the fully qualified image names to match")
+	}
+	if getImageInfoKey("nginx:1.23") == getImageInfoKey("nginx:latest") {
+		t.Fatalf("expected different tags of the image to not match")
+	}
+}
+
+func TestConvertToIRWithImageInfo(t *testing.T) {
+	composeFilePath := filepath.Join(t.TempDir(), "docker-compose.yaml")
+	composeFile := `version: "3"
+services:
+  web:
+    image: web:latest
+`
+	if err := os.WriteFile(composeFilePath, []byte(composeFile), 0644); err != nil {
+		t.Fatal(err)
+	}
+	imageInfo := &collecttypes.ImageInfoSpec{PortsToExpose: []int32{8080, 8443}, UserID: 1001}
+	ir, err := (&v3Loader{imageInfo: imageInfo}).ConvertToIR(composeFilePath, "web", false)
+	if err != nil {
+		t.Fatalf("failed to convert the compose file to IR. Error: %q", err)
+	}
+	service, ok := ir.Services["web"]
+	if !ok || len(service.Containers) != 1 {
+		t.Fatalf("expected the web service with a single container. Actual: %+v", ir.Services)
+	}
+	container := service.Containers[0]
+	if len(container.Ports) != 2 || container.Ports[0].ContainerPort != 8080 || container.Ports[1].ContainerPort != 8443 {
+		t.Fatalf("expected the ports exposed by the image. Actual: %+v", container.Ports)
+	}
+	if len(service.ServiceToPodPortForwardings) != 2 {
+		t.Fatalf("expected the ports exposed by the image to be forwarded. Actual: %+v", service.ServiceToPodPortForwardings)
+	}
+	if container.SecurityContext == nil || container.SecurityContext.RunAsUser == nil || *container.SecurityContext.RunAsUser != 1001 {
+		t.Fatalf("expected the user of the image. Actual: %+v", container.SecurityContext)
+	}
+}
+
+func TestGetSuggestedResources(t *testing.T) {
+	containerMetrics := collecttypes.ContainerMetrics{
+		Workload: "web",
+		CPU:      collecttypes.UsageMetrics{Average: 40, Peak: 100},
+		Memory:   collecttypes.UsageMetrics{Average: 50 * 1024 * 1024, Peak: 100 * 1024 * 1024},
+	}
+	resources := getSuggestedResources(containerMetrics)
+	expected := map[string]string{
+		"requests.cpu":    "40m",
+		"requests.memory": "100Mi",
+		"limits.cpu":      "150m",
+		"limits.memory":   "150Mi",
+	}
+	actual := map[string]string{
+		"requests.cpu":    resources.Requests.CPU().String(),
+		"requests.memory": resources.Requests.Memory().String(),
+		"limits.cpu":      resources.Limits.CPU().String(),
+		"limits.memory":   resources.Limits.Memory().String(),
+	}
+	for key, value := range expected {
+		if actual[key] != value {
+			t.Fatalf("expected %s to be %s . Actual: %s", key, value, actual[key])
+		}
+	}
+	idle := getSuggestedResources(collecttypes.ContainerMetrics{})
+	if idle.Requests.CPU().String() != "10m" || idle.Requests.Memory().String() != "32Mi" {
+		t.Fatalf("expected the minimum requests for an idle container. Actual: %+v", idle.Requests)
+	}
+}
+
+func TestWindowsVolumes(t *testing.T) {
+	t.Run("fix a volume split at the drive letter", func(t *testing.T) {
+		testcases := []struct {
+			vol  libcomposeyaml.Volume
+			want libcomposeyaml.Volume
+		}{
+			{vol: libcomposeyaml.Volume{Source: "C", Destination: `\data`, AccessMode: "/data"}, want: libcomposeyaml.Volume{Source: `C:\data`, Destination: "/data"}},
+			{vol: libcomposeyaml.Volume{Source: "d", Destination: "/data", AccessMode: "/data:ro"}, want: libcomposeyaml.Volume{Source: "d:/data", Destination: "/data", AccessMode: "ro"}},
+			{vol: libcomposeyaml.Volume{Source: "a", Destination: "/data", AccessMode: "ro"}, want: libcomposeyaml.Volume{Source: "a", Destination: "/data", AccessMode: "ro"}},
+			{vol: libcomposeyaml.Volume{Source: "./data", Destination: "/data"}, want: libcomposeyaml.Volume{Source: "./data", Destination: "/data"}},
+		}
+		for _, testcase := range testcases {
+			if actual := fixWindowsVolume(testcase.vol); actual != testcase.want {
+				t.Fatalf("wrong volume. Expected: %+v Actual: %+v", testcase.want, actual)
+			}
+		}
+	})
+	t.Run("paths", func(t *testing.T) {
+		for _, p := range []string{`C:\data`, "c:/data", `\\server\share`, `.\data`, "./data"} {
+			if !isPath(p) {
+				t.Fatalf("expected %s to be a path", p)
+			}
+		}
+		if isWindowsAbsPath("./data") || isWindowsAbsPath("data") || !isWindowsAbsPath(`C:\data`) {
+			t.Fatalf("wrong detection of the absolute Windows paths")
+		}
+	})
+	t.Run("map a Windows host path to a node path", func(t *testing.T) {
+		qaengine.ResetEngines()
+		defer qaengine.ResetEngines()
+		qaengine.AddEngine(qaengine.NewDefaultEngine())
+		qaengine.SetupConfigFile("", []string{`move2kube.storage.type."web".options="HostPath"`}, nil, nil, false)
+		volumeMount, volume, _, err := applyVolumePolicy(t.TempDir(), "web", `C:\Users\app\data`, "/data", modeReadWrite, map[string]bool{})
+		if err != nil {
+			t.Fatalf("failed to apply the volume policy. Error: %q", err)
+		}
+		if volume == nil || volume.HostPath == nil {
+			t.Fatalf("expected a host path volume. Actual: %+v", volume)
+		}
+		if volume.HostPath.Path != "/mnt/c/Users/app/data" {
+			t.Fatalf("wrong node path. Actual: %s", volume.HostPath.Path)
+		}
+		if volumeMount.MountPath != "/data" || volumeMount.Name != volume.Name {
+			t.Fatalf("wrong volume mount. Actual: %+v", volumeMount)
+		}
+	})
+}
+
+func TestWindowsComposeFiles(t *testing.T) {
+	t.Run("byte order mark and CRLF line endings in a v2 compose file and its env file", func(t *testing.T) {
+		dir := t.TempDir()
+		composeFile := "\ufeffversion: \"2\"\r\nservices:\r\n  web:\r\n    image: nginx\r\n    env_file: app.env\r\n    environment:\r\n      - B=2\r\n"
+		if err := os.WriteFile(filepath.Join(dir, "docker-compose.yaml"), []byte(composeFile), 0644); err != nil {
+			t.Fatalf("failed to write the compose file. Error: %q", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "app.env"), []byte("\ufeffA=1\r\n"), 0644); err != nil {
+			t.Fatalf("failed to write the env file. Error: %q", err)
+		}
+		proj, err := parseV2(filepath.Join(dir, "docker-compose.yaml"), true)
+		if err != nil {
+			t.Fatalf("failed to parse the compose file. Error: %q", err)
+		}
+		service, ok := proj.ServiceConfigs.Get("web")
+		if !ok {
+			t.Fatalf("expected the service web to be parsed")
+		}
+		want := []string{"B=2", "A=1"}
+		if !cmp.Equal(want, []string(service.Environment)) {
+			t.Fatalf("wrong environment. Difference:\n%s", cmp.Diff(want, []string(service.Environment)))
+		}
+	})
+	t.Run("tabs used for indentation in a v3 compose file", func(t *testing.T) {
+		composeFilePath := filepath.Join(t.TempDir(), "docker-compose.yaml")
+		composeFile := "version: \"3\"\r\nservices:\r\n\tweb:\r\n\t\timage: nginx\r\n\t\tcommand: echo\r\n"
+		if err := os.WriteFile(composeFilePath, []byte(composeFile), 0644); err != nil {
+			t.Fatalf("failed to write the compose file. Error: %q", err)
+		}
+		config, err := parseV3(composeFilePath)
+		if err != nil {
+			t.Fatalf("failed to parse the compose file. Error: %q", err)
+		}
+		if len(config.Services) != 1 || config.Services[0].Name != "web" || config.Services[0].Image != "nginx" {
+			t.Fatalf("wrong services. Actual: %+v", config.Services)
+		}
+	})
+}
+
+func TestGetUnsetEnv(t *testing.T) {
+	qaengine.ResetEngines()
+	defer qaengine.ResetEngines()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", []string{`move2kube.services."web".env."LOG_LEVEL"="debug"`}, nil, nil, false)
+	ir := irtypes.IR{}
+	if env := getUnsetEnv("web", "LOG_LEVEL", &ir); env.Value != "debug" || env.ValueFrom != nil {
+		t.Fatalf("expected the value from the config. Actual: %+v", env)
+	}
+	env := getUnsetEnv("web", "APP_MODE", &ir)
+	if env.ValueFrom == nil || env.ValueFrom.ConfigMapKeyRef == nil || env.ValueFrom.ConfigMapKeyRef.Name != "web-unset-envs" || env.ValueFrom.ConfigMapKeyRef.Key != "APP_MODE" {
+		t.Fatalf("expected a reference to the config map. Actual: %+v", env)
+	}
+	env = getUnsetEnv("web", "DB_PASSWORD", &ir)
+	if env.ValueFrom == nil || env.ValueFrom.SecretKeyRef == nil || env.ValueFrom.SecretKeyRef.Name != "web-unset-secret-envs" || env.ValueFrom.SecretKeyRef.Key != "DB_PASSWORD" {
+		t.Fatalf("expected a reference to the secret. Actual: %+v", env)
+	}
+	getUnsetEnv("web", "API_TOKEN", &ir)
+	want := []irtypes.Storage{
+		{Name: "web-unset-envs", StorageType: irtypes.ConfigMapKind, Content: map[string][]byte{"APP_MODE": []byte("")}},
+		{Name: "web-unset-secret-envs", StorageType: irtypes.SecretKind, Content: map[string][]byte{"DB_PASSWORD": []byte(""), "API_TOKEN": []byte("")}},
+	}
+	if !cmp.Equal(want, ir.Storages) {
+		t.Fatalf("wrong storages. Difference:\n%s", cmp.Diff(want, ir.Storages))
+	}
+}
+
+func TestAddDependencyWaitInitContainers(t *testing.T) {
+	qaengine.ResetEngines()
+	defer qaengine.ResetEngines()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", []string{`move2kube.services."web".dependencywaitstrategy="http"`}, nil, nil, false)
+	ir := irtypes.IR{Services: map[string]irtypes.Service{"web": irtypes.NewServiceWithName("web")}}
+	addDependencyWaitInitContainers(ir, "web", []composeDependency{{name: "api", port: 8080}})
+	want := []core.Container{{
+		Name:    "wait-for-api",
+		Image:   curlDependencyWaitImage,
+		Command: []string{"sh", "-c", dependencyWaitCommands[httpDependencyWaitStrategy]},
+		Env:     []core.EnvVar{{Name: dependencyWaitHostEnv, Value: "api"}, {Name: dependencyWaitPortEnv, Value: "8080"}},
+	}}
+	if !cmp.Equal(want, ir.Services["web"].InitContainers) {
+		t.Fatalf("wrong init containers. Difference:\n%s", cmp.Diff(want, ir.Services["web"].InitContainers))
+	}
+	ir.Services["worker"] = irtypes.NewServiceWithName("worker")
+	addDependencyWaitInitContainers(ir, "worker", []composeDependency{{name: "api", port: 8080}})
+	if len(ir.Services["worker"].InitContainers) != 0 {
+		t.Fatalf("expected the strategy of the other service to not be used. Actual: %+v", ir.Services["worker"].InitContainers)
+	}
+}
+
+func TestGetComposeProjectName(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "My_Shop")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("failed to create the directory. Error: %q", err)
+	}
+	composeFilePath := filepath.Join(dir, "docker-compose.yaml")
+	if err := os.WriteFile(composeFilePath, []byte("services:\n  web:\n    image: nginx\n"), 0644); err != nil {
+		t.Fatalf("failed to write the compose file. Error: %q", err)
+	}
+	if name := getComposeProjectName(composeFilePath); name != "my-shop" {
+		t.Fatalf("expected the project to be named after the directory. Actual: %s", name)
+	}
+	if err := os.WriteFile(composeFilePath, []byte("version: \"3.8\"\nname: storefront\nservices:\n  web:\n    image: nginx\n"), 0644); err != nil {
+		t.Fatalf("failed to write the compose file. Error: %q", err)
+	}
+	if name := getComposeProjectName(composeFilePath); name != "storefront" {
+		t.Fatalf("expected the project to be named using the top level name. Actual: %s", name)
+	}
+	if _, err := parseV3(composeFilePath); err != nil {
+		t.Fatalf("failed to parse a compose file with a top level name. Error: %q", err)
+	}
+}
+
+func TestGetPortMappings(t *testing.T) {
+	qaengine.ResetEngines()
+	defer qaengine.ResetEngines()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", []string{`move2kube.services."web".portrange."5000-5200"=true`}, nil, nil, false)
+	mappings := getPortMappings("web", []string{"3000-3002:4000-4002", "6060:6060/udp", "127.0.0.1:8001:8001", "9000", "10000-10200"}, true)
+	want := []composePortMapping{
+		{published: 3000, target: 4000, protocol: core.ProtocolTCP},
+		{published: 3001, target: 4001, protocol: core.ProtocolTCP},
+		{published: 3002, target: 4002, protocol: core.ProtocolTCP},
+		{published: 6060, target: 6060, protocol: core.ProtocolUDP},
+		{published: 8001, target: 8001, protocol: core.ProtocolTCP},
+		{published: 9000, target: 9000, protocol: core.ProtocolTCP},
+		{published: 10000, target: 10000, protocol: core.ProtocolTCP},
+	}
+	if !cmp.Equal(want, mappings, cmp.AllowUnexported(composePortMapping{})) {
+		t.Fatalf("wrong port mappings. Difference:\n%s", cmp.Diff(want, mappings, cmp.AllowUnexported(composePortMapping{})))
+	}
+	if mappings := getPortMappings("web", []string{"5000-5200"}, false); len(mappings) != 201 || mappings[0].published != 0 {
+		t.Fatalf("expected all the ports of the confirmed range without publishing them. Actual: %d ports", len(mappings))
+	}
+	service := irtypes.NewServiceWithName("web")
+	addPortForwardings([]composePortMapping{{published: 53, target: 53, protocol: core.ProtocolTCP}, {published: 53, target: 53, protocol: core.ProtocolUDP}}, &service)
+	if len(service.ServiceToPodPortForwardings) != 2 || service.ServiceToPodPortForwardings[1].Protocol != core.ProtocolUDP {
+		t.Fatalf("expected a port forwarding for each protocol. Actual: %+v", service.ServiceToPodPortForwardings)
+	}
+	addPortForwardings([]composePortMapping{{target: 8080, protocol: core.ProtocolTCP}}, &service)
+	if forwarding := service.ServiceToPodPortForwardings[2]; forwarding.ServicePort.Number != 8080 || !forwarding.Ephemeral {
+		t.Fatalf("expected the unpublished port to be forwarded from the same port and marked as ephemeral. Actual: %+v", forwarding)
+	}
+}
+
+func TestGetLabelsAndAnnotations(t *testing.T) {
+	labels := map[string]string{
+		"app":                     "web",
+		"tier":                    "frontend",
+		"com.example.description": "Accounting web app",
+	}
+	deployLabels := map[string]string{"tier": "backend"}
+	annotations, err := getStringMap([]interface{}{"prometheus.io/scrape=true", "app=shop"})
+	if err != nil {
+		t.Fatalf("failed to parse the annotations. Error: %q", err)
+	}
+	k8sLabels, k8sAnnotations := getLabelsAndAnnotations("web", labels, deployLabels, annotations)
+	wantLabels := map[string]string{"app": "web", "tier": "backend"}
+	if diff := cmp.Diff(wantLabels, k8sLabels); diff != "" {
+		t.Fatalf("wrong labels. Differences:\n%s", diff)
+	}
+	wantAnnotations := map[string]string{
+		"prometheus.io/scrape":    "true",
+		"app":                     "shop",
+		"com.example.description": "Accounting web app",
+	}
+	if diff := cmp.Diff(wantAnnotations, k8sAnnotations); diff != "" {
+		t.Fatalf("wrong annotations. Differences:\n%s", diff)
+	}
+	if labels["tier"] != "frontend" {
+		t.Fatalf("expected the compose labels to be left unchanged")
+	}
+}
+
+func TestSetDNSConfig(t *testing.T) {
+	t.Run("nameservers added to the DNS of the cluster", func(t *testing.T) {
+		qaengine.ResetEngines()
+		defer qaengine.ResetEngines()
+		qaengine.AddEngine(qaengine.NewDefaultEngine())
+		qaengine.SetupConfigFile("", nil, nil, nil, false)
+		service := irtypes.NewServiceWithName("web")
+		setDNSConfig("web", &service, []string{"8.8.8.8", "9.9.9.9"}, []string{"example.com"}, []string{"ndots:2", "rotate"})
+		ndots := "2"
+		want := &core.PodDNSConfig{
+			Nameservers: []string{"8.8.8.8", "9.9.9.9"},
+			Searches:    []string{"example.com"},
+			Options:     []core.PodDNSConfigOption{{Name: "ndots", Value: &ndots}, {Name: "rotate"}},
+		}
+		if diff := cmp.Diff(want, service.DNSConfig); diff != "" {
+			t.Fatalf("wrong DNS config. Differences:\n%s", diff)
+		}
+		if service.DNSPolicy != "" {
+			t.Fatalf("expected the DNS policy of the cluster to be kept. Actual: %s", service.DNSPolicy)
+		}
+	})
+	t.Run("only the nameservers", func(t *testing.T) {
+		qaengine.ResetEngines()
+		defer qaengine.ResetEngines()
+		qaengine.AddEngine(qaengine.NewDefaultEngine())
+		qaengine.SetupConfigFile("", []string{`move2kube.services."web".onlynameservers=true`}, nil, nil, false)
+		service := irtypes.NewServiceWithName("web")
+		setDNSConfig("web", &service, []string{"8.8.8.8"}, nil, nil)
+		if service.DNSPolicy != core.DNSNone {
+			t.Fatalf("expected the DNS policy to be %s . Actual: %s", core.DNSNone, service.DNSPolicy)
+		}
+	})
+	t.Run("only the search domains", func(t *testing.T) {
+		service := irtypes.NewServiceWithName("web")
+		setDNSConfig("web", &service, nil, []string{"example.com"}, nil)
+		if service.DNSConfig == nil || len(service.DNSConfig.Searches) != 1 || service.DNSPolicy != "" {
+			t.Fatalf("expected the search domains to be added to the DNS of the cluster. Actual: %+v %s", service.DNSConfig, service.DNSPolicy)
+		}
+	})
+	t.Run("no DNS settings", func(t *testing.T) {
+		service := irtypes.NewServiceWithName("web")
+		setDNSConfig("web", &service, nil, nil, nil)
+		if service.DNSConfig != nil || service.DNSPolicy != "" {
+			t.Fatalf("expected no DNS config. Actual: %+v %s", service.DNSConfig, service.DNSPolicy)
+		}
+	})
+}
+
+func TestDNSOptsV3(t *testing.T) {
+	qaengine.ResetEngines()
+	defer qaengine.ResetEngines()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", nil, nil, nil, false)
+	dir := t.TempDir()
+	writeComposeFiles(t, dir, map[string]string{
+		"docker-compose.yml": `version: "3.8"
+services:
+  web:
+    image: web:latest
+    dns_search: example.com
+    dns_opt:
+      - ndots:2
+      - use-vc
+`,
+	})
+	ir, err := (&v3Loader{}).ConvertToIR(filepath.Join(dir, "docker-compose.yml"), "web", false)
+	if err != nil {
+		t.Fatalf("failed to convert the compose file. Error: %q", err)
+	}
+	ndots := "2"
+	want := &core.PodDNSConfig{
+		Searches: []string{"example.com"},
+		Options:  []core.PodDNSConfigOption{{Name: "ndots", Value: &ndots}, {Name: "use-vc"}},
+	}
+	if diff := cmp.Diff(want, ir.Services["web"].DNSConfig); diff != "" {
+		t.Fatalf("wrong DNS config. Differences:\n%s", diff)
+	}
+}
+
+func TestGetUnsetVariables(t *testing.T) {
+	qaengine.ResetEngines()
+	defer qaengine.ResetEngines()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", []string{`move2kube.compose.variables."TAG"="1.2"`}, nil, nil, false)
+	dir := t.TempDir()
+	composeFilePath := filepath.Join(dir, "docker-compose.yaml")
+	composeFile := `version: "3.8"
+# image: ${COMMENTED}
+services:
+  web:
+    image: "nginx:${TAG}"
+    environment:
+      MODE: ${MODE:-dev}
+      LEVEL: ${LEVEL-info}
+      HOST: $HOST_NAME
+      REGION: ${REGION:?the region is required}
+      PRICE: $$5
+      USER: ${APP_USER}
+`
+	if err := os.WriteFile(composeFilePath, []byte(composeFile), 0644); err != nil {
+		t.Fatalf("failed to write the compose file. Error: %q", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, defaultEnvFile), []byte("APP_USER=admin\nREGION=eu\n"), 0644); err != nil {
+		t.Fatalf("failed to write the .env file. Error: %q", err)
+	}
+	want := map[string]string{"TAG": "1.2", "HOST_NAME": ""}
+	if diff := cmp.Diff(want, getUnsetVariables(composeFilePath, []byte(composeFile), getComposeFileEnvironment(composeFilePath))); diff != "" {
+		t.Fatalf("wrong unset variables. Differences:\n%s", diff)
+	}
+	config, err := parseV3(composeFilePath)
+	if err != nil {
+		t.Fatalf("failed to parse the compose file. Error: %q", err)
+	}
+	service := config.Services[0]
+	if service.Image != "nginx:1.2" {
+		t.Fatalf("expected the answer to be used for the variable. Actual: %s", service.Image)
+	}
+	for name, value := range map[string]string{"MODE": "dev", "LEVEL": "info", "USER": "admin", "REGION": "eu", "PRICE": "$5"} {
+		if actual := service.Environment[name]; actual == nil || *actual != value {
+			t.Fatalf("wrong value of the env var %s . Expected: %s Actual: %v", name, value, actual)
+		}
+	}
+}
+
+func TestComposeProjectPrefix(t *testing.T) {
+	qaengine.ResetEngines()
+	defer qaengine.ResetEngines()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", []string{`move2kube.naming.projects."blog".prefix=false`, `move2kube.storage.type."web".options="PVC"`}, nil, nil, false)
+	oldTempPath := common.TempPath
+	common.TempPath = t.TempDir()
+	defer func() { common.TempPath = oldTempPath }()
+	sourceDir := t.TempDir()
+	writeComposeFiles(t, sourceDir, map[string]string{
+		"shop/docker-compose.yml": `version: "3.8"
+services:
+  web:
+    image: shop-web
+    environment:
+      DB_HOST: db
+    volumes:
+      - data:/data
+  db:
+    image: postgres
+volumes:
+  data:
+`,
+		"blog/docker-compose.yml": `version: "3.8"
+services:
+  web:
+    image: blog-web
+`,
+	})
+	env, err := environment.NewEnvironment(environment.EnvInfo{
+		Name:    "ComposeAnalyser",
+		Source:  sourceDir,
+		Output:  t.TempDir(),
+		Context: t.TempDir(),
+		EnvPlatformConfig: environmenttypes.EnvPlatformConfig{
+			Platforms: []string{runtime.GOOS},
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("failed to create the environment. Error: %q", err)
+	}
+	defer env.Destroy()
+	tc := transformertypes.NewTransformer()
+	tc.Name = "ComposeAnalyser"
+	analyser := &ComposeAnalyser{}
+	if err := analyser.Init(tc, env); err != nil {
+		t.Fatalf("failed to initialize the transformer. Error: %q", err)
+	}
+	services, err := analyser.DirectoryDetect(sourceDir)
+	if err != nil {
+		t.Fatalf("failed to detect the services. Error: %q", err)
+	}
+	serviceNames := []string{}
+	for serviceName := range services {
+		serviceNames = append(serviceNames, serviceName)
+	}
+	sort.Strings(serviceNames)
+	if diff := cmp.Diff([]string{"shop-db", "shop-web", "web"}, serviceNames); diff != "" {
+		t.Fatalf("expected the services of the shop project to be prefixed and not merged with the other project. Differences:\n%s", diff)
+	}
+	newArtifacts := []transformertypes.Artifact{}
+	for _, serviceName := range []string{"shop-db", "shop-web"} {
+		artifact := services[serviceName][0]
+		artifact.Configs[artifacts.ServiceConfigType] = artifacts.ServiceConfig{ServiceName: serviceName}
+		newArtifacts = append(newArtifacts, artifact)
+	}
+	_, createdArtifacts, err := analyser.Transform(newArtifacts, nil)
+	if err != nil {
+		t.Fatalf("failed to transform the services. Error: %q", err)
+	}
+	ir := irtypes.NewIR()
+	for _, createdArtifact := range createdArtifacts {
+		if createdArtifact.Type != irtypes.IRArtifactType {
+			continue
+		}
+		currIR := irtypes.NewIR()
+		if err := createdArtifact.GetConfig(irtypes.IRConfigType, &currIR); err != nil {
+			t.Fatalf("failed to get the IR. Error: %q", err)
+		}
+		ir.Merge(currIR)
+	}
+	web, ok := ir.Services["shop-web"]
+	if !ok {
+		t.Fatalf("expected the prefixed web service. Actual: %+v", ir.Services)
+	}
+	if diff := cmp.Diff([]core.EnvVar{{Name: "DB_HOST", Value: "shop-db"}}, web.Containers[0].Env); diff != "" {
+		t.Fatalf("expected the reference to the database to use the prefixed name. Differences:\n%s", diff)
+	}
+	if len(ir.Storages) != 1 || !strings.HasPrefix(ir.Storages[0].Name, "shop-") {
+		t.Fatalf("expected the volume to be prefixed with the project name. Actual: %+v", ir.Storages)
+	}
+	if claimName := web.Volumes[0].PersistentVolumeClaim.ClaimName; claimName != ir.Storages[0].Name {
+		t.Fatalf("expected the claim of the volume to use the prefixed name. Actual: %s", claimName)
+	}
+}
+
+func TestGetAllDirContentAsMapReportsSkippedFiles(t *testing.T) {
+	oldMaxEmbeddedFileSizeBytes := common.MaxEmbeddedFileSizeBytes
+	common.MaxEmbeddedFileSizeBytes = 10
+	defer func() { common.MaxEmbeddedFileSizeBytes = oldMaxEmbeddedFileSizeBytes }()
+	report.Reset()
+	defer report.Reset()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.conf"), []byte("small"), 0644); err != nil {
+		t.Fatalf("failed to write the file. Error: %q", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "b.conf"), []byte("larger than the limit"), 0644); err != nil {
+		t.Fatalf("failed to write the file. Error: %q", err)
+	}
+	dataMap, err := getAllDirContentAsMap(dir, irtypes.ConfigMapKind, "config")
+	if err != nil {
+		t.Fatalf("failed to read the directory. Error: %q", err)
+	}
+	if diff := cmp.Diff(map[string][]byte{"a.conf": []byte("small")}, dataMap); diff != "" {
+		t.Fatalf("the data map is different. Differences:\n%s", diff)
+	}
+	followUps := report.Get("", "").Spec.FollowUps
+	if len(followUps) != 1 || !strings.Contains(followUps[0].Description, filepath.Join(dir, "b.conf")) {
+		t.Fatalf("expected a follow up for the skipped file. Actual: %+v", followUps)
+	}
+}
+
+func TestGetSourceFiles(t *testing.T) {
+	sourceDir := filepath.Join(string(filepath.Separator), "src", "app")
+	containerImages := map[string]irtypes.ContainerImage{
+		"web": {Build: irtypes.ContainerBuild{Artifacts: map[irtypes.ContainerBuildArtifactTypeValue][]string{
+			irtypes.DockerfileContainerBuildArtifactTypeValue: {filepath.Join(sourceDir, "web", "Dockerfile")},
+		}}},
+	}
+	sourceFiles := getSourceFiles(sourceDir, filepath.Join(sourceDir, "compose"), []string{"docker-compose.yaml", filepath.Join("..", "..", "shared", "base.yaml")}, containerImages)
+	want := []string{"compose/docker-compose.yaml", "../shared/base.yaml", "web/Dockerfile"}
+	if diff := cmp.Diff(want, sourceFiles); diff != "" {
+		t.Fatalf("wrong source files. Differences:\n%s", diff)
+	}
+}
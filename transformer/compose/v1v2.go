@@ -17,29 +17,41 @@
 package compose
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
-	"strings"
 	"time"
 
+	"github.com/docker/cli/cli/compose/loader"
 	"github.com/docker/libcompose/config"
 	"github.com/docker/libcompose/lookup"
 	"github.com/docker/libcompose/project"
+	libcomposeyaml "github.com/docker/libcompose/yaml"
 	"github.com/google/go-cmp/cmp"
 	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/common/deepcopy"
+	"github.com/konveyor/move2kube/common/report"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
 	irtypes "github.com/konveyor/move2kube/types/ir"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cast"
 	"k8s.io/apimachinery/pkg/api/resource"
 	core "k8s.io/kubernetes/pkg/apis/core"
-	"k8s.io/kubernetes/pkg/apis/networking"
 )
 
 // v1v2Loader loads a compoose file of versions 1 or 2
 type v1v2Loader struct {
+	// imageInfo is the metadata collected from the image of the service, if available
+	imageInfo *collecttypes.ImageInfoSpec
+	// overrideFilePaths are the override files merged into the compose file
+	overrideFilePaths []string
+	// sysctls are the sysctls of the service being converted, since the parser does not support them
+	sysctls map[string]string
+	// serviceNames are the names of the IR services for the compose services, as named during planning
+	serviceNames map[string]string
 }
 
 type preprocessFunc func(rawServiceMap config.RawServiceMap) (config.RawServiceMap, error)
@@ -87,11 +99,92 @@ func removeNonExistentEnvFilesV2(path string) preprocessFunc {
 	}
 }
 
-// parseV2 parses version 2 compose files
-func parseV2(path string, interpolate bool) (*project.Project, error) {
+// removeSysctlsV2 removes the sysctls of the services, since the parser does not support them. They are read using getSysctlsV2 instead.
+func removeSysctlsV2(rawServiceMap config.RawServiceMap) config.RawServiceMap {
+	for _, vals := range rawServiceMap {
+		delete(vals, sysctlsKey)
+	}
+	return rawServiceMap
+}
+
+// getSysctlsV2 returns the sysctls of the service in the version 2 compose file, merged with the ones in its override files
+func getSysctlsV2(paths []string, serviceName string) map[string]string {
+	sysctls := map[string]string{}
+	for _, path := range paths {
+		fileData, err := readComposeFile(path)
+		if err != nil {
+			logrus.Debugf("failed to read the sysctls in the compose file at path %s . Error: %q", path, err)
+			continue
+		}
+		if fileData, err = resolveExtendsV2(path, fileData); err != nil {
+			logrus.Debugf("failed to read the sysctls in the compose file at path %s . Error: %q", path, err)
+			continue
+		}
+		parsedComposeFile, err := loader.ParseYAML(fileData)
+		if err != nil {
+			logrus.Debugf("failed to read the sysctls in the compose file at path %s . Error: %q", path, err)
+			continue
+		}
+		service, ok := getComposeFileServices(parsedComposeFile)[serviceName].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		mapping, ok := getExtendsMapping(service[sysctlsKey])
+		if !ok {
+			continue
+		}
+		for name, value := range mapping {
+			sysctls[name] = cast.ToString(value)
+		}
+	}
+	return sysctls
+}
+
+// parseV2 parses version 2 compose files. The override files are merged into the compose file in order, like docker compose does.
+func parseV2(path string, interpolate bool, overridePaths ...string) (*project.Project, error) {
+	paths := append([]string{path}, overridePaths...)
+	fileDatas := [][]byte{}
+	for _, p := range paths {
+		fileData, err := readComposeFile(p)
+		if err != nil {
+			err := fmt.Errorf("failed to load docker compose file at path %s Error: %q", p, err)
+			logrus.Debug(err)
+			return nil, err
+		}
+		if fileData, err = resolveExtendsV2(p, fileData); err != nil {
+			err := fmt.Errorf("failed to load docker compose file at path %s Error: %q", p, err)
+			logrus.Debug(err)
+			return nil, err
+		}
+		fileDatas = append(fileDatas, fileData)
+	}
+	proj, err := parseV2Bytes(paths, fileDatas, interpolate)
+	if err != nil {
+		expandedFileDatas := [][]byte{}
+		anyExpanded := false
+		for _, fileData := range fileDatas {
+			expandedFileData, expanded := expandIndentationTabs(fileData)
+			anyExpanded = anyExpanded || expanded
+			expandedFileDatas = append(expandedFileDatas, expandedFileData)
+		}
+		if !anyExpanded {
+			return nil, err
+		}
+		if proj, _ = parseV2Bytes(paths, expandedFileDatas, interpolate); proj == nil {
+			return nil, err
+		}
+		logrus.Warnf("The docker compose file at path %s uses tabs for indentation. Replaced each of them with %d spaces", path, indentationTabWidth)
+	}
+	return proj, nil
+}
+
+// parseV2Bytes parses the contents of the version 1 or 2 compose file at the first path, along with its override files
+func parseV2Bytes(paths []string, fileDatas [][]byte, interpolate bool) (*project.Project, error) {
+	path := paths[0]
 	context := project.Context{}
-	context.ComposeFiles = []string{path}
-	context.ResourceLookup = new(lookup.FileResourceLookup)
+	context.ComposeFiles = paths
+	context.ComposeBytes = fileDatas
+	context.ResourceLookup = &normalizingResourceLookup{ResourceLookup: new(lookup.FileResourceLookup)}
 	//TODO: Check if any variable is mandatory
 	var lookUps []config.EnvironmentLookup
 	composeFileDir := filepath.Dir(path)
@@ -105,11 +198,23 @@ func parseV2(path string, interpolate bool) (*project.Project, error) {
 	if !common.IgnoreEnvironment {
 		lookUps = append(lookUps, &lookup.OsEnvLookup{})
 	}
+	if parsedComposeFile, err := loader.ParseYAML(fileDatas[0]); interpolate && err == nil && parsedComposeFile["services"] != nil {
+		if variables := getUnsetVariables(path, bytes.Join(fileDatas, []byte("\n")), getComposeFileEnvironment(path)); len(variables) != 0 {
+			// the other lookups take precedence over the earlier ones
+			lookUps = append([]config.EnvironmentLookup{&variablesEnvLookup{variables: variables}}, lookUps...)
+		}
+	}
 	context.EnvironmentLookup = &lookup.ComposableEnvLookup{Lookups: lookUps}
 	parseOptions := config.ParseOptions{
 		Interpolate: interpolate,
 		Validate:    true,
-		Preprocess:  removeNonExistentEnvFilesV2(path),
+		Preprocess: func(rawServiceMap config.RawServiceMap) (config.RawServiceMap, error) {
+			rawServiceMap, err := removeNonExistentEnvFilesV2(path)(rawServiceMap)
+			if err != nil {
+				return rawServiceMap, err
+			}
+			return removeSysctlsV2(rawServiceMap), nil
+		},
 	}
 	proj := project.NewProject(&context, nil, &parseOptions)
 	originalLevel := logrus.GetLevel()
@@ -126,11 +231,36 @@ func parseV2(path string, interpolate bool) (*project.Project, error) {
 
 // ConvertToIR loads a compose file to IR
 func (c *v1v2Loader) ConvertToIR(composefilepath string, serviceName string, parseNetwork bool) (ir irtypes.IR, err error) {
-	proj, err := parseV2(composefilepath, true)
+	proj, err := getParsedV2(composefilepath, true, c.overrideFilePaths...)
 	if err != nil {
 		return irtypes.IR{}, err
 	}
-	return c.convertToIR(filepath.Dir(composefilepath), proj, serviceName, parseNetwork)
+	c.sysctls = getSysctlsV2(append([]string{composefilepath}, c.overrideFilePaths...), serviceName)
+	ir, err = c.convertToIR(filepath.Dir(composefilepath), proj, serviceName, parseNetwork)
+	if err != nil {
+		return ir, err
+	}
+	if composeServiceConfig, ok := proj.ServiceConfigs.Get(serviceName); ok {
+		addDependencyWaitInitContainers(ir, getIRServiceName(c.serviceNames, serviceName), c.getDependencies(proj, composeServiceConfig.DependsOn))
+	}
+	return ir, nil
+}
+
+// getDependencies returns the services in depends_on along with the ports they can be reached on
+func (c *v1v2Loader) getDependencies(composeObject *project.Project, dependsOn []string) []composeDependency {
+	dependencies := []composeDependency{}
+	for _, dependencyName := range dependsOn {
+		composeServiceConfig, ok := composeObject.ServiceConfigs.Get(dependencyName)
+		if !ok {
+			continue
+		}
+		dependency := irtypes.NewServiceWithName(getIRServiceName(c.serviceNames, dependencyName))
+		addPortForwardings(c.getPortMappings(dependencyName, composeServiceConfig.Ports, composeServiceConfig.Expose), &dependency)
+		if port, ok := getDependencyPort(dependencyName, dependency); ok {
+			dependencies = append(dependencies, composeDependency{name: dependency.Name, port: port})
+		}
+	}
+	return dependencies
 }
 
 func (c *v1v2Loader) convertToIR(filedir string, composeObject *project.Project, serviceName string, parseNetwork bool) (ir irtypes.IR, err error) {
@@ -142,14 +272,20 @@ func (c *v1v2Loader) convertToIR(filedir string, composeObject *project.Project,
 		if name != serviceName {
 			continue
 		}
-		serviceConfig := irtypes.NewServiceWithName(common.NormalizeForMetadataName(name))
-		serviceConfig.Annotations = map[string]string(composeServiceConfig.Labels)
+		// the project is cached and shared by all the services in the compose file, so work on a copy
+		composeServiceConfig := deepcopy.DeepCopy(composeServiceConfig).(*config.ServiceConfig)
+		serviceConfig := irtypes.NewServiceWithName(getIRServiceName(c.serviceNames, name))
+		serviceConfig.Labels, serviceConfig.Annotations = getLabelsAndAnnotations(name, composeServiceConfig.Labels, nil, nil)
 		if composeServiceConfig.Hostname != "" {
 			serviceConfig.Hostname = composeServiceConfig.Hostname
 		}
+		if composeServiceConfig.Logging.Driver != "" {
+			serviceConfig.LoggingDriver = composeServiceConfig.Logging.Driver
+		}
 		if composeServiceConfig.DomainName != "" {
 			serviceConfig.Subdomain = composeServiceConfig.DomainName
 		}
+		setDNSConfig(name, &serviceConfig, composeServiceConfig.DNS, composeServiceConfig.DNSSearch, composeServiceConfig.DNSOpts)
 		serviceContainer := core.Container{}
 		serviceContainer.Image = composeServiceConfig.Image
 		if serviceContainer.Image == "" {
@@ -169,6 +305,7 @@ func (c *v1v2Loader) convertToIR(filedir string, composeObject *project.Project,
 					Artifacts: map[irtypes.ContainerBuildArtifactTypeValue][]string{
 						irtypes.DockerfileContainerBuildArtifactTypeValue: {filepath.Join(filedir, composeServiceConfig.Build.Dockerfile)},
 					},
+					BuildArgs: composeServiceConfig.Build.Args,
 				},
 			}
 		}
@@ -181,12 +318,16 @@ func (c *v1v2Loader) convertToIR(filedir string, composeObject *project.Project,
 		}
 		serviceContainer.Command = composeServiceConfig.Entrypoint
 		serviceContainer.Args = composeServiceConfig.Command
-		serviceContainer.Env = c.getEnvs(composeServiceConfig.Environment)
+		serviceContainer.Env = c.getEnvs(serviceConfig.Name, composeServiceConfig.Environment, &ir)
 		serviceContainer.WorkingDir = composeServiceConfig.WorkingDir
 		serviceContainer.Stdin = composeServiceConfig.StdinOpen
 		serviceContainer.TTY = composeServiceConfig.Tty
-		serviceContainer.Ports = c.getPorts(composeServiceConfig.Ports, composeServiceConfig.Expose)
-		c.addPorts(composeServiceConfig.Ports, composeServiceConfig.Expose, &serviceConfig)
+		if len(composeServiceConfig.Ports) == 0 && len(composeServiceConfig.Expose) == 0 {
+			composeServiceConfig.Expose = getImageExposedPorts(c.imageInfo)
+		}
+		portMappings := c.getPortMappings(name, composeServiceConfig.Ports, composeServiceConfig.Expose)
+		serviceContainer.Ports = getContainerPorts(portMappings)
+		addPortForwardings(portMappings, &serviceConfig)
 		podSecurityContext := &core.PodSecurityContext{}
 		securityContext := &core.SecurityContext{}
 		if composeServiceConfig.Privileged {
@@ -196,10 +337,12 @@ func (c *v1v2Loader) convertToIR(filedir string, composeObject *project.Project,
 			uid, err := cast.ToInt64E(composeServiceConfig.User)
 			if err != nil {
 				logrus.Warn("Ignoring user directive. User to be specified as a UID (numeric).")
+				report.AddDroppedField(name, "user", fmt.Sprintf("the user '%s' is not a numeric UID", composeServiceConfig.User))
 			} else {
 				securityContext.RunAsUser = &uid
 			}
-
+		} else {
+			securityContext.RunAsUser = getImageUserID(c.imageInfo)
 		}
 		capsAdd := []core.Capability{}
 		capsDrop := []core.Capability{}
@@ -242,10 +385,13 @@ func (c *v1v2Loader) convertToIR(filedir string, composeObject *project.Project,
 			}
 			serviceContainer.Resources.Limits = resourceLimit
 		}
+		// the v1 and v2 compose files do not have a cpu period, so the default period is used
+		addLegacyCPUResources(name, &serviceContainer, int64(composeServiceConfig.CPUShares), int64(composeServiceConfig.CPUQuota), 0)
 
 		restart := composeServiceConfig.Restart
 		if restart == "unless-stopped" {
 			logrus.Warnf("Restart policy 'unless-stopped' in service %s is not supported, convert it to 'always'", name)
+			report.AddDroppedField(name, "restart", "the restart policy 'unless-stopped' is not supported and was converted to 'always'")
 			serviceConfig.RestartPolicy = core.RestartPolicyAlways
 		}
 
@@ -265,9 +411,11 @@ func (c *v1v2Loader) convertToIR(filedir string, composeObject *project.Project,
 
 		if composeServiceConfig.VolumesFrom != nil {
 			logrus.Warnf("Ignoring VolumeFrom in compose for service %s : %s", serviceName, composeServiceConfig.VolumesFrom)
+			report.AddDroppedField(serviceName, "volumes_from", "sharing the volumes of other containers is not supported. Mount the same volumes in the service instead")
 		}
 		if composeServiceConfig.Volumes != nil {
 			for _, vol := range composeServiceConfig.Volumes.Volumes {
+				vol := fixWindowsVolume(*vol)
 				volumeMount, volume, storage, err := applyVolumePolicy(filedir, serviceName, vol.Source, vol.Destination, vol.AccessMode, storageMap)
 				if err != nil {
 					logrus.Warnf("Could not create storage: [%s]", err)
@@ -286,22 +434,21 @@ func (c *v1v2Loader) convertToIR(filedir string, composeObject *project.Project,
 				}
 			}
 		}
+		adviseHostFeatures(name, hostFeatures{privileged: composeServiceConfig.Privileged, networkMode: composeServiceConfig.NetworkMode, devices: composeServiceConfig.Devices, sysctls: c.sysctls}, &serviceConfig, &serviceContainer)
+		addUlimits(name, &serviceConfig, getUlimitsV2(composeServiceConfig.Ulimits))
 		serviceConfig.Containers = []core.Container{serviceContainer}
-		ir.Services[name] = serviceConfig
+		ir.Services[serviceConfig.Name] = serviceConfig
 	}
 	return ir, nil
 }
 
-func (c *v1v2Loader) getEnvs(envars []string) []core.EnvVar {
+func (c *v1v2Loader) getEnvs(serviceName string, envars []string, ir *irtypes.IR) []core.EnvVar {
 	envs := []core.EnvVar{}
 	for _, e := range envars {
 		m := regexp.MustCompile(`[=:]`)
 		locs := m.FindStringIndex(e)
 		if locs == nil || len(locs) < 1 {
-			envs = append(envs, core.EnvVar{
-				Name:  e,
-				Value: "unknown",
-			})
+			envs = append(envs, getUnsetEnv(serviceName, e, ir))
 		} else {
 			envs = append(envs, core.EnvVar{
 				Name:  e[:locs[0]],
@@ -312,102 +459,9 @@ func (c *v1v2Loader) getEnvs(envars []string) []core.EnvVar {
 	return envs
 }
 
-func (c *v1v2Loader) getPorts(composePorts []string, expose []string) []core.ContainerPort {
-	ports := []core.ContainerPort{}
-	exist := map[int]bool{}
-	for _, port := range composePorts {
-		_, podPort, protocol, err := c.parseContainerPort(port)
-		if err != nil {
-			continue
-		}
-		if !exist[podPort] {
-			ports = append(ports, core.ContainerPort{ContainerPort: int32(podPort), Protocol: protocol})
-			exist[podPort] = true
-		}
-	}
-	for _, port := range expose {
-		_, podPort, protocol, err := c.parseContainerPort(port)
-		if err != nil {
-			continue
-		}
-		if !exist[podPort] {
-			ports = append(ports, core.ContainerPort{ContainerPort: int32(podPort), Protocol: protocol})
-			exist[podPort] = true
-		}
-	}
-	return ports
-}
-
-func (c *v1v2Loader) addPorts(composePorts []string, expose []string, service *irtypes.Service) {
-	exist := map[int]bool{}
-	for _, port := range composePorts {
-		servicePortNumber, podPortNumber, _, err := c.parseContainerPort(port)
-		if err != nil {
-			continue
-		}
-		if !exist[servicePortNumber] {
-			// Forward the port on the k8s service to the k8s pod.
-			podPort := networking.ServiceBackendPort{Number: int32(podPortNumber)}
-			servicePort := networking.ServiceBackendPort{Number: int32(servicePortNumber)}
-			service.AddPortForwarding(servicePort, podPort, "")
-			exist[servicePortNumber] = true
-		}
-	}
-	for _, port := range expose {
-		servicePortNumber, podPortNumber, _, err := c.parseContainerPort(port)
-		if err != nil {
-			continue
-		}
-		if !exist[servicePortNumber] {
-			// Forward the port on the k8s service to the k8s pod.
-			podPort := networking.ServiceBackendPort{Number: int32(podPortNumber)}
-			servicePort := networking.ServiceBackendPort{Number: int32(servicePortNumber)}
-			service.AddPortForwarding(servicePort, podPort, "")
-			exist[servicePortNumber] = true
-		}
-	}
-}
-
-func (*v1v2Loader) parseContainerPort(value string) (servicePort int, podPort int, protocol core.Protocol, err error) {
-	protocol = core.ProtocolTCP
-	if strings.Contains(value, "/") {
-		parts := strings.Split(value, "/")
-		value = parts[0]
-		if strings.EqualFold(string(core.ProtocolUDP), parts[1]) {
-			protocol = core.ProtocolUDP
-		}
-	}
-	if !strings.Contains(value, ":") {
-		// "3000"
-		podPort, err = cast.ToIntE(value)
-		if err != nil {
-			logrus.Debugf("Failed to parse the port %s as an integer. Error: %q", value, err)
-			return podPort, podPort, protocol, err
-		}
-		return podPort, podPort, protocol, nil
-	}
-	// Split up the ports and IP
-	parts := strings.Split(value, ":")
-	// "8000:8000"
-	servicePortStr, podPortStr := parts[0], parts[1]
-	if len(parts) == 3 {
-		// "127.0.0.1:8001:8001"
-		servicePortStr, podPortStr = parts[1], parts[2]
-	} else if len(parts) > 3 {
-		err := fmt.Errorf("failed to parse the port %s properly", value)
-		return servicePort, podPort, protocol, err
-	}
-	servicePort, err = cast.ToIntE(servicePortStr)
-	if err != nil {
-		logrus.Debugf("Failed to parse the port %s as an integer. Error: %q", servicePortStr, err)
-		return servicePort, servicePort, protocol, err
-	}
-	podPort, err = cast.ToIntE(podPortStr)
-	if err != nil {
-		logrus.Debugf("Failed to parse the port %s as an integer. Error: %q", podPortStr, err)
-		return servicePort, podPort, protocol, err
-	}
-	return servicePort, podPort, protocol, nil
+// getPortMappings returns the ports and the exposed ports of the service
+func (*v1v2Loader) getPortMappings(serviceName string, composePorts []string, expose []string) []composePortMapping {
+	return append(getPortMappings(serviceName, composePorts, false), getPortMappings(serviceName, expose, true)...)
 }
 
 func getGroupAdd(group []string) ([]int64, error) {
@@ -434,3 +488,12 @@ func durationInSeconds(s string) (*int64, error) {
 	r := (int64)(duration.Seconds())
 	return &r, nil
 }
+
+// getUlimitsV2 returns the soft and hard limits of the ulimits
+func getUlimitsV2(ulimitsConfig libcomposeyaml.Ulimits) map[string]composeUlimit {
+	ulimits := map[string]composeUlimit{}
+	for _, ulimit := range ulimitsConfig.Elements {
+		ulimits[ulimit.Name] = composeUlimit{Soft: ulimit.Soft, Hard: ulimit.Hard}
+	}
+	return ulimits
+}
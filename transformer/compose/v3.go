@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -28,6 +29,8 @@ import (
 	libcomposeyaml "github.com/docker/libcompose/yaml"
 	"github.com/google/go-cmp/cmp"
 	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/common/report"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
 	irtypes "github.com/konveyor/move2kube/types/ir"
 	"github.com/konveyor/move2kube/types/qaengine/commonqa"
 	"github.com/pkg/errors"
@@ -35,11 +38,18 @@ import (
 	"github.com/spf13/cast"
 	"k8s.io/apimachinery/pkg/api/resource"
 	core "k8s.io/kubernetes/pkg/apis/core"
-	"k8s.io/kubernetes/pkg/apis/networking"
 )
 
 // v3Loader loads a v3 compose file
 type v3Loader struct {
+	// imageInfo is the metadata collected from the image of the service, if available
+	imageInfo *collecttypes.ImageInfoSpec
+	// overrideFilePaths are the override files merged into the compose file
+	overrideFilePaths []string
+	// activeProfiles are the compose profiles whose services are converted
+	activeProfiles []string
+	// serviceNames are the names of the IR services for the compose services, as named during planning
+	serviceNames map[string]string
 }
 
 func removeNonExistentEnvFilesV3(path string, parsedComposeFile map[string]interface{}) map[string]interface{} {
@@ -88,33 +98,244 @@ func removeNonExistentEnvFilesV3(path string, parsedComposeFile map[string]inter
 	return parsedComposeFile
 }
 
-// parseV3 parses version 3 compose files
-func parseV3(path string) (*types.Config, error) {
-	fileData, err := os.ReadFile(path)
+// extractInlineConfigsV3 removes the configs defined using inline content or an env var, since the parser does not support them,
+// and returns the contents of those configs
+func extractInlineConfigsV3(path string, parsedComposeFile map[string]interface{}, envMap map[string]string) map[string]string {
+	inlineConfigs := map[string]string{}
+	configs, ok := parsedComposeFile[configsKey].(map[string]interface{})
+	if !ok {
+		return inlineConfigs
+	}
+	for configName, val := range configs {
+		vals, ok := val.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if content, ok := vals[configContentKey]; ok {
+			inlineConfigs[configName] = cast.ToString(content)
+			delete(vals, configContentKey)
+		} else if envName, ok := vals[configEnvironmentKey]; ok {
+			content, ok := envMap[cast.ToString(envName)]
+			if !ok {
+				logrus.Warnf("The env var %s of the config %s in the file %s is not set. Using an empty config.", envName, configName, path)
+			}
+			inlineConfigs[configName] = content
+			delete(vals, configEnvironmentKey)
+		}
+	}
+	return inlineConfigs
+}
+
+// extractServiceExtrasV3 removes the keys of the services which the parser does not support, like the develop sections,
+// the annotations, the legacy cpu and block IO settings and the profiles, and returns them for each of the services
+func extractServiceExtrasV3(parsedComposeFile map[string]interface{}) map[string]map[string]interface{} {
+	serviceExtras := map[string]map[string]interface{}{}
+	services, ok := parsedComposeFile["services"].(map[string]interface{})
+	if !ok {
+		return serviceExtras
+	}
+	for serviceName, val := range services {
+		vals, ok := val.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for _, key := range []string{developKey, annotationsKey, cpuSharesKey, cpuQuotaKey, cpuPeriodKey, blkioConfigKey, profilesKey, dnsOptKey} {
+			extra, ok := vals[key]
+			if !ok {
+				continue
+			}
+			if serviceExtras[serviceName] == nil {
+				serviceExtras[serviceName] = map[string]interface{}{}
+			}
+			serviceExtras[serviceName][key] = extra
+			delete(vals, key)
+		}
+	}
+	return serviceExtras
+}
+
+// extractDependsOnConditionsV3 converts the long syntax of depends_on, which the parser does not support, into the short syntax
+// and returns the conditions of the dependencies of each of the services
+func extractDependsOnConditionsV3(parsedComposeFile map[string]interface{}) map[string]map[string]string {
+	dependsOnConditions := map[string]map[string]string{}
+	services, ok := parsedComposeFile["services"].(map[string]interface{})
+	if !ok {
+		return dependsOnConditions
+	}
+	for serviceName, val := range services {
+		vals, ok := val.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		dependencies, ok := vals[dependsOnKey].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		conditions := map[string]string{}
+		dependencyNames := []string{}
+		for dependencyName, dependencyVal := range dependencies {
+			dependencyNames = append(dependencyNames, dependencyName)
+			condition := serviceStartedCondition
+			if dependencyVals, ok := dependencyVal.(map[string]interface{}); ok {
+				if c, ok := dependencyVals[dependsOnConditionKey]; ok {
+					condition = cast.ToString(c)
+				}
+			}
+			conditions[dependencyName] = condition
+		}
+		sort.Strings(dependencyNames)
+		shortSyntax := []interface{}{}
+		for _, dependencyName := range dependencyNames {
+			shortSyntax = append(shortSyntax, dependencyName)
+		}
+		vals[dependsOnKey] = shortSyntax
+		dependsOnConditions[serviceName] = conditions
+	}
+	return dependsOnConditions
+}
+
+// extractDeviceRequestsV3 removes the devices reserved in the deploy sections and the GPUs used with gpus,
+// which the parser does not support, and returns them for each of the services
+func extractDeviceRequestsV3(parsedComposeFile map[string]interface{}) map[string][]interface{} {
+	deviceRequests := map[string][]interface{}{}
+	services, ok := parsedComposeFile["services"].(map[string]interface{})
+	if !ok {
+		return deviceRequests
+	}
+	for serviceName, val := range services {
+		vals, ok := val.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if gpus, ok := vals[gpusKey]; ok {
+			delete(vals, gpusKey)
+			gpuRequests, ok := gpus.([]interface{})
+			if !ok {
+				// like gpus: all
+				gpuRequests = []interface{}{map[string]interface{}{"count": gpus}}
+			}
+			for _, gpuRequest := range gpuRequests {
+				if gpuRequest, ok := gpuRequest.(map[string]interface{}); ok {
+					gpuRequest["capabilities"] = []interface{}{gpuDeviceCapability}
+					deviceRequests[serviceName] = append(deviceRequests[serviceName], gpuRequest)
+				}
+			}
+		}
+		deploy, _ := vals["deploy"].(map[string]interface{})
+		resources, _ := deploy["resources"].(map[string]interface{})
+		reservations, _ := resources["reservations"].(map[string]interface{})
+		if devices, ok := reservations["devices"].([]interface{}); ok {
+			delete(reservations, "devices")
+			deviceRequests[serviceName] = append(deviceRequests[serviceName], devices...)
+		}
+	}
+	return deviceRequests
+}
+
+// getDeviceRequestsV3 returns the devices reserved by the service
+func getDeviceRequestsV3(composeServiceConfig types.ServiceConfig) []composeDeviceRequest {
+	deviceRequests := []composeDeviceRequest{}
+	vals, _ := composeServiceConfig.Extras[deviceRequestsKey].([]interface{})
+	for _, val := range vals {
+		deviceRequestVals, ok := val.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		deviceRequest := composeDeviceRequest{
+			driver:       cast.ToString(deviceRequestVals["driver"]),
+			capabilities: cast.ToStringSlice(deviceRequestVals["capabilities"]),
+			deviceIDs:    cast.ToStringSlice(deviceRequestVals["device_ids"]),
+		}
+		if count, ok := deviceRequestVals["count"]; ok {
+			if cast.ToString(count) == allDevicesCount {
+				deviceRequest.count = -1
+			} else if intCount, err := cast.ToInt64E(count); err == nil {
+				deviceRequest.count = intCount
+			} else {
+				logrus.Warnf("Ignoring the invalid device count %v of the service %s . Error: %q", count, composeServiceConfig.Name, err)
+			}
+		} else if len(deviceRequest.deviceIDs) == 0 {
+			// all the devices are used if neither the count nor the ids are given
+			deviceRequest.count = -1
+		}
+		deviceRequests = append(deviceRequests, deviceRequest)
+	}
+	return deviceRequests
+}
+
+// getProfilesV3 returns the profiles of the service
+func getProfilesV3(composeServiceConfig types.ServiceConfig) []string {
+	return cast.ToStringSlice(composeServiceConfig.Extras[profilesKey])
+}
+
+// readComposeFileV3 reads the version 3 compose file and resolves its extends
+func readComposeFileV3(path string) (map[string]interface{}, []byte, error) {
+	fileData, err := readComposeFile(path)
 	if err != nil {
 		err := fmt.Errorf("unable to load Compose file at path %s Error: %q", path, err)
 		logrus.Debug(err)
-		return nil, err
+		return nil, nil, err
 	}
 	// Parse the Compose File
 	parsedComposeFile, err := loader.ParseYAML(fileData)
 	if err != nil {
+		expandedFileData, expanded := expandIndentationTabs(fileData)
+		if !expanded {
+			err := fmt.Errorf("unable to load Compose file at path %s Error: %q", path, err)
+			logrus.Debug(err)
+			return nil, nil, err
+		}
+		var expandedErr error
+		if parsedComposeFile, expandedErr = loader.ParseYAML(expandedFileData); expandedErr != nil {
+			err := fmt.Errorf("unable to load Compose file at path %s Error: %q", path, err)
+			logrus.Debug(err)
+			return nil, nil, err
+		}
+		logrus.Warnf("The Compose file at path %s uses tabs for indentation. Replaced each of them with %d spaces", path, indentationTabWidth)
+	}
+	if parsedComposeFile, err = resolveExtends(path, parsedComposeFile); err != nil {
 		err := fmt.Errorf("unable to load Compose file at path %s Error: %q", path, err)
 		logrus.Debug(err)
+		return nil, nil, err
+	}
+	return removeNonExistentEnvFilesV3(path, parsedComposeFile), fileData, nil
+}
+
+// parseV3 parses version 3 compose files. The override files are merged into the compose file in order, like docker compose does.
+func parseV3(path string, overridePaths ...string) (*types.Config, error) {
+	parsedComposeFile, fileData, err := readComposeFileV3(path)
+	if err != nil {
 		return nil, err
 	}
-	parsedComposeFile = removeNonExistentEnvFilesV3(path, parsedComposeFile)
-	// Adding .env file values if it exists
-	var envMap map[string]string
-	composeFileDir := filepath.Dir(path)
-	envFilePath := filepath.Join(composeFileDir, defaultEnvFile)
-	finfo, err := os.Stat(envFilePath)
-	if os.IsNotExist(err) || finfo.IsDir() {
-		envMap = getEnvironmentVariables("")
-		logrus.Debugf("Unable to find .env file %s. Ignoring it.", envFilePath)
-	} else {
-		envMap = getEnvironmentVariables(envFilePath)
-		logrus.Debugf("Adding .env file from path [%s] values to environment", envFilePath)
+	for _, overridePath := range overridePaths {
+		overrideComposeFile, overrideFileData, err := readComposeFileV3(overridePath)
+		if err != nil {
+			return nil, err
+		}
+		parsedComposeFile = mergeComposeFiles(parsedComposeFile, overrideComposeFile)
+		fileData = append(append(fileData, '\n'), overrideFileData...)
+	}
+	// the parser does not support the project name, which is read separately
+	delete(parsedComposeFile, projectNameKey)
+	envMap := getComposeFileEnvironment(path)
+	if parsedComposeFile["services"] != nil {
+		for name, value := range getUnsetVariables(path, fileData, envMap) {
+			envMap[name] = value
+		}
+	}
+	inlineConfigs := extractInlineConfigsV3(path, parsedComposeFile, envMap)
+	serviceExtras := extractServiceExtrasV3(parsedComposeFile)
+	for serviceName, deviceRequests := range extractDeviceRequestsV3(parsedComposeFile) {
+		if serviceExtras[serviceName] == nil {
+			serviceExtras[serviceName] = map[string]interface{}{}
+		}
+		serviceExtras[serviceName][deviceRequestsKey] = deviceRequests
+	}
+	for serviceName, conditions := range extractDependsOnConditionsV3(parsedComposeFile) {
+		if serviceExtras[serviceName] == nil {
+			serviceExtras[serviceName] = map[string]interface{}{}
+		}
+		serviceExtras[serviceName][dependsOnKey] = conditions
 	}
 	// Config details
 	configDetails := types.ConfigDetails{
@@ -128,19 +349,116 @@ func parseV3(path string) (*types.Config, error) {
 		logrus.Debug(err)
 		return nil, err
 	}
+	for configName, content := range inlineConfigs {
+		configObj := config.Configs[configName]
+		// the parser sets the file of a config without one to the working directory
+		configObj.File = ""
+		if configObj.Extras == nil {
+			configObj.Extras = map[string]interface{}{}
+		}
+		configObj.Extras[inlineConfigContentExtra] = content
+		config.Configs[configName] = configObj
+	}
+	for i, service := range config.Services {
+		extras, ok := serviceExtras[service.Name]
+		if !ok {
+			continue
+		}
+		if service.Extras == nil {
+			config.Services[i].Extras = map[string]interface{}{}
+		}
+		for key, val := range extras {
+			config.Services[i].Extras[key] = val
+		}
+	}
 	return config, nil
 }
 
+// addLegacyResourcesV3 converts the legacy cpu settings of the service into resources and records its block IO settings
+func addLegacyResourcesV3(composeServiceConfig types.ServiceConfig, service *irtypes.Service, container *core.Container) {
+	getInt := func(key string) int64 {
+		val, ok := composeServiceConfig.Extras[key]
+		if !ok {
+			return 0
+		}
+		intVal, err := cast.ToInt64E(val)
+		if err != nil {
+			logrus.Warnf("Ignoring the invalid %s %v of the service %s . Error: %q", key, val, composeServiceConfig.Name, err)
+			return 0
+		}
+		return intVal
+	}
+	addLegacyCPUResources(composeServiceConfig.Name, container, getInt(cpuSharesKey), getInt(cpuQuotaKey), getInt(cpuPeriodKey))
+	if blkioConfig, ok := composeServiceConfig.Extras[blkioConfigKey]; ok {
+		addBlkioConfigAnnotation(composeServiceConfig.Name, service, blkioConfig)
+	}
+}
+
 // ConvertToIR loads an v3 compose file into IR
 func (c *v3Loader) ConvertToIR(composefilepath string, serviceName string, parseNetwork bool) (irtypes.IR, error) {
 	logrus.Debugf("About to load configuration from docker compose file at path %s", composefilepath)
-	config, err := parseV3(composefilepath)
+	config, err := getParsedV3(composefilepath, c.overrideFilePaths...)
 	if err != nil {
 		logrus.Debugf("Error while loading docker compose config : %s", err)
 		return irtypes.IR{}, err
 	}
 	logrus.Debugf("About to start loading docker compose to intermediate rep")
-	return c.convertToIR(filepath.Dir(composefilepath), *config, serviceName, parseNetwork)
+	ir, err := c.convertToIR(filepath.Dir(composefilepath), getServiceViewV3(config, serviceName), serviceName, parseNetwork)
+	if err != nil {
+		return ir, err
+	}
+	for _, service := range config.Services {
+		if service.Name == serviceName {
+			addDependencyWaitInitContainers(ir, getIRServiceName(c.serviceNames, serviceName), c.getDependencies(*config, service))
+		}
+	}
+	if isDependedOnWhenHealthyV3(*config, serviceName) {
+		addHealthCheckReadinessProbes(ir, getIRServiceName(c.serviceNames, serviceName))
+	}
+	return ir, nil
+}
+
+// getDependencies returns the services in depends_on along with the ports they can be reached on
+func (c *v3Loader) getDependencies(composeObject types.Config, service types.ServiceConfig) []composeDependency {
+	conditions, _ := service.Extras[dependsOnKey].(map[string]string)
+	dependencies := []composeDependency{}
+	for _, dependencyName := range service.DependsOn {
+		condition := serviceStartedCondition
+		if c, ok := conditions[dependencyName]; ok {
+			condition = c
+		}
+		if condition == serviceCompletedSuccessfullyCondition {
+			logrus.Warnf("The service %s waits for the service %s to complete successfully, which is not supported by the k8s workloads. Not waiting for it", service.Name, dependencyName)
+			report.AddFollowUp(service.Name, fmt.Sprintf("Run the service %s as a Job and wait for it to complete before deploying the service %s", dependencyName, service.Name))
+			continue
+		}
+		for _, composeServiceConfig := range composeObject.Services {
+			if composeServiceConfig.Name != dependencyName {
+				continue
+			}
+			if !isProfileActive(getProfilesV3(composeServiceConfig), c.activeProfiles) {
+				logrus.Infof("The service %s depends on the service %s , which is not converted since none of its profiles are enabled. Not waiting for it", service.Name, dependencyName)
+				continue
+			}
+			dependency := irtypes.NewServiceWithName(getIRServiceName(c.serviceNames, dependencyName))
+			addPortForwardings(c.getPortMappings(composeServiceConfig.Name, composeServiceConfig.Ports, composeServiceConfig.Expose), &dependency)
+			if port, ok := getDependencyPort(dependencyName, dependency); ok {
+				dependencies = append(dependencies, composeDependency{name: dependency.Name, port: port, condition: condition})
+			}
+		}
+	}
+	return dependencies
+}
+
+// isDependedOnWhenHealthyV3 returns true if any of the services waits for the service to be healthy
+func isDependedOnWhenHealthyV3(composeObject types.Config, serviceName string) bool {
+	for _, service := range composeObject.Services {
+		conditions, _ := service.Extras[dependsOnKey].(map[string]string)
+		if conditions[serviceName] == serviceHealthyCondition {
+			return true
+		}
+	}
+	return false
 }
 
 func (c *v3Loader) convertToIR(filedir string, composeObject types.Config, serviceName string, parseNetwork bool) (irtypes.IR, error) {
@@ -165,7 +483,7 @@ func (c *v3Loader) convertToIR(filedir string, composeObject types.Config, servi
 		if composeServiceConfig.Name != serviceName {
 			continue
 		}
-		name := common.NormalizeForMetadataName(composeServiceConfig.Name)
+		name := getIRServiceName(c.serviceNames, composeServiceConfig.Name)
 		serviceConfig := irtypes.NewServiceWithName(name)
 		serviceContainer := core.Container{}
 
@@ -187,6 +505,9 @@ func (c *v3Loader) convertToIR(filedir string, composeObject types.Config, servi
 					Artifacts: map[irtypes.ContainerBuildArtifactTypeValue][]string{
 						irtypes.DockerfileContainerBuildArtifactTypeValue: {filepath.Join(filedir, composeServiceConfig.Build.Dockerfile)},
 					},
+					BuildArgs: composeServiceConfig.Build.Args,
+					Target:    composeServiceConfig.Build.Target,
+					Labels:    composeServiceConfig.Build.Labels,
 				},
 			}
 		}
@@ -200,27 +521,39 @@ func (c *v3Loader) convertToIR(filedir string, composeObject types.Config, servi
 		serviceContainer.Name = common.NormalizeForMetadataName(composeServiceConfig.ContainerName)
 		serviceContainer.TTY = composeServiceConfig.Tty
 
-		if len(composeServiceConfig.Ports) == 0 {
+		if len(composeServiceConfig.Ports) == 0 && len(composeServiceConfig.Expose) == 0 {
+			composeServiceConfig.Expose = getImageExposedPorts(c.imageInfo)
+		}
+		if len(composeServiceConfig.Ports) == 0 && len(composeServiceConfig.Expose) == 0 {
 			selectedPort := commonqa.GetPortForService(nil, `"`+serviceConfig.Name+`"`)
 			composeServiceConfig.Ports = []types.ServicePortConfig{{Protocol: "tcp", Target: uint32(selectedPort), Published: uint32(selectedPort)}}
 		}
 
-		serviceContainer.Ports = c.getPorts(composeServiceConfig.Ports, composeServiceConfig.Expose)
-		c.addPorts(composeServiceConfig.Ports, composeServiceConfig.Expose, &serviceConfig)
+		portMappings := c.getPortMappings(composeServiceConfig.Name, composeServiceConfig.Ports, composeServiceConfig.Expose)
+		serviceContainer.Ports = getContainerPorts(portMappings)
+		addPortForwardings(portMappings, &serviceConfig)
 
-		serviceConfig.Annotations = map[string]string(composeServiceConfig.Labels)
-		serviceConfig.Labels = common.MergeStringMaps(composeServiceConfig.Labels, composeServiceConfig.Deploy.Labels)
+		annotations, err := getStringMap(composeServiceConfig.Extras[annotationsKey])
+		if err != nil {
+			logrus.Warnf("Ignoring the invalid annotations of the service %s . Error: %q", composeServiceConfig.Name, err)
+		}
+		serviceConfig.Labels, serviceConfig.Annotations = getLabelsAndAnnotations(composeServiceConfig.Name, composeServiceConfig.Labels, composeServiceConfig.Deploy.Labels, annotations)
 		if composeServiceConfig.Hostname != "" {
 			serviceConfig.Hostname = composeServiceConfig.Hostname
 		}
+		if composeServiceConfig.Logging != nil {
+			serviceConfig.LoggingDriver = composeServiceConfig.Logging.Driver
+		}
 		if composeServiceConfig.DomainName != "" {
 			serviceConfig.Subdomain = composeServiceConfig.DomainName
 		}
+		setDNSConfig(name, &serviceConfig, composeServiceConfig.DNS, composeServiceConfig.DNSSearch, cast.ToStringSlice(composeServiceConfig.Extras[dnsOptKey]))
 		if composeServiceConfig.Pid != "" {
 			if composeServiceConfig.Pid == "host" {
 				serviceConfig.SecurityContext.HostPID = true
 			} else {
 				logrus.Warnf("Ignoring PID key for service \"%v\". Invalid value \"%v\".", name, composeServiceConfig.Pid)
+				report.AddDroppedField(name, "pid", fmt.Sprintf("only the 'host' pid mode is supported. Actual: '%s'", composeServiceConfig.Pid))
 			}
 		}
 		securityContext := &core.SecurityContext{}
@@ -231,9 +564,12 @@ func (c *v3Loader) convertToIR(filedir string, composeObject types.Config, servi
 			uid, err := cast.ToInt64E(composeServiceConfig.User)
 			if err != nil {
 				logrus.Warn("Ignoring user directive. User to be specified as a UID (numeric).")
+				report.AddDroppedField(name, "user", fmt.Sprintf("the user '%s' is not a numeric UID", composeServiceConfig.User))
 			} else {
 				securityContext.RunAsUser = &uid
 			}
+		} else {
+			securityContext.RunAsUser = getImageUserID(c.imageInfo)
 		}
 		capsAdd := []core.Capability{}
 		capsDrop := []core.Capability{}
@@ -303,6 +639,7 @@ func (c *v3Loader) convertToIR(filedir string, composeObject types.Config, servi
 				serviceContainer.Resources.Requests = resourceRequests
 			}
 		}
+		addLegacyResourcesV3(composeServiceConfig, &serviceConfig, &serviceContainer)
 
 		// HealthCheck
 		if composeServiceConfig.HealthCheck != nil && !composeServiceConfig.HealthCheck.Disable {
@@ -310,7 +647,7 @@ func (c *v3Loader) convertToIR(filedir string, composeObject types.Config, servi
 			if err != nil {
 				logrus.Warnf("Unable to parse health check : %s", err)
 			} else {
-				serviceContainer.LivenessProbe = &probe
+				setHealthCheckProbes(&serviceContainer, probe)
 			}
 		}
 		restart := composeServiceConfig.Restart
@@ -319,13 +656,14 @@ func (c *v3Loader) convertToIR(filedir string, composeObject types.Config, servi
 		}
 		if restart == "unless-stopped" {
 			logrus.Warnf("Restart policy 'unless-stopped' in service %s is not supported, convert it to 'always'", name)
+			report.AddDroppedField(name, "restart", "the restart policy 'unless-stopped' is not supported and was converted to 'always'")
 			serviceConfig.RestartPolicy = core.RestartPolicyAlways
 		}
 		// replicas:
 		if composeServiceConfig.Deploy.Replicas != nil {
 			serviceConfig.Replicas = int(*composeServiceConfig.Deploy.Replicas)
 		}
-		serviceContainer.Env = c.getEnvs(composeServiceConfig)
+		serviceContainer.Env = c.getEnvs(serviceConfig.Name, composeServiceConfig, &ir)
 
 		vml, vl := makeVolumesFromTmpFS(name, composeServiceConfig.Tmpfs)
 		for _, v := range vl {
@@ -389,8 +727,12 @@ func (c *v3Loader) convertToIR(filedir string, composeObject types.Config, servi
 				if o.External.External {
 					logrus.Errorf("Config metadata %s has an external source", c.Source)
 				} else {
-					srcBaseName := filepath.Base(o.File)
-					vSrc.Items = []core.KeyToPath{{Key: srcBaseName, Path: filepath.Base(target)}}
+					key := filepath.Base(o.File)
+					if _, ok := o.Extras[inlineConfigContentExtra]; ok {
+						// the contents of the configs defined inline are stored using the name of the config
+						key = common.MakeStringK8sServiceNameCompliant(c.Source)
+					}
+					vSrc.Items = []core.KeyToPath{{Key: key, Path: filepath.Base(target)}}
 					if c.Mode != nil {
 						signedMode := int32(*c.Mode)
 						vSrc.DefaultMode = &signedMode
@@ -432,6 +774,8 @@ func (c *v3Loader) convertToIR(filedir string, composeObject types.Config, servi
 				storageMap[storage.Name] = true
 			}
 		}
+		adviseHostFeatures(name, hostFeatures{privileged: composeServiceConfig.Privileged, networkMode: composeServiceConfig.NetworkMode, devices: composeServiceConfig.Devices, sysctls: composeServiceConfig.Sysctls, deviceRequests: getDeviceRequestsV3(composeServiceConfig)}, &serviceConfig, &serviceContainer)
+		addUlimits(name, &serviceConfig, getUlimitsV3(composeServiceConfig.Ulimits))
 		serviceConfig.Containers = []core.Container{serviceContainer}
 		ir.Services[name] = serviceConfig
 	}
@@ -440,7 +784,7 @@ func (c *v3Loader) convertToIR(filedir string, composeObject types.Config, servi
 }
 
 func (c *v3Loader) getSecretStorages(secrets map[string]types.SecretConfig) []irtypes.Storage {
-	storages := make([]irtypes.Storage, len(secrets))
+	storages := make([]irtypes.Storage, 0, len(secrets))
 	for secretName, secretObj := range secrets {
 		secretName := common.MakeStringK8sServiceNameCompliant(secretName)
 		logrus.Debugf("Secret name [%s] is made compliant", secretName)
@@ -450,9 +794,11 @@ func (c *v3Loader) getSecretStorages(secrets map[string]types.SecretConfig) []ir
 		}
 
 		if !secretObj.External.External {
-			content, err := os.ReadFile(secretObj.File)
+			content, err := common.ReadFileWithLimit(secretObj.File, common.MaxEmbeddedFileSizeBytes)
 			if err != nil {
-				logrus.Warnf("Could not read the secret file [%s]", secretObj.File)
+				if !reportFileTooLarge(err, irtypes.SecretKind, secretName) {
+					logrus.Warnf("Could not read the secret file [%s]. Encountered [%s]", secretObj.File, err)
+				}
 			} else {
 				storage.Content = map[string][]byte{secretName: content}
 			}
@@ -465,7 +811,7 @@ func (c *v3Loader) getSecretStorages(secrets map[string]types.SecretConfig) []ir
 }
 
 func (c *v3Loader) getConfigStorages(configs map[string]types.ConfigObjConfig) []irtypes.Storage {
-	Storages := make([]irtypes.Storage, len(configs))
+	Storages := make([]irtypes.Storage, 0, len(configs))
 
 	for cfgName, cfgObj := range configs {
 		cfgName := common.MakeStringK8sServiceNameCompliant(cfgName)
@@ -474,20 +820,24 @@ func (c *v3Loader) getConfigStorages(configs map[string]types.ConfigObjConfig) [
 			StorageType: irtypes.ConfigMapKind,
 		}
 
-		if !cfgObj.External.External {
+		if content, ok := cfgObj.Extras[inlineConfigContentExtra].(string); ok {
+			storage.Content = map[string][]byte{cfgName: []byte(content)}
+		} else if !cfgObj.External.External {
 			fileInfo, err := os.Stat(cfgObj.File)
 			if err != nil {
 				logrus.Warnf("Could not identify the type of secret artifact [%s]. Encountered [%s]", cfgObj.File, err)
 			} else {
 				if !fileInfo.IsDir() {
-					content, err := os.ReadFile(cfgObj.File)
+					content, err := common.ReadFileWithLimit(cfgObj.File, common.MaxEmbeddedFileSizeBytes)
 					if err != nil {
-						logrus.Warnf("Could not read the secret file [%s]. Encountered [%s]", cfgObj.File, err)
+						if !reportFileTooLarge(err, irtypes.ConfigMapKind, cfgName) {
+							logrus.Warnf("Could not read the secret file [%s]. Encountered [%s]", cfgObj.File, err)
+						}
 					} else {
 						storage.Content = map[string][]byte{cfgName: content}
 					}
 				} else {
-					dataMap, err := getAllDirContentAsMap(cfgObj.File)
+					dataMap, err := getAllDirContentAsMap(cfgObj.File, irtypes.ConfigMapKind, cfgName)
 					if err != nil {
 						logrus.Warnf("Could not read the secret directory [%s]. Encountered [%s]", cfgObj.File, err)
 					} else {
@@ -502,74 +852,40 @@ func (c *v3Loader) getConfigStorages(configs map[string]types.ConfigObjConfig) [
 	return Storages
 }
 
-func (*v3Loader) getPorts(ports []types.ServicePortConfig, expose []string) []core.ContainerPort {
-	containerPorts := []core.ContainerPort{}
-	exist := map[string]bool{}
-	for _, port := range ports {
-		proto := core.ProtocolTCP
-		if strings.EqualFold(string(core.ProtocolUDP), port.Protocol) {
-			proto = core.ProtocolUDP
-		}
-		// Add the port to the k8s pod.
-		containerPorts = append(containerPorts, core.ContainerPort{
-			ContainerPort: int32(port.Target),
-			Protocol:      proto,
-		})
-		exist[cast.ToString(port.Target)] = true
-	}
-	for _, port := range expose {
-		portValue := port
-		protocol := core.ProtocolTCP
-		if strings.Contains(portValue, "/") {
-			splits := strings.Split(port, "/")
-			portValue = splits[0]
-			protocol = core.Protocol(strings.ToUpper(splits[1]))
-		}
-		if exist[portValue] {
-			continue
+// getPortMappings returns the ports and the exposed ports of the service, with the huge port ranges capped
+func (*v3Loader) getPortMappings(serviceName string, ports []types.ServicePortConfig, expose []string) []composePortMapping {
+	mappings := []composePortMapping{}
+	// the parser expands the port ranges into consecutive ports
+	for start := 0; start < len(ports); {
+		end := start + 1
+		for ; end < len(ports); end++ {
+			prev, next := ports[end-1], ports[end]
+			if next.Target != prev.Target+1 || next.Protocol != prev.Protocol || (next.Published != prev.Published+1 && (next.Published != 0 || prev.Published != 0)) {
+				break
+			}
 		}
-		// Add the port to the k8s pod.
-		containerPorts = append(containerPorts, core.ContainerPort{
-			ContainerPort: cast.ToInt32(portValue),
-			Protocol:      protocol,
-		})
-	}
-
-	return containerPorts
-}
-
-func (*v3Loader) addPorts(ports []types.ServicePortConfig, expose []string, service *irtypes.Service) {
-	exist := map[string]bool{}
-	for _, port := range ports {
-		// Forward the port on the k8s service to the k8s pod.
-		podPort := networking.ServiceBackendPort{
-			Number: int32(port.Target),
+		rangeMappings := []composePortMapping{}
+		for _, port := range ports[start:end] {
+			rangeMappings = append(rangeMappings, composePortMapping{published: int32(port.Published), target: int32(port.Target), protocol: getPortProtocol(port.Protocol)})
 		}
-		servicePort := networking.ServiceBackendPort{
-			Number: int32(port.Published),
+		first, last := ports[start], ports[end-1]
+		portRange := fmt.Sprintf("%d-%d/%s", first.Target, last.Target, first.Protocol)
+		if first.Published != 0 {
+			portRange = fmt.Sprintf("%d-%d:%s", first.Published, last.Published, portRange)
 		}
-		service.AddPortForwarding(servicePort, podPort, "")
-		exist[cast.ToString(port.Target)] = true
+		mappings = append(mappings, capPortRange(serviceName, portRange, rangeMappings)...)
+		start = end
 	}
-	for _, port := range expose {
-		portValue := port
-		if strings.Contains(portValue, "/") {
-			splits := strings.Split(port, "/")
-			portValue = splits[0]
-		}
-		if exist[portValue] {
-			continue
-		}
-		// Forward the port on the k8s service to the k8s pod.
-		portNumber := cast.ToInt32(portValue)
-		podPort := networking.ServiceBackendPort{
-			Number: portNumber,
-		}
-		servicePort := networking.ServiceBackendPort{
-			Number: portNumber,
+	exist := map[composePortMapping]bool{}
+	for _, mapping := range mappings {
+		exist[composePortMapping{target: mapping.target, protocol: mapping.protocol}] = true
+	}
+	for _, mapping := range getPortMappings(serviceName, expose, true) {
+		if !exist[composePortMapping{target: mapping.target, protocol: mapping.protocol}] {
+			mappings = append(mappings, mapping)
 		}
-		service.AddPortForwarding(servicePort, podPort, "")
 	}
+	return mappings
 }
 
 func (c *v3Loader) getNetworks(composeServiceConfig types.ServiceConfig, composeObject types.Config) (networks []string) {
@@ -581,33 +897,55 @@ func (c *v3Loader) getNetworks(composeServiceConfig types.ServiceConfig, compose
 		}
 		networks = append(networks, netName)
 	}
+	sort.Strings(networks)
 	return networks
 }
 
-func (c *v3Loader) getHealthCheck(composeHealthCheck types.HealthCheckConfig) (core.Probe, error) {
-	probe := core.Probe{}
-
-	if len(composeHealthCheck.Test) > 1 {
+func (c *v3Loader) getHealthCheck(composeHealthCheck types.HealthCheckConfig) (*core.Probe, error) {
+	if len(composeHealthCheck.Test) == 0 {
+		logrus.Warnf("Could not find command to execute in probe : %s", composeHealthCheck.Test)
+		return nil, nil
+	}
+	probe := &core.Probe{}
+	// the test is either NONE, or an exec array after CMD, or a shell command after CMD-SHELL.
+	// docker/cli converts a test given as a string to the CMD-SHELL form.
+	// The health checks only requesting a url or connecting to a port of the container become network probes.
+	switch composeHealthCheck.Test[0] {
+	case healthCheckNone:
+		logrus.Debugf("The health check is disabled using %s", healthCheckNone)
+		return nil, nil
+	case healthCheckCmd:
+		if len(composeHealthCheck.Test) < 2 {
+			return nil, fmt.Errorf("the health check %s has no command", composeHealthCheck.Test)
+		}
+		probe.ProbeHandler = getHealthCheckProbeHandler(composeHealthCheck.Test[1:])
+	case healthCheckCmdShell:
+		if len(composeHealthCheck.Test) < 2 {
+			return nil, fmt.Errorf("the health check %s has no command", composeHealthCheck.Test)
+		}
+		shellCommand := strings.Join(composeHealthCheck.Test[1:], " ")
 		probe.ProbeHandler = core.ProbeHandler{
-			Exec: &core.ExecAction{
-				// docker/cli adds "CMD-SHELL" to the struct, hence we remove the first element of composeHealthCheck.Test
-				Command: composeHealthCheck.Test[1:],
-			},
+			Exec: &core.ExecAction{Command: []string{"sh", "-c", shellCommand}},
 		}
-	} else {
-		logrus.Warnf("Could not find command to execute in probe : %s", composeHealthCheck.Test)
+		if command := getShellHealthCheckCommand(shellCommand); command != nil {
+			if handler := getHealthCheckProbeHandler(command); handler.Exec == nil {
+				probe.ProbeHandler = handler
+			}
+		}
+	default:
+		return nil, fmt.Errorf("the health check %s must start with one of %s, %s or %s", composeHealthCheck.Test, healthCheckNone, healthCheckCmd, healthCheckCmdShell)
 	}
 	if composeHealthCheck.Timeout != nil {
 		parse, err := time.ParseDuration(composeHealthCheck.Timeout.String())
 		if err != nil {
-			return probe, errors.Wrap(err, "unable to parse health check timeout variable")
+			return nil, errors.Wrap(err, "unable to parse health check timeout variable")
 		}
 		probe.TimeoutSeconds = int32(parse.Seconds())
 	}
 	if composeHealthCheck.Interval != nil {
 		parse, err := time.ParseDuration(composeHealthCheck.Interval.String())
 		if err != nil {
-			return probe, errors.Wrap(err, "unable to parse health check interval variable")
+			return nil, errors.Wrap(err, "unable to parse health check interval variable")
 		}
 		probe.PeriodSeconds = int32(parse.Seconds())
 	}
@@ -617,7 +955,7 @@ func (c *v3Loader) getHealthCheck(composeHealthCheck types.HealthCheckConfig) (c
 	if composeHealthCheck.StartPeriod != nil {
 		parse, err := time.ParseDuration(composeHealthCheck.StartPeriod.String())
 		if err != nil {
-			return probe, errors.Wrap(err, "unable to parse health check startPeriod variable")
+			return nil, errors.Wrap(err, "unable to parse health check startPeriod variable")
 		}
 		probe.InitialDelaySeconds = int32(parse.Seconds())
 	}
@@ -625,13 +963,56 @@ func (c *v3Loader) getHealthCheck(composeHealthCheck types.HealthCheckConfig) (c
 	return probe, nil
 }
 
-func (c *v3Loader) getEnvs(composeServiceConfig types.ServiceConfig) (envs []core.EnvVar) {
-	for name, value := range composeServiceConfig.Environment {
+// getUlimitsV3 returns the soft and hard limits of the ulimits, which can be a single value used for both
+func getUlimitsV3(ulimitsConfig map[string]*types.UlimitsConfig) map[string]composeUlimit {
+	ulimits := map[string]composeUlimit{}
+	for name, ulimit := range ulimitsConfig {
+		if ulimit == nil {
+			continue
+		}
+		if ulimit.Single != 0 {
+			ulimits[name] = composeUlimit{Soft: int64(ulimit.Single), Hard: int64(ulimit.Single)}
+			continue
+		}
+		ulimits[name] = composeUlimit{Soft: int64(ulimit.Soft), Hard: int64(ulimit.Hard)}
+	}
+	return ulimits
+}
+
+// getStartupProbe moves a start period longer than the probe period from the initial delay of the liveness probe
+// to a startup probe, so that slow starting services get the whole start period while still being probed early
+func getStartupProbe(livenessProbe *core.Probe) (*core.Probe, *core.Probe) {
+	if livenessProbe == nil {
+		return nil, nil
+	}
+	periodSeconds := livenessProbe.PeriodSeconds
+	if periodSeconds <= 0 {
+		periodSeconds = defaultProbePeriodSeconds
+	}
+	if livenessProbe.InitialDelaySeconds <= periodSeconds {
+		return livenessProbe, nil
+	}
+	startupProbe := livenessProbe.DeepCopy()
+	startupProbe.InitialDelaySeconds = 0
+	startupProbe.PeriodSeconds = periodSeconds
+	startupProbe.FailureThreshold = (livenessProbe.InitialDelaySeconds + periodSeconds - 1) / periodSeconds
+	livenessProbe.InitialDelaySeconds = 0
+	return livenessProbe, startupProbe
+}
+
+func (c *v3Loader) getEnvs(serviceName string, composeServiceConfig types.ServiceConfig, ir *irtypes.IR) (envs []core.EnvVar) {
+	names := []string{}
+	for name := range composeServiceConfig.Environment {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := composeServiceConfig.Environment[name]
 		var env core.EnvVar
 		if value != nil {
 			env = core.EnvVar{Name: name, Value: *value}
 		} else {
-			env = core.EnvVar{Name: name, Value: "unknown"}
+			env = getUnsetEnv(serviceName, name, ir)
 		}
 		envs = append(envs, env)
 	}
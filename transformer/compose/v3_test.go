@@ -0,0 +1,544 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package compose
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/docker/cli/cli/compose/types"
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common/report"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestGetHealthCheck(t *testing.T) {
+	c := &v3Loader{}
+	interval := types.Duration(30 * time.Second)
+	retries := uint64(3)
+	testcases := []struct {
+		name string
+		test types.HealthCheckTest
+		want *core.Probe
+	}{
+		{
+			name: "exec form",
+			test: types.HealthCheckTest{"CMD", "pg_isready", "-U", "postgres"},
+			want: &core.Probe{ProbeHandler: core.ProbeHandler{Exec: &core.ExecAction{Command: []string{"pg_isready", "-U", "postgres"}}}, PeriodSeconds: 30, FailureThreshold: 3},
+		},
+		{
+			name: "shell form",
+			test: types.HealthCheckTest{"CMD-SHELL", "pg_isready -U $$POSTGRES_USER || exit 1"},
+			want: &core.Probe{ProbeHandler: core.ProbeHandler{Exec: &core.ExecAction{Command: []string{"sh", "-c", "pg_isready -U $$POSTGRES_USER || exit 1"}}}, PeriodSeconds: 30, FailureThreshold: 3},
+		},
+		{
+			name: "curl in the exec form",
+			test: types.HealthCheckTest{"CMD", "curl", "-fsS", "-H", "Accept: application/json", "http://localhost:8080/health?full=true"},
+			want: &core.Probe{ProbeHandler: core.ProbeHandler{HTTPGet: &core.HTTPGetAction{
+				Path:        "/health?full=true",
+				Port:        intstr.FromInt(8080),
+				Scheme:      core.URISchemeHTTP,
+				HTTPHeaders: []core.HTTPHeader{{Name: "Accept", Value: "application/json"}},
+			}}, PeriodSeconds: 30, FailureThreshold: 3},
+		},
+		{
+			name: "curl without fail",
+			test: types.HealthCheckTest{"CMD", "curl", "http://localhost"},
+			want: &core.Probe{ProbeHandler: core.ProbeHandler{Exec: &core.ExecAction{Command: []string{"curl", "http://localhost"}}}, PeriodSeconds: 30, FailureThreshold: 3},
+		},
+		{
+			name: "curl of another host",
+			test: types.HealthCheckTest{"CMD-SHELL", "curl -f http://db/health || exit 1"},
+			want: &core.Probe{ProbeHandler: core.ProbeHandler{Exec: &core.ExecAction{Command: []string{"sh", "-c", "curl -f http://db/health || exit 1"}}}, PeriodSeconds: 30, FailureThreshold: 3},
+		},
+		{
+			name: "wget in the shell form",
+			test: types.HealthCheckTest{"CMD-SHELL", "wget -q --spider https://127.0.0.1/ || exit 1"},
+			want: &core.Probe{ProbeHandler: core.ProbeHandler{HTTPGet: &core.HTTPGetAction{Path: "/", Port: intstr.FromInt(443), Scheme: core.URISchemeHTTPS}}, PeriodSeconds: 30, FailureThreshold: 3},
+		},
+		{
+			name: "nc in the shell form",
+			test: types.HealthCheckTest{"CMD-SHELL", "nc -z localhost 6379"},
+			want: &core.Probe{ProbeHandler: core.ProbeHandler{TCPSocket: &core.TCPSocketAction{Port: intstr.FromInt(6379)}}, PeriodSeconds: 30, FailureThreshold: 3},
+		},
+		{
+			name: "curl piped in the shell form",
+			test: types.HealthCheckTest{"CMD-SHELL", "curl -f http://localhost | grep ok"},
+			want: &core.Probe{ProbeHandler: core.ProbeHandler{Exec: &core.ExecAction{Command: []string{"sh", "-c", "curl -f http://localhost | grep ok"}}}, PeriodSeconds: 30, FailureThreshold: 3},
+		},
+		{
+			name: "disabled",
+			test: types.HealthCheckTest{"NONE"},
+		},
+	}
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			probe, err := c.getHealthCheck(types.HealthCheckConfig{Test: testcase.test, Interval: &interval, Retries: &retries})
+			if err != nil {
+				t.Fatalf("failed to get the health check. Error: %q", err)
+			}
+			if !cmp.Equal(testcase.want, probe) {
+				t.Fatalf("wrong probe. Difference:\n%s", cmp.Diff(testcase.want, probe))
+			}
+		})
+	}
+	t.Run("invalid", func(t *testing.T) {
+		for _, test := range []types.HealthCheckTest{{"CMD"}, {"curl", "-f", "http://localhost"}} {
+			if _, err := c.getHealthCheck(types.HealthCheckConfig{Test: test}); err == nil {
+				t.Fatalf("expected an error for the health check %s", test)
+			}
+		}
+	})
+}
+
+func TestGetStartupProbe(t *testing.T) {
+	handler := core.ProbeHandler{Exec: &core.ExecAction{Command: []string{"true"}}}
+	t.Run("long start period", func(t *testing.T) {
+		livenessProbe, startupProbe := getStartupProbe(&core.Probe{ProbeHandler: handler, InitialDelaySeconds: 95, PeriodSeconds: 10, FailureThreshold: 3})
+		wantLivenessProbe := &core.Probe{ProbeHandler: handler, PeriodSeconds: 10, FailureThreshold: 3}
+		wantStartupProbe := &core.Probe{ProbeHandler: handler, PeriodSeconds: 10, FailureThreshold: 10}
+		if !cmp.Equal(wantLivenessProbe, livenessProbe) {
+			t.Fatalf("wrong liveness probe. Difference:\n%s", cmp.Diff(wantLivenessProbe, livenessProbe))
+		}
+		if !cmp.Equal(wantStartupProbe, startupProbe) {
+			t.Fatalf("wrong startup probe. Difference:\n%s", cmp.Diff(wantStartupProbe, startupProbe))
+		}
+	})
+	t.Run("short start period", func(t *testing.T) {
+		probe := &core.Probe{ProbeHandler: handler, InitialDelaySeconds: 5}
+		livenessProbe, startupProbe := getStartupProbe(probe)
+		if livenessProbe != probe || livenessProbe.InitialDelaySeconds != 5 || startupProbe != nil {
+			t.Fatalf("expected the liveness probe to be kept as is and no startup probe. Actual: %+v %+v", livenessProbe, startupProbe)
+		}
+	})
+}
+
+func TestSetHealthCheckProbes(t *testing.T) {
+	handler := core.ProbeHandler{Exec: &core.ExecAction{Command: []string{"true"}}}
+	t.Run("all the probes", func(t *testing.T) {
+		qaengine.ResetEngines()
+		defer qaengine.ResetEngines()
+		qaengine.AddEngine(qaengine.NewDefaultEngine())
+		qaengine.SetupConfigFile("", nil, nil, nil, false)
+		container := core.Container{}
+		setHealthCheckProbes(&container, &core.Probe{ProbeHandler: handler, InitialDelaySeconds: 60, PeriodSeconds: 10})
+		wantProbe := &core.Probe{ProbeHandler: handler, PeriodSeconds: 10}
+		wantStartupProbe := &core.Probe{ProbeHandler: handler, PeriodSeconds: 10, FailureThreshold: 6}
+		if !cmp.Equal(wantProbe, container.LivenessProbe) || !cmp.Equal(wantProbe, container.ReadinessProbe) {
+			t.Fatalf("wrong liveness and readiness probes. Actual: %+v %+v", container.LivenessProbe, container.ReadinessProbe)
+		}
+		if !cmp.Equal(wantStartupProbe, container.StartupProbe) {
+			t.Fatalf("wrong startup probe. Difference:\n%s", cmp.Diff(wantStartupProbe, container.StartupProbe))
+		}
+	})
+	t.Run("only the liveness probe", func(t *testing.T) {
+		qaengine.ResetEngines()
+		defer qaengine.ResetEngines()
+		qaengine.AddEngine(qaengine.NewDefaultEngine())
+		qaengine.SetupConfigFile("", []string{`move2kube.compose.healthcheckprobes=["` + livenessProbeOpt + `"]`}, nil, nil, false)
+		container := core.Container{}
+		setHealthCheckProbes(&container, &core.Probe{ProbeHandler: handler, InitialDelaySeconds: 60, PeriodSeconds: 10})
+		wantProbe := &core.Probe{ProbeHandler: handler, InitialDelaySeconds: 60, PeriodSeconds: 10}
+		if !cmp.Equal(wantProbe, container.LivenessProbe) || container.ReadinessProbe != nil || container.StartupProbe != nil {
+			t.Fatalf("expected only the liveness probe with the start period. Actual: %+v %+v %+v", container.LivenessProbe, container.ReadinessProbe, container.StartupProbe)
+		}
+	})
+}
+
+func TestInlineConfigs(t *testing.T) {
+	qaengine.ResetEngines()
+	defer qaengine.ResetEngines()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", nil, nil, nil, false)
+	t.Setenv("APP_SETTINGS", "debug=false")
+	composeFilePath := filepath.Join(t.TempDir(), "docker-compose.yaml")
+	composeFile := `version: "3.8"
+services:
+  web:
+    image: nginx
+    configs:
+      - nginx
+      - settings
+configs:
+  nginx:
+    content: |
+      server {}
+  settings:
+    environment: APP_SETTINGS
+`
+	if err := os.WriteFile(composeFilePath, []byte(composeFile), 0644); err != nil {
+		t.Fatalf("failed to write the compose file. Error: %q", err)
+	}
+	config, err := parseV3(composeFilePath)
+	if err != nil {
+		t.Fatalf("failed to parse the compose file. Error: %q", err)
+	}
+	storages := (&v3Loader{}).getConfigStorages(config.Configs)
+	contents := map[string]string{}
+	for _, storage := range storages {
+		contents[storage.Name] = string(storage.Content[storage.Name])
+	}
+	want := map[string]string{"nginx": "server {}\n", "settings": "debug=false"}
+	if !cmp.Equal(want, contents) {
+		t.Fatalf("wrong config contents. Difference:\n%s", cmp.Diff(want, contents))
+	}
+	ir, err := (&v3Loader{}).convertToIR(filepath.Dir(composeFilePath), getServiceViewV3(config, "web"), "web", false)
+	if err != nil {
+		t.Fatalf("failed to convert the compose file. Error: %q", err)
+	}
+	items := map[string][]core.KeyToPath{}
+	for _, volume := range ir.Services["web"].Volumes {
+		if volume.ConfigMap != nil {
+			items[volume.Name] = volume.ConfigMap.Items
+		}
+	}
+	wantItems := map[string][]core.KeyToPath{
+		"nginx":    {{Key: "nginx", Path: "nginx"}},
+		"settings": {{Key: "settings", Path: "settings"}},
+	}
+	if diff := cmp.Diff(wantItems, items); diff != "" {
+		t.Fatalf("wrong config volumes. Differences:\n%s", diff)
+	}
+}
+
+func TestGetPortMappingsV3(t *testing.T) {
+	qaengine.ResetEngines()
+	defer qaengine.ResetEngines()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	ports := []types.ServicePortConfig{{Target: 6060, Published: 6060, Protocol: "udp"}}
+	for port := uint32(7000); port < 7150; port++ {
+		ports = append(ports, types.ServicePortConfig{Target: port, Published: port, Protocol: "tcp"})
+	}
+	mappings := (&v3Loader{}).getPortMappings("web", ports, []string{"6060/udp", "8080"})
+	want := []composePortMapping{
+		{published: 6060, target: 6060, protocol: core.ProtocolUDP},
+		{published: 7000, target: 7000, protocol: core.ProtocolTCP},
+		{published: 8080, target: 8080, protocol: core.ProtocolTCP},
+	}
+	if !cmp.Equal(want, mappings, cmp.AllowUnexported(composePortMapping{})) {
+		t.Fatalf("wrong port mappings. Difference:\n%s", cmp.Diff(want, mappings, cmp.AllowUnexported(composePortMapping{})))
+	}
+}
+
+func TestLegacyResources(t *testing.T) {
+	qaengine.ResetEngines()
+	defer qaengine.ResetEngines()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", []string{`move2kube.services."web".cpurequest="500m"`}, nil, nil, false)
+	composeFilePath := filepath.Join(t.TempDir(), "docker-compose.yaml")
+	composeFile := `version: "3.8"
+services:
+  web:
+    image: nginx
+    cpu_shares: 512
+    cpu_quota: 50000
+    cpu_period: 200000
+    blkio_config:
+      weight: 300
+      device_read_bps:
+        - path: /dev/sda
+          rate: 12mb
+`
+	if err := os.WriteFile(composeFilePath, []byte(composeFile), 0644); err != nil {
+		t.Fatalf("failed to write the compose file. Error: %q", err)
+	}
+	config, err := parseV3(composeFilePath)
+	if err != nil {
+		t.Fatalf("failed to parse the compose file. Error: %q", err)
+	}
+	service := irtypes.NewServiceWithName("web")
+	container := core.Container{}
+	addLegacyResourcesV3(config.Services[0], &service, &container)
+	if cpuLimit := container.Resources.Limits[core.ResourceCPU]; cpuLimit.MilliValue() != 250 {
+		t.Fatalf("expected a cpu limit of 250m. Actual: %s", cpuLimit.String())
+	}
+	if cpuRequest := container.Resources.Requests[core.ResourceCPU]; cpuRequest.MilliValue() != 250 {
+		t.Fatalf("expected the cpu request to be capped at the limit of 250m. Actual: %s", cpuRequest.String())
+	}
+	t.Run("cpu shares without a cpu request", func(t *testing.T) {
+		qaengine.ResetEngines()
+		qaengine.AddEngine(qaengine.NewDefaultEngine())
+		qaengine.SetupConfigFile("", nil, nil, nil, false)
+		report.Reset()
+		defer report.Reset()
+		container := core.Container{}
+		addLegacyCPUResources("web", &container, 512, 0, 0)
+		if _, ok := container.Resources.Requests[core.ResourceCPU]; ok {
+			t.Fatalf("expected no cpu request by default. Actual: %+v", container.Resources.Requests)
+		}
+		if len(report.Get("", "").Spec.DroppedFields) != 1 {
+			t.Fatalf("expected the cpu shares to be reported as dropped")
+		}
+	})
+	want := `{"device_read_bps":[{"path":"/dev/sda","rate":"12mb"}],"weight":300}`
+	if diff := cmp.Diff(want, service.Annotations[blkioConfigAnnotation]); diff != "" {
+		t.Fatalf("wrong block IO annotation. Differences:\n%s", diff)
+	}
+}
+
+func TestUlimits(t *testing.T) {
+	composeFilePath := filepath.Join(t.TempDir(), "docker-compose.yaml")
+	composeFile := `version: "3.8"
+services:
+  web:
+    image: nginx
+    ulimits:
+      nproc: 65535
+      nofile:
+        soft: 20000
+        hard: 40000
+`
+	if err := os.WriteFile(composeFilePath, []byte(composeFile), 0644); err != nil {
+		t.Fatalf("failed to write the compose file. Error: %q", err)
+	}
+	config, err := parseV3(composeFilePath)
+	if err != nil {
+		t.Fatalf("failed to parse the compose file. Error: %q", err)
+	}
+	report.Reset()
+	defer report.Reset()
+	service := irtypes.NewServiceWithName("web")
+	addUlimits("web", &service, getUlimitsV3(config.Services[0].Ulimits))
+	want := `{"nofile":{"soft":20000,"hard":40000},"nproc":{"soft":65535,"hard":65535}}`
+	if diff := cmp.Diff(want, service.Annotations[ulimitsAnnotation]); diff != "" {
+		t.Fatalf("wrong ulimits annotation. Differences:\n%s", diff)
+	}
+	r := report.Get("", "")
+	fields := []string{}
+	for _, droppedField := range r.Spec.DroppedFields {
+		fields = append(fields, droppedField.Field)
+	}
+	if diff := cmp.Diff([]string{"ulimits.nofile", "ulimits.nproc"}, fields); diff != "" {
+		t.Fatalf("wrong dropped fields. Differences:\n%s", diff)
+	}
+	if len(r.Spec.FollowUps) != 2 {
+		t.Fatalf("expected the follow ups for the node limits. Actual: %+v", r.Spec.FollowUps)
+	}
+}
+
+func TestDeviceRequests(t *testing.T) {
+	composeFilePath := filepath.Join(t.TempDir(), "docker-compose.yaml")
+	composeFile := `version: "3.8"
+services:
+  trainer:
+    image: trainer
+    deploy:
+      resources:
+        reservations:
+          memory: 1g
+          devices:
+            - driver: nvidia
+              count: 2
+              capabilities: [gpu]
+            - driver: amd
+              device_ids: ["0"]
+              capabilities: [gpu]
+  inference:
+    image: inference
+    gpus: all
+`
+	if err := os.WriteFile(composeFilePath, []byte(composeFile), 0644); err != nil {
+		t.Fatalf("failed to write the compose file. Error: %q", err)
+	}
+	config, err := parseV3(composeFilePath)
+	if err != nil {
+		t.Fatalf("failed to parse the compose file. Error: %q", err)
+	}
+	sort.Slice(config.Services, func(i, j int) bool { return config.Services[i].Name < config.Services[j].Name })
+	want := map[string]core.ResourceList{
+		"inference": {"nvidia.com/gpu": *resource.NewQuantity(1, resource.DecimalSI)},
+		"trainer":   {"nvidia.com/gpu": *resource.NewQuantity(2, resource.DecimalSI), "amd.com/gpu": *resource.NewQuantity(1, resource.DecimalSI)},
+	}
+	for _, service := range config.Services {
+		container := core.Container{}
+		addDeviceRequests(service.Name, getDeviceRequestsV3(service), &container)
+		if diff := cmp.Diff(want[service.Name], container.Resources.Limits); diff != "" {
+			t.Fatalf("wrong resource limits of the service %s . Differences:\n%s", service.Name, diff)
+		}
+	}
+}
+
+func TestDependsOnConditions(t *testing.T) {
+	qaengine.ResetEngines()
+	defer qaengine.ResetEngines()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", nil, nil, nil, false)
+	composeFilePath := filepath.Join(t.TempDir(), "docker-compose.yaml")
+	composeFile := `version: "3.8"
+services:
+  web:
+    image: nginx
+    depends_on:
+      db:
+        condition: service_healthy
+      cache:
+        condition: service_started
+      migrate:
+        condition: service_completed_successfully
+  db:
+    image: postgres
+    ports:
+      - "5432:5432"
+    healthcheck:
+      test: ["CMD", "pg_isready"]
+  cache:
+    image: redis
+    expose:
+      - "6379"
+  migrate:
+    image: migrate
+`
+	if err := os.WriteFile(composeFilePath, []byte(composeFile), 0644); err != nil {
+		t.Fatalf("failed to write the compose file. Error: %q", err)
+	}
+	config, err := parseV3(composeFilePath)
+	if err != nil {
+		t.Fatalf("failed to parse the compose file. Error: %q", err)
+	}
+	c := v3Loader{}
+	for _, service := range config.Services {
+		if service.Name != "web" {
+			continue
+		}
+		if diff := cmp.Diff([]string{"cache", "db", "migrate"}, service.DependsOn); diff != "" {
+			t.Fatalf("wrong dependencies. Differences:\n%s", diff)
+		}
+		want := []composeDependency{{name: "cache", port: 6379, condition: serviceStartedCondition}, {name: "db", port: 5432, condition: serviceHealthyCondition}}
+		if diff := cmp.Diff(want, c.getDependencies(*config, service), cmp.AllowUnexported(composeDependency{})); diff != "" {
+			t.Fatalf("wrong dependencies. Differences:\n%s", diff)
+		}
+	}
+	if !isDependedOnWhenHealthyV3(*config, "db") || isDependedOnWhenHealthyV3(*config, "cache") {
+		t.Fatalf("expected only the service db to be waited for until it is healthy")
+	}
+	ir := irtypes.IR{Services: map[string]irtypes.Service{"web": irtypes.NewServiceWithName("web")}}
+	addDependencyWaitInitContainers(ir, "web", []composeDependency{{name: "db", port: 5432, condition: serviceHealthyCondition}})
+	if len(ir.Services["web"].InitContainers) != 1 {
+		t.Fatalf("expected the dependencies to be waited for by default when they have to be healthy. Actual: %+v", ir.Services["web"].InitContainers)
+	}
+}
+
+func TestComposeProfiles(t *testing.T) {
+	qaengine.ResetEngines()
+	defer qaengine.ResetEngines()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", nil, nil, nil, false)
+	composeFileDir := t.TempDir()
+	composeFilePath := filepath.Join(composeFileDir, "docker-compose.yaml")
+	composeFile := `version: "3.8"
+services:
+  web:
+    image: nginx
+  debug:
+    image: busybox
+    profiles: ["debug"]
+  metrics:
+    image: prometheus
+    profiles: ["monitoring", "debug"]
+  admin:
+    image: adminer
+    profiles: ["tools"]
+`
+	if err := os.WriteFile(composeFilePath, []byte(composeFile), 0644); err != nil {
+		t.Fatalf("failed to write the compose file. Error: %q", err)
+	}
+	if err := os.WriteFile(filepath.Join(composeFileDir, defaultEnvFile), []byte("COMPOSE_PROFILES=monitoring,unknown\n"), 0644); err != nil {
+		t.Fatalf("failed to write the .env file. Error: %q", err)
+	}
+	config, err := parseV3(composeFilePath)
+	if err != nil {
+		t.Fatalf("failed to parse the compose file. Error: %q", err)
+	}
+	if len(config.Services) != 4 {
+		t.Fatalf("expected the services of all the profiles to be parsed. Actual: %+v", config.Services)
+	}
+	newArtifacts := []transformertypes.Artifact{}
+	for _, service := range config.Services {
+		newArtifacts = append(newArtifacts, (&ComposeAnalyser{}).getService(composeFilePath, nil, service.Name, getProfilesV3(service), service.Image, "", "", nil))
+	}
+	activeProfiles := getActiveProfiles(newArtifacts)
+	if diff := cmp.Diff([]string{"monitoring"}, activeProfiles); diff != "" {
+		t.Fatalf("wrong active profiles. Differences:\n%s", diff)
+	}
+	enabled := []string{}
+	for _, service := range config.Services {
+		if isProfileActive(getProfilesV3(service), activeProfiles) {
+			enabled = append(enabled, service.Name)
+		}
+	}
+	sort.Strings(enabled)
+	if diff := cmp.Diff([]string{"metrics", "web"}, enabled); diff != "" {
+		t.Fatalf("wrong enabled services. Differences:\n%s", diff)
+	}
+}
+
+func TestGetDependenciesInactiveProfiles(t *testing.T) {
+	config := types.Config{Services: []types.ServiceConfig{
+		{Name: "web", DependsOn: []string{"db", "debug"}},
+		{Name: "db", Expose: types.StringOrNumberList{"5432"}},
+		{Name: "debug", Expose: types.StringOrNumberList{"9229"}, Extras: map[string]interface{}{profilesKey: []interface{}{"debug"}}},
+	}}
+	dependencies := (&v3Loader{}).getDependencies(config, config.Services[0])
+	want := []composeDependency{{name: "db", port: 5432, condition: serviceStartedCondition}}
+	if diff := cmp.Diff(want, dependencies, cmp.AllowUnexported(composeDependency{})); diff != "" {
+		t.Fatalf("expected only the dependencies in the enabled profiles. Differences:\n%s", diff)
+	}
+}
+
+func TestPlannedServiceNames(t *testing.T) {
+	qaengine.ResetEngines()
+	defer qaengine.ResetEngines()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", []string{`move2kube.services."frontend".dependencywaitstrategy="` + tcpDependencyWaitStrategy + `"`}, nil, nil, false)
+	composeFilePath := filepath.Join(t.TempDir(), "docker-compose.yaml")
+	composeFile := `version: "3.8"
+services:
+  web_api:
+    image: api
+    expose: ["8080"]
+  web.api:
+    image: api
+    expose: ["9090"]
+  frontend:
+    image: frontend
+    depends_on: ["web.api"]
+`
+	if err := os.WriteFile(composeFilePath, []byte(composeFile), 0644); err != nil {
+		t.Fatalf("failed to write the compose file. Error: %q", err)
+	}
+	serviceNames := map[string]string{"web_api": "web-api", "web.api": "web-api-0123abcd", "frontend": "frontend"}
+	ir, err := (&v3Loader{serviceNames: serviceNames}).ConvertToIR(composeFilePath, "frontend", false)
+	if err != nil {
+		t.Fatalf("failed to convert the compose file. Error: %q", err)
+	}
+	initContainers := ir.Services["frontend"].InitContainers
+	if len(initContainers) != 1 {
+		t.Fatalf("expected an init container waiting for the dependency. Actual: %+v", initContainers)
+	}
+	want := []core.EnvVar{{Name: dependencyWaitHostEnv, Value: "web-api-0123abcd"}, {Name: dependencyWaitPortEnv, Value: "9090"}}
+	if diff := cmp.Diff(want, initContainers[0].Env); diff != "" {
+		t.Fatalf("expected the planned name of the dependency. Differences:\n%s", diff)
+	}
+}
@@ -49,6 +49,11 @@ type ImagePushTemplateConfig struct {
 	RegistryURL       string
 	RegistryNamespace string
 	Images            []string
+	// PushImageNames maps each local image to the name and tag it is pushed with
+	PushImageNames map[string]string
+	// DockerContext and PodmanConnection choose the daemon the images were built on, if it is not the default one
+	DockerContext    string
+	PodmanConnection string
 }
 
 // Init Initializes the transformer
@@ -96,6 +101,13 @@ func (t *ContainerImagesPushScript) Transform(newArtifacts []transformertypes.Ar
 	}
 	ipt.RegistryURL = commonqa.ImageRegistry()
 	ipt.RegistryNamespace = commonqa.ImageRegistryNamespace()
+	imageTag := commonqa.ImageTag()
+	ipt.DockerContext = commonqa.DockerContext()
+	ipt.PodmanConnection = commonqa.PodmanConnection()
+	ipt.PushImageNames = map[string]string{}
+	for _, image := range ipt.Images {
+		ipt.PushImageNames[image] = commonqa.GetTaggedImageName(image, imageTag)
+	}
 	pathMappings = append(pathMappings, transformertypes.PathMapping{
 		Type:           transformertypes.TemplatePathMappingType,
 		SrcPath:        filepath.Join(t.Env.Context, t.Config.Spec.TemplatesDir),
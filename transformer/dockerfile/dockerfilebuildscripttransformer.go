@@ -19,6 +19,8 @@ package dockerfile
 import (
 	"fmt"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/konveyor/move2kube/common"
 	"github.com/konveyor/move2kube/environment"
@@ -52,14 +54,21 @@ type DockerfileImageBuildScriptTemplateConfig struct {
 	DockerfilesConfig    []DockerfileImageBuildConfig
 	RegistryURL          string
 	RegistryNamespace    string
+	// DockerContext and PodmanConnection choose the daemon the images are built on, if it is not the default one
+	DockerContext    string
+	PodmanConnection string
 }
 
 // DockerfileImageBuildConfig contains the Dockerfile image build config to be used in the ImageBuild script
 type DockerfileImageBuildConfig struct {
 	DockerfileName string
 	ImageName      string
+	PushImageName  string
 	ContextUnix    string
 	ContextWindows string
+	// BuildOptionsUnix and BuildOptionsWindows are the quoted build args, target and labels passed to the build
+	BuildOptionsUnix    string
+	BuildOptionsWindows string
 }
 
 // Init Initializes the transformer
@@ -111,6 +120,11 @@ func (t *DockerfileImageBuildScript) Transform(newArtifacts []transformertypes.A
 		processedImages[imageName.ImageName] = true
 		var dockerfileImageBuildConfig DockerfileImageBuildConfig
 		dockerfileImageBuildConfig.ImageName = imageName.ImageName
+		buildOptions := artifacts.DockerfileBuildOptions{}
+		if err := artifact.GetConfig(artifacts.DockerfileBuildOptionsConfigType, &buildOptions); err == nil {
+			dockerfileImageBuildConfig.BuildOptionsUnix = getBuildOptions(buildOptions, quoteUnixArg)
+			dockerfileImageBuildConfig.BuildOptionsWindows = getBuildOptions(buildOptions, quoteWindowsArg)
+		}
 		for _, dockerfilePath := range artifact.Paths[artifacts.DockerfilePathType] {
 			dockerContextPath := filepath.Dir(dockerfilePath)
 			relDockerfilePath := filepath.Base(dockerfilePath)
@@ -177,6 +191,12 @@ func (t *DockerfileImageBuildScript) Transform(newArtifacts []transformertypes.A
 		RegistryURL:          commonqa.ImageRegistry(),
 		RegistryNamespace:    commonqa.ImageRegistryNamespace(),
 		DockerfilesConfig:    dockerfilesImageBuildConfig,
+		DockerContext:        commonqa.DockerContext(),
+		PodmanConnection:     commonqa.PodmanConnection(),
+	}
+	imageTag := commonqa.ImageTag()
+	for i, dockerfileImageBuildConfig := range templateData.DockerfilesConfig {
+		templateData.DockerfilesConfig[i].PushImageName = commonqa.GetTaggedImageName(dockerfileImageBuildConfig.ImageName, imageTag)
 	}
 	pathMappings = append(pathMappings, transformertypes.PathMapping{
 		Type:           transformertypes.TemplatePathMappingType,
@@ -218,3 +238,43 @@ func (t *DockerfileImageBuildScript) Transform(newArtifacts []transformertypes.A
 	})
 	return pathMappings, createdArtifacts, nil
 }
+
+// getBuildOptions returns the flags for the build args, the target and the labels, each preceded by a space
+func getBuildOptions(buildOptions artifacts.DockerfileBuildOptions, quote func(string) string) string {
+	options := ""
+	buildArgNames := []string{}
+	for name := range buildOptions.BuildArgs {
+		buildArgNames = append(buildArgNames, name)
+	}
+	sort.Strings(buildArgNames)
+	for _, name := range buildArgNames {
+		if value := buildOptions.BuildArgs[name]; value != nil {
+			options += " --build-arg " + quote(name+"="+*value)
+		} else {
+			// the value is taken from the environment of the build
+			options += " --build-arg " + quote(name)
+		}
+	}
+	if buildOptions.Target != "" {
+		options += " --target " + quote(buildOptions.Target)
+	}
+	labelNames := []string{}
+	for name := range buildOptions.Labels {
+		labelNames = append(labelNames, name)
+	}
+	sort.Strings(labelNames)
+	for _, name := range labelNames {
+		options += " --label " + quote(name+"="+buildOptions.Labels[name])
+	}
+	return options
+}
+
+// quoteUnixArg quotes the argument for the shell scripts
+func quoteUnixArg(arg string) string {
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+// quoteWindowsArg quotes the argument for the batch scripts
+func quoteWindowsArg(arg string) string {
+	return `"` + strings.ReplaceAll(strings.ReplaceAll(arg, "%", "%%"), `"`, `\"`) + `"`
+}
@@ -0,0 +1,44 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package dockerfile
+
+import (
+	"testing"
+
+	"github.com/konveyor/move2kube/types/transformer/artifacts"
+)
+
+func TestGetBuildOptions(t *testing.T) {
+	version := "1.2"
+	greeting := "it's 100%"
+	buildOptions := artifacts.DockerfileBuildOptions{
+		BuildArgs: map[string]*string{"VERSION": &version, "TOKEN": nil, "GREETING": &greeting},
+		Target:    "prod",
+		Labels:    map[string]string{"com.example.team": "web"},
+	}
+	want := ` --build-arg 'GREETING=it'\''s 100%' --build-arg 'TOKEN' --build-arg 'VERSION=1.2' --target 'prod' --label 'com.example.team=web'`
+	if got := getBuildOptions(buildOptions, quoteUnixArg); got != want {
+		t.Fatalf("wrong build options for the shell scripts. Expected: %s Actual: %s", want, got)
+	}
+	want = ` --build-arg "GREETING=it's 100%%" --build-arg "TOKEN" --build-arg "VERSION=1.2" --target "prod" --label "com.example.team=web"`
+	if got := getBuildOptions(buildOptions, quoteWindowsArg); got != want {
+		t.Fatalf("wrong build options for the batch scripts. Expected: %s Actual: %s", want, got)
+	}
+	if got := getBuildOptions(artifacts.DockerfileBuildOptions{}, quoteUnixArg); got != "" {
+		t.Fatalf("expected no build options. Actual: %s", got)
+	}
+}
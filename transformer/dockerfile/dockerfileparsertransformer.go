@@ -19,6 +19,7 @@ package dockerfile
 import (
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	"github.com/konveyor/move2kube/common"
@@ -29,10 +30,35 @@ import (
 	dockerparser "github.com/moby/buildkit/frontend/dockerfile/parser"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cast"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	core "k8s.io/kubernetes/pkg/apis/core"
 	"k8s.io/kubernetes/pkg/apis/networking"
 )
 
+const (
+	// jvmStartupProbePeriodSeconds and jvmStartupProbeFailureThreshold give the JVM apps 5 minutes to start
+	jvmStartupProbePeriodSeconds    = 10
+	jvmStartupProbeFailureThreshold = 30
+)
+
+var (
+	// jvmRegex matches the base images and the commands of the JVM apps
+	jvmRegex    = regexp.MustCompile(`(?i)(^|[/:\s"-])(java|openjdk|jdk|jre|temurin|corretto|semeru|tomcat|catalina\.sh|wildfly|jboss|liberty|websphere|wlp/bin/server)($|[/:\s".-])`)
+	nodejsRegex = regexp.MustCompile(`(?i)(^|[/:\s"-])(node|nodejs|npm|yarn|pnpm)($|[/:\s".-])`)
+	pythonRegex = regexp.MustCompile(`(?i)(^|[/:\s"-])(python|python3|pip|gunicorn|uvicorn)($|[/:\s".-])`)
+	dotnetRegex = regexp.MustCompile(`(?i)(^|[/:\s"-])(dotnet|aspnet)($|[/:\s".-])`)
+	// languageRegexes are checked in order to find the language of the app run by the last stage of a Dockerfile
+	languageRegexes = []struct {
+		language string
+		regex    *regexp.Regexp
+	}{
+		{language: irtypes.JavaLanguage, regex: jvmRegex},
+		{language: irtypes.DotnetLanguage, regex: dotnetRegex},
+		{language: irtypes.NodejsLanguage, regex: nodejsRegex},
+		{language: irtypes.PythonLanguage, regex: pythonRegex},
+	}
+)
+
 // DockerfileParser implements Transformer interface
 type DockerfileParser struct {
 	Config transformertypes.Transformer
@@ -92,7 +118,7 @@ func (t *DockerfileParser) Transform(newArtifacts []transformertypes.Artifact, a
 			if contextPaths, ok := newArtifact.Paths[artifacts.DockerfileContextPathType]; ok && len(contextPaths) > 0 {
 				contextPath = contextPaths[0]
 			}
-			createdArtifact, err := t.getIRFromDockerfile(paths[0], contextPath, imageName.ImageName, serviceConfig.ServiceName, serviceFsPath, ir)
+			createdArtifact, err := t.getIRFromDockerfile(paths[0], contextPath, imageName.ImageName, serviceConfig.ServiceName, serviceConfig.Language, serviceFsPath, ir)
 			if err != nil {
 				logrus.Errorf("failed to convert the Dockerfile to IR. Error: %q", err)
 				continue
@@ -103,7 +129,7 @@ func (t *DockerfileParser) Transform(newArtifacts []transformertypes.Artifact, a
 	return nil, createdArtifacts, nil
 }
 
-func (t *DockerfileParser) getIRFromDockerfile(dockerfilepath, contextPath, imageName, serviceName, serviceFsPath string, ir irtypes.IR) (transformertypes.Artifact, error) {
+func (t *DockerfileParser) getIRFromDockerfile(dockerfilepath, contextPath, imageName, serviceName, language, serviceFsPath string, ir irtypes.IR) (transformertypes.Artifact, error) {
 	df, err := t.getDockerFileAST(dockerfilepath)
 	if err != nil {
 		logrus.Errorf("Unable to parse dockerfile : %s", err)
@@ -147,7 +173,8 @@ func (t *DockerfileParser) getIRFromDockerfile(dockerfilepath, contextPath, imag
 	}
 	container.Build.Artifacts = t111
 
-	if len(container.ExposedPorts) == 0 {
+	portsDetected := len(container.ExposedPorts) != 0
+	if !portsDetected {
 		logrus.Warnf("Unable to find ports in Dockerfile : %s. Using default port %d", dockerfilepath, common.DefaultServicePort)
 		container.AddExposedPort(common.DefaultServicePort)
 	}
@@ -166,6 +193,20 @@ func (t *DockerfileParser) getIRFromDockerfile(dockerfilepath, contextPath, imag
 		irService.AddPortForwarding(servicePort, podPort, "")
 	}
 	serviceContainer.Ports = serviceContainerPorts
+	if t.isJVMContainer(df) {
+		if portsDetected {
+			logrus.Debugf("The Dockerfile %s runs a JVM app. Adding a startup probe to give it time to start", dockerfilepath)
+			serviceContainer.LivenessProbe, serviceContainer.StartupProbe = getJVMProbes(container.ExposedPorts[0])
+		} else {
+			// probing the default port would restart the container forever if the app does not listen on it
+			logrus.Debugf("The Dockerfile %s runs a JVM app but does not expose any ports. Not adding any probes", dockerfilepath)
+		}
+	}
+	irService.Language = language
+	if irService.Language == "" {
+		// the language of the Dockerfiles that were not generated by a transformer is guessed from the base image and the commands
+		irService.Language = t.getLanguage(df)
+	}
 	irService.Containers = []core.Container{serviceContainer}
 	if t.isWindowsContainer(df) {
 		irService.Annotations = map[string]string{common.WindowsAnnotation: common.AnnotationLabelValue}
@@ -219,3 +260,63 @@ func (t *DockerfileParser) isWindowsContainer(df *dockerparser.Result) bool {
 	}
 	return false
 }
+
+// isJVMContainer returns true if the last stage of the Dockerfile runs a JVM app
+func (t *DockerfileParser) isJVMContainer(df *dockerparser.Result) bool {
+	isJVM := false
+	for _, dfchild := range df.AST.Children {
+		switch {
+		case strings.EqualFold(dfchild.Value, "FROM"):
+			// only the last stage gets run
+			isJVM = dfchild.Next != nil && jvmRegex.MatchString(dfchild.Next.Value)
+		case strings.EqualFold(dfchild.Value, "CMD"), strings.EqualFold(dfchild.Value, "ENTRYPOINT"):
+			for node := dfchild.Next; node != nil; node = node.Next {
+				if jvmRegex.MatchString(node.Value) {
+					isJVM = true
+				}
+			}
+		}
+	}
+	return isJVM
+}
+
+// getLanguage returns the language of the app run by the last stage of the Dockerfile, or an empty string if it is unknown
+func (t *DockerfileParser) getLanguage(df *dockerparser.Result) string {
+	language := ""
+	for _, dfchild := range df.AST.Children {
+		values := []string{}
+		switch {
+		case strings.EqualFold(dfchild.Value, "FROM"):
+			// only the last stage gets run
+			language = ""
+			if dfchild.Next != nil {
+				values = append(values, dfchild.Next.Value)
+			}
+		case strings.EqualFold(dfchild.Value, "CMD"), strings.EqualFold(dfchild.Value, "ENTRYPOINT"):
+			for node := dfchild.Next; node != nil; node = node.Next {
+				values = append(values, node.Value)
+			}
+		}
+		for _, value := range values {
+			for _, languageRegex := range languageRegexes {
+				if languageRegex.regex.MatchString(value) {
+					language = languageRegex.language
+					break
+				}
+			}
+		}
+	}
+	return language
+}
+
+// getJVMProbes returns the liveness probe and the startup probe for a slow starting JVM app listening on the port
+func getJVMProbes(port int32) (*core.Probe, *core.Probe) {
+	handler := core.ProbeHandler{TCPSocket: &core.TCPSocketAction{Port: intstr.FromInt(int(port))}}
+	livenessProbe := &core.Probe{ProbeHandler: handler}
+	startupProbe := &core.Probe{
+		ProbeHandler:     handler,
+		PeriodSeconds:    jvmStartupProbePeriodSeconds,
+		FailureThreshold: jvmStartupProbeFailureThreshold,
+	}
+	return livenessProbe, startupProbe
+}
@@ -0,0 +1,169 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package dockerfile
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	environmenttypes "github.com/konveyor/move2kube/types/environment"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	dockerparser "github.com/moby/buildkit/frontend/dockerfile/parser"
+)
+
+func TestIsJVMContainer(t *testing.T) {
+	testcases := []struct {
+		dockerfile string
+		want       bool
+	}{
+		{dockerfile: "FROM eclipse-temurin:17-jre\nCOPY app.jar .\nCMD [\"java\", \"-jar\", \"app.jar\"]\n", want: true},
+		{dockerfile: "FROM registry.access.redhat.com/ubi8/ubi-minimal:latest\nCMD java -jar app.jar\n", want: true},
+		{dockerfile: "FROM tomcat:10\nEXPOSE 8080\n", want: true},
+		{dockerfile: "FROM maven:3 AS builder\nRUN mvn package\nFROM nginx:latest\nCOPY --from=builder /target /usr/share/nginx/html\n", want: false},
+		{dockerfile: "FROM node:18\nCMD [\"node\", \"javascript.js\"]\n", want: false},
+	}
+	parser := &DockerfileParser{}
+	for _, testcase := range testcases {
+		df, err := dockerparser.Parse(strings.NewReader(testcase.dockerfile))
+		if err != nil {
+			t.Fatalf("failed to parse the Dockerfile. Error: %q", err)
+		}
+		if actual := parser.isJVMContainer(df); actual != testcase.want {
+			t.Fatalf("wrong JVM detection for the Dockerfile:\n%s\nExpected: %t Actual: %t", testcase.dockerfile, testcase.want, actual)
+		}
+	}
+}
+
+func TestGetLanguage(t *testing.T) {
+	testcases := []struct {
+		dockerfile string
+		want       string
+	}{
+		{dockerfile: "FROM eclipse-temurin:17-jre\nCOPY app.jar .\nCMD [\"java\", \"-jar\", \"app.jar\"]\n", want: "java"},
+		{dockerfile: "FROM registry.access.redhat.com/ubi8/nodejs-16\nCMD npm run start\n", want: "nodejs"},
+		{dockerfile: "FROM registry.access.redhat.com/ubi8/python-36\nCMD [\"python\", \"app.py\"]\n", want: "python"},
+		{dockerfile: "FROM mcr.microsoft.com/dotnet/sdk:6.0 AS builder\nFROM mcr.microsoft.com/dotnet/aspnet:6.0\nCMD [\"dotnet\", \"app.dll\"]\n", want: "dotnet"},
+		{dockerfile: "FROM node:18 AS builder\nRUN npm run build\nFROM nginx:latest\nCOPY --from=builder /app/dist /usr/share/nginx/html\n", want: ""},
+	}
+	parser := &DockerfileParser{}
+	for _, testcase := range testcases {
+		df, err := dockerparser.Parse(strings.NewReader(testcase.dockerfile))
+		if err != nil {
+			t.Fatalf("failed to parse the Dockerfile. Error: %q", err)
+		}
+		if actual := parser.getLanguage(df); actual != testcase.want {
+			t.Fatalf("wrong language detection for the Dockerfile:\n%s\nExpected: %q Actual: %q", testcase.dockerfile, testcase.want, actual)
+		}
+	}
+}
+
+func TestGetIRFromDockerfileJVMProbes(t *testing.T) {
+	oldTempPath := common.TempPath
+	common.TempPath = t.TempDir()
+	defer func() { common.TempPath = oldTempPath }()
+	sourceDir := t.TempDir()
+	env, err := environment.NewEnvironment(environment.EnvInfo{
+		Name:    "DockerfileParser",
+		Source:  sourceDir,
+		Output:  t.TempDir(),
+		Context: t.TempDir(),
+		EnvPlatformConfig: environmenttypes.EnvPlatformConfig{
+			Platforms: []string{runtime.GOOS},
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("failed to create the environment. Error: %q", err)
+	}
+	defer env.Destroy()
+	parser := &DockerfileParser{}
+	if err := parser.Init(transformertypes.NewTransformer(), env); err != nil {
+		t.Fatalf("failed to initialize the transformer. Error: %q", err)
+	}
+	testcases := []struct {
+		dockerfile string
+		wantProbes bool
+	}{
+		{dockerfile: "FROM eclipse-temurin:17-jre\nEXPOSE 9000\nCMD [\"java\", \"-jar\", \"app.jar\"]\n", wantProbes: true},
+		{dockerfile: "FROM eclipse-temurin:17-jre\nCMD [\"java\", \"-jar\", \"worker.jar\"]\n", wantProbes: false},
+	}
+	for _, testcase := range testcases {
+		dockerfilePath := filepath.Join(sourceDir, "Dockerfile")
+		if err := os.WriteFile(dockerfilePath, []byte(testcase.dockerfile), common.DefaultFilePermission); err != nil {
+			t.Fatalf("failed to write the Dockerfile. Error: %q", err)
+		}
+		artifact, err := parser.getIRFromDockerfile(dockerfilePath, sourceDir, "app", "app", "", sourceDir, irtypes.NewIR())
+		if err != nil {
+			t.Fatalf("failed to get the IR from the Dockerfile. Error: %q", err)
+		}
+		ir := irtypes.IR{}
+		if err := artifact.GetConfig(irtypes.IRConfigType, &ir); err != nil {
+			t.Fatalf("failed to get the IR from the artifact. Error: %q", err)
+		}
+		container := ir.Services["app"].Containers[0]
+		if hasProbes := container.StartupProbe != nil && container.LivenessProbe != nil; hasProbes != testcase.wantProbes {
+			t.Fatalf("wrong probes for the Dockerfile:\n%s\nExpected probes: %t Actual: %+v %+v", testcase.dockerfile, testcase.wantProbes, container.LivenessProbe, container.StartupProbe)
+		}
+		if testcase.wantProbes && container.StartupProbe.TCPSocket.Port.IntValue() != 9000 {
+			t.Fatalf("expected the probes to use the exposed port 9000. Actual: %+v", container.StartupProbe.TCPSocket)
+		}
+	}
+}
+
+func TestGetIRFromDockerfileLanguage(t *testing.T) {
+	oldTempPath := common.TempPath
+	common.TempPath = t.TempDir()
+	defer func() { common.TempPath = oldTempPath }()
+	sourceDir := t.TempDir()
+	env, err := environment.NewEnvironment(environment.EnvInfo{
+		Name:              "test",
+		Source:            sourceDir,
+		Output:            t.TempDir(),
+		Context:           t.TempDir(),
+		EnvPlatformConfig: environmenttypes.EnvPlatformConfig{Platforms: []string{runtime.GOOS}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("failed to create the environment. Error: %q", err)
+	}
+	defer env.Destroy()
+	parser := &DockerfileParser{}
+	if err := parser.Init(transformertypes.NewTransformer(), env); err != nil {
+		t.Fatalf("failed to initialize the transformer. Error: %q", err)
+	}
+	dockerfilePath := filepath.Join(sourceDir, "Dockerfile")
+	if err := os.WriteFile(dockerfilePath, []byte("FROM registry.example.com/base:1\nCMD [\"./app\"]\n"), common.DefaultFilePermission); err != nil {
+		t.Fatalf("failed to write the Dockerfile. Error: %q", err)
+	}
+	for _, language := range []string{irtypes.PythonLanguage, ""} {
+		artifact, err := parser.getIRFromDockerfile(dockerfilePath, sourceDir, "app", "app", language, sourceDir, irtypes.NewIR())
+		if err != nil {
+			t.Fatalf("failed to get the IR from the Dockerfile. Error: %q", err)
+		}
+		ir := irtypes.IR{}
+		if err := artifact.GetConfig(irtypes.IRConfigType, &ir); err != nil {
+			t.Fatalf("failed to get the IR from the artifact. Error: %q", err)
+		}
+		if actual := ir.Services["app"].Language; actual != language {
+			t.Fatalf("expected the language detected by the generator '%s' to be used. Actual: '%s'", language, actual)
+		}
+	}
+}
@@ -571,7 +571,7 @@ func (t *DotNetCoreDockerfileGenerator) TransformArtifact(newArtifact transforme
 		// artifacts to inform other transformers of the Dockerfile we generated
 
 		paths := map[transformertypes.PathType][]string{artifacts.DockerfilePathType: {dockerfilePath}}
-		serviceConfig := artifacts.ServiceConfig{ServiceName: childProject.Name}
+		serviceConfig := artifacts.ServiceConfig{ServiceName: childProject.Name, Language: irtypes.DotnetLanguage}
 		imageName := artifacts.ImageName{ImageName: common.MakeStringContainerImageNameCompliant(childProject.Name)}
 		dockerfileArtifact := transformertypes.Artifact{
 			Name:  imageName.ImageName,
@@ -133,6 +133,7 @@ func (t *JarAnalyser) Transform(newArtifacts []transformertypes.Artifact, alread
 			logrus.Debugf("failed to load the service config from the artifact %+v . Error: %q", newArtifact, err)
 			continue
 		}
+		serviceConfig.Language = irtypes.JavaLanguage
 		imageName := artifacts.ImageName{}
 		if err := newArtifact.GetConfig(artifacts.ImageNameConfigType, &imageName); err != nil {
 			logrus.Debugf("failed to load the image name config from the artifact %+v . Error: %q", newArtifact, err)
@@ -90,6 +90,7 @@ func (t *Jboss) Transform(newArtifacts []transformertypes.Artifact, alreadySeenA
 			logrus.Errorf("unable to load config for Transformer into %T : %s", serviceConfig, err)
 			continue
 		}
+		serviceConfig.Language = irtypes.JavaLanguage
 		if serviceConfig.ServiceName == "" {
 			serviceConfig.ServiceName = common.MakeStringK8sServiceNameCompliant(newArtifact.Name)
 		}
@@ -108,6 +108,7 @@ func (t *Liberty) Transform(newArtifacts []transformertypes.Artifact, alreadySee
 			logrus.Errorf("failed to load service config from the artifact: %+v . Error: %q", serviceConfig, err)
 			continue
 		}
+		serviceConfig.Language = irtypes.JavaLanguage
 		if serviceConfig.ServiceName == "" {
 			serviceConfig.ServiceName = common.MakeStringK8sServiceNameCompliant(newArtifact.Name)
 		}
@@ -94,6 +94,7 @@ func (t *Tomcat) Transform(newArtifacts []transformertypes.Artifact, alreadySeen
 			logrus.Debugf("failed to load service config from the artifact: %+v . Error: %q", newArtifact, err)
 			continue
 		}
+		serviceConfig.Language = irtypes.JavaLanguage
 		if serviceConfig.ServiceName == "" {
 			serviceConfig.ServiceName = common.MakeStringK8sServiceNameCompliant(newArtifact.Name)
 		}
@@ -194,6 +194,7 @@ func (t *NodejsDockerfileGenerator) Transform(newArtifacts []transformertypes.Ar
 			logrus.Errorf("unable to load config for Transformer into %T . Error: %q", serviceConfig, err)
 			continue
 		}
+		serviceConfig.Language = irtypes.NodejsLanguage
 		imageName := artifacts.ImageName{}
 		if err := newArtifact.GetConfig(artifacts.ImageNameConfigType, &imageName); err != nil {
 			logrus.Debugf("unable to load config for Transformer into %T . Error: %q", imageName, err)
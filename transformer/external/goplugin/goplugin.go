@@ -0,0 +1,177 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package goplugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/rpc"
+
+	"github.com/hashicorp/go-plugin"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+)
+
+const (
+	// PluginName is the name under which the transformer is dispensed by the plugin
+	PluginName = "transformer"
+)
+
+// Handshake is used by move2kube and the plugins to verify that they can talk to each other
+var Handshake = plugin.HandshakeConfig{
+	ProtocolVersion:  1,
+	MagicCookieKey:   "MOVE2KUBE_TRANSFORMER_PLUGIN",
+	MagicCookieValue: "a4c5e1d4-3b7f-4c4e-9a1e-6c0f9f3b2d11",
+}
+
+// PluginMap is the map of plugins that move2kube can dispense
+var PluginMap = map[string]plugin.Plugin{
+	PluginName: &TransformerPlugin{},
+}
+
+// InitInput contains the details passed to a plugin when it is initialized
+type InitInput struct {
+	Config      transformertypes.Transformer `json:"config"`
+	ProjectName string                       `json:"projectName"`
+	SourceDir   string                       `json:"sourceDir"`
+	OutputDir   string                       `json:"outputDir"`
+	ContextDir  string                       `json:"contextDir"`
+	TempDir     string                       `json:"tempDir"`
+}
+
+// Transformer is the interface that the transformer plugins implement.
+// It follows the same contract as the built-in transformers.
+type Transformer interface {
+	Init(input InitInput) error
+	DirectoryDetect(dir string) (map[string][]transformertypes.Artifact, error)
+	Transform(newArtifacts []transformertypes.Artifact, alreadySeenArtifacts []transformertypes.Artifact) ([]transformertypes.PathMapping, []transformertypes.Artifact, error)
+}
+
+// Serve starts serving the transformer. It should be called from the main function of the plugin.
+func Serve(impl Transformer) {
+	plugin.Serve(&plugin.ServeConfig{
+		HandshakeConfig: Handshake,
+		Plugins:         map[string]plugin.Plugin{PluginName: &TransformerPlugin{Impl: impl}},
+	})
+}
+
+// TransformerPlugin implements the go-plugin Plugin interface for transformers over net/rpc
+type TransformerPlugin struct {
+	Impl Transformer
+}
+
+// Server returns the RPC server for the plugin side
+func (p *TransformerPlugin) Server(*plugin.MuxBroker) (interface{}, error) {
+	return &RPCServer{Impl: p.Impl}, nil
+}
+
+// Client returns the RPC client for the move2kube side
+func (*TransformerPlugin) Client(_ *plugin.MuxBroker, c *rpc.Client) (interface{}, error) {
+	return &RPCClient{client: c}, nil
+}
+
+// The artifacts are sent as json since their configs contain arbitrary types that gob cannot encode
+
+// RPCClient is the move2kube side of the transformer plugin
+type RPCClient struct {
+	client *rpc.Client
+}
+
+// Init initializes the transformer in the plugin
+func (c *RPCClient) Init(input InitInput) error {
+	inputBytes, err := json.Marshal(input)
+	if err != nil {
+		return fmt.Errorf("failed to marshal the init input to json. Error: %w", err)
+	}
+	return c.client.Call("Plugin.Init", inputBytes, new(bool))
+}
+
+// DirectoryDetect runs the detect of the plugin on the directory
+func (c *RPCClient) DirectoryDetect(dir string) (map[string][]transformertypes.Artifact, error) {
+	outputBytes := []byte{}
+	if err := c.client.Call("Plugin.DirectoryDetect", dir, &outputBytes); err != nil {
+		return nil, err
+	}
+	services := map[string][]transformertypes.Artifact{}
+	if err := json.Unmarshal(outputBytes, &services); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the detect output of the plugin. Error: %w", err)
+	}
+	return services, nil
+}
+
+// Transform runs the transform of the plugin on the artifacts
+func (c *RPCClient) Transform(newArtifacts []transformertypes.Artifact, alreadySeenArtifacts []transformertypes.Artifact) ([]transformertypes.PathMapping, []transformertypes.Artifact, error) {
+	inputBytes, err := json.Marshal(transformertypes.TransformInput{NewArtifacts: newArtifacts, AlreadySeenArtifacts: alreadySeenArtifacts})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal the transform input to json. Error: %w", err)
+	}
+	outputBytes := []byte{}
+	if err := c.client.Call("Plugin.Transform", inputBytes, &outputBytes); err != nil {
+		return nil, nil, err
+	}
+	output := transformertypes.TransformOutput{}
+	if err := json.Unmarshal(outputBytes, &output); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal the transform output of the plugin. Error: %w", err)
+	}
+	return output.PathMappings, output.CreatedArtifacts, nil
+}
+
+// RPCServer is the plugin side of the transformer plugin
+type RPCServer struct {
+	Impl Transformer
+}
+
+// Init initializes the transformer
+func (s *RPCServer) Init(inputBytes []byte, resp *bool) error {
+	input := InitInput{}
+	if err := json.Unmarshal(inputBytes, &input); err != nil {
+		return fmt.Errorf("failed to unmarshal the init input. Error: %w", err)
+	}
+	*resp = true
+	return s.Impl.Init(input)
+}
+
+// DirectoryDetect runs detect on the directory
+func (s *RPCServer) DirectoryDetect(dir string, resp *[]byte) error {
+	services, err := s.Impl.DirectoryDetect(dir)
+	if err != nil {
+		return err
+	}
+	outputBytes, err := json.Marshal(services)
+	if err != nil {
+		return fmt.Errorf("failed to marshal the detect output to json. Error: %w", err)
+	}
+	*resp = outputBytes
+	return nil
+}
+
+// Transform transforms the artifacts
+func (s *RPCServer) Transform(inputBytes []byte, resp *[]byte) error {
+	input := transformertypes.TransformInput{}
+	if err := json.Unmarshal(inputBytes, &input); err != nil {
+		return fmt.Errorf("failed to unmarshal the transform input. Error: %w", err)
+	}
+	pathMappings, createdArtifacts, err := s.Impl.Transform(input.NewArtifacts, input.AlreadySeenArtifacts)
+	if err != nil {
+		return err
+	}
+	outputBytes, err := json.Marshal(transformertypes.TransformOutput{PathMappings: pathMappings, CreatedArtifacts: createdArtifacts})
+	if err != nil {
+		return fmt.Errorf("failed to marshal the transform output to json. Error: %w", err)
+	}
+	*resp = outputBytes
+	return nil
+}
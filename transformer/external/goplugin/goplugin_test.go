@@ -0,0 +1,93 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package goplugin_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/go-plugin"
+	"github.com/konveyor/move2kube/transformer/external/goplugin"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/konveyor/move2kube/types/transformer/artifacts"
+)
+
+type testTransformer struct {
+	projectName string
+}
+
+func (t *testTransformer) Init(input goplugin.InitInput) error {
+	t.projectName = input.ProjectName
+	return nil
+}
+
+func (t *testTransformer) DirectoryDetect(dir string) (map[string][]transformertypes.Artifact, error) {
+	return map[string][]transformertypes.Artifact{
+		t.projectName: {{
+			Name:  t.projectName,
+			Type:  artifacts.ServiceArtifactType,
+			Paths: map[transformertypes.PathType][]string{artifacts.ServiceDirPathType: {dir}},
+		}},
+	}, nil
+}
+
+func (t *testTransformer) Transform(newArtifacts []transformertypes.Artifact, alreadySeenArtifacts []transformertypes.Artifact) ([]transformertypes.PathMapping, []transformertypes.Artifact, error) {
+	if len(newArtifacts) == 0 {
+		return nil, nil, fmt.Errorf("no artifacts to transform")
+	}
+	pathMappings := []transformertypes.PathMapping{{Type: transformertypes.DefaultPathMappingType, SrcPath: "src", DestPath: "dest"}}
+	return pathMappings, []transformertypes.Artifact{{Name: newArtifacts[0].Name, Configs: map[transformertypes.ConfigType]interface{}{"key": "value"}}}, nil
+}
+
+func TestTransformerPlugin(t *testing.T) {
+	client, _ := plugin.TestPluginRPCConn(t, map[string]plugin.Plugin{goplugin.PluginName: &goplugin.TransformerPlugin{Impl: &testTransformer{}}}, nil)
+	defer client.Close()
+	raw, err := client.Dispense(goplugin.PluginName)
+	if err != nil {
+		t.Fatalf("failed to dispense the plugin. Error: %q", err)
+	}
+	transformer := raw.(goplugin.Transformer)
+	if err := transformer.Init(goplugin.InitInput{ProjectName: "myproject"}); err != nil {
+		t.Fatalf("failed to initialize the plugin. Error: %q", err)
+	}
+	services, err := transformer.DirectoryDetect("/foo")
+	if err != nil {
+		t.Fatalf("failed to detect. Error: %q", err)
+	}
+	wantServices := map[string][]transformertypes.Artifact{"myproject": {{
+		Name:  "myproject",
+		Type:  artifacts.ServiceArtifactType,
+		Paths: map[transformertypes.PathType][]string{artifacts.ServiceDirPathType: {"/foo"}},
+	}}}
+	if diff := cmp.Diff(wantServices, services); diff != "" {
+		t.Fatalf("the detected services are different. Differences:\n%s", diff)
+	}
+	pathMappings, createdArtifacts, err := transformer.Transform(services["myproject"], nil)
+	if err != nil {
+		t.Fatalf("failed to transform. Error: %q", err)
+	}
+	if len(pathMappings) != 1 || pathMappings[0].DestPath != "dest" {
+		t.Fatalf("unexpected path mappings: %+v", pathMappings)
+	}
+	if len(createdArtifacts) != 1 || createdArtifacts[0].Configs["key"] != "value" {
+		t.Fatalf("unexpected artifacts: %+v", createdArtifacts)
+	}
+	if _, _, err := transformer.Transform(nil, nil); err == nil {
+		t.Fatalf("expected the error from the plugin to be returned")
+	}
+}
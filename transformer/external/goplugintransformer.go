@@ -0,0 +1,163 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package external
+
+import (
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"sync"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-plugin"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	"github.com/konveyor/move2kube/transformer/external/goplugin"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// goPluginLogWritersMutex guards goPluginLogWriters
+	goPluginLogWritersMutex sync.Mutex
+	// goPluginLogWriters are the writers of the logs of the running plugins, which are closed after the plugins are stopped
+	goPluginLogWriters []*io.PipeWriter
+)
+
+// GoPlugin implements transformer interface and runs a compiled transformer plugin over go-plugin RPC
+type GoPlugin struct {
+	Config       transformertypes.Transformer
+	Env          *environment.Environment
+	PluginConfig *GoPluginYamlConfig
+
+	client    *plugin.Client
+	impl      goplugin.Transformer
+	logWriter *io.PipeWriter
+}
+
+// GoPluginYamlConfig is the format of go plugin yaml config
+type GoPluginYamlConfig struct {
+	// Path is the path of the plugin binary, relative to the transformer directory
+	Path string   `yaml:"path"`
+	Args []string `yaml:"args,omitempty"`
+}
+
+// Init Initializes the transformer
+func (t *GoPlugin) Init(tc transformertypes.Transformer, env *environment.Environment) error {
+	t.Config = tc
+	t.Env = env
+	t.PluginConfig = &GoPluginYamlConfig{}
+	if err := common.GetObjFromInterface(t.Config.Spec.Config, t.PluginConfig); err != nil {
+		return fmt.Errorf("unable to load config for Transformer %+v into %T . Error: %w", t.Config.Spec.Config, t.PluginConfig, err)
+	}
+	if t.PluginConfig.Path == "" {
+		return fmt.Errorf("no plugin path specified for the transformer '%s'", tc.Name)
+	}
+	if common.DisableLocalExecution {
+		return fmt.Errorf("unable to start the plugin for the transformer '%s' . Local execution prevented by %s flag", tc.Name, common.DisableLocalExecutionFlag)
+	}
+	pluginPath := t.PluginConfig.Path
+	if !filepath.IsAbs(pluginPath) {
+		pluginPath = filepath.Join(env.GetEnvironmentContext(), pluginPath)
+	}
+	// the writer runs a goroutine copying the logs to logrus until it is closed
+	t.logWriter = logrus.StandardLogger().WriterLevel(logrus.DebugLevel)
+	t.client = plugin.NewClient(&plugin.ClientConfig{
+		HandshakeConfig:  goplugin.Handshake,
+		Plugins:          goplugin.PluginMap,
+		Cmd:              exec.Command(pluginPath, t.PluginConfig.Args...),
+		AllowedProtocols: []plugin.Protocol{plugin.ProtocolNetRPC},
+		Managed:          true,
+		Logger: hclog.New(&hclog.LoggerOptions{
+			Name:   tc.Name,
+			Output: t.logWriter,
+			Level:  hclog.Debug,
+		}),
+	})
+	rpcClient, err := t.client.Client()
+	if err != nil {
+		t.kill()
+		return fmt.Errorf("failed to start the plugin at path '%s' . Error: %w", pluginPath, err)
+	}
+	raw, err := rpcClient.Dispense(goplugin.PluginName)
+	if err != nil {
+		t.kill()
+		return fmt.Errorf("failed to dispense the transformer from the plugin at path '%s' . Error: %w", pluginPath, err)
+	}
+	impl, ok := raw.(goplugin.Transformer)
+	if !ok {
+		t.kill()
+		return fmt.Errorf("the plugin at path '%s' does not implement a transformer. Actual type %T", pluginPath, raw)
+	}
+	t.impl = impl
+	if err := t.impl.Init(goplugin.InitInput{
+		Config:      tc,
+		ProjectName: env.ProjectName,
+		SourceDir:   env.GetEnvironmentSource(),
+		OutputDir:   env.GetEnvironmentOutput(),
+		ContextDir:  env.GetEnvironmentContext(),
+		TempDir:     env.TempPath,
+	}); err != nil {
+		t.kill()
+		return fmt.Errorf("failed to initialize the plugin at path '%s' . Error: %w", pluginPath, err)
+	}
+	goPluginLogWritersMutex.Lock()
+	goPluginLogWriters = append(goPluginLogWriters, t.logWriter)
+	goPluginLogWritersMutex.Unlock()
+	return nil
+}
+
+// GetConfig returns the transformer config
+func (t *GoPlugin) GetConfig() (transformertypes.Transformer, *environment.Environment) {
+	return t.Config, t.Env
+}
+
+// DirectoryDetect runs detect in each sub directory
+func (t *GoPlugin) DirectoryDetect(dir string) (map[string][]transformertypes.Artifact, error) {
+	services, err := t.impl.DirectoryDetect(dir)
+	if err != nil {
+		return nil, fmt.Errorf("the plugin of the transformer '%s' failed to detect the directory '%s' . Error: %w", t.Config.Name, dir, err)
+	}
+	return services, nil
+}
+
+// Transform transforms the artifacts
+func (t *GoPlugin) Transform(newArtifacts []transformertypes.Artifact, alreadySeenArtifacts []transformertypes.Artifact) ([]transformertypes.PathMapping, []transformertypes.Artifact, error) {
+	pathMappings, createdArtifacts, err := t.impl.Transform(newArtifacts, alreadySeenArtifacts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("the plugin of the transformer '%s' failed to transform. Error: %w", t.Config.Name, err)
+	}
+	return pathMappings, createdArtifacts, nil
+}
+
+// CleanupGoPlugins stops all the running plugin processes and closes the writers of their logs
+func CleanupGoPlugins() {
+	plugin.CleanupClients()
+	goPluginLogWritersMutex.Lock()
+	defer goPluginLogWritersMutex.Unlock()
+	for _, logWriter := range goPluginLogWriters {
+		logWriter.Close()
+	}
+	goPluginLogWriters = nil
+}
+
+// kill stops the plugin process and closes the writer of its logs
+func (t *GoPlugin) kill() {
+	t.client.Kill()
+	t.logWriter.Close()
+}
@@ -0,0 +1,60 @@
+//go:build !wasm
+// +build !wasm
+
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package external
+
+import (
+	"errors"
+	"io"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	environmenttypes "github.com/konveyor/move2kube/types/environment"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+)
+
+func TestGoPluginClosesTheLogWriter(t *testing.T) {
+	oldTempPath := common.TempPath
+	common.TempPath = t.TempDir()
+	defer func() { common.TempPath = oldTempPath }()
+	env, err := environment.NewEnvironment(environment.EnvInfo{
+		Name:              "goplugin",
+		Source:            t.TempDir(),
+		Output:            t.TempDir(),
+		Context:           t.TempDir(),
+		EnvPlatformConfig: environmenttypes.EnvPlatformConfig{Platforms: []string{runtime.GOOS}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("failed to create the environment. Error: %q", err)
+	}
+	defer env.Destroy()
+	tc := transformertypes.NewTransformer()
+	tc.Name = "goplugin"
+	tc.Spec.Config = map[string]interface{}{"path": filepath.Join(t.TempDir(), "missing-plugin")}
+	transformer := &GoPlugin{}
+	if err := transformer.Init(tc, env); err == nil {
+		t.Fatalf("expected an error for a missing plugin")
+	}
+	if _, err := transformer.logWriter.Write([]byte("log line\n")); !errors.Is(err, io.ErrClosedPipe) {
+		t.Fatalf("expected the log writer to be closed after the plugin was killed. Actual: %v", err)
+	}
+}
@@ -0,0 +1,233 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package external
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	starutil "github.com/qri-io/starlib/util"
+	"github.com/sirupsen/logrus"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// fs package
+	fsReadYamlFnName  = "read_yaml"
+	fsWriteYamlFnName = "write_yaml"
+	// template package
+	evalTemplateFile = "eval_template_file"
+	// http package
+	httpGetFnName = "get"
+
+	httpTimeout          = 30 * time.Second
+	httpMaxResponseBytes = 10 * 1024 * 1024
+	httpMaxRedirects     = 10
+)
+
+func (t *Starlark) addHTTPModules() {
+	t.StarGlobals["http"] = &starlarkstruct.Module{
+		Name: "http",
+		Members: starlark.StringDict{
+			httpGetFnName: t.getStarlarkHTTPGet(),
+		},
+	}
+}
+
+func (t *Starlark) getStarlarkFSReadYaml() *starlark.Builtin {
+	return starlark.NewBuiltin(fsReadYamlFnName, func(_ *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var path string
+		if err := starlark.UnpackPositionalArgs(fsReadYamlFnName, args, kwargs, 1, &path); err != nil {
+			return starlark.None, fmt.Errorf("invalid args provided to '%s'. Error: %w", fsReadYamlFnName, err)
+		}
+		if !t.Env.IsPathValid(path) {
+			return starlark.None, fmt.Errorf("the path '%s' is invalid", path)
+		}
+		yamlBytes, err := os.ReadFile(path)
+		if err != nil {
+			return starlark.None, fmt.Errorf("failed to read the file at path '%s' . Error: %w", path, err)
+		}
+		docs := []interface{}{}
+		decoder := yaml.NewDecoder(bytes.NewReader(yamlBytes))
+		for {
+			var doc interface{}
+			if err := decoder.Decode(&doc); err != nil {
+				if err == io.EOF {
+					break
+				}
+				return starlark.None, fmt.Errorf("failed to parse the file at path '%s' as yaml. Error: %w", path, err)
+			}
+			if doc != nil {
+				docs = append(docs, doc)
+			}
+		}
+		return starutil.Marshal(docs)
+	})
+}
+
+func (t *Starlark) getStarlarkFSWriteYaml() *starlark.Builtin {
+	return starlark.NewBuiltin(fsWriteYamlFnName, func(_ *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var path string
+		var data starlark.Value
+		var multiDoc bool
+		var permissions = common.DefaultFilePermission
+		if err := starlark.UnpackArgs(fsWriteYamlFnName, args, kwargs, "filepath", &path, "data", &data, "multi_doc?", &multiDoc, "perm?", &permissions); err != nil {
+			return starlark.None, fmt.Errorf("invalid args provided to '%s'. Error: %w", fsWriteYamlFnName, err)
+		}
+		if !t.Env.IsPathValid(path) {
+			return starlark.None, fmt.Errorf("the path '%s' is invalid", path)
+		}
+		obj, err := starutil.Unmarshal(data)
+		if err != nil {
+			return starlark.None, fmt.Errorf("failed to unmarshal the starlark value %s . Error: %w", data, err)
+		}
+		docs := []interface{}{obj}
+		if multiDoc {
+			objs, ok := obj.([]interface{})
+			if !ok {
+				return starlark.None, fmt.Errorf("expected a list of documents when multi_doc is true. Actual type %T", obj)
+			}
+			docs = objs
+		}
+		buf := &bytes.Buffer{}
+		encoder := yaml.NewEncoder(buf)
+		encoder.SetIndent(2)
+		for _, doc := range docs {
+			if err := encoder.Encode(doc); err != nil {
+				return starlark.None, fmt.Errorf("failed to encode the data as yaml. Error: %w", err)
+			}
+		}
+		if err := encoder.Close(); err != nil {
+			return starlark.None, fmt.Errorf("failed to encode the data as yaml. Error: %w", err)
+		}
+		if err := os.WriteFile(path, buf.Bytes(), os.FileMode(permissions)); err != nil {
+			return starlark.None, fmt.Errorf("failed to write the yaml to the file at path '%s' . Error: %w", path, err)
+		}
+		return starlark.MakeInt(buf.Len()), nil
+	})
+}
+
+func (t *Starlark) getStarlarkEvalTemplateFile() *starlark.Builtin {
+	evalTemplateFn := t.getStarlarkEvalTemplate()
+	return starlark.NewBuiltin(evalTemplateFile, func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var path string
+		data := starlark.NewDict(16)
+		if err := starlark.UnpackPositionalArgs(evalTemplateFile, args, kwargs, 2, &path, &data); err != nil {
+			return starlark.None, fmt.Errorf("failed to unpack the positional arguments. Error: %w", err)
+		}
+		if !t.Env.IsPathValid(path) {
+			return starlark.None, fmt.Errorf("the path '%s' is invalid", path)
+		}
+		templateBytes, err := os.ReadFile(path)
+		if err != nil {
+			return starlark.None, fmt.Errorf("failed to read the template file at path '%s' . Error: %w", path, err)
+		}
+		return starlark.Call(thread, evalTemplateFn, starlark.Tuple{starlark.String(templateBytes), data}, nil)
+	})
+}
+
+// getStarlarkHTTPGet returns a function that fetches a url.
+// The host, and the host of every redirect, must be listed in the transformer config and the user must consent to the access.
+func (t *Starlark) getStarlarkHTTPGet() *starlark.Builtin {
+	return starlark.NewBuiltin(httpGetFnName, func(_ *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var rawURL string
+		headers := starlark.NewDict(0)
+		if err := starlark.UnpackArgs(httpGetFnName, args, kwargs, "url", &rawURL, "headers?", &headers); err != nil {
+			return starlark.None, fmt.Errorf("invalid args provided to '%s'. Error: %w", httpGetFnName, err)
+		}
+		parsedURL, err := url.Parse(rawURL)
+		if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") {
+			return starlark.None, fmt.Errorf("the url '%s' is not a valid http(s) url", rawURL)
+		}
+		if err := t.checkNetworkAccess(parsedURL.Hostname()); err != nil {
+			return starlark.None, err
+		}
+		req, err := http.NewRequest("GET", rawURL, nil)
+		if err != nil {
+			return starlark.None, fmt.Errorf("failed to create the request for the url '%s' . Error: %w", rawURL, err)
+		}
+		for _, item := range headers.Items() {
+			key, keyOk := starlark.AsString(item[0])
+			value, valueOk := starlark.AsString(item[1])
+			if !keyOk || !valueOk {
+				return starlark.None, fmt.Errorf("the headers should be a dict of strings. Actual: %s", headers)
+			}
+			req.Header.Set(key, value)
+		}
+		client := &http.Client{
+			Timeout: httpTimeout,
+			// every redirect is checked as well, so that an allowed host can not forward the request to another host
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) >= httpMaxRedirects {
+					return fmt.Errorf("stopped after %d redirects", httpMaxRedirects)
+				}
+				if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+					return fmt.Errorf("the redirect url '%s' is not a valid http(s) url", req.URL)
+				}
+				return t.checkNetworkAccess(req.URL.Hostname())
+			},
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return starlark.None, fmt.Errorf("failed to fetch the url '%s' . Error: %w", rawURL, err)
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(io.LimitReader(resp.Body, httpMaxResponseBytes))
+		if err != nil {
+			return starlark.None, fmt.Errorf("failed to read the response from the url '%s' . Error: %w", rawURL, err)
+		}
+		return starutil.Marshal(map[string]interface{}{
+			"status_code": resp.StatusCode,
+			"body":        string(body),
+		})
+	})
+}
+
+// checkNetworkAccess returns an error unless the host is allowed by the transformer config and the user
+func (t *Starlark) checkNetworkAccess(host string) error {
+	if !common.IsPresent(t.StarConfig.AllowedHosts, host) {
+		return fmt.Errorf("the transformer '%s' is not allowed to access the host '%s' . Add it to the allowedHosts in the transformer config", t.Config.Name, host)
+	}
+	if allowed, ok := t.consentedHosts[host]; ok {
+		if !allowed {
+			return fmt.Errorf("access to the host '%s' was denied for the transformer '%s'", host, t.Config.Name)
+		}
+		return nil
+	}
+	allowed := qaengine.FetchBoolAnswer(
+		common.JoinQASubKeys(common.ConfigTransformersKey, `"`+t.Config.Name+`"`, "network", `"`+host+`"`, "allow"),
+		fmt.Sprintf("Allow the transformer '%s' to access the network host '%s'?", t.Config.Name, host),
+		[]string{"The transformer will be able to fetch data from this host."},
+		false,
+		nil,
+	)
+	t.consentedHosts[host] = allowed
+	if !allowed {
+		return fmt.Errorf("access to the host '%s' was denied for the transformer '%s'", host, t.Config.Name)
+	}
+	logrus.Debugf("the transformer '%s' was allowed to access the host '%s'", t.Config.Name, host)
+	return nil
+}
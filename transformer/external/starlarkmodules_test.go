@@ -0,0 +1,110 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package external
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/konveyor/move2kube/types"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"go.starlark.net/starlark"
+)
+
+func getTestHTTPGetTransformer(allowedHosts []string, consentedHosts map[string]bool) *Starlark {
+	return &Starlark{
+		Config:         transformertypes.Transformer{ObjectMeta: types.ObjectMeta{Name: "test-starlark"}},
+		StarConfig:     &StarYamlConfig{AllowedHosts: allowedHosts},
+		StarThread:     &starlark.Thread{Name: "test-starlark"},
+		consentedHosts: consentedHosts,
+	}
+}
+
+func callHTTPGet(t *Starlark, rawURL string) (starlark.Value, error) {
+	return starlark.Call(t.StarThread, t.getStarlarkHTTPGet(), starlark.Tuple{starlark.String(rawURL)}, nil)
+}
+
+func TestStarlarkHTTPGet(t *testing.T) {
+	redirectTarget := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "redirected")
+	}))
+	defer redirectTarget.Close()
+	redirectTargetURL, err := url.Parse(redirectTarget.URL)
+	if err != nil {
+		t.Fatalf("failed to parse the url of the redirect target. Error: %q", err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/same-host":
+			http.Redirect(w, r, "/data", http.StatusFound)
+		case "/other-host":
+			// the same server, but with a host name which is not in the allowed hosts
+			http.Redirect(w, r, "http://localhost:"+redirectTargetURL.Port()+"/", http.StatusFound)
+		default:
+			fmt.Fprint(w, "data")
+		}
+	}))
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("failed to parse the url of the server. Error: %q", err)
+	}
+	allowedHost := serverURL.Hostname()
+
+	t.Run("allowed host", func(t *testing.T) {
+		st := getTestHTTPGetTransformer([]string{allowedHost}, map[string]bool{allowedHost: true})
+		resp, err := callHTTPGet(st, server.URL+"/data")
+		if err != nil {
+			t.Fatalf("failed to fetch the url. Error: %q", err)
+		}
+		if !strings.Contains(resp.String(), `"data"`) {
+			t.Fatalf("expected the body of the response. Actual: %s", resp)
+		}
+	})
+	t.Run("host which is not in the allowed hosts", func(t *testing.T) {
+		st := getTestHTTPGetTransformer([]string{"example.com"}, map[string]bool{})
+		if _, err := callHTTPGet(st, server.URL+"/data"); err == nil || !strings.Contains(err.Error(), "not allowed to access the host") {
+			t.Fatalf("expected the host to be rejected. Actual error: %v", err)
+		}
+	})
+	t.Run("host which the user denied access to", func(t *testing.T) {
+		st := getTestHTTPGetTransformer([]string{allowedHost}, map[string]bool{allowedHost: false})
+		if _, err := callHTTPGet(st, server.URL+"/data"); err == nil || !strings.Contains(err.Error(), "was denied") {
+			t.Fatalf("expected the access to be denied. Actual error: %v", err)
+		}
+	})
+	t.Run("redirect to the same host", func(t *testing.T) {
+		st := getTestHTTPGetTransformer([]string{allowedHost}, map[string]bool{allowedHost: true})
+		resp, err := callHTTPGet(st, server.URL+"/same-host")
+		if err != nil {
+			t.Fatalf("failed to fetch the url. Error: %q", err)
+		}
+		if !strings.Contains(resp.String(), `"data"`) {
+			t.Fatalf("expected the body of the redirected response. Actual: %s", resp)
+		}
+	})
+	t.Run("redirect to a host which is not in the allowed hosts", func(t *testing.T) {
+		st := getTestHTTPGetTransformer([]string{allowedHost}, map[string]bool{allowedHost: true, "localhost": true})
+		if _, err := callHTTPGet(st, server.URL+"/other-host"); err == nil || !strings.Contains(err.Error(), "not allowed to access the host 'localhost'") {
+			t.Fatalf("expected the redirect to be rejected. Actual error: %v", err)
+		}
+	})
+}
@@ -95,13 +95,16 @@ type Starlark struct {
 	StarGlobals starlark.StringDict
 	Env         *environment.Environment
 
-	detectFn    *starlark.Function
-	transformFn *starlark.Function
+	detectFn       *starlark.Function
+	transformFn    *starlark.Function
+	consentedHosts map[string]bool
 }
 
 // StarYamlConfig defines yaml config for Starlark transformers
 type StarYamlConfig struct {
 	StarFile string `yaml:"starFile"`
+	// AllowedHosts are the network hosts the http module can access, after the user consents
+	AllowedHosts []string `yaml:"allowedHosts,omitempty"`
 }
 
 // Init Initializes the transformer
@@ -114,6 +117,7 @@ func (t *Starlark) Init(tc transformertypes.Transformer, env *environment.Enviro
 		return fmt.Errorf("failed to load config for Transformer %+v into %T . Error: %w", t.Config.Spec.Config, t.StarConfig, err)
 	}
 	t.StarThread = &starlark.Thread{Name: tc.Name}
+	t.consentedHosts = map[string]bool{}
 	t.setDefaultGlobals()
 	tcmapobj, err := common.GetMapInterfaceFromObj(tc)
 	if err != nil {
@@ -339,6 +343,9 @@ func (t *Starlark) setDefaultGlobals() {
 	t.addAppModules()
 	t.addCryptoModules()
 	t.addArchiveModules()
+	if len(t.StarConfig.AllowedHosts) > 0 {
+		t.addHTTPModules()
+	}
 }
 
 func (t *Starlark) addStarlibModules() {
@@ -379,6 +386,8 @@ func (t *Starlark) addFSModules() {
 			fsRemoveAllFnName:            t.getStarlarkFSRemoveAll(),
 			fsPathRelFnName:              t.getStarlarkFSPathRel(),
 			fsFindXmlPathFnName:          t.getStarlarkFindXmlPath(),
+			fsReadYamlFnName:             t.getStarlarkFSReadYaml(),
+			fsWriteYamlFnName:            t.getStarlarkFSWriteYaml(),
 		},
 	}
 }
@@ -387,7 +396,8 @@ func (t *Starlark) addTemplateModules() {
 	t.StarGlobals["template"] = &starlarkstruct.Module{
 		Name: "template",
 		Members: starlark.StringDict{
-			evalTemplate: t.getStarlarkEvalTemplate(),
+			evalTemplate:     t.getStarlarkEvalTemplate(),
+			evalTemplateFile: t.getStarlarkEvalTemplateFile(),
 		},
 	}
 }
@@ -0,0 +1,378 @@
+//go:build !wasm
+// +build !wasm
+
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package external
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/dchest/uniuri"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/sirupsen/logrus"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"github.com/tetratelabs/wazero/sys"
+)
+
+const (
+	// wasmSourceDir is where the source directory is mounted read-only inside the WASM sandbox
+	wasmSourceDir = "/source"
+	// wasmWorkspaceDir is where the writable workspace directory is mounted inside the WASM sandbox
+	wasmWorkspaceDir    = "/workspace"
+	wasmDetectAction    = "detect"
+	wasmTransformAction = "transform"
+)
+
+var (
+	// wasmTransformersMutex guards wasmTransformers
+	wasmTransformersMutex sync.Mutex
+	// wasmTransformers are the initialized WASM transformers, which are closed by CleanupWASMTransformers
+	wasmTransformers []*WASM
+)
+
+// WASM implements transformer interface and runs transformers compiled to WASM (WASI) in a sandbox.
+// The module gets the request as json on stdin and writes the response as json to stdout.
+// The only files it can access are the source directory (read-only) and a per call workspace directory.
+type WASM struct {
+	Config     transformertypes.Transformer
+	Env        *environment.Environment
+	WASMConfig *WASMYamlConfig
+
+	runtime wazero.Runtime
+	module  wazero.CompiledModule
+	// workspacesMutex guards workspaceDirs
+	workspacesMutex sync.Mutex
+	// workspaceDirs are the workspaces with the files used by the outputs of the module, which are removed after the outputs are processed
+	workspaceDirs []string
+}
+
+// WASMYamlConfig is the format of wasm yaml config
+type WASMYamlConfig struct {
+	// WASMFile is the path of the WASM module, relative to the transformer directory
+	WASMFile string `yaml:"wasmFile"`
+}
+
+// WASMRequest is the request that is passed to the WASM module on stdin
+type WASMRequest struct {
+	Action         string                           `json:"action"`
+	Config         transformertypes.Transformer     `json:"config"`
+	ProjectName    string                           `json:"projectName"`
+	InputDirectory string                           `json:"inputDirectory,omitempty"`
+	TransformInput *transformertypes.TransformInput `json:"transformInput,omitempty"`
+}
+
+// Init Initializes the transformer
+func (t *WASM) Init(tc transformertypes.Transformer, env *environment.Environment) error {
+	t.Config = tc
+	t.Env = env
+	t.WASMConfig = &WASMYamlConfig{}
+	if err := common.GetObjFromInterface(t.Config.Spec.Config, t.WASMConfig); err != nil {
+		return fmt.Errorf("unable to load config for Transformer %+v into %T . Error: %w", t.Config.Spec.Config, t.WASMConfig, err)
+	}
+	if t.WASMConfig.WASMFile == "" {
+		return fmt.Errorf("no wasm file specified for the transformer '%s'", tc.Name)
+	}
+	wasmFilePath := filepath.Join(env.GetEnvironmentContext(), t.WASMConfig.WASMFile)
+	wasmBytes, err := os.ReadFile(wasmFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to read the wasm file at path '%s' . Error: %w", wasmFilePath, err)
+	}
+	ctx := context.Background()
+	// the modules are stopped when the context of the transformation is cancelled
+	t.runtime = wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfig().WithCloseOnContextDone(true))
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, t.runtime); err != nil {
+		t.runtime.Close(ctx)
+		return fmt.Errorf("failed to instantiate WASI for the transformer '%s' . Error: %w", tc.Name, err)
+	}
+	t.module, err = t.runtime.CompileModule(ctx, wasmBytes)
+	if err != nil {
+		t.runtime.Close(ctx)
+		return fmt.Errorf("failed to compile the wasm file at path '%s' . Error: %w", wasmFilePath, err)
+	}
+	wasmTransformersMutex.Lock()
+	wasmTransformers = append(wasmTransformers, t)
+	wasmTransformersMutex.Unlock()
+	return nil
+}
+
+// CleanupWASMTransformers closes the runtimes of the WASM transformers and removes their workspaces
+func CleanupWASMTransformers() {
+	wasmTransformersMutex.Lock()
+	defer wasmTransformersMutex.Unlock()
+	for _, t := range wasmTransformers {
+		t.close()
+	}
+	wasmTransformers = nil
+}
+
+// close closes the compiled module and the runtime, and removes the workspaces
+func (t *WASM) close() {
+	ctx := context.Background()
+	if err := t.module.Close(ctx); err != nil {
+		logrus.Debugf("failed to close the wasm module of the transformer '%s' . Error: %q", t.Config.Name, err)
+	}
+	if err := t.runtime.Close(ctx); err != nil {
+		logrus.Debugf("failed to close the wasm runtime of the transformer '%s' . Error: %q", t.Config.Name, err)
+	}
+	t.workspacesMutex.Lock()
+	defer t.workspacesMutex.Unlock()
+	for _, workspaceDir := range t.workspaceDirs {
+		if err := os.RemoveAll(workspaceDir); err != nil {
+			logrus.Debugf("failed to remove the workspace directory '%s' . Error: %q", workspaceDir, err)
+		}
+	}
+	t.workspaceDirs = nil
+}
+
+// GetConfig returns the transformer config
+func (t *WASM) GetConfig() (transformertypes.Transformer, *environment.Environment) {
+	return t.Config, t.Env
+}
+
+// DirectoryDetect runs detect in each sub directory
+func (t *WASM) DirectoryDetect(dir string) (map[string][]transformertypes.Artifact, error) {
+	workspaceDir, err := t.createWorkspace()
+	if err != nil {
+		return nil, err
+	}
+	pathTranslator := newWASMPathTranslator(t.Env.GetEnvironmentSource(), workspaceDir)
+	guestDir, err := pathTranslator.toGuest(dir)
+	if err != nil {
+		return nil, err
+	}
+	outputPaths := []string{}
+	defer func() { t.releaseWorkspace(workspaceDir, outputPaths) }()
+	services := map[string][]transformertypes.Artifact{}
+	if err := t.run(context.Background(), WASMRequest{Action: wasmDetectAction, InputDirectory: guestDir}, workspaceDir, &services); err != nil {
+		return nil, fmt.Errorf("failed to run the detect of the transformer '%s' on the directory '%s' . Error: %w", t.Config.Name, dir, err)
+	}
+	for serviceName, serviceArtifacts := range services {
+		for i, serviceArtifact := range serviceArtifacts {
+			if serviceArtifacts[i], err = pathTranslator.artifactToHost(serviceArtifact); err != nil {
+				return nil, err
+			}
+			outputPaths = append(outputPaths, getArtifactPaths(serviceArtifacts[i])...)
+		}
+		services[serviceName] = serviceArtifacts
+	}
+	return services, nil
+}
+
+// Transform transforms the artifacts
+func (t *WASM) Transform(newArtifacts []transformertypes.Artifact, alreadySeenArtifacts []transformertypes.Artifact) ([]transformertypes.PathMapping, []transformertypes.Artifact, error) {
+	return t.TransformWithContext(context.Background(), newArtifacts, alreadySeenArtifacts)
+}
+
+// TransformWithContext transforms the artifacts and stops the module when the context is cancelled
+func (t *WASM) TransformWithContext(ctx context.Context, newArtifacts []transformertypes.Artifact, alreadySeenArtifacts []transformertypes.Artifact) ([]transformertypes.PathMapping, []transformertypes.Artifact, error) {
+	workspaceDir, err := t.createWorkspace()
+	if err != nil {
+		return nil, nil, err
+	}
+	outputPaths := []string{}
+	defer func() { t.releaseWorkspace(workspaceDir, outputPaths) }()
+	pathTranslator := newWASMPathTranslator(t.Env.GetEnvironmentSource(), workspaceDir)
+	input := transformertypes.TransformInput{}
+	for _, newArtifact := range newArtifacts {
+		guestArtifact, err := pathTranslator.artifactToGuest(newArtifact)
+		if err != nil {
+			return nil, nil, err
+		}
+		input.NewArtifacts = append(input.NewArtifacts, guestArtifact)
+	}
+	for _, alreadySeenArtifact := range alreadySeenArtifacts {
+		guestArtifact, err := pathTranslator.artifactToGuest(alreadySeenArtifact)
+		if err != nil {
+			logrus.Debugf("skipping the already seen artifact '%s' since it is outside the sandbox. Error: %q", alreadySeenArtifact.Name, err)
+			continue
+		}
+		input.AlreadySeenArtifacts = append(input.AlreadySeenArtifacts, guestArtifact)
+	}
+	output := transformertypes.TransformOutput{}
+	if err := t.run(ctx, WASMRequest{Action: wasmTransformAction, TransformInput: &input}, workspaceDir, &output); err != nil {
+		return nil, nil, fmt.Errorf("failed to run the transform of the transformer '%s' . Error: %w", t.Config.Name, err)
+	}
+	for i, pathMapping := range output.PathMappings {
+		if output.PathMappings[i], err = pathTranslator.pathMappingToHost(pathMapping); err != nil {
+			return nil, nil, err
+		}
+		outputPaths = append(outputPaths, output.PathMappings[i].SrcPath)
+	}
+	for i, createdArtifact := range output.CreatedArtifacts {
+		if output.CreatedArtifacts[i], err = pathTranslator.artifactToHost(createdArtifact); err != nil {
+			return nil, nil, err
+		}
+		outputPaths = append(outputPaths, getArtifactPaths(output.CreatedArtifacts[i])...)
+	}
+	return output.PathMappings, output.CreatedArtifacts, nil
+}
+
+func (t *WASM) createWorkspace() (string, error) {
+	workspaceDir := filepath.Join(t.Env.TempPath, "wasm-"+uniuri.NewLen(5))
+	if err := os.MkdirAll(workspaceDir, common.DefaultDirectoryPermission); err != nil {
+		return "", fmt.Errorf("failed to create the workspace directory for the wasm module. Error: %w", err)
+	}
+	return workspaceDir, nil
+}
+
+// releaseWorkspace removes the workspace, unless the outputs of the module use the files in it.
+// Those workspaces are removed by CleanupWASMTransformers, since the outputs are processed after the module returns.
+func (t *WASM) releaseWorkspace(workspaceDir string, outputPaths []string) {
+	for _, outputPath := range outputPaths {
+		if filepath.IsAbs(outputPath) && common.IsParent(outputPath, workspaceDir) {
+			t.workspacesMutex.Lock()
+			t.workspaceDirs = append(t.workspaceDirs, workspaceDir)
+			t.workspacesMutex.Unlock()
+			return
+		}
+	}
+	if err := os.RemoveAll(workspaceDir); err != nil {
+		logrus.Debugf("failed to remove the workspace directory '%s' . Error: %q", workspaceDir, err)
+	}
+}
+
+// getArtifactPaths returns all the paths of the artifact
+func getArtifactPaths(artifact transformertypes.Artifact) []string {
+	paths := []string{}
+	for _, artifactPaths := range artifact.Paths {
+		paths = append(paths, artifactPaths...)
+	}
+	return paths
+}
+
+// run instantiates the module with the request on stdin and decodes the response from stdout
+func (t *WASM) run(ctx context.Context, req WASMRequest, workspaceDir string, resp interface{}) error {
+	req.Config = t.Config
+	req.ProjectName = t.Env.ProjectName
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("failed to marshal the request to json. Error: %w", err)
+	}
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	fsConfig := wazero.NewFSConfig().WithDirMount(workspaceDir, wasmWorkspaceDir)
+	if source := t.Env.GetEnvironmentSource(); source != "" {
+		fsConfig = fsConfig.WithReadOnlyDirMount(source, wasmSourceDir)
+	}
+	moduleConfig := wazero.NewModuleConfig().
+		WithName("").
+		WithArgs(t.Config.Name).
+		WithStdin(bytes.NewReader(reqBytes)).
+		WithStdout(stdout).
+		WithStderr(stderr).
+		WithFSConfig(fsConfig)
+	mod, err := t.runtime.InstantiateModule(ctx, t.module, moduleConfig)
+	if mod != nil {
+		defer mod.Close(ctx)
+	}
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return fmt.Errorf("the wasm module was stopped. Error: %w", ctxErr)
+		}
+		exitErr := &sys.ExitError{}
+		if !errors.As(err, &exitErr) || exitErr.ExitCode() != 0 {
+			return fmt.Errorf("the wasm module failed.\nstdout: %s\nstderr: %s\nError: %w", stdout.String(), stderr.String(), err)
+		}
+	}
+	logrus.Debugf("the wasm module of the transformer '%s' succeeded.\nstderr: %s", t.Config.Name, stderr.String())
+	if err := json.Unmarshal(stdout.Bytes(), resp); err != nil {
+		return fmt.Errorf("failed to parse the output of the wasm module as json. Output: %s . Error: %w", stdout.String(), err)
+	}
+	return nil
+}
+
+// wasmPathTranslator converts paths between the host and the WASM sandbox
+type wasmPathTranslator struct {
+	mounts map[string]string
+}
+
+func newWASMPathTranslator(sourceDir, workspaceDir string) wasmPathTranslator {
+	mounts := map[string]string{wasmWorkspaceDir: workspaceDir}
+	if sourceDir != "" {
+		mounts[wasmSourceDir] = sourceDir
+	}
+	return wasmPathTranslator{mounts: mounts}
+}
+
+func (p wasmPathTranslator) toGuest(hostPath string) (string, error) {
+	for guestDir, hostDir := range p.mounts {
+		if relPath, err := filepath.Rel(hostDir, hostPath); err == nil && relPath != ".." && !strings.HasPrefix(relPath, ".."+string(os.PathSeparator)) {
+			return filepath.ToSlash(filepath.Join(guestDir, relPath)), nil
+		}
+	}
+	return "", fmt.Errorf("the path '%s' is not accessible inside the wasm sandbox", hostPath)
+}
+
+func (p wasmPathTranslator) toHost(guestPath string) (string, error) {
+	guestPath = filepath.ToSlash(filepath.Clean(guestPath))
+	for guestDir, hostDir := range p.mounts {
+		if guestPath == guestDir || strings.HasPrefix(guestPath, guestDir+"/") {
+			return filepath.Join(hostDir, filepath.FromSlash(strings.TrimPrefix(guestPath, guestDir))), nil
+		}
+	}
+	return "", fmt.Errorf("the path '%s' from the wasm module is outside the sandbox", guestPath)
+}
+
+func (p wasmPathTranslator) artifactToGuest(artifact transformertypes.Artifact) (transformertypes.Artifact, error) {
+	return p.convertArtifactPaths(artifact, p.toGuest)
+}
+
+func (p wasmPathTranslator) artifactToHost(artifact transformertypes.Artifact) (transformertypes.Artifact, error) {
+	return p.convertArtifactPaths(artifact, p.toHost)
+}
+
+func (p wasmPathTranslator) convertArtifactPaths(artifact transformertypes.Artifact, convert func(string) (string, error)) (transformertypes.Artifact, error) {
+	if len(artifact.Paths) == 0 {
+		return artifact, nil
+	}
+	paths := map[transformertypes.PathType][]string{}
+	for pathType, artifactPaths := range artifact.Paths {
+		for _, artifactPath := range artifactPaths {
+			convertedPath, err := convert(artifactPath)
+			if err != nil {
+				return artifact, fmt.Errorf("failed to convert the paths of the artifact '%s' . Error: %w", artifact.Name, err)
+			}
+			paths[pathType] = append(paths[pathType], convertedPath)
+		}
+	}
+	artifact.Paths = paths
+	return artifact, nil
+}
+
+// pathMappingToHost converts the source path of the path mapping. The destination path is relative to the output directory.
+func (p wasmPathTranslator) pathMappingToHost(pathMapping transformertypes.PathMapping) (transformertypes.PathMapping, error) {
+	if pathMapping.SrcPath == "" || !strings.HasPrefix(pathMapping.SrcPath, "/") {
+		return pathMapping, nil
+	}
+	srcPath, err := p.toHost(pathMapping.SrcPath)
+	if err != nil {
+		return pathMapping, err
+	}
+	pathMapping.SrcPath = srcPath
+	return pathMapping, nil
+}
@@ -0,0 +1,178 @@
+//go:build !wasm
+// +build !wasm
+
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package external
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	environmenttypes "github.com/konveyor/move2kube/types/environment"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/konveyor/move2kube/types/transformer/artifacts"
+)
+
+var (
+	// wasmLoopModule is a module whose _start function loops forever
+	wasmLoopModule = []byte{
+		0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00, // magic and version
+		0x01, 0x04, 0x01, 0x60, 0x00, 0x00, // type section: func() with no params and results
+		0x03, 0x02, 0x01, 0x00, // function section: one function of type 0
+		0x07, 0x0a, 0x01, 0x06, '_', 's', 't', 'a', 'r', 't', 0x00, 0x00, // export section: _start
+		0x0a, 0x09, 0x01, 0x07, 0x00, 0x03, 0x40, 0x0c, 0x00, 0x0b, 0x0b, // code section: loop br 0 end end
+	}
+	// wasmEmptyModule is a module whose _start function returns without writing a response
+	wasmEmptyModule = []byte{
+		0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00,
+		0x01, 0x04, 0x01, 0x60, 0x00, 0x00,
+		0x03, 0x02, 0x01, 0x00,
+		0x07, 0x0a, 0x01, 0x06, '_', 's', 't', 'a', 'r', 't', 0x00, 0x00,
+		0x0a, 0x04, 0x01, 0x02, 0x00, 0x0b, // code section: end
+	}
+)
+
+func getTestWASMTransformer(t *testing.T, module []byte) *WASM {
+	t.Helper()
+	contextDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(contextDir, "transformer.wasm"), module, common.DefaultFilePermission); err != nil {
+		t.Fatalf("failed to write the wasm module. Error: %q", err)
+	}
+	env, err := environment.NewEnvironment(environment.EnvInfo{
+		Name:              "wasm",
+		Source:            t.TempDir(),
+		Output:            t.TempDir(),
+		Context:           contextDir,
+		EnvPlatformConfig: environmenttypes.EnvPlatformConfig{Platforms: []string{runtime.GOOS}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("failed to create the environment. Error: %q", err)
+	}
+	t.Cleanup(func() { env.Destroy() })
+	tc := transformertypes.NewTransformer()
+	tc.Name = "wasm"
+	tc.Spec.Config = map[string]interface{}{"wasmFile": "transformer.wasm"}
+	transformer := &WASM{}
+	if err := transformer.Init(tc, env); err != nil {
+		t.Fatalf("failed to initialize the transformer. Error: %q", err)
+	}
+	return transformer
+}
+
+func TestWASMTransform(t *testing.T) {
+	oldTempPath := common.TempPath
+	common.TempPath = t.TempDir()
+	defer func() {
+		CleanupWASMTransformers()
+		common.TempPath = oldTempPath
+	}()
+	getWorkspaces := func(transformer *WASM) []string {
+		workspaceDirs, err := filepath.Glob(filepath.Join(transformer.Env.TempPath, "wasm-*"))
+		if err != nil {
+			t.Fatalf("failed to list the workspaces. Error: %q", err)
+		}
+		return workspaceDirs
+	}
+	t.Run("stop the module when the context is cancelled", func(t *testing.T) {
+		transformer := getTestWASMTransformer(t, wasmLoopModule)
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		if _, _, err := transformer.TransformWithContext(ctx, nil, nil); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected the transform to be stopped. Actual: %v", err)
+		}
+		if workspaceDirs := getWorkspaces(transformer); len(workspaceDirs) != 0 {
+			t.Fatalf("expected the workspace to be removed. Actual: %+v", workspaceDirs)
+		}
+	})
+	t.Run("remove the workspace when the module fails", func(t *testing.T) {
+		transformer := getTestWASMTransformer(t, wasmEmptyModule)
+		if _, err := transformer.DirectoryDetect(transformer.Env.GetEnvironmentSource()); err == nil {
+			t.Fatalf("expected an error for a module without a response")
+		}
+		if workspaceDirs := getWorkspaces(transformer); len(workspaceDirs) != 0 {
+			t.Fatalf("expected the workspace to be removed. Actual: %+v", workspaceDirs)
+		}
+	})
+	t.Run("keep the workspaces used by the outputs until the cleanup", func(t *testing.T) {
+		transformer := getTestWASMTransformer(t, wasmEmptyModule)
+		workspaceDir, err := transformer.createWorkspace()
+		if err != nil {
+			t.Fatalf("failed to create the workspace. Error: %q", err)
+		}
+		transformer.releaseWorkspace(workspaceDir, []string{filepath.Join(workspaceDir, "deploy.yaml")})
+		if _, err := os.Stat(workspaceDir); err != nil {
+			t.Fatalf("expected the workspace used by the outputs to be kept. Error: %q", err)
+		}
+		CleanupWASMTransformers()
+		if _, err := os.Stat(workspaceDir); !os.IsNotExist(err) {
+			t.Fatalf("expected the workspace to be removed by the cleanup. Error: %v", err)
+		}
+	})
+}
+
+func TestWASMPathTranslator(t *testing.T) {
+	p := newWASMPathTranslator("/home/user/src", "/tmp/m2k-temp/workspace")
+	t.Run("host paths inside the mounts", func(t *testing.T) {
+		artifact := transformertypes.Artifact{Name: "svc1", Paths: map[transformertypes.PathType][]string{artifacts.ServiceDirPathType: {"/home/user/src/svc1"}}}
+		guestArtifact, err := p.artifactToGuest(artifact)
+		if err != nil {
+			t.Fatalf("failed to convert the artifact paths. Error: %q", err)
+		}
+		want := map[transformertypes.PathType][]string{artifacts.ServiceDirPathType: {"/source/svc1"}}
+		if diff := cmp.Diff(want, guestArtifact.Paths); diff != "" {
+			t.Fatalf("the guest paths are different. Differences:\n%s", diff)
+		}
+		hostArtifact, err := p.artifactToHost(guestArtifact)
+		if err != nil {
+			t.Fatalf("failed to convert the artifact paths back. Error: %q", err)
+		}
+		if diff := cmp.Diff(artifact, hostArtifact); diff != "" {
+			t.Fatalf("the host paths are different. Differences:\n%s", diff)
+		}
+	})
+	t.Run("paths outside the mounts", func(t *testing.T) {
+		if _, err := p.toGuest("/home/user/src-other"); err == nil {
+			t.Fatalf("expected an error for a host path outside the mounts")
+		}
+		if _, err := p.toHost("/source/../etc/passwd"); err == nil {
+			t.Fatalf("expected an error for a guest path outside the mounts")
+		}
+	})
+	t.Run("path mappings", func(t *testing.T) {
+		pathMapping, err := p.pathMappingToHost(transformertypes.PathMapping{SrcPath: "/workspace/deploy.yaml", DestPath: "deploy/deploy.yaml"})
+		if err != nil {
+			t.Fatalf("failed to convert the path mapping. Error: %q", err)
+		}
+		want := transformertypes.PathMapping{SrcPath: "/tmp/m2k-temp/workspace/deploy.yaml", DestPath: "deploy/deploy.yaml"}
+		if diff := cmp.Diff(want, pathMapping); diff != "" {
+			t.Fatalf("the path mapping is different. Differences:\n%s", diff)
+		}
+		templatePathMapping := transformertypes.PathMapping{Type: transformertypes.TemplatePathMappingType, SrcPath: "Dockerfile", DestPath: "Dockerfile"}
+		if pathMapping, err := p.pathMappingToHost(templatePathMapping); err != nil || pathMapping != templatePathMapping {
+			t.Fatalf("expected relative template paths to be left unchanged. Actual: %+v Error: %v", pathMapping, err)
+		}
+	})
+}
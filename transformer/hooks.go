@@ -0,0 +1,105 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"sync"
+
+	"github.com/konveyor/move2kube/common"
+	plantypes "github.com/konveyor/move2kube/types/plan"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/sirupsen/logrus"
+)
+
+// ServiceDetectedHook is called once for every named service found during planning
+type ServiceDetectedHook func(serviceName string, planArtifacts []plantypes.PlanArtifact)
+
+// ArtifactGeneratedHook is called for every artifact produced by a transformer.
+// The hook can return a modified artifact. Returning false drops the artifact
+// so that it is not consumed by any other transformer.
+// The hooks are called one at a time, even when the transformers run in parallel.
+type ArtifactGeneratedHook func(transformerName string, artifact transformertypes.Artifact) (transformertypes.Artifact, bool)
+
+// TransformDoneHook is called when the transformation finishes, with the error if it failed
+type TransformDoneHook func(outputPath string, err error)
+
+var (
+	serviceDetectedHooks   []ServiceDetectedHook
+	artifactGeneratedHooks []ArtifactGeneratedHook
+	transformDoneHooks     []TransformDoneHook
+	// artifactGeneratedHooksMutex serializes the hooks of the transformers that run in parallel
+	artifactGeneratedHooksMutex sync.Mutex
+)
+
+// AddServiceDetectedHook registers a hook that is called for every detected service
+func AddServiceDetectedHook(hook ServiceDetectedHook) {
+	serviceDetectedHooks = append(serviceDetectedHooks, hook)
+}
+
+// AddArtifactGeneratedHook registers a hook that is called for every generated artifact
+func AddArtifactGeneratedHook(hook ArtifactGeneratedHook) {
+	artifactGeneratedHooks = append(artifactGeneratedHooks, hook)
+}
+
+// AddTransformDoneHook registers a hook that is called at the end of the transformation
+func AddTransformDoneHook(hook TransformDoneHook) {
+	transformDoneHooks = append(transformDoneHooks, hook)
+}
+
+// ResetHooks removes all the registered hooks
+func ResetHooks() {
+	serviceDetectedHooks = nil
+	artifactGeneratedHooks = nil
+	transformDoneHooks = nil
+}
+
+func runServiceDetectedHooks(planServices map[string][]plantypes.PlanArtifact) {
+	for _, serviceName := range common.GetSortedServiceNames(planServices) {
+		for _, hook := range serviceDetectedHooks {
+			hook(serviceName, planServices[serviceName])
+		}
+	}
+}
+
+func runArtifactGeneratedHooks(transformerName string, newArtifacts []transformertypes.Artifact) []transformertypes.Artifact {
+	if len(artifactGeneratedHooks) == 0 {
+		return newArtifacts
+	}
+	artifactGeneratedHooksMutex.Lock()
+	defer artifactGeneratedHooksMutex.Unlock()
+	keptArtifacts := []transformertypes.Artifact{}
+	for _, newArtifact := range newArtifacts {
+		keep := true
+		for _, hook := range artifactGeneratedHooks {
+			if newArtifact, keep = hook(transformerName, newArtifact); !keep {
+				break
+			}
+		}
+		if !keep {
+			logrus.Infof("The artifact with name '%s' of type '%s' from the transformer '%s' was vetoed by a hook", newArtifact.Name, newArtifact.Type, transformerName)
+			continue
+		}
+		keptArtifacts = append(keptArtifacts, newArtifact)
+	}
+	return keptArtifacts
+}
+
+func runTransformDoneHooks(outputPath string, err error) {
+	for _, hook := range transformDoneHooks {
+		hook(outputPath, err)
+	}
+}
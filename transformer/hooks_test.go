@@ -0,0 +1,65 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	plantypes "github.com/konveyor/move2kube/types/plan"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+)
+
+func TestRunArtifactGeneratedHooks(t *testing.T) {
+	t.Run("no-hooks", func(t *testing.T) {
+		ResetHooks()
+		artifacts := []transformertypes.Artifact{{Name: "svc1"}}
+		if diff := cmp.Diff(artifacts, runArtifactGeneratedHooks("t1", artifacts)); diff != "" {
+			t.Fatalf("the artifacts changed without any hooks. Differences:\n%s", diff)
+		}
+	})
+	t.Run("veto-and-modify", func(t *testing.T) {
+		defer ResetHooks()
+		AddArtifactGeneratedHook(func(transformerName string, artifact transformertypes.Artifact) (transformertypes.Artifact, bool) {
+			return artifact, artifact.Name != "svc2"
+		})
+		AddArtifactGeneratedHook(func(transformerName string, artifact transformertypes.Artifact) (transformertypes.Artifact, bool) {
+			artifact.Type = transformertypes.ArtifactType(transformerName)
+			return artifact, true
+		})
+		artifacts := []transformertypes.Artifact{{Name: "svc1"}, {Name: "svc2"}}
+		want := []transformertypes.Artifact{{Name: "svc1", Type: "t1"}}
+		if diff := cmp.Diff(want, runArtifactGeneratedHooks("t1", artifacts)); diff != "" {
+			t.Fatalf("the hooks were not applied correctly. Differences:\n%s", diff)
+		}
+	})
+}
+
+func TestRunServiceDetectedHooks(t *testing.T) {
+	ResetHooks()
+	defer ResetHooks()
+	serviceNames := []string{}
+	AddServiceDetectedHook(func(serviceName string, planArtifacts []plantypes.PlanArtifact) {
+		serviceNames = append(serviceNames, serviceName)
+	})
+	planServices := map[string][]plantypes.PlanArtifact{"web": nil, "api": nil, "redis": nil, "db": nil}
+	runServiceDetectedHooks(planServices)
+	want := []string{"api", "db", "redis", "web"}
+	if diff := cmp.Diff(want, serviceNames); diff != "" {
+		t.Fatalf("the hooks were not called in the order of the service names. Differences:\n%s", diff)
+	}
+}
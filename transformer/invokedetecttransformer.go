@@ -17,6 +17,8 @@
 package transformer
 
 import (
+	"context"
+
 	"github.com/konveyor/move2kube/common"
 	"github.com/konveyor/move2kube/environment"
 	transformertypes "github.com/konveyor/move2kube/types/transformer"
@@ -55,11 +57,27 @@ func (t *InvokeDetect) Transform(
 	[]transformertypes.PathMapping,
 	[]transformertypes.Artifact,
 	error,
+) {
+	return t.TransformWithContext(context.Background(), inputArtifacts, inputOldArtifacts)
+}
+
+// TransformWithContext transforms the artifacts and stops when the context is cancelled
+func (t *InvokeDetect) TransformWithContext(
+	ctx context.Context,
+	inputArtifacts []transformertypes.Artifact,
+	inputOldArtifacts []transformertypes.Artifact,
+) (
+	[]transformertypes.PathMapping,
+	[]transformertypes.Artifact,
+	error,
 ) {
 	logrus.Trace("InvokeDetect.Transform start")
 	defer logrus.Trace("InvokeDetect.Transform end")
 	outputArtifacts := []transformertypes.Artifact{}
 	for _, inputArtifact := range inputArtifacts {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
 		if len(inputArtifact.Paths[artifacts.InvokeDetectPathType]) == 0 {
 			logrus.Errorf("the path to run the detect function is missing from the InvokeDetect artifact. Skipping")
 			continue
@@ -71,7 +89,11 @@ func (t *InvokeDetect) Transform(
 			logrus.Errorf("failed to load the InvokeDetect type config into struct of type %T . Error: %q", invokeDetectConfig, err)
 			continue
 		}
-		detectedServices, err := GetServices(common.DefaultProjectName, detectDir, &invokeDetectConfig.TransformerSelector)
+		detectedServices, err := GetServices(ctx, common.DefaultProjectName, detectDir, &invokeDetectConfig.TransformerSelector)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			// the services detected before the cancellation are incomplete
+			return nil, nil, ctxErr
+		}
 		if err != nil {
 			logrus.Errorf("failed to invoke the directory detect. Error: %q", err)
 			continue
@@ -0,0 +1,44 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/konveyor/move2kube/types/transformer/artifacts"
+)
+
+func TestInvokeDetectCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	inputArtifacts := []transformertypes.Artifact{{
+		Name:    "detect",
+		Type:    "InvokeDetect",
+		Paths:   map[transformertypes.PathType][]string{artifacts.InvokeDetectPathType: {t.TempDir()}},
+		Configs: map[transformertypes.ConfigType]interface{}{artifacts.InvokeDetectConfigType: artifacts.InvokeDetectConfig{}},
+	}}
+	_, outputArtifacts, err := (&InvokeDetect{}).TransformWithContext(ctx, inputArtifacts, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the cancellation error. Actual: %v", err)
+	}
+	if len(outputArtifacts) != 0 {
+		t.Fatalf("expected no artifacts after the cancellation. Actual: %+v", outputArtifacts)
+	}
+}
@@ -0,0 +1,72 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"sync"
+
+	"github.com/konveyor/move2kube/common"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	loadedIRMutex sync.Mutex
+	loadedIR      = irtypes.NewIR()
+)
+
+// resetLoadedIR clears the IR recorded so far
+func resetLoadedIR() {
+	loadedIRMutex.Lock()
+	defer loadedIRMutex.Unlock()
+	loadedIR = irtypes.NewIR()
+}
+
+// recordLoadedIRs merges the IRs produced by the transformers that load the source artifacts.
+// The IRs produced by the transformers that consume IRs are not recorded since they are derived from the loaded IRs.
+func recordLoadedIRs(tconfig transformertypes.Transformer, newArtifacts []transformertypes.Artifact) {
+	if _, ok := tconfig.Spec.ConsumedArtifacts[irtypes.IRArtifactType]; ok {
+		return
+	}
+	for _, newArtifact := range newArtifacts {
+		if newArtifact.Type != irtypes.IRArtifactType {
+			continue
+		}
+		ir := irtypes.IR{}
+		if err := newArtifact.GetConfig(irtypes.IRConfigType, &ir); err != nil {
+			logrus.Debugf("failed to load config of type '%s' into struct of type %T . Error: %q", irtypes.IRConfigType, ir, err)
+			continue
+		}
+		loadedIRMutex.Lock()
+		if loadedIR.Name == "" {
+			loadedIR.Name = ir.Name
+		}
+		loadedIR.Merge(&ir)
+		loadedIRMutex.Unlock()
+	}
+}
+
+// WriteLoadedIR writes the IR loaded from the source artifacts during the last transformation to the path
+func WriteLoadedIR(path string) error {
+	loadedIRMutex.Lock()
+	defer loadedIRMutex.Unlock()
+	if loadedIR.Name == "" {
+		loadedIR.Name = common.ProjectName
+	}
+	return irtypes.WriteIRDump(path, loadedIR)
+}
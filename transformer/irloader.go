@@ -0,0 +1,138 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"fmt"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	"github.com/konveyor/move2kube/types"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/konveyor/move2kube/types/transformer/artifacts"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// irDumpPathType points to the IR dump written using --dump-ir
+	irDumpPathType transformertypes.PathType = "IRDump"
+	// irDumpConfigType stores the name of the service in the IR dump
+	irDumpConfigType transformertypes.ConfigType = "IRDump"
+)
+
+// IRLoader implements Transformer interface.
+// It loads the IR dumps written using --dump-ir, possibly modified by external tools.
+type IRLoader struct {
+	Config transformertypes.Transformer
+	Env    *environment.Environment
+}
+
+// IRDumpConfig stores the name of the service in the IR dump
+type IRDumpConfig struct {
+	ServiceName string `yaml:"serviceName"`
+}
+
+// Init initializes the transformer
+func (t *IRLoader) Init(tc transformertypes.Transformer, env *environment.Environment) (err error) {
+	t.Config = tc
+	t.Env = env
+	return nil
+}
+
+// GetConfig returns the config of the transformer
+func (t *IRLoader) GetConfig() (transformertypes.Transformer, *environment.Environment) {
+	return t.Config, t.Env
+}
+
+// DirectoryDetect detects the IR dumps in the directory
+func (t *IRLoader) DirectoryDetect(dir string) (map[string][]transformertypes.Artifact, error) {
+	dumpPaths, err := common.GetFilesByExtInCurrDir(dir, []string{".yaml", ".yml", ".json"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the yaml and json files at path '%s' . Error: %w", dir, err)
+	}
+	services := map[string][]transformertypes.Artifact{}
+	for _, dumpPath := range dumpPaths {
+		typeMeta := types.TypeMeta{}
+		if err := common.ReadMove2KubeYaml(dumpPath, &typeMeta); err != nil || typeMeta.Kind != string(irtypes.IRDumpKind) {
+			continue
+		}
+		ir, err := irtypes.ReadIRDump(dumpPath)
+		if err != nil {
+			logrus.Errorf("failed to load the IR dump. Error: %q", err)
+			continue
+		}
+		for _, serviceName := range ir.GetSortedServiceNames() {
+			logrus.Debugf("Found the service '%s' in the IR dump at path '%s'", serviceName, dumpPath)
+			services[serviceName] = append(services[serviceName], transformertypes.Artifact{
+				Configs: map[transformertypes.ConfigType]interface{}{irDumpConfigType: IRDumpConfig{ServiceName: serviceName}},
+				Paths:   map[transformertypes.PathType][]string{irDumpPathType: {dumpPath}},
+			})
+		}
+	}
+	return services, nil
+}
+
+// Transform transforms the artifacts
+func (t *IRLoader) Transform(newArtifacts []transformertypes.Artifact, alreadySeenArtifacts []transformertypes.Artifact) ([]transformertypes.PathMapping, []transformertypes.Artifact, error) {
+	createdArtifacts := []transformertypes.Artifact{}
+	for _, newArtifact := range newArtifacts {
+		config := IRDumpConfig{}
+		if err := newArtifact.GetConfig(irDumpConfigType, &config); err != nil {
+			logrus.Errorf("failed to load config for Transformer into %T . Error: %q", config, err)
+			continue
+		}
+		serviceConfig := artifacts.ServiceConfig{}
+		if err := newArtifact.GetConfig(artifacts.ServiceConfigType, &serviceConfig); err != nil {
+			logrus.Errorf("failed to load config for Transformer into %T . Error: %q", serviceConfig, err)
+			continue
+		}
+		if len(newArtifact.Paths[irDumpPathType]) == 0 {
+			logrus.Errorf("the artifact for the service '%s' does not have the path to the IR dump", config.ServiceName)
+			continue
+		}
+		dumpPath := newArtifact.Paths[irDumpPathType][0]
+		dumpedIR, err := irtypes.ReadIRDump(dumpPath)
+		if err != nil {
+			logrus.Errorf("failed to load the IR dump. Error: %q", err)
+			continue
+		}
+		service, ok := dumpedIR.Services[config.ServiceName]
+		if !ok {
+			logrus.Errorf("the service '%s' was not found in the IR dump at path '%s'", config.ServiceName, dumpPath)
+			continue
+		}
+		ir := irtypes.NewIR()
+		ir.Name = t.Env.GetProjectName()
+		service.Name = serviceConfig.ServiceName
+		ir.Services[service.Name] = service
+		for _, container := range append(service.InitContainers, service.Containers...) {
+			if containerImage, ok := dumpedIR.ContainerImages[container.Image]; ok {
+				ir.AddContainer(container.Image, containerImage)
+			}
+		}
+		for _, storage := range dumpedIR.Storages {
+			ir.AddStorage(storage)
+		}
+		createdArtifacts = append(createdArtifacts, transformertypes.Artifact{
+			Name:    t.Env.GetProjectName(),
+			Type:    irtypes.IRArtifactType,
+			Configs: map[transformertypes.ConfigType]interface{}{irtypes.IRConfigType: ir},
+		})
+	}
+	return nil, createdArtifacts, nil
+}
@@ -178,10 +178,14 @@ func (o *APIResource) getClusterSupportedKinds(cluster collecttypes.ClusterMetad
 	return supportedKinds
 }
 
-func getPodLabels(name string, networks []string) map[string]string {
-	labels := getServiceLabels(name)
-	networklabels := getNetworkPolicyLabels(networks)
-	return common.MergeStringMaps(labels, networklabels)
+// getPodLabels returns the labels of the service along with the labels used by the selectors and the network policies
+func getPodLabels(service irtypes.Service) map[string]string {
+	labels := map[string]string{}
+	for key, value := range service.Labels {
+		labels[key] = value
+	}
+	labels = common.MergeStringMaps(labels, getServiceLabels(service.Name))
+	return common.MergeStringMaps(labels, getNetworkPolicyLabels(service.Networks))
 }
 
 func (o *APIResource) deepMerge(x, y runtime.Object) (runtime.Object, error) {
@@ -17,12 +17,10 @@
 package apiresource
 
 import (
-	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
 	"github.com/konveyor/move2kube/common"
 	"github.com/konveyor/move2kube/qaengine"
 	collecttypes "github.com/konveyor/move2kube/types/collection"
 	irtypes "github.com/konveyor/move2kube/types/ir"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -38,7 +36,7 @@ const (
 )
 
 func (*ArgoCDApplication) getSupportedKinds() []string {
-	return []string{v1alpha1.ApplicationSchemaGroupVersionKind.Kind}
+	return []string{argoCDApplicationKind}
 }
 
 func (a *ArgoCDApplication) createNewResources(ir irtypes.EnhancedIR, supportedKinds []string, targetCluster collecttypes.ClusterMetadata) []runtime.Object {
@@ -53,7 +51,7 @@ func (a *ArgoCDApplication) createNewResources(ir irtypes.EnhancedIR, supportedK
 }
 
 // createNewResources creates the runtime objects from the intermediate representation.
-func (*ArgoCDApplication) createNewResource(irApplication irtypes.Application, targetCluster collecttypes.ClusterMetadata) *v1alpha1.Application {
+func (*ArgoCDApplication) createNewResource(irApplication irtypes.Application, targetCluster collecttypes.ClusterMetadata) runtime.Object {
 	repoURL := irApplication.RepoURL
 	if repoURL == "" {
 		repoURL = placeHolderRepoURL
@@ -70,7 +68,6 @@ func (*ArgoCDApplication) createNewResource(irApplication irtypes.Application, t
 	if clusterServer == "" {
 		clusterServer = deployToSameCluster
 	}
-	appGVK := v1alpha1.ApplicationSchemaGroupVersionKind
 	destNamespace := qaengine.FetchStringAnswer(
 		common.ConfigTransformersKubernetesArgoCDNamespaceKey,
 		"Enter the destination namespace for the Argo CD pipeline",
@@ -78,21 +75,7 @@ func (*ArgoCDApplication) createNewResource(irApplication irtypes.Application, t
 		"",
 		nil,
 	)
-	return &v1alpha1.Application{
-		TypeMeta:   metav1.TypeMeta{APIVersion: appGVK.GroupVersion().String(), Kind: appGVK.Kind},
-		ObjectMeta: metav1.ObjectMeta{Name: irApplication.Name, Namespace: argoCDNameSpace},
-		Spec: v1alpha1.ApplicationSpec{
-			Source: &v1alpha1.ApplicationSource{
-				RepoURL:        repoURL,
-				TargetRevision: repoRef,
-				Path:           repoPath,
-			},
-			Destination: v1alpha1.ApplicationDestination{
-				Server:    clusterServer,
-				Namespace: destNamespace,
-			},
-		},
-	}
+	return newArgoCDApplication(irApplication.Name, repoURL, repoRef, repoPath, clusterServer, destNamespace)
 }
 
 // convertToClusterSupportedKinds converts the object to supported types if possible.
@@ -0,0 +1,48 @@
+//go:build !wasm
+// +build !wasm
+
+/*
+ *  Copyright IBM Corporation 2022
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var argoCDApplicationKind = v1alpha1.ApplicationSchemaGroupVersionKind.Kind
+
+// newArgoCDApplication returns an Argo CD Application deploying the path in the repo to the namespace in the cluster
+func newArgoCDApplication(name, repoURL, repoRef, repoPath, clusterServer, destNamespace string) runtime.Object {
+	appGVK := v1alpha1.ApplicationSchemaGroupVersionKind
+	return &v1alpha1.Application{
+		TypeMeta:   metav1.TypeMeta{APIVersion: appGVK.GroupVersion().String(), Kind: appGVK.Kind},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: argoCDNameSpace},
+		Spec: v1alpha1.ApplicationSpec{
+			Source: &v1alpha1.ApplicationSource{
+				RepoURL:        repoURL,
+				TargetRevision: repoRef,
+				Path:           repoPath,
+			},
+			Destination: v1alpha1.ApplicationDestination{
+				Server:    clusterServer,
+				Namespace: destNamespace,
+			},
+		},
+	}
+}
@@ -0,0 +1,53 @@
+//go:build wasm
+// +build wasm
+
+/*
+ *  Copyright IBM Corporation 2022
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// argoCDApplicationKind is the kind of the Argo CD Applications.
+// The Argo CD types pull in the kubectl terminal packages, which need the unix system calls,
+// so the WebAssembly builds create the Applications as unstructured objects.
+const argoCDApplicationKind = "Application"
+
+// newArgoCDApplication returns an Argo CD Application deploying the path in the repo to the namespace in the cluster
+func newArgoCDApplication(name, repoURL, repoRef, repoPath, clusterServer, destNamespace string) runtime.Object {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "argoproj.io/v1alpha1",
+		"kind":       argoCDApplicationKind,
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": argoCDNameSpace,
+		},
+		"spec": map[string]interface{}{
+			"source": map[string]interface{}{
+				"repoURL":        repoURL,
+				"targetRevision": repoRef,
+				"path":           repoPath,
+			},
+			"destination": map[string]interface{}{
+				"server":    clusterServer,
+				"namespace": destNamespace,
+			},
+		},
+	}}
+}
@@ -0,0 +1,213 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package apiresource
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/common/report"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	certManagerAPIVersion          = "cert-manager.io/v1"
+	certManagerIssuerKind          = "Issuer"
+	certManagerClusterIssuerKind   = "ClusterIssuer"
+	noCertManagerIssuer            = "none"
+	acmeHTTP01CertManagerIssuer    = "acme-http01"
+	acmeDNS01CertManagerIssuer     = "acme-dns01"
+	caCertManagerIssuer            = "ca"
+	selfSignedCertManagerIssuer    = "selfsigned"
+	defaultACMEServer              = "https://acme-v02.api.letsencrypt.org/directory"
+	certManagerIngressIssuer       = "cert-manager.io/issuer"
+	certManagerIngressCluster      = "cert-manager.io/cluster-issuer"
+	certManagerRouteIssuerName     = "cert-manager.io/issuer-name"
+	certManagerRouteIssuerKind     = "cert-manager.io/issuer-kind"
+	cloudflareDNS01Provider        = "cloudflare"
+	route53DNS01Provider           = "route53"
+	cloudDNSDNS01Provider          = "clouddns"
+	certManagerAccountSecretSuffix = "-acme-account-key"
+)
+
+// certManagerIssuer is an Issuer or a ClusterIssuer of cert-manager
+type certManagerIssuer struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              map[string]interface{} `json:"spec"`
+}
+
+// DeepCopyObject implements the runtime.Object interface
+func (i *certManagerIssuer) DeepCopyObject() runtime.Object {
+	newIssuer := &certManagerIssuer{TypeMeta: i.TypeMeta, Spec: runtime.DeepCopyJSON(i.Spec)}
+	i.ObjectMeta.DeepCopyInto(&newIssuer.ObjectMeta)
+	return newIssuer
+}
+
+// getIngressAnnotations returns the annotations that make cert-manager issue the TLS certificates of an Ingress
+func (i *certManagerIssuer) getIngressAnnotations() map[string]string {
+	if i.Kind == certManagerClusterIssuerKind {
+		return map[string]string{certManagerIngressCluster: i.Name}
+	}
+	return map[string]string{certManagerIngressIssuer: i.Name}
+}
+
+// getRouteAnnotations returns the annotations that make the cert-manager OpenShift routes controller issue the TLS certificates of a Route
+func (i *certManagerIssuer) getRouteAnnotations() map[string]string {
+	return map[string]string{certManagerRouteIssuerName: i.Name, certManagerRouteIssuerKind: i.Kind}
+}
+
+// createCertManagerIssuer creates the cert-manager issuer selected to issue the TLS certificates of the application.
+// It returns nil if the certificates are issued by an existing issuer or are provided in secrets.
+func createCertManagerIssuer(irName, ingressClassName string) *certManagerIssuer {
+	issuerType := qaengine.FetchSelectAnswer(
+		common.ConfigCertManagerIssuerTypeKey,
+		"Select the cert-manager issuer to generate for the TLS certificates :",
+		[]string{
+			"Choose " + noCertManagerIssuer + " if the TLS certificates are already in secrets or are issued by an existing issuer.",
+			"The ACME issuers get the certificates from Let's Encrypt by default.",
+		},
+		noCertManagerIssuer,
+		[]string{noCertManagerIssuer, acmeHTTP01CertManagerIssuer, acmeDNS01CertManagerIssuer, caCertManagerIssuer, selfSignedCertManagerIssuer},
+		nil,
+	)
+	if issuerType == noCertManagerIssuer {
+		return nil
+	}
+	kind := qaengine.FetchSelectAnswer(
+		common.ConfigCertManagerIssuerKindKey,
+		"Select the kind of the cert-manager issuer :",
+		[]string{"An Issuer only issues certificates in its namespace, a ClusterIssuer issues them in all the namespaces."},
+		certManagerIssuerKind,
+		[]string{certManagerIssuerKind, certManagerClusterIssuerKind},
+		nil,
+	)
+	name := common.MakeStringDNSSubdomainNameCompliant(irName)
+	spec := map[string]interface{}{}
+	switch issuerType {
+	case acmeHTTP01CertManagerIssuer, acmeDNS01CertManagerIssuer:
+		server := qaengine.FetchStringAnswer(common.ConfigCertManagerACMEServerKey, "Enter the directory URL of the ACME server :", nil, defaultACMEServer, nil)
+		email := qaengine.FetchStringAnswer(common.ConfigCertManagerACMEEmailKey, "Enter the email of the ACME account :", []string{"The ACME server sends the notices about the expiring certificates to this email."}, "", nil)
+		solver := getHTTP01Solver(ingressClassName)
+		if issuerType == acmeDNS01CertManagerIssuer {
+			solver = getDNS01Solver(name, ingressClassName)
+		}
+		acme := map[string]interface{}{
+			"server":              server,
+			"privateKeySecretRef": map[string]interface{}{"name": name + certManagerAccountSecretSuffix},
+			"solvers":             []interface{}{solver},
+		}
+		if email != "" {
+			acme["email"] = email
+		}
+		spec["acme"] = acme
+	case caCertManagerIssuer:
+		secretName := qaengine.FetchStringAnswer(
+			common.ConfigCertManagerCASecretKey,
+			"Enter the name of the secret containing the CA that signs the certificates :",
+			[]string{"The secret should contain the tls.crt and tls.key of the CA."},
+			irName+"-ca",
+			nil,
+		)
+		spec["ca"] = map[string]interface{}{"secretName": secretName}
+	case selfSignedCertManagerIssuer:
+		spec["selfSigned"] = map[string]interface{}{}
+	}
+	return &certManagerIssuer{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       kind,
+			APIVersion: certManagerAPIVersion,
+		},
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       spec,
+	}
+}
+
+// getHTTP01Solver returns the HTTP01 solver that solves the challenges using the ingresses of the ingress class
+func getHTTP01Solver(ingressClassName string) map[string]interface{} {
+	ingress := map[string]interface{}{}
+	if ingressClassName != "" {
+		ingress["ingressClassName"] = ingressClassName
+	}
+	return map[string]interface{}{"http01": map[string]interface{}{"ingress": ingress}}
+}
+
+// getDNS01Solver returns the DNS01 solver for the selected DNS provider, referring to the credentials that have to be created in the cluster.
+// It falls back to the HTTP01 solver if the settings required by the provider are not given.
+func getDNS01Solver(issuerName, ingressClassName string) map[string]interface{} {
+	provider := qaengine.FetchSelectAnswer(
+		common.ConfigCertManagerDNS01ProviderKey,
+		"Select the DNS provider that solves the DNS01 challenges :",
+		[]string{"The solver refers to the credentials of the provider, which have to be created in the cluster."},
+		cloudflareDNS01Provider,
+		[]string{cloudflareDNS01Provider, route53DNS01Provider, cloudDNSDNS01Provider},
+		nil,
+	)
+	fetchSecretName := func(defaultSecretName string, hints []string) string {
+		secretName := strings.TrimSpace(qaengine.FetchStringAnswer(common.ConfigCertManagerDNS01SecretKey, fmt.Sprintf("Enter the name of the secret containing the credentials of %s :", provider), hints, defaultSecretName, nil))
+		if secretName == "" {
+			return defaultSecretName
+		}
+		return secretName
+	}
+	fallback := func(setting string) map[string]interface{} {
+		logrus.Warnf("No %s was given for the %s DNS01 solver of the cert-manager issuer %s . Using the HTTP01 solver instead.", setting, provider, issuerName)
+		report.AddFollowUp("", fmt.Sprintf("The cert-manager issuer %s uses the HTTP01 solver since no %s was given for the %s DNS01 solver. Configure the DNS01 solver to issue wildcard certificates", issuerName, setting, provider))
+		return getHTTP01Solver(ingressClassName)
+	}
+	var dns01 map[string]interface{}
+	switch provider {
+	case route53DNS01Provider:
+		region := strings.TrimSpace(qaengine.FetchStringAnswer(common.ConfigCertManagerDNS01RegionKey, "Enter the AWS region of Route53 :", nil, "", nil))
+		if region == "" {
+			return fallback("region")
+		}
+		route53 := map[string]interface{}{"region": region}
+		if hostedZoneID := strings.TrimSpace(qaengine.FetchStringAnswer(common.ConfigCertManagerDNS01HostedZoneKey, "Enter the id of the hosted zone of the domain :", []string{"Leave it empty to find the hosted zone using the domain."}, "", nil)); hostedZoneID != "" {
+			route53["hostedZoneID"] = hostedZoneID
+		}
+		if secretName := fetchSecretName("", []string{"Leave it empty to use the ambient credentials, like the IAM role of the service account of cert-manager."}); secretName != "" {
+			route53["accessKeyIDSecretRef"] = map[string]interface{}{"name": secretName, "key": "access-key-id"}
+			route53["secretAccessKeySecretRef"] = map[string]interface{}{"name": secretName, "key": "secret-access-key"}
+			report.AddFollowUp("", fmt.Sprintf("Create the secret %s with the keys access-key-id and secret-access-key of an AWS user allowed to change the records of the Route53 hosted zone", secretName))
+		} else {
+			report.AddFollowUp("", "Allow the service account of cert-manager to change the records of the Route53 hosted zone using its ambient credentials")
+		}
+		dns01 = map[string]interface{}{route53DNS01Provider: route53}
+	case cloudDNSDNS01Provider:
+		project := strings.TrimSpace(qaengine.FetchStringAnswer(common.ConfigCertManagerDNS01ProjectKey, "Enter the Google Cloud project of Cloud DNS :", nil, "", nil))
+		if project == "" {
+			return fallback("project")
+		}
+		secretName := fetchSecretName(issuerName+"-clouddns-service-account", nil)
+		dns01 = map[string]interface{}{cloudDNSDNS01Provider: map[string]interface{}{
+			"project":                 project,
+			"serviceAccountSecretRef": map[string]interface{}{"name": secretName, "key": "key.json"},
+		}}
+		report.AddFollowUp("", fmt.Sprintf("Create the secret %s with the key key.json containing the key of a Google Cloud service account allowed to change the records of Cloud DNS in the project %s", secretName, project))
+	default:
+		secretName := fetchSecretName(issuerName+"-cloudflare-api-token", nil)
+		dns01 = map[string]interface{}{cloudflareDNS01Provider: map[string]interface{}{
+			"apiTokenSecretRef": map[string]interface{}{"name": secretName, "key": "api-token"},
+		}}
+		report.AddFollowUp("", fmt.Sprintf("Create the secret %s with the key api-token containing a Cloudflare API token allowed to edit the DNS records of the zone", secretName))
+	}
+	return map[string]interface{}{"dns01": dns01}
+}
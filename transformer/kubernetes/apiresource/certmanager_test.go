@@ -0,0 +1,120 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package apiresource
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common/report"
+	"github.com/konveyor/move2kube/qaengine"
+)
+
+func TestCreateCertManagerIssuer(t *testing.T) {
+	t.Run("no issuer by default", func(t *testing.T) {
+		qaengine.ResetEngines()
+		defer qaengine.ResetEngines()
+		qaengine.AddEngine(qaengine.NewDefaultEngine())
+		if issuer := createCertManagerIssuer("myapp", "nginx"); issuer != nil {
+			t.Fatalf("expected no issuer. Actual: %+v", issuer)
+		}
+	})
+	t.Run("acme http01 cluster issuer", func(t *testing.T) {
+		qaengine.ResetEngines()
+		defer qaengine.ResetEngines()
+		qaengine.AddEngine(qaengine.NewDefaultEngine())
+		qaengine.SetupConfigFile("", []string{
+			`move2kube.certmanager.issuertype="acme-http01"`,
+			`move2kube.certmanager.issuerkind="ClusterIssuer"`,
+			`move2kube.certmanager.acme.email="ops@example.com"`,
+		}, nil, nil, false)
+		issuer := createCertManagerIssuer("myapp", "nginx")
+		if issuer == nil {
+			t.Fatalf("expected an issuer")
+		}
+		want := map[string]interface{}{"acme": map[string]interface{}{
+			"server":              defaultACMEServer,
+			"email":               "ops@example.com",
+			"privateKeySecretRef": map[string]interface{}{"name": "myapp-acme-account-key"},
+			"solvers":             []interface{}{map[string]interface{}{"http01": map[string]interface{}{"ingress": map[string]interface{}{"ingressClassName": "nginx"}}}},
+		}}
+		if diff := cmp.Diff(want, issuer.Spec); diff != "" {
+			t.Fatalf("wrong issuer spec. Difference:\n%s", diff)
+		}
+		if diff := cmp.Diff(map[string]string{certManagerIngressCluster: "myapp"}, issuer.getIngressAnnotations()); diff != "" {
+			t.Fatalf("wrong ingress annotations. Difference:\n%s", diff)
+		}
+	})
+	t.Run("ca issuer", func(t *testing.T) {
+		qaengine.ResetEngines()
+		defer qaengine.ResetEngines()
+		qaengine.AddEngine(qaengine.NewDefaultEngine())
+		qaengine.SetupConfigFile("", []string{`move2kube.certmanager.issuertype="ca"`}, nil, nil, false)
+		issuer := createCertManagerIssuer("myapp", "")
+		if issuer == nil || issuer.Kind != certManagerIssuerKind {
+			t.Fatalf("expected an Issuer. Actual: %+v", issuer)
+		}
+		if diff := cmp.Diff(map[string]interface{}{"ca": map[string]interface{}{"secretName": "myapp-ca"}}, issuer.Spec); diff != "" {
+			t.Fatalf("wrong issuer spec. Difference:\n%s", diff)
+		}
+	})
+}
+
+func TestGetDNS01Solver(t *testing.T) {
+	t.Run("cloudflare solver refers to the secret of the api token", func(t *testing.T) {
+		qaengine.ResetEngines()
+		defer qaengine.ResetEngines()
+		qaengine.AddEngine(qaengine.NewDefaultEngine())
+		report.Reset()
+		defer report.Reset()
+		want := map[string]interface{}{"dns01": map[string]interface{}{cloudflareDNS01Provider: map[string]interface{}{
+			"apiTokenSecretRef": map[string]interface{}{"name": "myapp-cloudflare-api-token", "key": "api-token"},
+		}}}
+		if diff := cmp.Diff(want, getDNS01Solver("myapp", "nginx")); diff != "" {
+			t.Fatalf("wrong solver. Difference:\n%s", diff)
+		}
+		if len(report.Get("", "").Spec.FollowUps) != 1 {
+			t.Fatalf("expected a follow up to create the secret. Actual: %+v", report.Get("", "").Spec.FollowUps)
+		}
+	})
+	t.Run("route53 solver uses the given region and the ambient credentials", func(t *testing.T) {
+		qaengine.ResetEngines()
+		defer qaengine.ResetEngines()
+		qaengine.AddEngine(qaengine.NewDefaultEngine())
+		qaengine.SetupConfigFile("", []string{
+			`move2kube.certmanager.acme.dns01provider="route53"`,
+			`move2kube.certmanager.acme.dns01.region="eu-west-1"`,
+		}, nil, nil, false)
+		want := map[string]interface{}{"dns01": map[string]interface{}{route53DNS01Provider: map[string]interface{}{"region": "eu-west-1"}}}
+		if diff := cmp.Diff(want, getDNS01Solver("myapp", "nginx")); diff != "" {
+			t.Fatalf("wrong solver. Difference:\n%s", diff)
+		}
+	})
+	t.Run("clouddns solver without a project falls back to the http01 solver", func(t *testing.T) {
+		qaengine.ResetEngines()
+		defer qaengine.ResetEngines()
+		qaengine.AddEngine(qaengine.NewDefaultEngine())
+		qaengine.SetupConfigFile("", []string{`move2kube.certmanager.acme.dns01provider="clouddns"`}, nil, nil, false)
+		report.Reset()
+		defer report.Reset()
+		if diff := cmp.Diff(getHTTP01Solver("nginx"), getDNS01Solver("myapp", "nginx")); diff != "" {
+			t.Fatalf("wrong solver. Difference:\n%s", diff)
+		}
+		if len(report.Get("", "").Spec.FollowUps) != 1 {
+			t.Fatalf("expected a follow up about the fallback. Actual: %+v", report.Get("", "").Spec.FollowUps)
+		}
+	})
+}
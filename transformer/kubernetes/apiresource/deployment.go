@@ -74,7 +74,8 @@ func (d *Deployment) getSupportedKinds() []string {
 // createNewResources converts ir to runtime object
 func (d *Deployment) createNewResources(ir irtypes.EnhancedIR, supportedKinds []string, targetCluster collecttypes.ClusterMetadata) []runtime.Object {
 	objs := []runtime.Object{}
-	for _, service := range ir.Services {
+	for _, serviceName := range ir.GetSortedServiceNames() {
+		service := ir.Services[serviceName]
 		var obj runtime.Object
 		if service.Daemon {
 			if !common.IsPresent(supportedKinds, daemonSetKind) {
@@ -192,7 +193,7 @@ func (d *Deployment) convertToClusterSupportedKinds(obj runtime.Object, supporte
 func (d *Deployment) createDeployment(service irtypes.Service, cluster collecttypes.ClusterMetadataSpec) *apps.Deployment {
 	meta := metav1.ObjectMeta{
 		Name:        service.Name,
-		Labels:      getPodLabels(service.Name, service.Networks),
+		Labels:      getPodLabels(service),
 		Annotations: getAnnotations(service),
 	}
 	podSpec := service.PodSpec
@@ -205,7 +206,7 @@ func (d *Deployment) createDeployment(service irtypes.Service, cluster collectty
 func (d *Deployment) createDeploymentConfig(service irtypes.Service, cluster collecttypes.ClusterMetadataSpec) *okdappsv1.DeploymentConfig {
 	meta := metav1.ObjectMeta{
 		Name:        service.Name,
-		Labels:      getPodLabels(service.Name, service.Networks),
+		Labels:      getPodLabels(service),
 		Annotations: getAnnotations(service),
 	}
 	podSpec := service.PodSpec
@@ -219,7 +220,7 @@ func (d *Deployment) createDeploymentConfig(service irtypes.Service, cluster col
 func (d *Deployment) createReplicationController(service irtypes.Service, cluster collecttypes.ClusterMetadataSpec) *core.ReplicationController {
 	meta := metav1.ObjectMeta{
 		Name:        service.Name,
-		Labels:      getPodLabels(service.Name, service.Networks),
+		Labels:      getPodLabels(service),
 		Annotations: getAnnotations(service),
 	}
 	podSpec := service.PodSpec
@@ -235,7 +236,7 @@ func (d *Deployment) createPod(service irtypes.Service, cluster collecttypes.Clu
 	podSpec.RestartPolicy = core.RestartPolicyAlways
 	meta := metav1.ObjectMeta{
 		Name:        service.Name,
-		Labels:      getPodLabels(service.Name, service.Networks),
+		Labels:      getPodLabels(service),
 		Annotations: getAnnotations(service),
 	}
 	return d.toPod(meta, core.PodSpec(podSpec), podSpec.RestartPolicy, cluster)
@@ -247,7 +248,7 @@ func (d *Deployment) createDaemonSet(service irtypes.Service, cluster collecttyp
 	podSpec.RestartPolicy = core.RestartPolicyAlways
 	meta := metav1.ObjectMeta{
 		Name:        service.Name,
-		Labels:      getPodLabels(service.Name, service.Networks),
+		Labels:      getPodLabels(service),
 		Annotations: getAnnotations(service),
 	}
 	pod := apps.DaemonSet{
@@ -272,7 +273,7 @@ func (d *Deployment) createJob(service irtypes.Service, cluster collecttypes.Clu
 	podspec.RestartPolicy = core.RestartPolicyOnFailure
 	meta := metav1.ObjectMeta{
 		Name:        service.Name,
-		Labels:      getPodLabels(service.Name, service.Networks),
+		Labels:      getPodLabels(service),
 		Annotations: getAnnotations(service),
 	}
 	pod := batch.Job{
@@ -297,7 +298,7 @@ func (d *Deployment) createStatefulSet(service irtypes.Service, cluster collectt
 	podSpec.RestartPolicy = core.RestartPolicyAlways
 	meta := metav1.ObjectMeta{
 		Name:        service.Name,
-		Labels:      getPodLabels(service.Name, service.Networks),
+		Labels:      getPodLabels(service),
 		Annotations: getAnnotations(service),
 	}
 	statefulset := apps.StatefulSet{
@@ -327,7 +328,7 @@ func (d *Deployment) createArgorollout(service irtypes.Service, cluster collectt
 
 	meta := metav1.ObjectMeta{
 		Name:        service.Name,
-		Labels:      getPodLabels(service.Name, service.Networks),
+		Labels:      getPodLabels(service),
 		Annotations: getAnnotations(service),
 	}
 	replicas := int32(service.Replicas)
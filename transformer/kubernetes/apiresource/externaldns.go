@@ -0,0 +1,63 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package apiresource
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/spf13/cast"
+)
+
+const (
+	externalDNSHostnameAnnotation = "external-dns.alpha.kubernetes.io/hostname"
+	externalDNSTTLAnnotation      = "external-dns.alpha.kubernetes.io/ttl"
+	defaultExternalDNSTTL         = "300"
+)
+
+// useExternalDNS returns true if the Ingress and the LoadBalancer Services should be annotated for external-dns
+func useExternalDNS() bool {
+	return qaengine.FetchBoolAnswer(
+		common.ConfigExternalDNSEnableKey,
+		"Do you want to add the external-dns annotations to the Ingress and the LoadBalancer Services?",
+		[]string{"external-dns creates the DNS records of the annotated hostnames in the DNS provider of the cluster."},
+		false,
+		nil,
+	)
+}
+
+// getExternalDNSAnnotations returns the external-dns annotations for the hostnames
+func getExternalDNSAnnotations(hostnames []string) map[string]string {
+	ttl := qaengine.FetchStringAnswer(
+		common.ConfigExternalDNSTTLKey,
+		"Enter the TTL in seconds of the DNS records :",
+		nil,
+		defaultExternalDNSTTL,
+		func(ttl interface{}) error {
+			_, err := cast.ToUint32E(ttl)
+			return err
+		},
+	)
+	hostnames = common.MergeSlices([]string{}, hostnames)
+	sort.Strings(hostnames)
+	annotations := map[string]string{externalDNSHostnameAnnotation: strings.Join(hostnames, ",")}
+	if ttl != "" {
+		annotations[externalDNSTTLAnnotation] = ttl
+	}
+	return annotations
+}
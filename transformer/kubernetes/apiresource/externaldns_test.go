@@ -0,0 +1,57 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package apiresource
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	core "k8s.io/kubernetes/pkg/apis/core"
+	"k8s.io/kubernetes/pkg/apis/networking"
+)
+
+func TestExternalDNSAnnotations(t *testing.T) {
+	qaengine.ResetEngines()
+	defer qaengine.ResetEngines()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", []string{`move2kube.externaldns.enable=true`, `move2kube.externaldns.ttl="60"`}, nil, nil, false)
+	ir := irtypes.NewIR()
+	ir.Name = "myapp"
+	web := irtypes.NewServiceWithName("web")
+	web.ServiceToPodPortForwardings = []irtypes.ServiceToPodPortForwarding{{
+		ServicePort: networking.ServiceBackendPort{Number: 80},
+		PodPort:     networking.ServiceBackendPort{Number: 8080},
+		ServiceType: core.ServiceTypeLoadBalancer,
+	}}
+	ir.Services["web"] = web
+	targetCluster := collection.ClusterMetadata{Spec: collection.ClusterMetadataSpec{Host: "example.com"}}
+	objs := (&Service{}).createNewResources(irtypes.NewEnhancedIRFromIR(ir), []string{common.ServiceKind}, targetCluster)
+	if len(objs) != 1 {
+		t.Fatalf("expected a single service. Actual: %+v", objs)
+	}
+	service, ok := objs[0].(*core.Service)
+	if !ok {
+		t.Fatalf("expected a service. Actual: %T", objs[0])
+	}
+	want := map[string]string{externalDNSHostnameAnnotation: "web.example.com", externalDNSTTLAnnotation: "60"}
+	if diff := cmp.Diff(want, service.Annotations); diff != "" {
+		t.Fatalf("wrong annotations on the LoadBalancer service. Difference:\n%s", diff)
+	}
+}
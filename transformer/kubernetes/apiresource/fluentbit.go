@@ -0,0 +1,296 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package apiresource
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/kubernetes/pkg/apis/apps"
+	core "k8s.io/kubernetes/pkg/apis/core"
+	"k8s.io/kubernetes/pkg/apis/rbac"
+)
+
+const (
+	// noLoggingBackend does not ship the container logs
+	noLoggingBackend            = "none"
+	elasticsearchLoggingBackend = "elasticsearch"
+	lokiLoggingBackend          = "loki"
+	cloudWatchLoggingBackend    = "cloudwatch"
+	fluentBitImage              = "cr.fluentbit.io/fluent/fluent-bit:2.1.10"
+	fluentBitConfigFileName     = "fluent-bit.conf"
+	fluentBitConfigMountPath    = "/fluent-bit/etc"
+	fluentBitConfigVolumeName   = "config"
+	fluentBitLogsVolumeName     = "varlog"
+	hostLogsPath                = "/var/log"
+)
+
+var (
+	// defaultLoggingEndpoints are the default endpoints of the logging backends. The region is the endpoint of CloudWatch.
+	defaultLoggingEndpoints = map[string]string{
+		elasticsearchLoggingBackend: "elasticsearch.logging:9200",
+		lokiLoggingBackend:          "loki.logging:3100",
+		cloudWatchLoggingBackend:    "us-east-1",
+	}
+	// remoteLoggingDrivers are the compose logging drivers that send the logs to a centralized logging backend,
+	// along with the backend the logs are shipped to by default
+	remoteLoggingDrivers = map[string]string{
+		"awslogs":    cloudWatchLoggingBackend,
+		"loki":       lokiLoggingBackend,
+		"fluentd":    elasticsearchLoggingBackend,
+		"gelf":       elasticsearchLoggingBackend,
+		"syslog":     elasticsearchLoggingBackend,
+		"splunk":     elasticsearchLoggingBackend,
+		"gcplogs":    elasticsearchLoggingBackend,
+		"logentries": elasticsearchLoggingBackend,
+	}
+)
+
+// FluentBit handles the Fluent Bit DaemonSet that ships the container logs to a centralized logging backend
+type FluentBit struct {
+}
+
+// getSupportedKinds returns all kinds supported by the class
+func (f *FluentBit) getSupportedKinds() []string {
+	return []string{string(irtypes.ConfigMapKind), daemonSetKind, rbac.ServiceAccountKind, roleKind, roleBindingKind}
+}
+
+// createNewResources creates a Fluent Bit DaemonSet, along with its configuration and permissions,
+// that ships the logs of the selected services to the logging backend
+func (f *FluentBit) createNewResources(ir irtypes.EnhancedIR, supportedKinds []string, targetCluster collecttypes.ClusterMetadata) []runtime.Object {
+	if len(ir.Services) == 0 {
+		return nil
+	}
+	defaultBackend := noLoggingBackend
+	for _, serviceName := range ir.GetSortedServiceNames() {
+		if backend, ok := remoteLoggingDrivers[ir.Services[serviceName].LoggingDriver]; ok {
+			defaultBackend = backend
+			break
+		}
+	}
+	backend := qaengine.FetchSelectAnswer(
+		common.ConfigLoggingBackendKey,
+		"Select the logging backend the container logs should be shipped to :",
+		[]string{"A Fluent Bit DaemonSet is generated to ship the logs of the services. Choose " + noLoggingBackend + " to not ship the logs."},
+		defaultBackend,
+		[]string{noLoggingBackend, elasticsearchLoggingBackend, lokiLoggingBackend, cloudWatchLoggingBackend},
+		nil,
+	)
+	if backend == noLoggingBackend {
+		return nil
+	}
+	if !common.IsPresent(supportedKinds, daemonSetKind) || !common.IsPresent(supportedKinds, string(irtypes.ConfigMapKind)) {
+		logrus.Errorf("Could not find a valid resource type in cluster to create a Fluent Bit DaemonSet")
+		return nil
+	}
+	endpointDesc := fmt.Sprintf("Enter the host:port of %s :", backend)
+	if backend == cloudWatchLoggingBackend {
+		endpointDesc = "Enter the AWS region of CloudWatch :"
+	}
+	endpoint := qaengine.FetchStringAnswer(common.ConfigLoggingEndpointKey, endpointDesc, nil, defaultLoggingEndpoints[backend], nil)
+	serviceNames := f.getLoggedServiceNames(ir, defaultBackend != noLoggingBackend)
+	if len(serviceNames) == 0 {
+		logrus.Infof("None of the services ship their logs. Not generating the Fluent Bit DaemonSet.")
+		return nil
+	}
+	name := common.MakeStringK8sServiceNameCompliant(ir.Name + "-fluent-bit")
+	labels := map[string]string{selector: name}
+	config := getFluentBitConfig(ir.Name, serviceNames, backend, endpoint)
+	objs := []runtime.Object{
+		&core.ConfigMap{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       string(irtypes.ConfigMapKind),
+				APIVersion: core.SchemeGroupVersion.String(),
+			},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+			Data:       map[string]string{fluentBitConfigFileName: config},
+		},
+		&core.ServiceAccount{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       rbac.ServiceAccountKind,
+				APIVersion: core.SchemeGroupVersion.String(),
+			},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+		},
+		&rbac.Role{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       roleKind,
+				APIVersion: rbac.SchemeGroupVersion.String(),
+			},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+			// the kubernetes filter enriches the logs with the metadata of the pods
+			Rules: []rbac.PolicyRule{{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get", "list", "watch"}}},
+		},
+		&rbac.RoleBinding{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       roleBindingKind,
+				APIVersion: rbac.SchemeGroupVersion.String(),
+			},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+			RoleRef:    rbac.RoleRef{APIGroup: rbac.SchemeGroupVersion.Group, Kind: roleKind, Name: name},
+			Subjects:   []rbac.Subject{{Kind: rbac.ServiceAccountKind, Name: name}},
+		},
+		f.createFluentBitDaemonSet(name, labels),
+	}
+	return objs
+}
+
+// convertToClusterSupportedKinds converts kinds to cluster supported kinds
+func (f *FluentBit) convertToClusterSupportedKinds(obj runtime.Object, supportedKinds []string, otherobjs []runtime.Object, _ irtypes.EnhancedIR, targetCluster collecttypes.ClusterMetadata) ([]runtime.Object, bool) {
+	if common.IsPresent(f.getSupportedKinds(), obj.GetObjectKind().GroupVersionKind().Kind) {
+		return []runtime.Object{obj}, true
+	}
+	return nil, false
+}
+
+// getLoggedServiceNames returns the sorted names of the services whose logs should be shipped.
+// When some services use a remote logging driver in the source, only those are selected by default.
+func (f *FluentBit) getLoggedServiceNames(ir irtypes.EnhancedIR, hasRemoteLoggingDrivers bool) []string {
+	serviceNames := []string{}
+	for _, serviceName := range ir.GetSortedServiceNames() {
+		service := ir.Services[serviceName]
+		if service.OnlyIngress || len(service.Containers) == 0 {
+			continue
+		}
+		_, isRemote := remoteLoggingDrivers[service.LoggingDriver]
+		if qaengine.FetchBoolAnswer(
+			common.JoinQASubKeys(common.ConfigServicesKey, `"`+serviceName+`"`, common.ConfigCentralizedLoggingForServiceKeySegment),
+			fmt.Sprintf("Ship the logs of the service '%s' to the logging backend?", serviceName),
+			nil,
+			isRemote || !hasRemoteLoggingDrivers,
+			nil,
+		) {
+			serviceNames = append(serviceNames, service.Name)
+		}
+	}
+	sort.Strings(serviceNames)
+	return serviceNames
+}
+
+func (f *FluentBit) createFluentBitDaemonSet(name string, labels map[string]string) *apps.DaemonSet {
+	meta := metav1.ObjectMeta{Name: name, Labels: labels}
+	return &apps.DaemonSet{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       daemonSetKind,
+			APIVersion: apps.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: meta,
+		Spec: apps.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: core.PodTemplateSpec{
+				ObjectMeta: meta,
+				Spec: core.PodSpec{
+					ServiceAccountName: name,
+					Containers: []core.Container{{
+						Name:  "fluent-bit",
+						Image: fluentBitImage,
+						VolumeMounts: []core.VolumeMount{
+							{Name: fluentBitConfigVolumeName, MountPath: fluentBitConfigMountPath, ReadOnly: true},
+							{Name: fluentBitLogsVolumeName, MountPath: hostLogsPath, ReadOnly: true},
+						},
+					}},
+					Volumes: []core.Volume{
+						{
+							Name: fluentBitConfigVolumeName,
+							VolumeSource: core.VolumeSource{
+								ConfigMap: &core.ConfigMapVolumeSource{LocalObjectReference: core.LocalObjectReference{Name: name}},
+							},
+						},
+						{
+							Name:         fluentBitLogsVolumeName,
+							VolumeSource: core.VolumeSource{HostPath: &core.HostPathVolumeSource{Path: hostLogsPath}},
+						},
+					},
+					// run on all the nodes, including the tainted ones
+					Tolerations: []core.Toleration{{Operator: core.TolerationOpExists}},
+				},
+			},
+		},
+	}
+}
+
+// getFluentBitConfig returns the Fluent Bit configuration that tails the container logs of the services on the node,
+// adds the metadata of the pods and sends them to the logging backend
+func getFluentBitConfig(appName string, serviceNames []string, backend, endpoint string) string {
+	paths := []string{}
+	for _, serviceName := range serviceNames {
+		// the names of the pods start with the name of their service
+		paths = append(paths, fmt.Sprintf("%s/containers/%s-*.log", hostLogsPath, serviceName))
+	}
+	config := `[SERVICE]
+    Flush         5
+    Log_Level     info
+
+[INPUT]
+    Name              tail
+    Tag               kube.*
+    Path              ` + strings.Join(paths, ",") + `
+    multiline.parser  docker, cri
+    Mem_Buf_Limit     5MB
+    Skip_Long_Lines   On
+
+[FILTER]
+    Name              kubernetes
+    Match             kube.*
+    Merge_Log         On
+    Keep_Log          Off
+
+[OUTPUT]
+    Match             kube.*
+`
+	if backend == cloudWatchLoggingBackend {
+		return config + `    Name              cloudwatch_logs
+    region            ` + endpoint + `
+    log_group_name    /` + appName + `
+    log_stream_prefix kube-
+    auto_create_group On
+`
+	}
+	host, port := getLoggingHostAndPort(backend, endpoint)
+	config += `    Name              ` + map[string]string{elasticsearchLoggingBackend: "es", lokiLoggingBackend: "loki"}[backend] + `
+    Host              ` + host + `
+    Port              ` + port + `
+`
+	if backend == lokiLoggingBackend {
+		return config + `    Labels            job=fluent-bit
+    Auto_Kubernetes_Labels On
+`
+	}
+	return config + `    Logstash_Format   On
+    Logstash_Prefix   ` + appName + `
+    Suppress_Type_Name On
+    Replace_Dots      On
+`
+}
+
+// getLoggingHostAndPort splits the endpoint of the logging backend, using the default port of the backend when it is missing
+func getLoggingHostAndPort(backend, endpoint string) (string, string) {
+	host, port, err := net.SplitHostPort(endpoint)
+	if err == nil {
+		return host, port
+	}
+	_, defaultPort, _ := net.SplitHostPort(defaultLoggingEndpoints[backend])
+	return endpoint, defaultPort
+}
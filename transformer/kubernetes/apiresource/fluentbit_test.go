@@ -0,0 +1,77 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package apiresource
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"k8s.io/kubernetes/pkg/apis/apps"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestCreateFluentBit(t *testing.T) {
+	qaengine.ResetEngines()
+	defer qaengine.ResetEngines()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", []string{`move2kube.logging.endpoint="es.example.com"`}, nil, nil, false)
+	ir := irtypes.NewIR()
+	ir.Name = "myapp"
+	web := irtypes.NewServiceWithName("web")
+	web.Containers = []core.Container{{Name: "web"}}
+	web.LoggingDriver = "fluentd"
+	ir.Services["web"] = web
+	worker := irtypes.NewServiceWithName("worker")
+	worker.Containers = []core.Container{{Name: "worker"}}
+	ir.Services["worker"] = worker
+	supportedKinds := (&FluentBit{}).getSupportedKinds()
+	objs := (&FluentBit{}).createNewResources(irtypes.NewEnhancedIRFromIR(ir), supportedKinds, collection.ClusterMetadata{})
+	if len(objs) != 5 {
+		t.Fatalf("expected the config map, the permissions and the daemonset. Actual: %+v", objs)
+	}
+	configMap, ok := objs[0].(*core.ConfigMap)
+	if !ok {
+		t.Fatalf("expected a config map. Actual: %T", objs[0])
+	}
+	config := configMap.Data[fluentBitConfigFileName]
+	// the service with the fluentd logging driver selects elasticsearch and is the only one shipping its logs by default
+	for _, want := range []string{"Path              /var/log/containers/web-*.log\n", "Name              es\n", "Host              es.example.com\n", "Port              9200\n"} {
+		if !strings.Contains(config, want) {
+			t.Fatalf("expected the config to contain %q. Actual:\n%s", want, config)
+		}
+	}
+	daemonSet, ok := objs[4].(*apps.DaemonSet)
+	if !ok {
+		t.Fatalf("expected a daemonset. Actual: %T", objs[4])
+	}
+	if daemonSet.Name != "myapp-fluent-bit" || daemonSet.Spec.Template.Spec.ServiceAccountName != "myapp-fluent-bit" {
+		t.Fatalf("expected the daemonset to run with its service account. Actual: %+v", daemonSet)
+	}
+}
+
+func TestCreateFluentBitWithoutBackend(t *testing.T) {
+	qaengine.ResetEngines()
+	defer qaengine.ResetEngines()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	ir := irtypes.NewIR()
+	ir.Services["web"] = irtypes.NewServiceWithName("web")
+	if objs := (&FluentBit{}).createNewResources(irtypes.NewEnhancedIRFromIR(ir), (&FluentBit{}).getSupportedKinds(), collection.ClusterMetadata{}); len(objs) != 0 {
+		t.Fatalf("expected no objects when the services do not use centralized logging. Actual: %+v", objs)
+	}
+}
@@ -0,0 +1,164 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+const (
+	// grafanaDashboardLabel is the label the Grafana sidecar uses to discover the ConfigMaps containing dashboards
+	grafanaDashboardLabel      = "grafana_dashboard"
+	grafanaDashboardLabelValue = "1"
+	grafanaDatasourceVariable  = "${datasource}"
+	grafanaPanelWidth          = 6
+	grafanaPanelHeight         = 8
+	// grafanaDashboardWidth is the number of columns in the grid of a Grafana dashboard
+	grafanaDashboardWidth        = 24
+	grafanaDashboardUIDMaxLength = 40
+)
+
+// GrafanaDashboard handles the ConfigMaps containing the Grafana dashboards
+type GrafanaDashboard struct {
+}
+
+// getSupportedKinds returns all kinds supported by the class
+func (g *GrafanaDashboard) getSupportedKinds() []string {
+	return []string{string(irtypes.ConfigMapKind)}
+}
+
+// createNewResources creates a ConfigMap with a dashboard for the application, that can be discovered by the Grafana sidecar
+func (g *GrafanaDashboard) createNewResources(ir irtypes.EnhancedIR, supportedKinds []string, targetCluster collecttypes.ClusterMetadata) []runtime.Object {
+	if len(ir.Services) == 0 {
+		return nil
+	}
+	if !qaengine.FetchBoolAnswer(
+		common.ConfigMonitoringDashboardsKey,
+		"Do you want to generate a Grafana dashboard for the application?",
+		[]string{fmt.Sprintf("The dashboard is put in a ConfigMap with the label %s=%s to be discovered by the Grafana sidecar", grafanaDashboardLabel, grafanaDashboardLabelValue)},
+		false,
+		nil,
+	) {
+		return nil
+	}
+	if !common.IsPresent(supportedKinds, string(irtypes.ConfigMapKind)) {
+		logrus.Errorf("Could not find a valid resource type in cluster to create a ConfigMap")
+		return nil
+	}
+	name := common.MakeStringDNSSubdomainNameCompliant(ir.Name + "-grafana-dashboard")
+	dashboard, err := json.MarshalIndent(getGrafanaDashboard(ir), "", "  ")
+	if err != nil {
+		logrus.Errorf("failed to encode the Grafana dashboard as json. Error: %q", err)
+		return nil
+	}
+	return []runtime.Object{&core.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       string(irtypes.ConfigMapKind),
+			APIVersion: core.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{grafanaDashboardLabel: grafanaDashboardLabelValue},
+		},
+		Data: map[string]string{name + ".json": string(dashboard)},
+	}}
+}
+
+// convertToClusterSupportedKinds converts kinds to cluster supported kinds
+func (g *GrafanaDashboard) convertToClusterSupportedKinds(obj runtime.Object, supportedKinds []string, otherobjs []runtime.Object, _ irtypes.EnhancedIR, targetCluster collecttypes.ClusterMetadata) ([]runtime.Object, bool) {
+	if common.IsPresent(g.getSupportedKinds(), obj.GetObjectKind().GroupVersionKind().Kind) {
+		return []runtime.Object{obj}, true
+	}
+	return nil, false
+}
+
+// getGrafanaDashboard returns a dashboard with a row of panels for the CPU, the memory, the restarts and the requests of each service
+func getGrafanaDashboard(ir irtypes.EnhancedIR) map[string]interface{} {
+	panels := []map[string]interface{}{}
+	y := 0
+	for _, serviceName := range ir.GetSortedServiceNames() {
+		service := ir.Services[serviceName]
+		if service.OnlyIngress {
+			continue
+		}
+		podSelector := fmt.Sprintf(`pod=~"%s-.*"`, regexp.QuoteMeta(service.Name))
+		panels = append(panels, map[string]interface{}{
+			"id":        len(panels) + 1,
+			"type":      "row",
+			"title":     service.Name,
+			"collapsed": false,
+			"gridPos":   map[string]interface{}{"x": 0, "y": y, "w": grafanaDashboardWidth, "h": 1},
+		})
+		y++
+		targets := []map[string]interface{}{
+			{"title": "CPU", "expr": fmt.Sprintf(`sum(rate(container_cpu_usage_seconds_total{%s,container!=""}[5m]))`, podSelector)},
+			{"title": "Memory", "expr": fmt.Sprintf(`sum(container_memory_working_set_bytes{%s,container!=""})`, podSelector)},
+			{"title": "Restarts", "expr": fmt.Sprintf(`sum(increase(kube_pod_container_status_restarts_total{%s}[1h]))`, podSelector)},
+		}
+		if len(service.ServiceToPodPortForwardings) != 0 {
+			// the endpoint label added by the Prometheus operator is the name of the port of the service
+			targets = append(targets, map[string]interface{}{"title": "Requests by port", "expr": fmt.Sprintf(`sum by (endpoint) (rate(http_requests_total{service="%s"}[5m]))`, service.Name)})
+		}
+		for j, target := range targets {
+			title := target["title"]
+			delete(target, "title")
+			target["refId"] = "A"
+			panels = append(panels, map[string]interface{}{
+				"id":         len(panels) + 1,
+				"type":       "timeseries",
+				"title":      title,
+				"datasource": map[string]interface{}{"type": "prometheus", "uid": grafanaDatasourceVariable},
+				"gridPos":    map[string]interface{}{"x": j * grafanaPanelWidth, "y": y, "w": grafanaPanelWidth, "h": grafanaPanelHeight},
+				"targets":    []map[string]interface{}{target},
+			})
+		}
+		y += grafanaPanelHeight
+	}
+	// the uid of a dashboard can have at most 40 characters
+	uid := common.MakeStringDNSNameCompliant(ir.Name)
+	if len(uid) > grafanaDashboardUIDMaxLength {
+		uid = uid[:grafanaDashboardUIDMaxLength]
+	}
+	return map[string]interface{}{
+		"title":         ir.Name,
+		"uid":           uid,
+		"tags":          []string{"move2kube"},
+		"schemaVersion": 36,
+		"time":          map[string]interface{}{"from": "now-6h", "to": "now"},
+		"refresh":       "30s",
+		"templating": map[string]interface{}{
+			"list": []map[string]interface{}{{
+				"name":  "datasource",
+				"label": "Data source",
+				"type":  "datasource",
+				"query": "prometheus",
+			}},
+		},
+		"panels": panels,
+	}
+}
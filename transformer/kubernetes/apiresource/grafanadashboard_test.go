@@ -0,0 +1,60 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	core "k8s.io/kubernetes/pkg/apis/core"
+	"k8s.io/kubernetes/pkg/apis/networking"
+)
+
+func TestCreateGrafanaDashboard(t *testing.T) {
+	qaengine.ResetEngines()
+	defer qaengine.ResetEngines()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", []string{`move2kube.monitoring.dashboards=true`}, nil, nil, false)
+	ir := irtypes.NewIR()
+	ir.Name = "myapp"
+	web := irtypes.NewServiceWithName("web")
+	web.ServiceToPodPortForwardings = []irtypes.ServiceToPodPortForwarding{{ServicePort: networking.ServiceBackendPort{Number: 8080}, PodPort: networking.ServiceBackendPort{Number: 8080}}}
+	ir.Services["web"] = web
+	ir.Services["worker"] = irtypes.NewServiceWithName("worker")
+	objs := (&GrafanaDashboard{}).createNewResources(irtypes.NewEnhancedIRFromIR(ir), []string{string(irtypes.ConfigMapKind)}, collection.ClusterMetadata{})
+	if len(objs) != 1 {
+		t.Fatalf("expected a single config map for the application. Actual: %+v", objs)
+	}
+	configMap, ok := objs[0].(*core.ConfigMap)
+	if !ok {
+		t.Fatalf("expected a config map. Actual: %T", objs[0])
+	}
+	if configMap.Name != "myapp-grafana-dashboard" || configMap.Labels[grafanaDashboardLabel] != grafanaDashboardLabelValue {
+		t.Fatalf("expected the config map to be discoverable by the sidecar. Actual: %+v", configMap.ObjectMeta)
+	}
+	dashboard := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(configMap.Data["myapp-grafana-dashboard.json"]), &dashboard); err != nil {
+		t.Fatalf("failed to parse the dashboard. Error: %q", err)
+	}
+	// a row and 4 panels for web and a row and 3 panels for worker, since it has no ports
+	if panels, _ := dashboard["panels"].([]interface{}); len(panels) != 9 {
+		t.Fatalf("wrong number of panels. Actual: %d", len(panels))
+	}
+}
@@ -39,7 +39,8 @@ type KnativeService struct {
 func (d *KnativeService) createNewResources(ir irtypes.EnhancedIR, supportedKinds []string, targetCluster collecttypes.ClusterMetadata) []runtime.Object {
 	objs := []runtime.Object{}
 
-	for _, service := range ir.Services {
+	for _, serviceName := range ir.GetSortedServiceNames() {
+		service := ir.Services[serviceName]
 		podSpec := core.PodSpec(service.PodSpec)
 		podSpec.RestartPolicy = core.RestartPolicyAlways
 		knativeservice := &knativev1.Service{
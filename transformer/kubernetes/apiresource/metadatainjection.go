@@ -0,0 +1,157 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// resourceSelector matches the resources using their kinds and names
+type resourceSelector struct {
+	kinds []string
+	names []*regexp.Regexp
+}
+
+// metadataInjector adds labels and annotations to the resources matching the kinds and names
+type metadataInjector struct {
+	resourceSelector
+	labels      map[string]string
+	annotations map[string]string
+}
+
+var metadataInjectors []metadataInjector
+
+// LoadMetadataInjections loads the rules from all the metadata injection configs found in the directory.
+// The rules are applied to every resource that is written out afterwards.
+func LoadMetadataInjections(dir string) error {
+	metadataInjectors = nil
+	if dir == "" {
+		return nil
+	}
+	yamlPaths, err := common.GetFilesByExt(dir, []string{".yml", ".yaml"})
+	if err != nil {
+		return fmt.Errorf("failed to look for yaml files in the directory '%s' . Error: %w", dir, err)
+	}
+	sort.Strings(yamlPaths)
+	for _, yamlPath := range yamlPaths {
+		mi := transformertypes.NewMetadataInjection()
+		if err := common.ReadMove2KubeYaml(yamlPath, &mi); err != nil || mi.Kind != transformertypes.MetadataInjectionKind {
+			continue
+		}
+		logrus.Debugf("found the metadata injection config at path '%s'", yamlPath)
+		for ruleIdx, rule := range mi.Spec.Rules {
+			injector, err := newMetadataInjector(rule)
+			if err != nil {
+				logrus.Errorf("skipping the rule %d of the metadata injection config at path '%s' . Error: %q", ruleIdx, yamlPath, err)
+				continue
+			}
+			metadataInjectors = append(metadataInjectors, injector)
+		}
+	}
+	return nil
+}
+
+func newMetadataInjector(rule transformertypes.MetadataInjectionRule) (metadataInjector, error) {
+	selector, err := newResourceSelector(rule.Kinds, rule.Names)
+	if err != nil {
+		return metadataInjector{}, err
+	}
+	return metadataInjector{resourceSelector: selector, labels: rule.Labels, annotations: rule.Annotations}, nil
+}
+
+func newResourceSelector(kinds, names []string) (resourceSelector, error) {
+	selector := resourceSelector{kinds: kinds}
+	for _, name := range names {
+		nameRegex, err := regexp.Compile("^(?:" + name + ")$")
+		if err != nil {
+			return selector, fmt.Errorf("failed to compile the name regex '%s' . Error: %w", name, err)
+		}
+		selector.names = append(selector.names, nameRegex)
+	}
+	return selector, nil
+}
+
+func (selector resourceSelector) matches(kind, name string) bool {
+	if len(selector.kinds) > 0 {
+		found := false
+		for _, k := range selector.kinds {
+			if strings.EqualFold(k, kind) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(selector.names) == 0 {
+		return true
+	}
+	for _, nameRegex := range selector.names {
+		if nameRegex.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// injectMetadata adds the labels and annotations of all the matching rules to the resource
+func injectMetadata(obj runtime.Object) {
+	if len(metadataInjectors) == 0 {
+		return
+	}
+	objMeta, err := meta.Accessor(obj)
+	if err != nil {
+		logrus.Debugf("failed to get the metadata of the object %+v . Error: %q", obj, err)
+		return
+	}
+	kind := obj.GetObjectKind().GroupVersionKind().Kind
+	for _, injector := range metadataInjectors {
+		if !injector.matches(kind, objMeta.GetName()) {
+			continue
+		}
+		if len(injector.labels) > 0 {
+			objLabels := objMeta.GetLabels()
+			if objLabels == nil {
+				objLabels = map[string]string{}
+			}
+			for k, v := range injector.labels {
+				objLabels[k] = v
+			}
+			objMeta.SetLabels(objLabels)
+		}
+		if len(injector.annotations) > 0 {
+			objAnnotations := objMeta.GetAnnotations()
+			if objAnnotations == nil {
+				objAnnotations = map[string]string{}
+			}
+			for k, v := range injector.annotations {
+				objAnnotations[k] = v
+			}
+			objMeta.SetAnnotations(objAnnotations)
+		}
+	}
+}
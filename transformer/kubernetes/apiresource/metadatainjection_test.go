@@ -0,0 +1,75 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestInjectMetadata(t *testing.T) {
+	dir := t.TempDir()
+	config := `apiVersion: move2kube.konveyor.io/v1alpha1
+kind: MetadataInjection
+metadata:
+  name: ownership
+spec:
+  rules:
+    - labels:
+        team: payments
+    - kinds: [service]
+      names: ["front.*"]
+      annotations:
+        owner: web
+    - names: ["[invalid"]
+      labels:
+        invalid: "true"
+`
+	if err := os.WriteFile(filepath.Join(dir, "metadata.yaml"), []byte(config), 0644); err != nil {
+		t.Fatalf("failed to write the config. Error: %q", err)
+	}
+	if err := LoadMetadataInjections(dir); err != nil {
+		t.Fatalf("failed to load the metadata injection configs. Error: %q", err)
+	}
+	defer LoadMetadataInjections("")
+	if len(metadataInjectors) != 2 {
+		t.Fatalf("expected the invalid rule to be skipped. Actual: %+v", metadataInjectors)
+	}
+
+	frontend := createService("frontend", nil).(*v1.Service)
+	frontend.Labels = map[string]string{"app": "frontend"}
+	injectMetadata(frontend)
+	if want := map[string]string{"app": "frontend", "team": "payments"}; !cmp.Equal(frontend.Labels, want) {
+		t.Fatalf("wrong labels. Differences: %s", cmp.Diff(want, frontend.Labels))
+	}
+	if want := map[string]string{"owner": "web"}; !cmp.Equal(frontend.Annotations, want) {
+		t.Fatalf("wrong annotations. Differences: %s", cmp.Diff(want, frontend.Annotations))
+	}
+
+	backend := createService("backend-frontend", nil).(*v1.Service)
+	injectMetadata(backend)
+	if want := map[string]string{"team": "payments"}; !cmp.Equal(backend.Labels, want) {
+		t.Fatalf("wrong labels. Differences: %s", cmp.Diff(want, backend.Labels))
+	}
+	if backend.Annotations != nil {
+		t.Fatalf("the name should match the whole name. Actual annotations: %+v", backend.Annotations)
+	}
+}
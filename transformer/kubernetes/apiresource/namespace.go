@@ -0,0 +1,168 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package apiresource
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/common/report"
+	"github.com/konveyor/move2kube/qaengine"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/konveyor/move2kube/types/qaengine/commonqa"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cast"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+const (
+	namespaceKind = "Namespace"
+	// podSecurityLabelPrefix is the prefix of the labels that set the Pod Security Standard levels of a namespace
+	podSecurityLabelPrefix = "pod-security.kubernetes.io/"
+	podSecurityRestricted  = "restricted"
+	podSecurityPrivileged  = "privileged"
+)
+
+// Namespace handles the Namespace that the resources are deployed to, like the namespace enforcing the restricted Pod Security Standard
+type Namespace struct {
+}
+
+// getSupportedKinds returns all kinds supported by the class
+func (n *Namespace) getSupportedKinds() []string {
+	return []string{namespaceKind}
+}
+
+// createNewResources creates a Namespace enforcing the restricted Pod Security Standard, or else the namespace of the project
+func (n *Namespace) createNewResources(ir irtypes.EnhancedIR, supportedKinds []string, targetCluster collecttypes.ClusterMetadata) []runtime.Object {
+	if len(ir.Services) == 0 {
+		return nil
+	}
+	if !commonqa.PodSecurityRestricted() {
+		return n.createProjectNamespace(ir, supportedKinds)
+	}
+	name := getPodSecurityNamespace()
+	if name == "" {
+		return n.createProjectNamespace(ir, supportedKinds)
+	}
+	if !common.IsPresent(supportedKinds, namespaceKind) {
+		logrus.Errorf("Could not find a valid resource type in cluster to create a Namespace")
+		return nil
+	}
+	labels := map[string]string{}
+	for _, mode := range []string{"enforce", "audit", "warn"} {
+		labels[podSecurityLabelPrefix+mode] = podSecurityRestricted
+		labels[podSecurityLabelPrefix+mode+"-version"] = "latest"
+	}
+	return []runtime.Object{&core.Namespace{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       namespaceKind,
+			APIVersion: core.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+	}}
+}
+
+// createProjectNamespace creates the namespace the resources are deployed to, like the namespace of a compose project.
+// The services exempted from the Pod Security Standard level of the namespace get their own namespaces with the lower levels,
+// so that the level of the namespace of the other services is not lowered.
+func (n *Namespace) createProjectNamespace(ir irtypes.EnhancedIR, supportedKinds []string) []runtime.Object {
+	name := getProjectNamespace(ir)
+	levels := []string{}
+	for _, serviceName := range ir.GetSortedServiceNames() {
+		level := getPodSecurityExemptionLevel(ir.Services[serviceName])
+		if level == "" {
+			continue
+		}
+		if name == "" {
+			logrus.Warnf("The namespace to deploy to is not known. Deploy the service %s to a namespace with the Pod Security Standard level %s manually.", serviceName, level)
+			report.AddFollowUp(serviceName, fmt.Sprintf("Deploy the service to a namespace labelled with %senforce=%s so that its capabilities are allowed", podSecurityLabelPrefix, level))
+			continue
+		}
+		exemptedNamespace := getExemptedNamespace(name, level)
+		report.AddFollowUp(serviceName, fmt.Sprintf("The service is deployed to the namespace %s with the Pod Security Standard level %s. Refer to it from the other services as %s.%s", exemptedNamespace, level, serviceName, exemptedNamespace))
+		levels = common.AppendIfNotPresent(levels, level)
+	}
+	if name == "" {
+		return nil
+	}
+	if !common.IsPresent(supportedKinds, namespaceKind) {
+		logrus.Errorf("Could not find a valid resource type in cluster to create a Namespace")
+		return nil
+	}
+	sort.Strings(levels)
+	objs := []runtime.Object{&core.Namespace{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       namespaceKind,
+			APIVersion: core.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+	}}
+	for _, level := range levels {
+		objs = append(objs, &core.Namespace{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       namespaceKind,
+				APIVersion: core.SchemeGroupVersion.String(),
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name: getExemptedNamespace(name, level),
+				Labels: map[string]string{
+					podSecurityLabelPrefix + "enforce":         level,
+					podSecurityLabelPrefix + "enforce-version": "latest",
+				},
+			},
+		})
+	}
+	return objs
+}
+
+// getPodSecurityNamespace returns the name of the namespace to generate with the restricted Pod Security Standard enforcement level.
+// The workloads are deployed to this namespace so that the level applies to them.
+func getPodSecurityNamespace() string {
+	return qaengine.FetchStringAnswer(
+		common.ConfigPodSecurityNamespaceKey,
+		"Enter the name of the namespace to generate with the restricted enforcement level :",
+		[]string{
+			"The namespace rejects the pods that do not comply with the restricted Pod Security Standard.",
+			"The generated resources are deployed to this namespace.",
+			"Leave empty to not generate a namespace.",
+		},
+		"",
+		func(name interface{}) error {
+			nameStr := cast.ToString(name)
+			if nameStr == "" {
+				return nil
+			}
+			if errs := validation.IsDNS1123Label(nameStr); len(errs) != 0 {
+				return fmt.Errorf("the namespace name '%s' is invalid. %s", nameStr, strings.Join(errs, ". "))
+			}
+			return nil
+		},
+	)
+}
+
+// convertToClusterSupportedKinds converts kinds to cluster supported kinds
+func (n *Namespace) convertToClusterSupportedKinds(obj runtime.Object, supportedKinds []string, otherobjs []runtime.Object, _ irtypes.EnhancedIR, targetCluster collecttypes.ClusterMetadata) ([]runtime.Object, bool) {
+	if common.IsPresent(n.getSupportedKinds(), obj.GetObjectKind().GroupVersionKind().Kind) {
+		return []runtime.Object{obj}, true
+	}
+	return nil, false
+}
@@ -49,7 +49,8 @@ func (d *NetworkPolicy) createNewResources(ir irtypes.EnhancedIR, supportedKinds
 		return nil
 	}
 
-	for _, service := range ir.Services {
+	for _, serviceName := range ir.GetSortedServiceNames() {
+		service := ir.Services[serviceName]
 		// Create services depending on whether the service needs to be externally exposed
 		for _, net := range service.Networks {
 			logrus.Debugf("Network %s is detected at Source, shall be converted to equivalent NetworkPolicy at Destination", net)
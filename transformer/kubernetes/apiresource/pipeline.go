@@ -19,11 +19,13 @@ package apiresource
 import (
 	"fmt"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/konveyor/move2kube/common"
 	collecttypes "github.com/konveyor/move2kube/types/collection"
 	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/konveyor/move2kube/types/qaengine/commonqa"
 	"github.com/sirupsen/logrus"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -89,7 +91,14 @@ func (*Pipeline) createNewResource(irpipeline irtypes.Pipeline, ir irtypes.Enhan
 	containerIndex := 0
 	gitNeedsSSHCreds := false
 	gitNeedsBasicAuthCreds := false
-	for imageName, container := range ir.ContainerImages {
+	imageTag := commonqa.ImageTag()
+	imageNames := []string{}
+	for imageName := range ir.ContainerImages {
+		imageNames = append(imageNames, imageName)
+	}
+	sort.Strings(imageNames)
+	for _, imageName := range imageNames {
+		container := ir.ContainerImages[imageName]
 		if container.Build.ContainerBuildType == "" {
 			continue
 		}
@@ -207,7 +216,7 @@ func (*Pipeline) createNewResource(irpipeline irtypes.Pipeline, ir irtypes.Enhan
 					{Name: "dockerconfig", Workspace: registryCredsWorkspace},
 				},
 				Params: []v1beta1.Param{
-					{Name: "IMAGE", Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: "$(params.image-registry-url)/" + imageName}},
+					{Name: "IMAGE", Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: "$(params.image-registry-url)/" + commonqa.GetTaggedImageName(imageName, imageTag)}},
 					{Name: "DOCKERFILE", Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: dockerfilePath}},
 					{Name: "CONTEXT", Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: contextPath}},
 				},
@@ -0,0 +1,155 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	policyExceptionKind       = "PolicyException"
+	policyExceptionAPIVersion = "kyverno.io/v2beta1"
+)
+
+var (
+	// kyvernoPolicyRules are the names of the rules of the capability policies in the Pod Security Standard library of Kyverno
+	kyvernoPolicyRules = map[string][]string{
+		"disallow-capabilities":        {"adding-capabilities", "autogen-adding-capabilities"},
+		"disallow-capabilities-strict": {"require-drop-all", "autogen-require-drop-all", "adding-capabilities-strict", "autogen-adding-capabilities-strict"},
+	}
+	// policyExceptionKinds are the kinds of the resources creating the pods of the services
+	policyExceptionKinds = []string{"Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "Job", "CronJob"}
+)
+
+// PolicyException handles the Kyverno PolicyExceptions exempting the pods of the services from the policies restricting the capabilities
+type PolicyException struct {
+}
+
+// policyException is a Kyverno PolicyException
+type policyException struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              policyExceptionSpec `json:"spec"`
+}
+
+type policyExceptionSpec struct {
+	Exceptions []policyExceptionRules `json:"exceptions"`
+	Match      policyExceptionMatch   `json:"match"`
+}
+
+type policyExceptionRules struct {
+	PolicyName string   `json:"policyName"`
+	RuleNames  []string `json:"ruleNames"`
+}
+
+type policyExceptionMatch struct {
+	Any []policyExceptionResourceFilter `json:"any"`
+}
+
+type policyExceptionResourceFilter struct {
+	Resources policyExceptionResources `json:"resources"`
+}
+
+type policyExceptionResources struct {
+	Kinds    []string              `json:"kinds"`
+	Names    []string              `json:"names,omitempty"`
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+// DeepCopyObject implements the runtime.Object interface
+func (e *policyException) DeepCopyObject() runtime.Object {
+	newException := &policyException{TypeMeta: e.TypeMeta}
+	e.ObjectMeta.DeepCopyInto(&newException.ObjectMeta)
+	for _, rules := range e.Spec.Exceptions {
+		newException.Spec.Exceptions = append(newException.Spec.Exceptions, policyExceptionRules{PolicyName: rules.PolicyName, RuleNames: append([]string{}, rules.RuleNames...)})
+	}
+	for _, filter := range e.Spec.Match.Any {
+		newException.Spec.Match.Any = append(newException.Spec.Match.Any, policyExceptionResourceFilter{Resources: policyExceptionResources{
+			Kinds:    append([]string{}, filter.Resources.Kinds...),
+			Names:    append([]string{}, filter.Resources.Names...),
+			Selector: filter.Resources.Selector.DeepCopy(),
+		}})
+	}
+	return newException
+}
+
+// getSupportedKinds returns all kinds supported by the class
+func (p *PolicyException) getSupportedKinds() []string {
+	return []string{policyExceptionKind}
+}
+
+// createNewResources creates a PolicyException for each service exempted from the Kyverno policies restricting the capabilities
+func (p *PolicyException) createNewResources(ir irtypes.EnhancedIR, supportedKinds []string, targetCluster collecttypes.ClusterMetadata) []runtime.Object {
+	apiVersion := policyExceptionAPIVersion
+	if versions := targetCluster.Spec.GetSupportedVersions(policyExceptionKind); versions != nil {
+		apiVersion = versions[0]
+	}
+	objs := []runtime.Object{}
+	for _, serviceName := range ir.GetSortedServiceNames() {
+		service := ir.Services[serviceName]
+		exceptions := []policyExceptionRules{}
+		for _, exemption := range service.PolicyExemptions {
+			if !strings.HasPrefix(exemption, collecttypes.KyvernoPolicyPrefix) {
+				continue
+			}
+			policyName := strings.TrimPrefix(exemption, collecttypes.KyvernoPolicyPrefix)
+			ruleNames, ok := kyvernoPolicyRules[policyName]
+			if !ok {
+				logrus.Warnf("The rules of the Kyverno policy '%s' are not known. Skipping the exception of the service '%s' .", policyName, serviceName)
+				continue
+			}
+			exceptions = append(exceptions, policyExceptionRules{PolicyName: policyName, RuleNames: ruleNames})
+		}
+		if len(exceptions) == 0 {
+			continue
+		}
+		objs = append(objs, &policyException{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       policyExceptionKind,
+				APIVersion: apiVersion,
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   common.MakeStringDNSSubdomainNameCompliant(service.Name + "-capabilities"),
+				Labels: getServiceLabels(service.Name),
+			},
+			Spec: policyExceptionSpec{
+				Exceptions: exceptions,
+				Match: policyExceptionMatch{Any: []policyExceptionResourceFilter{
+					{Resources: policyExceptionResources{Kinds: policyExceptionKinds, Names: []string{service.Name}}},
+					// the names of the pods created by the controllers are generated, so the pods are matched using the labels of the service
+					{Resources: policyExceptionResources{Kinds: []string{"Pod"}, Selector: &metav1.LabelSelector{MatchLabels: getServiceLabels(service.Name)}}},
+				}},
+			},
+		})
+	}
+	return objs
+}
+
+// convertToClusterSupportedKinds converts kinds to cluster supported kinds
+func (p *PolicyException) convertToClusterSupportedKinds(obj runtime.Object, supportedKinds []string, otherobjs []runtime.Object, _ irtypes.EnhancedIR, targetCluster collecttypes.ClusterMetadata) ([]runtime.Object, bool) {
+	if common.IsPresent(p.getSupportedKinds(), obj.GetObjectKind().GroupVersionKind().Kind) {
+		return []runtime.Object{obj}, true
+	}
+	return nil, false
+}
@@ -0,0 +1,53 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCreatePolicyExceptions(t *testing.T) {
+	ir := irtypes.NewIR()
+	vpn := irtypes.NewServiceWithName("vpn")
+	vpn.PolicyExemptions = []string{"pod-security/privileged", "kyverno/disallow-capabilities", "kyverno/unknown-policy"}
+	ir.Services["vpn"] = vpn
+	ir.Services["web"] = irtypes.NewServiceWithName("web")
+	objs := (&PolicyException{}).createNewResources(irtypes.NewEnhancedIRFromIR(ir), []string{policyExceptionKind}, collection.ClusterMetadata{})
+	if len(objs) != 1 {
+		t.Fatalf("expected a policy exception for the exempted service. Actual: %+v", objs)
+	}
+	exception, ok := objs[0].(*policyException)
+	if !ok {
+		t.Fatalf("expected a policy exception. Actual: %T", objs[0])
+	}
+	want := []policyExceptionRules{{PolicyName: "disallow-capabilities", RuleNames: []string{"adding-capabilities", "autogen-adding-capabilities"}}}
+	if diff := cmp.Diff(want, exception.Spec.Exceptions); diff != "" {
+		t.Fatalf("wrong exceptions. Difference:\n%s", diff)
+	}
+	wantMatch := policyExceptionMatch{Any: []policyExceptionResourceFilter{
+		{Resources: policyExceptionResources{Kinds: policyExceptionKinds, Names: []string{"vpn"}}},
+		{Resources: policyExceptionResources{Kinds: []string{"Pod"}, Selector: &metav1.LabelSelector{MatchLabels: getServiceLabels("vpn")}}},
+	}}
+	if diff := cmp.Diff(wantMatch, exception.Spec.Match); diff != "" {
+		t.Fatalf("wrong resources. Difference:\n%s", diff)
+	}
+}
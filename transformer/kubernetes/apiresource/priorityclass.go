@@ -0,0 +1,92 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"github.com/konveyor/move2kube/common"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/konveyor/move2kube/types/qaengine/commonqa"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/kubernetes/pkg/apis/scheduling"
+)
+
+const (
+	priorityClassKind = "PriorityClass"
+	// defaultPriorityClassValue and priorityClassValueStep give the priority classes decreasing values in the order they were listed
+	defaultPriorityClassValue int32 = 1000000
+	priorityClassValueStep    int32 = 1000
+)
+
+// PriorityClass handles the PriorityClass objects
+type PriorityClass struct {
+}
+
+// getSupportedKinds returns all kinds supported by the class
+func (p *PriorityClass) getSupportedKinds() []string {
+	return []string{priorityClassKind}
+}
+
+// createNewResources creates the priority classes assigned to the services that are not in the cluster
+func (p *PriorityClass) createNewResources(ir irtypes.EnhancedIR, supportedKinds []string, targetCluster collecttypes.ClusterMetadata) []runtime.Object {
+	assignedPriorityClasses := map[string]bool{}
+	for _, serviceName := range ir.GetSortedServiceNames() {
+		service := ir.Services[serviceName]
+		if service.PriorityClassName != "" && !common.IsPresent(targetCluster.Spec.PriorityClasses, service.PriorityClassName) {
+			assignedPriorityClasses[service.PriorityClassName] = true
+		}
+	}
+	if len(assignedPriorityClasses) == 0 || !commonqa.GeneratePriorityClasses() {
+		return nil
+	}
+	if !common.IsPresent(supportedKinds, priorityClassKind) {
+		logrus.Errorf("Could not find a valid resource type in cluster to create a PriorityClass")
+		return nil
+	}
+	objs := []runtime.Object{}
+	for i, priorityClassName := range commonqa.PriorityClasses(targetCluster.Spec.PriorityClasses) {
+		if !assignedPriorityClasses[priorityClassName] {
+			continue
+		}
+		value := commonqa.PriorityClassValue(priorityClassName, defaultPriorityClassValue-int32(i)*priorityClassValueStep)
+		objs = append(objs, p.createPriorityClass(priorityClassName, value))
+	}
+	return objs
+}
+
+// convertToClusterSupportedKinds converts kinds to cluster supported kinds
+func (p *PriorityClass) convertToClusterSupportedKinds(obj runtime.Object, supportedKinds []string, otherobjs []runtime.Object, _ irtypes.EnhancedIR, targetCluster collecttypes.ClusterMetadata) ([]runtime.Object, bool) {
+	if common.IsPresent(p.getSupportedKinds(), obj.GetObjectKind().GroupVersionKind().Kind) {
+		return []runtime.Object{obj}, true
+	}
+	return nil, false
+}
+
+func (p *PriorityClass) createPriorityClass(name string, value int32) *scheduling.PriorityClass {
+	return &scheduling.PriorityClass{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       priorityClassKind,
+			APIVersion: scheduling.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Value: value,
+	}
+}
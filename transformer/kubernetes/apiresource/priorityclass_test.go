@@ -0,0 +1,58 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"testing"
+
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"k8s.io/kubernetes/pkg/apis/scheduling"
+)
+
+func TestCreatePriorityClasses(t *testing.T) {
+	qaengine.ResetEngines()
+	defer qaengine.ResetEngines()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", []string{`move2kube.priorityclasses.names="critical,normal,batch"`, `move2kube.priorityclasses.generate=true`, `move2kube.priorityclasses.values."batch"="10"`}, nil, nil, false)
+	ir := irtypes.NewIR()
+	for serviceName, priorityClassName := range map[string]string{"web": "critical", "api": "critical", "worker": "batch", "db": ""} {
+		service := irtypes.NewServiceWithName(serviceName)
+		service.PriorityClassName = priorityClassName
+		ir.Services[serviceName] = service
+	}
+	objs := (&PriorityClass{}).createNewResources(irtypes.NewEnhancedIRFromIR(ir), []string{priorityClassKind}, collection.ClusterMetadata{})
+	want := map[string]int32{"critical": 1000000, "batch": 10}
+	if len(objs) != len(want) {
+		t.Fatalf("expected a priority class for each of the assigned priority classes. Actual: %+v", objs)
+	}
+	for _, obj := range objs {
+		priorityClass, ok := obj.(*scheduling.PriorityClass)
+		if !ok {
+			t.Fatalf("expected a priority class. Actual: %T", obj)
+		}
+		if value, ok := want[priorityClass.Name]; !ok || priorityClass.Value != value {
+			t.Fatalf("wrong priority class. Actual: %+v", priorityClass)
+		}
+	}
+
+	targetCluster := collection.ClusterMetadata{Spec: collection.ClusterMetadataSpec{PriorityClasses: []string{"critical", "batch"}}}
+	if objs := (&PriorityClass{}).createNewResources(irtypes.NewEnhancedIRFromIR(ir), []string{priorityClassKind}, targetCluster); len(objs) != 0 {
+		t.Fatalf("expected no priority classes to be created for the ones in the cluster. Actual: %+v", objs)
+	}
+}
@@ -0,0 +1,115 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/konveyor/move2kube/types/qaengine/commonqa"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// clusterScopedKinds are the kinds of the generated resources that do not belong to a namespace
+var clusterScopedKinds = []string{
+	"Namespace", "PriorityClass", "ClusterRole", "ClusterRoleBinding", "ClusterIssuer", "StorageClass",
+	"PersistentVolume", "CustomResourceDefinition", "ClusterTask", "ClusterTriggerBinding",
+}
+
+// getProjectNamespace returns the namespace the resources are deployed to when the services belong to projects, like compose projects.
+// The namespace defaults to the name of the project if all the services belong to the same project.
+// The namespace generated with the restricted Pod Security Standard enforcement level takes precedence, so that the level applies to the workloads.
+func getProjectNamespace(ir irtypes.EnhancedIR) string {
+	if len(ir.Services) != 0 && commonqa.PodSecurityRestricted() {
+		if namespace := getPodSecurityNamespace(); namespace != "" {
+			return namespace
+		}
+	}
+	projects := []string{}
+	for _, serviceName := range ir.GetSortedServiceNames() {
+		service := ir.Services[serviceName]
+		if service.Project != "" {
+			projects = common.AppendIfNotPresent(projects, service.Project)
+		}
+	}
+	if len(projects) == 0 {
+		return ""
+	}
+	sort.Strings(projects)
+	defaultNamespace := ""
+	if len(projects) == 1 {
+		defaultNamespace = projects[0]
+	}
+	return commonqa.Namespace(defaultNamespace)
+}
+
+// addProjectMetadata puts the namespaced resource in the namespace and labels the resources of a service with the project of the service.
+// The service of a resource is found using the service label, falling back to the name of the resource.
+// The resources of the services exempted from the Pod Security Standard level of the namespace are put in the namespace with their level.
+func addProjectMetadata(obj runtime.Object, ir irtypes.EnhancedIR, namespace string) {
+	objMeta, err := meta.Accessor(obj)
+	if err != nil {
+		logrus.Debugf("failed to get the metadata of the object %+v . Error: %q", obj, err)
+		return
+	}
+	serviceName, ok := objMeta.GetLabels()[selector]
+	if !ok {
+		serviceName = objMeta.GetName()
+	}
+	service, isServiceResource := ir.Services[serviceName]
+	if namespace != "" && objMeta.GetNamespace() == "" && !common.IsPresent(clusterScopedKinds, obj.GetObjectKind().GroupVersionKind().Kind) {
+		if level := getPodSecurityExemptionLevel(service); isServiceResource && level != "" {
+			objMeta.SetNamespace(getExemptedNamespace(namespace, level))
+		} else {
+			objMeta.SetNamespace(namespace)
+		}
+	}
+	if !isServiceResource || service.Project == "" {
+		return
+	}
+	labels := objMeta.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[common.InstanceLabel] = service.Project
+	objMeta.SetLabels(labels)
+}
+
+// getPodSecurityExemptionLevel returns the Pod Security Standard level the service is exempted to, or an empty string if it is not exempted
+func getPodSecurityExemptionLevel(service irtypes.Service) string {
+	level := ""
+	for _, exemption := range service.PolicyExemptions {
+		if !strings.HasPrefix(exemption, collecttypes.PodSecurityPolicyPrefix) {
+			continue
+		}
+		// the privileged level allows all the capabilities allowed by the baseline level
+		if level != podSecurityPrivileged {
+			level = strings.TrimPrefix(exemption, collecttypes.PodSecurityPolicyPrefix)
+		}
+	}
+	return level
+}
+
+// getExemptedNamespace returns the namespace with the lower Pod Security Standard level for the services exempted from the level of the namespace
+func getExemptedNamespace(namespace, level string) string {
+	return common.MakeStringDNSLabelNameCompliant(namespace + "-" + level)
+}
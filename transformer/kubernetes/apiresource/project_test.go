@@ -0,0 +1,130 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"testing"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/pkg/apis/apps"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestAddProjectMetadata(t *testing.T) {
+	ir := irtypes.NewIR()
+	web := irtypes.NewServiceWithName("web")
+	web.Project = "shop"
+	ir.Services["web"] = web
+	enhancedIR := irtypes.NewEnhancedIRFromIR(ir)
+
+	t.Run("resources of a service are labelled with its project and namespaced", func(t *testing.T) {
+		deployment := &apps.Deployment{
+			TypeMeta:   metav1.TypeMeta{Kind: "Deployment"},
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Labels: getServiceLabels("web")},
+		}
+		addProjectMetadata(deployment, enhancedIR, "shop")
+		if deployment.Labels[common.InstanceLabel] != "shop" {
+			t.Fatalf("expected the deployment to be labelled with the project. Actual: %+v", deployment.Labels)
+		}
+		if deployment.Namespace != "shop" {
+			t.Fatalf("expected the deployment to be in the namespace of the project. Actual: %s", deployment.Namespace)
+		}
+	})
+
+	t.Run("cluster scoped resources are not namespaced", func(t *testing.T) {
+		namespace := &core.Namespace{TypeMeta: metav1.TypeMeta{Kind: "Namespace"}, ObjectMeta: metav1.ObjectMeta{Name: "shop"}}
+		addProjectMetadata(namespace, enhancedIR, "shop")
+		if namespace.Namespace != "" {
+			t.Fatalf("expected the namespace to not be namespaced. Actual: %s", namespace.Namespace)
+		}
+		if _, ok := namespace.Labels[common.InstanceLabel]; ok {
+			t.Fatalf("expected the namespace to not be labelled with a project. Actual: %+v", namespace.Labels)
+		}
+	})
+}
+
+func TestGetProjectNamespace(t *testing.T) {
+	qaengine.ResetEngines()
+	defer qaengine.ResetEngines()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", []string{`move2kube.podsecurity.restricted=true`, `move2kube.podsecurity.namespace="restricted"`}, nil, nil, false)
+	ir := irtypes.NewIR()
+	web := irtypes.NewServiceWithName("web")
+	web.Project = "shop"
+	ir.Services["web"] = web
+	if namespace := getProjectNamespace(irtypes.NewEnhancedIRFromIR(ir)); namespace != "restricted" {
+		t.Fatalf("expected the workloads to be deployed to the namespace with the restricted level. Actual: %s", namespace)
+	}
+}
+
+func TestCreateProjectNamespace(t *testing.T) {
+	qaengine.ResetEngines()
+	defer qaengine.ResetEngines()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", []string{`move2kube.podsecurity.restricted=false`}, nil, nil, false)
+	ir := irtypes.NewIR()
+	web := irtypes.NewServiceWithName("web")
+	web.Project = "shop"
+	ir.Services["web"] = web
+	objs := (&Namespace{}).createNewResources(irtypes.NewEnhancedIRFromIR(ir), []string{namespaceKind}, collecttypes.ClusterMetadata{})
+	if len(objs) != 1 {
+		t.Fatalf("expected the namespace of the project to be created. Actual: %+v", objs)
+	}
+	if namespace, ok := objs[0].(*core.Namespace); !ok || namespace.Name != "shop" || len(namespace.Labels) != 0 {
+		t.Fatalf("expected the namespace named after the project. Actual: %+v", objs[0])
+	}
+}
+
+func TestCreateExemptedNamespace(t *testing.T) {
+	qaengine.ResetEngines()
+	defer qaengine.ResetEngines()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", []string{`move2kube.podsecurity.restricted=false`}, nil, nil, false)
+	ir := irtypes.NewIR()
+	web := irtypes.NewServiceWithName("web")
+	web.Project = "shop"
+	ir.Services["web"] = web
+	vpn := irtypes.NewServiceWithName("vpn")
+	vpn.Project = "shop"
+	vpn.PolicyExemptions = []string{"pod-security/baseline", "pod-security/privileged"}
+	ir.Services["vpn"] = vpn
+	enhancedIR := irtypes.NewEnhancedIRFromIR(ir)
+	objs := (&Namespace{}).createNewResources(enhancedIR, []string{namespaceKind}, collecttypes.ClusterMetadata{})
+	if len(objs) != 2 {
+		t.Fatalf("expected the namespace of the project and the namespace of the exempted service to be created. Actual: %+v", objs)
+	}
+	if namespace, ok := objs[0].(*core.Namespace); !ok || namespace.Name != "shop" || len(namespace.Labels) != 0 {
+		t.Fatalf("expected the namespace of the project to keep its level. Actual: %+v", objs[0])
+	}
+	if namespace, ok := objs[1].(*core.Namespace); !ok || namespace.Name != "shop-privileged" || namespace.Labels["pod-security.kubernetes.io/enforce"] != "privileged" {
+		t.Fatalf("expected the namespace with the privileged level. Actual: %+v", objs[1])
+	}
+	for name, wantNamespace := range map[string]string{"web": "shop", "vpn": "shop-privileged"} {
+		deployment := &apps.Deployment{
+			TypeMeta:   metav1.TypeMeta{Kind: "Deployment"},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: getServiceLabels(name)},
+		}
+		addProjectMetadata(deployment, enhancedIR, "shop")
+		if deployment.Namespace != wantNamespace {
+			t.Fatalf("expected the deployment of the service %s to be in the namespace %s. Actual: %s", name, wantNamespace, deployment.Namespace)
+		}
+	}
+}
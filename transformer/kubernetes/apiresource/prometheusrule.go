@@ -0,0 +1,206 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	prometheusRuleKind       = "PrometheusRule"
+	prometheusRuleAPIVersion = "monitoring.coreos.com/v1"
+	// podRestartsAlertThreshold is the number of restarts in the window after which an alert fires
+	podRestartsAlertThreshold = 3
+	// probeFailuresAlertThreshold is the number of failed probes in the window after which an alert fires
+	probeFailuresAlertThreshold = 3
+	alertSeverityLabel          = "severity"
+	alertSeverityWarning        = "warning"
+	alertSeverityCritical       = "critical"
+)
+
+var invalidAlertNameChars = regexp.MustCompile(`[^a-zA-Z0-9_]+`)
+
+// PrometheusRule handles the PrometheusRule objects of the Prometheus operator
+type PrometheusRule struct {
+}
+
+// prometheusRule is a PrometheusRule of the Prometheus operator containing the alerting rules for a service
+type prometheusRule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              prometheusRuleSpec `json:"spec"`
+}
+
+type prometheusRuleSpec struct {
+	Groups []prometheusRuleGroup `json:"groups"`
+}
+
+type prometheusRuleGroup struct {
+	Name  string                `json:"name"`
+	Rules []prometheusAlertRule `json:"rules"`
+}
+
+type prometheusAlertRule struct {
+	Alert       string            `json:"alert"`
+	Expr        string            `json:"expr"`
+	For         string            `json:"for,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// DeepCopyObject implements the runtime.Object interface
+func (r *prometheusRule) DeepCopyObject() runtime.Object {
+	newRule := &prometheusRule{TypeMeta: r.TypeMeta}
+	r.ObjectMeta.DeepCopyInto(&newRule.ObjectMeta)
+	for _, group := range r.Spec.Groups {
+		newGroup := prometheusRuleGroup{Name: group.Name}
+		for _, rule := range group.Rules {
+			rule.Labels = common.MergeStringMaps(map[string]string{}, rule.Labels)
+			rule.Annotations = common.MergeStringMaps(map[string]string{}, rule.Annotations)
+			newGroup.Rules = append(newGroup.Rules, rule)
+		}
+		newRule.Spec.Groups = append(newRule.Spec.Groups, newGroup)
+	}
+	return newRule
+}
+
+// getSupportedKinds returns all kinds supported by the class
+func (p *PrometheusRule) getSupportedKinds() []string {
+	return []string{prometheusRuleKind}
+}
+
+// createNewResources creates the alerting rules for the restarts, the unavailable replicas and the probe failures of each service
+func (p *PrometheusRule) createNewResources(ir irtypes.EnhancedIR, supportedKinds []string, targetCluster collecttypes.ClusterMetadata) []runtime.Object {
+	if len(ir.Services) == 0 {
+		return nil
+	}
+	// Since the Prometheus operator is an extension, the supported kinds are ignored and it is upto the user to install it.
+	if !qaengine.FetchBoolAnswer(
+		common.ConfigMonitoringAlertsKey,
+		"Do you want to generate Prometheus alerting rules for the services?",
+		[]string{"The PrometheusRule resources need the Prometheus operator and the metrics from kube-state-metrics"},
+		false,
+		nil,
+	) {
+		return nil
+	}
+	objs := []runtime.Object{}
+	for _, serviceName := range ir.GetSortedServiceNames() {
+		service := ir.Services[serviceName]
+		if service.OnlyIngress {
+			continue
+		}
+		objs = append(objs, p.createPrometheusRule(service))
+	}
+	return objs
+}
+
+// convertToClusterSupportedKinds converts kinds to cluster supported kinds
+func (p *PrometheusRule) convertToClusterSupportedKinds(obj runtime.Object, supportedKinds []string, otherobjs []runtime.Object, _ irtypes.EnhancedIR, targetCluster collecttypes.ClusterMetadata) ([]runtime.Object, bool) {
+	if common.IsPresent(p.getSupportedKinds(), obj.GetObjectKind().GroupVersionKind().Kind) {
+		return []runtime.Object{obj}, true
+	}
+	return nil, false
+}
+
+func (p *PrometheusRule) createPrometheusRule(service irtypes.Service) *prometheusRule {
+	podSelector := fmt.Sprintf(`pod=~"%s-.*"`, regexp.QuoteMeta(service.Name))
+	rules := []prometheusAlertRule{{
+		Alert:       getAlertName(service.Name, "PodRestarting"),
+		Expr:        fmt.Sprintf("increase(kube_pod_container_status_restarts_total{%s}[15m]) > %d", podSelector, podRestartsAlertThreshold),
+		For:         "5m",
+		Labels:      map[string]string{alertSeverityLabel: alertSeverityWarning},
+		Annotations: map[string]string{"summary": fmt.Sprintf("The containers of %s are restarting frequently", service.Name)},
+	}}
+	if expr := getReplicasUnavailableExpr(service, podSelector); expr != "" {
+		rules = append(rules, prometheusAlertRule{
+			Alert:       getAlertName(service.Name, "ReplicasUnavailable"),
+			Expr:        expr,
+			For:         "10m",
+			Labels:      map[string]string{alertSeverityLabel: alertSeverityCritical},
+			Annotations: map[string]string{"summary": fmt.Sprintf("Some of the replicas of %s are unavailable", service.Name)},
+		})
+	}
+	if hasProbes(service) {
+		rules = append(rules, prometheusAlertRule{
+			Alert:       getAlertName(service.Name, "ProbeFailing"),
+			Expr:        fmt.Sprintf(`increase(prober_probe_total{result="failed",%s}[10m]) > %d`, podSelector, probeFailuresAlertThreshold),
+			For:         "5m",
+			Labels:      map[string]string{alertSeverityLabel: alertSeverityWarning},
+			Annotations: map[string]string{"summary": fmt.Sprintf("The health checks of %s are failing", service.Name)},
+		})
+	}
+	return &prometheusRule{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       prometheusRuleKind,
+			APIVersion: prometheusRuleAPIVersion,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   service.Name + "-alerts",
+			Labels: getServiceLabels(service.Name),
+		},
+		Spec: prometheusRuleSpec{Groups: []prometheusRuleGroup{{Name: service.Name, Rules: rules}}},
+	}
+}
+
+// getAlertName returns the name of the alert in camel case, since the alert names cannot contain hyphens
+func getAlertName(serviceName, alert string) string {
+	name := ""
+	for _, part := range invalidAlertNameChars.Split(serviceName, -1) {
+		if part != "" {
+			name += strings.ToUpper(part[:1]) + part[1:]
+		}
+	}
+	return name + alert
+}
+
+// getReplicasUnavailableExpr returns the expression that checks the available replicas of the controller of the service
+func getReplicasUnavailableExpr(service irtypes.Service, podSelector string) string {
+	if service.Daemon {
+		return fmt.Sprintf(`kube_daemonset_status_number_unavailable{daemonset="%s"} > 0`, service.Name)
+	}
+	switch service.DeploymentType {
+	case irtypes.DeploymentTypeStatefulSet:
+		return fmt.Sprintf(`kube_statefulset_status_replicas_ready{statefulset="%[1]s"} < kube_statefulset_status_replicas{statefulset="%[1]s"}`, service.Name)
+	case irtypes.DeploymentTypeArgoRollout:
+		replicas := service.Replicas
+		if replicas < 1 {
+			replicas = 1
+		}
+		return fmt.Sprintf(`count(kube_pod_status_ready{condition="true",%s} == 1) < %d`, podSelector, replicas)
+	}
+	return fmt.Sprintf(`kube_deployment_status_replicas_unavailable{deployment="%s"} > 0`, service.Name)
+}
+
+// hasProbes returns true if any of the containers of the service has a health check
+func hasProbes(service irtypes.Service) bool {
+	for _, container := range service.Containers {
+		if container.LivenessProbe != nil || container.ReadinessProbe != nil || container.StartupProbe != nil {
+			return true
+		}
+	}
+	return false
+}
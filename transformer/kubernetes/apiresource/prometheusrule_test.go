@@ -0,0 +1,69 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"testing"
+
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestCreatePrometheusRules(t *testing.T) {
+	qaengine.ResetEngines()
+	defer qaengine.ResetEngines()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", []string{`move2kube.monitoring.alerts=true`}, nil, nil, false)
+	ir := irtypes.NewIR()
+	web := irtypes.NewServiceWithName("my-web")
+	web.Containers = []core.Container{{Name: "web", LivenessProbe: &core.Probe{}}}
+	ir.Services["my-web"] = web
+	db := irtypes.NewServiceWithName("db")
+	db.DeploymentType = irtypes.DeploymentTypeStatefulSet
+	ir.Services["db"] = db
+	objs := (&PrometheusRule{}).createNewResources(irtypes.NewEnhancedIRFromIR(ir), nil, collection.ClusterMetadata{})
+	if len(objs) != 2 {
+		t.Fatalf("expected a prometheus rule for each of the services. Actual: %+v", objs)
+	}
+	want := map[string][]string{
+		"db-alerts":     {"DbPodRestarting", "DbReplicasUnavailable"},
+		"my-web-alerts": {"MyWebPodRestarting", "MyWebReplicasUnavailable", "MyWebProbeFailing"},
+	}
+	for _, obj := range objs {
+		rule, ok := obj.(*prometheusRule)
+		if !ok {
+			t.Fatalf("expected a prometheus rule. Actual: %T", obj)
+		}
+		alerts := []string{}
+		for _, alertRule := range rule.Spec.Groups[0].Rules {
+			alerts = append(alerts, alertRule.Alert)
+		}
+		if wantAlerts, ok := want[rule.Name]; !ok || len(wantAlerts) != len(alerts) {
+			t.Fatalf("wrong alerts for the rule %s . Actual: %+v", rule.Name, alerts)
+		}
+		for i, alert := range want[rule.Name] {
+			if alerts[i] != alert {
+				t.Fatalf("wrong alerts for the rule %s . Expected: %+v Actual: %+v", rule.Name, want[rule.Name], alerts)
+			}
+		}
+	}
+	if expr := getReplicasUnavailableExpr(db, ""); expr != `kube_statefulset_status_replicas_ready{statefulset="db"} < kube_statefulset_status_replicas{statefulset="db"}` {
+		t.Fatalf("wrong expression for the stateful set. Actual: %s", expr)
+	}
+}
@@ -0,0 +1,85 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"fmt"
+	"strings"
+
+	semver "github.com/Masterminds/semver/v3"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/types/info"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// addProvenanceAnnotations annotates the resource with the transformer and the move2kube version that generated it.
+// The resources of a service are also annotated with the source files of the service and the transformer that converted them.
+// The service of a resource is found using the service label, falling back to the name of the resource.
+// Only the major and minor versions are recorded, so that the outputs do not change with every patch release.
+func addProvenanceAnnotations(obj runtime.Object, ir irtypes.EnhancedIR, transformer transformertypes.Transformer) {
+	objMeta, err := meta.Accessor(obj)
+	if err != nil {
+		logrus.Debugf("failed to get the metadata of the object %+v . Error: %q", obj, err)
+		return
+	}
+	annotations := objMeta.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	if transformer.Name != "" {
+		annotations[common.GeneratedByAnnotation] = transformer.Name
+		if transformerVersion := getTransformerVersion(transformer); transformerVersion != "" {
+			annotations[common.GeneratedByVersionAnnotation] = transformerVersion
+		}
+	}
+	annotations[common.Move2KubeVersionAnnotation] = getProvenanceVersion(info.GetVersion())
+	serviceName, ok := objMeta.GetLabels()[selector]
+	if !ok {
+		serviceName = objMeta.GetName()
+	}
+	if service, ok := ir.Services[serviceName]; ok {
+		if len(service.SourceFiles) > 0 {
+			annotations[common.SourceFilesAnnotation] = strings.Join(service.SourceFiles, ",")
+		}
+		if service.SourceTransformer != "" {
+			annotations[common.SourceTransformerAnnotation] = service.SourceTransformer
+		}
+	}
+	objMeta.SetAnnotations(annotations)
+}
+
+// getTransformerVersion returns the version of the transformer.
+// The built-in transformers have the version of move2kube, while the external transformers can have a version label.
+func getTransformerVersion(transformer transformertypes.Transformer) string {
+	if transformer.Labels[common.BuiltInTransformerLabel] == common.AnnotationLabelValue {
+		return getProvenanceVersion(info.GetVersion())
+	}
+	return transformer.Labels[common.TransformerVersionLabel]
+}
+
+// getProvenanceVersion returns the major and minor version of a semver version, or the version as it is if it is not a semver version
+func getProvenanceVersion(version string) string {
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return version
+	}
+	return fmt.Sprintf("v%d.%d", v.Major(), v.Minor())
+}
@@ -0,0 +1,94 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/pkg/apis/apps"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestProvenanceAnnotations(t *testing.T) {
+	ir := irtypes.NewIR()
+	web := irtypes.NewServiceWithName("web")
+	web.SourceFiles = []string{"docker-compose.yaml", "web/Dockerfile"}
+	web.SourceTransformer = "ComposeAnalyser"
+	ir.Services["web"] = web
+	enhancedIR := irtypes.NewEnhancedIRFromIR(ir)
+	builtInTransformer := transformertypes.NewTransformer()
+	builtInTransformer.Name = "Kubernetes"
+	builtInTransformer.Labels = map[string]string{common.BuiltInTransformerLabel: common.AnnotationLabelValue}
+
+	t.Run("resources of a service are linked to its source files", func(t *testing.T) {
+		deployment := &apps.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web-deployment", Labels: getServiceLabels("web"), Annotations: map[string]string{"existing": "kept"}}}
+		addProvenanceAnnotations(deployment, enhancedIR, builtInTransformer)
+		want := map[string]string{
+			"existing":                          "kept",
+			common.GeneratedByAnnotation:        "Kubernetes",
+			common.GeneratedByVersionAnnotation: "v0.3",
+			common.Move2KubeVersionAnnotation:   "v0.3",
+			common.SourceFilesAnnotation:        "docker-compose.yaml,web/Dockerfile",
+			common.SourceTransformerAnnotation:  "ComposeAnalyser",
+		}
+		if diff := cmp.Diff(want, deployment.Annotations); diff != "" {
+			t.Fatalf("wrong provenance annotations. Difference:\n%s", diff)
+		}
+	})
+
+	t.Run("resources not belonging to a service only record the generator", func(t *testing.T) {
+		namespace := &core.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "myproject"}}
+		addProvenanceAnnotations(namespace, enhancedIR, builtInTransformer)
+		want := map[string]string{
+			common.GeneratedByAnnotation:        "Kubernetes",
+			common.GeneratedByVersionAnnotation: "v0.3",
+			common.Move2KubeVersionAnnotation:   "v0.3",
+		}
+		if diff := cmp.Diff(want, namespace.Annotations); diff != "" {
+			t.Fatalf("wrong provenance annotations. Difference:\n%s", diff)
+		}
+	})
+
+	t.Run("external transformers record their version label", func(t *testing.T) {
+		externalTransformer := transformertypes.NewTransformer()
+		externalTransformer.Name = "MyTransformer"
+		externalTransformer.Labels = map[string]string{common.TransformerVersionLabel: "1.2.3"}
+		namespace := &core.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "myproject"}}
+		addProvenanceAnnotations(namespace, enhancedIR, externalTransformer)
+		want := map[string]string{
+			common.GeneratedByAnnotation:        "MyTransformer",
+			common.GeneratedByVersionAnnotation: "1.2.3",
+			common.Move2KubeVersionAnnotation:   "v0.3",
+		}
+		if diff := cmp.Diff(want, namespace.Annotations); diff != "" {
+			t.Fatalf("wrong provenance annotations. Difference:\n%s", diff)
+		}
+	})
+}
+
+func TestGetProvenanceVersion(t *testing.T) {
+	for version, want := range map[string]string{"v0.3.0": "v0.3", "v0.3.1+abc": "v0.3", "1.2.3-alpha.1": "v1.2", "latest": "latest"} {
+		if got := getProvenanceVersion(version); got != want {
+			t.Fatalf("wrong provenance version for the version '%s' . Expected: %s Actual: %s", version, want, got)
+		}
+	}
+}
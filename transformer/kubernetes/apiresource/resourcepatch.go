@@ -0,0 +1,172 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package apiresource
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/konveyor/move2kube/common"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+)
+
+// resourcePatcher applies a patch to the resources matching the kinds and names
+type resourcePatcher struct {
+	resourceSelector
+	patchType transformertypes.ResourcePatchType
+	patchJSON []byte
+	source    string
+}
+
+var resourcePatchers []resourcePatcher
+
+// LoadResourcePatches loads the patches from all the resource patch configs found in the directory.
+// The patches are applied to every resource that is written out afterwards.
+func LoadResourcePatches(dir string) error {
+	resourcePatchers = nil
+	if dir == "" {
+		return nil
+	}
+	yamlPaths, err := common.GetFilesByExt(dir, []string{".yml", ".yaml"})
+	if err != nil {
+		return fmt.Errorf("failed to look for yaml files in the directory '%s' . Error: %w", dir, err)
+	}
+	sort.Strings(yamlPaths)
+	for _, yamlPath := range yamlPaths {
+		rp := transformertypes.NewResourcePatch()
+		if err := common.ReadMove2KubeYaml(yamlPath, &rp); err != nil || rp.Kind != transformertypes.ResourcePatchKind {
+			continue
+		}
+		logrus.Debugf("found the resource patch config at path '%s'", yamlPath)
+		for patchIdx, patch := range rp.Spec.Patches {
+			patcher, err := newResourcePatcher(filepath.Dir(yamlPath), patch)
+			if err != nil {
+				logrus.Errorf("skipping the patch %d of the resource patch config at path '%s' . Error: %q", patchIdx, yamlPath, err)
+				continue
+			}
+			patcher.source = fmt.Sprintf("%s[%d]", yamlPath, patchIdx)
+			resourcePatchers = append(resourcePatchers, patcher)
+		}
+	}
+	return nil
+}
+
+func newResourcePatcher(dir string, patch transformertypes.ResourcePatchRule) (resourcePatcher, error) {
+	selector, err := newResourceSelector(patch.Kinds, patch.Names)
+	if err != nil {
+		return resourcePatcher{}, err
+	}
+	patcher := resourcePatcher{resourceSelector: selector, patchType: patch.Type}
+	if patcher.patchType == "" {
+		patcher.patchType = transformertypes.StrategicMergeResourcePatchType
+	}
+	if patcher.patchType != transformertypes.StrategicMergeResourcePatchType && patcher.patchType != transformertypes.JSON6902ResourcePatchType {
+		return patcher, fmt.Errorf("the patch type '%s' is not supported. Supported types: [%s, %s]", patcher.patchType,
+			transformertypes.StrategicMergeResourcePatchType, transformertypes.JSON6902ResourcePatchType)
+	}
+	patchYaml := []byte(patch.Patch)
+	if patch.Path != "" {
+		patchPath := patch.Path
+		if !filepath.IsAbs(patchPath) {
+			patchPath = filepath.Join(dir, patchPath)
+		}
+		if patchYaml, err = os.ReadFile(patchPath); err != nil {
+			return patcher, fmt.Errorf("failed to read the patch file at path '%s' . Error: %w", patchPath, err)
+		}
+	}
+	var patchI interface{}
+	if err := yaml.Unmarshal(patchYaml, &patchI); err != nil {
+		return patcher, fmt.Errorf("failed to unmarshal the patch as yaml. Error: %w", err)
+	}
+	if patchI == nil {
+		return patcher, fmt.Errorf("the patch is empty")
+	}
+	if patcher.patchJSON, err = json.Marshal(patchI); err != nil {
+		return patcher, fmt.Errorf("failed to marshal the patch to json. Error: %w", err)
+	}
+	if patcher.patchType == transformertypes.JSON6902ResourcePatchType {
+		if _, err := jsonpatch.DecodePatch(patcher.patchJSON); err != nil {
+			return patcher, fmt.Errorf("failed to decode the json patch. Error: %w", err)
+		}
+	}
+	return patcher, nil
+}
+
+func (patcher resourcePatcher) apply(obj runtime.Object, objJSON []byte) ([]byte, error) {
+	if patcher.patchType == transformertypes.JSON6902ResourcePatchType {
+		patch, err := jsonpatch.DecodePatch(patcher.patchJSON)
+		if err != nil {
+			return objJSON, err
+		}
+		return patch.Apply(objJSON)
+	}
+	if _, ok := obj.(runtime.Unstructured); ok {
+		return jsonpatch.MergePatch(objJSON, patcher.patchJSON)
+	}
+	return strategicpatch.StrategicMergePatch(objJSON, patcher.patchJSON, obj)
+}
+
+// patchResource applies all the matching patches to the resource and returns the patched resource
+func patchResource(obj runtime.Object) runtime.Object {
+	if len(resourcePatchers) == 0 {
+		return obj
+	}
+	objMeta, err := meta.Accessor(obj)
+	if err != nil {
+		logrus.Debugf("failed to get the metadata of the object %+v . Error: %q", obj, err)
+		return obj
+	}
+	kind := obj.GetObjectKind().GroupVersionKind().Kind
+	name := objMeta.GetName()
+	var objJSON []byte
+	for _, patcher := range resourcePatchers {
+		if !patcher.matches(kind, name) {
+			continue
+		}
+		if objJSON == nil {
+			if objJSON, err = json.Marshal(obj); err != nil {
+				logrus.Errorf("failed to marshal the %s '%s' to json. Error: %q", kind, name, err)
+				return obj
+			}
+		}
+		patchedJSON, err := patcher.apply(obj, objJSON)
+		if err != nil {
+			logrus.Errorf("failed to apply the patch %s to the %s '%s' . Error: %q", patcher.source, kind, name, err)
+			continue
+		}
+		logrus.Debugf("applied the patch %s to the %s '%s'", patcher.source, kind, name)
+		objJSON = patchedJSON
+	}
+	if objJSON == nil {
+		return obj
+	}
+	patchedObj := &unstructured.Unstructured{}
+	if err := patchedObj.UnmarshalJSON(objJSON); err != nil {
+		logrus.Errorf("failed to unmarshal the patched %s '%s' . Error: %q", kind, name, err)
+		return obj
+	}
+	return patchedObj
+}
@@ -0,0 +1,105 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package apiresource
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestPatchResource(t *testing.T) {
+	dir := t.TempDir()
+	config := `apiVersion: move2kube.konveyor.io/v1alpha1
+kind: ResourcePatch
+metadata:
+  name: org-policies
+spec:
+  patches:
+    - kinds: [deployment]
+      path: tolerations.yaml
+    - kinds: [deployment]
+      names: ["web"]
+      type: json6902
+      patch: |
+        - op: add
+          path: /spec/template/spec/containers/-
+          value:
+            name: proxy
+            image: proxy:1.0
+    - type: unknown
+      patch: "{}"
+`
+	tolerations := `spec:
+  template:
+    spec:
+      tolerations:
+        - key: dedicated
+          operator: Equal
+          value: apps
+          effect: NoSchedule
+      containers:
+        - name: web
+          imagePullPolicy: Always
+`
+	if err := os.WriteFile(filepath.Join(dir, "patches.yaml"), []byte(config), 0644); err != nil {
+		t.Fatalf("failed to write the config. Error: %q", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "tolerations.yaml"), []byte(tolerations), 0644); err != nil {
+		t.Fatalf("failed to write the patch. Error: %q", err)
+	}
+	if err := LoadResourcePatches(dir); err != nil {
+		t.Fatalf("failed to load the resource patch configs. Error: %q", err)
+	}
+	defer LoadResourcePatches("")
+	if len(resourcePatchers) != 2 {
+		t.Fatalf("expected the invalid patch to be skipped. Actual: %+v", resourcePatchers)
+	}
+
+	deployment := &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "web"},
+		Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "web", Image: "web:latest"}},
+		}}},
+	}
+	patched, ok := patchResource(deployment).(*unstructured.Unstructured)
+	if !ok {
+		t.Fatalf("expected the deployment to be patched")
+	}
+	containers, _, _ := unstructured.NestedSlice(patched.Object, "spec", "template", "spec", "containers")
+	if len(containers) != 2 {
+		t.Fatalf("expected the sidecar to be added. Actual: %+v", containers)
+	}
+	web := containers[0].(map[string]interface{})
+	if web["image"] != "web:latest" || web["imagePullPolicy"] != "Always" {
+		t.Fatalf("expected the container to be merged using its name. Actual: %+v", web)
+	}
+	tolerationsList, _, _ := unstructured.NestedSlice(patched.Object, "spec", "template", "spec", "tolerations")
+	if len(tolerationsList) != 1 {
+		t.Fatalf("expected the tolerations to be added. Actual: %+v", tolerationsList)
+	}
+
+	service := createService("web", nil)
+	if patchResource(service) != service {
+		t.Fatalf("expected the service to not be patched")
+	}
+}
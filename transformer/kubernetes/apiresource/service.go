@@ -18,6 +18,7 @@ package apiresource
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/konveyor/move2kube/common"
@@ -45,14 +46,17 @@ type Service struct {
 
 // getSupportedKinds returns supported kinds
 func (d *Service) getSupportedKinds() []string {
-	return []string{common.ServiceKind, common.IngressKind, routeKind}
+	return []string{common.ServiceKind, common.IngressKind, routeKind, certManagerIssuerKind, certManagerClusterIssuerKind}
 }
 
 // createNewResources converts IR to runtime objects
 func (d *Service) createNewResources(ir irtypes.EnhancedIR, supportedKinds []string, targetCluster collecttypes.ClusterMetadata) []runtime.Object {
 	objs := []runtime.Object{}
 	ingressEnabled := false
-	for _, service := range ir.Services {
+	// the edge terminated routes without a certificate get it from cert-manager
+	certlessRoutes := []*okdroutev1.Route{}
+	for _, serviceName := range ir.GetSortedServiceNames() {
+		service := ir.Services[serviceName]
 		exposeobjectcreated := false
 		if _, _, _, st := d.getExposeInfo(service); st != "" || service.OnlyIngress {
 			// Create services depending on whether the service needs to be externally exposed
@@ -61,6 +65,9 @@ func (d *Service) createNewResources(ir irtypes.EnhancedIR, supportedKinds []str
 				routeObjs := d.createRoutes(service, ir, targetCluster)
 				for _, routeObj := range routeObjs {
 					objs = append(objs, routeObj)
+					if routeObj.Spec.TLS != nil && routeObj.Spec.TLS.Termination == okdroutev1.TLSTerminationEdge && routeObj.Spec.TLS.Certificate == "" {
+						certlessRoutes = append(certlessRoutes, routeObj)
+					}
 				}
 				exposeobjectcreated = true
 			} else if common.IsPresent(supportedKinds, common.IngressKind) {
@@ -78,6 +85,10 @@ func (d *Service) createNewResources(ir irtypes.EnhancedIR, supportedKinds []str
 			continue
 		}
 		obj := d.createService(service)
+		if obj.Spec.Type == core.ServiceTypeLoadBalancer && useExternalDNS() {
+			hostname := service.Name + "." + d.getHost(ir.Name, targetCluster)
+			obj.Annotations = common.MergeStringMaps(obj.Annotations, getExternalDNSAnnotations([]string{hostname}))
+		}
 		objs = append(objs, obj)
 		// for Argo Rollouts, 2 services are required: one for the stable version, and one
 		// for the experimental version
@@ -88,11 +99,37 @@ func (d *Service) createNewResources(ir irtypes.EnhancedIR, supportedKinds []str
 		}
 	}
 
+	if len(certlessRoutes) != 0 {
+		if issuer := createCertManagerIssuer(ir.Name, ""); issuer != nil {
+			for _, route := range certlessRoutes {
+				route.Annotations = common.MergeStringMaps(route.Annotations, issuer.getRouteAnnotations())
+			}
+			objs = append(objs, issuer)
+		}
+	}
+
 	// Create one ingress for all services
 	if ingressEnabled {
 		obj := d.createIngress(ir, targetCluster)
 		if obj != nil {
 			objs = append(objs, obj)
+			if useExternalDNS() {
+				hostnames := []string{}
+				for _, rule := range obj.Spec.Rules {
+					hostnames = append(hostnames, rule.Host)
+				}
+				obj.Annotations = common.MergeStringMaps(obj.Annotations, getExternalDNSAnnotations(hostnames))
+			}
+			if len(obj.Spec.TLS) != 0 {
+				ingressClassName := ""
+				if obj.Spec.IngressClassName != nil {
+					ingressClassName = *obj.Spec.IngressClassName
+				}
+				if issuer := createCertManagerIssuer(ir.Name, ingressClassName); issuer != nil {
+					obj.Annotations = common.MergeStringMaps(obj.Annotations, issuer.getIngressAnnotations())
+					objs = append(objs, issuer)
+				}
+			}
 		}
 	}
 
@@ -190,8 +227,8 @@ func (d *Service) routeToIngress(route okdroutev1.Route, ir irtypes.EnhancedIR,
 		targetName := route.Spec.To.Name
 		s, ok := ir.Services[targetName]
 		if !ok {
-			for _, s1 := range ir.Services {
-				if s1.BackendServiceName == targetName {
+			for _, serviceName := range ir.GetSortedServiceNames() {
+				if s1 := ir.Services[serviceName]; s1.BackendServiceName == targetName {
 					s = s1
 					ok = true
 					break
@@ -365,7 +402,8 @@ func (d *Service) createIngress(ir irtypes.EnhancedIR, targetCluster collecttype
 	pathType := networking.PathTypePrefix
 
 	hostHTTPIngressPaths := map[string][]networking.HTTPIngressPath{} //[hostprefix]
-	for _, service := range ir.Services {
+	for _, serviceName := range ir.GetSortedServiceNames() {
+		service := ir.Services[serviceName]
 		backendServiceName := service.BackendServiceName
 		if service.BackendServiceName == "" {
 			backendServiceName = service.Name
@@ -405,7 +443,15 @@ func (d *Service) createIngress(ir irtypes.EnhancedIR, targetCluster collecttype
 	// Set the default ingressClass value
 	quesKeyClass := common.JoinQASubKeys(qaId, common.ConfigIngressClassNameKeySuffix)
 	descClass := "Provide the Ingress class name for ingress"
-	ingressClassName := qaengine.FetchStringAnswer(quesKeyClass, descClass, []string{"Leave empty to use the cluster default"}, "", nil)
+	hintsClass := []string{"Leave empty to use the cluster default"}
+	defaultIngressClassName := ""
+	if len(targetCluster.Spec.IngressClasses) != 0 {
+		hintsClass = append(hintsClass, "Ingress classes available in the target cluster: "+strings.Join(targetCluster.Spec.IngressClasses, ", "))
+		if targetCluster.Spec.DefaultIngressClass == "" && len(targetCluster.Spec.IngressClasses) == 1 {
+			defaultIngressClassName = targetCluster.Spec.IngressClasses[0]
+		}
+	}
+	ingressClassName := qaengine.FetchStringAnswer(quesKeyClass, descClass, hintsClass, defaultIngressClassName, nil)
 
 	// Configure the rule with the above fan-out paths
 	rules := []networking.IngressRule{}
@@ -418,7 +464,13 @@ func (d *Service) createIngress(ir irtypes.EnhancedIR, targetCluster collecttype
 	quesKeyTLS := common.JoinQASubKeys(qaId, common.ConfigIngressTLSKeySuffix)
 	descTLS := "Provide the TLS secret for ingress"
 	secretName = qaengine.FetchStringAnswer(quesKeyTLS, descTLS, []string{"Leave empty to use http"}, defaultSecretName, nil)
-	for hostprefix, httpIngressPaths := range hostHTTPIngressPaths {
+	hostPrefixes := []string{}
+	for hostprefix := range hostHTTPIngressPaths {
+		hostPrefixes = append(hostPrefixes, hostprefix)
+	}
+	sort.Strings(hostPrefixes)
+	for _, hostprefix := range hostPrefixes {
+		httpIngressPaths := hostHTTPIngressPaths[hostprefix]
 		ph := host
 		if hostprefix != "" {
 			ph = hostprefix + "." + ph
@@ -497,6 +549,9 @@ func (d *Service) getExposeInfo(service irtypes.Service) (servicePorts []core.Se
 		servicePortName := forwarding.ServicePort.Name
 		if servicePortName == "" {
 			servicePortName = fmt.Sprintf("port-%d", forwarding.ServicePort.Number)
+			if protocol := forwarding.GetProtocol(); protocol != "" {
+				servicePortName += "-" + strings.ToLower(string(protocol))
+			}
 		}
 		targetPort := intstr.IntOrString{Type: intstr.String, StrVal: forwarding.PodPort.Name}
 		if forwarding.PodPort.Name == "" {
@@ -507,6 +562,7 @@ func (d *Service) getExposeInfo(service irtypes.Service) (servicePorts []core.Se
 			Name:       servicePortName,
 			Port:       forwarding.ServicePort.Number,
 			TargetPort: targetPort,
+			Protocol:   forwarding.Protocol,
 		}
 		switch forwarding.ServiceType {
 		case core.ServiceTypeLoadBalancer:
@@ -542,6 +598,18 @@ func (d *Service) getExposeInfo(service irtypes.Service) (servicePorts []core.Se
 	return servicePorts, hostPrefixes, relPaths, serviceType
 }
 
+// getHost returns the host domain of the services exposed in the target cluster
+func (d *Service) getHost(irName string, targetCluster collecttypes.ClusterMetadata) string {
+	if targetCluster.Spec.Host != "" {
+		return targetCluster.Spec.Host
+	}
+	qaLabel := collecttypes.DefaultClusterSpecificQaLabel
+	if _, ok := targetCluster.Labels[collecttypes.ClusterQaLabelKey]; ok {
+		qaLabel = targetCluster.Labels[collecttypes.ClusterQaLabelKey]
+	}
+	return commonqa.IngressHost(d.getHostName(irName), qaLabel)
+}
+
 func (d *Service) getHostName(irName string) string {
 	return irName + ".com"
 }
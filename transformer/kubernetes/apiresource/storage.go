@@ -17,10 +17,18 @@
 package apiresource
 
 import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
 	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
 	collecttypes "github.com/konveyor/move2kube/types/collection"
 	irtypes "github.com/konveyor/move2kube/types/ir"
 	"github.com/sirupsen/logrus"
+	"github.com/spf13/cast"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	core "k8s.io/kubernetes/pkg/apis/core"
@@ -46,7 +54,7 @@ func (s *Storage) createNewResources(ir irtypes.EnhancedIR, supportedKinds []str
 			objs = append(objs, s.createSecret(stObj))
 		}
 		if stObj.StorageType == irtypes.PVCKind {
-			objs = append(objs, s.createPVC(stObj))
+			objs = append(objs, s.createPVC(stObj, targetCluster.Spec))
 		}
 	}
 	return objs
@@ -69,11 +77,29 @@ func (s *Storage) createConfigMap(st irtypes.Storage) *core.ConfigMap {
 		ObjectMeta: metav1.ObjectMeta{
 			Name: st.Name,
 		},
-		BinaryData: st.Content,
+	}
+	for key, content := range st.Content {
+		if !isTextContent(content) {
+			// keystores, images, etc. would get corrupted if they were stored as strings
+			if configMap.BinaryData == nil {
+				configMap.BinaryData = map[string][]byte{}
+			}
+			configMap.BinaryData[key] = content
+			continue
+		}
+		if configMap.Data == nil {
+			configMap.Data = map[string]string{}
+		}
+		configMap.Data[key] = string(content)
 	}
 	return configMap
 }
 
+// isTextContent returns true if the content is valid UTF-8 without any NUL characters
+func isTextContent(content []byte) bool {
+	return utf8.Valid(content) && bytes.IndexByte(content, 0) == -1
+}
+
 func (s *Storage) createSecret(st irtypes.Storage) *core.Secret {
 	secType := core.SecretTypeOpaque
 	if st.SecretType != "" {
@@ -96,9 +122,22 @@ func (s *Storage) createSecret(st irtypes.Storage) *core.Secret {
 	return secret
 }
 
-func (s *Storage) createPVC(st irtypes.Storage) *core.PersistentVolumeClaim {
+func (s *Storage) createPVC(st irtypes.Storage, cluster collecttypes.ClusterMetadataSpec) *core.PersistentVolumeClaim {
 	logrus.Trace("Storage.createPVC start")
 	defer logrus.Trace("Storage.createPVC end")
+	if len(st.PersistentVolumeClaimSpec.AccessModes) == 0 {
+		st.PersistentVolumeClaimSpec.AccessModes = []core.PersistentVolumeAccessMode{core.ReadWriteOnce}
+	}
+	st.PersistentVolumeClaimSpec.StorageClassName = getStorageClassName(st, cluster)
+	st.PersistentVolumeClaimSpec.AccessModes = getAccessModes(st, cluster)
+	if _, ok := st.PersistentVolumeClaimSpec.Resources.Requests[core.ResourceStorage]; !ok {
+		requests := core.ResourceList{}
+		for name, quantity := range st.PersistentVolumeClaimSpec.Resources.Requests {
+			requests[name] = quantity
+		}
+		requests[core.ResourceStorage] = getPVCSize(st.Name)
+		st.PersistentVolumeClaimSpec.Resources.Requests = requests
+	}
 	pvc := &core.PersistentVolumeClaim{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       string(irtypes.PVCKind),
@@ -114,6 +153,109 @@ func (s *Storage) createPVC(st irtypes.Storage) *core.PersistentVolumeClaim {
 	return pvc
 }
 
+// getStorageClassName returns the storage class for the claim based on the storage classes collected from the target cluster.
+// Claims without a storage class use the default storage class of the cluster,
+// so a storage class is chosen only if there is no default or the default does not support the access modes of the claim.
+func getStorageClassName(st irtypes.Storage, cluster collecttypes.ClusterMetadataSpec) *string {
+	storageClassName := st.PersistentVolumeClaimSpec.StorageClassName
+	if !cluster.IsCollected() || len(cluster.StorageClasses) == 0 {
+		return storageClassName
+	}
+	accessModes := getAccessModeNames(st.PersistentVolumeClaimSpec.AccessModes)
+	if storageClassName != nil {
+		if *storageClassName == "" {
+			return storageClassName
+		}
+		if !common.IsPresent(cluster.StorageClasses, *storageClassName) {
+			logrus.Warnf("The storage class '%s' of the claim '%s' is not available in the target cluster", *storageClassName, st.Name)
+		} else if cluster.SupportsAccessModes(*storageClassName, accessModes) {
+			return storageClassName
+		} else {
+			logrus.Warnf("The storage class '%s' of the claim '%s' does not support the access modes %+v", *storageClassName, st.Name, accessModes)
+		}
+	} else if cluster.DefaultStorageClass != "" && cluster.SupportsAccessModes(cluster.DefaultStorageClass, accessModes) {
+		return nil
+	}
+	def := ""
+	hints := []string{"These are the storage classes available in the target cluster"}
+	for _, storageClass := range cluster.StorageClasses {
+		if def == "" && cluster.SupportsAccessModes(storageClass, accessModes) {
+			def = storageClass
+		}
+		if supportedAccessModes, ok := cluster.StorageClassAccessModes[storageClass]; ok {
+			hints = append(hints, fmt.Sprintf("%s supports %s", storageClass, strings.Join(supportedAccessModes, ", ")))
+		}
+	}
+	if cluster.DefaultStorageClass != "" && (def == "" || cluster.SupportsAccessModes(cluster.DefaultStorageClass, accessModes)) {
+		def = cluster.DefaultStorageClass
+	}
+	if def == "" {
+		def = cluster.StorageClasses[0]
+	}
+	selectedStorageClass := qaengine.FetchSelectAnswer(
+		fmt.Sprintf(common.ConfigStoragesStorageClassKey, `"`+st.Name+`"`),
+		fmt.Sprintf("Select the storage class for the claim '%s' with the access modes %s:", st.Name, strings.Join(accessModes, ", ")),
+		hints,
+		def,
+		cluster.StorageClasses,
+		nil,
+	)
+	return &selectedStorageClass
+}
+
+// getAccessModes returns the access modes of the claim that are supported by the storage class of the claim.
+// A claim with access modes that are not supported never gets bound, so the unsupported access modes are dropped.
+func getAccessModes(st irtypes.Storage, cluster collecttypes.ClusterMetadataSpec) []core.PersistentVolumeAccessMode {
+	accessModes := st.PersistentVolumeClaimSpec.AccessModes
+	storageClassName := cluster.DefaultStorageClass
+	if st.PersistentVolumeClaimSpec.StorageClassName != nil {
+		storageClassName = *st.PersistentVolumeClaimSpec.StorageClassName
+	}
+	supportedAccessModes, ok := cluster.StorageClassAccessModes[storageClassName]
+	if storageClassName == "" || !ok || cluster.SupportsAccessModes(storageClassName, getAccessModeNames(accessModes)) {
+		return accessModes
+	}
+	newAccessModes := []core.PersistentVolumeAccessMode{}
+	for _, accessMode := range accessModes {
+		if common.IsPresent(supportedAccessModes, string(accessMode)) {
+			newAccessModes = append(newAccessModes, accessMode)
+		}
+	}
+	if len(newAccessModes) == 0 {
+		newAccessModes = []core.PersistentVolumeAccessMode{core.ReadWriteOnce}
+	}
+	logrus.Warnf("The storage class '%s' does not support the access modes %+v of the claim '%s' . Using the access modes %+v instead.", storageClassName, accessModes, st.Name, newAccessModes)
+	return newAccessModes
+}
+
+func getAccessModeNames(accessModes []core.PersistentVolumeAccessMode) []string {
+	names := []string{}
+	for _, accessMode := range accessModes {
+		names = append(names, string(accessMode))
+	}
+	return names
+}
+
+// getPVCSize returns the size requested by a claim that does not specify one
+func getPVCSize(claimName string) resource.Quantity {
+	size := qaengine.FetchStringAnswer(
+		fmt.Sprintf(common.ConfigStoragesSizeKey, `"`+claimName+`"`),
+		fmt.Sprintf("Provide the size of the storage for the claim '%s':", claimName),
+		[]string{"The size is a Kubernetes quantity like 100Mi or 10Gi"},
+		common.DefaultPVCSize.String(),
+		func(size interface{}) error {
+			_, err := resource.ParseQuantity(cast.ToString(size))
+			return err
+		},
+	)
+	quantity, err := resource.ParseQuantity(size)
+	if err != nil {
+		logrus.Errorf("failed to parse the size '%s' of the claim '%s' . Using the default size %s . Error: %q", size, claimName, common.DefaultPVCSize.String(), err)
+		return common.DefaultPVCSize.DeepCopy()
+	}
+	return quantity
+}
+
 func convertPVCVolumeToEmptyVolume(vPVC core.Volume) *core.Volume {
 	vEmptySrc := &core.VolumeSource{
 		EmptyDir: &core.EmptyDirVolumeSource{},
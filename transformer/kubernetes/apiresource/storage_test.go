@@ -0,0 +1,103 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"testing"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestCreatePVC(t *testing.T) {
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	cluster := collection.ClusterMetadataSpec{
+		KubernetesVersion:   "v1.26.0",
+		StorageClasses:      []string{"gp2", "efs"},
+		DefaultStorageClass: "gp2",
+		StorageClassAccessModes: map[string][]string{
+			"gp2": {"ReadWriteOnce"},
+			"efs": {"ReadWriteOnce", "ReadOnlyMany", "ReadWriteMany"},
+		},
+	}
+	newStorage := func(accessModes ...core.PersistentVolumeAccessMode) irtypes.Storage {
+		return irtypes.Storage{
+			Name:                      "data",
+			StorageType:               irtypes.PVCKind,
+			PersistentVolumeClaimSpec: core.PersistentVolumeClaimSpec{AccessModes: accessModes},
+		}
+	}
+
+	t.Run("claim supported by the default storage class", func(t *testing.T) {
+		pvc := new(Storage).createPVC(newStorage(), cluster)
+		if pvc.Spec.StorageClassName != nil {
+			t.Fatalf("expected the default storage class to be used. Actual: %s", *pvc.Spec.StorageClassName)
+		}
+		if len(pvc.Spec.AccessModes) != 1 || pvc.Spec.AccessModes[0] != core.ReadWriteOnce {
+			t.Fatalf("expected the claim to be ReadWriteOnce. Actual: %+v", pvc.Spec.AccessModes)
+		}
+		size := pvc.Spec.Resources.Requests[core.ResourceStorage]
+		if size.Cmp(common.DefaultPVCSize) != 0 {
+			t.Fatalf("expected the default size %s . Actual: %s", common.DefaultPVCSize.String(), size.String())
+		}
+	})
+
+	t.Run("claim not supported by the default storage class", func(t *testing.T) {
+		pvc := new(Storage).createPVC(newStorage(core.ReadWriteMany), cluster)
+		if pvc.Spec.StorageClassName == nil || *pvc.Spec.StorageClassName != "efs" {
+			t.Fatalf("expected the storage class that supports ReadWriteMany. Actual: %v", pvc.Spec.StorageClassName)
+		}
+		if len(pvc.Spec.AccessModes) != 1 || pvc.Spec.AccessModes[0] != core.ReadWriteMany {
+			t.Fatalf("expected the claim to be ReadWriteMany. Actual: %+v", pvc.Spec.AccessModes)
+		}
+	})
+
+	t.Run("claim with a storage class that does not support its access modes", func(t *testing.T) {
+		st := newStorage(core.ReadWriteMany)
+		storageClassName := "gp2"
+		st.PersistentVolumeClaimSpec.StorageClassName = &storageClassName
+		onlyBlockCluster := cluster
+		onlyBlockCluster.StorageClasses = []string{"gp2"}
+		pvc := new(Storage).createPVC(st, onlyBlockCluster)
+		if len(pvc.Spec.AccessModes) != 1 || pvc.Spec.AccessModes[0] != core.ReadWriteOnce {
+			t.Fatalf("expected the access modes to be changed to ReadWriteOnce. Actual: %+v", pvc.Spec.AccessModes)
+		}
+	})
+}
+
+func TestCreateConfigMap(t *testing.T) {
+	keystore := []byte{0xfe, 0xed, 0xfe, 0xed, 0x00, 0x00, 0x00, 0x02}
+	st := irtypes.Storage{
+		Name:        "config",
+		StorageType: irtypes.ConfigMapKind,
+		Content: map[string][]byte{
+			"app.properties": []byte("server.port=8080\n"),
+			"keystore.jks":   keystore,
+			"nul.txt":        []byte("a\x00b"),
+		},
+	}
+	configMap := new(Storage).createConfigMap(st)
+	if len(configMap.Data) != 1 || configMap.Data["app.properties"] != "server.port=8080\n" {
+		t.Fatalf("expected the text file to be in the data. Actual: %+v", configMap.Data)
+	}
+	if len(configMap.BinaryData) != 2 || string(configMap.BinaryData["keystore.jks"]) != string(keystore) {
+		t.Fatalf("expected the binary files to be in the binary data. Actual: %+v", configMap.BinaryData)
+	}
+}
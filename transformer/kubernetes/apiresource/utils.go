@@ -20,7 +20,6 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"reflect"
 	"strings"
 
 	"github.com/konveyor/move2kube/common"
@@ -28,8 +27,9 @@ import (
 	"github.com/konveyor/move2kube/transformer/kubernetes/k8sschema/fixer"
 	collecttypes "github.com/konveyor/move2kube/types/collection"
 	irtypes "github.com/konveyor/move2kube/types/ir"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
 	"github.com/sirupsen/logrus"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -40,6 +40,7 @@ func TransformIRAndPersist(
 	apiResources []IAPIResource,
 	targetCluster collecttypes.ClusterMetadata,
 	setDefaultValuesInYamls bool,
+	transformer transformertypes.Transformer,
 ) (files []string, err error) {
 	logrus.Trace("TransformIRAndPersist start")
 	defer logrus.Trace("TransformIRAndPersist end")
@@ -56,6 +57,15 @@ func TransformIRAndPersist(
 	if err != nil {
 		return nil, fmt.Errorf("failed to fix, convert and transform the objects. Error: %w", err)
 	}
+	if !common.DisableProvenanceAnnotations {
+		for _, obj := range convertedObjs {
+			addProvenanceAnnotations(obj, ir, transformer)
+		}
+	}
+	namespace := getProjectNamespace(ir)
+	for _, obj := range convertedObjs {
+		addProjectMetadata(obj, ir, namespace)
+	}
 	filesWritten, err := writeObjects(outputPath, convertedObjs)
 	if err != nil {
 		return nil, fmt.Errorf("failed to write the transformed objects to the directory at path '%s' . Error: %w", outputPath, err)
@@ -96,12 +106,14 @@ func writeObjects(outputPath string, objs []runtime.Object) ([]string, error) {
 	}
 	filesWritten := []string{}
 	for _, obj := range objs {
+		injectMetadata(obj)
+		yamlPath := filepath.Join(outputPath, getFilename(obj))
+		obj = patchResource(obj)
 		objYamlBytes, err := common.MarshalObjToYaml(obj)
 		if err != nil {
 			logrus.Errorf("failed to marshal the runtime. Object to yaml. Object: %+v Error: %q", obj, err)
 			continue
 		}
-		yamlPath := filepath.Join(outputPath, getFilename(obj))
 		if err := os.WriteFile(yamlPath, objYamlBytes, common.DefaultFilePermission); err != nil {
 			logrus.Errorf("failed to write the yaml to file at path '%s' . Error: %q", yamlPath, err)
 			continue
@@ -126,8 +138,9 @@ func convertVersion(objs []runtime.Object, clusterSpec collecttypes.ClusterMetad
 }
 
 func getFilename(obj runtime.Object) string {
-	val := reflect.ValueOf(obj).Elem()
-	typeMeta := val.FieldByName("TypeMeta").Interface().(metav1.TypeMeta)
-	objectMeta := val.FieldByName("ObjectMeta").Interface().(metav1.ObjectMeta)
-	return fmt.Sprintf("%s-%s.yaml", objectMeta.Name, strings.ToLower(typeMeta.Kind))
+	name := ""
+	if objMeta, err := meta.Accessor(obj); err == nil {
+		name = objMeta.GetName()
+	}
+	return fmt.Sprintf("%s-%s.yaml", name, strings.ToLower(obj.GetObjectKind().GroupVersionKind().Kind))
 }
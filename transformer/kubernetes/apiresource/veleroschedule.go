@@ -0,0 +1,156 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package apiresource
+
+import (
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/konveyor/move2kube/types/qaengine/commonqa"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	veleroScheduleKind       = "Schedule"
+	veleroScheduleAPIVersion = "velero.io/v1"
+	// veleroNamespace is the namespace Velero is installed to, which it watches for the schedules
+	veleroNamespace       = "velero"
+	defaultBackupSchedule = "0 2 * * *"
+	// defaultBackupTTL keeps the backups for 30 days
+	defaultBackupTTL = "720h0m0s"
+)
+
+// VeleroSchedule handles the Velero Schedules that back up the persistent volumes of the services
+type VeleroSchedule struct {
+}
+
+// veleroSchedule is a Velero Schedule that periodically creates backups
+type veleroSchedule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              veleroScheduleSpec `json:"spec"`
+}
+
+type veleroScheduleSpec struct {
+	Schedule string           `json:"schedule"`
+	Template veleroBackupSpec `json:"template"`
+}
+
+type veleroBackupSpec struct {
+	IncludedNamespaces []string              `json:"includedNamespaces,omitempty"`
+	LabelSelector      *metav1.LabelSelector `json:"labelSelector,omitempty"`
+	SnapshotVolumes    *bool                 `json:"snapshotVolumes,omitempty"`
+	TTL                string                `json:"ttl,omitempty"`
+}
+
+// DeepCopyObject implements the runtime.Object interface
+func (s *veleroSchedule) DeepCopyObject() runtime.Object {
+	newSchedule := &veleroSchedule{TypeMeta: s.TypeMeta, Spec: s.Spec}
+	s.ObjectMeta.DeepCopyInto(&newSchedule.ObjectMeta)
+	newSchedule.Spec.Template.IncludedNamespaces = append([]string{}, s.Spec.Template.IncludedNamespaces...)
+	newSchedule.Spec.Template.LabelSelector = s.Spec.Template.LabelSelector.DeepCopy()
+	if s.Spec.Template.SnapshotVolumes != nil {
+		snapshotVolumes := *s.Spec.Template.SnapshotVolumes
+		newSchedule.Spec.Template.SnapshotVolumes = &snapshotVolumes
+	}
+	return newSchedule
+}
+
+// getSupportedKinds returns all kinds supported by the class
+func (v *VeleroSchedule) getSupportedKinds() []string {
+	return []string{veleroScheduleKind}
+}
+
+// createNewResources creates a Velero Schedule backing up the pods of the services that use persistent volumes, along with their volumes
+func (v *VeleroSchedule) createNewResources(ir irtypes.EnhancedIR, supportedKinds []string, targetCluster collecttypes.ClusterMetadata) []runtime.Object {
+	serviceNames := []string{}
+	for _, serviceName := range ir.GetSortedServiceNames() {
+		service := ir.Services[serviceName]
+		for _, volume := range service.Volumes {
+			if volume.PersistentVolumeClaim != nil {
+				serviceNames = append(serviceNames, service.Name)
+				break
+			}
+		}
+	}
+	// Since Velero is an extension, the supported kinds are ignored and it is upto the user to install it.
+	if len(serviceNames) == 0 || !commonqa.VeleroBackup() {
+		return nil
+	}
+	// the volumes are either snapshotted or opted into the file system backup through the annotations on the pods
+	snapshotVolumes := commonqa.VeleroBackupMethod() == commonqa.SnapshotBackupMethod
+	schedule := qaengine.FetchStringAnswer(
+		common.ConfigBackupScheduleKey,
+		"Enter the cron schedule of the backups :",
+		[]string{"The schedule is in the cron format and the time is in UTC."},
+		defaultBackupSchedule,
+		nil,
+	)
+	ttl := qaengine.FetchStringAnswer(
+		common.ConfigBackupTTLKey,
+		"Enter how long the backups should be kept :",
+		[]string{"The duration is in the Go format, like 720h0m0s for 30 days."},
+		defaultBackupTTL,
+		nil,
+	)
+	// without the included namespaces Velero backs up the matching pods of the whole cluster
+	namespace := getProjectNamespace(ir)
+	if namespace == "" {
+		namespace = qaengine.FetchStringAnswer(
+			common.ConfigBackupNamespaceKey,
+			"Enter the namespace the services are deployed to, so that Velero only backs up that namespace :",
+			[]string{"The resources are deployed to the namespace of the current context."},
+			"default",
+			nil,
+		)
+	}
+	includedNamespaces := []string{namespace}
+	for _, serviceName := range serviceNames {
+		if level := getPodSecurityExemptionLevel(ir.Services[serviceName]); level != "" {
+			includedNamespaces = common.AppendIfNotPresent(includedNamespaces, getExemptedNamespace(namespace, level))
+		}
+	}
+	name := common.MakeStringDNSSubdomainNameCompliant(ir.Name + "-backup")
+	return []runtime.Object{&veleroSchedule{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       veleroScheduleKind,
+			APIVersion: veleroScheduleAPIVersion,
+		},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: veleroNamespace},
+		Spec: veleroScheduleSpec{
+			Schedule: schedule,
+			Template: veleroBackupSpec{
+				IncludedNamespaces: includedNamespaces,
+				// Velero also backs up the persistent volume claims and the volumes used by the selected pods
+				LabelSelector: &metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{{Key: selector, Operator: metav1.LabelSelectorOpIn, Values: serviceNames}},
+				},
+				SnapshotVolumes: &snapshotVolumes,
+				TTL:             ttl,
+			},
+		},
+	}}
+}
+
+// convertToClusterSupportedKinds converts kinds to cluster supported kinds
+func (v *VeleroSchedule) convertToClusterSupportedKinds(obj runtime.Object, supportedKinds []string, otherobjs []runtime.Object, _ irtypes.EnhancedIR, targetCluster collecttypes.ClusterMetadata) ([]runtime.Object, bool) {
+	if common.IsPresent(v.getSupportedKinds(), obj.GetObjectKind().GroupVersionKind().Kind) {
+		return []runtime.Object{obj}, true
+	}
+	return nil, false
+}
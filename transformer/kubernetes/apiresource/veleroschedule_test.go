@@ -0,0 +1,63 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestCreateVeleroSchedule(t *testing.T) {
+	qaengine.ResetEngines()
+	defer qaengine.ResetEngines()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", []string{`move2kube.backup.enable=true`, `move2kube.backup.namespace="shop"`}, nil, nil, false)
+	ir := irtypes.NewIR()
+	ir.Name = "shop"
+	for _, serviceName := range []string{"web", "db", "cache"} {
+		service := irtypes.NewServiceWithName(serviceName)
+		if serviceName != "web" {
+			service.Volumes = []core.Volume{{Name: "data", VolumeSource: core.VolumeSource{PersistentVolumeClaim: &core.PersistentVolumeClaimVolumeSource{ClaimName: serviceName + "-data"}}}}
+		}
+		if serviceName == "cache" {
+			service.PolicyExemptions = []string{collection.PodSecurityPolicyPrefix + podSecurityPrivileged}
+		}
+		ir.Services[serviceName] = service
+	}
+	objs := (&VeleroSchedule{}).createNewResources(irtypes.NewEnhancedIRFromIR(ir), []string{veleroScheduleKind}, collection.ClusterMetadata{})
+	if len(objs) != 1 {
+		t.Fatalf("expected a schedule. Actual: %+v", objs)
+	}
+	schedule, ok := objs[0].(*veleroSchedule)
+	if !ok {
+		t.Fatalf("expected a velero schedule. Actual: %T", objs[0])
+	}
+	if schedule.Namespace != veleroNamespace {
+		t.Fatalf("expected the schedule to be in the namespace %s . Actual: %s", veleroNamespace, schedule.Namespace)
+	}
+	if want := []string{"shop", "shop-privileged"}; !reflect.DeepEqual(schedule.Spec.Template.IncludedNamespaces, want) {
+		t.Fatalf("expected the included namespaces %+v . Actual: %+v", want, schedule.Spec.Template.IncludedNamespaces)
+	}
+	if want := []string{"cache", "db"}; !reflect.DeepEqual(schedule.Spec.Template.LabelSelector.MatchExpressions[0].Values, want) {
+		t.Fatalf("expected the services with persistent volumes %+v to be backed up. Actual: %+v", want, schedule.Spec.Template.LabelSelector)
+	}
+}
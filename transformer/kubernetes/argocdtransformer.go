@@ -102,10 +102,9 @@ func (t *ArgoCD) Transform(newArtifacts []transformertypes.Artifact, alreadySeen
 		ir.Name = newArtifact.Name
 		preprocessedIR, err := irpreprocessor.Preprocess(ir, clusterConfig)
 		if err != nil {
-			logrus.Errorf("Unable to prepreocess IR : %s", err)
-		} else {
-			ir = preprocessedIR
+			return nil, nil, fmt.Errorf("failed to preprocess the IR of the artifact '%s' . Error: %w", newArtifact.Name, err)
 		}
+		ir = preprocessedIR
 		resources := []apiresource.IAPIResource{
 			new(apiresource.ArgoCDApplication),
 		}
@@ -113,7 +112,7 @@ func (t *ArgoCD) Transform(newArtifacts []transformertypes.Artifact, alreadySeen
 		tempDest := filepath.Join(t.Env.TempPath, deployCICDDir)
 		logrus.Debugf("Generating ArgoCD yamls for CI/CD")
 		enhancedIR := t.setupEnhancedIR(ir, t.Env.GetProjectName())
-		files, err := apiresource.TransformIRAndPersist(enhancedIR, tempDest, resources, clusterConfig, t.ArgoCDConfig.SetDefaultValuesInYamls)
+		files, err := apiresource.TransformIRAndPersist(enhancedIR, tempDest, resources, clusterConfig, t.ArgoCDConfig.SetDefaultValuesInYamls, t.Config)
 		if err != nil {
 			logrus.Errorf("failed to transform and persist IR. Error: %q", err)
 			continue
@@ -112,10 +112,9 @@ func (t *BuildConfig) Transform(newArtifacts []transformertypes.Artifact, alread
 		ir.Name = newArtifact.Name
 		preprocessedIR, err := irpreprocessor.Preprocess(ir, clusterConfig)
 		if err != nil {
-			logrus.Errorf("failed to prepreocess the IR. Error: %q", err)
-		} else {
-			ir = preprocessedIR
+			return nil, nil, fmt.Errorf("failed to preprocess the IR of the artifact '%s' . Error: %w", newArtifact.Name, err)
 		}
+		ir = preprocessedIR
 		if len(clusterConfig.Spec.GetSupportedVersions("BuildConfig")) == 0 {
 			logrus.Debugf("BuildConfig was not found on the target cluster.")
 			continue
@@ -131,6 +130,7 @@ func (t *BuildConfig) Transform(newArtifacts []transformertypes.Artifact, alread
 			apiResources,
 			clusterConfig,
 			t.BuildConfigConfig.SetDefaultValuesInYamls,
+			t.Config,
 		)
 		if err != nil {
 			logrus.Errorf("failed to transform and persist the IR. Error: %q", err)
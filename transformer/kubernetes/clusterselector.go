@@ -18,6 +18,9 @@ package kubernetes
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
 
 	"github.com/konveyor/move2kube/common"
 	"github.com/konveyor/move2kube/environment"
@@ -47,6 +50,8 @@ type ClusterSelectorTransformer struct {
 	Env      *environment.Environment
 	Clusters map[string]collecttypes.ClusterMetadata
 	CSConfig *ClusterSelectorConfig
+	// collectedClusters contains the names of the clusters collected using move2kube collect
+	collectedClusters []string
 }
 
 // ClusterSelectorConfig represents the configuration of the cluster selector
@@ -76,6 +81,9 @@ func (t *ClusterSelectorTransformer) Init(tc transformertypes.Transformer, e *en
 		}
 		t.Clusters[cm.Name] = cm
 	}
+	if e.Source != "" {
+		t.loadCollectedClusters(filepath.Join(e.Source, collecttypes.CollectOutputDirName, collecttypes.ClustersDirName))
+	}
 	err = common.GetObjFromInterface(t.Config.Spec.Config, t.CSConfig)
 	if err != nil {
 		logrus.Errorf("unable to load config for Transformer %+v into %T : %s", t.Config.Spec.Config, t.CSConfig, err)
@@ -87,6 +95,30 @@ func (t *ClusterSelectorTransformer) Init(tc transformertypes.Transformer, e *en
 	return nil
 }
 
+// loadCollectedClusters loads the cluster metadata collected using move2kube collect and copied into the source directory
+func (t *ClusterSelectorTransformer) loadCollectedClusters(clustersDir string) {
+	if _, err := os.Stat(clustersDir); err != nil {
+		return
+	}
+	filePaths, err := common.GetFilesByExt(clustersDir, []string{".yml", ".yaml"})
+	if err != nil {
+		logrus.Warnf("Failed to fetch the collected cluster metadata yamls at path %q Error: %q", clustersDir, err)
+		return
+	}
+	for _, filePath := range filePaths {
+		cm, err := t.GetClusterMetadata(filePath)
+		if err != nil {
+			continue
+		}
+		if _, ok := t.Clusters[cm.Name]; ok {
+			logrus.Warnf("The collected cluster metadata at path %q overrides the cluster config with the same name : %s", filePath, cm.Name)
+		}
+		t.Clusters[cm.Name] = cm
+		t.collectedClusters = common.AppendIfNotPresent(t.collectedClusters, cm.Name)
+	}
+	sort.Strings(t.collectedClusters)
+}
+
 // GetConfig returns the transformer config
 func (t *ClusterSelectorTransformer) GetConfig() (transformertypes.Transformer, *environment.Environment) {
 	return t.Config, t.Env
@@ -103,12 +135,17 @@ func (t *ClusterSelectorTransformer) Transform(newArtifacts []transformertypes.A
 	for c := range t.Clusters {
 		clusterTypeList = append(clusterTypeList, c)
 	}
+	sort.Strings(clusterTypeList)
 	if len(clusterTypeList) == 0 {
 		err = fmt.Errorf("no cluster configuration available")
 		logrus.Errorf("%s", err)
 		return nil, nil, err
 	}
 	def := defaultClusterType
+	if len(t.collectedClusters) != 0 {
+		// prefer the cluster that was collected from the actual target
+		def = t.collectedClusters[0]
+	}
 	if !common.IsPresent(clusterTypeList, def) {
 		def = clusterTypeList[0]
 	}
@@ -0,0 +1,437 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/filesystem"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/transformer/kubernetes/k8sschema"
+	"github.com/konveyor/move2kube/types/qaengine/commonqa"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	helmOperatorBaseImage  = "quay.io/operator-framework/helm-operator:v1.31.0"
+	helmOperatorVersion    = "v1alpha1"
+	helmOperatorChartsDir  = "helm-charts"
+	helmOperatorDockerfile = `FROM ` + helmOperatorBaseImage + `
+
+ENV HOME=/opt/helm
+COPY watches.yaml ${HOME}/watches.yaml
+COPY ` + helmOperatorChartsDir + ` ${HOME}/` + helmOperatorChartsDir + `
+WORKDIR ${HOME}
+`
+)
+
+var (
+	// helmOperatorWorkloadKinds are the kinds that make a set of yamls an app that can be delivered as an operator
+	helmOperatorWorkloadKinds = regexp.MustCompile("^(" + podTemplateKinds + "|" + podKind + "|CronJob)$")
+	invalidKindChars          = regexp.MustCompile(`[^a-zA-Z0-9]+`)
+)
+
+// helmOperatorConfig contains the details of the custom resource watched by the operator
+type helmOperatorConfig struct {
+	chartName string
+	domain    string
+	group     string
+	kind      string
+	plural    string
+	image     string
+}
+
+// shouldGenerateHelmOperator returns true if the user wants the yamls of the project containing workloads to be delivered as an operator
+func shouldGenerateHelmOperator(projectName, yamlsPath string) bool {
+	if !hasWorkloads(yamlsPath) {
+		return false
+	}
+	return qaengine.FetchBoolAnswer(
+		common.JoinQASubKeys(common.ConfigParameterizationHelmOperatorKey, `"`+projectName+`"`, common.ConfigEnableForHelmOperatorKeySegment),
+		fmt.Sprintf("Do you want to generate a Helm operator that deploys the Helm chart of the project '%s'?", projectName),
+		[]string{"The operator watches a custom resource for the app and installs the Helm chart with the values in its spec"},
+		false,
+		nil,
+	)
+}
+
+// hasWorkloads returns true if the yamls contain any workloads
+func hasWorkloads(yamlsPath string) bool {
+	pathedKs, err := k8sschema.GetK8sResourcesWithPaths(yamlsPath, false)
+	if err != nil {
+		logrus.Debugf("failed to get the k8s resources from the directory %s . Error: %q", yamlsPath, err)
+		return false
+	}
+	for _, ks := range pathedKs {
+		for _, k := range ks {
+			if kind, _, _, err := k8sschema.GetInfoFromK8sResource(k); err == nil && helmOperatorWorkloadKinds.MatchString(kind) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// getHelmOperatorConfig returns the group and kind of the custom resource for the helm chart, and the image of the operator
+func getHelmOperatorConfig(projectName, chartName string) helmOperatorConfig {
+	quotedProjectName := `"` + projectName + `"`
+	domain := qaengine.FetchStringAnswer(
+		common.JoinQASubKeys(common.ConfigParameterizationHelmOperatorKey, quotedProjectName, common.ConfigDomainForHelmOperatorKeySegment),
+		fmt.Sprintf("Enter the domain of the API group of the custom resource watched by the Helm operator of the project '%s' :", projectName),
+		[]string{"The API group is the name of the chart followed by the domain"},
+		"example.com",
+		nil,
+	)
+	kind := ""
+	for _, part := range invalidKindChars.Split(chartName, -1) {
+		if part != "" {
+			kind += strings.ToUpper(part[:1]) + part[1:]
+		}
+	}
+	// the image is tagged with the version of the bundle, so that the bundle always installs the operator it was built with
+	defaultImage := commonqa.ImageRegistry() + "/" + commonqa.ImageRegistryNamespace() + "/" + chartName + "-operator:v" + olmBundleVersion
+	image := qaengine.FetchStringAnswer(
+		common.JoinQASubKeys(common.ConfigParameterizationHelmOperatorKey, quotedProjectName, common.ConfigImageForHelmOperatorKeySegment),
+		fmt.Sprintf("Enter the image of the Helm operator of the project '%s' :", projectName),
+		[]string{"Use a specific tag or digest, since the operator lifecycle manager does not pull a newer image for the same tag"},
+		defaultImage,
+		validateHelmOperatorImage,
+	)
+	return helmOperatorConfig{
+		chartName: chartName,
+		domain:    domain,
+		group:     chartName + "." + domain,
+		kind:      kind,
+		plural:    strings.ToLower(kind) + "s",
+		image:     image,
+	}
+}
+
+// validateHelmOperatorImage rejects the images that are not pinned to a tag other than latest or to a digest
+func validateHelmOperatorImage(answer interface{}) error {
+	image, ok := answer.(string)
+	if !ok {
+		return fmt.Errorf("expected a string. Actual: %T", answer)
+	}
+	if strings.Contains(image, "@") {
+		return nil
+	}
+	if _, tag := common.GetImageNameAndTag(image); tag == "latest" {
+		return fmt.Errorf("the image '%s' of the Helm operator must have a tag other than latest or a digest", image)
+	}
+	return nil
+}
+
+// generateHelmOperator generates a Helm operator project in the operator-sdk layout for the helm chart of the project
+func generateHelmOperator(projectName, yamlsPath, chartDir, operatorDir string) error {
+	config := getHelmOperatorConfig(projectName, filepath.Base(chartDir))
+	if err := filesystem.Replicate(chartDir, filepath.Join(operatorDir, helmOperatorChartsDir, config.chartName)); err != nil {
+		return fmt.Errorf("failed to copy the helm chart %s to the operator directory %s . Error: %w", chartDir, operatorDir, err)
+	}
+	configDir := filepath.Join(operatorDir, "config")
+	for _, dir := range []string{"crd/bases", "rbac", "manager", "samples"} {
+		if err := os.MkdirAll(filepath.Join(configDir, dir), common.DefaultDirectoryPermission); err != nil {
+			return fmt.Errorf("failed to create the directory %s . Error: %w", filepath.Join(configDir, dir), err)
+		}
+	}
+	crd := getHelmOperatorCRD(config)
+	role := getHelmOperatorRole(config, yamlsPath)
+	leaderElectionRole := getHelmOperatorLeaderElectionRole(config)
+	manager := getHelmOperatorManager(config)
+	files := map[string]interface{}{
+		"PROJECT":      getHelmOperatorProject(config),
+		"watches.yaml": []map[string]interface{}{{"group": config.group, "version": helmOperatorVersion, "kind": config.kind, "chart": helmOperatorChartsDir + "/" + config.chartName}},
+		filepath.Join("config", "crd", "bases", config.group+"_"+config.plural+".yaml"): crd,
+		filepath.Join("config", "rbac", "role.yaml"):                                    role,
+		filepath.Join("config", "rbac", "role_binding.yaml"):                            getHelmOperatorRoleBinding(config),
+		filepath.Join("config", "rbac", "service_account.yaml"):                         getHelmOperatorServiceAccount(config),
+		filepath.Join("config", "rbac", "leader_election_role.yaml"):                    leaderElectionRole,
+		filepath.Join("config", "rbac", "leader_election_role_binding.yaml"):            getHelmOperatorLeaderElectionRoleBinding(config),
+	}
+	// there is a sample custom resource for each of the environments with the values of the environment in its spec
+	valuesPaths, err := filepath.Glob(filepath.Join(chartDir, "values*.yaml"))
+	if err != nil {
+		return fmt.Errorf("failed to find the values of the helm chart %s . Error: %w", chartDir, err)
+	}
+	samples := []map[string]interface{}{}
+	for _, valuesPath := range valuesPaths {
+		values := map[string]interface{}{}
+		if err := common.ReadYaml(valuesPath, &values); err != nil {
+			logrus.Errorf("failed to read the values of the helm chart from the file %s . Error: %q", valuesPath, err)
+			continue
+		}
+		env := strings.TrimPrefix(strings.TrimSuffix(filepath.Base(valuesPath), ".yaml"), "values")
+		sample := getHelmOperatorSample(config, env, values)
+		samples = append(samples, sample)
+		files[filepath.Join("config", "samples", config.group+"_"+helmOperatorVersion+"_"+strings.ToLower(config.kind)+env+".yaml")] = sample
+	}
+	for path, obj := range files {
+		if err := common.WriteYaml(filepath.Join(operatorDir, path), obj); err != nil {
+			return fmt.Errorf("failed to write the file %s of the helm operator. Error: %w", path, err)
+		}
+	}
+	managerYaml := []byte{}
+	for _, obj := range manager {
+		objYaml, err := common.ObjectToYamlBytes(obj)
+		if err != nil {
+			return fmt.Errorf("failed to encode the manager of the helm operator as yaml. Error: %w", err)
+		}
+		if len(managerYaml) != 0 {
+			managerYaml = append(managerYaml, []byte("---\n")...)
+		}
+		managerYaml = append(managerYaml, objYaml...)
+	}
+	if err := os.WriteFile(filepath.Join(configDir, "manager", "manager.yaml"), managerYaml, common.DefaultFilePermission); err != nil {
+		return fmt.Errorf("failed to write the manager of the helm operator. Error: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(operatorDir, common.DefaultDockerfileName), []byte(helmOperatorDockerfile), common.DefaultFilePermission); err != nil {
+		return fmt.Errorf("failed to write the Dockerfile of the helm operator. Error: %w", err)
+	}
+	if err := generateOLMBundle(config, operatorDir, crd, role, leaderElectionRole, manager[len(manager)-1], samples); err != nil {
+		return fmt.Errorf("failed to generate the OLM bundle of the helm operator. Error: %w", err)
+	}
+	return nil
+}
+
+func getHelmOperatorProject(config helmOperatorConfig) map[string]interface{} {
+	return map[string]interface{}{
+		"domain":      config.domain,
+		"layout":      []string{"helm.sdk.operatorframework.io/v1"},
+		"projectName": config.chartName + "-operator",
+		"resources": []map[string]interface{}{{
+			"api":     map[string]interface{}{"crdVersion": "v1", "namespaced": true},
+			"domain":  config.domain,
+			"group":   config.chartName,
+			"kind":    config.kind,
+			"version": helmOperatorVersion,
+		}},
+		"version": "3",
+	}
+}
+
+func getHelmOperatorCRD(config helmOperatorConfig) map[string]interface{} {
+	return map[string]interface{}{
+		"apiVersion": "apiextensions.k8s.io/v1",
+		"kind":       "CustomResourceDefinition",
+		"metadata":   map[string]interface{}{"name": config.plural + "." + config.group},
+		"spec": map[string]interface{}{
+			"group": config.group,
+			"names": map[string]interface{}{
+				"kind":     config.kind,
+				"listKind": config.kind + "List",
+				"plural":   config.plural,
+				"singular": strings.ToLower(config.kind),
+			},
+			"scope": "Namespaced",
+			"versions": []map[string]interface{}{{
+				"name":    helmOperatorVersion,
+				"served":  true,
+				"storage": true,
+				"schema": map[string]interface{}{
+					"openAPIV3Schema": map[string]interface{}{
+						"description": config.kind + " is the Schema for the " + config.plural + " API",
+						"type":        "object",
+						"properties": map[string]interface{}{
+							"apiVersion": map[string]interface{}{"type": "string"},
+							"kind":       map[string]interface{}{"type": "string"},
+							"metadata":   map[string]interface{}{"type": "object"},
+							"spec": map[string]interface{}{
+								"description":                          "Spec contains the values of the Helm chart",
+								"type":                                 "object",
+								"x-kubernetes-preserve-unknown-fields": true,
+							},
+							"status": map[string]interface{}{
+								"type":                                 "object",
+								"x-kubernetes-preserve-unknown-fields": true,
+							},
+						},
+					},
+				},
+				"subresources": map[string]interface{}{"status": map[string]interface{}{}},
+			}},
+		},
+	}
+}
+
+// getHelmOperatorRole returns the role that allows the operator to manage the custom resources and the resources in the helm chart
+func getHelmOperatorRole(config helmOperatorConfig, yamlsPath string) map[string]interface{} {
+	allVerbs := []string{"create", "delete", "get", "list", "patch", "update", "watch"}
+	rules := []map[string]interface{}{
+		{"apiGroups": []string{""}, "resources": []string{"namespaces"}, "verbs": []string{"get"}},
+		{"apiGroups": []string{""}, "resources": []string{"secrets"}, "verbs": allVerbs},
+		{"apiGroups": []string{""}, "resources": []string{"events"}, "verbs": []string{"create"}},
+		{"apiGroups": []string{config.group}, "resources": []string{config.plural, config.plural + "/status", config.plural + "/finalizers"}, "verbs": allVerbs},
+	}
+	groups := []string{}
+	if pathedKs, err := k8sschema.GetK8sResourcesWithPaths(yamlsPath, false); err == nil {
+		for _, ks := range pathedKs {
+			for _, k := range ks {
+				_, apiVersion, _, err := k8sschema.GetInfoFromK8sResource(k)
+				if err != nil {
+					continue
+				}
+				gv, err := schema.ParseGroupVersion(apiVersion)
+				if err != nil {
+					continue
+				}
+				groups = common.AppendIfNotPresent(groups, gv.Group)
+			}
+		}
+	}
+	sort.Strings(groups)
+	for _, group := range groups {
+		rules = append(rules, map[string]interface{}{"apiGroups": []string{group}, "resources": []string{"*"}, "verbs": allVerbs})
+	}
+	return map[string]interface{}{
+		"apiVersion": "rbac.authorization.k8s.io/v1",
+		"kind":       "ClusterRole",
+		"metadata":   map[string]interface{}{"name": config.chartName + "-operator-manager-role"},
+		"rules":      rules,
+	}
+}
+
+func getHelmOperatorRoleBinding(config helmOperatorConfig) map[string]interface{} {
+	return map[string]interface{}{
+		"apiVersion": "rbac.authorization.k8s.io/v1",
+		"kind":       "ClusterRoleBinding",
+		"metadata":   map[string]interface{}{"name": config.chartName + "-operator-manager-rolebinding"},
+		"roleRef": map[string]interface{}{
+			"apiGroup": "rbac.authorization.k8s.io",
+			"kind":     "ClusterRole",
+			"name":     config.chartName + "-operator-manager-role",
+		},
+		"subjects": []map[string]interface{}{{
+			"kind":      "ServiceAccount",
+			"name":      config.chartName + "-operator-controller-manager",
+			"namespace": config.chartName + "-operator-system",
+		}},
+	}
+}
+
+// getHelmOperatorLeaderElectionRole returns the role that allows the operator to elect a leader in its own namespace
+func getHelmOperatorLeaderElectionRole(config helmOperatorConfig) map[string]interface{} {
+	allVerbs := []string{"create", "delete", "get", "list", "patch", "update", "watch"}
+	return map[string]interface{}{
+		"apiVersion": "rbac.authorization.k8s.io/v1",
+		"kind":       "Role",
+		"metadata": map[string]interface{}{
+			"name":      config.chartName + "-operator-leader-election-role",
+			"namespace": config.chartName + "-operator-system",
+		},
+		"rules": []map[string]interface{}{
+			{"apiGroups": []string{""}, "resources": []string{"configmaps"}, "verbs": allVerbs},
+			{"apiGroups": []string{"coordination.k8s.io"}, "resources": []string{"leases"}, "verbs": allVerbs},
+			{"apiGroups": []string{""}, "resources": []string{"events"}, "verbs": []string{"create", "patch"}},
+		},
+	}
+}
+
+func getHelmOperatorLeaderElectionRoleBinding(config helmOperatorConfig) map[string]interface{} {
+	return map[string]interface{}{
+		"apiVersion": "rbac.authorization.k8s.io/v1",
+		"kind":       "RoleBinding",
+		"metadata": map[string]interface{}{
+			"name":      config.chartName + "-operator-leader-election-rolebinding",
+			"namespace": config.chartName + "-operator-system",
+		},
+		"roleRef": map[string]interface{}{
+			"apiGroup": "rbac.authorization.k8s.io",
+			"kind":     "Role",
+			"name":     config.chartName + "-operator-leader-election-role",
+		},
+		"subjects": []map[string]interface{}{{
+			"kind":      "ServiceAccount",
+			"name":      config.chartName + "-operator-controller-manager",
+			"namespace": config.chartName + "-operator-system",
+		}},
+	}
+}
+
+func getHelmOperatorServiceAccount(config helmOperatorConfig) map[string]interface{} {
+	return map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ServiceAccount",
+		"metadata": map[string]interface{}{
+			"name":      config.chartName + "-operator-controller-manager",
+			"namespace": config.chartName + "-operator-system",
+		},
+	}
+}
+
+func getHelmOperatorManager(config helmOperatorConfig) []map[string]interface{} {
+	labels := map[string]interface{}{"control-plane": "controller-manager", "app.kubernetes.io/name": config.chartName + "-operator"}
+	return []map[string]interface{}{{
+		"apiVersion": "v1",
+		"kind":       "Namespace",
+		"metadata":   map[string]interface{}{"name": config.chartName + "-operator-system", "labels": labels},
+	}, {
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":      config.chartName + "-operator-controller-manager",
+			"namespace": config.chartName + "-operator-system",
+			"labels":    labels,
+		},
+		"spec": map[string]interface{}{
+			"replicas": 1,
+			"selector": map[string]interface{}{"matchLabels": labels},
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{"labels": labels},
+				"spec": map[string]interface{}{
+					"serviceAccountName": config.chartName + "-operator-controller-manager",
+					"containers": []map[string]interface{}{{
+						"name":  "manager",
+						"image": config.image,
+						"args":  []string{"--leader-elect", "--leader-election-id=" + config.chartName + "-operator"},
+						"livenessProbe": map[string]interface{}{
+							"httpGet":             map[string]interface{}{"path": "/healthz", "port": 8081},
+							"initialDelaySeconds": 15,
+							"periodSeconds":       20,
+						},
+						"readinessProbe": map[string]interface{}{
+							"httpGet":             map[string]interface{}{"path": "/readyz", "port": 8081},
+							"initialDelaySeconds": 5,
+							"periodSeconds":       10,
+						},
+						"resources": map[string]interface{}{
+							"limits":   map[string]interface{}{"cpu": "500m", "memory": "128Mi"},
+							"requests": map[string]interface{}{"cpu": "10m", "memory": "64Mi"},
+						},
+					}},
+					"terminationGracePeriodSeconds": 10,
+				},
+			},
+		},
+	}}
+}
+
+// getHelmOperatorSample returns a custom resource that installs the helm chart with the values of the environment
+func getHelmOperatorSample(config helmOperatorConfig, env string, values map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"apiVersion": config.group + "/" + helmOperatorVersion,
+		"kind":       config.kind,
+		"metadata":   map[string]interface{}{"name": config.chartName + env},
+		"spec":       values,
+	}
+}
@@ -0,0 +1,137 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+)
+
+func TestGenerateHelmOperator(t *testing.T) {
+	common.IgnoreEnvironment = true
+	qaengine.ResetEngines()
+	defer qaengine.ResetEngines()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", []string{`move2kube.parameterization.helmoperator."other".domain="other.io"`}, nil, nil, false)
+	yamlsPath := t.TempDir()
+	deployment := "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\n"
+	if err := os.WriteFile(filepath.Join(yamlsPath, "web-deployment.yaml"), []byte(deployment), common.DefaultFilePermission); err != nil {
+		t.Fatalf("failed to write the deployment. Error: %q", err)
+	}
+	if !hasWorkloads(yamlsPath) {
+		t.Fatalf("expected the yamls to have workloads")
+	}
+	chartDir := filepath.Join(t.TempDir(), "my-app")
+	if err := os.MkdirAll(filepath.Join(chartDir, "templates"), common.DefaultDirectoryPermission); err != nil {
+		t.Fatalf("failed to create the chart directory. Error: %q", err)
+	}
+	if err := os.WriteFile(filepath.Join(chartDir, "values-dev.yaml"), []byte("common:\n  replicas: 2\n"), common.DefaultFilePermission); err != nil {
+		t.Fatalf("failed to write the values. Error: %q", err)
+	}
+	operatorDir := t.TempDir()
+	if err := generateHelmOperator("my-app", yamlsPath, chartDir, operatorDir); err != nil {
+		t.Fatalf("failed to generate the helm operator. Error: %q", err)
+	}
+	for _, path := range []string{
+		"Dockerfile",
+		"PROJECT",
+		"watches.yaml",
+		"helm-charts/my-app/values-dev.yaml",
+		"config/crd/bases/my-app.example.com_myapps.yaml",
+		"config/rbac/role.yaml",
+		"config/rbac/leader_election_role.yaml",
+		"config/rbac/leader_election_role_binding.yaml",
+		"config/manager/manager.yaml",
+		"config/samples/my-app.example.com_v1alpha1_myapp-dev.yaml",
+		"bundle.Dockerfile",
+		"bundle/manifests/my-app-operator.clusterserviceversion.yaml",
+		"bundle/manifests/my-app.example.com_myapps.yaml",
+		"bundle/metadata/annotations.yaml",
+	} {
+		if _, err := os.Stat(filepath.Join(operatorDir, path)); err != nil {
+			t.Fatalf("expected the file %s to be generated. Error: %q", path, err)
+		}
+	}
+	watches := []map[string]interface{}{}
+	if err := common.ReadYaml(filepath.Join(operatorDir, "watches.yaml"), &watches); err != nil {
+		t.Fatalf("failed to read the watches. Error: %q", err)
+	}
+	if len(watches) != 1 || watches[0]["kind"] != "MyApp" || watches[0]["group"] != "my-app.example.com" || watches[0]["chart"] != "helm-charts/my-app" {
+		t.Fatalf("wrong watches. Actual: %+v", watches)
+	}
+	csv := map[string]interface{}{}
+	if err := common.ReadYaml(filepath.Join(operatorDir, "bundle/manifests/my-app-operator.clusterserviceversion.yaml"), &csv); err != nil {
+		t.Fatalf("failed to read the cluster service version. Error: %q", err)
+	}
+	install, _ := csv["spec"].(map[string]interface{})["install"].(map[string]interface{})
+	deployments, _ := install["spec"].(map[string]interface{})["deployments"].([]interface{})
+	if install["strategy"] != "deployment" || len(deployments) != 1 {
+		t.Fatalf("expected the install strategy to contain the deployment of the manager. Actual: %+v", install)
+	}
+	permissions, _ := install["spec"].(map[string]interface{})["permissions"].([]interface{})
+	if len(permissions) != 1 {
+		t.Fatalf("expected the install strategy to contain the namespaced permissions for the leader election. Actual: %+v", install)
+	}
+	role := map[string]interface{}{}
+	if err := common.ReadYaml(filepath.Join(operatorDir, "config/rbac/role.yaml"), &role); err != nil {
+		t.Fatalf("failed to read the role. Error: %q", err)
+	}
+	for _, rule := range role["rules"].([]interface{}) {
+		for _, resource := range rule.(map[string]interface{})["resources"].([]interface{}) {
+			if resource == "leases" || resource == "configmaps" {
+				t.Fatalf("expected the leader election rules to be in the namespaced role only. Actual: %+v", role)
+			}
+		}
+	}
+	podSpec := deployments[0].(map[string]interface{})["spec"].(map[string]interface{})["template"].(map[string]interface{})["spec"].(map[string]interface{})
+	image := podSpec["containers"].([]interface{})[0].(map[string]interface{})["image"]
+	if image != "quay.io/"+common.ProjectName+"/my-app-operator:v"+olmBundleVersion {
+		t.Fatalf("expected the image of the operator to be pinned to the version of the bundle. Actual: %s", image)
+	}
+}
+
+func TestGetHelmOperatorConfig(t *testing.T) {
+	common.IgnoreEnvironment = true
+	qaengine.ResetEngines()
+	defer qaengine.ResetEngines()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", []string{
+		`move2kube.parameterization.helmoperator."shop".domain="shop.io"`,
+		`move2kube.parameterization.helmoperator."shop".image="quay.io/acme/shop-operator@sha256:1234"`,
+	}, nil, nil, false)
+	config := getHelmOperatorConfig("shop", "shop")
+	if config.group != "shop.shop.io" || config.image != "quay.io/acme/shop-operator@sha256:1234" {
+		t.Fatalf("expected the answers of the project to be used. Actual: %+v", config)
+	}
+	if config := getHelmOperatorConfig("billing", "billing"); config.group != "billing.example.com" {
+		t.Fatalf("expected the answers of the other projects not to be used. Actual: %+v", config)
+	}
+	for image, valid := range map[string]bool{
+		"quay.io/acme/shop-operator:v0.1.0":      true,
+		"quay.io/acme/shop-operator@sha256:1234": true,
+		"quay.io/acme/shop-operator:latest":      false,
+		"quay.io/acme/shop-operator":             false,
+	} {
+		if err := validateHelmOperatorImage(image); (err == nil) != valid {
+			t.Fatalf("expected the image %s to be valid: %t . Error: %v", image, valid, err)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/common/report"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/konveyor/move2kube/types/qaengine/commonqa"
+	"github.com/sirupsen/logrus"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+const (
+	// the ways of handling a capability that is not allowed by the admission policies of the cluster
+	dropCapabilityOpt   = "Drop the capability"
+	exemptCapabilityOpt = "Keep the capability and generate the policy exemptions"
+	keepCapabilityOpt   = "Keep the capability as is"
+)
+
+// capabilityAlternatives describe what the commonly blocked capabilities are used for and what can be used instead
+var capabilityAlternatives = map[string]string{
+	"NET_ADMIN":  "NET_ADMIN is commonly used to change the routes or the iptables rules, which a CNI plugin or a service mesh can often do instead",
+	"NET_RAW":    "NET_RAW is commonly used by ping and packet capture tools, which are rarely needed by the app itself",
+	"SYS_ADMIN":  "SYS_ADMIN is commonly used to mount file systems, which k8s volumes can often do instead",
+	"SYS_PTRACE": "SYS_PTRACE is commonly used by debuggers and profilers, which can be attached using kubectl debug instead",
+	"SYS_TIME":   "SYS_TIME changes the clock of the node, which is usually kept in sync by the cluster instead",
+}
+
+// capabilityPolicyPreprocessor handles the capabilities added to the containers that the admission policies of the cluster would reject,
+// like the ones disallowed by the Pod Security Standard of the namespace or by Kyverno and Gatekeeper policies
+type capabilityPolicyPreprocessor struct {
+}
+
+func (cp capabilityPolicyPreprocessor) preprocess(ir irtypes.IR, targetCluster collection.ClusterMetadata) (irtypes.IR, error) {
+	policies := targetCluster.Spec.GetCapabilityPolicies()
+	// the restricted Pod Security Standard drops all the capabilities that could be rejected
+	if len(ir.Services) == 0 || len(policies) == 0 || commonqa.PodSecurityRestricted() {
+		return ir, nil
+	}
+	for _, serviceName := range ir.GetSortedServiceNames() {
+		service := ir.Services[serviceName]
+		for _, capability := range getAddedCapabilities(service) {
+			violatedPolicies := getViolatedCapabilityPolicies(capability, policies)
+			if len(violatedPolicies) == 0 {
+				continue
+			}
+			switch getCapabilityPolicyHandling(serviceName, capability, violatedPolicies) {
+			case dropCapabilityOpt:
+				logrus.Infof("Dropping the capability '%s' of the service '%s' since it is not allowed by the policies %+v", capability, serviceName, violatedPolicies)
+				removeCapability(&service, capability)
+			case exemptCapabilityOpt:
+				addPolicyExemptions(&service, capability, violatedPolicies)
+			default:
+				logrus.Warnf("The pods of the service '%s' will be rejected since the capability '%s' is not allowed by the policies %+v", serviceName, capability, violatedPolicies)
+				report.AddFollowUp(serviceName, fmt.Sprintf("Allow the capability %s in the policies %s, or remove it from the service if it is not needed", capability, strings.Join(violatedPolicies, ", ")))
+			}
+		}
+		ir.Services[serviceName] = service
+	}
+	return ir, nil
+}
+
+// getCapabilityPolicyHandling asks how to handle a capability of the service that is not allowed by the admission policies
+func getCapabilityPolicyHandling(serviceName, capability string, violatedPolicies []string) string {
+	hints := []string{fmt.Sprintf("The capability is not allowed by the admission policies %s of the target cluster.", strings.Join(violatedPolicies, ", "))}
+	if alternative, ok := capabilityAlternatives[capability]; ok {
+		hints = append(hints, alternative)
+	}
+	hints = append(hints, "The exemptions are a namespace with a lower Pod Security Standard level and Kyverno policy exceptions. The Gatekeeper constraints have to be changed manually.")
+	return qaengine.FetchSelectAnswer(
+		common.JoinQASubKeys(common.ConfigServicesKey, `"`+serviceName+`"`, common.ConfigCapabilityPolicyForServiceKeySegment, `"`+capability+`"`),
+		fmt.Sprintf("The service '%s' adds the capability '%s' which the target cluster rejects. What should be done with it?", serviceName, capability),
+		hints,
+		// dropping the capability could break the service, so it is kept unless asked otherwise
+		keepCapabilityOpt,
+		[]string{dropCapabilityOpt, exemptCapabilityOpt, keepCapabilityOpt},
+		nil,
+	)
+}
+
+// getAddedCapabilities returns the capabilities added to the containers of the service
+func getAddedCapabilities(service irtypes.Service) []string {
+	capabilities := []string{}
+	for _, container := range append(append([]core.Container{}, service.InitContainers...), service.Containers...) {
+		if container.SecurityContext == nil || container.SecurityContext.Capabilities == nil {
+			continue
+		}
+		for _, capability := range container.SecurityContext.Capabilities.Add {
+			capabilities = common.AppendIfNotPresent(capabilities, normalizeCapability(capability))
+		}
+	}
+	sort.Strings(capabilities)
+	return capabilities
+}
+
+// getViolatedCapabilityPolicies returns the admission policies which do not allow the capability
+func getViolatedCapabilityPolicies(capability string, policies map[string][]string) []string {
+	violatedPolicies := []string{}
+	for name, allowedCapabilities := range policies {
+		if !common.IsPresent(allowedCapabilities, capability) {
+			violatedPolicies = append(violatedPolicies, name)
+		}
+	}
+	sort.Strings(violatedPolicies)
+	return violatedPolicies
+}
+
+// addPolicyExemptions records the policies the pods of the service have to be exempted from to add the capability.
+// The Pod Security Standard is exempted by using the lowest level allowing the capability.
+func addPolicyExemptions(service *irtypes.Service, capability string, violatedPolicies []string) {
+	for _, policy := range violatedPolicies {
+		switch {
+		case strings.HasPrefix(policy, collection.PodSecurityPolicyPrefix):
+			level := "privileged"
+			if common.IsPresent(collection.PodSecurityBaselineCapabilities, capability) {
+				level = "baseline"
+			}
+			service.PolicyExemptions = common.AppendIfNotPresent(service.PolicyExemptions, collection.PodSecurityPolicyPrefix+level)
+		case strings.HasPrefix(policy, collection.KyvernoPolicyPrefix):
+			service.PolicyExemptions = common.AppendIfNotPresent(service.PolicyExemptions, policy)
+		default:
+			constraint := strings.TrimPrefix(policy, collection.GatekeeperPolicyPrefix)
+			report.AddFollowUp(service.Name, fmt.Sprintf("Allow the capability %s in the Gatekeeper constraint %s, or exclude the namespace of the service from it", capability, constraint))
+		}
+	}
+}
+
+// removeCapability removes the capability from the capabilities added to the containers of the service
+func removeCapability(service *irtypes.Service, capability string) {
+	for _, containers := range [][]core.Container{service.InitContainers, service.Containers} {
+		for i := range containers {
+			if containers[i].SecurityContext == nil || containers[i].SecurityContext.Capabilities == nil {
+				continue
+			}
+			capabilities := []core.Capability{}
+			for _, added := range containers[i].SecurityContext.Capabilities.Add {
+				if normalizeCapability(added) != capability {
+					capabilities = append(capabilities, added)
+				}
+			}
+			containers[i].SecurityContext.Capabilities.Add = capabilities
+		}
+	}
+}
+
+// normalizeCapability returns the name of the capability without the CAP_ prefix
+func normalizeCapability(capability core.Capability) string {
+	return strings.TrimPrefix(strings.ToUpper(string(capability)), "CAP_")
+}
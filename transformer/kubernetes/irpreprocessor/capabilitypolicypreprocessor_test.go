@@ -0,0 +1,62 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestCapabilityPolicyPreprocessor(t *testing.T) {
+	qaengine.ResetEngines()
+	defer qaengine.ResetEngines()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", []string{
+		`move2kube.podsecurity.restricted=false`,
+		`move2kube.services."vpn".capabilitypolicy."NET_ADMIN"="Keep the capability and generate the policy exemptions"`,
+		`move2kube.services."vpn".capabilitypolicy."SYS_PTRACE"="Drop the capability"`,
+	}, nil, nil, false)
+	ir := irtypes.NewIR()
+	vpn := irtypes.NewServiceWithName("vpn")
+	vpn.Containers = []core.Container{{
+		Name:            "vpn",
+		SecurityContext: &core.SecurityContext{Capabilities: &core.Capabilities{Add: []core.Capability{"CHOWN", "NET_ADMIN", "CAP_SYS_ADMIN", "SYS_PTRACE"}}},
+	}}
+	ir.Services["vpn"] = vpn
+	targetCluster := collection.ClusterMetadata{Spec: collection.ClusterMetadataSpec{
+		PodSecurityLevel:   "baseline",
+		CapabilityPolicies: map[string][]string{"kyverno/disallow-capabilities": collection.PodSecurityBaselineCapabilities},
+	}}
+
+	preprocessedIR, err := capabilityPolicyPreprocessor{}.preprocess(ir, targetCluster)
+	if err != nil {
+		t.Fatalf("failed to preprocess the IR. Error: %q", err)
+	}
+	vpn = preprocessedIR.Services["vpn"]
+	// the SYS_ADMIN capability is kept by default
+	if diff := cmp.Diff([]core.Capability{"CHOWN", "NET_ADMIN", "CAP_SYS_ADMIN"}, vpn.Containers[0].SecurityContext.Capabilities.Add); diff != "" {
+		t.Fatalf("wrong capabilities. Difference:\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"kyverno/disallow-capabilities", "pod-security/privileged"}, vpn.PolicyExemptions); diff != "" {
+		t.Fatalf("wrong policy exemptions. Difference:\n%s", diff)
+	}
+}
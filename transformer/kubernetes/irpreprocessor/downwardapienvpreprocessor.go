@@ -0,0 +1,77 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/konveyor/move2kube/types/qaengine/commonqa"
+	"github.com/sirupsen/logrus"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+// downwardAPIEnvs are the env vars with the pod and node information and the fields they get their values from
+var downwardAPIEnvs = []struct {
+	name      string
+	fieldPath string
+}{
+	{name: "POD_NAME", fieldPath: "metadata.name"},
+	{name: "POD_NAMESPACE", fieldPath: "metadata.namespace"},
+	{name: "POD_IP", fieldPath: "status.podIP"},
+	{name: "NODE_NAME", fieldPath: "spec.nodeName"},
+}
+
+// downwardAPIEnvPreprocessor injects the env vars with the pod and node information into the containers
+type downwardAPIEnvPreprocessor struct {
+}
+
+func (dp downwardAPIEnvPreprocessor) preprocess(ir irtypes.IR, targetCluster collection.ClusterMetadata) (irtypes.IR, error) {
+	for _, serviceName := range ir.GetSortedServiceNames() {
+		service := ir.Services[serviceName]
+		if len(service.Containers) == 0 || !commonqa.DownwardAPIEnv(serviceName) {
+			continue
+		}
+		for i, container := range service.Containers {
+			container.Env = getEnvsWithDownwardAPI(container.Env)
+			service.Containers[i] = container
+		}
+		ir.Services[serviceName] = service
+	}
+	return ir, nil
+}
+
+// getEnvsWithDownwardAPI appends the downward API env vars that are not already set
+func getEnvsWithDownwardAPI(envs []core.EnvVar) []core.EnvVar {
+	for _, downwardAPIEnv := range downwardAPIEnvs {
+		isSet := false
+		for _, env := range envs {
+			if env.Name == downwardAPIEnv.name {
+				isSet = true
+				break
+			}
+		}
+		if isSet {
+			logrus.Debugf("The env var %s is already set. Not injecting it using the downward API", downwardAPIEnv.name)
+			continue
+		}
+		envs = append(envs, core.EnvVar{
+			Name:      downwardAPIEnv.name,
+			ValueFrom: &core.EnvVarSource{FieldRef: &core.ObjectFieldSelector{APIVersion: "v1", FieldPath: downwardAPIEnv.fieldPath}},
+		})
+	}
+	return envs
+}
@@ -0,0 +1,60 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"testing"
+
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestDownwardAPIEnvPreprocessor(t *testing.T) {
+	qaengine.ResetEngines()
+	defer qaengine.ResetEngines()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", []string{`move2kube.services."web".downwardapienv=true`}, nil, nil, false)
+	ir := irtypes.NewIR()
+	web := irtypes.NewServiceWithName("web")
+	web.Containers = []core.Container{{Name: "web", Env: []core.EnvVar{{Name: "NODE_NAME", Value: "custom"}}}}
+	ir.Services["web"] = web
+	db := irtypes.NewServiceWithName("db")
+	db.Containers = []core.Container{{Name: "db"}}
+	ir.Services["db"] = db
+
+	preprocessedIR, err := downwardAPIEnvPreprocessor{}.preprocess(ir, collection.ClusterMetadata{})
+	if err != nil {
+		t.Fatalf("failed to preprocess the IR. Error: %q", err)
+	}
+	envs := preprocessedIR.Services["web"].Containers[0].Env
+	if len(envs) != 4 || envs[0].Value != "custom" {
+		t.Fatalf("expected the downward API env vars to be added without replacing the existing ones. Actual: %+v", envs)
+	}
+	for _, env := range envs[1:] {
+		if env.ValueFrom == nil || env.ValueFrom.FieldRef == nil {
+			t.Fatalf("expected the env var %s to refer to a field of the pod. Actual: %+v", env.Name, env)
+		}
+	}
+	if envs[3].Name != "POD_IP" || envs[3].ValueFrom.FieldRef.FieldPath != "status.podIP" {
+		t.Fatalf("wrong env var. Actual: %+v", envs[3])
+	}
+	if envs := preprocessedIR.Services["db"].Containers[0].Env; len(envs) != 0 {
+		t.Fatalf("expected no env vars to be injected by default. Actual: %+v", envs)
+	}
+}
@@ -0,0 +1,71 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"fmt"
+
+	"github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/konveyor/move2kube/types/qaengine/commonqa"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+const (
+	// defaultTerminationGracePeriodSeconds is the time Kubernetes gives the containers to stop when it is not specified
+	defaultTerminationGracePeriodSeconds int64 = 30
+)
+
+// gracefulShutdownPreprocessor delays the stopping of the containers of the services that receive requests,
+// so that the in-flight requests are not dropped during the rollouts
+type gracefulShutdownPreprocessor struct {
+}
+
+func (gp gracefulShutdownPreprocessor) preprocess(ir irtypes.IR, targetCluster collection.ClusterMetadata) (irtypes.IR, error) {
+	for _, serviceName := range ir.GetSortedServiceNames() {
+		service := ir.Services[serviceName]
+		if len(service.Containers) == 0 || len(service.ServiceToPodPortForwardings) == 0 {
+			continue
+		}
+		delay := commonqa.PreStopDelay(serviceName)
+		if delay == 0 {
+			continue
+		}
+		for i, container := range service.Containers {
+			if container.Lifecycle == nil {
+				container.Lifecycle = &core.Lifecycle{}
+			}
+			if container.Lifecycle.PreStop == nil {
+				container.Lifecycle.PreStop = &core.LifecycleHandler{
+					Exec: &core.ExecAction{Command: []string{"sh", "-c", fmt.Sprintf("sleep %d", delay)}},
+				}
+			}
+			service.Containers[i] = container
+		}
+		// the grace period includes the time spent in the preStop hooks
+		terminationGracePeriodSeconds := defaultTerminationGracePeriodSeconds
+		if service.TerminationGracePeriodSeconds != nil {
+			terminationGracePeriodSeconds = *service.TerminationGracePeriodSeconds
+		}
+		if terminationGracePeriodSeconds < delay+defaultTerminationGracePeriodSeconds {
+			terminationGracePeriodSeconds = delay + defaultTerminationGracePeriodSeconds
+		}
+		service.TerminationGracePeriodSeconds = &terminationGracePeriodSeconds
+		ir.Services[serviceName] = service
+	}
+	return ir, nil
+}
@@ -0,0 +1,58 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"testing"
+
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	core "k8s.io/kubernetes/pkg/apis/core"
+	"k8s.io/kubernetes/pkg/apis/networking"
+)
+
+func TestGracefulShutdownPreprocessor(t *testing.T) {
+	qaengine.ResetEngines()
+	defer qaengine.ResetEngines()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", []string{`move2kube.services."web".prestopdelay="15"`, `move2kube.services."worker".prestopdelay="15"`}, nil, nil, false)
+	ir := irtypes.NewIR()
+	web := irtypes.NewServiceWithName("web")
+	web.Containers = []core.Container{{Name: "web"}}
+	web.AddPortForwarding(networking.ServiceBackendPort{Number: 80}, networking.ServiceBackendPort{Number: 8080}, "")
+	ir.Services["web"] = web
+	worker := irtypes.NewServiceWithName("worker")
+	worker.Containers = []core.Container{{Name: "worker"}}
+	ir.Services["worker"] = worker
+
+	preprocessedIR, err := gracefulShutdownPreprocessor{}.preprocess(ir, collection.ClusterMetadata{})
+	if err != nil {
+		t.Fatalf("failed to preprocess the IR. Error: %q", err)
+	}
+	web = preprocessedIR.Services["web"]
+	lifecycle := web.Containers[0].Lifecycle
+	if lifecycle == nil || lifecycle.PreStop == nil || lifecycle.PreStop.Exec == nil || lifecycle.PreStop.Exec.Command[2] != "sleep 15" {
+		t.Fatalf("expected a preStop hook sleeping for the delay. Actual: %+v", lifecycle)
+	}
+	if web.TerminationGracePeriodSeconds == nil || *web.TerminationGracePeriodSeconds != 45 {
+		t.Fatalf("expected the grace period to include the delay. Actual: %v", web.TerminationGracePeriodSeconds)
+	}
+	if worker := preprocessedIR.Services["worker"]; worker.Containers[0].Lifecycle != nil || worker.TerminationGracePeriodSeconds != nil {
+		t.Fatalf("expected the service without ports to be unchanged. Actual: %+v", worker)
+	}
+}
@@ -28,12 +28,20 @@ import (
 	core "k8s.io/kubernetes/pkg/apis/core"
 )
 
+const (
+	// the ways of exposing a port that is published on a random host port in the source
+	clusterIPOnlyExposure = "Only inside the cluster using a ClusterIP service"
+	nodePortExposure      = "On a NodePort assigned by the cluster"
+	portForwardExposure   = "Using a port-forward script"
+)
+
 // ingressPreprocessor optimizes the ingress options of the application
 type ingressPreprocessor struct {
 }
 
 func (opt *ingressPreprocessor) preprocess(ir irtypes.IR, targetCluster collection.ClusterMetadata) (irtypes.IR, error) {
-	for serviceName, service := range ir.Services {
+	for _, serviceName := range ir.GetSortedServiceNames() {
+		service := ir.Services[serviceName]
 		tempService := ir.Services[serviceName]
 		for portForwardingIdx, portForwarding := range service.ServiceToPodPortForwardings {
 			if portForwarding.ServicePort.Number == 0 {
@@ -47,12 +55,41 @@ func (opt *ingressPreprocessor) preprocess(ir irtypes.IR, targetCluster collecti
 				portForwarding.ServiceType = core.ServiceTypeClusterIP
 			}
 			noneServiceType := "Don't create service"
-			portKeyPart := common.JoinQASubKeys(common.ConfigServicesKey, `"`+serviceName+`"`, `"`+cast.ToString(portForwarding.ServicePort.Number)+`"`)
+			port := cast.ToString(portForwarding.ServicePort.Number)
+			if protocol := portForwarding.GetProtocol(); protocol != "" {
+				port += "/" + strings.ToLower(string(protocol))
+			}
+			portKeyPart := common.JoinQASubKeys(common.ConfigServicesKey, `"`+serviceName+`"`, `"`+port+`"`)
+			if portForwarding.Ephemeral {
+				portForwarding.ServiceType, portForwarding.PortForward = opt.getEphemeralPortExposure(serviceName, port, portKeyPart, portForwarding.GetProtocol())
+				portForwarding.ServiceRelPath = ""
+				tempService.ServiceToPodPortForwardings[portForwardingIdx] = portForwarding
+				continue
+			}
 			options := []string{common.IngressKind, string(core.ServiceTypeLoadBalancer), string(core.ServiceTypeNodePort), string(core.ServiceTypeClusterIP), noneServiceType}
-			desc := fmt.Sprintf("What kind of service/ingress should be created for the service %s's %d port?", serviceName, portForwarding.ServicePort.Number)
+			desc := fmt.Sprintf("What kind of service/ingress should be created for the service %s's %s port?", serviceName, port)
 			hints := []string{"Choose " + common.IngressKind + " if you want a ingress/route resource to be created"}
 			quesKey := common.JoinQASubKeys(portKeyPart, "servicetype")
-			portForwarding.ServiceType = core.ServiceType(qaengine.FetchSelectAnswer(quesKey, desc, hints, common.IngressKind, options, nil))
+			def := common.IngressKind
+			if portForwarding.GetProtocol() != "" {
+				// ingresses and routes only support HTTP
+				options = options[1:]
+				hints = []string{"The port uses the " + string(portForwarding.Protocol) + " protocol, which can not be exposed using an ingress/route"}
+				def = string(core.ServiceTypeLoadBalancer)
+				if !targetCluster.Spec.SupportsLoadBalancer() {
+					def = string(core.ServiceTypeNodePort)
+				}
+			} else if !targetCluster.Spec.SupportsIngress() {
+				def = string(core.ServiceTypeLoadBalancer)
+				hints = append(hints, "The target cluster does not have an ingress controller")
+				if !targetCluster.Spec.SupportsLoadBalancer() {
+					def = string(core.ServiceTypeNodePort)
+				}
+			}
+			if !targetCluster.Spec.SupportsLoadBalancer() {
+				hints = append(hints, "The target cluster does not provision load balancers for services of type "+string(core.ServiceTypeLoadBalancer))
+			}
+			portForwarding.ServiceType = core.ServiceType(qaengine.FetchSelectAnswer(quesKey, desc, hints, def, options, nil))
 			if string(portForwarding.ServiceType) == noneServiceType {
 				portForwarding.ServiceType = ""
 			}
@@ -71,3 +108,21 @@ func (opt *ingressPreprocessor) preprocess(ir irtypes.IR, targetCluster collecti
 	}
 	return ir, nil
 }
+
+// getEphemeralPortExposure asks how to expose a port that is published on a random host port in the source
+func (opt *ingressPreprocessor) getEphemeralPortExposure(serviceName, port, portKeyPart string, protocol core.Protocol) (core.ServiceType, bool) {
+	options := []string{nodePortExposure, clusterIPOnlyExposure}
+	hints := []string{"The port is published on a random host port in the source, so it is not exposed using an ingress/route"}
+	if protocol == "" {
+		options = append(options, portForwardExposure)
+		hints = append(hints, "The port-forward script forwards a random local port to the port, like the source does")
+	}
+	desc := fmt.Sprintf("How should the service %s's %s port be exposed?", serviceName, port)
+	switch qaengine.FetchSelectAnswer(common.JoinQASubKeys(portKeyPart, "exposure"), desc, hints, nodePortExposure, options, nil) {
+	case nodePortExposure:
+		return core.ServiceTypeNodePort, false
+	case portForwardExposure:
+		return core.ServiceTypeClusterIP, true
+	}
+	return core.ServiceTypeClusterIP, false
+}
@@ -0,0 +1,69 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	core "k8s.io/kubernetes/pkg/apis/core"
+	"k8s.io/kubernetes/pkg/apis/networking"
+)
+
+func TestIngressPreprocessorEphemeralPorts(t *testing.T) {
+	qaengine.ResetEngines()
+	defer qaengine.ResetEngines()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	// the port has to be quoted, which is not possible using a config string
+	configFile := filepath.Join(t.TempDir(), "m2kconfig.yaml")
+	config := "move2kube:\n  services:\n    web:\n      \"9090\":\n        exposure: " + portForwardExposure + "\n"
+	if err := os.WriteFile(configFile, []byte(config), 0644); err != nil {
+		t.Fatalf("failed to write the config file. Error: %q", err)
+	}
+	qaengine.SetupConfigFile("", nil, []string{configFile}, nil, false)
+	ir := irtypes.NewIR()
+	web := irtypes.NewServiceWithName("web")
+	for _, forwarding := range []irtypes.ServiceToPodPortForwarding{
+		{ServicePort: networking.ServiceBackendPort{Number: 8080}, PodPort: networking.ServiceBackendPort{Number: 8080}, Ephemeral: true},
+		{ServicePort: networking.ServiceBackendPort{Number: 9090}, PodPort: networking.ServiceBackendPort{Number: 9090}, Ephemeral: true},
+		{ServicePort: networking.ServiceBackendPort{Number: 5353}, PodPort: networking.ServiceBackendPort{Number: 5353}, Protocol: core.ProtocolUDP},
+	} {
+		if err := web.MergePortForwarding(forwarding); err != nil {
+			t.Fatalf("failed to add the port forwarding. Error: %q", err)
+		}
+	}
+	ir.Services["web"] = web
+
+	preprocessedIR, err := (&ingressPreprocessor{}).preprocess(ir, collection.ClusterMetadata{})
+	if err != nil {
+		t.Fatalf("failed to preprocess the IR. Error: %q", err)
+	}
+	forwardings := preprocessedIR.Services["web"].ServiceToPodPortForwardings
+	if forwardings[0].ServiceType != core.ServiceTypeNodePort || forwardings[0].PortForward || forwardings[0].ServiceRelPath != "" {
+		t.Fatalf("expected the ephemeral port to be exposed using a NodePort by default. Actual: %+v", forwardings[0])
+	}
+	if forwardings[1].ServiceType != core.ServiceTypeClusterIP || !forwardings[1].PortForward {
+		t.Fatalf("expected the ephemeral port to be port forwarded. Actual: %+v", forwardings[1])
+	}
+	if forwardings[2].ServiceType == "" || forwardings[2].ServiceRelPath != "" {
+		t.Fatalf("expected the UDP port to be exposed without an ingress. Actual: %+v", forwardings[2])
+	}
+}
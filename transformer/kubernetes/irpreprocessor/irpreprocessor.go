@@ -17,6 +17,8 @@
 package irpreprocessor
 
 import (
+	"fmt"
+
 	"github.com/konveyor/move2kube/types/collection"
 	irtypes "github.com/konveyor/move2kube/types/ir"
 	"github.com/sirupsen/logrus"
@@ -29,12 +31,13 @@ type irpreprocessor interface {
 
 // getIRPreprocessors returns optimizers
 func getIRPreprocessors() []irpreprocessor {
-	var l = []irpreprocessor{new(mergePreprocessor), new(normalizeCharacterPreprocessor), new(statefulsetPreprocessor), new(ingressPreprocessor), new(replicaPreprocessor), 
-		new(imagePullPolicyPreprocessor), new(registryPreProcessor)}
+	var l = []irpreprocessor{new(mergePreprocessor), new(normalizeCharacterPreprocessor), new(sharedEnvPreprocessor), new(statefulsetPreprocessor), new(ingressPreprocessor), new(replicaPreprocessor),
+		new(imagePullPolicyPreprocessor), new(registryPreProcessor), new(storageSizePreprocessor), new(securityContextPreprocessor), new(securityBaselinePreprocessor), new(capabilityPolicyPreprocessor), new(namingConventionPreprocessor), new(resourcePresetPreprocessor), new(podAntiAffinityPreprocessor), new(topologySpreadPreprocessor), new(priorityClassPreprocessor), new(downwardAPIEnvPreprocessor), new(gracefulShutdownPreprocessor), new(openTelemetryPreprocessor), new(veleroBackupPreprocessor), new(podSecurityPreprocessor)}
 	return l
 }
 
-// Preprocess preprocesses IR before application artifacts are generated
+// Preprocess preprocesses IR before application artifacts are generated.
+// The IR is validated once the characters have been normalized, so that the names and values fixed up by the normalization are not rejected.
 func Preprocess(ir irtypes.IR, targetCluster collection.ClusterMetadata) (irtypes.IR, error) {
 	optimizers := getIRPreprocessors()
 	logrus.Debug("Begin Optimization")
@@ -47,6 +50,11 @@ func Preprocess(ir irtypes.IR, targetCluster collection.ClusterMetadata) (irtype
 		} else {
 			logrus.Debugf("[%T] Done", o)
 		}
+		if _, ok := o.(*normalizeCharacterPreprocessor); ok {
+			if err := ir.Validate(); err != nil {
+				return ir, fmt.Errorf("the IR is invalid after normalization. Error: %w", err)
+			}
+		}
 	}
 	logrus.Debug("Optimization done")
 	return ir, nil
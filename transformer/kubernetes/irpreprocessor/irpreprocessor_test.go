@@ -0,0 +1,43 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestPreprocessValidatesTheNormalizedIR(t *testing.T) {
+	qaengine.ResetEngines()
+	defer qaengine.ResetEngines()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", nil, nil, nil, false)
+	ir := irtypes.NewIR()
+	ir.Name = "myproject"
+	web := irtypes.NewServiceWithName("web")
+	web.Containers = []core.Container{{Name: "web", Image: "web:latest", Ports: []core.ContainerPort{{ContainerPort: 70000}}}}
+	ir.AddService(web)
+	_, err := Preprocess(ir, collection.ClusterMetadata{})
+	if err == nil || !strings.Contains(err.Error(), "services[web].containers[0].ports[0].containerPort: Invalid value: 70000") {
+		t.Fatalf("expected the invalid container port to be reported. Actual: %v", err)
+	}
+}
@@ -34,11 +34,11 @@ func (opt *mergePreprocessor) preprocess(ir irtypes.IR, targetCluster collection
 		pfs := service.ServiceToPodPortForwardings
 		service.ServiceToPodPortForwardings = []irtypes.ServiceToPodPortForwarding{}
 		for _, pf := range pfs {
-			service.AddPortForwarding(pf.ServicePort, pf.PodPort, pf.ServiceRelPath)
+			service.MergePortForwarding(pf)
 		}
 		for _, c := range service.Containers {
 			for _, p := range c.Ports {
-				service.AddPortForwarding(networking.ServiceBackendPort{Number: p.ContainerPort}, networking.ServiceBackendPort{Number: p.ContainerPort}, "")
+				service.AddPortForwardingWithProtocol(networking.ServiceBackendPort{Number: p.ContainerPort}, networking.ServiceBackendPort{Number: p.ContainerPort}, "", p.Protocol)
 			}
 		}
 		tolerations := service.Tolerations
@@ -0,0 +1,113 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package irpreprocessor
+
+import (
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/konveyor/move2kube/types/qaengine/commonqa"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cast"
+)
+
+const (
+	defaultNameSeparator = "-"
+	defaultNameMaxLength = 63
+	nameHashLength       = 8
+)
+
+// namingConventionPreprocessor renames the services and storages using the naming convention and updates the references to them
+type namingConventionPreprocessor struct {
+}
+
+// namingConvention is the naming convention applied to the names of the generated resources
+type namingConvention struct {
+	prefix    string
+	suffix    string
+	separator string
+	maxLength int
+}
+
+func (np namingConventionPreprocessor) preprocess(ir irtypes.IR, targetCluster collection.ClusterMetadata) (irtypes.IR, error) {
+	maxLengthStr := commonqa.NameMaxLength(cast.ToString(defaultNameMaxLength))
+	maxLength, err := cast.ToIntE(maxLengthStr)
+	if err != nil {
+		logrus.Errorf("The maximum length %s is not a number. Reverting to default %d.", maxLengthStr, defaultNameMaxLength)
+		maxLength = defaultNameMaxLength
+	}
+	nc := namingConvention{
+		prefix:    strings.ToLower(strings.TrimSpace(commonqa.NamePrefix())),
+		suffix:    strings.ToLower(strings.TrimSpace(commonqa.NameSuffix())),
+		separator: commonqa.NameSeparator(defaultNameSeparator),
+		maxLength: maxLength,
+	}
+	if nc.prefix == "" && nc.suffix == "" && nc.maxLength >= defaultNameMaxLength {
+		return ir, nil
+	}
+	if ir.Name != "" {
+		ir.Name = nc.getName(ir.Name)
+	}
+	serviceNames := map[string]string{}
+	for _, service := range ir.Services {
+		serviceNames[service.Name] = nc.getName(service.Name)
+	}
+	storageNames := map[string]string{}
+	for i, storage := range ir.Storages {
+		storageNames[storage.Name] = nc.getName(storage.Name)
+		ir.Storages[i].Name = storageNames[storage.Name]
+	}
+	services := map[string]irtypes.Service{}
+	for _, service := range ir.Services {
+		service.Name = serviceNames[service.Name]
+		service.RenameServiceReferences(serviceNames)
+		service.PodSpec.RenameStorageReferences(storageNames)
+		services[service.Name] = service
+	}
+	ir.Services = services
+	return ir, nil
+}
+
+// getName returns the name following the naming convention, shortening the original name and adding a hash to it if it is too long
+func (nc namingConvention) getName(name string) string {
+	join := func(name string) string {
+		parts := []string{}
+		for _, part := range []string{nc.prefix, name, nc.suffix} {
+			if part != "" {
+				parts = append(parts, part)
+			}
+		}
+		return strings.Join(parts, nc.separator)
+	}
+	newName := join(name)
+	if len(newName) > nc.maxLength {
+		hash := common.GetSHA256Hash(name)[:nameHashLength]
+		shortLength := len(name) - (len(newName) - nc.maxLength) - len(hash) - 1
+		if shortLength > 0 {
+			hash = name[:shortLength] + "-" + hash
+		}
+		if newName = join(hash); len(newName) > nc.maxLength {
+			newName = newName[:nc.maxLength]
+		}
+	}
+	newName = common.ReplaceStartingTerminatingHyphens(common.MakeStringDNSNameCompliantWithoutDots(newName), "a", "z")
+	if newName != name {
+		logrus.Debugf("Changing the name %s to %s using the naming convention", name, newName)
+	}
+	return newName
+}
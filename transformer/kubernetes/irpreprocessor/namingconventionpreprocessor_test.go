@@ -0,0 +1,115 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package irpreprocessor
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestNamingConventionPreprocessor(t *testing.T) {
+	qaengine.ResetEngines()
+	defer qaengine.ResetEngines()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", []string{`move2kube.naming.prefix="acme"`, `move2kube.naming.suffix="prod"`, `move2kube.naming.maxlength="30"`}, nil, nil, false)
+	ir := irtypes.NewIR()
+	ir.Name = "myapp"
+	web := irtypes.NewServiceWithName("web")
+	web.Containers = []core.Container{{
+		Name: "web",
+		Env:  []core.EnvVar{{Name: "PASSWORD", ValueFrom: &core.EnvVarSource{SecretKeyRef: &core.SecretKeySelector{LocalObjectReference: core.LocalObjectReference{Name: "db-secret"}, Key: "password"}}}},
+	}}
+	web.Volumes = []core.Volume{{Name: "data", VolumeSource: core.VolumeSource{PersistentVolumeClaim: &core.PersistentVolumeClaimVolumeSource{ClaimName: "data"}}}}
+	ir.Services["web"] = web
+	longName := "a-service-with-a-really-long-name"
+	ir.Services[longName] = irtypes.NewServiceWithName(longName)
+	ir.Storages = []irtypes.Storage{{Name: "db-secret", StorageType: irtypes.SecretKind}, {Name: "data", StorageType: irtypes.PVCKind}}
+
+	preprocessedIR, err := namingConventionPreprocessor{}.preprocess(ir, collection.ClusterMetadata{})
+	if err != nil {
+		t.Fatalf("failed to preprocess the IR. Error: %q", err)
+	}
+	if preprocessedIR.Name != "acme-myapp-prod" {
+		t.Fatalf("expected the name of the project to follow the naming convention. Actual: %s", preprocessedIR.Name)
+	}
+	newWeb, ok := preprocessedIR.Services["acme-web-prod"]
+	if !ok || newWeb.Name != "acme-web-prod" {
+		t.Fatalf("expected the service to be renamed. Actual: %+v", preprocessedIR.Services)
+	}
+	if name := newWeb.Containers[0].Env[0].ValueFrom.SecretKeyRef.Name; name != "acme-db-secret-prod" {
+		t.Fatalf("expected the reference to the secret to be renamed. Actual: %s", name)
+	}
+	if name := newWeb.Volumes[0].PersistentVolumeClaim.ClaimName; name != "acme-data-prod" {
+		t.Fatalf("expected the reference to the pvc to be renamed. Actual: %s", name)
+	}
+	if preprocessedIR.Storages[0].Name != "acme-db-secret-prod" || preprocessedIR.Storages[1].Name != "acme-data-prod" {
+		t.Fatalf("expected the storages to be renamed. Actual: %+v", preprocessedIR.Storages)
+	}
+	for name := range preprocessedIR.Services {
+		if len(name) > 30 || !strings.HasPrefix(name, "acme-") || !strings.HasSuffix(name, "-prod") {
+			t.Fatalf("expected the name '%s' to be at most 30 characters and follow the naming convention", name)
+		}
+	}
+}
+
+func TestNamingConventionPreprocessorServiceReferences(t *testing.T) {
+	qaengine.ResetEngines()
+	defer qaengine.ResetEngines()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", []string{`move2kube.naming.prefix="acme"`}, nil, nil, false)
+	ir := irtypes.NewIR()
+	web := irtypes.NewServiceWithName("web")
+	web.Containers = []core.Container{{
+		Name: "web",
+		Env: []core.EnvVar{
+			{Name: "DB_HOST", Value: "db"},
+			{Name: "DATABASE_URL", Value: "postgres://user@db:5432/app"},
+			{Name: "MODE", Value: "dbadmin"},
+		},
+	}}
+	web.InitContainers = []core.Container{{
+		Name: "wait-for-db",
+		Env:  []core.EnvVar{{Name: "WAIT_HOST", Value: "db"}, {Name: "WAIT_PORT", Value: "5432"}},
+	}}
+	ir.Services["web"] = web
+	ir.Services["db"] = irtypes.NewServiceWithName("db")
+
+	preprocessedIR, err := namingConventionPreprocessor{}.preprocess(ir, collection.ClusterMetadata{})
+	if err != nil {
+		t.Fatalf("failed to preprocess the IR. Error: %q", err)
+	}
+	if _, ok := preprocessedIR.Services["acme-db"]; !ok {
+		t.Fatalf("expected the database service to be renamed. Actual: %+v", preprocessedIR.Services)
+	}
+	newWeb := preprocessedIR.Services["acme-web"]
+	wantEnv := []core.EnvVar{
+		{Name: "DB_HOST", Value: "acme-db"},
+		{Name: "DATABASE_URL", Value: "postgres://user@acme-db:5432/app"},
+		{Name: "MODE", Value: "dbadmin"},
+	}
+	if diff := cmp.Diff(wantEnv, newWeb.Containers[0].Env); diff != "" {
+		t.Fatalf("wrong env of the container. Difference:\n%s", diff)
+	}
+	if host := newWeb.InitContainers[0].Env[0].Value; host != "acme-db" {
+		t.Fatalf("expected the dependency wait to use the new name of the database service. Actual: %s", host)
+	}
+}
@@ -0,0 +1,184 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package irpreprocessor
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/konveyor/move2kube/types/qaengine/commonqa"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+const (
+	otelInjectAnnotationPrefix             = "instrumentation.opentelemetry.io/inject-"
+	otelContainerNamesAnnotation           = "instrumentation.opentelemetry.io/container-names"
+	otelSDKInjection                       = "sdk"
+	otelCollectorContainerName             = "otel-collector"
+	otelCollectorImage                     = "otel/opentelemetry-collector:0.88.0"
+	otelCollectorConfigVolumeName          = "otel-collector-config"
+	otelCollectorConfigMountPath           = "/etc/otelcol"
+	otelCollectorConfigFileName            = "config.yaml"
+	otelCollectorConfigMapNameSuffix       = "-otel-collector-config"
+	otelCollectorOTLPGRPCPort        int32 = 4317
+	otelCollectorOTLPHTTPPort        int32 = 4318
+	otelExporterOTLPEndpointEnv            = "OTEL_EXPORTER_OTLP_ENDPOINT"
+	otelExporterOTLPProtocolEnv            = "OTEL_EXPORTER_OTLP_PROTOCOL"
+	otelExporterOTLPGRPCProtocol           = "grpc"
+	otelServiceNameEnv                     = "OTEL_SERVICE_NAME"
+)
+
+// otelCollectorConfigTemplate receives the telemetry of the app over OTLP and exports it to the collector endpoint
+const otelCollectorConfigTemplate = `receivers:
+  otlp:
+    protocols:
+      grpc:
+        endpoint: 0.0.0.0:%d
+      http:
+        endpoint: 0.0.0.0:%d
+processors:
+  batch: {}
+exporters:
+  otlp:
+    endpoint: %q
+    tls:
+      insecure: true
+service:
+  pipelines:
+    traces:
+      receivers: [otlp]
+      processors: [batch]
+      exporters: [otlp]
+    metrics:
+      receivers: [otlp]
+      processors: [batch]
+      exporters: [otlp]
+    logs:
+      receivers: [otlp]
+      processors: [batch]
+      exporters: [otlp]
+`
+
+// openTelemetryPreprocessor instruments the services with OpenTelemetry, either through the auto-instrumentation
+// of the OpenTelemetry Operator or through a collector sidecar
+type openTelemetryPreprocessor struct {
+}
+
+func (op openTelemetryPreprocessor) preprocess(ir irtypes.IR, targetCluster collection.ClusterMetadata) (irtypes.IR, error) {
+	if len(ir.Services) == 0 {
+		return ir, nil
+	}
+	instrumentation := commonqa.Instrumentation()
+	if instrumentation == "" {
+		return ir, nil
+	}
+	collectorEndpoint := ""
+	if instrumentation == commonqa.SidecarInstrumentation {
+		collectorEndpoint = commonqa.CollectorEndpoint()
+	}
+	for _, serviceName := range ir.GetSortedServiceNames() {
+		service := ir.Services[serviceName]
+		if len(service.Containers) == 0 {
+			continue
+		}
+		switch instrumentation {
+		case commonqa.OperatorInstrumentation:
+			service = op.addInstrumentationAnnotations(service)
+		case commonqa.SidecarInstrumentation:
+			var storage irtypes.Storage
+			service, storage = op.addCollectorSidecar(service, collectorEndpoint)
+			if storage.Name != "" {
+				ir.AddStorage(storage)
+			}
+		}
+		ir.Services[serviceName] = service
+	}
+	return ir, nil
+}
+
+// addInstrumentationAnnotations asks the OpenTelemetry Operator to inject the instrumentation for the language of the service.
+// The services in an unknown language only get the SDK configuration injected.
+func (op openTelemetryPreprocessor) addInstrumentationAnnotations(service irtypes.Service) irtypes.Service {
+	injection := service.Language
+	if injection == "" {
+		injection = otelSDKInjection
+	}
+	service.Annotations = common.MergeStringMaps(map[string]string{}, service.Annotations)
+	service.Annotations[otelInjectAnnotationPrefix+injection] = common.AnnotationLabelValue
+	if len(service.Containers) > 1 {
+		// only the first container runs the app, the rest are sidecars
+		service.Annotations[otelContainerNamesAnnotation] = service.Containers[0].Name
+	}
+	return service
+}
+
+// addCollectorSidecar adds a collector container that the app containers send their telemetry to,
+// and returns the ConfigMap storage with the configuration of the collector
+func (op openTelemetryPreprocessor) addCollectorSidecar(service irtypes.Service, collectorEndpoint string) (irtypes.Service, irtypes.Storage) {
+	for _, container := range service.Containers {
+		if container.Name == otelCollectorContainerName {
+			return service, irtypes.Storage{}
+		}
+	}
+	for i, container := range service.Containers {
+		envs := map[string]string{
+			otelExporterOTLPEndpointEnv: fmt.Sprintf("http://localhost:%d", otelCollectorOTLPGRPCPort),
+			// some SDKs default to http/protobuf, which the collector receives on a different port
+			otelExporterOTLPProtocolEnv: otelExporterOTLPGRPCProtocol,
+			otelServiceNameEnv:          service.Name,
+		}
+		for _, env := range container.Env {
+			delete(envs, env.Name)
+		}
+		envNames := []string{}
+		for envName := range envs {
+			envNames = append(envNames, envName)
+		}
+		sort.Strings(envNames)
+		for _, envName := range envNames {
+			container.Env = append(container.Env, core.EnvVar{Name: envName, Value: envs[envName]})
+		}
+		service.Containers[i] = container
+	}
+	configMapName := common.MakeStringK8sServiceNameCompliant(service.Name + otelCollectorConfigMapNameSuffix)
+	service.Containers = append(service.Containers, core.Container{
+		Name:  otelCollectorContainerName,
+		Image: otelCollectorImage,
+		Args:  []string{"--config=" + otelCollectorConfigMountPath + "/" + otelCollectorConfigFileName},
+		Ports: []core.ContainerPort{
+			{Name: "otlp-grpc", ContainerPort: otelCollectorOTLPGRPCPort, Protocol: core.ProtocolTCP},
+			{Name: "otlp-http", ContainerPort: otelCollectorOTLPHTTPPort, Protocol: core.ProtocolTCP},
+		},
+		VolumeMounts: []core.VolumeMount{{Name: otelCollectorConfigVolumeName, MountPath: otelCollectorConfigMountPath, ReadOnly: true}},
+	})
+	service.Volumes = append(service.Volumes, core.Volume{
+		Name: otelCollectorConfigVolumeName,
+		VolumeSource: core.VolumeSource{
+			ConfigMap: &core.ConfigMapVolumeSource{LocalObjectReference: core.LocalObjectReference{Name: configMapName}},
+		},
+	})
+	storage := irtypes.Storage{
+		Name:        configMapName,
+		StorageType: irtypes.ConfigMapKind,
+		Content: map[string][]byte{
+			otelCollectorConfigFileName: []byte(fmt.Sprintf(otelCollectorConfigTemplate, otelCollectorOTLPGRPCPort, otelCollectorOTLPHTTPPort, collectorEndpoint)),
+		},
+	}
+	return service, storage
+}
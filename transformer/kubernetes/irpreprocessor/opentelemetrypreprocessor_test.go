@@ -0,0 +1,98 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package irpreprocessor
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func getOpenTelemetryTestIR() irtypes.IR {
+	ir := irtypes.NewIR()
+	web := irtypes.NewServiceWithName("web")
+	web.Containers = []core.Container{{Name: "web"}}
+	web.Language = irtypes.JavaLanguage
+	ir.Services["web"] = web
+	worker := irtypes.NewServiceWithName("worker")
+	worker.Containers = []core.Container{{Name: "worker"}, {Name: "proxy"}}
+	ir.Services["worker"] = worker
+	return ir
+}
+
+func TestOpenTelemetryPreprocessorOperator(t *testing.T) {
+	qaengine.ResetEngines()
+	defer qaengine.ResetEngines()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", []string{`move2kube.monitoring.instrumentation="operator"`}, nil, nil, false)
+
+	preprocessedIR, err := openTelemetryPreprocessor{}.preprocess(getOpenTelemetryTestIR(), collection.ClusterMetadata{})
+	if err != nil {
+		t.Fatalf("failed to preprocess the IR. Error: %q", err)
+	}
+	if web := preprocessedIR.Services["web"]; web.Annotations["instrumentation.opentelemetry.io/inject-java"] != "true" {
+		t.Fatalf("expected the java instrumentation to be injected. Actual: %+v", web.Annotations)
+	}
+	worker := preprocessedIR.Services["worker"]
+	if worker.Annotations["instrumentation.opentelemetry.io/inject-sdk"] != "true" {
+		t.Fatalf("expected the SDK to be injected into the service in an unknown language. Actual: %+v", worker.Annotations)
+	}
+	if worker.Annotations["instrumentation.opentelemetry.io/container-names"] != "worker" {
+		t.Fatalf("expected only the app container to be instrumented. Actual: %+v", worker.Annotations)
+	}
+}
+
+func TestOpenTelemetryPreprocessorSidecar(t *testing.T) {
+	qaengine.ResetEngines()
+	defer qaengine.ResetEngines()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", []string{`move2kube.monitoring.instrumentation="sidecar"`, `move2kube.monitoring.collectorendpoint="tempo.tracing:4317"`}, nil, nil, false)
+
+	preprocessedIR, err := openTelemetryPreprocessor{}.preprocess(getOpenTelemetryTestIR(), collection.ClusterMetadata{})
+	if err != nil {
+		t.Fatalf("failed to preprocess the IR. Error: %q", err)
+	}
+	web := preprocessedIR.Services["web"]
+	if len(web.Containers) != 2 || web.Containers[1].Name != "otel-collector" {
+		t.Fatalf("expected a collector sidecar. Actual: %+v", web.Containers)
+	}
+	envs := map[string]string{}
+	for _, env := range web.Containers[0].Env {
+		envs[env.Name] = env.Value
+	}
+	if envs["OTEL_EXPORTER_OTLP_ENDPOINT"] != "http://localhost:4317" || envs["OTEL_EXPORTER_OTLP_PROTOCOL"] != "grpc" || envs["OTEL_SERVICE_NAME"] != "web" {
+		t.Fatalf("expected the app to export to the sidecar. Actual: %+v", envs)
+	}
+	if len(web.Volumes) != 1 || web.Volumes[0].ConfigMap == nil || web.Volumes[0].ConfigMap.Name != "web-otel-collector-config" {
+		t.Fatalf("expected the collector config to be mounted. Actual: %+v", web.Volumes)
+	}
+	found := false
+	for _, storage := range preprocessedIR.Storages {
+		if storage.Name == "web-otel-collector-config" {
+			found = true
+			if storage.StorageType != irtypes.ConfigMapKind || !strings.Contains(string(storage.Content["config.yaml"]), `endpoint: "tempo.tracing:4317"`) {
+				t.Fatalf("expected the collector config to export to the endpoint. Actual: %+v", storage)
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("expected the ConfigMap with the collector config. Actual: %+v", preprocessedIR.Storages)
+	}
+}
@@ -0,0 +1,73 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"github.com/konveyor/move2kube/types"
+	"github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/konveyor/move2kube/types/qaengine/commonqa"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+const (
+	// podAntiAffinityWeight is the weight of the preferred pod anti-affinity
+	podAntiAffinityWeight = 100
+)
+
+// topologyKeys are the node labels for each of the topologies the replicas can be spread across
+var topologyKeys = map[string]string{
+	commonqa.HostnameTopology: "kubernetes.io/hostname",
+	commonqa.ZoneTopology:     "topology.kubernetes.io/zone",
+}
+
+// podAntiAffinityPreprocessor adds a preferred pod anti-affinity to the services with multiple replicas
+type podAntiAffinityPreprocessor struct {
+}
+
+func (pp podAntiAffinityPreprocessor) preprocess(ir irtypes.IR, targetCluster collection.ClusterMetadata) (irtypes.IR, error) {
+	for _, serviceName := range ir.GetSortedServiceNames() {
+		service := ir.Services[serviceName]
+		if service.Replicas <= 1 || service.Daemon || (service.Affinity != nil && service.Affinity.PodAntiAffinity != nil) {
+			continue
+		}
+		topologyKey, ok := topologyKeys[commonqa.PodAntiAffinity(serviceName)]
+		if !ok {
+			continue
+		}
+		if service.Affinity == nil {
+			service.Affinity = &core.Affinity{}
+		}
+		service.Affinity.PodAntiAffinity = &core.PodAntiAffinity{
+			PreferredDuringSchedulingIgnoredDuringExecution: []core.WeightedPodAffinityTerm{{
+				Weight: podAntiAffinityWeight,
+				PodAffinityTerm: core.PodAffinityTerm{
+					LabelSelector: getServiceLabelSelector(service.Name),
+					TopologyKey:   topologyKey,
+				},
+			}},
+		}
+		ir.Services[serviceName] = service
+	}
+	return ir, nil
+}
+
+// getServiceLabelSelector returns the label selector matching the pods of the service
+func getServiceLabelSelector(serviceName string) *metav1.LabelSelector {
+	return &metav1.LabelSelector{MatchLabels: map[string]string{types.GroupName + "/service": serviceName}}
+}
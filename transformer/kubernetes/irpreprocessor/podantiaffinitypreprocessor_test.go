@@ -0,0 +1,61 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"testing"
+
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+)
+
+func TestPodAntiAffinityPreprocessor(t *testing.T) {
+	qaengine.ResetEngines()
+	defer qaengine.ResetEngines()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", []string{`move2kube.services."web".podantiaffinity="zone"`, `move2kube.services."single".podantiaffinity="hostname"`}, nil, nil, false)
+	ir := irtypes.NewIR()
+	web := irtypes.NewServiceWithName("web")
+	web.Replicas = 3
+	ir.Services["web"] = web
+	single := irtypes.NewServiceWithName("single")
+	single.Replicas = 1
+	ir.Services["single"] = single
+	db := irtypes.NewServiceWithName("db")
+	db.Replicas = 2
+	ir.Services["db"] = db
+
+	preprocessedIR, err := podAntiAffinityPreprocessor{}.preprocess(ir, collection.ClusterMetadata{})
+	if err != nil {
+		t.Fatalf("failed to preprocess the IR. Error: %q", err)
+	}
+	affinity := preprocessedIR.Services["web"].Affinity
+	if affinity == nil || affinity.PodAntiAffinity == nil || len(affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution) != 1 {
+		t.Fatalf("expected a preferred pod anti-affinity. Actual: %+v", affinity)
+	}
+	term := affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution[0].PodAffinityTerm
+	if term.TopologyKey != "topology.kubernetes.io/zone" || term.LabelSelector.MatchLabels["move2kube.konveyor.io/service"] != "web" {
+		t.Fatalf("wrong pod affinity term. Actual: %+v", term)
+	}
+	if affinity := preprocessedIR.Services["single"].Affinity; affinity != nil {
+		t.Fatalf("expected no pod anti-affinity for a single replica. Actual: %+v", affinity)
+	}
+	if affinity := preprocessedIR.Services["db"].Affinity; affinity != nil {
+		t.Fatalf("expected no pod anti-affinity by default. Actual: %+v", affinity)
+	}
+}
@@ -0,0 +1,132 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package irpreprocessor
+
+import (
+	"fmt"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/common/report"
+	"github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/konveyor/move2kube/types/qaengine/commonqa"
+	"github.com/sirupsen/logrus"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+// podSecurityPreprocessor hardens the pods so that they are admitted by the restricted Pod Security Standard,
+// and flags the services that cannot comply with it
+type podSecurityPreprocessor struct {
+}
+
+func (pp podSecurityPreprocessor) preprocess(ir irtypes.IR, targetCluster collection.ClusterMetadata) (irtypes.IR, error) {
+	if len(ir.Services) == 0 || !commonqa.PodSecurityRestricted() {
+		return ir, nil
+	}
+	for _, serviceName := range ir.GetSortedServiceNames() {
+		service := ir.Services[serviceName]
+		if len(service.Containers) == 0 {
+			continue
+		}
+		for _, reason := range getPodSecurityViolations(service) {
+			logrus.Warnf("The service '%s' cannot comply with the restricted Pod Security Standard, since %s", serviceName, reason)
+		}
+		if service.SecurityContext == nil {
+			service.SecurityContext = &core.PodSecurityContext{}
+		}
+		if isRootUser(service.SecurityContext.RunAsUser) {
+			logrus.Warnf("Removing the root user id of the service '%s' to comply with the restricted Pod Security Standard", serviceName)
+			service.SecurityContext.RunAsUser = nil
+		}
+		runAsNonRoot := true
+		service.SecurityContext.RunAsNonRoot = &runAsNonRoot
+		if service.SecurityContext.SeccompProfile == nil || service.SecurityContext.SeccompProfile.Type == core.SeccompProfileTypeUnconfined {
+			service.SecurityContext.SeccompProfile = &core.SeccompProfile{Type: core.SeccompProfileTypeRuntimeDefault}
+		}
+		for i := range service.InitContainers {
+			service.InitContainers[i].SecurityContext = getPodSecurityRestrictedSecurityContext(serviceName, service.InitContainers[i])
+		}
+		for i := range service.Containers {
+			service.Containers[i].SecurityContext = getPodSecurityRestrictedSecurityContext(serviceName, service.Containers[i])
+		}
+		ir.Services[serviceName] = service
+	}
+	return ir, nil
+}
+
+// getPodSecurityRestrictedSecurityContext returns the security context of the container hardened for the restricted Pod Security Standard.
+// The privileged containers are left unchanged, since they would not work otherwise
+// and the API server rejects the privileged containers that do not allow privilege escalation.
+func getPodSecurityRestrictedSecurityContext(serviceName string, container core.Container) *core.SecurityContext {
+	securityContext := &core.SecurityContext{}
+	if container.SecurityContext != nil {
+		securityContext = container.SecurityContext.DeepCopy()
+	}
+	if securityContext.Privileged != nil && *securityContext.Privileged {
+		logrus.Warnf("The container '%s' in the service '%s' is privileged. Not hardening it for the restricted Pod Security Standard.", container.Name, serviceName)
+		report.AddFollowUp(serviceName, fmt.Sprintf("The container '%s' is privileged, so it is rejected by the restricted Pod Security Standard. Remove the privileged mode or deploy it to a namespace with the privileged level.", container.Name))
+		return container.SecurityContext
+	}
+	if isRootUser(securityContext.RunAsUser) {
+		logrus.Warnf("Removing the root user id of the container '%s' in the service '%s' to comply with the restricted Pod Security Standard", container.Name, serviceName)
+		securityContext.RunAsUser = nil
+	}
+	runAsNonRoot := true
+	securityContext.RunAsNonRoot = &runAsNonRoot
+	allowPrivilegeEscalation := false
+	securityContext.AllowPrivilegeEscalation = &allowPrivilegeEscalation
+	capabilities := &core.Capabilities{Drop: []core.Capability{"ALL"}}
+	if securityContext.Capabilities != nil {
+		for _, capability := range securityContext.Capabilities.Add {
+			if !common.IsPresent(restrictedCapabilities, capability) {
+				logrus.Warnf("The capability '%s' of the container '%s' in the service '%s' is not allowed by the restricted Pod Security Standard. Dropping it.", capability, container.Name, serviceName)
+				continue
+			}
+			capabilities.Add = append(capabilities.Add, capability)
+		}
+	}
+	securityContext.Capabilities = capabilities
+	if securityContext.SeccompProfile != nil && securityContext.SeccompProfile.Type == core.SeccompProfileTypeUnconfined {
+		// the container inherits the RuntimeDefault profile of the pod
+		securityContext.SeccompProfile = nil
+	}
+	return securityContext
+}
+
+// getPodSecurityViolations returns the reasons the service cannot comply with the restricted Pod Security Standard
+func getPodSecurityViolations(service irtypes.Service) []string {
+	reasons := []string{}
+	if service.SecurityContext != nil && (service.SecurityContext.HostNetwork || service.SecurityContext.HostPID || service.SecurityContext.HostIPC) {
+		reasons = append(reasons, "it uses the host namespaces")
+	}
+	for _, volume := range service.Volumes {
+		if volume.HostPath != nil {
+			reasons = append(reasons, "the volume '"+volume.Name+"' is a host path")
+		}
+	}
+	for _, container := range append(append([]core.Container{}, service.InitContainers...), service.Containers...) {
+		if container.SecurityContext != nil && container.SecurityContext.Privileged != nil && *container.SecurityContext.Privileged {
+			reasons = append(reasons, "the container '"+container.Name+"' is privileged")
+		}
+		for _, port := range container.Ports {
+			if port.HostPort != 0 {
+				reasons = append(reasons, "the container '"+container.Name+"' uses host ports")
+				break
+			}
+		}
+	}
+	return reasons
+}
@@ -0,0 +1,83 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package irpreprocessor
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestPodSecurityPreprocessor(t *testing.T) {
+	qaengine.ResetEngines()
+	defer qaengine.ResetEngines()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", []string{`move2kube.podsecurity.restricted=true`}, nil, nil, false)
+	rootUser := int64(0)
+	ir := irtypes.NewIR()
+	web := irtypes.NewServiceWithName("web")
+	web.Containers = []core.Container{{
+		Name: "web",
+		SecurityContext: &core.SecurityContext{
+			RunAsUser:    &rootUser,
+			Capabilities: &core.Capabilities{Add: []core.Capability{"NET_BIND_SERVICE", "SYS_ADMIN"}},
+		},
+	}}
+	ir.Services["web"] = web
+	privileged := true
+	agent := irtypes.NewServiceWithName("agent")
+	agent.Containers = []core.Container{{Name: "agent", SecurityContext: &core.SecurityContext{Privileged: &privileged}}}
+	ir.Services["agent"] = agent
+
+	preprocessedIR, err := podSecurityPreprocessor{}.preprocess(ir, collection.ClusterMetadata{})
+	if err != nil {
+		t.Fatalf("failed to preprocess the IR. Error: %q", err)
+	}
+	runAsNonRoot := true
+	allowPrivilegeEscalation := false
+	want := &core.SecurityContext{
+		RunAsNonRoot:             &runAsNonRoot,
+		AllowPrivilegeEscalation: &allowPrivilegeEscalation,
+		Capabilities:             &core.Capabilities{Drop: []core.Capability{"ALL"}, Add: []core.Capability{"NET_BIND_SERVICE"}},
+	}
+	web = preprocessedIR.Services["web"]
+	if diff := cmp.Diff(want, web.Containers[0].SecurityContext); diff != "" {
+		t.Fatalf("wrong security context of the container. Difference:\n%s", diff)
+	}
+	wantPod := &core.PodSecurityContext{RunAsNonRoot: &runAsNonRoot, SeccompProfile: &core.SeccompProfile{Type: core.SeccompProfileTypeRuntimeDefault}}
+	if diff := cmp.Diff(wantPod, web.SecurityContext); diff != "" {
+		t.Fatalf("wrong security context of the pod. Difference:\n%s", diff)
+	}
+	wantPrivileged := &core.SecurityContext{Privileged: &privileged}
+	if diff := cmp.Diff(wantPrivileged, preprocessedIR.Services["agent"].Containers[0].SecurityContext); diff != "" {
+		t.Fatalf("expected the security context of the privileged container to be unchanged. Difference:\n%s", diff)
+	}
+}
+
+func TestGetPodSecurityViolations(t *testing.T) {
+	privileged := true
+	service := irtypes.NewServiceWithName("agent")
+	service.Volumes = []core.Volume{{Name: "logs", VolumeSource: core.VolumeSource{HostPath: &core.HostPathVolumeSource{Path: "/var/log"}}}}
+	service.Containers = []core.Container{{Name: "agent", SecurityContext: &core.SecurityContext{Privileged: &privileged}}}
+	want := []string{"the volume 'logs' is a host path", "the container 'agent' is privileged"}
+	if diff := cmp.Diff(want, getPodSecurityViolations(service)); diff != "" {
+		t.Fatalf("wrong violations. Difference:\n%s", diff)
+	}
+}
@@ -0,0 +1,45 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/konveyor/move2kube/types/qaengine/commonqa"
+)
+
+// priorityClassPreprocessor assigns the selected priority classes to the services
+type priorityClassPreprocessor struct {
+}
+
+func (pp priorityClassPreprocessor) preprocess(ir irtypes.IR, targetCluster collection.ClusterMetadata) (irtypes.IR, error) {
+	priorityClasses := commonqa.PriorityClasses(targetCluster.Spec.PriorityClasses)
+	if len(priorityClasses) == 0 {
+		return ir, nil
+	}
+	for _, serviceName := range ir.GetSortedServiceNames() {
+		service := ir.Services[serviceName]
+		if service.PriorityClassName != "" {
+			continue
+		}
+		if service.PriorityClassName = commonqa.PriorityClassName(serviceName, priorityClasses); service.PriorityClassName == "" {
+			continue
+		}
+		ir.Services[serviceName] = service
+	}
+	return ir, nil
+}
@@ -0,0 +1,47 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"testing"
+
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+)
+
+func TestPriorityClassPreprocessor(t *testing.T) {
+	qaengine.ResetEngines()
+	defer qaengine.ResetEngines()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", []string{`move2kube.services."web".priorityclassname="high"`, `move2kube.services."db".priorityclassname="unknown"`}, nil, nil, false)
+	ir := irtypes.NewIR()
+	ir.Services["web"] = irtypes.NewServiceWithName("web")
+	ir.Services["db"] = irtypes.NewServiceWithName("db")
+	targetCluster := collection.ClusterMetadata{Spec: collection.ClusterMetadataSpec{PriorityClasses: []string{"high", "low"}}}
+
+	preprocessedIR, err := priorityClassPreprocessor{}.preprocess(ir, targetCluster)
+	if err != nil {
+		t.Fatalf("failed to preprocess the IR. Error: %q", err)
+	}
+	if priorityClassName := preprocessedIR.Services["web"].PriorityClassName; priorityClassName != "high" {
+		t.Fatalf("expected the selected priority class. Actual: %s", priorityClassName)
+	}
+	if priorityClassName := preprocessedIR.Services["db"].PriorityClassName; priorityClassName != "" {
+		t.Fatalf("expected no priority class when the selected one is not in the cluster. Actual: %s", priorityClassName)
+	}
+}
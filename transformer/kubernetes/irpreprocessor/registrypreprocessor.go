@@ -65,8 +65,8 @@ func (p registryPreProcessor) preprocess(ir irtypes.IR, targetCluster collection
 	// find all the registries that we use for our images
 
 	usedRegistries := []string{}
-	for _, service := range ir.Services {
-		for _, container := range service.Containers {
+	for _, serviceName := range ir.GetSortedServiceNames() {
+		for _, container := range ir.Services[serviceName].Containers {
 			if !common.IsPresent(newImageNames, container.Image) {
 
 				// if it's a pre-existing image then find the registry where the image exists
@@ -122,6 +122,7 @@ func (p registryPreProcessor) preprocess(ir irtypes.IR, targetCluster collection
 
 	imagePullSecrets := map[string]string{} // registry url -> pull secret name
 	registryNamespace := commonqa.ImageRegistryNamespace()
+	imageTag := commonqa.ImageTag()
 	for _, registry := range usedRegistries {
 		if _, ok := imagePullSecrets[registry]; !ok {
 			imagePullSecrets[registry] = common.NormalizeForMetadataName(strings.ReplaceAll(registry, ".", "-") + imagePullSecretSuffix)
@@ -190,6 +191,9 @@ func (p registryPreProcessor) preprocess(ir irtypes.IR, targetCluster collection
 		for i, container := range service.Containers {
 			if common.IsPresent(newImageNames, container.Image) {
 				image, tag := common.GetImageNameAndTag(container.Image)
+				if imageTag != "" {
+					tag = imageTag
+				}
 				if registryToPushImagesTo != "" && registryNamespace != "" {
 					container.Image = registryToPushImagesTo + "/" + registryNamespace + "/" + image + ":" + tag
 				} else if registryNamespace != "" {
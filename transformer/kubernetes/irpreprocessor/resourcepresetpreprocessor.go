@@ -0,0 +1,75 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/konveyor/move2kube/types/qaengine/commonqa"
+	"k8s.io/apimachinery/pkg/api/resource"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+// resourcePresets are the requests and limits for each of the resource presets
+var resourcePresets = map[string]core.ResourceRequirements{
+	commonqa.SmallResourcePreset:  getPresetResources("100m", "128Mi", "250m", "256Mi"),
+	commonqa.MediumResourcePreset: getPresetResources("250m", "256Mi", "500m", "512Mi"),
+	commonqa.LargeResourcePreset:  getPresetResources("500m", "512Mi", "1", "1Gi"),
+}
+
+// resourcePresetPreprocessor replaces the requests and limits of the containers with the ones from the selected preset
+type resourcePresetPreprocessor struct {
+}
+
+func (rp resourcePresetPreprocessor) preprocess(ir irtypes.IR, targetCluster collection.ClusterMetadata) (irtypes.IR, error) {
+	for _, serviceName := range ir.GetSortedServiceNames() {
+		service := ir.Services[serviceName]
+		if len(service.Containers) == 0 {
+			continue
+		}
+		presetResources, ok := resourcePresets[commonqa.ResourcePreset(serviceName)]
+		if !ok {
+			continue
+		}
+		for i, container := range service.Containers {
+			container.Resources.Requests = getMergedResources(container.Resources.Requests, presetResources.Requests)
+			container.Resources.Limits = getMergedResources(container.Resources.Limits, presetResources.Limits)
+			service.Containers[i] = container
+		}
+		ir.Services[serviceName] = service
+	}
+	return ir, nil
+}
+
+// getMergedResources returns a copy of the resources with the cpu and memory replaced by the ones from the preset
+func getMergedResources(resources, presetResources core.ResourceList) core.ResourceList {
+	mergedResources := core.ResourceList{}
+	for resourceName, quantity := range resources {
+		mergedResources[resourceName] = quantity
+	}
+	for resourceName, quantity := range presetResources {
+		mergedResources[resourceName] = quantity
+	}
+	return mergedResources
+}
+
+func getPresetResources(cpuRequest, memoryRequest, cpuLimit, memoryLimit string) core.ResourceRequirements {
+	return core.ResourceRequirements{
+		Requests: core.ResourceList{core.ResourceCPU: resource.MustParse(cpuRequest), core.ResourceMemory: resource.MustParse(memoryRequest)},
+		Limits:   core.ResourceList{core.ResourceCPU: resource.MustParse(cpuLimit), core.ResourceMemory: resource.MustParse(memoryLimit)},
+	}
+}
@@ -0,0 +1,61 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"testing"
+
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"k8s.io/apimachinery/pkg/api/resource"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestResourcePresetPreprocessor(t *testing.T) {
+	qaengine.ResetEngines()
+	defer qaengine.ResetEngines()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", []string{`move2kube.services."web".resourcepreset="large"`}, nil, nil, false)
+	ir := irtypes.NewIR()
+	web := irtypes.NewServiceWithName("web")
+	web.Containers = []core.Container{{Name: "web", Resources: core.ResourceRequirements{
+		Requests: core.ResourceList{core.ResourceCPU: resource.MustParse("2"), core.ResourceEphemeralStorage: resource.MustParse("1Gi")},
+	}}}
+	ir.Services["web"] = web
+	db := irtypes.NewServiceWithName("db")
+	db.Containers = []core.Container{{Name: "db"}}
+	ir.Services["db"] = db
+
+	preprocessedIR, err := resourcePresetPreprocessor{}.preprocess(ir, collection.ClusterMetadata{})
+	if err != nil {
+		t.Fatalf("failed to preprocess the IR. Error: %q", err)
+	}
+	webResources := preprocessedIR.Services["web"].Containers[0].Resources
+	if cpu := webResources.Requests[core.ResourceCPU]; cpu.String() != "500m" {
+		t.Fatalf("expected the cpu request of the preset. Actual: %s", cpu.String())
+	}
+	if memory := webResources.Limits[core.ResourceMemory]; memory.String() != "1Gi" {
+		t.Fatalf("expected the memory limit of the preset. Actual: %s", memory.String())
+	}
+	if _, ok := webResources.Requests[core.ResourceEphemeralStorage]; !ok {
+		t.Fatalf("expected the resources not in the preset to be kept. Actual: %+v", webResources)
+	}
+	if dbResources := preprocessedIR.Services["db"].Containers[0].Resources; len(dbResources.Requests) != 0 || len(dbResources.Limits) != 0 {
+		t.Fatalf("expected the service without a preset to be unchanged. Actual: %+v", dbResources)
+	}
+}
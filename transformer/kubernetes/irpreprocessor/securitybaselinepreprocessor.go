@@ -0,0 +1,77 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package irpreprocessor
+
+import (
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/konveyor/move2kube/types/qaengine/commonqa"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+// securityBaselinePreprocessor applies the security baseline to the containers, setting the seccomp profile
+// and dropping the capabilities that are not added in the source
+type securityBaselinePreprocessor struct {
+}
+
+func (sp securityBaselinePreprocessor) preprocess(ir irtypes.IR, targetCluster collection.ClusterMetadata) (irtypes.IR, error) {
+	if len(ir.Services) == 0 || targetCluster.Spec.UsesSecurityContextConstraints() {
+		// the security context constraints already restrict the containers
+		return ir, nil
+	}
+	seccompProfile := commonqa.SecurityBaselineSeccompProfile()
+	dropAllCapabilities := commonqa.SecurityBaselineDropAllCapabilities()
+	if seccompProfile == "" && !dropAllCapabilities {
+		return ir, nil
+	}
+	for serviceName, service := range ir.Services {
+		podHasSeccompProfile := service.SecurityContext != nil && service.SecurityContext.SeccompProfile != nil
+		for i := range service.InitContainers {
+			service.InitContainers[i].SecurityContext = getBaselineSecurityContext(service.InitContainers[i], seccompProfile, podHasSeccompProfile, dropAllCapabilities)
+		}
+		for i := range service.Containers {
+			service.Containers[i].SecurityContext = getBaselineSecurityContext(service.Containers[i], seccompProfile, podHasSeccompProfile, dropAllCapabilities)
+		}
+		ir.Services[serviceName] = service
+	}
+	return ir, nil
+}
+
+// getBaselineSecurityContext returns the security context of the container with the baseline applied.
+// The privileged containers are left as they are, since they have all the capabilities and no seccomp profile anyway.
+func getBaselineSecurityContext(container core.Container, seccompProfile string, podHasSeccompProfile, dropAllCapabilities bool) *core.SecurityContext {
+	if container.SecurityContext != nil && container.SecurityContext.Privileged != nil && *container.SecurityContext.Privileged {
+		return container.SecurityContext
+	}
+	securityContext := &core.SecurityContext{}
+	if container.SecurityContext != nil {
+		securityContext = container.SecurityContext.DeepCopy()
+	}
+	if seccompProfile != "" && securityContext.SeccompProfile == nil && !podHasSeccompProfile {
+		securityContext.SeccompProfile = &core.SeccompProfile{Type: core.SeccompProfileType(seccompProfile)}
+	}
+	if dropAllCapabilities {
+		if securityContext.Capabilities == nil {
+			securityContext.Capabilities = &core.Capabilities{}
+		}
+		if !common.IsPresent(securityContext.Capabilities.Drop, "ALL") {
+			// the capabilities added in the source are kept, since they are applied after the dropped ones
+			securityContext.Capabilities.Drop = []core.Capability{"ALL"}
+		}
+	}
+	return securityContext
+}
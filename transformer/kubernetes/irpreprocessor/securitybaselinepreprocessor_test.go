@@ -0,0 +1,56 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package irpreprocessor
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestSecurityBaselinePreprocessor(t *testing.T) {
+	qaengine.ResetEngines()
+	defer qaengine.ResetEngines()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	privileged := true
+	ir := irtypes.NewIR()
+	web := irtypes.NewServiceWithName("web")
+	web.Containers = []core.Container{
+		{Name: "web", SecurityContext: &core.SecurityContext{Capabilities: &core.Capabilities{Add: []core.Capability{"NET_ADMIN"}, Drop: []core.Capability{"MKNOD"}}}},
+		{Name: "agent", SecurityContext: &core.SecurityContext{Privileged: &privileged}},
+	}
+	ir.Services["web"] = web
+
+	preprocessedIR, err := securityBaselinePreprocessor{}.preprocess(ir, collection.ClusterMetadata{})
+	if err != nil {
+		t.Fatalf("failed to preprocess the IR. Error: %q", err)
+	}
+	want := &core.SecurityContext{
+		Capabilities:   &core.Capabilities{Add: []core.Capability{"NET_ADMIN"}, Drop: []core.Capability{"ALL"}},
+		SeccompProfile: &core.SeccompProfile{Type: core.SeccompProfileTypeRuntimeDefault},
+	}
+	web = preprocessedIR.Services["web"]
+	if diff := cmp.Diff(want, web.Containers[0].SecurityContext); diff != "" {
+		t.Fatalf("wrong security context of the container. Difference:\n%s", diff)
+	}
+	if diff := cmp.Diff(&core.SecurityContext{Privileged: &privileged}, web.Containers[1].SecurityContext); diff != "" {
+		t.Fatalf("expected the privileged container to be unchanged. Difference:\n%s", diff)
+	}
+}
@@ -0,0 +1,149 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/sirupsen/logrus"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+const (
+	// defaultSecurityContextConstraint is the security context constraint used by the workloads of authenticated users since OpenShift 4.11
+	defaultSecurityContextConstraint = "restricted-v2"
+)
+
+var (
+	// anyUserSecurityContextConstraints allow the pods to run as any user, including root
+	anyUserSecurityContextConstraints = []string{"anyuid", "hostmount-anyuid", "privileged"}
+	// nonRootUserSecurityContextConstraints allow the pods to run as any user other than root
+	nonRootUserSecurityContextConstraints = []string{"nonroot", "nonroot-v2"}
+	// seccompSecurityContextConstraints allow the pods to use the seccomp profile of the container runtime
+	seccompSecurityContextConstraints = []string{"restricted-v2", "nonroot-v2"}
+	// restrictedCapabilities are the only capabilities the restricted security context constraints allow to be added
+	restrictedCapabilities = []core.Capability{"NET_BIND_SERVICE"}
+)
+
+// sccPermissions stores what the security context constraints available to the workloads allow
+type sccPermissions struct {
+	anyUser     bool
+	nonRootUser bool
+	privileged  bool
+	seccomp     bool
+}
+
+// securityContextPreprocessor adjusts the security contexts so that the pods are admitted by the OpenShift security context constraints.
+// Only the clusters collected using move2kube collect are adjusted for.
+type securityContextPreprocessor struct {
+}
+
+func (p *securityContextPreprocessor) preprocess(ir irtypes.IR, targetCluster collection.ClusterMetadata) (irtypes.IR, error) {
+	// the built-in OpenShift targets list the security context constraints api without knowing which constraints the workloads can use
+	if !targetCluster.Spec.IsCollected() || !targetCluster.Spec.UsesSecurityContextConstraints() {
+		return ir, nil
+	}
+	sccs := targetCluster.Spec.SecurityContextConstraints
+	if len(sccs) == 0 {
+		sccs = []string{defaultSecurityContextConstraint}
+	}
+	permissions := sccPermissions{
+		anyUser:    hasOverlap(sccs, anyUserSecurityContextConstraints),
+		privileged: common.IsPresent(sccs, "privileged"),
+		seccomp:    hasOverlap(sccs, seccompSecurityContextConstraints),
+	}
+	permissions.nonRootUser = permissions.anyUser || hasOverlap(sccs, nonRootUserSecurityContextConstraints)
+	if permissions.anyUser && permissions.privileged {
+		return ir, nil
+	}
+	for serviceName, service := range ir.Services {
+		if service.SecurityContext != nil && !permissions.anyUser {
+			if !permissions.nonRootUser || isRootUser(service.SecurityContext.RunAsUser) {
+				// the user and group ids are assigned from the range of the namespace
+				service.SecurityContext.RunAsUser = nil
+				service.SecurityContext.RunAsGroup = nil
+				service.SecurityContext.FSGroup = nil
+				service.SecurityContext.SupplementalGroups = nil
+			}
+		}
+		for i := range service.InitContainers {
+			service.InitContainers[i].SecurityContext = getRestrictedSecurityContext(serviceName, service.InitContainers[i], permissions)
+		}
+		for i := range service.Containers {
+			service.Containers[i].SecurityContext = getRestrictedSecurityContext(serviceName, service.Containers[i], permissions)
+		}
+		ir.Services[serviceName] = service
+	}
+	return ir, nil
+}
+
+// getRestrictedSecurityContext returns the security context of the container that is allowed by the security context constraints
+func getRestrictedSecurityContext(serviceName string, container core.Container, permissions sccPermissions) *core.SecurityContext {
+	securityContext := &core.SecurityContext{}
+	if container.SecurityContext != nil {
+		securityContext = container.SecurityContext.DeepCopy()
+	}
+	if !permissions.anyUser {
+		if !permissions.nonRootUser || isRootUser(securityContext.RunAsUser) {
+			if securityContext.RunAsUser != nil {
+				logrus.Debugf("Removing the user id %d of the container '%s' in the service '%s' . OpenShift assigns the user id from the range of the namespace.", *securityContext.RunAsUser, container.Name, serviceName)
+			}
+			securityContext.RunAsUser = nil
+			securityContext.RunAsGroup = nil
+		}
+		runAsNonRoot := true
+		securityContext.RunAsNonRoot = &runAsNonRoot
+	}
+	if permissions.privileged {
+		return securityContext
+	}
+	if securityContext.Privileged != nil && *securityContext.Privileged {
+		logrus.Warnf("The container '%s' in the service '%s' is privileged, which is not allowed by the security context constraints of the target cluster. Running it unprivileged.", container.Name, serviceName)
+	}
+	securityContext.Privileged = nil
+	allowPrivilegeEscalation := false
+	securityContext.AllowPrivilegeEscalation = &allowPrivilegeEscalation
+	capabilities := &core.Capabilities{Drop: []core.Capability{"ALL"}}
+	if securityContext.Capabilities != nil {
+		for _, capability := range securityContext.Capabilities.Add {
+			if !common.IsPresent(restrictedCapabilities, capability) {
+				logrus.Warnf("The capability '%s' of the container '%s' in the service '%s' is not allowed by the security context constraints of the target cluster. Dropping it.", capability, container.Name, serviceName)
+				continue
+			}
+			capabilities.Add = append(capabilities.Add, capability)
+		}
+	}
+	securityContext.Capabilities = capabilities
+	if securityContext.SeccompProfile == nil && permissions.seccomp {
+		securityContext.SeccompProfile = &core.SeccompProfile{Type: core.SeccompProfileTypeRuntimeDefault}
+	}
+	return securityContext
+}
+
+func isRootUser(uid *int64) bool {
+	return uid != nil && *uid == 0
+}
+
+func hasOverlap(a []string, b []string) bool {
+	for _, value := range a {
+		if common.IsPresent(b, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,112 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+const builtInOpenshiftClusterPath = "../../../assets/built-in/transformers/kubernetes/clusterselector/clusters/openshift.yaml"
+
+func TestSecurityContextPreprocessor(t *testing.T) {
+	getIR := func() irtypes.IR {
+		ir := irtypes.NewIR()
+		uid := int64(0)
+		privileged := true
+		service := irtypes.NewServiceWithName("web")
+		service.Containers = []core.Container{{
+			Name:  "web",
+			Image: "web:latest",
+			SecurityContext: &core.SecurityContext{
+				RunAsUser:    &uid,
+				Privileged:   &privileged,
+				Capabilities: &core.Capabilities{Add: []core.Capability{"NET_ADMIN", "NET_BIND_SERVICE"}},
+			},
+		}}
+		ir.Services["web"] = service
+		return ir
+	}
+	openshift := collection.NewClusterMetadata("openshift")
+	openshift.Spec.KubernetesVersion = "v1.26.0"
+	openshift.Spec.APIKindVersionMap = map[string][]string{"SecurityContextConstraints": {"security.openshift.io/v1"}}
+
+	t.Run("security contexts are not changed for clusters without security context constraints", func(t *testing.T) {
+		ir, err := new(securityContextPreprocessor).preprocess(getIR(), collection.NewClusterMetadata("kubernetes"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ir.Services["web"].Containers[0].SecurityContext.RunAsUser == nil {
+			t.Fatalf("expected the user of the container to not be changed")
+		}
+	})
+
+	t.Run("security contexts are not changed for the built-in openshift target", func(t *testing.T) {
+		builtInOpenshift := collection.ClusterMetadata{}
+		if err := common.ReadMove2KubeYaml(builtInOpenshiftClusterPath, &builtInOpenshift); err != nil {
+			t.Fatalf("failed to read the built-in openshift cluster metadata. Error: %q", err)
+		}
+		if !builtInOpenshift.Spec.UsesSecurityContextConstraints() {
+			t.Fatalf("expected the built-in openshift target to have the security context constraints api")
+		}
+		ir, err := new(securityContextPreprocessor).preprocess(getIR(), builtInOpenshift)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(getIR().Services["web"].Containers[0].SecurityContext, ir.Services["web"].Containers[0].SecurityContext); diff != "" {
+			t.Fatalf("expected the security context to not be changed. Differences:\n%s", diff)
+		}
+	})
+
+	t.Run("security contexts are restricted for the default security context constraint", func(t *testing.T) {
+		ir, err := new(securityContextPreprocessor).preprocess(getIR(), openshift)
+		if err != nil {
+			t.Fatal(err)
+		}
+		securityContext := ir.Services["web"].Containers[0].SecurityContext
+		if securityContext.RunAsUser != nil || securityContext.Privileged != nil {
+			t.Fatalf("expected the fixed user and privileged mode to be removed. Actual: %+v", securityContext)
+		}
+		if securityContext.RunAsNonRoot == nil || !*securityContext.RunAsNonRoot || securityContext.AllowPrivilegeEscalation == nil || *securityContext.AllowPrivilegeEscalation {
+			t.Fatalf("expected the container to run as non root without privilege escalation. Actual: %+v", securityContext)
+		}
+		if len(securityContext.Capabilities.Drop) != 1 || securityContext.Capabilities.Drop[0] != "ALL" || len(securityContext.Capabilities.Add) != 1 || securityContext.Capabilities.Add[0] != "NET_BIND_SERVICE" {
+			t.Fatalf("expected all capabilities except NET_BIND_SERVICE to be dropped. Actual: %+v", securityContext.Capabilities)
+		}
+		if securityContext.SeccompProfile == nil || securityContext.SeccompProfile.Type != core.SeccompProfileTypeRuntimeDefault {
+			t.Fatalf("expected the runtime default seccomp profile. Actual: %+v", securityContext.SeccompProfile)
+		}
+	})
+
+	t.Run("user is kept when the anyuid security context constraint is available", func(t *testing.T) {
+		cluster := openshift
+		cluster.Spec.SecurityContextConstraints = []string{"restricted-v2", "anyuid"}
+		ir, err := new(securityContextPreprocessor).preprocess(getIR(), cluster)
+		if err != nil {
+			t.Fatal(err)
+		}
+		securityContext := ir.Services["web"].Containers[0].SecurityContext
+		if securityContext.RunAsUser == nil || securityContext.Privileged != nil {
+			t.Fatalf("expected only the privileged mode to be removed. Actual: %+v", securityContext)
+		}
+	})
+}
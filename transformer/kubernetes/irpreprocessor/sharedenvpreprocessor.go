@@ -0,0 +1,200 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/sirupsen/logrus"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+const (
+	// minSharedEnvServices and minSharedEnvVars are the least number of services sharing the least number of identical env vars
+	// for the env vars to be moved into a common config map or secret
+	minSharedEnvServices = 2
+	minSharedEnvVars     = 2
+	// sharedEnvSuffix and sharedSecretEnvSuffix are the suffixes of the config maps and secrets holding the shared env vars
+	sharedEnvSuffix       = "shared-env"
+	sharedSecretEnvSuffix = "shared-secret-env"
+)
+
+// sharedEnvGroup is a set of identical env vars shared by the same services
+type sharedEnvGroup struct {
+	serviceNames []string
+	envs         []core.EnvVar
+}
+
+// sharedEnvPreprocessor moves the identical env vars shared by multiple services into common config maps and secrets,
+// which the containers refer to using envFrom, so that they are changed in one place
+type sharedEnvPreprocessor struct {
+}
+
+func (sp sharedEnvPreprocessor) preprocess(ir irtypes.IR, targetCluster collection.ClusterMetadata) (irtypes.IR, error) {
+	groups := getSharedEnvGroups(ir)
+	if len(groups) == 0 {
+		return ir, nil
+	}
+	if !qaengine.FetchBoolAnswer(
+		common.ConfigSharedEnvKey,
+		fmt.Sprintf("Found %d sets of identical env vars shared by multiple services. Move each of them into a common config map or secret?", len(groups)),
+		[]string{"The containers refer to the common config maps and secrets using envFrom, instead of repeating the env vars."},
+		false,
+		nil,
+	) {
+		return ir, nil
+	}
+	for i, group := range groups {
+		suffix := ""
+		if i > 0 {
+			suffix = fmt.Sprintf("-%d", i+1)
+		}
+		configMapName := getSharedEnvStorageName(ir, sharedEnvSuffix+suffix)
+		secretName := getSharedEnvStorageName(ir, sharedSecretEnvSuffix+suffix)
+		envNames := []string{}
+		configMapContent := map[string][]byte{}
+		secretContent := map[string][]byte{}
+		for _, env := range group.envs {
+			envNames = append(envNames, env.Name)
+			if common.SecretEnvNameRegex.MatchString(env.Name) {
+				secretContent[env.Name] = []byte(env.Value)
+			} else {
+				configMapContent[env.Name] = []byte(env.Value)
+			}
+		}
+		envFrom := []core.EnvFromSource{}
+		if len(configMapContent) != 0 {
+			ir.Storages = append(ir.Storages, irtypes.Storage{Name: configMapName, StorageType: irtypes.ConfigMapKind, Content: configMapContent})
+			envFrom = append(envFrom, core.EnvFromSource{ConfigMapRef: &core.ConfigMapEnvSource{LocalObjectReference: core.LocalObjectReference{Name: configMapName}}})
+		}
+		if len(secretContent) != 0 {
+			ir.Storages = append(ir.Storages, irtypes.Storage{Name: secretName, StorageType: irtypes.SecretKind, Content: secretContent})
+			envFrom = append(envFrom, core.EnvFromSource{SecretRef: &core.SecretEnvSource{LocalObjectReference: core.LocalObjectReference{Name: secretName}}})
+		}
+		logrus.Infof("Moving the env vars %+v shared by the services %+v into common config maps and secrets", envNames, group.serviceNames)
+		for _, serviceName := range group.serviceNames {
+			service := ir.Services[serviceName]
+			for ci := range service.Containers {
+				container := &service.Containers[ci]
+				envs := []core.EnvVar{}
+				for _, env := range container.Env {
+					if !common.IsPresent(envNames, env.Name) {
+						envs = append(envs, env)
+					}
+				}
+				container.Env = envs
+				// the later sources take precedence, like the env vars did over the earlier sources
+				container.EnvFrom = append(container.EnvFrom, envFrom...)
+			}
+			ir.Services[serviceName] = service
+		}
+	}
+	return ir, nil
+}
+
+// getSharedEnvGroups returns the sets of identical env vars shared by the same services.
+// An env var is shared by a service if all the containers of the service have it with a literal value.
+func getSharedEnvGroups(ir irtypes.IR) []sharedEnvGroup {
+	serviceNames := ir.GetSortedServiceNames()
+	envServices := map[core.EnvVar][]string{}
+	for _, serviceName := range serviceNames {
+		for _, env := range getCommonLiteralEnvs(ir.Services[serviceName]) {
+			envServices[env] = append(envServices[env], serviceName)
+		}
+	}
+	groupsByServices := map[string]*sharedEnvGroup{}
+	for env, envServiceNames := range envServices {
+		if len(envServiceNames) < minSharedEnvServices {
+			continue
+		}
+		key := strings.Join(envServiceNames, ",")
+		if _, ok := groupsByServices[key]; !ok {
+			groupsByServices[key] = &sharedEnvGroup{serviceNames: envServiceNames}
+		}
+		groupsByServices[key].envs = append(groupsByServices[key].envs, env)
+	}
+	groups := []sharedEnvGroup{}
+	for _, group := range groupsByServices {
+		if len(group.envs) < minSharedEnvVars {
+			continue
+		}
+		sort.Slice(group.envs, func(i, j int) bool { return group.envs[i].Name < group.envs[j].Name })
+		groups = append(groups, *group)
+	}
+	// the groups shared by more services come first
+	sort.Slice(groups, func(i, j int) bool {
+		if len(groups[i].serviceNames) != len(groups[j].serviceNames) {
+			return len(groups[i].serviceNames) > len(groups[j].serviceNames)
+		}
+		return strings.Join(groups[i].serviceNames, ",") < strings.Join(groups[j].serviceNames, ",")
+	})
+	return groups
+}
+
+// getCommonLiteralEnvs returns the env vars with literal values that all the containers of the service have.
+// The env vars referring to other env vars are left out, since they can only refer to the env vars defined before them.
+func getCommonLiteralEnvs(service irtypes.Service) []core.EnvVar {
+	if len(service.Containers) == 0 {
+		return nil
+	}
+	envs := []core.EnvVar{}
+	for _, env := range service.Containers[0].Env {
+		if env.ValueFrom != nil || strings.Contains(env.Value, "$(") {
+			continue
+		}
+		shared := true
+		for _, container := range service.Containers[1:] {
+			if !containsEnv(container.Env, env) {
+				shared = false
+				break
+			}
+		}
+		if shared {
+			envs = append(envs, env)
+		}
+	}
+	return envs
+}
+
+func containsEnv(envs []core.EnvVar, env core.EnvVar) bool {
+	for _, e := range envs {
+		if e.Name == env.Name && e.ValueFrom == nil && e.Value == env.Value {
+			return true
+		}
+	}
+	return false
+}
+
+// getSharedEnvStorageName returns a name for the config map or secret of the shared env vars that is not used by the other storages
+func getSharedEnvStorageName(ir irtypes.IR, suffix string) string {
+	name := common.MakeStringK8sServiceNameCompliant(suffix)
+	if ir.Name != "" {
+		name = common.MakeStringK8sServiceNameCompliant(ir.Name + "-" + suffix)
+	}
+	for _, storage := range ir.Storages {
+		if storage.Name == name {
+			return common.MakeStringK8sServiceNameCompliant(fmt.Sprintf("%s-%d", name, len(ir.Storages)))
+		}
+	}
+	return name
+}
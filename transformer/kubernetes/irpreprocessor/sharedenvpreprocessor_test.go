@@ -0,0 +1,72 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestSharedEnvPreprocessor(t *testing.T) {
+	qaengine.ResetEngines()
+	defer qaengine.ResetEngines()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", []string{`move2kube.sharedenv=true`}, nil, nil, false)
+	ir := irtypes.NewIR()
+	ir.Name = "shop"
+	sharedEnvs := []core.EnvVar{{Name: "DB_HOST", Value: "db"}, {Name: "DB_PASSWORD", Value: "secret"}, {Name: "LOG_LEVEL", Value: "info"}}
+	for _, serviceName := range []string{"api", "worker"} {
+		service := irtypes.NewServiceWithName(serviceName)
+		service.Containers = []core.Container{{Name: serviceName, Env: append([]core.EnvVar{{Name: "ROLE", Value: serviceName}}, sharedEnvs...)}}
+		ir.Services[serviceName] = service
+	}
+	web := irtypes.NewServiceWithName("web")
+	web.Containers = []core.Container{{Name: "web", Env: []core.EnvVar{{Name: "DB_HOST", Value: "db"}, {Name: "LOG_LEVEL", Value: "debug"}}}}
+	ir.Services["web"] = web
+
+	preprocessedIR, err := sharedEnvPreprocessor{}.preprocess(ir, collection.ClusterMetadata{})
+	if err != nil {
+		t.Fatalf("failed to preprocess the IR. Error: %q", err)
+	}
+	wantStorages := []irtypes.Storage{
+		{Name: "shop-shared-env", StorageType: irtypes.ConfigMapKind, Content: map[string][]byte{"LOG_LEVEL": []byte("info")}},
+		{Name: "shop-shared-secret-env", StorageType: irtypes.SecretKind, Content: map[string][]byte{"DB_PASSWORD": []byte("secret")}},
+	}
+	// DB_HOST is the only env var shared by all the services, so it is left as is
+	if diff := cmp.Diff(wantStorages, preprocessedIR.Storages); diff != "" {
+		t.Fatalf("wrong storages. Difference:\n%s", diff)
+	}
+	api := preprocessedIR.Services["api"].Containers[0]
+	if diff := cmp.Diff([]core.EnvVar{{Name: "ROLE", Value: "api"}, {Name: "DB_HOST", Value: "db"}}, api.Env); diff != "" {
+		t.Fatalf("wrong env vars. Difference:\n%s", diff)
+	}
+	wantEnvFrom := []core.EnvFromSource{
+		{ConfigMapRef: &core.ConfigMapEnvSource{LocalObjectReference: core.LocalObjectReference{Name: "shop-shared-env"}}},
+		{SecretRef: &core.SecretEnvSource{LocalObjectReference: core.LocalObjectReference{Name: "shop-shared-secret-env"}}},
+	}
+	if diff := cmp.Diff(wantEnvFrom, api.EnvFrom); diff != "" {
+		t.Fatalf("wrong env sources. Difference:\n%s", diff)
+	}
+	if web := preprocessedIR.Services["web"].Containers[0]; len(web.Env) != 2 || len(web.EnvFrom) != 0 {
+		t.Fatalf("expected the env vars of the web service to be left unchanged. Actual: %+v", web)
+	}
+}
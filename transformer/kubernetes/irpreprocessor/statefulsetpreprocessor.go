@@ -32,10 +32,11 @@ func (sp statefulsetPreprocessor) preprocess(ir irtypes.IR, targetCluster collec
 		return ir, nil
 	}
 
-	for k, scObj := range ir.Services {
+	for _, serviceName := range ir.GetSortedServiceNames() {
+		scObj := ir.Services[serviceName]
 		isStateful := commonqa.GetDeploymentType(scObj.Name)
 		scObj.DeploymentType = isStateful
-		ir.Services[k] = scObj
+		ir.Services[serviceName] = scObj
 	}
 
 	return ir, nil
@@ -0,0 +1,392 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/common/report"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/resource"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+const (
+	// maxStorageDataBytes is the maximum size of the data in a ConfigMap or Secret.
+	// The API server limit is 1MiB for the whole object, so leave some room for the metadata.
+	maxStorageDataBytes = 1000 * 1000
+
+	splitStorageOption       = "Split the content across multiple objects"
+	pvcLoaderStorageOption   = "Load the content into a PVC using an init container"
+	objectStoreStorageOption = "Download the content from an external object store using an init container"
+	keepStorageOption        = "Keep it as a single object"
+
+	storageLoaderImage         = "busybox:1.36.1"
+	storageDownloaderImage     = "curlimages/curl:8.4.0"
+	storageChunksMountPath     = "/m2k/chunks"
+	storageDataMountPath       = "/m2k/data"
+	storageChunkSuffixTemplate = ".m2kchunk%04d"
+)
+
+// storageSizePreprocessor handles ConfigMaps and Secrets whose content is too large for the API server
+type storageSizePreprocessor struct {
+}
+
+func (sp storageSizePreprocessor) preprocess(ir irtypes.IR, targetCluster collection.ClusterMetadata) (irtypes.IR, error) {
+	storages := []irtypes.Storage{}
+	for _, storage := range ir.Storages {
+		if storage.StorageType != irtypes.ConfigMapKind && storage.StorageType != irtypes.SecretKind {
+			storages = append(storages, storage)
+			continue
+		}
+		size := getStorageDataSize(storage.Content)
+		if size <= maxStorageDataBytes {
+			storages = append(storages, storage)
+			continue
+		}
+		options := []string{splitStorageOption, pvcLoaderStorageOption, objectStoreStorageOption, keepStorageOption}
+		def := splitStorageOption
+		if !canSplitStorage(storage.Content) {
+			options = []string{pvcLoaderStorageOption, objectStoreStorageOption, keepStorageOption}
+			def = pvcLoaderStorageOption
+		}
+		selectedOption := qaengine.FetchSelectAnswer(
+			fmt.Sprintf(common.ConfigStoragesOversizedKey, `"`+storage.Name+`"`),
+			fmt.Sprintf("The %s '%s' has %d bytes of data which is larger than the limit of %d bytes. How should it be handled?", storage.StorageType, storage.Name, size, maxStorageDataBytes),
+			[]string{"The API server will reject the object if it is kept as a single object."},
+			def,
+			options,
+			nil,
+		)
+		switch selectedOption {
+		case splitStorageOption:
+			parts := splitStorage(storage)
+			replaceStorageVolumes(ir, storage, func(volume core.Volume) []core.Volume {
+				volume.VolumeSource = getProjectedVolumeSource(volume.VolumeSource, parts)
+				return []core.Volume{volume}
+			})
+			replaceStorageEnvReferences(ir, storage, parts)
+			storages = append(storages, parts...)
+		case pvcLoaderStorageOption:
+			chunks := chunkStorage(storage)
+			pvc := irtypes.Storage{
+				Name:        storage.Name,
+				StorageType: irtypes.PVCKind,
+				PersistentVolumeClaimSpec: core.PersistentVolumeClaimSpec{
+					AccessModes: []core.PersistentVolumeAccessMode{getLoaderAccessMode(ir, storage)},
+					Resources: core.ResourceRequirements{
+						Requests: core.ResourceList{core.ResourceStorage: getPVCSize(size)},
+					},
+				},
+			}
+			keys := getSortedKeys(storage.Content)
+			replaceStorageVolumes(ir, storage, func(volume core.Volume) []core.Volume {
+				chunksVolume := core.Volume{Name: volume.Name + "-chunks", VolumeSource: getProjectedVolumeSource(volume.VolumeSource, chunks)}
+				volume.VolumeSource = core.VolumeSource{PersistentVolumeClaim: &core.PersistentVolumeClaimVolumeSource{ClaimName: pvc.Name}}
+				return []core.Volume{volume, chunksVolume}
+			}, func(serviceConfig *irtypes.Service, volume core.Volume) {
+				chunksVolumeName := volume.Name + "-chunks"
+				script := []string{"set -e"}
+				for _, key := range keys {
+					script = append(script, fmt.Sprintf("cat %s/%s.m2kchunk* > %s/%s", storageChunksMountPath, key, storageDataMountPath, key))
+				}
+				serviceConfig.InitContainers = append(serviceConfig.InitContainers, core.Container{
+					Name:    common.MakeStringK8sServiceNameCompliant(volume.Name + "-loader"),
+					Image:   storageLoaderImage,
+					Command: []string{"sh", "-c", strings.Join(script, "\n")},
+					VolumeMounts: []core.VolumeMount{
+						{Name: chunksVolumeName, MountPath: storageChunksMountPath, ReadOnly: true},
+						{Name: volume.Name, MountPath: storageDataMountPath},
+					},
+				})
+			})
+			replaceStorageEnvReferences(ir, storage, nil)
+			storages = append(storages, chunks...)
+			storages = append(storages, pvc)
+		case objectStoreStorageOption:
+			url := strings.TrimSuffix(qaengine.FetchStringAnswer(
+				fmt.Sprintf(common.ConfigStoragesObjectStoreURLKey, `"`+storage.Name+`"`),
+				fmt.Sprintf("Enter the url of the object store location to download the content of the %s '%s' from:", storage.StorageType, storage.Name),
+				[]string{"Each key will be downloaded from <url>/<key>. Upload the content to this location before deploying."},
+				"https://objectstore.example.com/"+storage.Name,
+				nil,
+			), "/")
+			keys := getSortedKeys(storage.Content)
+			replaceStorageVolumes(ir, storage, func(volume core.Volume) []core.Volume {
+				volume.VolumeSource = core.VolumeSource{EmptyDir: &core.EmptyDirVolumeSource{}}
+				return []core.Volume{volume}
+			}, func(serviceConfig *irtypes.Service, volume core.Volume) {
+				script := []string{"set -e"}
+				for _, key := range keys {
+					script = append(script, fmt.Sprintf("curl -fsSL -o %s/%s %s/%s", storageDataMountPath, key, url, key))
+				}
+				serviceConfig.InitContainers = append(serviceConfig.InitContainers, core.Container{
+					Name:         common.MakeStringK8sServiceNameCompliant(volume.Name + "-downloader"),
+					Image:        storageDownloaderImage,
+					Command:      []string{"sh", "-c", strings.Join(script, "\n")},
+					VolumeMounts: []core.VolumeMount{{Name: volume.Name, MountPath: storageDataMountPath}},
+				})
+			})
+			replaceStorageEnvReferences(ir, storage, nil)
+			logrus.Infof("Upload the keys %+v of the %s '%s' to the object store at '%s' before deploying", keys, storage.StorageType, storage.Name, url)
+		default:
+			logrus.Warnf("The %s '%s' is larger than %d bytes and will be rejected by the API server", storage.StorageType, storage.Name, maxStorageDataBytes)
+			storages = append(storages, storage)
+		}
+	}
+	ir.Storages = storages
+	return ir, nil
+}
+
+func getStorageDataSize(content map[string][]byte) int {
+	size := 0
+	for key, value := range content {
+		size += len(key) + len(value)
+	}
+	return size
+}
+
+func getSortedKeys(content map[string][]byte) []string {
+	keys := []string{}
+	for key := range content {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// canSplitStorage returns true if every key fits in an object by itself
+func canSplitStorage(content map[string][]byte) bool {
+	for key, value := range content {
+		if len(key)+len(value) > maxStorageDataBytes {
+			return false
+		}
+	}
+	return true
+}
+
+// splitStorage distributes the keys across as few objects as possible
+func splitStorage(storage irtypes.Storage) []irtypes.Storage {
+	return packStorageContent(storage, "part", storage.Content)
+}
+
+// chunkStorage splits the values into chunks and distributes the chunks across multiple objects.
+// The chunks of a key are named <key>.m2kchunk<index> so that concatenating them in lexical order restores the value.
+func chunkStorage(storage irtypes.Storage) []irtypes.Storage {
+	chunks := map[string][]byte{}
+	for key, value := range storage.Content {
+		chunkSize := maxStorageDataBytes - len(key) - len(fmt.Sprintf(storageChunkSuffixTemplate, 0))
+		for i := 0; i == 0 || i*chunkSize < len(value); i++ {
+			end := (i + 1) * chunkSize
+			if end > len(value) {
+				end = len(value)
+			}
+			chunks[key+fmt.Sprintf(storageChunkSuffixTemplate, i)] = value[i*chunkSize : end]
+		}
+	}
+	return packStorageContent(storage, "chunk", chunks)
+}
+
+func packStorageContent(storage irtypes.Storage, suffix string, content map[string][]byte) []irtypes.Storage {
+	parts := []irtypes.Storage{}
+	size := 0
+	for _, key := range getSortedKeys(content) {
+		keySize := len(key) + len(content[key])
+		if len(parts) == 0 || size+keySize > maxStorageDataBytes {
+			part := storage
+			part.Name = fmt.Sprintf("%s-%s-%d", storage.Name, suffix, len(parts))
+			part.Content = map[string][]byte{}
+			parts = append(parts, part)
+			size = 0
+		}
+		parts[len(parts)-1].Content[key] = content[key]
+		size += keySize
+	}
+	return parts
+}
+
+func getPVCSize(size int) resource.Quantity {
+	// leave room for the file system overhead
+	quantity := *resource.NewQuantity(int64(size)*2, resource.BinarySI)
+	if quantity.Cmp(common.DefaultPVCSize) < 0 {
+		return common.DefaultPVCSize.DeepCopy()
+	}
+	return quantity
+}
+
+// getProjectedVolumeSource returns a volume source that combines the keys of all the parts into a single directory
+func getProjectedVolumeSource(original core.VolumeSource, parts []irtypes.Storage) core.VolumeSource {
+	projected := &core.ProjectedVolumeSource{}
+	items := []core.KeyToPath{}
+	if original.ConfigMap != nil {
+		projected.DefaultMode = original.ConfigMap.DefaultMode
+		items = original.ConfigMap.Items
+	} else if original.Secret != nil {
+		projected.DefaultMode = original.Secret.DefaultMode
+		items = original.Secret.Items
+	}
+	for _, part := range parts {
+		partItems := []core.KeyToPath{}
+		for _, item := range items {
+			if _, ok := part.Content[item.Key]; ok {
+				partItems = append(partItems, item)
+			}
+		}
+		if len(items) > 0 && len(partItems) == 0 {
+			continue
+		}
+		if part.StorageType == irtypes.SecretKind {
+			projected.Sources = append(projected.Sources, core.VolumeProjection{Secret: &core.SecretProjection{
+				LocalObjectReference: core.LocalObjectReference{Name: part.Name},
+				Items:                partItems,
+			}})
+			continue
+		}
+		projected.Sources = append(projected.Sources, core.VolumeProjection{ConfigMap: &core.ConfigMapProjection{
+			LocalObjectReference: core.LocalObjectReference{Name: part.Name},
+			Items:                partItems,
+		}})
+	}
+	return core.VolumeSource{Projected: projected}
+}
+
+// replaceStorageVolumes replaces the volumes that use the storage in all the services.
+// The optional update functions are called with the original volume for every replaced volume in a service.
+func replaceStorageVolumes(ir irtypes.IR, storage irtypes.Storage, replace func(core.Volume) []core.Volume, updates ...func(*irtypes.Service, core.Volume)) {
+	for serviceName, serviceConfig := range ir.Services {
+		newVolumes := []core.Volume{}
+		for _, volume := range serviceConfig.Volumes {
+			if !isStorageVolume(volume, storage) {
+				newVolumes = append(newVolumes, volume)
+				continue
+			}
+			logrus.Debugf("replacing the volume '%s' of the service '%s' that uses the %s '%s'", volume.Name, serviceName, storage.StorageType, storage.Name)
+			newVolumes = append(newVolumes, replace(volume)...)
+			for _, update := range updates {
+				update(&serviceConfig, volume)
+			}
+		}
+		serviceConfig.Volumes = newVolumes
+		ir.Services[serviceName] = serviceConfig
+	}
+}
+
+// getLoaderAccessMode returns the access mode of the PVC the content of the storage is loaded into.
+// The init container of every pod using the storage writes the content, so the PVC must be writable by all of them.
+func getLoaderAccessMode(ir irtypes.IR, storage irtypes.Storage) core.PersistentVolumeAccessMode {
+	pods := 0
+	for _, serviceConfig := range ir.Services {
+		for _, volume := range serviceConfig.Volumes {
+			if isStorageVolume(volume, storage) {
+				if serviceConfig.Replicas > 1 {
+					pods += serviceConfig.Replicas
+				} else {
+					pods++
+				}
+				break
+			}
+		}
+	}
+	if pods > 1 {
+		return core.ReadWriteMany
+	}
+	return core.ReadWriteOnce
+}
+
+// replaceStorageEnvReferences replaces the env variables that use the storage in all the services.
+// The references are moved to the parts that contain the keys, or removed if the storage is not kept as objects.
+func replaceStorageEnvReferences(ir irtypes.IR, storage irtypes.Storage, parts []irtypes.Storage) {
+	getPartName := func(key string) string {
+		for _, part := range parts {
+			if _, ok := part.Content[key]; ok {
+				return part.Name
+			}
+		}
+		return ""
+	}
+	for serviceName, serviceConfig := range ir.Services {
+		for _, containers := range [][]core.Container{serviceConfig.Containers, serviceConfig.InitContainers} {
+			for i := range containers {
+				container := &containers[i]
+				newEnvs := []core.EnvVar{}
+				for _, env := range container.Env {
+					var name *string
+					var key string
+					if env.ValueFrom != nil && env.ValueFrom.ConfigMapKeyRef != nil && storage.StorageType == irtypes.ConfigMapKind {
+						name, key = &env.ValueFrom.ConfigMapKeyRef.Name, env.ValueFrom.ConfigMapKeyRef.Key
+					} else if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil && storage.StorageType == irtypes.SecretKind {
+						name, key = &env.ValueFrom.SecretKeyRef.Name, env.ValueFrom.SecretKeyRef.Key
+					}
+					if name == nil || *name != storage.Name {
+						newEnvs = append(newEnvs, env)
+						continue
+					}
+					if partName := getPartName(key); partName != "" {
+						*name = partName
+						newEnvs = append(newEnvs, env)
+						continue
+					}
+					logrus.Warnf("removed the env variable '%s' of the service '%s' since the %s '%s' is not kept as an object", env.Name, serviceName, storage.StorageType, storage.Name)
+					report.AddFollowUp(serviceName, fmt.Sprintf("Read the key %s of the %s %s from the files of its volume instead of the env variable %s", key, storage.StorageType, storage.Name, env.Name))
+				}
+				if len(container.Env) > 0 {
+					container.Env = newEnvs
+				}
+				newEnvFroms := []core.EnvFromSource{}
+				for _, envFrom := range container.EnvFrom {
+					isConfigMapRef := envFrom.ConfigMapRef != nil && envFrom.ConfigMapRef.Name == storage.Name && storage.StorageType == irtypes.ConfigMapKind
+					isSecretRef := envFrom.SecretRef != nil && envFrom.SecretRef.Name == storage.Name && storage.StorageType == irtypes.SecretKind
+					if !isConfigMapRef && !isSecretRef {
+						newEnvFroms = append(newEnvFroms, envFrom)
+						continue
+					}
+					if len(parts) == 0 {
+						logrus.Warnf("removed the env variables of the service '%s' from the %s '%s' since it is not kept as an object", serviceName, storage.StorageType, storage.Name)
+						report.AddFollowUp(serviceName, fmt.Sprintf("Read the %s %s from the files of its volume instead of the env variables", storage.StorageType, storage.Name))
+						continue
+					}
+					for _, part := range parts {
+						partEnvFrom := *envFrom.DeepCopy()
+						if isConfigMapRef {
+							partEnvFrom.ConfigMapRef.Name = part.Name
+						} else {
+							partEnvFrom.SecretRef.Name = part.Name
+						}
+						newEnvFroms = append(newEnvFroms, partEnvFrom)
+					}
+				}
+				if len(container.EnvFrom) > 0 {
+					container.EnvFrom = newEnvFroms
+				}
+			}
+		}
+		ir.Services[serviceName] = serviceConfig
+	}
+}
+
+func isStorageVolume(volume core.Volume, storage irtypes.Storage) bool {
+	if storage.StorageType == irtypes.ConfigMapKind {
+		return volume.ConfigMap != nil && volume.ConfigMap.Name == storage.Name
+	}
+	return volume.Secret != nil && volume.Secret.SecretName == storage.Name
+}
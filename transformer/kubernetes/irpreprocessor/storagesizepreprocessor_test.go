@@ -0,0 +1,165 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func getIRWithOversizedConfigMap(content map[string][]byte) irtypes.IR {
+	ir := irtypes.NewIR()
+	ir.Storages = []irtypes.Storage{{Name: "config", StorageType: irtypes.ConfigMapKind, Content: content}}
+	svc := irtypes.NewServiceWithName("svc")
+	svc.Volumes = []core.Volume{{Name: "config", VolumeSource: core.VolumeSource{ConfigMap: &core.ConfigMapVolumeSource{LocalObjectReference: core.LocalObjectReference{Name: "config"}}}}}
+	svc.Containers = []core.Container{{Name: "svc", VolumeMounts: []core.VolumeMount{{Name: "config", MountPath: "/config"}}}}
+	ir.Services["svc"] = svc
+	return ir
+}
+
+func TestStorageSizePreprocessor(t *testing.T) {
+	var clusterConfig collection.ClusterMetadata
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+
+	t.Run("small config map is not changed", func(t *testing.T) {
+		ir := getIRWithOversizedConfigMap(map[string][]byte{"a": []byte("hello")})
+		actual, err := storageSizePreprocessor{}.preprocess(ir, clusterConfig)
+		if err != nil {
+			t.Fatal("Failed to preprocess the IR. Error:", err)
+		}
+		if len(actual.Storages) != 1 || actual.Storages[0].Name != "config" {
+			t.Fatalf("expected the storage to be unchanged. Actual: %+v", actual.Storages)
+		}
+	})
+
+	t.Run("config map with small keys is split across objects", func(t *testing.T) {
+		value := []byte(strings.Repeat("a", 600*1000))
+		ir := getIRWithOversizedConfigMap(map[string][]byte{"a": value, "b": value, "c": value})
+		actual, err := storageSizePreprocessor{}.preprocess(ir, clusterConfig)
+		if err != nil {
+			t.Fatal("Failed to preprocess the IR. Error:", err)
+		}
+		if len(actual.Storages) != 3 {
+			t.Fatalf("expected 3 storages. Actual: %d", len(actual.Storages))
+		}
+		for _, storage := range actual.Storages {
+			if getStorageDataSize(storage.Content) > maxStorageDataBytes {
+				t.Fatalf("the storage '%s' is still too large", storage.Name)
+			}
+		}
+		volumes := actual.Services["svc"].Volumes
+		if len(volumes) != 1 || volumes[0].Projected == nil || len(volumes[0].Projected.Sources) != 3 {
+			t.Fatalf("expected a projected volume with 3 sources. Actual: %+v", volumes)
+		}
+	})
+
+	t.Run("env references are moved to the parts", func(t *testing.T) {
+		value := []byte(strings.Repeat("a", 600*1000))
+		ir := getIRWithOversizedConfigMap(map[string][]byte{"a": value, "b": value, "c": value})
+		svc := ir.Services["svc"]
+		svc.Containers[0].Env = []core.EnvVar{{Name: "B", ValueFrom: &core.EnvVarSource{ConfigMapKeyRef: &core.ConfigMapKeySelector{LocalObjectReference: core.LocalObjectReference{Name: "config"}, Key: "b"}}}}
+		svc.Containers[0].EnvFrom = []core.EnvFromSource{{ConfigMapRef: &core.ConfigMapEnvSource{LocalObjectReference: core.LocalObjectReference{Name: "config"}}}}
+		ir.Services["svc"] = svc
+		actual, err := storageSizePreprocessor{}.preprocess(ir, clusterConfig)
+		if err != nil {
+			t.Fatal("Failed to preprocess the IR. Error:", err)
+		}
+		container := actual.Services["svc"].Containers[0]
+		if len(container.Env) != 1 || container.Env[0].ValueFrom.ConfigMapKeyRef.Name != "config-part-1" {
+			t.Fatalf("expected the env to use the part containing the key. Actual: %+v", container.Env)
+		}
+		if len(container.EnvFrom) != 3 {
+			t.Fatalf("expected an envFrom for each part. Actual: %+v", container.EnvFrom)
+		}
+		for i, envFrom := range container.EnvFrom {
+			if expected := fmt.Sprintf("config-part-%d", i); envFrom.ConfigMapRef.Name != expected {
+				t.Fatalf("expected the envFrom to use the part %s . Actual: %+v", expected, envFrom)
+			}
+		}
+	})
+
+	t.Run("config map with a large key is loaded into a pvc", func(t *testing.T) {
+		value := []byte(strings.Repeat("a", 2500*1000))
+		ir := getIRWithOversizedConfigMap(map[string][]byte{"big": value})
+		actual, err := storageSizePreprocessor{}.preprocess(ir, clusterConfig)
+		if err != nil {
+			t.Fatal("Failed to preprocess the IR. Error:", err)
+		}
+		chunks := []byte{}
+		pvcs := 0
+		for _, storage := range actual.Storages {
+			if storage.StorageType == irtypes.PVCKind {
+				pvcs++
+				continue
+			}
+			if getStorageDataSize(storage.Content) > maxStorageDataBytes {
+				t.Fatalf("the storage '%s' is still too large", storage.Name)
+			}
+			for _, key := range getSortedKeys(storage.Content) {
+				chunks = append(chunks, storage.Content[key]...)
+			}
+		}
+		if pvcs != 1 {
+			t.Fatalf("expected 1 pvc. Actual: %d", pvcs)
+		}
+		if string(chunks) != string(value) {
+			t.Fatalf("the chunks do not add up to the original content")
+		}
+		svc := actual.Services["svc"]
+		if len(svc.InitContainers) != 1 {
+			t.Fatalf("expected 1 init container. Actual: %d", len(svc.InitContainers))
+		}
+		if len(svc.Volumes) != 2 || svc.Volumes[0].PersistentVolumeClaim == nil || svc.Volumes[1].Projected == nil {
+			t.Fatalf("expected a pvc volume and a projected chunks volume. Actual: %+v", svc.Volumes)
+		}
+		if svc.InitContainers[0].Image != storageLoaderImage || strings.HasSuffix(storageLoaderImage, ":latest") {
+			t.Fatalf("expected the loader to use the pinned image %s . Actual: %s", storageLoaderImage, svc.InitContainers[0].Image)
+		}
+		for _, storage := range actual.Storages {
+			if storage.StorageType == irtypes.PVCKind && storage.AccessModes[0] != core.ReadWriteOnce {
+				t.Fatalf("expected the pvc of a single pod to be ReadWriteOnce. Actual: %+v", storage.AccessModes)
+			}
+		}
+	})
+
+	t.Run("pvc of multiple replicas is shared", func(t *testing.T) {
+		value := []byte(strings.Repeat("a", 2500*1000))
+		ir := getIRWithOversizedConfigMap(map[string][]byte{"big": value})
+		svc := ir.Services["svc"]
+		svc.Replicas = 2
+		svc.Containers[0].Env = []core.EnvVar{{Name: "BIG", ValueFrom: &core.EnvVarSource{ConfigMapKeyRef: &core.ConfigMapKeySelector{LocalObjectReference: core.LocalObjectReference{Name: "config"}, Key: "big"}}}}
+		ir.Services["svc"] = svc
+		actual, err := storageSizePreprocessor{}.preprocess(ir, clusterConfig)
+		if err != nil {
+			t.Fatal("Failed to preprocess the IR. Error:", err)
+		}
+		for _, storage := range actual.Storages {
+			if storage.StorageType == irtypes.PVCKind && storage.AccessModes[0] != core.ReadWriteMany {
+				t.Fatalf("expected the pvc of multiple replicas to be ReadWriteMany. Actual: %+v", storage.AccessModes)
+			}
+		}
+		if envs := actual.Services["svc"].Containers[0].Env; len(envs) != 0 {
+			t.Fatalf("expected the env using the config map to be removed. Actual: %+v", envs)
+		}
+	})
+}
@@ -0,0 +1,58 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/konveyor/move2kube/types/qaengine/commonqa"
+	"github.com/sirupsen/logrus"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+// topologySpreadPreprocessor adds topology spread constraints to the services with multiple replicas
+type topologySpreadPreprocessor struct {
+}
+
+func (tp topologySpreadPreprocessor) preprocess(ir irtypes.IR, targetCluster collection.ClusterMetadata) (irtypes.IR, error) {
+	for _, serviceName := range ir.GetSortedServiceNames() {
+		service := ir.Services[serviceName]
+		if service.Replicas <= 1 || service.Daemon || len(service.TopologySpreadConstraints) > 0 {
+			continue
+		}
+		topologies := commonqa.TopologySpread(serviceName)
+		if len(topologies) == 0 {
+			continue
+		}
+		maxSkew := commonqa.TopologySpreadMaxSkew(serviceName)
+		for _, topology := range topologies {
+			topologyKey, ok := topologyKeys[topology]
+			if !ok {
+				logrus.Warnf("Ignoring the unknown topology %s for the service %s", topology, serviceName)
+				continue
+			}
+			service.TopologySpreadConstraints = append(service.TopologySpreadConstraints, core.TopologySpreadConstraint{
+				MaxSkew:           maxSkew,
+				TopologyKey:       topologyKey,
+				WhenUnsatisfiable: core.ScheduleAnyway,
+				LabelSelector:     getServiceLabelSelector(service.Name),
+			})
+		}
+		ir.Services[serviceName] = service
+	}
+	return ir, nil
+}
@@ -0,0 +1,58 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"testing"
+
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestTopologySpreadPreprocessor(t *testing.T) {
+	qaengine.ResetEngines()
+	defer qaengine.ResetEngines()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", []string{`move2kube.services."web".topologyspread.topologies=["zone","hostname"]`, `move2kube.services."web".topologyspread.maxskew="2"`}, nil, nil, false)
+	ir := irtypes.NewIR()
+	web := irtypes.NewServiceWithName("web")
+	web.Replicas = 3
+	ir.Services["web"] = web
+	db := irtypes.NewServiceWithName("db")
+	db.Replicas = 2
+	ir.Services["db"] = db
+
+	preprocessedIR, err := topologySpreadPreprocessor{}.preprocess(ir, collection.ClusterMetadata{})
+	if err != nil {
+		t.Fatalf("failed to preprocess the IR. Error: %q", err)
+	}
+	constraints := preprocessedIR.Services["web"].TopologySpreadConstraints
+	if len(constraints) != 2 {
+		t.Fatalf("expected a topology spread constraint for each of the topologies. Actual: %+v", constraints)
+	}
+	for i, topologyKey := range []string{"topology.kubernetes.io/zone", "kubernetes.io/hostname"} {
+		constraint := constraints[i]
+		if constraint.TopologyKey != topologyKey || constraint.MaxSkew != 2 || constraint.WhenUnsatisfiable != core.ScheduleAnyway || constraint.LabelSelector.MatchLabels["move2kube.konveyor.io/service"] != "web" {
+			t.Fatalf("wrong topology spread constraint. Actual: %+v", constraint)
+		}
+	}
+	if constraints := preprocessedIR.Services["db"].TopologySpreadConstraints; len(constraints) != 0 {
+		t.Fatalf("expected no topology spread constraints by default. Actual: %+v", constraints)
+	}
+}
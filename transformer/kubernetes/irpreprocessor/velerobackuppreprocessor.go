@@ -0,0 +1,67 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package irpreprocessor
+
+import (
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/konveyor/move2kube/types/qaengine/commonqa"
+)
+
+const (
+	// veleroBackupVolumesAnnotation opts the volumes of a pod into the file system backup of Velero
+	veleroBackupVolumesAnnotation = "backup.velero.io/backup-volumes"
+)
+
+// veleroBackupPreprocessor opts the persistent volumes of the services into the file system backup of Velero
+type veleroBackupPreprocessor struct {
+}
+
+func (vp veleroBackupPreprocessor) preprocess(ir irtypes.IR, targetCluster collection.ClusterMetadata) (irtypes.IR, error) {
+	serviceNames := []string{}
+	for _, serviceName := range ir.GetSortedServiceNames() {
+		if len(getPVCVolumeNames(ir.Services[serviceName])) > 0 {
+			serviceNames = append(serviceNames, serviceName)
+		}
+	}
+	if len(serviceNames) == 0 || !commonqa.VeleroBackup() || commonqa.VeleroBackupMethod() != commonqa.FileSystemBackupMethod {
+		return ir, nil
+	}
+	for _, serviceName := range serviceNames {
+		service := ir.Services[serviceName]
+		if _, ok := service.Annotations[veleroBackupVolumesAnnotation]; ok {
+			continue
+		}
+		service.Annotations = common.MergeStringMaps(map[string]string{}, service.Annotations)
+		service.Annotations[veleroBackupVolumesAnnotation] = strings.Join(getPVCVolumeNames(service), ",")
+		ir.Services[serviceName] = service
+	}
+	return ir, nil
+}
+
+// getPVCVolumeNames returns the names of the volumes of the service that are backed by persistent volume claims
+func getPVCVolumeNames(service irtypes.Service) []string {
+	volumeNames := []string{}
+	for _, volume := range service.Volumes {
+		if volume.PersistentVolumeClaim != nil {
+			volumeNames = append(volumeNames, volume.Name)
+		}
+	}
+	return volumeNames
+}
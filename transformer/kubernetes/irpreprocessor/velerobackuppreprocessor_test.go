@@ -0,0 +1,52 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package irpreprocessor
+
+import (
+	"testing"
+
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestVeleroBackupPreprocessor(t *testing.T) {
+	qaengine.ResetEngines()
+	defer qaengine.ResetEngines()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", []string{`move2kube.backup.enable=true`, `move2kube.backup.method="fsb"`}, nil, nil, false)
+	ir := irtypes.NewIR()
+	db := irtypes.NewServiceWithName("db")
+	db.Volumes = []core.Volume{
+		{Name: "data", VolumeSource: core.VolumeSource{PersistentVolumeClaim: &core.PersistentVolumeClaimVolumeSource{ClaimName: "data"}}},
+		{Name: "config", VolumeSource: core.VolumeSource{ConfigMap: &core.ConfigMapVolumeSource{LocalObjectReference: core.LocalObjectReference{Name: "config"}}}},
+		{Name: "wal", VolumeSource: core.VolumeSource{PersistentVolumeClaim: &core.PersistentVolumeClaimVolumeSource{ClaimName: "wal"}}},
+	}
+	ir.Services["db"] = db
+	ir.Services["web"] = irtypes.NewServiceWithName("web")
+
+	preprocessedIR, err := veleroBackupPreprocessor{}.preprocess(ir, collection.ClusterMetadata{})
+	if err != nil {
+		t.Fatalf("failed to preprocess the IR. Error: %q", err)
+	}
+	if actual := preprocessedIR.Services["db"].Annotations[veleroBackupVolumesAnnotation]; actual != "data,wal" {
+		t.Fatalf("expected only the persistent volumes to be opted into the backup. Actual: %q", actual)
+	}
+	if _, ok := preprocessedIR.Services["web"].Annotations[veleroBackupVolumesAnnotation]; ok {
+		t.Fatalf("expected the service without persistent volumes to be unchanged. Actual: %+v", preprocessedIR.Services["web"].Annotations)
+	}
+}
@@ -17,6 +17,7 @@
 package kubernetes
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 
@@ -100,16 +101,15 @@ func (t *Knative) Transform(newArtifacts []transformertypes.Artifact, alreadySee
 		ir.Name = a.Name
 		preprocessedIR, err := irpreprocessor.Preprocess(ir, clusterConfig)
 		if err != nil {
-			logrus.Errorf("Unable to prepreocess IR : %s", err)
-		} else {
-			ir = preprocessedIR
+			return nil, nil, fmt.Errorf("failed to preprocess the IR of the artifact '%s' . Error: %w", a.Name, err)
 		}
+		ir = preprocessedIR
 		deployKnativeDir := t.KnativeConfig.OutputPath
 		tempDest := filepath.Join(t.Env.TempPath, deployKnativeDir)
 		logrus.Debugf("Starting Kubernetes transform")
 		logrus.Debugf("Total services to be transformed : %d", len(ir.Services))
 		apis := []apiresource.IAPIResource{&apiresource.KnativeService{}}
-		files, err := apiresource.TransformIRAndPersist(irtypes.NewEnhancedIRFromIR(ir), tempDest, apis, clusterConfig, t.KnativeConfig.SetDefaultValuesInYamls)
+		files, err := apiresource.TransformIRAndPersist(irtypes.NewEnhancedIRFromIR(ir), tempDest, apis, clusterConfig, t.KnativeConfig.SetDefaultValuesInYamls, t.Config)
 		if err != nil {
 			logrus.Errorf("Unable to transform and persist IR : %s", err)
 			return nil, nil, err
@@ -131,10 +131,9 @@ func (t *Kubernetes) Transform(newArtifacts []transformertypes.Artifact, already
 		}
 		preprocessedIR, err := irpreprocessor.Preprocess(ir, clusterConfig)
 		if err != nil {
-			logrus.Errorf("failed to pre-preocess the IR. Error: %q", err)
-		} else {
-			ir = preprocessedIR
+			return nil, nil, fmt.Errorf("failed to preprocess the IR of the artifact '%s' . Error: %w", newArtifact.Name, err)
 		}
+		ir = preprocessedIR
 		tempDest := filepath.Join(t.Env.TempPath, "k8s-yamls-"+common.GetRandomString())
 		logrus.Debugf("Starting Kubernetes transform")
 		logrus.Debugf("Total services to be transformed: %d", len(ir.Services))
@@ -144,11 +143,26 @@ func (t *Kubernetes) Transform(newArtifacts []transformertypes.Artifact, already
 			new(apiresource.Service),
 			new(apiresource.ImageStream),
 			new(apiresource.NetworkPolicy),
+			new(apiresource.PriorityClass),
+			new(apiresource.PrometheusRule),
+			new(apiresource.GrafanaDashboard),
+			new(apiresource.FluentBit),
+			new(apiresource.VeleroSchedule),
+			new(apiresource.Namespace),
+			new(apiresource.PolicyException),
 		}
-		files, err := apiresource.TransformIRAndPersist(irtypes.NewEnhancedIRFromIR(ir), tempDest, apis, clusterConfig, t.KubernetesConfig.SetDefaultValuesInYamls)
+		files, err := apiresource.TransformIRAndPersist(irtypes.NewEnhancedIRFromIR(ir), tempDest, apis, clusterConfig, t.KubernetesConfig.SetDefaultValuesInYamls, t.Config)
 		if err != nil {
 			return nil, nil, fmt.Errorf("failed to transform and persist the IR. Error: %w", err)
 		}
+		if script := getPortForwardScript(ir); script != "" {
+			pathMapping, err := writePortForwardScript(t.Env.TempPath, script)
+			if err != nil {
+				logrus.Errorf("failed to write the port-forward script. Error: %q", err)
+			} else {
+				pathMappings = append(pathMappings, pathMapping)
+			}
+		}
 		serviceFsPath := ""
 		if serviceFsPaths, ok := newArtifact.Paths[artifacts.ServiceDirPathType]; ok && len(serviceFsPaths) > 0 {
 			serviceFsPath = serviceFsPaths[0]
@@ -176,7 +190,11 @@ func (t *Kubernetes) Transform(newArtifacts []transformertypes.Artifact, already
 			moreParams := []parameterizer.ParameterizerT{}
 			if err := newArtifact.GetConfig(ExtraParameterizersConfigType, &moreParams); err != nil {
 				logrus.Debugf("failed to load config of type '%s' into struct of type %T . Error: %q", ExtraParameterizersConfigType, moreParams, err)
-			} else {
+			}
+			moreParams = append(moreParams, getImageParameterizers(ir)...)
+			moreParams = append(moreParams, getResourcePresetParameterizers(ir)...)
+			moreParams = append(moreParams, getPodAntiAffinityParameterizers(ir)...)
+			if len(moreParams) > 0 {
 				if createdArtifact.Configs == nil {
 					createdArtifact.Configs = map[string]interface{}{}
 				}
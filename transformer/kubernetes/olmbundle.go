@@ -0,0 +1,156 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+)
+
+const (
+	olmBundleDir           = "bundle"
+	olmBundleDockerfile    = "bundle.Dockerfile"
+	olmBundleVersion       = "0.1.0"
+	olmBundleChannel       = "alpha"
+	olmBundleManifestsDir  = "manifests"
+	olmBundleMetadataDir   = "metadata"
+	olmBundleMediaType     = "registry+v1"
+	olmBundleAnnotationKey = "operators.operatorframework.io.bundle."
+)
+
+// getOLMBundleAnnotations returns the annotations of the bundle, that are also the labels of the bundle image
+func getOLMBundleAnnotations(config helmOperatorConfig) map[string]string {
+	return map[string]string{
+		olmBundleAnnotationKey + "mediatype.v1":       olmBundleMediaType,
+		olmBundleAnnotationKey + "manifests.v1":       olmBundleManifestsDir + "/",
+		olmBundleAnnotationKey + "metadata.v1":        olmBundleMetadataDir + "/",
+		olmBundleAnnotationKey + "package.v1":         config.chartName + "-operator",
+		olmBundleAnnotationKey + "channels.v1":        olmBundleChannel,
+		olmBundleAnnotationKey + "channel.default.v1": olmBundleChannel,
+	}
+}
+
+// generateOLMBundle generates a bundle of the helm operator that can be added to an operator catalog
+func generateOLMBundle(config helmOperatorConfig, operatorDir string, crd, role, leaderElectionRole, deployment map[string]interface{}, samples []map[string]interface{}) error {
+	manifestsDir := filepath.Join(operatorDir, olmBundleDir, olmBundleManifestsDir)
+	metadataDir := filepath.Join(operatorDir, olmBundleDir, olmBundleMetadataDir)
+	for _, dir := range []string{manifestsDir, metadataDir} {
+		if err := os.MkdirAll(dir, common.DefaultDirectoryPermission); err != nil {
+			return fmt.Errorf("failed to create the directory %s . Error: %w", dir, err)
+		}
+	}
+	csv, err := getClusterServiceVersion(config, role, leaderElectionRole, deployment, samples)
+	if err != nil {
+		return fmt.Errorf("failed to create the cluster service version. Error: %w", err)
+	}
+	if err := common.WriteYaml(filepath.Join(manifestsDir, config.chartName+"-operator.clusterserviceversion.yaml"), csv); err != nil {
+		return fmt.Errorf("failed to write the cluster service version. Error: %w", err)
+	}
+	if err := common.WriteYaml(filepath.Join(manifestsDir, config.group+"_"+config.plural+".yaml"), crd); err != nil {
+		return fmt.Errorf("failed to write the custom resource definition. Error: %w", err)
+	}
+	annotations := getOLMBundleAnnotations(config)
+	if err := common.WriteYaml(filepath.Join(metadataDir, "annotations.yaml"), map[string]interface{}{"annotations": annotations}); err != nil {
+		return fmt.Errorf("failed to write the annotations of the bundle. Error: %w", err)
+	}
+	keys := []string{}
+	for key := range annotations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	dockerfile := "FROM scratch\n\n"
+	for _, key := range keys {
+		dockerfile += fmt.Sprintf("LABEL %s=%s\n", key, annotations[key])
+	}
+	dockerfile += fmt.Sprintf("\nCOPY %s/%s /%s/\nCOPY %s/%s /%s/\n", olmBundleDir, olmBundleManifestsDir, olmBundleManifestsDir, olmBundleDir, olmBundleMetadataDir, olmBundleMetadataDir)
+	if err := os.WriteFile(filepath.Join(operatorDir, olmBundleDockerfile), []byte(dockerfile), common.DefaultFilePermission); err != nil {
+		return fmt.Errorf("failed to write the Dockerfile of the bundle. Error: %w", err)
+	}
+	return nil
+}
+
+// getClusterServiceVersion returns the cluster service version that installs the operator with the deployment and the permissions of the manager
+func getClusterServiceVersion(config helmOperatorConfig, role, leaderElectionRole, deployment map[string]interface{}, samples []map[string]interface{}) (map[string]interface{}, error) {
+	examples, err := json.Marshal(samples)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode the sample custom resources as json. Error: %w", err)
+	}
+	name := config.chartName + "-operator"
+	displayName := config.kind + " Operator"
+	serviceAccountName := config.chartName + "-operator-controller-manager"
+	deploymentMetadata, _ := deployment["metadata"].(map[string]interface{})
+	return map[string]interface{}{
+		"apiVersion": "operators.coreos.com/v1alpha1",
+		"kind":       "ClusterServiceVersion",
+		"metadata": map[string]interface{}{
+			"name": name + ".v" + olmBundleVersion,
+			"annotations": map[string]interface{}{
+				"alm-examples":                           string(examples),
+				"capabilities":                           "Basic Install",
+				"containerImage":                         config.image,
+				"operators.operatorframework.io/builder": "move2kube",
+			},
+		},
+		"spec": map[string]interface{}{
+			"displayName": displayName,
+			"description": "Installs the Helm chart " + config.chartName + " generated by Move2Kube",
+			"version":     olmBundleVersion,
+			"maturity":    olmBundleChannel,
+			"keywords":    []string{config.chartName, "move2kube"},
+			"provider":    map[string]interface{}{"name": config.domain},
+			"customresourcedefinitions": map[string]interface{}{
+				"owned": []map[string]interface{}{{
+					"name":        config.plural + "." + config.group,
+					"kind":        config.kind,
+					"version":     helmOperatorVersion,
+					"displayName": config.kind,
+					"description": "Deploys " + strings.ToLower(config.kind) + " with the values of the Helm chart in its spec",
+				}},
+			},
+			"install": map[string]interface{}{
+				"strategy": "deployment",
+				"spec": map[string]interface{}{
+					"clusterPermissions": []map[string]interface{}{{
+						"serviceAccountName": serviceAccountName,
+						"rules":              role["rules"],
+					}},
+					"permissions": []map[string]interface{}{{
+						"serviceAccountName": serviceAccountName,
+						"rules":              leaderElectionRole["rules"],
+					}},
+					"deployments": []map[string]interface{}{{
+						"name":  deploymentMetadata["name"],
+						"label": deploymentMetadata["labels"],
+						"spec":  deployment["spec"],
+					}},
+				},
+			},
+			"installModes": []map[string]interface{}{
+				{"type": "OwnNamespace", "supported": true},
+				{"type": "SingleNamespace", "supported": true},
+				{"type": "MultiNamespace", "supported": false},
+				{"type": "AllNamespaces", "supported": true},
+			},
+		},
+	}, nil
+}
@@ -21,6 +21,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/konveyor/move2kube/common"
@@ -31,6 +32,7 @@ import (
 	qatypes "github.com/konveyor/move2kube/types/qaengine"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cast"
+	"gopkg.in/yaml.v3"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -45,6 +47,7 @@ var (
 	specialJSONPathChars         = regexp.MustCompile(`(~|\/)`)
 	templateInnerParametersRegex = regexp.MustCompile(`\$\([^)]+\)`)
 	invalidHelmChartNameChars    = regexp.MustCompile("[^a-zA-Z0-9-]+")
+	invalidEnvironmentNameChars  = regexp.MustCompile("[^a-zA-Z0-9_.-]+")
 )
 
 // Parameterize does the parameterization based on a spec
@@ -63,7 +66,7 @@ func Parameterize(srcDir, outDir string, packSpecConfig ParameterizerConfigT, ps
 	shouldGenerateDefaultEnv := false
 	normEnvs := []string{}
 	for _, env := range packSpecConfig.Envs {
-		normEnv := common.NormalizeForMetadataName(env)
+		normEnv := NormalizeEnvironmentName(env)
 		if normEnv == "" {
 			logrus.Debugf("got an invalid environment name in the parameterizer. Will generate a default environment instead. Env name: %s . ParameterizerConfig: %+v", env, packSpecConfig)
 			shouldGenerateDefaultEnv = true
@@ -80,6 +83,8 @@ func Parameterize(srcDir, outDir string, packSpecConfig ParameterizerConfigT, ps
 	if err != nil {
 		return filesWritten, err
 	}
+	pathedKs = externalizeSecrets(pathedKs, packSpecConfig.Secrets)
+	sortedKPaths := getSortedKPaths(pathedKs)
 	if packSpecConfig.Helm != "" {
 		// helm chart with multiple values.yaml
 		helmChartName := normalizeForHelmChartName(packSpecConfig.ProjectName)
@@ -90,8 +95,8 @@ func Parameterize(srcDir, outDir string, packSpecConfig ParameterizerConfigT, ps
 		if err := os.MkdirAll(helmTemplatesDir, common.DefaultDirectoryPermission); err != nil {
 			logrus.Errorf("Unable to create directory for helm : %s", err)
 		} else {
-			for kPath, ks := range pathedKs {
-				for _, k := range ks {
+			for _, kPath := range sortedKPaths {
+				for _, k := range pathedKs[kPath] {
 					k = deepcopy.DeepCopy(k).(k8sschema.K8sResourceT)
 					if err := parameterize(TargetHelm, packSpecConfig.Envs, k, ps, namedValues, nil, nil); err != nil {
 						logrus.Errorf("Unable to parameterize for helm : %s", err)
@@ -140,8 +145,8 @@ func Parameterize(srcDir, outDir string, packSpecConfig ParameterizerConfigT, ps
 		} else {
 			kustPatches := map[string]map[PatchMetadataT][]PatchT{}
 			kPaths := []string{}
-			for kPath, ks := range pathedKs {
-				for _, k := range ks {
+			for _, kPath := range sortedKPaths {
+				for _, k := range pathedKs[kPath] {
 					// base
 					finalKPath := filepath.Join(baseDir, kPath)
 					if err := writeResourceAppendToFile(k, finalKPath); err != nil {
@@ -173,8 +178,13 @@ func Parameterize(srcDir, outDir string, packSpecConfig ParameterizerConfigT, ps
 						if _, ok := kustPatches[env]; !ok {
 							kustPatches[env] = map[PatchMetadataT][]PatchT{}
 						}
-						for _, v := range patches {
-							kustPatches[env][patchMetadata] = append(kustPatches[env][patchMetadata], v)
+						patchPaths := []string{}
+						for patchPath := range patches {
+							patchPaths = append(patchPaths, patchPath)
+						}
+						sort.Strings(patchPaths)
+						for _, patchPath := range patchPaths {
+							kustPatches[env][patchMetadata] = append(kustPatches[env][patchMetadata], patches[patchPath])
 						}
 					}
 					kPaths = append(kPaths, kPath)
@@ -204,6 +214,7 @@ func Parameterize(srcDir, outDir string, packSpecConfig ParameterizerConfigT, ps
 					metas = append(metas, kMeta)
 					filesWritten = append(filesWritten, finalKPath)
 				}
+				sort.SliceStable(metas, func(i, j int) bool { return metas[i].Path < metas[j].Path })
 				kustomization := map[string]interface{}{"resources": []string{"../../base"}, "patches": metas}
 				finalKPath := filepath.Join(envDir, "kustomization.yaml")
 				if err := common.WriteYaml(finalKPath, kustomization); err != nil {
@@ -218,8 +229,8 @@ func Parameterize(srcDir, outDir string, packSpecConfig ParameterizerConfigT, ps
 		// openshift templates for each env
 		newKs := []k8sschema.K8sResourceT{}
 		ocParams := map[string]map[string]string{}
-		for _, ks := range pathedKs {
-			for _, k := range ks {
+		for _, kPath := range sortedKPaths {
+			for _, k := range pathedKs[kPath] {
 				k = deepcopy.DeepCopy(k).(k8sschema.K8sResourceT)
 				if err := parameterize(TargetOCTemplates, packSpecConfig.Envs, k, ps, nil, nil, ocParams); err != nil {
 					logrus.Errorf("Unable to parameterize for OC Templates : %s", err)
@@ -230,12 +241,19 @@ func Parameterize(srcDir, outDir string, packSpecConfig ParameterizerConfigT, ps
 		}
 		singleSet := []OCParamT{}
 		if len(ocParams) > 0 {
-			for _, kvs := range ocParams {
-				for k, v := range kvs {
-					singleSet = append(singleSet, OCParamT{Name: k, Value: v})
-				}
-				break
+			envs := []string{}
+			for env := range ocParams {
+				envs = append(envs, env)
+			}
+			sort.Strings(envs)
+			kvs := ocParams[envs[0]]
+			if defaultKvs, ok := ocParams[parameterizerDefaultEnvironment]; ok {
+				kvs = defaultKvs
+			}
+			for k, v := range kvs {
+				singleSet = append(singleSet, OCParamT{Name: k, Value: v})
 			}
+			sort.SliceStable(singleSet, func(i, j int) bool { return singleSet[i].Name < singleSet[j].Name })
 		}
 		templ := map[string]interface{}{
 			"apiVersion": "template.openshift.io/v1",
@@ -264,6 +282,7 @@ func Parameterize(srcDir, outDir string, packSpecConfig ParameterizerConfigT, ps
 				for k, v := range params {
 					finalParams = append(finalParams, fmt.Sprintf("%s=%s", k, v))
 				}
+				sort.Strings(finalParams)
 				if err := os.WriteFile(finalKPath, []byte(strings.Join(finalParams, "\n")), common.DefaultFilePermission); err != nil {
 					logrus.Errorf("Unable to write to %s : %s", finalKPath, err)
 					continue
@@ -278,6 +297,16 @@ func Parameterize(srcDir, outDir string, packSpecConfig ParameterizerConfigT, ps
 // ------------------------------
 // Utilities
 
+// getSortedKPaths returns the paths of the k8s resources in sorted order so that the outputs are deterministic
+func getSortedKPaths(pathedKs map[string][]k8sschema.K8sResourceT) []string {
+	kPaths := []string{}
+	for kPath := range pathedKs {
+		kPaths = append(kPaths, kPath)
+	}
+	sort.Strings(kPaths)
+	return kPaths
+}
+
 func getGVKNFromK(k k8sschema.K8sResourceT) (group string, version string, kind string, metadataName string, err error) {
 	var apiVersion string
 	kind, apiVersion, metadataName, err = k8sschema.GetInfoFromK8sResource(k)
@@ -376,6 +405,9 @@ func splitOnIdxs(s string, idxs []int) []ParamOrStringT {
 		isParam = !isParam
 		ss = append(ss, currs)
 	}
+	if prevIdx < len(s) {
+		ss = append(ss, ParamOrStringT{IsParam: isParam, Data: s[prevIdx:]})
+	}
 	return ss
 }
 
@@ -655,7 +687,7 @@ func parameterizeHelperHelm(
 					param := p.Parameters[0]
 					for _, pV := range param.Values {
 						if doesMatchEnv(pV, env, kind, apiVersion, metadataName, resultKV.Matches) {
-							paramValue = pV.Value
+							paramValue = getTypedValue(pV.Value, origParamValue)
 							break
 						}
 					}
@@ -850,9 +882,22 @@ func parameterizeHelperKustomize(envs []string, k k8sschema.K8sResourceT, p Para
 			}
 			p.Question.Desc = origQuesDesc
 		}
+		var originalValues []string
+		var paramsAndStrings []ParamOrStringT
+		if parameters, _ := getParameters(p.Template); len(parameters) > 1 {
+			// fill the template for each environment when the original value can be split into the parameters
+			if defaultStr, ok := paramValue.(string); ok {
+				if originalValues, paramsAndStrings, err = parseTemplate(p.Template, defaultStr, p.Regex); err != nil {
+					logrus.Debugf("failed to parse the multi parameter template %s for kustomize. Error: %q", p.Template, err)
+					originalValues = nil
+				}
+			}
+		}
 		for _, env := range envs {
 			origParamValue := paramValue
-			if len(p.Parameters) > 0 {
+			if len(originalValues) > 0 {
+				paramValue = fillTemplateForEnv(paramsAndStrings, originalValues, p.Parameters, env, kind, apiVersion, metadataName, resultKV.Matches)
+			} else if len(p.Parameters) > 0 {
 				if len(p.Parameters) > 1 {
 					logrus.Debugf("more than one parameter specified for kustomize parameterization, ignoring all of them. Actual length: %d Parameters: %+v", len(p.Parameters), p.Parameters)
 				} else {
@@ -860,7 +905,7 @@ func parameterizeHelperKustomize(envs []string, k k8sschema.K8sResourceT, p Para
 					// no need to check the parameter name since for kustomize there should be at most one parameter
 					for _, pV := range param.Values {
 						if doesMatchEnv(pV, env, kind, apiVersion, metadataName, resultKV.Matches) {
-							paramValue = pV.Value
+							paramValue = getTypedValue(pV.Value, origParamValue)
 							break
 						}
 					}
@@ -1141,6 +1186,56 @@ Actual value is %+v of type %T`,
 	return nil
 }
 
+// fillTemplateForEnv fills the template using the values of the parameters for the given environment.
+// Parameters that do not have a value for the environment keep their original value.
+func fillTemplateForEnv(paramsAndStrings []ParamOrStringT, originalValues []string, params []ParameterT, env, kind, apiVersion, metadataName string, matches map[string]string) string {
+	filled := ""
+	paramIdx := 0
+	for _, pOrS := range paramsAndStrings {
+		if !pOrS.IsParam {
+			filled += pOrS.Data
+			continue
+		}
+		value := originalValues[paramIdx]
+		paramIdx++
+		for _, param := range params {
+			if "${"+param.Name+"}" != pOrS.Data {
+				continue
+			}
+			if param.Default != "" {
+				value = param.Default
+			}
+			for _, pV := range param.Values {
+				if doesMatchEnv(pV, env, kind, apiVersion, metadataName, matches) {
+					value = pV.Value
+					break
+				}
+			}
+			break
+		}
+		filled += value
+	}
+	return filled
+}
+
+// getTypedValue parses the value as yaml when the value it replaces is not a string, so that numbers and booleans keep their type
+func getTypedValue(value string, original interface{}) interface{} {
+	if _, ok := original.(string); ok || original == nil {
+		return value
+	}
+	var typed interface{}
+	if err := yaml.Unmarshal([]byte(value), &typed); err != nil || typed == nil {
+		return value
+	}
+	return typed
+}
+
+// NormalizeEnvironmentName makes the environment name safe to use in the names of the values files and the overlay directories.
+// Unlike the metadata names, it keeps the case of the name, so that the files are named after the environments given by the user.
+func NormalizeEnvironmentName(env string) string {
+	return strings.Trim(invalidEnvironmentNameChars.ReplaceAllLiteralString(strings.TrimSpace(env), "-"), "-.")
+}
+
 func normalizeForHelmChartName(name string) string {
 	if len(name) == 0 {
 		logrus.Error("The input helm chart name is empty.")
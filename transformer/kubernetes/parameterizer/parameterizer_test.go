@@ -83,3 +83,52 @@ func TestGettingAndParameterizingResources(t *testing.T) {
 		}
 	}
 }
+
+func TestParameterizeIsDeterministic(t *testing.T) {
+	baseDir, err := filepath.Abs("testdata")
+	if err != nil {
+		t.Fatalf("Failed to make the base directory absolute path. Error: %q", err)
+	}
+	ps, err := parameterizer.CollectParamsFromPath(filepath.Join(baseDir, "parameterizers"))
+	if err != nil {
+		t.Fatalf("Unable to collect parameterizers. Error: %q", err)
+	}
+	psl := []parameterizer.ParameterizerT{}
+	for _, p := range ps {
+		psl = append(psl, p...)
+	}
+	psp := parameterizer.ParameterizerConfigT{
+		Helm:        "helm-chart",
+		Kustomize:   "kustomize",
+		OCTemplates: "openshift-templates",
+		ProjectName: "myproject",
+		Envs:        []string{"dev", "staging", "prod"},
+	}
+	var want map[string]string
+	for i := 0; i < 5; i++ {
+		outputPath := t.TempDir()
+		filesWritten, err := parameterizer.Parameterize(filepath.Join(baseDir, "k8s-resources"), outputPath, psp, psl)
+		if err != nil {
+			t.Fatalf("Failed to apply all the parameterizations. Error: %q", err)
+		}
+		actual := map[string]string{}
+		for _, fileWritten := range filesWritten {
+			relFilePath, err := filepath.Rel(outputPath, fileWritten)
+			if err != nil {
+				t.Fatalf("failed to make the file path %s relative to the output path %s . Error: %q", fileWritten, outputPath, err)
+			}
+			actualBytes, err := os.ReadFile(fileWritten)
+			if err != nil {
+				t.Fatalf("Failed to read the output data at path %s . Error: %q", fileWritten, err)
+			}
+			actual[relFilePath] = string(actualBytes)
+		}
+		if want == nil {
+			want = actual
+			continue
+		}
+		if !cmp.Equal(actual, want) {
+			t.Fatalf("The outputs are different across runs. Differences:\n%s", cmp.Diff(want, actual))
+		}
+	}
+}
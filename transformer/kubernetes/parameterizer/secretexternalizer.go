@@ -0,0 +1,496 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package parameterizer
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/common/report"
+	"github.com/konveyor/move2kube/transformer/kubernetes/k8sschema"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cast"
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	secretKind                    = "Secret"
+	ingressKind                   = "Ingress"
+	externalSecretAPIVersion      = "external-secrets.io/v1beta1"
+	externalSecretKind            = "ExternalSecret"
+	secretProviderClassAPIVersion = "secrets-store.csi.x-k8s.io/v1"
+	secretProviderClassKind       = "SecretProviderClass"
+	secretsStoreCSIDriver         = "secrets-store.csi.k8s.io"
+	vaultAgentAnnotationPrefix    = "vault.hashicorp.com/"
+)
+
+// GetSecretNames returns the names of the secrets in the k8s yamls in a directory
+func GetSecretNames(srcDir string) ([]string, error) {
+	pathedKs, err := k8sschema.GetK8sResourcesWithPaths(srcDir, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the k8s resources from the directory '%s' . Error: %w", srcDir, err)
+	}
+	secretNames := []string{}
+	for _, ks := range pathedKs {
+		for _, k := range ks {
+			kind, _, metadataName, err := k8sschema.GetInfoFromK8sResource(k)
+			if err != nil || kind != secretKind {
+				continue
+			}
+			secretNames = common.AppendIfNotPresent(secretNames, metadataName)
+		}
+	}
+	sort.Strings(secretNames)
+	return secretNames, nil
+}
+
+// externalizeSecrets strips the data of the secrets and replaces them and their references with the chosen mechanism
+func externalizeSecrets(pathedKs map[string][]k8sschema.K8sResourceT, secrets []SecretExternalizationT) map[string][]k8sschema.K8sResourceT {
+	externalizations := map[string]SecretExternalizationT{}
+	for _, secret := range secrets {
+		if secret.Mechanism == "" || secret.Mechanism == NoSecretExternalization {
+			continue
+		}
+		externalizations[secret.Name] = secret
+	}
+	if len(externalizations) == 0 {
+		return pathedKs
+	}
+	replacer := secretReferenceReplacer{externalizations: externalizations, secretKeys: map[string][]string{}}
+	for _, ks := range pathedKs {
+		for _, k := range ks {
+			if kind, _, metadataName, err := k8sschema.GetInfoFromK8sResource(k); err == nil && kind == secretKind {
+				replacer.secretKeys[metadataName] = getSecretKeys(k)
+			}
+		}
+	}
+	ingressCSISecrets := []string{}
+	newPathedKs := map[string][]k8sschema.K8sResourceT{}
+	for kPath, ks := range pathedKs {
+		newKs := []k8sschema.K8sResourceT{}
+		for _, k := range ks {
+			kind, _, metadataName, err := k8sschema.GetInfoFromK8sResource(k)
+			if err != nil {
+				newKs = append(newKs, k)
+				continue
+			}
+			if kind == secretKind {
+				externalization, ok := externalizations[metadataName]
+				if !ok {
+					newKs = append(newKs, k)
+					continue
+				}
+				switch externalization.Mechanism {
+				case ExternalSecretsOperatorExternalization:
+					newKs = append(newKs, getExternalSecret(k, externalization))
+				case CSISecretsStoreExternalization:
+					newKs = append(newKs, getSecretProviderClass(k, externalization))
+				case VaultAgentExternalization:
+				default:
+					logrus.Errorf("unsupported secret externalization mechanism '%s' for the secret '%s'", externalization.Mechanism, metadataName)
+					newKs = append(newKs, k)
+				}
+				continue
+			}
+			if kind == ingressKind {
+				ingressCSISecrets = common.MergeSlices(ingressCSISecrets, replacer.replaceIngressSecretReferences(metadataName, k))
+			}
+			podMetadata, podSpec, ok := getPodMetadataAndSpec(k)
+			if ok {
+				replacer.replaceSecretReferences(metadataName, podMetadata, podSpec)
+			}
+			newKs = append(newKs, k)
+		}
+		if len(newKs) == 0 {
+			logrus.Debugf("all the resources in the file '%s' were externalized secrets", filepath.Base(kPath))
+			continue
+		}
+		newPathedKs[kPath] = newKs
+	}
+	for _, secretName := range ingressCSISecrets {
+		if !common.IsPresent(replacer.csiMountedSecrets, secretName) {
+			logrus.Warnf("The secret '%s' used by the ingresses is only synced by the CSI driver while a pod mounts it, and no pod mounts it", secretName)
+			report.AddFollowUp("", fmt.Sprintf("Mount the SecretProviderClass %s in a pod so that the secret used by the ingresses is synced", secretName))
+		}
+	}
+	return newPathedKs
+}
+
+// getSecretKeys returns the sorted keys in the data and stringData of a secret
+func getSecretKeys(secret k8sschema.K8sResourceT) []string {
+	keys := []string{}
+	for _, dataKey := range []string{"data", "stringData"} {
+		data, ok := secret[dataKey].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for key := range data {
+			keys = common.AppendIfNotPresent(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// getExternalSecret returns an ExternalSecret that creates the secret using the data from a secret store
+func getExternalSecret(secret k8sschema.K8sResourceT, externalization SecretExternalizationT) k8sschema.K8sResourceT {
+	data := []interface{}{}
+	for _, key := range getSecretKeys(secret) {
+		data = append(data, map[string]interface{}{
+			"secretKey": key,
+			"remoteRef": map[string]interface{}{"key": externalization.Path, "property": key},
+		})
+	}
+	target := map[string]interface{}{"name": externalization.Name, "creationPolicy": "Owner"}
+	if secretType := cast.ToString(secret["type"]); secretType != "" && secretType != "Opaque" {
+		target["template"] = map[string]interface{}{"type": secretType}
+	}
+	return k8sschema.K8sResourceT{
+		"apiVersion": externalSecretAPIVersion,
+		"kind":       externalSecretKind,
+		"metadata":   getExternalizedMetadata(secret),
+		"spec": map[string]interface{}{
+			"refreshInterval": "1h",
+			"secretStoreRef":  map[string]interface{}{"name": externalization.Store, "kind": "SecretStore"},
+			"target":          target,
+			"data":            data,
+		},
+	}
+}
+
+// getSecretProviderClass returns a SecretProviderClass that mounts the data from a provider and syncs it to the secret
+func getSecretProviderClass(secret k8sschema.K8sResourceT, externalization SecretExternalizationT) k8sschema.K8sResourceT {
+	objects := []interface{}{}
+	secretObjectData := []interface{}{}
+	for _, key := range getSecretKeys(secret) {
+		objects = append(objects, map[string]interface{}{"objectName": key, "secretPath": externalization.Path, "secretKey": key})
+		secretObjectData = append(secretObjectData, map[string]interface{}{"objectName": key, "key": key})
+	}
+	objectsYaml, err := yaml.Marshal(objects)
+	if err != nil {
+		logrus.Errorf("failed to marshal the objects of the secret '%s' to yaml. Error: %q", externalization.Name, err)
+	}
+	secretType := cast.ToString(secret["type"])
+	if secretType == "" {
+		secretType = "Opaque"
+	}
+	return k8sschema.K8sResourceT{
+		"apiVersion": secretProviderClassAPIVersion,
+		"kind":       secretProviderClassKind,
+		"metadata":   getExternalizedMetadata(secret),
+		"spec": map[string]interface{}{
+			"provider":   externalization.Store,
+			"parameters": map[string]interface{}{"objects": string(objectsYaml)},
+			"secretObjects": []interface{}{map[string]interface{}{
+				"secretName": externalization.Name,
+				"type":       secretType,
+				"data":       secretObjectData,
+			}},
+		},
+	}
+}
+
+// getExternalizedMetadata returns the metadata of a secret without any fields that are specific to the secret
+func getExternalizedMetadata(secret k8sschema.K8sResourceT) map[string]interface{} {
+	newMetadata := map[string]interface{}{}
+	metadata, ok := secret["metadata"].(map[string]interface{})
+	if !ok {
+		return newMetadata
+	}
+	for _, key := range []string{"name", "namespace", "labels", "annotations"} {
+		if value, ok := metadata[key]; ok {
+			newMetadata[key] = value
+		}
+	}
+	return newMetadata
+}
+
+// getPodMetadataAndSpec returns the pod metadata and spec of pods and the resources with pod templates
+func getPodMetadataAndSpec(k k8sschema.K8sResourceT) (map[string]interface{}, map[string]interface{}, bool) {
+	kind := cast.ToString(k["kind"])
+	podFields := []string{"spec", "template"}
+	switch kind {
+	case "Pod":
+		podFields = nil
+	case "CronJob":
+		podFields = []string{"spec", "jobTemplate", "spec", "template"}
+	}
+	podMap := k
+	if len(podFields) > 0 {
+		pod, ok, _ := unstructured.NestedFieldNoCopy(k, podFields...)
+		if !ok {
+			return nil, nil, false
+		}
+		if podMap, ok = pod.(map[string]interface{}); !ok {
+			return nil, nil, false
+		}
+	}
+	podSpec, ok := podMap["spec"].(map[string]interface{})
+	if !ok {
+		return nil, nil, false
+	}
+	podMetadata, ok := podMap["metadata"].(map[string]interface{})
+	if !ok {
+		podMetadata = map[string]interface{}{}
+		podMap["metadata"] = podMetadata
+	}
+	return podMetadata, podSpec, true
+}
+
+// secretReferenceReplacer replaces the references to the externalized secrets
+type secretReferenceReplacer struct {
+	externalizations map[string]SecretExternalizationT
+	// secretKeys are the keys of the secrets
+	secretKeys map[string][]string
+	// csiMountedSecrets are the secrets mounted using the CSI driver, which syncs them to the cluster while they are mounted
+	csiMountedSecrets []string
+}
+
+// replaceIngressSecretReferences removes the TLS secrets of an ingress which are provided by the vault agent.
+// It returns the TLS secrets provided by the CSI driver.
+func (r *secretReferenceReplacer) replaceIngressSecretReferences(resourceName string, ingress k8sschema.K8sResourceT) []string {
+	csiSecrets := []string{}
+	tlses, _, _ := unstructured.NestedSlice(ingress, "spec", "tls")
+	for i, tls := range tlses {
+		tlsMap, ok := tls.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		secretName := cast.ToString(tlsMap["secretName"])
+		externalization, ok := r.externalizations[secretName]
+		if !ok {
+			continue
+		}
+		switch externalization.Mechanism {
+		case CSISecretsStoreExternalization:
+			csiSecrets = common.AppendIfNotPresent(csiSecrets, secretName)
+		case VaultAgentExternalization:
+			delete(tlsMap, "secretName")
+			tlses[i] = tlsMap
+			logrus.Warnf("removed the TLS secret '%s' of the ingress '%s' since the secrets provided by the vault agent are only available inside the pods", secretName, resourceName)
+			report.AddFollowUp("", fmt.Sprintf("Create the TLS secret %s used by the ingress %s", secretName, resourceName))
+		}
+	}
+	if len(tlses) > 0 {
+		if err := unstructured.SetNestedSlice(ingress, tlses, "spec", "tls"); err != nil {
+			logrus.Errorf("failed to set the TLS of the ingress '%s' . Error: %q", resourceName, err)
+		}
+	}
+	return csiSecrets
+}
+
+// replaceSecretReferences replaces the references to the externalized secrets in a pod spec.
+// The secrets provided by the CSI driver are mounted in the pods using them, since the driver only syncs the mounted secrets to the cluster.
+func (r *secretReferenceReplacer) replaceSecretReferences(resourceName string, podMetadata, podSpec map[string]interface{}) {
+	// vaultVolumes are the names of the removed volumes of the secrets provided by the vault agent, and the names of their secrets
+	vaultVolumes := map[string]string{}
+	mountedCSISecrets := []string{}
+	volumes, _ := podSpec["volumes"].([]interface{})
+	newVolumes := []interface{}{}
+	for _, volume := range volumes {
+		volumeMap, ok := volume.(map[string]interface{})
+		if !ok {
+			newVolumes = append(newVolumes, volume)
+			continue
+		}
+		secretName, _, _ := unstructured.NestedString(volumeMap, "secret", "secretName")
+		externalization, ok := r.externalizations[secretName]
+		if !ok {
+			newVolumes = append(newVolumes, volume)
+			continue
+		}
+		switch externalization.Mechanism {
+		case CSISecretsStoreExternalization:
+			delete(volumeMap, "secret")
+			volumeMap["csi"] = getCSIVolumeSource(externalization)
+			newVolumes = append(newVolumes, volumeMap)
+			mountedCSISecrets = common.AppendIfNotPresent(mountedCSISecrets, secretName)
+		case VaultAgentExternalization:
+			vaultVolumes[cast.ToString(volumeMap["name"])] = secretName
+		default:
+			newVolumes = append(newVolumes, volume)
+		}
+	}
+	referencedCSISecrets := []string{}
+	containers := []map[string]interface{}{}
+	for _, containersKey := range []string{"containers", "initContainers"} {
+		containerList, _ := podSpec[containersKey].([]interface{})
+		for _, container := range containerList {
+			containerMap, ok := container.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			containers = append(containers, containerMap)
+			referencedCSISecrets = common.MergeSlices(referencedCSISecrets, r.replaceSecretReferencesInContainer(resourceName, podMetadata, containerMap, vaultVolumes))
+		}
+	}
+	if imagePullSecrets, ok := podSpec["imagePullSecrets"].([]interface{}); ok {
+		newImagePullSecrets := []interface{}{}
+		for _, imagePullSecret := range imagePullSecrets {
+			secretName := cast.ToString(cast.ToStringMap(imagePullSecret)["name"])
+			externalization, ok := r.externalizations[secretName]
+			if ok && externalization.Mechanism == VaultAgentExternalization {
+				logrus.Warnf("removed the image pull secret '%s' of '%s' since the secrets provided by the vault agent are only available inside the pods", secretName, resourceName)
+				report.AddFollowUp("", fmt.Sprintf("Create the image pull secret %s used by %s", secretName, resourceName))
+				continue
+			}
+			if ok && externalization.Mechanism == CSISecretsStoreExternalization {
+				referencedCSISecrets = common.AppendIfNotPresent(referencedCSISecrets, secretName)
+			}
+			newImagePullSecrets = append(newImagePullSecrets, imagePullSecret)
+		}
+		podSpec["imagePullSecrets"] = newImagePullSecrets
+	}
+	sort.Strings(referencedCSISecrets)
+	for _, secretName := range referencedCSISecrets {
+		if common.IsPresent(mountedCSISecrets, secretName) || len(containers) == 0 {
+			continue
+		}
+		volumeName := common.MakeStringDNSLabelNameCompliant(secretName + "-secrets-store")
+		newVolumes = append(newVolumes, map[string]interface{}{"name": volumeName, "csi": getCSIVolumeSource(r.externalizations[secretName])})
+		volumeMounts, _ := containers[0]["volumeMounts"].([]interface{})
+		containers[0]["volumeMounts"] = append(volumeMounts, map[string]interface{}{
+			"name":      volumeName,
+			"mountPath": "/mnt/secrets-store/" + secretName,
+			"readOnly":  true,
+		})
+		mountedCSISecrets = append(mountedCSISecrets, secretName)
+	}
+	if len(volumes) > 0 || len(newVolumes) > 0 {
+		podSpec["volumes"] = newVolumes
+	}
+	r.csiMountedSecrets = common.MergeSlices(r.csiMountedSecrets, mountedCSISecrets)
+}
+
+// replaceSecretReferencesInContainer replaces the references to the secrets provided by the vault agent in a container.
+// The vault agent renders each key of a mounted secret to a file at the original mount path.
+// It returns the secrets provided by the CSI driver which the environment variables of the container use.
+func (r *secretReferenceReplacer) replaceSecretReferencesInContainer(resourceName string, podMetadata, container map[string]interface{}, vaultVolumes map[string]string) []string {
+	csiSecrets := []string{}
+	if volumeMounts, ok := container["volumeMounts"].([]interface{}); ok && len(vaultVolumes) > 0 {
+		newVolumeMounts := []interface{}{}
+		for _, volumeMount := range volumeMounts {
+			volumeMountMap, ok := volumeMount.(map[string]interface{})
+			if !ok {
+				newVolumeMounts = append(newVolumeMounts, volumeMount)
+				continue
+			}
+			secretName, ok := vaultVolumes[cast.ToString(volumeMountMap["name"])]
+			if !ok {
+				newVolumeMounts = append(newVolumeMounts, volumeMount)
+				continue
+			}
+			addVaultAgentFileAnnotations(podMetadata, r.externalizations[secretName], r.secretKeys[secretName], cast.ToString(volumeMountMap["mountPath"]))
+		}
+		container["volumeMounts"] = newVolumeMounts
+	}
+	if envs, ok := container["env"].([]interface{}); ok {
+		newEnvs := []interface{}{}
+		for _, env := range envs {
+			envMap, ok := env.(map[string]interface{})
+			if !ok {
+				newEnvs = append(newEnvs, env)
+				continue
+			}
+			secretName, _, _ := unstructured.NestedString(envMap, "valueFrom", "secretKeyRef", "name")
+			externalization, ok := r.externalizations[secretName]
+			if ok && externalization.Mechanism == CSISecretsStoreExternalization {
+				csiSecrets = common.AppendIfNotPresent(csiSecrets, secretName)
+			}
+			if !ok || externalization.Mechanism != VaultAgentExternalization {
+				newEnvs = append(newEnvs, env)
+				continue
+			}
+			addVaultAgentAnnotations(podMetadata, externalization)
+			logrus.Warnf("removed the environment variable '%s' in '%s' since the secret '%s' is provided by the vault agent at /vault/secrets/%s",
+				envMap["name"], resourceName, externalization.Name, externalization.Name)
+		}
+		container["env"] = newEnvs
+	}
+	if envFroms, ok := container["envFrom"].([]interface{}); ok {
+		newEnvFroms := []interface{}{}
+		for _, envFrom := range envFroms {
+			envFromMap, ok := envFrom.(map[string]interface{})
+			if !ok {
+				newEnvFroms = append(newEnvFroms, envFrom)
+				continue
+			}
+			secretName, _, _ := unstructured.NestedString(envFromMap, "secretRef", "name")
+			externalization, ok := r.externalizations[secretName]
+			if ok && externalization.Mechanism == CSISecretsStoreExternalization {
+				csiSecrets = common.AppendIfNotPresent(csiSecrets, secretName)
+			}
+			if !ok || externalization.Mechanism != VaultAgentExternalization {
+				newEnvFroms = append(newEnvFroms, envFrom)
+				continue
+			}
+			addVaultAgentAnnotations(podMetadata, externalization)
+			logrus.Warnf("removed the environment variables from the secret '%s' in '%s' since it is provided by the vault agent at /vault/secrets/%s",
+				externalization.Name, resourceName, externalization.Name)
+		}
+		container["envFrom"] = newEnvFroms
+	}
+	return csiSecrets
+}
+
+// getCSIVolumeSource returns the CSI volume source mounting the secret provided by the CSI driver
+func getCSIVolumeSource(externalization SecretExternalizationT) map[string]interface{} {
+	return map[string]interface{}{
+		"driver":           secretsStoreCSIDriver,
+		"readOnly":         true,
+		"volumeAttributes": map[string]interface{}{"secretProviderClass": externalization.Name},
+	}
+}
+
+// addVaultAgentFileAnnotations adds the annotations that make the vault agent injector render each key of the secret
+// to a file named after the key in the directory the secret was mounted at
+func addVaultAgentFileAnnotations(podMetadata map[string]interface{}, externalization SecretExternalizationT, keys []string, mountPath string) {
+	annotations := getVaultAgentAnnotations(podMetadata, externalization)
+	// the data of the secrets in the version 2 of the KV secrets engine is nested under data
+	dataField := ".Data"
+	if strings.Contains(externalization.Path, "/data/") {
+		dataField = ".Data.data"
+	}
+	for _, key := range keys {
+		name := externalization.Name + "-" + key
+		annotations[vaultAgentAnnotationPrefix+"agent-inject-secret-"+name] = externalization.Path
+		annotations[vaultAgentAnnotationPrefix+"agent-inject-file-"+name] = key
+		annotations[vaultAgentAnnotationPrefix+"agent-inject-template-"+name] = fmt.Sprintf(`{{- with secret "%s" -}}{{ index %s "%s" }}{{- end }}`, externalization.Path, dataField, key)
+		annotations[vaultAgentAnnotationPrefix+"secret-volume-path-"+name] = mountPath
+	}
+}
+
+// addVaultAgentAnnotations adds the annotations that make the vault agent injector render the secret in the pod
+func addVaultAgentAnnotations(podMetadata map[string]interface{}, externalization SecretExternalizationT) {
+	annotations := getVaultAgentAnnotations(podMetadata, externalization)
+	annotations[vaultAgentAnnotationPrefix+"agent-inject-secret-"+externalization.Name] = externalization.Path
+}
+
+// getVaultAgentAnnotations returns the annotations of the pod after adding the annotations that enable the vault agent injector
+func getVaultAgentAnnotations(podMetadata map[string]interface{}, externalization SecretExternalizationT) map[string]interface{} {
+	annotations, ok := podMetadata["annotations"].(map[string]interface{})
+	if !ok {
+		annotations = map[string]interface{}{}
+		podMetadata["annotations"] = annotations
+	}
+	annotations[vaultAgentAnnotationPrefix+"agent-inject"] = "true"
+	annotations[vaultAgentAnnotationPrefix+"role"] = externalization.Store
+	return annotations
+}
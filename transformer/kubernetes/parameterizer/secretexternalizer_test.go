@@ -0,0 +1,300 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package parameterizer_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/konveyor/move2kube/transformer/kubernetes/k8sschema"
+	"github.com/konveyor/move2kube/transformer/kubernetes/parameterizer"
+)
+
+const (
+	testSecretYaml = `apiVersion: v1
+kind: Secret
+metadata:
+  name: db
+data:
+  password: cGFzc3dvcmQ=
+  username: dXNlcg==
+`
+	testDeploymentYaml = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      containers:
+        - name: web
+          image: web:latest
+          env:
+            - name: DB_PASSWORD
+              valueFrom:
+                secretKeyRef:
+                  name: db
+                  key: password
+            - name: PORT
+              value: "8080"
+          volumeMounts:
+            - name: db-volume
+              mountPath: /etc/db
+      volumes:
+        - name: db-volume
+          secret:
+            secretName: db
+`
+	testEnvDeploymentYaml = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      imagePullSecrets:
+        - name: db
+      containers:
+        - name: web
+          image: web:latest
+          envFrom:
+            - secretRef:
+                name: db
+`
+	testIngressYaml = `apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: web
+spec:
+  tls:
+    - hosts:
+        - web.example.com
+      secretName: db
+  rules:
+    - host: web.example.com
+      http:
+        paths:
+          - path: /
+            pathType: Prefix
+            backend:
+              service:
+                name: web
+                port:
+                  number: 8080
+`
+)
+
+func getExternalizedResources(t *testing.T, secret parameterizer.SecretExternalizationT, deploymentYaml string) map[string]k8sschema.K8sResourceT {
+	t.Helper()
+	srcDir := t.TempDir()
+	outDir := t.TempDir()
+	for fileName, yaml := range map[string]string{"db-secret.yaml": testSecretYaml, "web-deployment.yaml": deploymentYaml, "web-ingress.yaml": testIngressYaml} {
+		if err := os.WriteFile(filepath.Join(srcDir, fileName), []byte(yaml), 0644); err != nil {
+			t.Fatalf("failed to write the yaml %s . Error: %q", fileName, err)
+		}
+	}
+	secretNames, err := parameterizer.GetSecretNames(srcDir)
+	if err != nil {
+		t.Fatalf("failed to get the secret names. Error: %q", err)
+	}
+	if len(secretNames) != 1 || secretNames[0] != "db" {
+		t.Fatalf("failed to get the secret names. Expected: [db] Actual: %+v", secretNames)
+	}
+	config := parameterizer.ParameterizerConfigT{Kustomize: "kustomize", ProjectName: "test", Secrets: []parameterizer.SecretExternalizationT{secret}}
+	if _, err := parameterizer.Parameterize(srcDir, outDir, config, nil); err != nil {
+		t.Fatalf("failed to parameterize. Error: %q", err)
+	}
+	pathedKs, err := k8sschema.GetK8sResourcesWithPaths(filepath.Join(outDir, "kustomize", "base"), false)
+	if err != nil {
+		t.Fatalf("failed to read the kustomize base. Error: %q", err)
+	}
+	resources := map[string]k8sschema.K8sResourceT{}
+	for _, ks := range pathedKs {
+		for _, k := range ks {
+			kind, _, _, err := k8sschema.GetInfoFromK8sResource(k)
+			if err != nil {
+				continue
+			}
+			resources[kind] = k
+		}
+	}
+	return resources
+}
+
+func getPodSpec(t *testing.T, resources map[string]k8sschema.K8sResourceT) map[string]interface{} {
+	t.Helper()
+	deployment, ok := resources["Deployment"]
+	if !ok {
+		t.Fatalf("expected the deployment to be kept. Actual: %+v", resources)
+	}
+	return deployment["spec"].(map[string]interface{})["template"].(map[string]interface{})["spec"].(map[string]interface{})
+}
+
+func getIngressTLS(t *testing.T, resources map[string]k8sschema.K8sResourceT) map[string]interface{} {
+	t.Helper()
+	ingress, ok := resources["Ingress"]
+	if !ok {
+		t.Fatalf("expected the ingress to be kept. Actual: %+v", resources)
+	}
+	return ingress["spec"].(map[string]interface{})["tls"].([]interface{})[0].(map[string]interface{})
+}
+
+func TestExternalizeSecrets(t *testing.T) {
+	t.Run("external secrets operator", func(t *testing.T) {
+		resources := getExternalizedResources(t, parameterizer.SecretExternalizationT{
+			Name: "db", Mechanism: parameterizer.ExternalSecretsOperatorExternalization, Store: "my-store", Path: "prod/db",
+		}, testEnvDeploymentYaml)
+		if _, ok := resources["Secret"]; ok {
+			t.Fatalf("expected the secret to be removed")
+		}
+		externalSecret, ok := resources["ExternalSecret"]
+		if !ok {
+			t.Fatalf("expected an ExternalSecret to be generated. Actual: %+v", resources)
+		}
+		spec := externalSecret["spec"].(map[string]interface{})
+		if spec["secretStoreRef"].(map[string]interface{})["name"] != "my-store" {
+			t.Fatalf("expected the secret store to be my-store. Actual: %+v", spec["secretStoreRef"])
+		}
+		data := spec["data"].([]interface{})
+		if len(data) != 2 || data[0].(map[string]interface{})["secretKey"] != "password" {
+			t.Fatalf("expected the data to have the keys of the secret. Actual: %+v", data)
+		}
+		podSpec := getPodSpec(t, resources)
+		if imagePullSecrets := podSpec["imagePullSecrets"].([]interface{}); len(imagePullSecrets) != 1 {
+			t.Fatalf("expected the image pull secret to be kept. Actual: %+v", imagePullSecrets)
+		}
+		if _, ok := podSpec["volumes"]; ok {
+			t.Fatalf("expected no volume to be added. Actual: %+v", podSpec["volumes"])
+		}
+		if tls := getIngressTLS(t, resources); tls["secretName"] != "db" {
+			t.Fatalf("expected the ingress TLS secret to be kept. Actual: %+v", tls)
+		}
+	})
+	t.Run("csi secrets store", func(t *testing.T) {
+		resources := getExternalizedResources(t, parameterizer.SecretExternalizationT{
+			Name: "db", Mechanism: parameterizer.CSISecretsStoreExternalization, Store: "vault", Path: "secret/data/db",
+		}, testDeploymentYaml)
+		spc, ok := resources["SecretProviderClass"]
+		if !ok {
+			t.Fatalf("expected a SecretProviderClass to be generated. Actual: %+v", resources)
+		}
+		objects := spc["spec"].(map[string]interface{})["parameters"].(map[string]interface{})["objects"].(string)
+		if !strings.Contains(objects, "secretPath: secret/data/db") {
+			t.Fatalf("expected the objects to use the path of the secret. Actual: %s", objects)
+		}
+		volumes := resources["Deployment"]["spec"].(map[string]interface{})["template"].(map[string]interface{})["spec"].(map[string]interface{})["volumes"].([]interface{})
+		volume := volumes[0].(map[string]interface{})
+		if _, ok := volume["secret"]; ok {
+			t.Fatalf("expected the secret volume to be replaced. Actual: %+v", volume)
+		}
+		if volume["csi"].(map[string]interface{})["driver"] != "secrets-store.csi.k8s.io" {
+			t.Fatalf("expected a csi volume. Actual: %+v", volume)
+		}
+		if len(volumes) != 1 {
+			t.Fatalf("expected no volume to be added since the secret is already mounted. Actual: %+v", volumes)
+		}
+		container := getPodSpec(t, resources)["containers"].([]interface{})[0].(map[string]interface{})
+		if envs := container["env"].([]interface{}); len(envs) != 2 {
+			t.Fatalf("expected the envs to be kept since the driver syncs the secret. Actual: %+v", envs)
+		}
+		if tls := getIngressTLS(t, resources); tls["secretName"] != "db" {
+			t.Fatalf("expected the ingress TLS secret to be kept. Actual: %+v", tls)
+		}
+	})
+	t.Run("csi secrets store without a secret volume", func(t *testing.T) {
+		resources := getExternalizedResources(t, parameterizer.SecretExternalizationT{
+			Name: "db", Mechanism: parameterizer.CSISecretsStoreExternalization, Store: "vault", Path: "secret/data/db",
+		}, testEnvDeploymentYaml)
+		podSpec := getPodSpec(t, resources)
+		if imagePullSecrets := podSpec["imagePullSecrets"].([]interface{}); len(imagePullSecrets) != 1 {
+			t.Fatalf("expected the image pull secret to be kept. Actual: %+v", imagePullSecrets)
+		}
+		volumes := podSpec["volumes"].([]interface{})
+		if len(volumes) != 1 {
+			t.Fatalf("expected a csi volume to be added so that the driver syncs the secret. Actual: %+v", volumes)
+		}
+		volume := volumes[0].(map[string]interface{})
+		if volume["name"] != "db-secrets-store" || volume["csi"].(map[string]interface{})["volumeAttributes"].(map[string]interface{})["secretProviderClass"] != "db" {
+			t.Fatalf("expected a csi volume using the SecretProviderClass. Actual: %+v", volume)
+		}
+		container := podSpec["containers"].([]interface{})[0].(map[string]interface{})
+		if envFroms := container["envFrom"].([]interface{}); len(envFroms) != 1 {
+			t.Fatalf("expected the envFrom to be kept. Actual: %+v", envFroms)
+		}
+		volumeMounts := container["volumeMounts"].([]interface{})
+		if len(volumeMounts) != 1 || volumeMounts[0].(map[string]interface{})["mountPath"] != "/mnt/secrets-store/db" {
+			t.Fatalf("expected the csi volume to be mounted. Actual: %+v", volumeMounts)
+		}
+	})
+	t.Run("vault agent", func(t *testing.T) {
+		resources := getExternalizedResources(t, parameterizer.SecretExternalizationT{
+			Name: "db", Mechanism: parameterizer.VaultAgentExternalization, Store: "web", Path: "secret/data/db",
+		}, testDeploymentYaml)
+		if _, ok := resources["Secret"]; ok {
+			t.Fatalf("expected the secret to be removed")
+		}
+		template := resources["Deployment"]["spec"].(map[string]interface{})["template"].(map[string]interface{})
+		annotations := template["metadata"].(map[string]interface{})["annotations"].(map[string]interface{})
+		if annotations["vault.hashicorp.com/agent-inject"] != "true" || annotations["vault.hashicorp.com/role"] != "web" ||
+			annotations["vault.hashicorp.com/agent-inject-secret-db"] != "secret/data/db" {
+			t.Fatalf("expected the vault agent annotations. Actual: %+v", annotations)
+		}
+		podSpec := template["spec"].(map[string]interface{})
+		if volumes := podSpec["volumes"].([]interface{}); len(volumes) != 0 {
+			t.Fatalf("expected the secret volume to be removed. Actual: %+v", volumes)
+		}
+		container := podSpec["containers"].([]interface{})[0].(map[string]interface{})
+		if envs := container["env"].([]interface{}); len(envs) != 1 {
+			t.Fatalf("expected only the env not using the secret to be kept. Actual: %+v", envs)
+		}
+		if volumeMounts := container["volumeMounts"].([]interface{}); len(volumeMounts) != 0 {
+			t.Fatalf("expected the secret volume mount to be removed. Actual: %+v", volumeMounts)
+		}
+		for _, key := range []string{"password", "username"} {
+			if annotations["vault.hashicorp.com/secret-volume-path-db-"+key] != "/etc/db" || annotations["vault.hashicorp.com/agent-inject-file-db-"+key] != key {
+				t.Fatalf("expected the key %s to be rendered at the mount path of the secret. Actual: %+v", key, annotations)
+			}
+			expectedTemplate := `{{- with secret "secret/data/db" -}}{{ index .Data.data "` + key + `" }}{{- end }}`
+			if annotations["vault.hashicorp.com/agent-inject-template-db-"+key] != expectedTemplate {
+				t.Fatalf("expected the template of the key %s to be %s . Actual: %+v", key, expectedTemplate, annotations)
+			}
+		}
+		if tls := getIngressTLS(t, resources); tls["secretName"] != nil {
+			t.Fatalf("expected the ingress TLS secret to be removed. Actual: %+v", tls)
+		}
+	})
+	t.Run("vault agent without a secret volume", func(t *testing.T) {
+		resources := getExternalizedResources(t, parameterizer.SecretExternalizationT{
+			Name: "db", Mechanism: parameterizer.VaultAgentExternalization, Store: "web", Path: "secret/db",
+		}, testEnvDeploymentYaml)
+		podSpec := getPodSpec(t, resources)
+		if imagePullSecrets, _ := podSpec["imagePullSecrets"].([]interface{}); len(imagePullSecrets) != 0 {
+			t.Fatalf("expected the image pull secret to be removed. Actual: %+v", imagePullSecrets)
+		}
+		container := podSpec["containers"].([]interface{})[0].(map[string]interface{})
+		if envFroms, _ := container["envFrom"].([]interface{}); len(envFroms) != 0 {
+			t.Fatalf("expected the envFrom to be removed. Actual: %+v", envFroms)
+		}
+	})
+}
@@ -17,6 +17,8 @@
 package parameterizer
 
 import (
+	"reflect"
+
 	"github.com/konveyor/move2kube/common"
 	"github.com/konveyor/move2kube/types/qaengine"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -33,11 +35,26 @@ type HelmValuesT map[string]interface{}
 
 // ParameterizerConfigT is the set of paths to be parameterized
 type ParameterizerConfigT struct {
-	ProjectName string   `yaml:"projectName,omitempty" json:"projectName,omitempty"`
-	Helm        string   `yaml:"helm,omitempty" json:"helm,omitempty"`
-	Kustomize   string   `yaml:"kustomize,omitempty" json:"kustomize,omitempty"`
-	OCTemplates string   `yaml:"openshiftTemplates,omitempty" json:"openshiftTemplates,omitempty"`
-	Envs        []string `yaml:"envs,omitempty" json:"envs,omitempty"`
+	ProjectName string                   `yaml:"projectName,omitempty" json:"projectName,omitempty"`
+	Helm        string                   `yaml:"helm,omitempty" json:"helm,omitempty"`
+	Kustomize   string                   `yaml:"kustomize,omitempty" json:"kustomize,omitempty"`
+	OCTemplates string                   `yaml:"openshiftTemplates,omitempty" json:"openshiftTemplates,omitempty"`
+	Envs        []string                 `yaml:"envs,omitempty" json:"envs,omitempty"`
+	Secrets     []SecretExternalizationT `yaml:"secrets,omitempty" json:"secrets,omitempty"`
+}
+
+// SecretExternalizationMechanismT is the mechanism used to provide the data of a secret from outside the cluster
+type SecretExternalizationMechanismT string
+
+// SecretExternalizationT specifies how the data of a generated secret should be provided
+type SecretExternalizationT struct {
+	// Name is the name of the secret
+	Name      string                          `yaml:"name" json:"name"`
+	Mechanism SecretExternalizationMechanismT `yaml:"mechanism" json:"mechanism"`
+	// Store is the secret store for ESO, the provider for the CSI driver and the role for the vault agent
+	Store string `yaml:"store,omitempty" json:"store,omitempty"`
+	// Path is the path of the secret in the external secret manager
+	Path string `yaml:"path,omitempty" json:"path,omitempty"`
 }
 
 // ParameterizerFileT is the file format for the parameterizers
@@ -64,6 +81,16 @@ type ParameterizerT struct {
 	Parameters                 []ParameterT      `yaml:"parameters,omitempty" json:"parameters,omitempty"`
 }
 
+// Compare is used while merging artifacts so that only identical parameterizers get merged
+func (ParameterizerT) Compare(x, y interface{}) bool {
+	return reflect.DeepEqual(x, y)
+}
+
+// Merge returns the other parameterizer, since only identical parameterizers get merged
+func (ParameterizerT) Merge(y interface{}) interface{} {
+	return y
+}
+
 // FilterT is used to choose the k8s resources that the parameterizer should be applied on
 type FilterT struct {
 	Kind       string   `yaml:"kind,omitempty" json:"kind,omitempty"`
@@ -142,6 +169,14 @@ const (
 	TargetKustomize ParamTargetT = "kustomize"
 	// TargetOCTemplates is used when the target is the parameterization of Openshift Templates
 	TargetOCTemplates ParamTargetT = "openshifttemplates"
+	// NoSecretExternalization keeps the data of the secret in the generated artifacts
+	NoSecretExternalization SecretExternalizationMechanismT = "none"
+	// ExternalSecretsOperatorExternalization replaces the secret with an ExternalSecret of the External Secrets Operator
+	ExternalSecretsOperatorExternalization SecretExternalizationMechanismT = "externalsecrets"
+	// CSISecretsStoreExternalization replaces the secret with a SecretProviderClass of the Secrets Store CSI driver
+	CSISecretsStoreExternalization SecretExternalizationMechanismT = "csi"
+	// VaultAgentExternalization replaces the secret with the annotations of the Vault agent injector
+	VaultAgentExternalization SecretExternalizationMechanismT = "vault"
 	// ParamQuesIDPrefix is used as a prefix when the key is not specified in the questions in a parameterizer
 	ParamQuesIDPrefix = common.BaseKey + common.Delim + "parameterization"
 )
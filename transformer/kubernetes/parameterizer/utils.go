@@ -21,6 +21,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/konveyor/move2kube/common"
@@ -358,3 +359,57 @@ func CollectParamsFromPath(parameterizersDir string) (map[string][]Parameterizer
 	}
 	return params, nil
 }
+
+// GetGlobalParameters returns the parameters in the templates of the parameterizers that do not depend on the k8s resource being parameterized
+func GetGlobalParameters(ps []ParameterizerT) []string {
+	globalParameters := []string{}
+	for _, p := range ps {
+		if p.Template == "" {
+			continue
+		}
+		parameters, err := getParameters(p.Template)
+		if err != nil {
+			logrus.Debugf("failed to get the parameters from the template '%s' . Error: %q", p.Template, err)
+			continue
+		}
+		for _, parameter := range parameters {
+			if !strings.Contains(parameter, "$(") {
+				globalParameters = common.AppendIfNotPresent(globalParameters, parameter)
+			}
+		}
+	}
+	sort.Strings(globalParameters)
+	return globalParameters
+}
+
+// AddEnvironmentValues returns a copy of the parameterizers where the given values for each parameter take precedence in their environments
+func AddEnvironmentValues(ps []ParameterizerT, envValues map[string][]ParameterValueT) []ParameterizerT {
+	newPs := []ParameterizerT{}
+	for _, p := range ps {
+		parameters := []string{}
+		if p.Template != "" {
+			parameters, _ = getParameters(p.Template)
+		}
+		newParameters := append([]ParameterT{}, p.Parameters...)
+		for _, parameter := range parameters {
+			values, ok := envValues[parameter]
+			if !ok {
+				continue
+			}
+			found := false
+			for i, newParameter := range newParameters {
+				if newParameter.Name == parameter {
+					newParameters[i].Values = append(append([]ParameterValueT{}, values...), newParameter.Values...)
+					found = true
+					break
+				}
+			}
+			if !found {
+				newParameters = append(newParameters, ParameterT{Name: parameter, Values: values})
+			}
+		}
+		p.Parameters = newParameters
+		newPs = append(newPs, p)
+	}
+	return newPs
+}
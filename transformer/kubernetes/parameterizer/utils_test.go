@@ -21,6 +21,7 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common/deepcopy"
 	"github.com/konveyor/move2kube/transformer/kubernetes/parameterizer"
 )
 
@@ -93,3 +94,43 @@ func TestGet2(t *testing.T) {
 		t.Fatalf("differences %+v", cmp.Diff(results, want))
 	}
 }
+
+func TestAddEnvironmentValues(t *testing.T) {
+	ps := []parameterizer.ParameterizerT{
+		{Target: "spec.replicas", Template: "${common.replicas}", Parameters: []parameterizer.ParameterT{
+			{Name: "common.replicas", Default: "2", Values: []parameterizer.ParameterValueT{{Envs: []string{"dev"}, Value: "1"}}},
+		}},
+		{Target: "spec.template.spec.containers.[containerName:name].image", Template: "${imageregistry.url}/$(containerName):latest"},
+	}
+	if globalParameters := parameterizer.GetGlobalParameters(ps); !cmp.Equal(globalParameters, []string{"common.replicas", "imageregistry.url"}) {
+		t.Fatalf("failed to get the global parameters. Actual %+v", globalParameters)
+	}
+	envValues := map[string][]parameterizer.ParameterValueT{
+		"common.replicas":   {{Envs: []string{"prod"}, Value: "5"}},
+		"imageregistry.url": {{Envs: []string{"prod"}, Value: "quay.io"}},
+	}
+	want := []parameterizer.ParameterizerT{
+		{Target: "spec.replicas", Template: "${common.replicas}", Parameters: []parameterizer.ParameterT{
+			{Name: "common.replicas", Default: "2", Values: []parameterizer.ParameterValueT{{Envs: []string{"prod"}, Value: "5"}, {Envs: []string{"dev"}, Value: "1"}}},
+		}},
+		{Target: "spec.template.spec.containers.[containerName:name].image", Template: "${imageregistry.url}/$(containerName):latest", Parameters: []parameterizer.ParameterT{
+			{Name: "imageregistry.url", Values: []parameterizer.ParameterValueT{{Envs: []string{"prod"}, Value: "quay.io"}}},
+		}},
+	}
+	if actual := parameterizer.AddEnvironmentValues(ps, envValues); !cmp.Equal(actual, want) {
+		t.Fatalf("differences %+v", cmp.Diff(actual, want))
+	}
+	if len(ps[0].Parameters[0].Values) != 1 || ps[1].Parameters != nil {
+		t.Fatalf("the original parameterizers were modified. Actual %+v", ps)
+	}
+}
+
+func TestMergeParameterizers(t *testing.T) {
+	web := parameterizer.ParameterizerT{Target: "spec.replicas", Template: "${web.replicas}", Filters: []parameterizer.FilterT{{Kind: "Deployment", Name: "web"}}}
+	db := parameterizer.ParameterizerT{Target: "spec.replicas", Template: "${db.replicas}", Filters: []parameterizer.FilterT{{Kind: "Deployment", Name: "db"}}}
+	merged := deepcopy.Merge([]parameterizer.ParameterizerT{web}, []parameterizer.ParameterizerT{web, db})
+	want := []parameterizer.ParameterizerT{web, db}
+	if !cmp.Equal(merged, want) {
+		t.Fatalf("expected only the identical parameterizers to be merged. Difference:\n%s", cmp.Diff(want, merged))
+	}
+}
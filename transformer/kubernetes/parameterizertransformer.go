@@ -20,20 +20,28 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
 
 	"github.com/konveyor/move2kube/common"
 	"github.com/konveyor/move2kube/environment"
+	"github.com/konveyor/move2kube/qaengine"
 	"github.com/konveyor/move2kube/transformer/kubernetes/k8sschema"
 	"github.com/konveyor/move2kube/transformer/kubernetes/parameterizer"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/konveyor/move2kube/types/qaengine/commonqa"
 	transformertypes "github.com/konveyor/move2kube/types/transformer"
 	"github.com/konveyor/move2kube/types/transformer/artifacts"
 	"github.com/sirupsen/logrus"
+	core "k8s.io/kubernetes/pkg/apis/core"
 )
 
 const (
-	helmPathTemplateName       = "HelmPath"
-	kustomizePathTemplateName  = "KustomizePath"
-	ocTemplatePathTemplateName = "OCTemplatePath"
+	helmPathTemplateName         = "HelmPath"
+	kustomizePathTemplateName    = "KustomizePath"
+	ocTemplatePathTemplateName   = "OCTemplatePath"
+	helmOperatorPathTemplateName = "HelmOperatorPath"
 )
 
 const (
@@ -41,6 +49,16 @@ const (
 	ExtraParameterizersConfigType transformertypes.ConfigType = "ExtraParameterizers"
 )
 
+const (
+	imageRegistryURLParameter       = "imageregistry.url"
+	imageRegistryNamespaceParameter = "imageregistry.namespace"
+	imageTagParameter               = "imageregistry.tag"
+	resourcePresetsParameter        = "resourcepresets"
+	// podTemplateKinds are the kinds whose pod spec is at spec.template.spec
+	podTemplateKinds = "Deployment|DeploymentConfig|ReplicationController|DaemonSet|StatefulSet|Job|Rollout"
+	podKind          = "Pod"
+)
+
 // Parameterizer implements Transformer interface
 type Parameterizer struct {
 	Config              transformertypes.Transformer
@@ -51,11 +69,12 @@ type Parameterizer struct {
 
 // ParameterizerYamlConfig implements Parameterizer path config interface
 type ParameterizerYamlConfig struct {
-	HelmPath       string   `yaml:"helmPath" json:"helmPath"`
-	OCTemplatePath string   `yaml:"ocTemplatePath" json:"ocTemplatePath"`
-	KustomizePath  string   `yaml:"kustomizePath" json:"kustomizePath"`
-	ProjectName    string   `yaml:"projectName" json:"projectName"`
-	Envs           []string `yaml:"envs,omitempty" json:"envs,omitempty"`
+	HelmPath         string   `yaml:"helmPath" json:"helmPath"`
+	OCTemplatePath   string   `yaml:"ocTemplatePath" json:"ocTemplatePath"`
+	KustomizePath    string   `yaml:"kustomizePath" json:"kustomizePath"`
+	HelmOperatorPath string   `yaml:"helmOperatorPath" json:"helmOperatorPath"`
+	ProjectName      string   `yaml:"projectName" json:"projectName"`
+	Envs             []string `yaml:"envs,omitempty" json:"envs,omitempty"`
 }
 
 // ParameterizerPathTemplateConfig stores the template config
@@ -155,10 +174,8 @@ func (paramTransformer *Parameterizer) Transform(
 			Kustomize:   "kustomize",
 			OCTemplates: "octemplates",
 			ProjectName: projectName,
-			Envs:        []string{},
-		}
-		if len(paramTransformer.ParameterizerConfig.Envs) > 0 {
-			pt.Envs = paramTransformer.ParameterizerConfig.Envs
+			Envs:        paramTransformer.getEnvironments(),
+			Secrets:     getSecretExternalizations(yamlsPath),
 		}
 		if len(paramTransformer.ParameterizerConfig.HelmPath) == 0 {
 			pt.Helm = ""
@@ -169,7 +186,9 @@ func (paramTransformer *Parameterizer) Transform(
 		if len(paramTransformer.ParameterizerConfig.OCTemplatePath) == 0 {
 			pt.OCTemplates = ""
 		}
-		filesWritten, err := parameterizer.Parameterize(yamlsPath, destPath, pt, append(paramTransformer.parameterizers, moreParams...))
+		params := append(append([]parameterizer.ParameterizerT{}, paramTransformer.parameterizers...), moreParams...)
+		params = parameterizer.AddEnvironmentValues(params, getEnvironmentValues(pt.Envs, params))
+		filesWritten, err := parameterizer.Parameterize(yamlsPath, destPath, pt, params)
 		if err != nil {
 			logrus.Errorf(
 				"failed to parameterize the YAML files in the source directory '%s' and write to the output directory '%s' . Error: %q",
@@ -223,6 +242,268 @@ func (paramTransformer *Parameterizer) Transform(
 				DestPath: fmt.Sprintf("{{ .%s }}", octKey),
 			})
 		}
+		if len(paramTransformer.ParameterizerConfig.HelmOperatorPath) != 0 && pt.Helm != "" && shouldGenerateHelmOperator(projectName, yamlsPath) {
+			helmOperatorKey := helmOperatorPathTemplateName + common.GetRandomString()
+			helmOperatorDestPath := filepath.Join(destPath, "helm-operator")
+			chartDirs, err := filepath.Glob(filepath.Join(destPath, pt.Helm, "*"))
+			if err != nil || len(chartDirs) == 0 {
+				logrus.Errorf("failed to find the helm chart in the directory '%s' . Error: %q", filepath.Join(destPath, pt.Helm), err)
+			} else if err := generateHelmOperator(projectName, yamlsPath, chartDirs[0], helmOperatorDestPath); err != nil {
+				logrus.Errorf("failed to generate the helm operator for the helm chart '%s' . Error: %q", chartDirs[0], err)
+			} else {
+				pathMappings = append(pathMappings, transformertypes.PathMapping{
+					Type:           transformertypes.PathTemplatePathMappingType,
+					SrcPath:        paramTransformer.ParameterizerConfig.HelmOperatorPath,
+					TemplateConfig: ParameterizerPathTemplateConfig{YamlsPath: yamlsPath, PathTemplateName: helmOperatorKey, ServiceFsPath: serviceFsPath},
+				})
+				pathMappings = append(pathMappings, transformertypes.PathMapping{
+					Type:     transformertypes.DefaultPathMappingType,
+					SrcPath:  helmOperatorDestPath,
+					DestPath: fmt.Sprintf("{{ .%s }}", helmOperatorKey),
+				})
+			}
+		}
 	}
 	return pathMappings, nil, nil
 }
+
+// getEnvironments returns the environments to generate the parameterized artifacts for.
+// The environments are only asked for when more than one environment is configured.
+func (paramTransformer *Parameterizer) getEnvironments() []string {
+	envs := getEnvironmentNames(paramTransformer.ParameterizerConfig.Envs)
+	if len(envs) < 2 {
+		return envs
+	}
+	answer := qaengine.FetchStringAnswer(
+		common.ConfigParameterizationEnvsKey,
+		"Enter a comma separated list of the environments to generate the helm values, kustomize overlays and openshift template parameters for : ",
+		[]string{"Ex : dev,staging,prod", "Leave it empty to generate a single set of default values."},
+		strings.Join(envs, ","),
+		nil,
+	)
+	return getEnvironmentNames(strings.Split(answer, ","))
+}
+
+// getEnvironmentNames normalizes the environment names, keeping their case since they are used in the file names
+func getEnvironmentNames(names []string) []string {
+	envs := []string{}
+	for _, name := range names {
+		if env := parameterizer.NormalizeEnvironmentName(name); env != "" {
+			envs = common.AppendIfNotPresent(envs, env)
+		}
+	}
+	return envs
+}
+
+// getEnvironmentValues asks which of the parameters should have a different value in each environment, along with those values
+func getEnvironmentValues(envs []string, ps []parameterizer.ParameterizerT) map[string][]parameterizer.ParameterValueT {
+	envValues := map[string][]parameterizer.ParameterValueT{}
+	if len(envs) < 2 {
+		return envValues
+	}
+	for _, parameter := range parameterizer.GetGlobalParameters(ps) {
+		quotedParameter := `"` + parameter + `"`
+		if !qaengine.FetchBoolAnswer(
+			common.JoinQASubKeys(common.ConfigParameterizationKey, quotedParameter, common.ConfigEnvSpecificForParameterKeySegment),
+			fmt.Sprintf("Should the parameter '%s' have a different value in each environment?", parameter),
+			[]string{"Environments: " + strings.Join(envs, ", ")},
+			false,
+			nil,
+		) {
+			continue
+		}
+		for _, env := range envs {
+			value := qaengine.FetchStringAnswer(
+				common.JoinQASubKeys(common.ConfigParameterizationKey, quotedParameter, common.ConfigValuesForParameterKeySegment, `"`+env+`"`),
+				fmt.Sprintf("[%s] Enter the value of the parameter '%s' : ", env, parameter),
+				[]string{"Leave it empty to use the generated value."},
+				"",
+				nil,
+			)
+			if value == "" {
+				continue
+			}
+			envValues[parameter] = append(envValues[parameter], parameterizer.ParameterValueT{Envs: []string{env}, Value: value})
+		}
+	}
+	return envValues
+}
+
+// getSecretExternalizations asks how the data of each of the generated secrets should be provided
+func getSecretExternalizations(yamlsPath string) []parameterizer.SecretExternalizationT {
+	secretNames, err := parameterizer.GetSecretNames(yamlsPath)
+	if err != nil {
+		logrus.Errorf("failed to get the secrets to externalize. Error: %q", err)
+		return nil
+	}
+	secrets := []parameterizer.SecretExternalizationT{}
+	for _, secretName := range secretNames {
+		quotedSecretName := `"` + secretName + `"`
+		mechanism := parameterizer.SecretExternalizationMechanismT(qaengine.FetchSelectAnswer(
+			common.JoinQASubKeys(common.ConfigParameterizationSecretsKey, quotedSecretName, common.ConfigMechanismForSecretKeySegment),
+			fmt.Sprintf("Select how the data of the secret '%s' should be provided in the parameterized artifacts :", secretName),
+			[]string{
+				string(parameterizer.ExternalSecretsOperatorExternalization) + " : an ExternalSecret of the External Secrets Operator creates the secret",
+				string(parameterizer.CSISecretsStoreExternalization) + " : the volumes are mounted using a SecretProviderClass of the Secrets Store CSI driver",
+				string(parameterizer.VaultAgentExternalization) + " : the vault agent injector renders the secret at /vault/secrets/" + secretName,
+				"Choose " + string(parameterizer.NoSecretExternalization) + " to keep the data of the secret in the artifacts.",
+			},
+			string(parameterizer.NoSecretExternalization),
+			[]string{
+				string(parameterizer.NoSecretExternalization),
+				string(parameterizer.ExternalSecretsOperatorExternalization),
+				string(parameterizer.CSISecretsStoreExternalization),
+				string(parameterizer.VaultAgentExternalization),
+			},
+			nil,
+		))
+		storeDesc, defaultStore, defaultPath := "", "", "secret/data/"+secretName
+		switch mechanism {
+		case parameterizer.ExternalSecretsOperatorExternalization:
+			storeDesc, defaultStore, defaultPath = "Enter the name of the SecretStore to fetch the secret '%s' from :", "secret-store", secretName
+		case parameterizer.CSISecretsStoreExternalization:
+			storeDesc, defaultStore = "Enter the Secrets Store CSI driver provider for the secret '%s' :", "vault"
+		case parameterizer.VaultAgentExternalization:
+			storeDesc, defaultStore = "Enter the vault role used by the services using the secret '%s' :", common.NormalizeForMetadataName(secretName)
+		default:
+			continue
+		}
+		store := qaengine.FetchStringAnswer(
+			common.JoinQASubKeys(common.ConfigParameterizationSecretsKey, quotedSecretName, common.ConfigStoreForSecretKeySegment),
+			fmt.Sprintf(storeDesc, secretName),
+			nil,
+			defaultStore,
+			nil,
+		)
+		path := qaengine.FetchStringAnswer(
+			common.JoinQASubKeys(common.ConfigParameterizationSecretsKey, quotedSecretName, common.ConfigPathForSecretKeySegment),
+			fmt.Sprintf("Enter the path of the secret '%s' in the external secret manager :", secretName),
+			nil,
+			defaultPath,
+			nil,
+		)
+		secrets = append(secrets, parameterizer.SecretExternalizationT{Name: secretName, Mechanism: mechanism, Store: store, Path: path})
+	}
+	return secrets
+}
+
+// getImageParameterizers returns parameterizers that rewrite the images built by move2kube using a single set of
+// values for the registry url, the registry namespace and, if all the images use the same tag, the tag.
+func getImageParameterizers(ir irtypes.IR) []parameterizer.ParameterizerT {
+	registryURL := commonqa.ImageRegistry()
+	registryNamespace := commonqa.ImageRegistryNamespace()
+	if registryURL == "" || registryNamespace == "" {
+		return nil
+	}
+	imageTag := commonqa.ImageTag()
+	newImageNames := []string{}
+	for imageName, containerImage := range ir.ContainerImages {
+		if containerImage.Build.ContainerBuildType == "" {
+			continue
+		}
+		name, _ := common.GetImageNameAndTag(imageName)
+		newImageNames = append(newImageNames, name)
+	}
+	if len(newImageNames) == 0 {
+		return nil
+	}
+	imagePrefix := registryURL + "/" + registryNamespace + "/"
+	serviceNames := ir.GetSortedServiceNames()
+	params := []parameterizer.ParameterizerT{}
+	for _, serviceName := range serviceNames {
+		service := ir.Services[serviceName]
+		for containersKey, containers := range map[string][]core.Container{"containers": service.Containers, "initContainers": service.InitContainers} {
+			for _, container := range containers {
+				name, tag := common.GetImageNameAndTag(container.Image)
+				if container.Image != imagePrefix+name+":"+tag || !common.IsPresent(newImageNames, name) {
+					continue
+				}
+				template := "${" + imageRegistryURLParameter + "}/${" + imageRegistryNamespaceParameter + "}/" + name + ":"
+				regex := `([^/]+)/(.+)/` + regexp.QuoteMeta(name+":")
+				if imageTag == "" {
+					template += tag
+					regex += regexp.QuoteMeta(tag)
+				} else {
+					template += "${" + imageTagParameter + "}"
+					regex += `([^/:]+)`
+				}
+				target := containersKey + ".[containerName:name=" + container.Name + "].image"
+				params = append(params, parameterizer.ParameterizerT{
+					Target:   "spec.template.spec." + target,
+					Template: template,
+					Regex:    regex,
+					Filters:  []parameterizer.FilterT{{Kind: podTemplateKinds, Name: regexp.QuoteMeta(service.Name)}},
+				}, parameterizer.ParameterizerT{
+					Target:   "spec." + target,
+					Template: template,
+					Regex:    regex,
+					Filters:  []parameterizer.FilterT{{Kind: podKind, Name: regexp.QuoteMeta(service.Name)}},
+				})
+			}
+		}
+	}
+	sort.SliceStable(params, func(i, j int) bool { return params[i].Target < params[j].Target })
+	return params
+}
+
+// getResourcePresetParameterizers returns parameterizers that replace the requests and limits of the services
+// using a resource preset with parameters shared by all the services using the same preset.
+func getResourcePresetParameterizers(ir irtypes.IR) []parameterizer.ParameterizerT {
+	serviceNames := ir.GetSortedServiceNames()
+	params := []parameterizer.ParameterizerT{}
+	for _, serviceName := range serviceNames {
+		service := ir.Services[serviceName]
+		if len(service.Containers) == 0 {
+			continue
+		}
+		preset := commonqa.ResourcePreset(serviceName)
+		if preset == "" {
+			continue
+		}
+		for _, container := range service.Containers {
+			for _, resourcesKey := range []string{"requests", "limits"} {
+				for _, resourceName := range []string{"cpu", "memory"} {
+					template := "${" + resourcePresetsParameter + "." + preset + "." + resourcesKey + "." + resourceName + "}"
+					target := "containers.[containerName:name=" + container.Name + "].resources." + resourcesKey + "." + resourceName
+					params = append(params, parameterizer.ParameterizerT{
+						Target:   "spec.template.spec." + target,
+						Template: template,
+						Filters:  []parameterizer.FilterT{{Kind: podTemplateKinds, Name: regexp.QuoteMeta(service.Name)}},
+					}, parameterizer.ParameterizerT{
+						Target:   "spec." + target,
+						Template: template,
+						Filters:  []parameterizer.FilterT{{Kind: podKind, Name: regexp.QuoteMeta(service.Name)}},
+					})
+				}
+			}
+		}
+	}
+	return params
+}
+
+// getPodAntiAffinityParameterizers returns parameterizers that expose the topology key of the generated pod anti-affinities
+func getPodAntiAffinityParameterizers(ir irtypes.IR) []parameterizer.ParameterizerT {
+	serviceNames := ir.GetSortedServiceNames()
+	params := []parameterizer.ParameterizerT{}
+	for _, serviceName := range serviceNames {
+		service := ir.Services[serviceName]
+		if service.Affinity == nil || service.Affinity.PodAntiAffinity == nil || len(service.Affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution) == 0 {
+			continue
+		}
+		if commonqa.PodAntiAffinity(serviceName) == "" {
+			continue
+		}
+		template := "${services." + service.Name + ".podantiaffinity.topologykey}"
+		target := "affinity.podAntiAffinity.preferredDuringSchedulingIgnoredDuringExecution.[0].podAffinityTerm.topologyKey"
+		params = append(params, parameterizer.ParameterizerT{
+			Target:   "spec.template.spec." + target,
+			Template: template,
+			Filters:  []parameterizer.FilterT{{Kind: podTemplateKinds, Name: regexp.QuoteMeta(service.Name)}},
+		}, parameterizer.ParameterizerT{
+			Target:   "spec." + target,
+			Template: template,
+			Filters:  []parameterizer.FilterT{{Kind: podKind, Name: regexp.QuoteMeta(service.Name)}},
+		})
+	}
+	return params
+}
@@ -0,0 +1,177 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/transformer/kubernetes/parameterizer"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestGetImageParameterizers(t *testing.T) {
+	common.IgnoreEnvironment = true
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	newImage := "quay.io/" + common.ProjectName + "/web:latest"
+	ir := irtypes.IR{
+		ContainerImages: map[string]irtypes.ContainerImage{
+			"web": {Build: irtypes.ContainerBuild{ContainerBuildType: irtypes.DockerfileContainerBuildType}},
+		},
+		Services: map[string]irtypes.Service{
+			"web": {
+				Name: "web",
+				PodSpec: irtypes.PodSpec{
+					Containers: []core.Container{{Name: "web", Image: newImage}, {Name: "cache", Image: "docker.io/library/redis:7"}},
+				},
+			},
+		},
+	}
+	params := getImageParameterizers(ir)
+	if len(params) != 2 {
+		t.Fatalf("expected a parameterizer for the pod template and one for the pod of the new image only. Actual: %+v", params)
+	}
+	for _, param := range params {
+		if !strings.Contains(param.Target, "[containerName:name=web]") {
+			t.Fatalf("expected only the container with the new image to be parameterized. Actual target: %s", param.Target)
+		}
+	}
+
+	srcDir := t.TempDir()
+	deployment := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+        - name: web
+          image: ` + newImage + `
+        - name: cache
+          image: docker.io/library/redis:7
+`
+	if err := os.WriteFile(filepath.Join(srcDir, "web-deployment.yaml"), []byte(deployment), 0644); err != nil {
+		t.Fatalf("failed to write the deployment. Error: %q", err)
+	}
+	outDir := t.TempDir()
+	if _, err := parameterizer.Parameterize(srcDir, outDir, parameterizer.ParameterizerConfigT{Helm: "helm", ProjectName: "web"}, params); err != nil {
+		t.Fatalf("failed to parameterize. Error: %q", err)
+	}
+	templateBytes, err := os.ReadFile(filepath.Join(outDir, "helm", "web", "templates", "web-deployment.yaml"))
+	if err != nil {
+		t.Fatalf("failed to read the helm template. Error: %q", err)
+	}
+	wantImage := `{{ index .Values "imageregistry" "url" }}/{{ index .Values "imageregistry" "namespace" }}/web:latest`
+	if !strings.Contains(string(templateBytes), wantImage) {
+		t.Fatalf("expected the helm template to contain the image %s . Actual:\n%s", wantImage, string(templateBytes))
+	}
+	if !strings.Contains(string(templateBytes), "image: docker.io/library/redis:7") {
+		t.Fatalf("expected the existing image to be left as is. Actual:\n%s", string(templateBytes))
+	}
+	valuesBytes, err := os.ReadFile(filepath.Join(outDir, "helm", "web", "values.yaml"))
+	if err != nil {
+		t.Fatalf("failed to read the helm values. Error: %q", err)
+	}
+	if !strings.Contains(string(valuesBytes), "url: quay.io") || !strings.Contains(string(valuesBytes), "namespace: "+common.ProjectName) {
+		t.Fatalf("expected the registry url and namespace in the helm values. Actual:\n%s", string(valuesBytes))
+	}
+}
+
+func TestGetResourcePresetParameterizers(t *testing.T) {
+	common.IgnoreEnvironment = true
+	qaengine.ResetEngines()
+	defer qaengine.ResetEngines()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", []string{`move2kube.services."web".resourcepreset="medium"`}, nil, nil, false)
+	ir := irtypes.IR{
+		Services: map[string]irtypes.Service{
+			"web": {Name: "web", PodSpec: irtypes.PodSpec{Containers: []core.Container{{Name: "web"}}}},
+			"db":  {Name: "db", PodSpec: irtypes.PodSpec{Containers: []core.Container{{Name: "db"}}}},
+		},
+	}
+	params := getResourcePresetParameterizers(ir)
+	if len(params) != 8 {
+		t.Fatalf("expected parameterizers for the cpu and memory requests and limits of the pod template and the pod of the web service only. Actual: %+v", params)
+	}
+
+	srcDir := t.TempDir()
+	deployment := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+        - name: web
+          image: web:latest
+          resources:
+            requests:
+              cpu: 250m
+              memory: 256Mi
+            limits:
+              cpu: 500m
+              memory: 512Mi
+`
+	if err := os.WriteFile(filepath.Join(srcDir, "web-deployment.yaml"), []byte(deployment), 0644); err != nil {
+		t.Fatalf("failed to write the deployment. Error: %q", err)
+	}
+	outDir := t.TempDir()
+	if _, err := parameterizer.Parameterize(srcDir, outDir, parameterizer.ParameterizerConfigT{Helm: "helm", ProjectName: "web"}, params); err != nil {
+		t.Fatalf("failed to parameterize. Error: %q", err)
+	}
+	templateBytes, err := os.ReadFile(filepath.Join(outDir, "helm", "web", "templates", "web-deployment.yaml"))
+	if err != nil {
+		t.Fatalf("failed to read the helm template. Error: %q", err)
+	}
+	if !strings.Contains(string(templateBytes), `{{ index .Values "resourcepresets" "medium" "limits" "memory" }}`) {
+		t.Fatalf("expected the resources to use the preset parameters. Actual:\n%s", templateBytes)
+	}
+	valuesBytes, err := os.ReadFile(filepath.Join(outDir, "helm", "web", "values.yaml"))
+	if err != nil {
+		t.Fatalf("failed to read the helm values. Error: %q", err)
+	}
+	if !strings.Contains(string(valuesBytes), "medium:") || !strings.Contains(string(valuesBytes), "memory: 512Mi") {
+		t.Fatalf("expected the preset values in the helm values. Actual:\n%s", valuesBytes)
+	}
+}
+
+func TestGetEnvironments(t *testing.T) {
+	qaengine.ResetEngines()
+	defer qaengine.ResetEngines()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", []string{`move2kube.parameterization.envs="Dev, Prod EU,Dev"`}, nil, nil, false)
+	paramTransformer := &Parameterizer{ParameterizerConfig: &ParameterizerYamlConfig{Envs: []string{"default"}}}
+	if envs := paramTransformer.getEnvironments(); !cmp.Equal(envs, []string{"default"}) {
+		t.Fatalf("expected the environments not to be asked for when only the default environment is configured. Actual: %+v", envs)
+	}
+	paramTransformer.ParameterizerConfig.Envs = nil
+	if envs := paramTransformer.getEnvironments(); len(envs) != 0 {
+		t.Fatalf("expected the environments not to be asked for when no environment is configured. Actual: %+v", envs)
+	}
+	paramTransformer.ParameterizerConfig.Envs = []string{"dev", "prod"}
+	if envs := paramTransformer.getEnvironments(); !cmp.Equal(envs, []string{"Dev", "Prod-EU"}) {
+		t.Fatalf("expected the environments to keep their case. Actual: %+v", envs)
+	}
+}
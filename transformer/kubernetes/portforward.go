@@ -0,0 +1,84 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+)
+
+const (
+	portForwardScriptFileName = "portforward"
+	portForwardScriptHeader   = `#!/usr/bin/env bash
+# Forwards random local ports to the ports of the services that are published on random host ports in the source.
+# kubectl prints the local port chosen for each of them.
+# Invoke as ./portforward.sh [namespace]
+
+NAMESPACE_ARGS=()
+if [ "$#" -gt 0 ]; then
+  NAMESPACE_ARGS=(--namespace "$1")
+fi
+`
+)
+
+// getPortForwardScript returns the script forwarding local ports to the service ports accessed using port forwarding.
+// It returns an empty string if there are no such ports.
+func getPortForwardScript(ir irtypes.IR) string {
+	serviceNames := ir.GetSortedServiceNames()
+	script := strings.Builder{}
+	for _, serviceName := range serviceNames {
+		service := ir.Services[serviceName]
+		ports := []string{}
+		for _, forwarding := range service.ServiceToPodPortForwardings {
+			if forwarding.PortForward {
+				ports = append(ports, fmt.Sprintf(":%d", forwarding.ServicePort.Number))
+			}
+		}
+		if len(ports) == 0 {
+			continue
+		}
+		script.WriteString(fmt.Sprintf("\necho 'forwarding the ports of the service %s'\n", service.Name))
+		script.WriteString(fmt.Sprintf("kubectl port-forward \"${NAMESPACE_ARGS[@]}\" service/%s %s &\n", service.Name, strings.Join(ports, " ")))
+	}
+	if script.Len() == 0 {
+		return ""
+	}
+	return portForwardScriptHeader + script.String() + "\nwait\n"
+}
+
+// writePortForwardScript writes the port-forward script to the temporary directory and returns the path mapping that copies it to the scripts directory
+func writePortForwardScript(tempPath string, script string) (transformertypes.PathMapping, error) {
+	scriptDir := filepath.Join(tempPath, "portforward-"+common.GetRandomString())
+	if err := os.MkdirAll(scriptDir, common.DefaultDirectoryPermission); err != nil {
+		return transformertypes.PathMapping{}, fmt.Errorf("failed to create the directory %s . Error: %w", scriptDir, err)
+	}
+	scriptPath := filepath.Join(scriptDir, portForwardScriptFileName+common.ShExt)
+	if err := os.WriteFile(scriptPath, []byte(script), common.DefaultExecutablePermission); err != nil {
+		return transformertypes.PathMapping{}, fmt.Errorf("failed to write the port-forward script to %s . Error: %w", scriptPath, err)
+	}
+	return transformertypes.PathMapping{
+		Type:     transformertypes.DefaultPathMappingType,
+		SrcPath:  scriptDir,
+		DestPath: common.ScriptsDir,
+	}, nil
+}
@@ -0,0 +1,43 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"strings"
+	"testing"
+
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"k8s.io/kubernetes/pkg/apis/networking"
+)
+
+func TestGetPortForwardScript(t *testing.T) {
+	ir := irtypes.NewIR()
+	web := irtypes.NewServiceWithName("web")
+	web.ServiceToPodPortForwardings = []irtypes.ServiceToPodPortForwarding{
+		{ServicePort: networking.ServiceBackendPort{Number: 8080}, PodPort: networking.ServiceBackendPort{Number: 8080}, PortForward: true},
+		{ServicePort: networking.ServiceBackendPort{Number: 80}, PodPort: networking.ServiceBackendPort{Number: 80}},
+		{ServicePort: networking.ServiceBackendPort{Number: 9090}, PodPort: networking.ServiceBackendPort{Number: 9090}, PortForward: true},
+	}
+	ir.Services["web"] = web
+	if script := getPortForwardScript(irtypes.NewIR()); script != "" {
+		t.Fatalf("expected no script without port forwarded ports. Actual:\n%s", script)
+	}
+	script := getPortForwardScript(ir)
+	if !strings.Contains(script, `kubectl port-forward "${NAMESPACE_ARGS[@]}" service/web :8080 :9090 &`) {
+		t.Fatalf("expected the ports of the service to be forwarded. Actual:\n%s", script)
+	}
+}
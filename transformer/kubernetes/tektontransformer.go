@@ -124,10 +124,9 @@ func (t *Tekton) Transform(newArtifacts []transformertypes.Artifact, alreadySeen
 		ir.Name = newArtifact.Name
 		preprocessedIR, err := irpreprocessor.Preprocess(ir, clusterConfig)
 		if err != nil {
-			logrus.Errorf("Unable to prepreocess IR : %s", err)
-		} else {
-			ir = preprocessedIR
+			return nil, nil, fmt.Errorf("failed to preprocess the IR of the artifact '%s' . Error: %w", newArtifact.Name, err)
 		}
+		ir = preprocessedIR
 		resources := []apiresource.IAPIResource{
 			new(apiresource.Service),
 			new(apiresource.ServiceAccount),
@@ -143,7 +142,7 @@ func (t *Tekton) Transform(newArtifacts []transformertypes.Artifact, alreadySeen
 		tempDest := filepath.Join(t.Env.TempPath, deployCICDDir)
 		logrus.Debugf("Generating Tekton pipeline for CI/CD")
 		enhancedIR := t.setupEnhancedIR(ir, t.Env.GetProjectName())
-		files, err := apiresource.TransformIRAndPersist(enhancedIR, tempDest, resources, clusterConfig, t.TektonConfig.SetDefaultValuesInYamls)
+		files, err := apiresource.TransformIRAndPersist(enhancedIR, tempDest, resources, clusterConfig, t.TektonConfig.SetDefaultValuesInYamls, t.Config)
 		if err != nil {
 			logrus.Errorf("Unable to transform and persist IR : %s", err)
 			return nil, nil, err
@@ -0,0 +1,242 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/sirupsen/logrus"
+)
+
+// getTransformerOrdering merges all the transformer ordering configs found in the directory.
+// It returns nil if there are no ordering configs.
+func getTransformerOrdering(dir string) (*transformertypes.TransformerOrdering, error) {
+	yamlPaths, err := common.GetFilesByExt(dir, []string{".yml", ".yaml"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to look for yaml files in the directory '%s' . Error: %w", dir, err)
+	}
+	sort.Strings(yamlPaths)
+	var ordering *transformertypes.TransformerOrdering
+	for _, yamlPath := range yamlPaths {
+		o := transformertypes.NewTransformerOrdering()
+		if err := common.ReadMove2KubeYaml(yamlPath, &o); err != nil || o.Kind != transformertypes.TransformerOrderingKind {
+			continue
+		}
+		logrus.Debugf("found the transformer ordering config at path '%s'", yamlPath)
+		if ordering == nil {
+			merged := transformertypes.NewTransformerOrdering()
+			ordering = &merged
+		}
+		ordering.Spec.Order = append(ordering.Spec.Order, o.Spec.Order...)
+		ordering.Spec.Disabled = append(ordering.Spec.Disabled, o.Spec.Disabled...)
+		for name, deps := range o.Spec.RunAfter {
+			ordering.Spec.RunAfter[name] = append(ordering.Spec.RunAfter[name], deps...)
+		}
+	}
+	return ordering, nil
+}
+
+// orderTransformers sorts the transformers into a valid execution order.
+// The explicit ordering from the config must not have cycles. A transformer that produces an artifact
+// type runs before transformers that consume it, unless that would contradict the explicit ordering.
+// Ties are broken using the original order of the transformers.
+func orderTransformers(ts []Transformer, ordering transformertypes.TransformerOrderingSpec) ([]Transformer, error) {
+	names := []string{}
+	indices := map[string]int{}
+	for i, t := range ts {
+		tc, _ := t.GetConfig()
+		names = append(names, tc.Name)
+		indices[tc.Name] = i
+	}
+	graph := make([][]bool, len(ts))
+	for i := range graph {
+		graph[i] = make([]bool, len(ts))
+	}
+	addEdge := func(before, after string) {
+		b, ok := indices[before]
+		if !ok {
+			logrus.Debugf("ignoring the ordering rule for '%s' since the transformer is not initialized", before)
+			return
+		}
+		a, ok := indices[after]
+		if !ok {
+			logrus.Debugf("ignoring the ordering rule for '%s' since the transformer is not initialized", after)
+			return
+		}
+		if a != b {
+			graph[b][a] = true
+		}
+	}
+	for i := 1; i < len(ordering.Order); i++ {
+		addEdge(ordering.Order[i-1], ordering.Order[i])
+	}
+	afterNames := []string{}
+	for name := range ordering.RunAfter {
+		afterNames = append(afterNames, name)
+	}
+	sort.Strings(afterNames)
+	for _, name := range afterNames {
+		for _, dep := range ordering.RunAfter[name] {
+			addEdge(dep, name)
+		}
+	}
+	if cycle := findCycle(graph); len(cycle) > 0 {
+		cycleNames := []string{}
+		for _, i := range cycle {
+			cycleNames = append(cycleNames, names[i])
+		}
+		return nil, fmt.Errorf("the transformer ordering has a cycle: %s", strings.Join(cycleNames, " -> "))
+	}
+	for b, producer := range ts {
+		ptc, _ := producer.GetConfig()
+		for a, consumer := range ts {
+			if a == b || graph[b][a] {
+				continue
+			}
+			ctc, _ := consumer.GetConfig()
+			if !producesConsumedArtifact(ptc.Spec, ctc.Spec) {
+				continue
+			}
+			if isReachable(graph, a, b) {
+				logrus.Debugf("not running '%s' before '%s' since it would create a cycle in the transformer ordering", ptc.Name, ctc.Name)
+				continue
+			}
+			graph[b][a] = true
+		}
+	}
+	inDegrees := make([]int, len(ts))
+	for b := range graph {
+		for a := range graph[b] {
+			if graph[b][a] {
+				inDegrees[a]++
+			}
+		}
+	}
+	done := make([]bool, len(ts))
+	orderedTransformers := []Transformer{}
+	for len(orderedTransformers) < len(ts) {
+		next := -1
+		for i := range ts {
+			if !done[i] && inDegrees[i] == 0 {
+				next = i
+				break
+			}
+		}
+		if next == -1 {
+			return nil, fmt.Errorf("failed to compute a valid execution order for the transformers")
+		}
+		done[next] = true
+		for a := range graph[next] {
+			if graph[next][a] {
+				inDegrees[a]--
+			}
+		}
+		orderedTransformers = append(orderedTransformers, ts[next])
+	}
+	return orderedTransformers, nil
+}
+
+// producesConsumedArtifact returns true if the producer creates an artifact type that the consumer consumes
+func producesConsumedArtifact(producer, consumer transformertypes.TransformerSpec) bool {
+	for artifactType, producedArtifact := range producer.ProducedArtifacts {
+		if producedArtifact.Disabled {
+			continue
+		}
+		if producedArtifact.ChangeTypeTo != "" {
+			artifactType = producedArtifact.ChangeTypeTo
+		}
+		if artifactType == ALLOW_ALL_ARTIFACT_TYPES {
+			continue
+		}
+		if consumedArtifact, ok := consumer.ConsumedArtifacts[artifactType]; ok && !consumedArtifact.Disabled {
+			return true
+		}
+	}
+	return false
+}
+
+// isReachable returns true if there is a path from the start vertex to the end vertex
+func isReachable(graph [][]bool, start, end int) bool {
+	visited := make([]bool, len(graph))
+	stack := []int{start}
+	for len(stack) > 0 {
+		curr := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if curr == end {
+			return true
+		}
+		if visited[curr] {
+			continue
+		}
+		visited[curr] = true
+		for next, ok := range graph[curr] {
+			if ok && !visited[next] {
+				stack = append(stack, next)
+			}
+		}
+	}
+	return false
+}
+
+// findCycle returns the vertices of a cycle in the graph, starting and ending with the same vertex.
+// It returns nil if the graph has no cycles.
+func findCycle(graph [][]bool) []int {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	states := make([]int, len(graph))
+	path := []int{}
+	var visit func(int) []int
+	visit = func(curr int) []int {
+		states[curr] = visiting
+		path = append(path, curr)
+		for next, ok := range graph[curr] {
+			if !ok {
+				continue
+			}
+			if states[next] == visiting {
+				for i, v := range path {
+					if v == next {
+						return append(append([]int{}, path[i:]...), next)
+					}
+				}
+			}
+			if states[next] == unvisited {
+				if cycle := visit(next); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		states[curr] = visited
+		return nil
+	}
+	for i := range graph {
+		if states[i] == unvisited {
+			if cycle := visit(i); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
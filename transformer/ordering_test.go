@@ -0,0 +1,116 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/environment"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+)
+
+type orderingTestTransformer struct {
+	config transformertypes.Transformer
+}
+
+func (t *orderingTestTransformer) Init(tc transformertypes.Transformer, env *environment.Environment) error {
+	t.config = tc
+	return nil
+}
+
+func (t *orderingTestTransformer) GetConfig() (transformertypes.Transformer, *environment.Environment) {
+	return t.config, nil
+}
+
+func (t *orderingTestTransformer) DirectoryDetect(dir string) (map[string][]transformertypes.Artifact, error) {
+	return nil, nil
+}
+
+func (t *orderingTestTransformer) Transform(newArtifacts, alreadySeenArtifacts []transformertypes.Artifact) ([]transformertypes.PathMapping, []transformertypes.Artifact, error) {
+	return nil, nil, nil
+}
+
+func newOrderingTestTransformer(name string, consumes, produces []transformertypes.ArtifactType) Transformer {
+	tc := transformertypes.NewTransformer()
+	tc.Name = name
+	tc.Spec.ConsumedArtifacts = map[transformertypes.ArtifactType]transformertypes.ArtifactProcessConfig{}
+	for _, c := range consumes {
+		tc.Spec.ConsumedArtifacts[c] = transformertypes.ArtifactProcessConfig{}
+	}
+	tc.Spec.ProducedArtifacts = map[transformertypes.ArtifactType]transformertypes.ProducedArtifact{}
+	for _, p := range produces {
+		tc.Spec.ProducedArtifacts[p] = transformertypes.ProducedArtifact{}
+	}
+	return &orderingTestTransformer{config: tc}
+}
+
+func getTransformerNames(ts []Transformer) []string {
+	names := []string{}
+	for _, t := range ts {
+		tc, _ := t.GetConfig()
+		names = append(names, tc.Name)
+	}
+	return names
+}
+
+func TestOrderTransformers(t *testing.T) {
+	ts := []Transformer{
+		newOrderingTestTransformer("kubernetes", []transformertypes.ArtifactType{"IR"}, nil),
+		newOrderingTestTransformer("dockerfile", []transformertypes.ArtifactType{"Service"}, []transformertypes.ArtifactType{"IR"}),
+		newOrderingTestTransformer("readme", nil, nil),
+		newOrderingTestTransformer("compose", nil, []transformertypes.ArtifactType{"Service"}),
+	}
+	t.Run("artifact dependencies", func(t *testing.T) {
+		orderedTransformers, err := orderTransformers(ts, transformertypes.TransformerOrderingSpec{})
+		if err != nil {
+			t.Fatalf("failed to order the transformers. Error: %q", err)
+		}
+		want := []string{"readme", "compose", "dockerfile", "kubernetes"}
+		if diff := cmp.Diff(want, getTransformerNames(orderedTransformers)); diff != "" {
+			t.Fatalf("the transformers are not in the expected order. Differences:\n%s", diff)
+		}
+	})
+	t.Run("explicit ordering wins over artifact dependencies", func(t *testing.T) {
+		ordering := transformertypes.TransformerOrderingSpec{
+			Order:    []string{"kubernetes", "dockerfile"},
+			RunAfter: map[string][]string{"compose": {"readme", "not-initialized"}},
+		}
+		orderedTransformers, err := orderTransformers(ts, ordering)
+		if err != nil {
+			t.Fatalf("failed to order the transformers. Error: %q", err)
+		}
+		want := []string{"kubernetes", "readme", "compose", "dockerfile"}
+		if diff := cmp.Diff(want, getTransformerNames(orderedTransformers)); diff != "" {
+			t.Fatalf("the transformers are not in the expected order. Differences:\n%s", diff)
+		}
+	})
+	t.Run("cycle in the explicit ordering", func(t *testing.T) {
+		ordering := transformertypes.TransformerOrderingSpec{
+			Order:    []string{"readme", "compose"},
+			RunAfter: map[string][]string{"readme": {"compose"}},
+		}
+		_, err := orderTransformers(ts, ordering)
+		if err == nil {
+			t.Fatalf("expected an error because of the cycle in the ordering")
+		}
+		if !strings.Contains(err.Error(), "readme -> compose -> readme") {
+			t.Fatalf("the error does not report the cycle. Actual: %q", err)
+		}
+	})
+}
@@ -0,0 +1,247 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/sirupsen/logrus"
+)
+
+const planCacheVersion = "v2"
+
+// parentDirReferenceRegex matches the relative paths starting with ../ in a file, like the extends and env files of a compose file
+var parentDirReferenceRegex = regexp.MustCompile(`\.\.[/\\][^\s"'` + "`" + `,;:()\[\]{}<>|*?]*`)
+
+// planCache caches the results of directory detection keyed by the transformer config and the contents of the directory
+type planCache struct {
+	dir string
+	// dirHashes maps each directory path to the hash of all the files and sub directories inside it
+	dirHashes map[string]string
+	// fileHashes maps each file path to the hash of its contents
+	fileHashes map[string]string
+	// dirReferences maps each directory path to the paths outside the directory, that are referenced using ../ by the files inside it
+	dirReferences map[string][]string
+	hits          int
+	misses        int
+}
+
+// newPlanCache returns a plan cache for the source directory if caching is enabled, nil otherwise
+func newPlanCache(sourceDir string) *planCache {
+	if common.PlanCacheDir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(common.PlanCacheDir, common.DefaultDirectoryPermission); err != nil {
+		logrus.Warnf("failed to create the plan cache directory '%s' . Planning without the cache. Error: %q", common.PlanCacheDir, err)
+		return nil
+	}
+	c := &planCache{dir: common.PlanCacheDir, dirHashes: map[string]string{}, fileHashes: map[string]string{}, dirReferences: map[string][]string{}}
+	if _, _, err := c.hashDirectory(sourceDir); err != nil {
+		logrus.Warnf("failed to compute the hashes of the source directory '%s' . Planning without the cache. Error: %q", sourceDir, err)
+		return nil
+	}
+	return c
+}
+
+// hashDirectory computes the hash of the directory from the names and contents of its files and the hashes of its sub directories.
+// The hashes of all the directories and files in the tree are stored in the cache, along with the paths outside each directory
+// that are referenced by the files inside it. It returns the hash and the referenced paths outside the directory.
+func (c *planCache) hashDirectory(dir string) (string, []string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read the directory '%s' . Error: %w", dir, err)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	hasher := sha256.New()
+	references := []string{}
+	for _, entry := range entries {
+		entryPath := filepath.Join(dir, entry.Name())
+		if entry.IsDir() {
+			if isIgnoredDirName(entry.Name()) {
+				continue
+			}
+			subDirHash, subDirReferences, err := c.hashDirectory(entryPath)
+			if err != nil {
+				return "", nil, err
+			}
+			fmt.Fprintf(hasher, "d %s %s\n", entry.Name(), subDirHash)
+			references = append(references, subDirReferences...)
+			continue
+		}
+		if !entry.Type().IsRegular() {
+			fmt.Fprintf(hasher, "o %s\n", entry.Name())
+			continue
+		}
+		fileHash, fileReferences, err := hashFile(entryPath)
+		if err != nil {
+			return "", nil, err
+		}
+		c.fileHashes[entryPath] = fileHash
+		fmt.Fprintf(hasher, "f %s %s\n", entry.Name(), fileHash)
+		for _, fileReference := range fileReferences {
+			references = append(references, filepath.Join(dir, filepath.FromSlash(fileReference)))
+		}
+	}
+	dirHash := fmt.Sprintf("%x", hasher.Sum(nil))
+	c.dirHashes[dir] = dirHash
+	outsideReferences := []string{}
+	for _, reference := range references {
+		if reference != dir && !common.IsParent(reference, dir) {
+			outsideReferences = common.AppendIfNotPresent(outsideReferences, reference)
+		}
+	}
+	sort.Strings(outsideReferences)
+	c.dirReferences[dir] = outsideReferences
+	return dirHash, outsideReferences, nil
+}
+
+// hashFile returns the hash of the contents of the file and the relative paths starting with ../ in it
+func hashFile(path string) (string, []string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to open the file at path '%s' . Error: %w", path, err)
+	}
+	defer f.Close()
+	hasher := sha256.New()
+	references := []string{}
+	reader := bufio.NewReader(io.TeeReader(f, hasher))
+	for {
+		line, err := reader.ReadSlice('\n')
+		for _, reference := range parentDirReferenceRegex.FindAll(line, -1) {
+			references = common.AppendIfNotPresent(references, string(reference))
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil && err != bufio.ErrBufferFull {
+			return "", nil, fmt.Errorf("failed to read the file at path '%s' . Error: %w", path, err)
+		}
+	}
+	return fmt.Sprintf("%x", hasher.Sum(nil)), references, nil
+}
+
+// getReferenceHash returns the hash of a path outside a directory, that is referenced by the files inside it.
+// Only the files and the directories inside the source directory can be hashed.
+// A referenced directory outside the source directory could be arbitrarily large, so it makes the result uncacheable.
+func (c *planCache) getReferenceHash(reference string) (string, bool) {
+	if dirHash, ok := c.dirHashes[reference]; ok {
+		return dirHash, true
+	}
+	if fileHash, ok := c.fileHashes[reference]; ok {
+		return fileHash, true
+	}
+	fi, err := os.Stat(reference)
+	if os.IsNotExist(err) {
+		return "missing", true
+	}
+	if err != nil || !fi.Mode().IsRegular() {
+		return "", false
+	}
+	fileHash, _, err := hashFile(reference)
+	if err != nil {
+		logrus.Debugf("failed to hash the referenced file at path '%s' . Error: %q", reference, err)
+		return "", false
+	}
+	c.fileHashes[reference] = fileHash
+	return fileHash, true
+}
+
+// getKey returns the cache key for running directory detect on the directory using the transformer.
+// The key includes the contents of the paths outside the directory, that are referenced by the files inside it.
+func (c *planCache) getKey(config transformertypes.Transformer, dir string) (string, bool) {
+	dirHash, ok := c.dirHashes[dir]
+	if !ok {
+		return "", false
+	}
+	configBytes, err := json.Marshal(config)
+	if err != nil {
+		logrus.Debugf("failed to marshal the config of the transformer '%s' . Error: %q", config.Name, err)
+		return "", false
+	}
+	key := planCacheVersion + "\n" + string(configBytes) + "\n" + dir + "\n" + dirHash
+	for _, reference := range c.dirReferences[dir] {
+		referenceHash, ok := c.getReferenceHash(reference)
+		if !ok {
+			logrus.Debugf("not caching the directory detect result for the directory '%s' since it references the path '%s'", dir, reference)
+			return "", false
+		}
+		key += "\n" + reference + " " + referenceHash
+	}
+	return common.GetSHA256Hash(key), true
+}
+
+func (c *planCache) getPath(key string) string {
+	return filepath.Join(c.dir, key[:2], key+".json")
+}
+
+// directoryDetect runs directory detect using the transformer, returning the cached result if the directory has not changed.
+// The returned artifacts have already been decoded from the transformer's environment.
+func (c *planCache) directoryDetect(transformer Transformer, config transformertypes.Transformer, env *environment.Environment, dir string) (map[string][]transformertypes.Artifact, error) {
+	detect := func() (map[string][]transformertypes.Artifact, error) {
+		services, err := transformer.DirectoryDetect(env.Encode(dir).(string))
+		if err != nil {
+			return services, err
+		}
+		return *env.Decode(&services).(*map[string][]transformertypes.Artifact), nil
+	}
+	if c == nil {
+		return detect()
+	}
+	key, ok := c.getKey(config, dir)
+	if !ok {
+		return detect()
+	}
+	cachePath := c.getPath(key)
+	if cachedBytes, err := os.ReadFile(cachePath); err == nil {
+		services := map[string][]transformertypes.Artifact{}
+		if err := json.Unmarshal(cachedBytes, &services); err == nil {
+			logrus.Debugf("[%s] using the cached directory detect result for the directory '%s'", config.Name, dir)
+			c.hits++
+			return services, nil
+		}
+		logrus.Debugf("failed to parse the plan cache file at path '%s' . Error: %q", cachePath, err)
+	}
+	c.misses++
+	services, err := detect()
+	if err != nil {
+		return services, err
+	}
+	servicesBytes, err := json.Marshal(services)
+	if err != nil {
+		logrus.Debugf("failed to marshal the directory detect result of the transformer '%s' . Error: %q", config.Name, err)
+		return services, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(cachePath), common.DefaultDirectoryPermission); err != nil {
+		logrus.Debugf("failed to create the plan cache directory '%s' . Error: %q", filepath.Dir(cachePath), err)
+		return services, nil
+	}
+	if err := os.WriteFile(cachePath, servicesBytes, common.DefaultFilePermission); err != nil {
+		logrus.Debugf("failed to write the plan cache file at path '%s' . Error: %q", cachePath, err)
+	}
+	return services, nil
+}
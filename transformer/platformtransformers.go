@@ -0,0 +1,40 @@
+//go:build !wasm
+// +build !wasm
+
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"github.com/konveyor/move2kube/transformer/external"
+)
+
+// unsupportedTransformerClasses are the transformer classes which are not available on this platform
+var unsupportedTransformerClasses = []string{}
+
+// getPlatformTransformers returns the transformers whose dependencies do not build for WebAssembly
+func getPlatformTransformers() []Transformer {
+	return []Transformer{
+		new(external.WASM),
+		new(CloudFoundry),
+	}
+}
+
+// cleanupPlatformTransformers releases the resources held by the platform specific transformers
+func cleanupPlatformTransformers() {
+	external.CleanupWASMTransformers()
+}
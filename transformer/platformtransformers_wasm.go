@@ -0,0 +1,33 @@
+//go:build wasm
+// +build wasm
+
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+// unsupportedTransformerClasses are the transformer classes which are not available in WebAssembly.
+// The WASM transformer needs the wazero wasi system calls and the CloudFoundry transformer needs the bosh system packages.
+var unsupportedTransformerClasses = []string{"WASM", "CloudFoundry"}
+
+// getPlatformTransformers returns no transformers in WebAssembly
+func getPlatformTransformers() []Transformer {
+	return nil
+}
+
+// cleanupPlatformTransformers does nothing in WebAssembly, since there are no platform specific transformers
+func cleanupPlatformTransformers() {
+}
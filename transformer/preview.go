@@ -0,0 +1,183 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/filesystem"
+	"github.com/konveyor/move2kube/qaengine"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	previewBaseDir    = "preview-base"
+	previewStagingDir = "preview-staging"
+	// previewDiffHashLength is the length of the prefix of the hash of a diff used in the question keys
+	previewDiffHashLength = 12
+)
+
+var (
+	// previewMutex makes sure that the previews of transformers running in parallel are not interleaved
+	previewMutex sync.Mutex
+	// previewBasePath is the copy of the output directory the previews are compared with. The previews are disabled if empty.
+	previewBasePath = ""
+)
+
+// StartDiffPreview enables previewing the files written by each transformer as a diff against the current contents of the output directory.
+// The output directory is copied since the transformation overwrites it.
+func StartDiffPreview(outputPath string) error {
+	basePath := filepath.Join(common.TempPath, previewBaseDir)
+	if err := os.RemoveAll(basePath); err != nil {
+		return fmt.Errorf("failed to remove the directory '%s' . Error: %w", basePath, err)
+	}
+	if err := os.MkdirAll(basePath, common.DefaultDirectoryPermission); err != nil {
+		return fmt.Errorf("failed to create the directory '%s' . Error: %w", basePath, err)
+	}
+	if _, err := os.Stat(outputPath); err == nil {
+		if err := filesystem.Merge(outputPath, basePath, false); err != nil {
+			return fmt.Errorf("failed to copy the output directory '%s' to '%s' . Error: %w", outputPath, basePath, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to stat the output directory '%s' . Error: %w", outputPath, err)
+	}
+	previewBasePath = basePath
+	return nil
+}
+
+// StopDiffPreview disables the previews and removes the copy of the output directory
+func StopDiffPreview() {
+	if previewBasePath == "" {
+		return
+	}
+	if err := os.RemoveAll(previewBasePath); err != nil {
+		logrus.Debugf("failed to remove the directory '%s' . Error: %q", previewBasePath, err)
+	}
+	previewBasePath = ""
+}
+
+// approvePathMappings shows the diff of the files the transformer is about to write and asks whether to write them.
+// The path mappings are approved without asking if they do not change any files.
+// The question is keyed by the hash of the diff, since a transformer can run many times with different changes.
+func approvePathMappings(transformerName string, pathMappings []transformertypes.PathMapping, sourcePath string) bool {
+	previewMutex.Lock()
+	defer previewMutex.Unlock()
+	diff, err := getPathMappingsDiff(pathMappings, sourcePath, previewBasePath)
+	if err != nil {
+		logrus.Errorf("failed to preview the changes of the transformer '%s' . Error: %q", transformerName, err)
+		return true
+	}
+	if diff == "" {
+		logrus.Debugf("the transformer '%s' does not change any files in the output directory", transformerName)
+		return true
+	}
+	diffHash := common.GetSHA256Hash(diff)[:previewDiffHashLength]
+	return qaengine.FetchBoolAnswer(
+		common.JoinQASubKeys(common.ConfigTransformersKey, `"`+transformerName+`"`, common.ConfigApplyChangesForTransformerKeySegment, `"`+diffHash+`"`),
+		fmt.Sprintf("Write the changes of the transformer '%s' to the output directory?", transformerName),
+		[]string{"The files of a skipped transformer are left out of the output directory", "Changes:\n" + diff},
+		true,
+		nil,
+	)
+}
+
+// getPathMappingsDiff returns the unified diff between the files in the base directory and the files written by the path mappings
+func getPathMappingsDiff(pathMappings []transformertypes.PathMapping, sourcePath, basePath string) (string, error) {
+	stagingPath, err := os.MkdirTemp(common.TempPath, previewStagingDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to create a temporary directory for the preview. Error: %w", err)
+	}
+	defer os.RemoveAll(stagingPath)
+	if err := processPathMappings(pathMappings, sourcePath, stagingPath, false); err != nil {
+		return "", fmt.Errorf("failed to process the path mappings. Error: %w", err)
+	}
+	newFiles := map[string]string{}
+	if err := filepath.WalkDir(stagingPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		relPath, err := filepath.Rel(stagingPath, path)
+		if err != nil {
+			return err
+		}
+		newFiles[filepath.ToSlash(relPath)] = path
+		return nil
+	}); err != nil {
+		return "", fmt.Errorf("failed to walk the directory '%s' . Error: %w", stagingPath, err)
+	}
+	// the deleted files are compared with empty files
+	for _, pm := range pathMappings {
+		if !strings.EqualFold(string(pm.Type), string(transformertypes.DeletePathMappingType)) || filepath.IsAbs(pm.DestPath) {
+			continue
+		}
+		if _, ok := newFiles[filepath.ToSlash(pm.DestPath)]; !ok {
+			newFiles[filepath.ToSlash(pm.DestPath)] = ""
+		}
+	}
+	relPaths := []string{}
+	for relPath := range newFiles {
+		relPaths = append(relPaths, relPath)
+	}
+	sort.Strings(relPaths)
+	diff := strings.Builder{}
+	for _, relPath := range relPaths {
+		oldContents, err := readPreviewFile(filepath.Join(basePath, filepath.FromSlash(relPath)))
+		if err != nil {
+			return "", err
+		}
+		newContents, err := readPreviewFile(newFiles[relPath])
+		if err != nil {
+			return "", err
+		}
+		if bytes.Equal(oldContents, newContents) {
+			continue
+		}
+		if bytes.IndexByte(oldContents, 0) != -1 || bytes.IndexByte(newContents, 0) != -1 {
+			diff.WriteString(fmt.Sprintf("Binary files a/%s and b/%s differ\n", relPath, relPath))
+			continue
+		}
+		diff.WriteString(common.UnifiedDiff("a/"+relPath, "b/"+relPath, string(oldContents), string(newContents)))
+	}
+	return diff.String(), nil
+}
+
+// readPreviewFile returns the contents of the file, or nothing if the file does not exist
+func readPreviewFile(path string) ([]byte, error) {
+	if path == "" {
+		return nil, nil
+	}
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read the file at path '%s' . Error: %w", path, err)
+	}
+	return contents, nil
+}
@@ -0,0 +1,104 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	qatypes "github.com/konveyor/move2kube/types/qaengine"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+)
+
+func TestGetPathMappingsDiff(t *testing.T) {
+	oldTempPath := common.TempPath
+	common.TempPath = t.TempDir()
+	defer func() { common.TempPath = oldTempPath }()
+	sourcePath := t.TempDir()
+	basePath := t.TempDir()
+	writeFile := func(path, contents string) {
+		if err := os.MkdirAll(filepath.Dir(path), common.DefaultDirectoryPermission); err != nil {
+			t.Fatalf("failed to create the directory for the file '%s' . Error: %q", path, err)
+		}
+		if err := os.WriteFile(path, []byte(contents), common.DefaultFilePermission); err != nil {
+			t.Fatalf("failed to write the file '%s' . Error: %q", path, err)
+		}
+	}
+	writeFile(filepath.Join(sourcePath, "yamls", "deployment.yaml"), "kind: Deployment\nreplicas: 2\n")
+	writeFile(filepath.Join(sourcePath, "yamls", "service.yaml"), "kind: Service\n")
+	writeFile(filepath.Join(basePath, "yamls", "deployment.yaml"), "kind: Deployment\nreplicas: 1\n")
+	writeFile(filepath.Join(basePath, "yamls", "service.yaml"), "kind: Service\n")
+	writeFile(filepath.Join(basePath, "old.yaml"), "kind: ConfigMap\n")
+	pathMappings := []transformertypes.PathMapping{
+		{Type: transformertypes.DefaultPathMappingType, SrcPath: filepath.Join(sourcePath, "yamls"), DestPath: "yamls"},
+		{Type: transformertypes.DeletePathMappingType, DestPath: "old.yaml"},
+	}
+	got, err := getPathMappingsDiff(pathMappings, sourcePath, basePath)
+	if err != nil {
+		t.Fatalf("failed to get the diff of the path mappings. Error: %q", err)
+	}
+	want := "--- a/old.yaml\n+++ b/old.yaml\n@@ -1 +0,0 @@\n-kind: ConfigMap\n" +
+		"--- a/yamls/deployment.yaml\n+++ b/yamls/deployment.yaml\n@@ -1,2 +1,2 @@\n kind: Deployment\n-replicas: 1\n+replicas: 2\n"
+	if got != want {
+		t.Fatalf("the diff is wrong. Difference:\n%s", cmp.Diff(want, got))
+	}
+}
+
+func TestApprovePathMappings(t *testing.T) {
+	oldTempPath := common.TempPath
+	oldPreviewBasePath := previewBasePath
+	common.TempPath = t.TempDir()
+	previewBasePath = t.TempDir()
+	defer func() {
+		qaengine.ResetEngines()
+		qaengine.ResetQuestionHooks()
+		common.TempPath = oldTempPath
+		previewBasePath = oldPreviewBasePath
+	}()
+	qaengine.ResetEngines()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	problems := []qatypes.Problem{}
+	qaengine.AddQuestionHook(func(prob qatypes.Problem) {
+		problems = append(problems, prob)
+	})
+	sourcePath := t.TempDir()
+	for _, contents := range []string{"replicas: 1\n", "replicas: 2\n"} {
+		if err := os.WriteFile(filepath.Join(sourcePath, "deployment.yaml"), []byte(contents), common.DefaultFilePermission); err != nil {
+			t.Fatalf("failed to write the file. Error: %q", err)
+		}
+		pathMappings := []transformertypes.PathMapping{{Type: transformertypes.DefaultPathMappingType, SrcPath: sourcePath, DestPath: "yamls"}}
+		if !approvePathMappings("t1", pathMappings, sourcePath) {
+			t.Fatalf("expected the changes to be approved by default")
+		}
+	}
+	if len(problems) != 2 {
+		t.Fatalf("expected a question for each of the diffs. Actual: %+v", problems)
+	}
+	if problems[0].ID == problems[1].ID {
+		t.Fatalf("expected the questions of different diffs to have different keys. Actual: %s", problems[0].ID)
+	}
+	for i, problem := range problems {
+		if hints := strings.Join(problem.Hints, "\n"); !strings.Contains(hints, "+replicas: "+string(rune('1'+i))) {
+			t.Fatalf("expected the diff to be in the hints. Actual: %s", hints)
+		}
+	}
+}
@@ -18,11 +18,16 @@ package transformer
 
 import (
 	"path/filepath"
+	"sort"
 	"strings"
 
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/common/report"
 	"github.com/konveyor/move2kube/environment"
 	"github.com/konveyor/move2kube/types/info"
+	irtypes "github.com/konveyor/move2kube/types/ir"
 	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/konveyor/move2kube/types/transformer/artifacts"
 	"github.com/sirupsen/logrus"
 	"gopkg.in/yaml.v3"
 )
@@ -33,6 +38,46 @@ type ReadMeGenerator struct {
 	Env    *environment.Environment
 }
 
+// ReadMeTemplateConfig contains the data used to fill the readme and runbook templates
+type ReadMeTemplateConfig struct {
+	Version      string
+	ProjectName  string
+	Images       []string
+	BuildScripts []string
+	PushScripts  []string
+	Deployments  []RunbookDeployment
+	Secrets      []RunbookSecret
+	QAAnswers    []RunbookAnswer
+	FollowUps    []RunbookFollowUp
+}
+
+// RunbookDeployment is a directory of generated yamls along with the transformer that generated them
+type RunbookDeployment struct {
+	Transformer string
+	Path        string
+	// ParameterizedPath is the directory containing the helm chart, kustomize and openshift template versions of the yamls
+	ParameterizedPath string
+}
+
+// RunbookSecret is a secret in the generated yamls whose values must be filled in before deploying
+type RunbookSecret struct {
+	Name string
+	Path string
+}
+
+// RunbookAnswer is a QA decision that shaped the output
+type RunbookAnswer struct {
+	ID       string
+	Question string
+	Answer   string
+}
+
+// RunbookFollowUp is a manual step that must be done before deploying the output
+type RunbookFollowUp struct {
+	Service     string
+	Description string
+}
+
 // Init initializes the translator
 func (t *ReadMeGenerator) Init(tc transformertypes.Transformer, env *environment.Environment) (err error) {
 	t.Config = tc
@@ -53,13 +98,53 @@ func (t *ReadMeGenerator) DirectoryDetect(dir string) (services map[string][]tra
 // Transform transforms the artifacts
 func (t *ReadMeGenerator) Transform(newArtifacts []transformertypes.Artifact, alreadySeenArtifacts []transformertypes.Artifact) ([]transformertypes.PathMapping, []transformertypes.Artifact, error) {
 	pathMappings := []transformertypes.PathMapping{}
-	data := struct{ Version string }{Version: ""}
+	data := ReadMeTemplateConfig{ProjectName: t.Env.ProjectName}
 	verYAMl, err := yaml.Marshal(info.GetVersionInfo())
 	if err != nil {
 		logrus.Errorf("failed to marshal the version information to YAML. Error: %q", err)
 	} else {
 		data.Version = strings.TrimSpace(string(verYAMl))
 	}
+	for _, a := range append(alreadySeenArtifacts, newArtifacts...) {
+		switch a.Type {
+		case artifacts.NewImagesArtifactType:
+			images := artifacts.NewImages{}
+			if err := a.GetConfig(artifacts.NewImagesConfigType, &images); err != nil {
+				logrus.Debugf("failed to load config of type '%s' into struct of type %T . Error: %q", artifacts.NewImagesConfigType, images, err)
+				continue
+			}
+			data.Images = common.MergeSlices(data.Images, images.ImageNames)
+		case artifacts.ContainerImageBuildScriptArtifactType:
+			data.BuildScripts = common.MergeSlices(data.BuildScripts, t.getOutputRelPaths(a.Paths[artifacts.ContainerImageBuildShScriptPathType]))
+		case artifacts.ContainerImagesPushScriptArtifactType:
+			data.PushScripts = common.MergeSlices(data.PushScripts, t.getOutputRelPaths(a.Paths[artifacts.ContainerImagesPushShScriptPathType]))
+		case artifacts.KubernetesYamlsArtifactType:
+			for _, yamlsPath := range a.Paths[artifacts.KubernetesYamlsPathType] {
+				relPath := t.getOutputRelPath(yamlsPath)
+				if isDeploymentPresent(data.Deployments, relPath) {
+					continue
+				}
+				data.Deployments = append(data.Deployments, RunbookDeployment{Transformer: a.Name, Path: relPath, ParameterizedPath: relPath + "-parameterized"})
+				data.Secrets = append(data.Secrets, t.getSecrets(yamlsPath)...)
+			}
+		}
+	}
+	sort.Strings(data.Images)
+	sort.SliceStable(data.Deployments, func(i, j int) bool { return data.Deployments[i].Path < data.Deployments[j].Path })
+	sort.SliceStable(data.Secrets, func(i, j int) bool {
+		if data.Secrets[i].Path != data.Secrets[j].Path {
+			return data.Secrets[i].Path < data.Secrets[j].Path
+		}
+		return data.Secrets[i].Name < data.Secrets[j].Name
+	})
+	r := report.Get("", "")
+	for _, qaAnswer := range r.Spec.QAAnswers {
+		data.QAAnswers = append(data.QAAnswers, RunbookAnswer{ID: qaAnswer.ID, Question: qaAnswer.Question, Answer: report.FormatAnswer(qaAnswer.Answer)})
+	}
+	sort.SliceStable(data.QAAnswers, func(i, j int) bool { return data.QAAnswers[i].ID < data.QAAnswers[j].ID })
+	for _, followUp := range r.Spec.FollowUps {
+		data.FollowUps = append(data.FollowUps, RunbookFollowUp{Service: followUp.Service, Description: followUp.Description})
+	}
 	pathMappings = append(pathMappings, transformertypes.PathMapping{
 		Type:           transformertypes.TemplatePathMappingType,
 		SrcPath:        filepath.Join(t.Env.Context, t.Config.Spec.TemplatesDir),
@@ -67,3 +152,57 @@ func (t *ReadMeGenerator) Transform(newArtifacts []transformertypes.Artifact, al
 	})
 	return pathMappings, nil, nil
 }
+
+// getSecrets returns the secrets present in the yamls directory
+func (t *ReadMeGenerator) getSecrets(yamlsPath string) []RunbookSecret {
+	if !filepath.IsAbs(yamlsPath) {
+		yamlsPath = filepath.Join(t.Env.GetEnvironmentOutput(), yamlsPath)
+	}
+	yamlPaths, err := common.GetFilesByExt(yamlsPath, []string{".yml", ".yaml"})
+	if err != nil {
+		logrus.Debugf("failed to look for yaml files in the directory '%s' . Error: %q", yamlsPath, err)
+		return nil
+	}
+	secrets := []RunbookSecret{}
+	for _, yamlPath := range yamlPaths {
+		obj := struct {
+			Kind     string `yaml:"kind"`
+			Metadata struct {
+				Name string `yaml:"name"`
+			} `yaml:"metadata"`
+		}{}
+		if err := common.ReadYaml(yamlPath, &obj); err != nil || obj.Kind != string(irtypes.SecretKind) {
+			continue
+		}
+		secrets = append(secrets, RunbookSecret{Name: obj.Metadata.Name, Path: t.getOutputRelPath(yamlPath)})
+	}
+	return secrets
+}
+
+// getOutputRelPaths makes the paths relative to the output directory
+func (t *ReadMeGenerator) getOutputRelPaths(paths []string) []string {
+	relPaths := []string{}
+	for _, path := range paths {
+		relPaths = append(relPaths, t.getOutputRelPath(path))
+	}
+	return relPaths
+}
+
+// getOutputRelPath makes the path relative to the output directory
+func (t *ReadMeGenerator) getOutputRelPath(path string) string {
+	if filepath.IsAbs(path) {
+		if relPath, err := filepath.Rel(t.Env.GetEnvironmentOutput(), path); err == nil {
+			path = relPath
+		}
+	}
+	return filepath.ToSlash(path)
+}
+
+func isDeploymentPresent(deployments []RunbookDeployment, path string) bool {
+	for _, deployment := range deployments {
+		if deployment.Path == path {
+			return true
+		}
+	}
+	return false
+}
@@ -107,7 +107,7 @@ func nameServices(projectName string, inputServicesMap map[string][]plantypes.Pl
 			normalizedProjectName := common.NormalizeForMetadataName(projectName)
 			inputServicesMap[normalizedProjectName] = unnamedServices
 		}
-		return inputServicesMap
+		return normalizeServiceNames(inputServicesMap)
 	}
 
 	repoNameToServicePathInfos := map[string][]servicePathInfo{}
@@ -149,7 +149,27 @@ func nameServices(projectName string, inputServicesMap map[string][]plantypes.Pl
 			outputServicesMap[normalizedNewName] = serviceDirToUnnamedServices[pathInfo.path]
 		}
 	}
-	return plantypes.MergeServices(inputServicesMap, outputServicesMap)
+	return normalizeServiceNames(plantypes.MergeServices(inputServicesMap, outputServicesMap))
+}
+
+// normalizeServiceNames makes the service names valid Kubernetes names while keeping distinct services distinct.
+// Names like "web_api" and "web.api" would otherwise be merged into the same service "web-api".
+func normalizeServiceNames(services map[string][]plantypes.PlanArtifact) map[string][]plantypes.PlanArtifact {
+	serviceNames := []string{}
+	for serviceName := range services {
+		serviceNames = append(serviceNames, serviceName)
+	}
+	newServiceNames := common.NormalizeForMetadataNames(serviceNames, common.ServiceNameMaxLength)
+	normalizedServices := map[string][]plantypes.PlanArtifact{}
+	for serviceName, serviceArtifacts := range services {
+		newServiceName, ok := newServiceNames[serviceName]
+		if !ok {
+			logrus.Errorf("failed to normalize the service name '%s' . Ignoring the service", serviceName)
+			continue
+		}
+		normalizedServices[newServiceName] = serviceArtifacts
+	}
+	return normalizedServices
 }
 
 func bucketServices(services []servicePathInfo) map[string][]servicePathInfo {
@@ -17,6 +17,7 @@
 package transformer
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -26,8 +27,11 @@ import (
 	"runtime"
 	"sort"
 	"strings"
+	"sync"
 
 	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/common/deepcopy"
+	"github.com/konveyor/move2kube/common/report"
 	"github.com/konveyor/move2kube/environment"
 	containertypes "github.com/konveyor/move2kube/environment/container"
 	"github.com/konveyor/move2kube/filesystem"
@@ -40,6 +44,7 @@ import (
 	"github.com/konveyor/move2kube/transformer/dockerfilegenerator/windows"
 	"github.com/konveyor/move2kube/transformer/external"
 	"github.com/konveyor/move2kube/transformer/kubernetes"
+	"github.com/konveyor/move2kube/transformer/kubernetes/apiresource"
 	"github.com/konveyor/move2kube/types"
 	environmenttypes "github.com/konveyor/move2kube/types/environment"
 	graphtypes "github.com/konveyor/move2kube/types/graph"
@@ -61,6 +66,11 @@ type Transformer interface {
 	Transform(newArtifacts []transformertypes.Artifact, alreadySeenArtifacts []transformertypes.Artifact) ([]transformertypes.PathMapping, []transformertypes.Artifact, error)
 }
 
+// ContextTransformer is implemented by the transformers that stop transforming when the context is cancelled
+type ContextTransformer interface {
+	TransformWithContext(ctx context.Context, newArtifacts []transformertypes.Artifact, alreadySeenArtifacts []transformertypes.Artifact) ([]transformertypes.PathMapping, []transformertypes.Artifact, error)
+}
+
 type processType int
 
 const (
@@ -84,12 +94,18 @@ var (
 	transformers                 = []Transformer{}
 	invokedByDefaultTransformers = []Transformer{}
 	transformerMap               = map[string]Transformer{}
+	// transformerLocks makes sure that a transformer and its environment are used by only one goroutine at a time
+	transformerLocks      = map[string]*sync.Mutex{}
+	transformerLocksMutex sync.Mutex
+	// graphMutex protects the transformation graph when transformers run in parallel
+	graphMutex sync.Mutex
 )
 
 func init() {
 	transformerObjs := []Transformer{
 		new(external.Starlark),
 		new(external.Executable),
+		new(external.GoPlugin),
 
 		new(Router),
 
@@ -118,8 +134,9 @@ func init() {
 		new(CNBContainerizer),
 		new(compose.ComposeAnalyser),
 		new(compose.ComposeGenerator),
+		new(compose.DockerContainersAnalyser),
 
-		new(CloudFoundry),
+		new(IRLoader),
 
 		new(containerimage.ContainerImagesPushScript),
 
@@ -136,6 +153,7 @@ func init() {
 		new(ReadMeGenerator),
 		new(InvokeDetect),
 	}
+	transformerObjs = append(transformerObjs, getPlatformTransformers()...)
 	transformerTypes = common.GetTypesMap(transformerObjs)
 }
 
@@ -204,6 +222,27 @@ func InitTransformers(transformerYamlPaths map[string]string, selector labels.Se
 		}
 	}
 	transformerConfigs := getFilteredTransformers(transformerYamlPaths, selector, logError)
+	var ordering *transformertypes.TransformerOrdering
+	if common.AssetsPath != "" {
+		var err error
+		if ordering, err = getTransformerOrdering(common.AssetsPath); err != nil {
+			logrus.Debugf("failed to get the transformer ordering config. Error: %q", err)
+		}
+	}
+	if err := apiresource.LoadMetadataInjections(common.AssetsPath); err != nil {
+		logrus.Debugf("failed to load the metadata injection configs. Error: %q", err)
+	}
+	if err := apiresource.LoadResourcePatches(common.AssetsPath); err != nil {
+		logrus.Debugf("failed to load the resource patch configs. Error: %q", err)
+	}
+	if ordering != nil {
+		for _, disabledTransformerName := range ordering.Spec.Disabled {
+			if _, ok := transformerConfigs[disabledTransformerName]; ok {
+				logrus.Debugf("the transformer '%s' has been disabled by the ordering config", disabledTransformerName)
+				delete(transformerConfigs, disabledTransformerName)
+			}
+		}
+	}
 	deselectedTransformers := map[string]string{}
 	for transformerName, transformerPath := range transformerYamlPaths {
 		if _, ok := transformerConfigs[transformerName]; !ok {
@@ -219,6 +258,7 @@ func InitTransformers(transformerYamlPaths map[string]string, selector labels.Se
 		}
 	}
 	sort.Strings(transformerNames)
+	sort.Strings(transformerNamesSelectedByDefault)
 	selectedTransformerNames := qaengine.FetchMultiSelectAnswer(
 		common.ConfigTransformerTypesKey,
 		"Select all transformer types that you are interested in:",
@@ -289,6 +329,19 @@ func InitTransformers(transformerYamlPaths map[string]string, selector labels.Se
 			invokedByDefaultTransformers = append(invokedByDefaultTransformers, transformer)
 		}
 	}
+	if ordering != nil {
+		orderedTransformers, err := orderTransformers(transformers, ordering.Spec)
+		if err != nil {
+			return deselectedTransformers, fmt.Errorf("failed to order the transformers. Error: %w", err)
+		}
+		transformers = orderedTransformers
+		invokedByDefaultTransformers = []Transformer{}
+		for _, transformer := range transformers {
+			if tc, _ := transformer.GetConfig(); tc.Spec.InvokedByDefault.Enabled {
+				invokedByDefaultTransformers = append(invokedByDefaultTransformers, transformer)
+			}
+		}
+	}
 	initialized = true
 	return deselectedTransformers, nil
 }
@@ -301,6 +354,18 @@ func Destroy() {
 			logrus.Errorf("Unable to destroy environment : %s", err)
 		}
 	}
+	external.CleanupGoPlugins()
+	cleanupPlatformTransformers()
+}
+
+// Reset destroys the transformers and clears the initialized state so that they can be initialized again
+func Reset() {
+	Destroy()
+	containertypes.Reset()
+	initialized = false
+	transformers = []Transformer{}
+	invokedByDefaultTransformers = []Transformer{}
+	transformerMap = map[string]Transformer{}
 }
 
 // GetInitializedTransformers returns the list of initialized transformers
@@ -332,8 +397,9 @@ func GetInitializedTransformersF(filters labels.Selector) []Transformer {
 	return filteredTransformers
 }
 
-// GetServices returns the list of services detected in a directory
-func GetServices(projectName string, dir string, transformerSelector *metav1.LabelSelector) (map[string][]plantypes.PlanArtifact, error) {
+// GetServices returns the list of services detected in a directory.
+// Planning stops with the context's error if the context is cancelled.
+func GetServices(ctx context.Context, projectName string, dir string, transformerSelector *metav1.LabelSelector) (map[string][]plantypes.PlanArtifact, error) {
 	logrus.Trace("GetServices start")
 	defer logrus.Trace("GetServices end")
 	selectedTransformers := transformers
@@ -345,9 +411,13 @@ func GetServices(projectName string, dir string, transformerSelector *metav1.Lab
 		selectedTransformers = GetInitializedTransformersF(filters)
 	}
 	planServices := map[string][]plantypes.PlanArtifact{}
+	cache := newPlanCache(dir)
 	logrus.Infof("Planning started on the base directory: '%s'", dir)
 	logrus.Debugf("selectedTransformers: %+v", selectedTransformers)
 	for _, transformer := range selectedTransformers {
+		if err := ctx.Err(); err != nil {
+			return planServices, fmt.Errorf("planning was stopped. Error: %w", err)
+		}
 		config, env := transformer.GetConfig()
 		if err := env.Reset(); err != nil {
 			logrus.Errorf("failed to reset the environment for the transformer named '%s' . Error: %q", config.Name, err)
@@ -357,12 +427,12 @@ func GetServices(projectName string, dir string, transformerSelector *metav1.Lab
 			continue
 		}
 		logrus.Infof("[%s] Planning", config.Name)
-		newServices, err := transformer.DirectoryDetect(env.Encode(dir).(string))
+		newServices, err := cache.directoryDetect(transformer, config, env, dir)
 		if err != nil {
 			logrus.Errorf("failed to look for services in the directory '%s' using the transformer named '%s' . Error: %q", dir, config.Name, err)
 			continue
 		}
-		newPlanServices := getPlanArtifactsFromArtifacts(*env.Decode(&newServices).(*map[string][]transformertypes.Artifact), config)
+		newPlanServices := getPlanArtifactsFromArtifacts(newServices, config)
 		planServices = plantypes.MergeServices(planServices, newPlanServices)
 		if len(newPlanServices) > 0 {
 			logrus.Infof(getNamedAndUnNamedServicesLogMessage(newPlanServices))
@@ -373,45 +443,63 @@ func GetServices(projectName string, dir string, transformerSelector *metav1.Lab
 	logrus.Infof("[Base Directory] %s", getNamedAndUnNamedServicesLogMessage(planServices))
 	logrus.Infof("Planning finished on the base directory: '%s'", dir)
 	logrus.Info("Planning started on its sub directories")
-	nservices, err := walkForServices(dir, planServices)
+	nservices, err := walkForServices(ctx, dir, planServices, cache)
 	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return planServices, fmt.Errorf("planning was stopped during the directory walk. Error: %w", ctxErr)
+		}
 		logrus.Errorf("Transformation planning - Directory Walk failed. Error: %q", err)
 	} else {
 		planServices = nservices
 		logrus.Infoln("Planning finished on its sub directories")
 	}
 	logrus.Infof("[Directory Walk] %s", getNamedAndUnNamedServicesLogMessage(planServices))
+	if cache != nil {
+		logrus.Infof("Plan cache: %d hits and %d misses", cache.hits, cache.misses)
+	}
 	planServices = nameServices(projectName, planServices)
 	logrus.Infof("[Named Services] Identified %d named services", len(planServices))
+	runServiceDetectedHooks(planServices)
 	return planServices, nil
 }
 
-func walkForServices(inputPath string, bservices map[string][]plantypes.PlanArtifact) (map[string][]plantypes.PlanArtifact, error) {
+func walkForServices(ctx context.Context, inputPath string, bservices map[string][]plantypes.PlanArtifact, cache *planCache) (map[string][]plantypes.PlanArtifact, error) {
 	services := bservices
-	ignoreDirectories, ignoreContents := getIgnorePaths(inputPath)
+	dirs, ignoreFilePaths, err := listPlanDirectories(ctx, inputPath)
+	if err != nil {
+		return services, fmt.Errorf("failed to walk through the directory at path %s . Error: %q", inputPath, err)
+	}
+	dirs = applyPlanSizeBudget(dirs)
+	ignoreRules := parseIgnoreFiles(inputPath, ignoreFilePaths)
 	knownServiceDirPaths := []string{}
-
-	err := filepath.WalkDir(inputPath, func(path string, info os.DirEntry, err error) error {
-		if err != nil {
-			logrus.Warnf("Skipping path %q due to error. Error: %q", path, err)
-			return nil
-		}
-		if !info.IsDir() {
-			return nil
-		}
-		for _, dirRegExp := range common.DefaultIgnoreDirRegexps {
-			if dirRegExp.Match([]byte(filepath.Base(path))) {
-				return filepath.SkipDir
+	skippedDirPaths := []string{}
+
+	for _, dir := range dirs {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return services, fmt.Errorf("failed to walk through the directory at path %s . Error: %q", inputPath, ctxErr)
+		}
+		path := dir.path
+		skipped := false
+		for _, skippedDirPath := range skippedDirPaths {
+			if path == skippedDirPath || common.IsParent(path, skippedDirPath) {
+				skipped = true
+				break
 			}
 		}
+		if skipped {
+			continue
+		}
 		if common.IsPresent(knownServiceDirPaths, path) {
-			return filepath.SkipDir // TODO: Should we go inside the directory in this case?
+			skippedDirPaths = append(skippedDirPaths, path) // TODO: Should we go inside the directory in this case?
+			continue
 		}
-		if common.IsPresent(ignoreDirectories, path) {
-			if common.IsPresent(ignoreContents, path) {
-				return filepath.SkipDir
-			}
-			return nil
+		if ignoreRules.isIgnored(path) {
+			logrus.Debugf("not planning in the directory %s since it is ignored by the %s files", path, common.IgnoreFilename)
+			skippedDirPaths = append(skippedDirPaths, path)
+			continue
+		}
+		if ignoreRules.isOnlyDirIgnored(path) {
+			continue
 		}
 		common.PlanProgressNumDirectories++
 		logrus.Debugf("Planning in directory %s", path)
@@ -427,7 +515,7 @@ func walkForServices(inputPath string, bservices map[string][]plantypes.PlanArti
 			if config.Spec.DirectoryDetect.Levels == 1 || config.Spec.DirectoryDetect.Levels == 0 {
 				continue
 			}
-			newServicesToArtifacts, err := transformer.DirectoryDetect(env.Encode(path).(string))
+			newServicesToArtifacts, err := cache.directoryDetect(transformer, config, env, path)
 			if err != nil {
 				logrus.Warnf("[%s] directory detect failed. Error: %q", config.Name, err)
 				continue
@@ -443,7 +531,7 @@ func walkForServices(inputPath string, bservices map[string][]plantypes.PlanArti
 					}
 				}
 			}
-			newPlanServices := getPlanArtifactsFromArtifacts(*env.Decode(&newServicesToArtifacts).(*map[string][]transformertypes.Artifact), config)
+			newPlanServices := getPlanArtifactsFromArtifacts(newServicesToArtifacts, config)
 			services = plantypes.MergeServices(services, newPlanServices)
 			logrus.Debugf("[%s] Done", config.Name)
 			numfound += len(newPlanServices)
@@ -459,13 +547,9 @@ func walkForServices(inputPath string, bservices map[string][]plantypes.PlanArti
 			}
 		}
 		logrus.Debugf("planning finished for the directory %s and %d services were detected", path, numfound)
-		if skipThisDir || common.IsPresent(ignoreContents, path) {
-			return filepath.SkipDir
+		if skipThisDir {
+			skippedDirPaths = append(skippedDirPaths, path)
 		}
-		return nil
-	})
-	if err != nil {
-		return services, fmt.Errorf("failed to walk through the directory at path %s . Error: %q", inputPath, err)
 	}
 	return services, nil
 }
@@ -508,10 +592,14 @@ func preprocessArtifact(planArtifact plantypes.PlanArtifact) plantypes.PlanArtif
 	return planArtifact
 }
 
-// Transform transforms as per the plan
-func Transform(planArtifacts []plantypes.PlanArtifact, sourceDir, outputPath string, maxIterations int) error {
+// Transform transforms as per the plan.
+// The transformation stops with the context's error if the context is cancelled.
+// The registered TransformDoneHooks are called once the transformation finishes.
+func Transform(ctx context.Context, planArtifacts []plantypes.PlanArtifact, sourceDir, outputPath string, maxIterations int) (err error) {
 	logrus.Trace("transformer.Transform start")
 	defer logrus.Trace("transformer.Transform end")
+	defer func() { runTransformDoneHooks(outputPath, err) }()
+	resetLoadedIR()
 	var allArtifacts []transformertypes.Artifact
 	newArtifactsToProcess := []transformertypes.Artifact{}
 	pathMappings := []transformertypes.PathMapping{}
@@ -522,7 +610,7 @@ func Transform(planArtifacts []plantypes.PlanArtifact, sourceDir, outputPath str
 	startVertexId := graph.AddVertex("start", iteration, nil)
 	for _, invokedByDefaultTransformer := range invokedByDefaultTransformers {
 		tDefaultConfig, defaultEnv := invokedByDefaultTransformer.GetConfig()
-		newPathMappings, defaultArtifacts, err := runSingleTransform(nil, nil, invokedByDefaultTransformer, tDefaultConfig, defaultEnv, graph, iteration)
+		newPathMappings, defaultArtifacts, err := runSingleTransform(ctx, nil, nil, invokedByDefaultTransformer, tDefaultConfig, defaultEnv, graph, iteration)
 		if err != nil {
 			logrus.Errorf("failed to transform using the transformer %s. Error: %q", tDefaultConfig.Name, err)
 		}
@@ -544,13 +632,16 @@ func Transform(planArtifacts []plantypes.PlanArtifact, sourceDir, outputPath str
 	// logging
 
 	for {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("the transformation was stopped at iteration %d . Error: %w", iteration, err)
+		}
 		iteration++
 		if maxIterations >= 0 && iteration > maxIterations {
 			logrus.Errorf("exceeded the max number of iterations: %d . stopping.", maxIterations)
 			break
 		}
 		logrus.Infof("Iteration %d - %d artifacts to process", iteration, len(newArtifactsToProcess))
-		newPathMappings, newArtifacts, _ := transform(newArtifactsToProcess, allArtifacts, consume, nil, graph, iteration)
+		newPathMappings, newArtifacts, _ := transform(ctx, newArtifactsToProcess, allArtifacts, consume, nil, graph, iteration)
 		pathMappings = append(pathMappings, newPathMappings...)
 		if err := os.RemoveAll(outputPath); err != nil {
 			return fmt.Errorf("failed to remove the output directory '%s' . Error: %w", outputPath, err)
@@ -588,93 +679,163 @@ func Transform(planArtifacts []plantypes.PlanArtifact, sourceDir, outputPath str
 	return nil
 }
 
-func transform(newArtifactsToProcess, allArtifacts []transformertypes.Artifact, pt processType, depSel labels.Selector, graph *graphtypes.Graph, iteration int) (pathMappings []transformertypes.PathMapping, newArtifactsCreated, updatedArtifacts []transformertypes.Artifact) {
+func transform(ctx context.Context, newArtifactsToProcess, allArtifacts []transformertypes.Artifact, pt processType, depSel labels.Selector, graph *graphtypes.Graph, iteration int) (pathMappings []transformertypes.PathMapping, newArtifactsCreated, updatedArtifacts []transformertypes.Artifact) {
 	logrus.Trace("transform start")
 	defer logrus.Trace("transform end")
 	if pt == dependency && (depSel == nil || depSel.String() == "") {
 		return nil, nil, newArtifactsToProcess
 	}
+	if pt == consume && common.MaxParallelTransforms > 1 && !qaengine.IsInteractive() {
+		// in consume mode the transformers don't depend on each other's outputs within an iteration.
+		// The questions are only answered from the config and the defaults, so the answers don't depend on the scheduling.
+		results := transformInParallel(ctx, newArtifactsToProcess, allArtifacts, graph, iteration)
+		for _, result := range results {
+			pathMappings = append(pathMappings, result.pathMappings...)
+			newArtifactsCreated = append(newArtifactsCreated, result.newArtifactsCreated...)
+		}
+		logrus.Debugf("Created %d pathMappings and %d artifacts from transform.", len(pathMappings), len(newArtifactsCreated))
+		return pathMappings, newArtifactsCreated, nil
+	}
 	for _, transformer := range transformers {
-		tConfig, env := transformer.GetConfig()
-		if pt == dependency && !depSel.Matches(labels.Set(tConfig.Labels)) {
-			logrus.Debugf("currently in dependency mode and the dependency selector does not match the transformer named '%s'", tConfig.Name)
-			continue
+		if ctx.Err() != nil {
+			logrus.Debugf("the context was cancelled, not running any more transformers. Error: %q", ctx.Err())
+			break
 		}
-		artifactsToProcess, artifactsToNotProcess := getArtifactsToProcess(newArtifactsToProcess, allArtifacts, tConfig, pt)
-		if len(artifactsToProcess) == 0 {
-			logrus.Debugf("did not find any artifacts for the transformer named '%s' to process", tConfig.Name)
-			continue
+		result := transformUsing(ctx, transformer, newArtifactsToProcess, allArtifacts, pt, depSel, graph, iteration)
+		pathMappings = append(pathMappings, result.pathMappings...)
+		newArtifactsCreated = append(newArtifactsCreated, result.newArtifactsCreated...)
+		updatedArtifacts = append(updatedArtifacts, result.updatedArtifacts...)
+		if result.artifactsToProcess != nil {
+			newArtifactsToProcess = *result.artifactsToProcess
 		}
+	}
+	if pt == passthrough || pt == dependency {
+		logrus.Debugf("Created %d pathMappings, %d artifacts, %d updated artifacts from transform while passing through/dependency.", len(pathMappings), len(newArtifactsCreated), len(newArtifactsToProcess))
+		return pathMappings, newArtifactsCreated, newArtifactsToProcess
+	}
+	logrus.Debugf("Created %d pathMappings and %d artifacts from transform.", len(pathMappings), len(newArtifactsCreated))
+	return pathMappings, newArtifactsCreated, nil
+}
 
-		logrus.Debugf("Transformer '%s' will be processing %d artifacts in %d mode", tConfig.Name, len(artifactsToProcess), pt)
-		// Dependency processing
-		dependencyCreatedNewPathMappings, dependencyCreatedNewArtifacts, dependencyUpdatedArtifacts := transform(artifactsToProcess, allArtifacts, dependency, tConfig.Spec.DependencySelector, graph, iteration)
-		pathMappings = append(pathMappings, dependencyCreatedNewPathMappings...)
-		// Dependency processing
+// transformResult stores the outputs of running a single transformer along with its dependencies and pass throughs
+type transformResult struct {
+	pathMappings        []transformertypes.PathMapping
+	newArtifactsCreated []transformertypes.Artifact
+	updatedArtifacts    []transformertypes.Artifact
+	// artifactsToProcess is the new list of artifacts for the rest of the transformers to process, nil if unchanged
+	artifactsToProcess *[]transformertypes.Artifact
+}
 
-		artifactsToConsume, artifactsToNotConsume := getArtifactsToProcess(dependencyUpdatedArtifacts, allArtifacts, tConfig, pt)
-		if len(artifactsToNotConsume) != 0 {
-			logrus.Errorf("Artifacts to not consume: %d. This should have been 0.", len(artifactsToNotConsume))
+// transformInParallel runs the transformers in consume mode using a bounded pool of workers.
+// Each transformer gets its own copy of the artifacts since merging artifacts modifies their configs.
+// The results are returned in the same order as the transformers so that merging them is deterministic.
+func transformInParallel(ctx context.Context, newArtifactsToProcess, allArtifacts []transformertypes.Artifact, graph *graphtypes.Graph, iteration int) []transformResult {
+	results := make([]transformResult, len(transformers))
+	workers := make(chan struct{}, common.MaxParallelTransforms)
+	wg := sync.WaitGroup{}
+	for i, transformer := range transformers {
+		if ctx.Err() != nil {
+			logrus.Debugf("the context was cancelled, not running any more transformers. Error: %q", ctx.Err())
+			break
 		}
+		workerNewArtifactsToProcess := deepcopy.DeepCopy(newArtifactsToProcess).([]transformertypes.Artifact)
+		workerAllArtifacts := deepcopy.DeepCopy(allArtifacts).([]transformertypes.Artifact)
+		workers <- struct{}{}
+		wg.Add(1)
+		go func(i int, transformer Transformer) {
+			defer wg.Done()
+			defer func() { <-workers }()
+			results[i] = transformUsing(ctx, transformer, workerNewArtifactsToProcess, workerAllArtifacts, consume, nil, graph, iteration)
+		}(i, transformer)
+	}
+	wg.Wait()
+	return results
+}
 
-		logrus.Infof("Transformer '%s' processing %d artifacts", tConfig.Name, len(artifactsToConsume))
-		producedNewPathMappings, producedNewArtifacts, err := runSingleTransform(artifactsToConsume, allArtifacts, transformer, tConfig, env, graph, iteration)
-		if err != nil {
-			logrus.Errorf("failed to run a single transformation using the transformer %+v on the artifacts: %+v", tConfig, artifactsToConsume)
-			logrus.Error(err.Error())
-			continue
-		}
-		pathMappings = append(pathMappings, producedNewPathMappings...)
-		artifactsToPassThrough := []transformertypes.Artifact{}
-		artifactsAlreadyPassedThrough := []transformertypes.Artifact{}
-		if pt == consume {
-			artifactsToPassThrough = append(dependencyCreatedNewArtifacts, producedNewArtifacts...)
-		} else if pt == passthrough || pt == dependency {
-			for _, a := range producedNewArtifacts {
-				if c, ok := tConfig.Spec.ConsumedArtifacts[a.Type]; ok &&
-					(c.Mode != transformertypes.MandatoryPassThrough && c.Mode != transformertypes.OnDemandPassThrough) {
-					artifactsToPassThrough = append(artifactsToPassThrough, a)
-				} else {
-					artifactsAlreadyPassedThrough = append(artifactsAlreadyPassedThrough, a)
-				}
+// transformUsing runs a single transformer on the artifacts it can process, along with its dependencies and pass throughs
+func transformUsing(ctx context.Context, transformer Transformer, newArtifactsToProcess, allArtifacts []transformertypes.Artifact, pt processType, depSel labels.Selector, graph *graphtypes.Graph, iteration int) (result transformResult) {
+	tConfig, env := transformer.GetConfig()
+	if pt == dependency && !depSel.Matches(labels.Set(tConfig.Labels)) {
+		logrus.Debugf("currently in dependency mode and the dependency selector does not match the transformer named '%s'", tConfig.Name)
+		return result
+	}
+	artifactsToProcess, artifactsToNotProcess := getArtifactsToProcess(newArtifactsToProcess, allArtifacts, tConfig, pt)
+	if len(artifactsToProcess) == 0 {
+		logrus.Debugf("did not find any artifacts for the transformer named '%s' to process", tConfig.Name)
+		return result
+	}
+
+	logrus.Debugf("Transformer '%s' will be processing %d artifacts in %d mode", tConfig.Name, len(artifactsToProcess), pt)
+	// Dependency processing
+	dependencyCreatedNewPathMappings, dependencyCreatedNewArtifacts, dependencyUpdatedArtifacts := transform(ctx, artifactsToProcess, allArtifacts, dependency, tConfig.Spec.DependencySelector, graph, iteration)
+	result.pathMappings = append(result.pathMappings, dependencyCreatedNewPathMappings...)
+	// Dependency processing
+
+	artifactsToConsume, artifactsToNotConsume := getArtifactsToProcess(dependencyUpdatedArtifacts, allArtifacts, tConfig, pt)
+	if len(artifactsToNotConsume) != 0 {
+		logrus.Errorf("Artifacts to not consume: %d. This should have been 0.", len(artifactsToNotConsume))
+	}
+
+	logrus.Infof("Transformer '%s' processing %d artifacts", tConfig.Name, len(artifactsToConsume))
+	producedNewPathMappings, producedNewArtifacts, err := runSingleTransform(ctx, artifactsToConsume, allArtifacts, transformer, tConfig, env, graph, iteration)
+	if err != nil {
+		logrus.Errorf("failed to run a single transformation using the transformer %+v on the artifacts: %+v", tConfig, artifactsToConsume)
+		logrus.Error(err.Error())
+		return result
+	}
+	result.pathMappings = append(result.pathMappings, producedNewPathMappings...)
+	artifactsToPassThrough := []transformertypes.Artifact{}
+	artifactsAlreadyPassedThrough := []transformertypes.Artifact{}
+	if pt == consume {
+		artifactsToPassThrough = append(dependencyCreatedNewArtifacts, producedNewArtifacts...)
+	} else if pt == passthrough || pt == dependency {
+		for _, a := range producedNewArtifacts {
+			if c, ok := tConfig.Spec.ConsumedArtifacts[a.Type]; ok &&
+				(c.Mode != transformertypes.MandatoryPassThrough && c.Mode != transformertypes.OnDemandPassThrough) {
+				artifactsToPassThrough = append(artifactsToPassThrough, a)
+			} else {
+				artifactsAlreadyPassedThrough = append(artifactsAlreadyPassedThrough, a)
 			}
 		}
+	}
 
-		passedThroughPathMappings, passedThroughNewArtifactsCreated, passedThroughUpdatedArtifacts := transform(artifactsToPassThrough, allArtifacts, passthrough, nil, graph, iteration)
+	passedThroughPathMappings, passedThroughNewArtifactsCreated, passedThroughUpdatedArtifacts := transform(ctx, artifactsToPassThrough, allArtifacts, passthrough, nil, graph, iteration)
 
-		pathMappings = append(pathMappings, passedThroughPathMappings...)
-		newArtifactsCreated = append(newArtifactsCreated, passedThroughNewArtifactsCreated...)
-		if pt == consume {
-			newArtifactsCreated = append(newArtifactsCreated, passedThroughUpdatedArtifacts...)
-		}
-		updatedArtifacts = append(updatedArtifacts, passedThroughUpdatedArtifacts...)
-		if pt == passthrough || pt == dependency {
-			newArtifactsToProcess = artifactsToNotProcess
-			newArtifactsToProcess = append(newArtifactsToProcess, passedThroughUpdatedArtifacts...)
-			newArtifactsToProcess = append(newArtifactsToProcess, artifactsAlreadyPassedThrough...)
-		}
-		logrus.Infof("Transformer %s Done", tConfig.Name)
+	result.pathMappings = append(result.pathMappings, passedThroughPathMappings...)
+	result.newArtifactsCreated = append(result.newArtifactsCreated, passedThroughNewArtifactsCreated...)
+	if pt == consume {
+		result.newArtifactsCreated = append(result.newArtifactsCreated, passedThroughUpdatedArtifacts...)
 	}
+	result.updatedArtifacts = append(result.updatedArtifacts, passedThroughUpdatedArtifacts...)
 	if pt == passthrough || pt == dependency {
-		logrus.Debugf("Created %d pathMappings, %d artifacts, %d updated artifacts from transform while passing through/dependency.", len(pathMappings), len(newArtifactsCreated), len(newArtifactsToProcess))
-		return pathMappings, newArtifactsCreated, newArtifactsToProcess
+		remainingArtifacts := artifactsToNotProcess
+		remainingArtifacts = append(remainingArtifacts, passedThroughUpdatedArtifacts...)
+		remainingArtifacts = append(remainingArtifacts, artifactsAlreadyPassedThrough...)
+		result.artifactsToProcess = &remainingArtifacts
 	}
-	logrus.Debugf("Created %d pathMappings and %d artifacts from transform.", len(pathMappings), len(newArtifactsCreated))
-	return pathMappings, newArtifactsCreated, nil
+	logrus.Infof("Transformer %s Done", tConfig.Name)
+	return result
 }
 
-func runSingleTransform(artifactsToProcess, allArtifacts []transformertypes.Artifact, transformer Transformer, tconfig transformertypes.Transformer, env *environment.Environment, graph *graphtypes.Graph, iteration int) (newPathMappings []transformertypes.PathMapping, newArtifacts []transformertypes.Artifact, err error) {
+func runSingleTransform(ctx context.Context, artifactsToProcess, allArtifacts []transformertypes.Artifact, transformer Transformer, tconfig transformertypes.Transformer, env *environment.Environment, graph *graphtypes.Graph, iteration int) (newPathMappings []transformertypes.PathMapping, newArtifacts []transformertypes.Artifact, err error) {
 	logrus.Trace("runSingleTransform start")
 	defer logrus.Trace("runSingleTransform end")
+	transformerLock := getTransformerLock(tconfig.Name)
+	transformerLock.Lock()
+	defer transformerLock.Unlock()
 	if err := env.Reset(); err != nil {
 		return nil, nil, fmt.Errorf("failed to reset the environment: %+v Error: %q", env, err)
 	}
-	newPathMappings, newArtifacts, err = transformer.Transform(
-		*env.Encode(&artifactsToProcess).(*[]transformertypes.Artifact),
-		*env.Encode(&allArtifacts).(*[]transformertypes.Artifact),
-	)
+	encodedArtifactsToProcess := *env.Encode(&artifactsToProcess).(*[]transformertypes.Artifact)
+	encodedAllArtifacts := *env.Encode(&allArtifacts).(*[]transformertypes.Artifact)
+	if contextTransformer, ok := transformer.(ContextTransformer); ok {
+		newPathMappings, newArtifacts, err = contextTransformer.TransformWithContext(ctx, encodedArtifactsToProcess, encodedAllArtifacts)
+	} else {
+		newPathMappings, newArtifacts, err = transformer.Transform(encodedArtifactsToProcess, encodedAllArtifacts)
+	}
 	// logging
 	{
+		graphMutex.Lock()
 		vertexName := fmt.Sprintf("iteration: %d\nclass: %s\nname: %s", iteration, tconfig.Spec.Class, tconfig.Name)
 		targetVertexId := graph.AddVertex(
 			vertexName,
@@ -714,6 +875,7 @@ func runSingleTransform(artifactsToProcess, allArtifacts []transformertypes.Arti
 			newArtifact.Configs[graphtypes.GraphSourceVertexKey] = targetVertexId
 			newArtifacts[i] = newArtifact
 		}
+		graphMutex.Unlock()
 	}
 	// logging
 
@@ -735,16 +897,39 @@ func runSingleTransform(artifactsToProcess, allArtifacts []transformertypes.Arti
 		}
 	}
 	newArtifacts = filteredArtifacts
+	if common.DumpIRPath != "" {
+		recordLoadedIRs(tconfig, newArtifacts)
+	}
 	newPathMappings = env.ProcessPathMappings(newPathMappings)
 	newPathMappings = *env.DownloadAndDecode(&newPathMappings, true).(*[]transformertypes.PathMapping)
+	if previewBasePath != "" && len(newPathMappings) != 0 && !approvePathMappings(tconfig.Name, newPathMappings, env.Source) {
+		logrus.Infof("Skipping the files written by the transformer '%s'", tconfig.Name)
+		newPathMappings = nil
+	}
 	if err := processPathMappings(newPathMappings, env.Source, env.Output, false); err != nil {
 		return newPathMappings, newArtifacts, fmt.Errorf("failed to process the path mappings: %+v . Error: %q", newPathMappings, err)
 	}
 	newArtifacts = *env.DownloadAndDecode(&newArtifacts, false).(*[]transformertypes.Artifact)
 	newArtifacts = postProcessArtifacts(newArtifacts, tconfig)
+	newArtifacts = runArtifactGeneratedHooks(tconfig.Name, newArtifacts)
+	for _, newArtifact := range newArtifacts {
+		report.AddGeneratedArtifact(tconfig.Name, newArtifact)
+	}
 	return newPathMappings, newArtifacts, nil
 }
 
+// getTransformerLock returns the lock for the transformer with the given name
+func getTransformerLock(name string) *sync.Mutex {
+	transformerLocksMutex.Lock()
+	defer transformerLocksMutex.Unlock()
+	lock, ok := transformerLocks[name]
+	if !ok {
+		lock = &sync.Mutex{}
+		transformerLocks[name] = lock
+	}
+	return lock
+}
+
 func getArtifactsToProcess(
 	newArtifactsToProcess,
 	allArtifacts []transformertypes.Artifact,
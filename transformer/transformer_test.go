@@ -0,0 +1,415 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	"github.com/konveyor/move2kube/qaengine"
+	environmenttypes "github.com/konveyor/move2kube/types/environment"
+	graphtypes "github.com/konveyor/move2kube/types/graph"
+	qatypes "github.com/konveyor/move2kube/types/qaengine"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+)
+
+type parallelTestTransformer struct {
+	config transformertypes.Transformer
+	env    *environment.Environment
+	delay  time.Duration
+	ask    bool
+}
+
+func (t *parallelTestTransformer) Init(tc transformertypes.Transformer, env *environment.Environment) error {
+	t.config = tc
+	t.env = env
+	return nil
+}
+
+func (t *parallelTestTransformer) GetConfig() (transformertypes.Transformer, *environment.Environment) {
+	return t.config, t.env
+}
+
+func (t *parallelTestTransformer) DirectoryDetect(dir string) (map[string][]transformertypes.Artifact, error) {
+	return nil, nil
+}
+
+func (t *parallelTestTransformer) Transform(newArtifacts, alreadySeenArtifacts []transformertypes.Artifact) ([]transformertypes.PathMapping, []transformertypes.Artifact, error) {
+	time.Sleep(t.delay)
+	if t.ask {
+		qaengine.FetchStringAnswer("move2kube.test."+t.config.Name, "Enter a value", nil, "", nil)
+	}
+	pathMappings := []transformertypes.PathMapping{}
+	for _, newArtifact := range newArtifacts {
+		pathMappings = append(pathMappings, transformertypes.PathMapping{
+			Type:     transformertypes.DefaultPathMappingType,
+			DestPath: t.config.Name + "/" + newArtifact.Name,
+		})
+	}
+	return pathMappings, nil, nil
+}
+
+func TestTransformInParallel(t *testing.T) {
+	oldTempPath := common.TempPath
+	oldMaxParallelTransforms := common.MaxParallelTransforms
+	common.TempPath = t.TempDir()
+	defer func() {
+		Reset()
+		common.TempPath = oldTempPath
+		common.MaxParallelTransforms = oldMaxParallelTransforms
+	}()
+	Reset()
+	for i, name := range []string{"t1", "t2", "t3", "t4", "t5"} {
+		tc := transformertypes.NewTransformer()
+		tc.Name = name
+		tc.Spec.ConsumedArtifacts = map[transformertypes.ArtifactType]transformertypes.ArtifactProcessConfig{"Service": {}}
+		env, err := environment.NewEnvironment(environment.EnvInfo{
+			Name:    name,
+			Source:  t.TempDir(),
+			Output:  t.TempDir(),
+			Context: t.TempDir(),
+			EnvPlatformConfig: environmenttypes.EnvPlatformConfig{
+				Platforms: []string{runtime.GOOS},
+			},
+		}, nil)
+		if err != nil {
+			t.Fatalf("failed to create the environment. Error: %q", err)
+		}
+		transformer := &parallelTestTransformer{delay: time.Duration(5-i) * 10 * time.Millisecond}
+		if err := transformer.Init(tc, env); err != nil {
+			t.Fatalf("failed to initialize the transformer. Error: %q", err)
+		}
+		transformers = append(transformers, transformer)
+	}
+	artifacts := []transformertypes.Artifact{}
+	for _, name := range []string{"svc1", "svc2"} {
+		artifacts = append(artifacts, transformertypes.Artifact{
+			Name:    name,
+			Type:    "Service",
+			Configs: map[transformertypes.ConfigType]interface{}{graphtypes.GraphSourceVertexKey: 0},
+		})
+	}
+	common.MaxParallelTransforms = 1
+	want, _, _ := transform(context.TODO(), artifacts, artifacts, consume, nil, graphtypes.NewGraph(), 2)
+	common.MaxParallelTransforms = 3
+	got, _, _ := transform(context.TODO(), artifacts, artifacts, consume, nil, graphtypes.NewGraph(), 2)
+	if len(want) != 10 {
+		t.Fatalf("expected 10 path mappings from the sequential transform. Actual: %+v", want)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("the parallel transform did not produce the same path mappings as the sequential one. Differences:\n%s", diff)
+	}
+}
+
+type interactiveTestEngine struct {
+	qaengine.DefaultEngine
+}
+
+func (*interactiveTestEngine) IsInteractiveEngine() bool {
+	return true
+}
+
+func TestTransformInParallelInteractive(t *testing.T) {
+	oldTempPath := common.TempPath
+	oldMaxParallelTransforms := common.MaxParallelTransforms
+	common.TempPath = t.TempDir()
+	defer func() {
+		Reset()
+		qaengine.ResetEngines()
+		qaengine.ResetQuestionHooks()
+		common.TempPath = oldTempPath
+		common.MaxParallelTransforms = oldMaxParallelTransforms
+	}()
+	Reset()
+	qaengine.ResetEngines()
+	qaengine.AddEngine(&interactiveTestEngine{})
+	questions := []string{}
+	qaengine.AddQuestionHook(func(prob qatypes.Problem) {
+		questions = append(questions, prob.ID)
+	})
+	names := []string{"t1", "t2", "t3", "t4", "t5"}
+	for i, name := range names {
+		tc := transformertypes.NewTransformer()
+		tc.Name = name
+		tc.Spec.ConsumedArtifacts = map[transformertypes.ArtifactType]transformertypes.ArtifactProcessConfig{"Service": {}}
+		env, err := environment.NewEnvironment(environment.EnvInfo{
+			Name:    name,
+			Source:  t.TempDir(),
+			Output:  t.TempDir(),
+			Context: t.TempDir(),
+			EnvPlatformConfig: environmenttypes.EnvPlatformConfig{
+				Platforms: []string{runtime.GOOS},
+			},
+		}, nil)
+		if err != nil {
+			t.Fatalf("failed to create the environment. Error: %q", err)
+		}
+		transformer := &parallelTestTransformer{delay: time.Duration(5-i) * 10 * time.Millisecond, ask: true}
+		if err := transformer.Init(tc, env); err != nil {
+			t.Fatalf("failed to initialize the transformer. Error: %q", err)
+		}
+		transformers = append(transformers, transformer)
+	}
+	artifacts := []transformertypes.Artifact{{
+		Name:    "svc1",
+		Type:    "Service",
+		Configs: map[transformertypes.ConfigType]interface{}{graphtypes.GraphSourceVertexKey: 0},
+	}}
+	common.MaxParallelTransforms = 3
+	transform(context.TODO(), artifacts, artifacts, consume, nil, graphtypes.NewGraph(), 2)
+	want := []string{}
+	for _, name := range names {
+		want = append(want, "move2kube.test."+name)
+	}
+	if diff := cmp.Diff(want, questions); diff != "" {
+		t.Fatalf("expected the questions to be asked in the order of the transformers. Differences:\n%s", diff)
+	}
+}
+
+type countingDetectTransformer struct {
+	parallelTestTransformer
+	numDetects int
+}
+
+func (t *countingDetectTransformer) DirectoryDetect(dir string) (map[string][]transformertypes.Artifact, error) {
+	t.numDetects++
+	return map[string][]transformertypes.Artifact{"svc1": {{Name: "svc1", Type: "Service"}}}, nil
+}
+
+func TestPlanCache(t *testing.T) {
+	oldTempPath := common.TempPath
+	oldPlanCacheDir := common.PlanCacheDir
+	common.TempPath = t.TempDir()
+	common.PlanCacheDir = t.TempDir()
+	defer func() {
+		common.TempPath = oldTempPath
+		common.PlanCacheDir = oldPlanCacheDir
+	}()
+	sourceDir := t.TempDir()
+	sourceFilePath := filepath.Join(sourceDir, "Dockerfile")
+	if err := os.WriteFile(sourceFilePath, []byte("FROM scratch"), 0644); err != nil {
+		t.Fatalf("failed to write the source file. Error: %q", err)
+	}
+	tc := transformertypes.NewTransformer()
+	tc.Name = "t1"
+	env, err := environment.NewEnvironment(environment.EnvInfo{
+		Name:    tc.Name,
+		Source:  sourceDir,
+		Output:  t.TempDir(),
+		Context: t.TempDir(),
+		EnvPlatformConfig: environmenttypes.EnvPlatformConfig{
+			Platforms: []string{runtime.GOOS},
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("failed to create the environment. Error: %q", err)
+	}
+	defer env.Destroy()
+	transformer := &countingDetectTransformer{}
+	if err := transformer.Init(tc, env); err != nil {
+		t.Fatalf("failed to initialize the transformer. Error: %q", err)
+	}
+	detect := func() map[string][]transformertypes.Artifact {
+		cache := newPlanCache(sourceDir)
+		if cache == nil {
+			t.Fatalf("expected the plan cache to be enabled")
+		}
+		services, err := cache.directoryDetect(transformer, tc, env, sourceDir)
+		if err != nil {
+			t.Fatalf("failed to detect the services. Error: %q", err)
+		}
+		return services
+	}
+	want := detect()
+	if diff := cmp.Diff(want, detect()); diff != "" {
+		t.Fatalf("the cached result is different. Differences:\n%s", diff)
+	}
+	if transformer.numDetects != 1 {
+		t.Fatalf("expected directory detect to run once for an unchanged directory. Actual: %d", transformer.numDetects)
+	}
+	if err := os.WriteFile(sourceFilePath, []byte("FROM alpine"), 0644); err != nil {
+		t.Fatalf("failed to update the source file. Error: %q", err)
+	}
+	detect()
+	if transformer.numDetects != 2 {
+		t.Fatalf("expected directory detect to run again after the directory changed. Actual: %d", transformer.numDetects)
+	}
+}
+
+func TestPlanCacheReferencedPaths(t *testing.T) {
+	oldTempPath := common.TempPath
+	oldPlanCacheDir := common.PlanCacheDir
+	common.TempPath = t.TempDir()
+	common.PlanCacheDir = t.TempDir()
+	defer func() {
+		common.TempPath = oldTempPath
+		common.PlanCacheDir = oldPlanCacheDir
+	}()
+	sourceDir := t.TempDir()
+	appDir := filepath.Join(sourceDir, "app")
+	if err := os.MkdirAll(appDir, 0755); err != nil {
+		t.Fatalf("failed to create the app directory. Error: %q", err)
+	}
+	envFilePath := filepath.Join(sourceDir, "common.env")
+	if err := os.WriteFile(envFilePath, []byte("PORT=8080"), 0644); err != nil {
+		t.Fatalf("failed to write the env file. Error: %q", err)
+	}
+	composeFilePath := filepath.Join(appDir, "docker-compose.yaml")
+	if err := os.WriteFile(composeFilePath, []byte("services:\n  web:\n    env_file: ../common.env\n"), 0644); err != nil {
+		t.Fatalf("failed to write the compose file. Error: %q", err)
+	}
+	tc := transformertypes.NewTransformer()
+	tc.Name = "t1"
+	env, err := environment.NewEnvironment(environment.EnvInfo{
+		Name:    tc.Name,
+		Source:  sourceDir,
+		Output:  t.TempDir(),
+		Context: t.TempDir(),
+		EnvPlatformConfig: environmenttypes.EnvPlatformConfig{
+			Platforms: []string{runtime.GOOS},
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("failed to create the environment. Error: %q", err)
+	}
+	defer env.Destroy()
+	transformer := &countingDetectTransformer{}
+	if err := transformer.Init(tc, env); err != nil {
+		t.Fatalf("failed to initialize the transformer. Error: %q", err)
+	}
+	detect := func() {
+		cache := newPlanCache(sourceDir)
+		if cache == nil {
+			t.Fatalf("expected the plan cache to be enabled")
+		}
+		if _, err := cache.directoryDetect(transformer, tc, env, appDir); err != nil {
+			t.Fatalf("failed to detect the services. Error: %q", err)
+		}
+	}
+	detect()
+	detect()
+	if transformer.numDetects != 1 {
+		t.Fatalf("expected directory detect to run once for an unchanged directory. Actual: %d", transformer.numDetects)
+	}
+	if err := os.WriteFile(envFilePath, []byte("PORT=9090"), 0644); err != nil {
+		t.Fatalf("failed to update the env file. Error: %q", err)
+	}
+	detect()
+	if transformer.numDetects != 2 {
+		t.Fatalf("expected directory detect to run again after the referenced file changed. Actual: %d", transformer.numDetects)
+	}
+	// a directory outside the source directory is not hashed, so the result is not cached
+	if err := os.WriteFile(composeFilePath, []byte("services:\n  web:\n    build: ../../outside\n"), 0644); err != nil {
+		t.Fatalf("failed to update the compose file. Error: %q", err)
+	}
+	if err := os.MkdirAll(filepath.Join(filepath.Dir(sourceDir), "outside"), 0755); err != nil {
+		t.Fatalf("failed to create the outside directory. Error: %q", err)
+	}
+	detect()
+	detect()
+	if transformer.numDetects != 4 {
+		t.Fatalf("expected directory detect to run every time for a directory referencing a directory outside the source directory. Actual: %d", transformer.numDetects)
+	}
+}
+
+func TestListPlanDirectories(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"a/b/c", "a/node_modules/pkg", "d", ".git/objects", "vendor/lib"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatalf("failed to create the directory '%s' . Error: %q", dir, err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, "d", common.IgnoreFilename), []byte("."), 0644); err != nil {
+		t.Fatalf("failed to write the ignore file. Error: %q", err)
+	}
+	oldPlanMaxDepth := common.PlanMaxDepth
+	defer func() { common.PlanMaxDepth = oldPlanMaxDepth }()
+	getPaths := func() []string {
+		dirs, _, err := listPlanDirectories(context.TODO(), root)
+		if err != nil {
+			t.Fatalf("failed to list the directories. Error: %q", err)
+		}
+		paths := []string{}
+		for _, dir := range dirs {
+			relPath, _ := filepath.Rel(root, dir.path)
+			paths = append(paths, relPath)
+		}
+		return paths
+	}
+	common.PlanMaxDepth = -1
+	if diff := cmp.Diff([]string{".", "a", "a/b", "a/b/c", "d"}, getPaths()); diff != "" {
+		t.Fatalf("wrong directories. Differences:\n%s", diff)
+	}
+	common.PlanMaxDepth = 1
+	if diff := cmp.Diff([]string{".", "a", "d"}, getPaths()); diff != "" {
+		t.Fatalf("wrong directories with a max depth. Differences:\n%s", diff)
+	}
+	_, ignoreFilePaths, _ := listPlanDirectories(context.TODO(), root)
+	if diff := cmp.Diff([]string{filepath.Join(root, "d", common.IgnoreFilename)}, ignoreFilePaths); diff != "" {
+		t.Fatalf("wrong ignore files. Differences:\n%s", diff)
+	}
+}
+
+func TestParseIgnoreFiles(t *testing.T) {
+	root := t.TempDir()
+	ignoreFiles := map[string]string{
+		common.IgnoreFilename:                       "# vendored and generated trees\nvendor/\n/generated\n**/testdata\nsrc/*\n!src/app\nbuild/\n",
+		filepath.Join("lib", common.IgnoreFilename): ".\n!build\n",
+	}
+	ignoreFilePaths := []string{}
+	for relPath, contents := range ignoreFiles {
+		ignoreFilePath := filepath.Join(root, relPath)
+		if err := os.MkdirAll(filepath.Dir(ignoreFilePath), 0755); err != nil {
+			t.Fatalf("failed to create the directory for the ignore file. Error: %q", err)
+		}
+		if err := os.WriteFile(ignoreFilePath, []byte(contents), 0644); err != nil {
+			t.Fatalf("failed to write the ignore file. Error: %q", err)
+		}
+		ignoreFilePaths = append(ignoreFilePaths, ignoreFilePath)
+	}
+	rules := parseIgnoreFiles(root, ignoreFilePaths)
+	testcases := map[string]bool{
+		".":            false,
+		"vendor":       true,
+		"a/vendor":     true,
+		"generated":    true,
+		"a/generated":  false,
+		"x/testdata":   true,
+		"x/y/testdata": true,
+		"src":          false,
+		"src/app":      false,
+		"src/other":    true,
+		"lib":          false,
+		"lib/build":    false,
+		"a/build":      true,
+	}
+	for relPath, want := range testcases {
+		if actual := rules.isIgnored(filepath.Join(root, relPath)); actual != want {
+			t.Errorf("wrong result for the directory '%s' . Expected: %t Actual: %t", relPath, want, actual)
+		}
+	}
+	if !rules.isOnlyDirIgnored(filepath.Join(root, "lib")) || rules.isOnlyDirIgnored(filepath.Join(root, "src")) {
+		t.Fatalf("expected only the directory containing the '.' rule to be ignored. Actual: %+v", rules.ignoredDirs)
+	}
+}
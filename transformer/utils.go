@@ -22,8 +22,10 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"sort"
 	"strings"
 
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
 	"github.com/konveyor/move2kube/common"
 	"github.com/konveyor/move2kube/common/deepcopy"
 	plantypes "github.com/konveyor/move2kube/types/plan"
@@ -78,39 +80,86 @@ func getSelectorFromInterface(sel interface{}) (labels.Selector, error) {
 	return nil, nil
 }
 
-func getIgnorePaths(inputPath string) (ignoreDirectories []string, ignoreContents []string) {
-	filePaths, err := common.GetFilesByName(inputPath, []string{common.IgnoreFilename}, nil)
-	if err != nil {
-		logrus.Warnf("failed to fetch .m2kignore files at path '%s' . Error: %q", inputPath, err)
-		return ignoreDirectories, ignoreContents
-	}
+// ignoreRules are the rules from the .m2kignore files found in the source directory.
+// The rules follow the gitignore semantics. A line containing just "." means that the directory
+// containing the .m2kignore file is not planned, while its sub directories still are.
+type ignoreRules struct {
+	rootDir     string
+	matcher     gitignore.Matcher
+	ignoredDirs []string
+}
+
+// parseIgnoreFiles returns the rules from the .m2kignore files inside the root directory
+func parseIgnoreFiles(rootDir string, filePaths []string) ignoreRules {
+	rules := ignoreRules{rootDir: rootDir}
+	// the rules in the nested files take precedence over the ones in their parent directories
+	filePaths = append([]string{}, filePaths...)
+	sort.SliceStable(filePaths, func(i, j int) bool {
+		return strings.Count(filePaths[i], string(os.PathSeparator)) < strings.Count(filePaths[j], string(os.PathSeparator))
+	})
+	patterns := []gitignore.Pattern{}
 	for _, filePath := range filePaths {
+		dir := filepath.Dir(filePath)
+		domain, err := rules.getPathParts(dir)
+		if err != nil {
+			logrus.Warnf("skipping the .m2kignore file at path '%s' since it is outside the source directory. Error: %q", filePath, err)
+			continue
+		}
 		file, err := os.Open(filePath)
 		if err != nil {
 			logrus.Warnf("failed to open the .m2kignore file at path '%s' . Error: %q", filePath, err)
 			continue
 		}
-		defer file.Close()
-
 		scanner := bufio.NewScanner(file)
 		scanner.Split(bufio.ScanLines)
-
 		for scanner.Scan() {
 			line := strings.TrimSpace(scanner.Text())
-			if len(line) == 0 {
+			if len(line) == 0 || strings.HasPrefix(line, "#") {
 				continue
 			}
-			if strings.HasSuffix(line, "*") {
-				line = strings.TrimSuffix(line, "*")
-				path := filepath.Join(filepath.Dir(filePath), line)
-				ignoreContents = append(ignoreContents, path)
-			} else {
-				path := filepath.Join(filepath.Dir(filePath), line)
-				ignoreDirectories = append(ignoreDirectories, path)
+			if line == "." || line == "./" {
+				rules.ignoredDirs = append(rules.ignoredDirs, dir)
+				continue
 			}
+			patterns = append(patterns, gitignore.ParsePattern(line, domain))
 		}
+		file.Close()
+	}
+	rules.matcher = gitignore.NewMatcher(patterns)
+	return rules
+}
+
+// getPathParts returns the components of the path relative to the root directory
+func (rules ignoreRules) getPathParts(path string) ([]string, error) {
+	relPath, err := filepath.Rel(rules.rootDir, path)
+	if err != nil {
+		return nil, err
+	}
+	relPath = filepath.ToSlash(relPath)
+	if relPath == "." {
+		return nil, nil
 	}
-	return ignoreDirectories, ignoreContents
+	if relPath == ".." || strings.HasPrefix(relPath, "../") {
+		return nil, fmt.Errorf("the path '%s' is not inside the directory '%s'", path, rules.rootDir)
+	}
+	return strings.Split(relPath, "/"), nil
+}
+
+// isIgnored returns true if the directory and everything inside it should not be planned
+func (rules ignoreRules) isIgnored(dir string) bool {
+	if rules.matcher == nil {
+		return false
+	}
+	parts, err := rules.getPathParts(dir)
+	if err != nil || len(parts) == 0 {
+		return false
+	}
+	return rules.matcher.Match(parts, true)
+}
+
+// isOnlyDirIgnored returns true if the directory should not be planned, while its sub directories still are
+func (rules ignoreRules) isOnlyDirIgnored(dir string) bool {
+	return common.IsPresent(rules.ignoredDirs, dir)
 }
 
 func updatedArtifacts(alreadySeenArtifacts []transformertypes.Artifact, newArtifacts ...transformertypes.Artifact) (updatedArtifacts []transformertypes.Artifact) {
@@ -260,6 +309,10 @@ func getFilteredTransformers(transformerYamlPaths map[string]string, selector la
 			filteredTransformerConfigs[tc.Name] = tc
 			continue
 		}
+		if common.IsPresent(unsupportedTransformerClasses, tc.Spec.Class) {
+			logrus.Debugf("Ignoring the transformer '%s' since the transformer class '%s' is not supported on this platform", transformerName, tc.Spec.Class)
+			continue
+		}
 		logrus.Errorf("Ignoring the transformer '%s' since the transformer class '%s' was not found", transformerName, tc.Spec.Class)
 	}
 	transformerConfigs := map[string]transformertypes.Transformer{}
@@ -0,0 +1,205 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/sirupsen/logrus"
+)
+
+// planDirectory is a directory found while walking the source directory for planning
+type planDirectory struct {
+	path string
+	// size is the total size of the files directly inside the directory
+	size int64
+	// children are the sub directories in lexical order
+	children []*planDirectory
+	// linkedDirs are the real paths of the root directory and of the symbolic link targets that lead to this directory
+	linkedDirs []string
+}
+
+// vendoredDirNames are the names of the directories containing vendored dependencies, which are not planned in.
+// The transformers still see them when they list the files of a service.
+var vendoredDirNames = []string{"node_modules", "bower_components", "vendor", "__pycache__"}
+
+// isIgnoredDirName returns true if directories with this name should never be planned
+func isIgnoredDirName(name string) bool {
+	if common.IsPresent(vendoredDirNames, name) {
+		return true
+	}
+	for _, dirRegExp := range common.DefaultIgnoreDirRegexps {
+		if dirRegExp.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// listPlanDirectories concurrently lists the directories inside the root directory that are not ignored by default,
+// up to common.PlanMaxDepth levels deep. It also returns the paths of all the .m2kignore files it found.
+// The directories are returned in the same order as filepath.WalkDir would visit them.
+func listPlanDirectories(ctx context.Context, root string) (dirs []*planDirectory, ignoreFilePaths []string, err error) {
+	workers := make(chan struct{}, runtime.NumCPU())
+	mutex := sync.Mutex{}
+	var firstErr error
+	var list func(dir *planDirectory, depth int)
+	list = func(dir *planDirectory, depth int) {
+		if ctx.Err() != nil {
+			return
+		}
+		entries, err := os.ReadDir(dir.path)
+		if err != nil {
+			if dir.path == root {
+				mutex.Lock()
+				firstErr = err
+				mutex.Unlock()
+				return
+			}
+			logrus.Warnf("Skipping path %q due to error. Error: %q", dir.path, err)
+			return
+		}
+		for _, entry := range entries {
+			entryPath := filepath.Join(dir.path, entry.Name())
+			isDir := entry.IsDir()
+			linkedDirs := dir.linkedDirs
+			var linkInfo os.FileInfo
+			if entry.Type()&os.ModeSymlink != 0 {
+				var ok bool
+				if linkInfo, linkedDirs, ok = resolvePlanSymlink(dir, entryPath); !ok {
+					continue
+				}
+				isDir = linkInfo.IsDir()
+			}
+			if !isDir {
+				if entry.Name() == common.IgnoreFilename {
+					mutex.Lock()
+					ignoreFilePaths = append(ignoreFilePaths, entryPath)
+					mutex.Unlock()
+				}
+				if linkInfo != nil {
+					dir.size += linkInfo.Size()
+				} else if info, err := entry.Info(); err == nil {
+					dir.size += info.Size()
+				}
+				continue
+			}
+			if isIgnoredDirName(entry.Name()) {
+				continue
+			}
+			if common.PlanMaxDepth >= 0 && depth+1 > common.PlanMaxDepth {
+				logrus.Debugf("not planning in the directory '%s' since it is deeper than the max depth %d", entryPath, common.PlanMaxDepth)
+				continue
+			}
+			dir.children = append(dir.children, &planDirectory{path: entryPath, linkedDirs: linkedDirs})
+		}
+		wg := sync.WaitGroup{}
+		for _, child := range dir.children {
+			select {
+			case workers <- struct{}{}:
+				wg.Add(1)
+				go func(child *planDirectory) {
+					defer wg.Done()
+					defer func() { <-workers }()
+					list(child, depth+1)
+				}(child)
+			default:
+				// all the workers are busy so list it in this goroutine instead of waiting
+				list(child, depth+1)
+			}
+		}
+		wg.Wait()
+	}
+	if isIgnoredDirName(filepath.Base(root)) {
+		return nil, nil, nil
+	}
+	rootDir := &planDirectory{path: root}
+	if realRoot, err := filepath.EvalSymlinks(root); err == nil {
+		rootDir.linkedDirs = []string{realRoot}
+	}
+	list(rootDir, 0)
+	if firstErr != nil {
+		return nil, nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	var flatten func(dir *planDirectory)
+	flatten = func(dir *planDirectory) {
+		dirs = append(dirs, dir)
+		for _, child := range dir.children {
+			flatten(child)
+		}
+	}
+	flatten(rootDir)
+	sort.Strings(ignoreFilePaths)
+	return dirs, ignoreFilePaths, nil
+}
+
+// applyPlanSizeBudget returns the directories that fit inside the size budget, in order.
+// Directories after the budget is exhausted are not planned.
+func applyPlanSizeBudget(dirs []*planDirectory) []*planDirectory {
+	if common.PlanMaxSizeBytes < 0 {
+		return dirs
+	}
+	total := int64(0)
+	for i, dir := range dirs {
+		total += dir.size
+		if total > common.PlanMaxSizeBytes {
+			logrus.Warnf("The source directory is larger than the size budget of %d bytes. Not planning in %d directories starting from '%s'", common.PlanMaxSizeBytes, len(dirs)-i, dir.path)
+			return dirs[:i]
+		}
+	}
+	return dirs
+}
+
+// resolvePlanSymlink returns the info of the target of the symbolic link inside the directory as per the symlink policy.
+// If the target is a directory, it is added to the linked directories of the directories found under the link.
+func resolvePlanSymlink(dir *planDirectory, linkPath string) (os.FileInfo, []string, bool) {
+	if common.SymlinkPolicy == common.SkipSymlinkPolicy {
+		common.WarnAboutSymlink(linkPath, "Skipping the symbolic link '%s' as per the symlink policy", linkPath)
+		return nil, nil, false
+	}
+	target, err := filepath.EvalSymlinks(linkPath)
+	if err != nil {
+		common.WarnAboutSymlink(linkPath, "Skipping the symbolic link '%s' since its target could not be resolved. Error: %q", linkPath, err)
+		return nil, nil, false
+	}
+	info, err := os.Stat(target)
+	if err != nil {
+		common.WarnAboutSymlink(linkPath, "Skipping the symbolic link '%s' since its target '%s' could not be found. Error: %q", linkPath, target, err)
+		return nil, nil, false
+	}
+	if !info.IsDir() {
+		return info, dir.linkedDirs, true
+	}
+	realDir, err := filepath.EvalSymlinks(dir.path)
+	if err != nil {
+		realDir = dir.path
+	}
+	if common.IsSymlinkLoop(filepath.Join(realDir, filepath.Base(linkPath)), target, dir.linkedDirs...) {
+		common.WarnAboutSymlink(linkPath, "Skipping the symbolic link '%s' since its target '%s' contains the link", linkPath, target)
+		return nil, nil, false
+	}
+	return info, append(append([]string{}, dir.linkedDirs...), target), true
+}
@@ -0,0 +1,96 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package catalog
+
+import (
+	"github.com/konveyor/move2kube/types"
+)
+
+const (
+	// TransformerCatalogKind defines kind of the index of a transformer catalog
+	TransformerCatalogKind types.Kind = "TransformerCatalog"
+	// InstalledTransformersKind defines kind of the file listing the transformers installed from catalogs
+	InstalledTransformersKind types.Kind = "InstalledTransformers"
+)
+
+// TransformerCatalog is the index of the community transformers and customizations that can be installed
+type TransformerCatalog struct {
+	types.TypeMeta   `yaml:",inline"`
+	types.ObjectMeta `yaml:"metadata,omitempty"`
+	Spec             TransformerCatalogSpec `yaml:"spec,omitempty"`
+}
+
+// TransformerCatalogSpec stores the data
+type TransformerCatalogSpec struct {
+	Transformers []CatalogEntry `yaml:"transformers"`
+}
+
+// CatalogEntry describes a transformer in the catalog along with all its published versions
+type CatalogEntry struct {
+	Name        string           `yaml:"name"`
+	Description string           `yaml:"description,omitempty"`
+	Tags        []string         `yaml:"tags,omitempty"`
+	Versions    []CatalogVersion `yaml:"versions"`
+}
+
+// CatalogVersion is a version of a transformer.
+// The source is a git url, an OCI artifact ref, an archive url or a path relative to the index.
+type CatalogVersion struct {
+	Version string `yaml:"version"`
+	Source  string `yaml:"source"`
+}
+
+// InstalledTransformers lists the transformers installed from catalogs
+type InstalledTransformers struct {
+	types.TypeMeta   `yaml:",inline"`
+	types.ObjectMeta `yaml:"metadata,omitempty"`
+	Spec             InstalledTransformersSpec `yaml:"spec,omitempty"`
+}
+
+// InstalledTransformersSpec stores the data
+type InstalledTransformersSpec struct {
+	Transformers []InstalledTransformer `yaml:"transformers"`
+}
+
+// InstalledTransformer records the version of a transformer installed from a catalog and where it came from
+type InstalledTransformer struct {
+	Name        string `yaml:"name"`
+	Version     string `yaml:"version"`
+	Source      string `yaml:"source"`
+	Catalog     string `yaml:"catalog"`
+	InstalledAt string `yaml:"installedAt"`
+}
+
+// NewTransformerCatalog creates a new instance of TransformerCatalog
+func NewTransformerCatalog() TransformerCatalog {
+	return TransformerCatalog{
+		TypeMeta: types.TypeMeta{
+			Kind:       string(TransformerCatalogKind),
+			APIVersion: types.SchemeGroupVersion.String(),
+		},
+	}
+}
+
+// NewInstalledTransformers creates a new instance of InstalledTransformers
+func NewInstalledTransformers() InstalledTransformers {
+	return InstalledTransformers{
+		TypeMeta: types.TypeMeta{
+			Kind:       string(InstalledTransformersKind),
+			APIVersion: types.SchemeGroupVersion.String(),
+		},
+	}
+}
@@ -0,0 +1,54 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package collection
+
+import (
+	"github.com/konveyor/move2kube/types"
+)
+
+// CollectBundleKind defines kind of the manifest of a collect bundle
+const CollectBundleKind types.Kind = "CollectBundle"
+
+// CollectBundle lists the files of a portable bundle of the collect output, along with their checksums
+type CollectBundle struct {
+	types.TypeMeta   `yaml:",inline"`
+	types.ObjectMeta `yaml:"metadata,omitempty"`
+	Spec             CollectBundleSpec `yaml:"spec,omitempty"`
+}
+
+// CollectBundleSpec stores the data
+type CollectBundleSpec struct {
+	CreatedAt        string              `yaml:"createdAt"`
+	Move2KubeVersion string              `yaml:"move2kubeVersion,omitempty"`
+	Files            []CollectBundleFile `yaml:"files"`
+}
+
+// CollectBundleFile stores the path of a file relative to the collect output directory and its sha256 checksum
+type CollectBundleFile struct {
+	Path   string `yaml:"path"`
+	SHA256 string `yaml:"sha256"`
+}
+
+// NewCollectBundle creates a new instance of CollectBundle
+func NewCollectBundle() CollectBundle {
+	return CollectBundle{
+		TypeMeta: types.TypeMeta{
+			Kind:       string(CollectBundleKind),
+			APIVersion: types.SchemeGroupVersion.String(),
+		},
+	}
+}
@@ -34,7 +34,21 @@ type CfServices struct {
 
 // CfServicesSpec stores the data
 type CfServicesSpec struct {
-	CfServices []cfclient.Service `yaml:"services"`
+	CfServices      []cfclient.Service `yaml:"services"`
+	ServiceBindings []CfServiceBinding `yaml:"serviceBindings,omitempty"`
+}
+
+// CfServiceBinding stores a service instance bound to an app, with the fields the app sees in VCAP_SERVICES.
+// The bindings can also be written by hand, from an export of the VCAP_SERVICES of the app, when they cannot be collected.
+type CfServiceBinding struct {
+	AppName      string                 `yaml:"appName,omitempty" json:"-"`
+	Name         string                 `yaml:"name" json:"name"`
+	InstanceName string                 `yaml:"instanceName,omitempty" json:"instance_name,omitempty"`
+	BindingName  string                 `yaml:"bindingName,omitempty" json:"binding_name,omitempty"`
+	Label        string                 `yaml:"label" json:"label"`
+	Plan         string                 `yaml:"plan,omitempty" json:"plan,omitempty"`
+	Tags         []string               `yaml:"tags,omitempty" json:"tags"`
+	Credentials  map[string]interface{} `yaml:"credentials,omitempty" json:"credentials"`
 }
 
 // NewCfServices creates a new instance of CfServices
@@ -25,12 +25,32 @@ import (
 // ClusterMetadataKind defines the kind of cluster metadata file
 const ClusterMetadataKind types.Kind = "ClusterMetadata"
 
+// CollectOutputDirName is the name of the directory where move2kube collect writes its output
+const CollectOutputDirName = types.AppNameShort + "_collect"
+
+// ClustersDirName is the name of the sub directory of the collect output that contains the cluster metadata
+const ClustersDirName = "clusters"
+
 // ClusterQaLabelKey is the keyname for the clusterqalabel Key
 const ClusterQaLabelKey = types.GroupName + "/clusterqalabel"
 
 // DefaultClusterSpecificQaLabel defines the default storage QA label to be used in the absence of any user-defined name
 const DefaultClusterSpecificQaLabel = "default"
 
+// PodSecurityPolicyPrefix, KyvernoPolicyPrefix and GatekeeperPolicyPrefix are the prefixes of the names of the admission policies restricting the capabilities of the containers
+const (
+	PodSecurityPolicyPrefix = "pod-security/"
+	KyvernoPolicyPrefix     = "kyverno/"
+	GatekeeperPolicyPrefix  = "gatekeeper/"
+)
+
+var (
+	// PodSecurityBaselineCapabilities are the capabilities the baseline Pod Security Standard allows to be added
+	PodSecurityBaselineCapabilities = []string{"AUDIT_WRITE", "CHOWN", "DAC_OVERRIDE", "FOWNER", "FSETID", "KILL", "MKNOD", "NET_BIND_SERVICE", "SETFCAP", "SETGID", "SETPCAP", "SETUID", "SYS_CHROOT"}
+	// PodSecurityRestrictedCapabilities are the capabilities the restricted Pod Security Standard allows to be added
+	PodSecurityRestrictedCapabilities = []string{"NET_BIND_SERVICE"}
+)
+
 // ClusterMetadata for collect output
 type ClusterMetadata struct {
 	types.TypeMeta   `yaml:",inline"`
@@ -78,6 +98,28 @@ type ClusterMetadataSpec struct {
 	StorageClasses    []string            `yaml:"storageClasses"`
 	APIKindVersionMap map[string][]string `yaml:"apiKindVersionMap"` //[kubernetes kind]["gv1", "gv2",...,"gvn"] prioritized group-version
 	Host              string              `yaml:"host,omitempty"`    // Optional field, either collected with move2kube collect or by asking the user.
+	// KubernetesVersion is the version of the Kubernetes api server in the cluster
+	KubernetesVersion string `yaml:"kubernetesVersion,omitempty"`
+	// APIGroups contains all the api groups in the cluster, including the ones added by custom resource definitions
+	APIGroups []string `yaml:"apiGroups,omitempty"`
+	// DefaultStorageClass is the storage class used by claims that do not specify one
+	DefaultStorageClass string `yaml:"defaultStorageClass,omitempty"`
+	// StorageClassAccessModes contains the access modes supported by the storage classes, for the storage classes whose provisioner is known
+	StorageClassAccessModes map[string][]string `yaml:"storageClassAccessModes,omitempty"`
+	// IngressClasses contains the ingress classes available in the cluster
+	IngressClasses []string `yaml:"ingressClasses,omitempty"`
+	// DefaultIngressClass is the ingress class used by ingresses that do not specify one
+	DefaultIngressClass string `yaml:"defaultIngressClass,omitempty"`
+	// LoadBalancerSupported is true if the cluster provisions load balancers for Services of type LoadBalancer. It is nil if it is not known.
+	LoadBalancerSupported *bool `yaml:"loadBalancerSupported,omitempty"`
+	// SecurityContextConstraints contains the OpenShift security context constraints that the workloads in the target namespace can use
+	SecurityContextConstraints []string `yaml:"securityContextConstraints,omitempty"`
+	// PriorityClasses contains the priority classes available in the cluster, except the ones reserved for the system
+	PriorityClasses []string `yaml:"priorityClasses,omitempty"`
+	// PodSecurityLevel is the Pod Security Standard level enforced in the target namespace, if any
+	PodSecurityLevel string `yaml:"podSecurityLevel,omitempty"`
+	// CapabilityPolicies maps the Kyverno and Gatekeeper policies restricting the capabilities added to the containers to the capabilities they allow
+	CapabilityPolicies map[string][]string `yaml:"capabilityPolicies,omitempty"`
 }
 
 // Merge helps merge clustermetadata
@@ -102,6 +144,102 @@ func (c *ClusterMetadataSpec) Merge(newc ClusterMetadataSpec) bool {
 	}
 	c.APIKindVersionMap = apiversionkindmap
 	c.Host = newc.Host
+	if len(newc.APIGroups) != 0 {
+		c.APIGroups = newc.APIGroups
+	}
+	if newc.DefaultStorageClass != "" && common.IsPresent(c.StorageClasses, newc.DefaultStorageClass) {
+		c.DefaultStorageClass = newc.DefaultStorageClass
+	} else if !common.IsPresent(c.StorageClasses, c.DefaultStorageClass) {
+		c.DefaultStorageClass = ""
+	}
+	var storageClassAccessModes map[string][]string
+	for _, sc := range c.StorageClasses {
+		accessModes, ok := newc.StorageClassAccessModes[sc]
+		if !ok {
+			accessModes, ok = c.StorageClassAccessModes[sc]
+		}
+		if !ok {
+			continue
+		}
+		if storageClassAccessModes == nil {
+			storageClassAccessModes = map[string][]string{}
+		}
+		storageClassAccessModes[sc] = accessModes
+	}
+	c.StorageClassAccessModes = storageClassAccessModes
+	if newc.KubernetesVersion != "" {
+		c.KubernetesVersion = newc.KubernetesVersion
+		c.IngressClasses = newc.IngressClasses
+		c.DefaultIngressClass = newc.DefaultIngressClass
+		c.LoadBalancerSupported = newc.LoadBalancerSupported
+		c.SecurityContextConstraints = newc.SecurityContextConstraints
+		c.PriorityClasses = newc.PriorityClasses
+		c.PodSecurityLevel = newc.PodSecurityLevel
+		c.CapabilityPolicies = newc.CapabilityPolicies
+	}
+	return true
+}
+
+// IsCollected returns true if the capabilities of the cluster were collected using move2kube collect
+func (c *ClusterMetadataSpec) IsCollected() bool {
+	return c.KubernetesVersion != ""
+}
+
+// SupportsIngress returns true if the cluster can expose services using an Ingress or a Route.
+// The cluster is assumed to support it if its capabilities were not collected.
+func (c *ClusterMetadataSpec) SupportsIngress() bool {
+	if !c.IsCollected() {
+		return true
+	}
+	if c.GetSupportedVersions("Route") != nil {
+		return true
+	}
+	if c.GetSupportedVersions(common.IngressKind) == nil {
+		return false
+	}
+	// an Ingress needs an ingress controller, which registers an ingress class
+	return c.GetSupportedVersions("IngressClass") == nil || len(c.IngressClasses) != 0
+}
+
+// SupportsLoadBalancer returns true if the cluster provisions load balancers for Services of type LoadBalancer.
+// The cluster is assumed to support it if it is not known.
+func (c *ClusterMetadataSpec) SupportsLoadBalancer() bool {
+	return c.LoadBalancerSupported == nil || *c.LoadBalancerSupported
+}
+
+// UsesSecurityContextConstraints returns true if the pods in the cluster are admitted using OpenShift security context constraints
+func (c *ClusterMetadataSpec) UsesSecurityContextConstraints() bool {
+	return c.GetSupportedVersions("SecurityContextConstraints") != nil
+}
+
+// GetCapabilityPolicies returns the admission policies of the cluster restricting the capabilities added to the containers,
+// along with the capabilities each of them allows. The Pod Security Standard enforced in the target namespace is one of them.
+func (c *ClusterMetadataSpec) GetCapabilityPolicies() map[string][]string {
+	policies := map[string][]string{}
+	switch c.PodSecurityLevel {
+	case "baseline":
+		policies[PodSecurityPolicyPrefix+c.PodSecurityLevel] = PodSecurityBaselineCapabilities
+	case "restricted":
+		policies[PodSecurityPolicyPrefix+c.PodSecurityLevel] = PodSecurityRestrictedCapabilities
+	}
+	for name, capabilities := range c.CapabilityPolicies {
+		policies[name] = capabilities
+	}
+	return policies
+}
+
+// SupportsAccessModes returns true if the storage class supports all the access modes.
+// The access modes are assumed to be supported if they are not known for the storage class.
+func (c *ClusterMetadataSpec) SupportsAccessModes(storageClass string, accessModes []string) bool {
+	supportedAccessModes, ok := c.StorageClassAccessModes[storageClass]
+	if !ok {
+		return true
+	}
+	for _, accessMode := range accessModes {
+		if !common.IsPresent(supportedAccessModes, accessMode) {
+			return false
+		}
+	}
 	return true
 }
 
@@ -139,3 +139,51 @@ func TestNewClusterMetadata(t *testing.T) {
 		t.Fatal("Failed to initialize ClusterMetadata properly.")
 	}
 }
+
+func TestSupportsIngress(t *testing.T) {
+	t.Run("assume ingress support when the capabilities were not collected", func(t *testing.T) {
+		cmeta := collection.NewClusterMetadata("ctxname1")
+		if !cmeta.Spec.SupportsIngress() {
+			t.Fatal("Expected the cluster to support ingress")
+		}
+	})
+
+	t.Run("no ingress support when the cluster has no ingress classes", func(t *testing.T) {
+		cmeta := collection.NewClusterMetadata("ctxname1")
+		cmeta.Spec.KubernetesVersion = "v1.27.3"
+		cmeta.Spec.APIKindVersionMap = map[string][]string{"Ingress": {"networking.k8s.io/v1"}, "IngressClass": {"networking.k8s.io/v1"}}
+		if cmeta.Spec.SupportsIngress() {
+			t.Fatal("Expected the cluster to not support ingress")
+		}
+		cmeta.Spec.IngressClasses = []string{"nginx"}
+		if !cmeta.Spec.SupportsIngress() {
+			t.Fatal("Expected the cluster to support ingress")
+		}
+	})
+
+	t.Run("ingress support when the cluster has routes", func(t *testing.T) {
+		cmeta := collection.NewClusterMetadata("ctxname1")
+		cmeta.Spec.KubernetesVersion = "v1.27.3"
+		cmeta.Spec.APIKindVersionMap = map[string][]string{"Route": {"route.openshift.io/v1"}}
+		if !cmeta.Spec.SupportsIngress() {
+			t.Fatal("Expected the cluster to support ingress")
+		}
+	})
+}
+
+func TestSupportsLoadBalancer(t *testing.T) {
+	cmeta := collection.NewClusterMetadata("ctxname1")
+	if !cmeta.Spec.SupportsLoadBalancer() {
+		t.Fatal("Expected the cluster to support load balancers when it is not known")
+	}
+	supported := false
+	cmeta.Spec.LoadBalancerSupported = &supported
+	if cmeta.Spec.SupportsLoadBalancer() {
+		t.Fatal("Expected the cluster to not support load balancers")
+	}
+	newcmeta := collection.NewClusterMetadata("ctxname1")
+	newcmeta.Spec.KubernetesVersion = "v1.27.3"
+	if !cmeta.Merge(newcmeta) || !cmeta.Spec.SupportsLoadBalancer() {
+		t.Fatal("Expected the load balancer support of the newly collected cluster to be used")
+	}
+}
@@ -0,0 +1,82 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package collection
+
+import (
+	"github.com/konveyor/move2kube/types"
+)
+
+// DockerContainersMetadataKind defines kind of the docker containers file
+const DockerContainersMetadataKind types.Kind = "DockerContainers"
+
+// DockerContainers stores the data about the containers running in a docker daemon
+type DockerContainers struct {
+	types.TypeMeta   `yaml:",inline"`
+	types.ObjectMeta `yaml:"metadata,omitempty"`
+	Spec             DockerContainersSpec `yaml:"spec,omitempty"`
+}
+
+// DockerContainersSpec stores the data
+type DockerContainersSpec struct {
+	Containers []DockerContainer `yaml:"containers"`
+}
+
+// DockerContainer stores the data about a running container
+type DockerContainer struct {
+	Name          string                 `yaml:"name"`
+	Image         string                 `yaml:"image"`
+	Entrypoint    []string               `yaml:"entrypoint,omitempty"`
+	Cmd           []string               `yaml:"cmd,omitempty"`
+	Env           []string               `yaml:"env,omitempty"`
+	WorkingDir    string                 `yaml:"workingDir,omitempty"`
+	User          string                 `yaml:"user,omitempty"`
+	Hostname      string                 `yaml:"hostname,omitempty"`
+	Labels        map[string]string      `yaml:"labels,omitempty"`
+	Ports         []DockerContainerPort  `yaml:"ports,omitempty"`
+	Mounts        []DockerContainerMount `yaml:"mounts,omitempty"`
+	RestartPolicy string                 `yaml:"restartPolicy,omitempty"`
+	Networks      []string               `yaml:"networks,omitempty"`
+	Privileged    bool                   `yaml:"privileged,omitempty"`
+	CapAdd        []string               `yaml:"capAdd,omitempty"`
+	CapDrop       []string               `yaml:"capDrop,omitempty"`
+}
+
+// DockerContainerPort stores a port exposed by the container and the host port it is published on
+type DockerContainerPort struct {
+	ContainerPort int32  `yaml:"containerPort"`
+	HostPort      int32  `yaml:"hostPort,omitempty"`
+	Protocol      string `yaml:"protocol,omitempty"`
+}
+
+// DockerContainerMount stores a volume, bind mount or tmpfs mounted into the container
+type DockerContainerMount struct {
+	Type        string `yaml:"type"`
+	Name        string `yaml:"name,omitempty"`
+	Source      string `yaml:"source,omitempty"`
+	Destination string `yaml:"destination"`
+	ReadOnly    bool   `yaml:"readOnly,omitempty"`
+}
+
+// NewDockerContainers creates a new instance of DockerContainers
+func NewDockerContainers() DockerContainers {
+	return DockerContainers{
+		TypeMeta: types.TypeMeta{
+			Kind:       string(DockerContainersMetadataKind),
+			APIVersion: types.SchemeGroupVersion.String(),
+		},
+	}
+}
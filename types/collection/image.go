@@ -46,6 +46,10 @@ type ImageInfoSpec struct {
 	PortsToExpose []int32  `yaml:"ports"`
 	AccessedDirs  []string `yaml:"accessedDirs"`
 	UserID        int      `yaml:"userID"`
+	User          string   `yaml:"user,omitempty"`
+	Entrypoint    []string `yaml:"entrypoint,omitempty"`
+	Cmd           []string `yaml:"cmd,omitempty"`
+	Env           []string `yaml:"env,omitempty"`
 
 	Created string            `json:"created,omitempty" yaml:"created,omitempty"`
 	Params  map[string]string `json:"params,omitempty" yaml:"params,omitempty"`
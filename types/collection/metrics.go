@@ -0,0 +1,97 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package collection
+
+import (
+	"github.com/konveyor/move2kube/types"
+)
+
+// ResourceMetricsKind defines kind of the resource metrics file
+const ResourceMetricsKind types.Kind = "ResourceMetrics"
+
+const (
+	// DockerMetricsSource is the source of the metrics sampled from the containers running in a docker daemon
+	DockerMetricsSource = "docker"
+	// KubernetesMetricsSource is the source of the metrics sampled from the metrics server of a Kubernetes cluster
+	KubernetesMetricsSource = "kubernetes"
+)
+
+// ResourceMetrics stores the resource usage observed for the workloads of a source
+type ResourceMetrics struct {
+	types.TypeMeta   `yaml:",inline"`
+	types.ObjectMeta `yaml:"metadata,omitempty"`
+	Spec             ResourceMetricsSpec `yaml:"spec,omitempty"`
+}
+
+// ResourceMetricsSpec stores the data
+type ResourceMetricsSpec struct {
+	Source     string             `yaml:"source"`
+	Namespace  string             `yaml:"namespace,omitempty"`
+	Samples    int                `yaml:"samples"`
+	Containers []ContainerMetrics `yaml:"containers"`
+}
+
+// ContainerMetrics stores the resource usage of a single container of a workload
+type ContainerMetrics struct {
+	// Workload is the compose service, deployment, statefulset or daemonset the container belongs to
+	Workload  string `yaml:"workload"`
+	Container string `yaml:"container"`
+	// CPU is in millicores
+	CPU UsageMetrics `yaml:"cpu"`
+	// Memory is in bytes
+	Memory UsageMetrics `yaml:"memory"`
+}
+
+// UsageMetrics stores the average and the peak of the sampled usage
+type UsageMetrics struct {
+	Average int64 `yaml:"average"`
+	Peak    int64 `yaml:"peak"`
+}
+
+// GetContainerMetrics returns the metrics of the container with the given workload and container name.
+// An empty workload or container name matches any. When several containers match, like the replicas of
+// a scaled service, the one with the highest peak memory usage is returned.
+func (r *ResourceMetrics) GetContainerMetrics(workload, container string) (ContainerMetrics, bool) {
+	found := false
+	metrics := ContainerMetrics{}
+	for _, containerMetrics := range r.Spec.Containers {
+		if (workload != "" && containerMetrics.Workload != workload) || (container != "" && containerMetrics.Container != container) {
+			continue
+		}
+		if !found || containerMetrics.Memory.Peak > metrics.Memory.Peak {
+			metrics = containerMetrics
+			found = true
+		}
+	}
+	return metrics, found
+}
+
+// NewResourceMetrics creates a new instance of ResourceMetrics
+func NewResourceMetrics(source string) ResourceMetrics {
+	return ResourceMetrics{
+		TypeMeta: types.TypeMeta{
+			Kind:       string(ResourceMetricsKind),
+			APIVersion: types.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: types.ObjectMeta{
+			Name: source,
+		},
+		Spec: ResourceMetricsSpec{
+			Source: source,
+		},
+	}
+}
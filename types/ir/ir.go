@@ -19,6 +19,7 @@ package ir
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/konveyor/move2kube/common"
@@ -68,6 +69,17 @@ const (
 	DeploymentTypeArgoRollout DeploymentType = "ArgoRollout"
 )
 
+const (
+	// JavaLanguage is the language of the services running JVM apps
+	JavaLanguage = "java"
+	// NodejsLanguage is the language of the services running Node.js apps
+	NodejsLanguage = "nodejs"
+	// PythonLanguage is the language of the services running Python apps
+	PythonLanguage = "python"
+	// DotnetLanguage is the language of the services running .NET apps
+	DotnetLanguage = "dotnet"
+)
+
 // IRArtifactType represents artifact type of IR
 const IRArtifactType transformertypes.ArtifactType = "IR"
 
@@ -76,6 +88,7 @@ const IRConfigType transformertypes.ConfigType = "IR"
 
 // IR is the intermediate representation filled by source transformers
 type IR struct {
+	SchemaVersion   string // Optional field with the version of the IR schema, empty means the current version
 	Name            string
 	ContainerImages map[string]ContainerImage // [imageName]
 	Services        map[string]Service
@@ -127,6 +140,12 @@ type Service struct {
 	OnlyIngress                 bool
 	Daemon                      bool           //Gets converted to DaemonSet
 	DeploymentType              DeploymentType // The type of Deployment this service gets converted to (Rollout/StatefulSet/Deployment)
+	Language                    string         // Optional field with the language of the app run by the service (java/nodejs/python/dotnet)
+	LoggingDriver               string         // Optional field with the logging driver of the service in the source, e.g. fluentd
+	SourceFiles                 []string       // Optional field with the source files the service was converted from, relative to the source directory
+	SourceTransformer           string         // Optional field with the name of the transformer that converted the source files
+	Project                     string         // Optional field with the name of the project the service belongs to, like the compose project
+	PolicyExemptions            []string       // Optional field with the admission policies the pods of the service are exempted from, like kyverno/disallow-capabilities
 }
 
 // ServiceToPodPortForwarding forwards a k8s service port to a k8s pod port
@@ -135,7 +154,10 @@ type ServiceToPodPortForwarding struct {
 	PodPort        networking.ServiceBackendPort
 	ServiceRelPath string
 	ServiceType    core.ServiceType
-	Headless       bool // Whether this port forwarding is for a headless service
+	Headless       bool          // Whether this port forwarding is for a headless service
+	Protocol       core.Protocol // The protocol of the port, TCP if empty
+	Ephemeral      bool          // Whether the port is published on a random host port in the source
+	PortForward    bool          // Whether the port is accessed locally using port forwarding
 }
 
 // ContainerImage defines images that need to be built or reused.
@@ -151,6 +173,11 @@ type ContainerBuild struct {
 	ContainerBuildType ContainerBuildTypeValue                      `yaml:"-"`
 	ContextPath        string                                       `yaml:"-"`
 	Artifacts          map[ContainerBuildArtifactTypeValue][]string `yaml:"-"` //[artifacttype]value
+	// BuildArgs, Target and Labels are the options of the build, like the ones in the build section of a compose service.
+	// A build arg without a value is taken from the environment of the build.
+	BuildArgs map[string]*string `yaml:"-"`
+	Target    string             `yaml:"-"`
+	Labels    map[string]string  `yaml:"-"`
 }
 
 // StorageKindType defines storage type kind
@@ -194,10 +221,21 @@ func (ir *IR) AddService(service Service) {
 	}
 }
 
+// GetSortedServiceNames returns the names of the services in sorted order
+func (ir *IR) GetSortedServiceNames() []string {
+	serviceNames := make([]string, 0, len(ir.Services))
+	for serviceName := range ir.Services {
+		serviceNames = append(serviceNames, serviceName)
+	}
+	sort.Strings(serviceNames)
+	return serviceNames
+}
+
 // GetAllServicePorts returns all ports with a serviceport mapping
 func (ir *IR) GetAllServicePorts() []int32 {
 	ports := []int32{}
-	for _, s := range ir.Services {
+	for _, serviceName := range ir.GetSortedServiceNames() {
+		s := ir.Services[serviceName]
 		for _, pf := range s.ServiceToPodPortForwardings {
 			ports = append(ports, pf.PodPort.Number)
 		}
@@ -246,30 +284,56 @@ func (service *Service) merge(nService Service) {
 		service.Replicas = nService.Replicas
 	}
 	service.Networks = common.MergeSlices(service.Networks, nService.Networks)
+	if nService.Language != "" {
+		service.Language = nService.Language
+	}
+	if nService.LoggingDriver != "" {
+		service.LoggingDriver = nService.LoggingDriver
+	}
+	service.SourceFiles = common.MergeSlices(service.SourceFiles, nService.SourceFiles)
+	if nService.SourceTransformer != "" {
+		service.SourceTransformer = nService.SourceTransformer
+	}
+	if nService.Project != "" {
+		service.Project = nService.Project
+	}
+	service.PolicyExemptions = common.MergeSlices(service.PolicyExemptions, nService.PolicyExemptions)
 	service.OnlyIngress = service.OnlyIngress && nService.OnlyIngress
 	service.Daemon = service.Daemon && nService.Daemon
 	for _, pf := range nService.ServiceToPodPortForwardings {
-		service.AddPortForwarding(pf.ServicePort, pf.PodPort, pf.ServiceRelPath)
+		service.MergePortForwarding(pf)
 	}
 }
 
-// AddPortForwarding adds a new port forwarding to the service.
+// AddPortForwarding adds a new TCP port forwarding to the service.
 func (service *Service) AddPortForwarding(servicePort networking.ServiceBackendPort, podPort networking.ServiceBackendPort, relPath string) error {
+	return service.AddPortForwardingWithProtocol(servicePort, podPort, relPath, "")
+}
+
+// AddPortForwardingWithProtocol adds a new port forwarding with the given protocol to the service.
+// The same port number can be forwarded once for each protocol.
+func (service *Service) AddPortForwardingWithProtocol(servicePort networking.ServiceBackendPort, podPort networking.ServiceBackendPort, relPath string, protocol core.Protocol) error {
 	if podPort.Number == 0 || servicePort.Number == 0 {
 		return fmt.Errorf("PodPort or ServicePort can not be 0")
 	}
+	if protocol == core.ProtocolTCP {
+		protocol = ""
+	}
 	for _, forwarding := range service.ServiceToPodPortForwardings {
 		if servicePort.Name != "" && forwarding.ServicePort.Name == servicePort.Name {
 			err := fmt.Errorf("the port name %s on %s service is already in use. Not adding the new forwarding", servicePort.Name, service.Name)
 			return err
 		}
-		if forwarding.ServicePort.Number == servicePort.Number {
+		if forwarding.ServicePort.Number == servicePort.Number && forwarding.GetProtocol() == protocol {
 			err := fmt.Errorf("the port number %d on %s service is already in use. Not adding the new forwarding", servicePort.Number, service.Name)
 			return err
 		}
 	}
-	newForwarding := ServiceToPodPortForwarding{ServicePort: servicePort, PodPort: podPort, ServiceRelPath: relPath}
+	newForwarding := ServiceToPodPortForwarding{ServicePort: servicePort, PodPort: podPort, ServiceRelPath: relPath, Protocol: protocol}
 	for _, pf := range service.ServiceToPodPortForwardings {
+		if pf.GetProtocol() != protocol {
+			continue
+		}
 		if pf.PodPort == newForwarding.PodPort || pf.ServicePort == newForwarding.ServicePort {
 			return fmt.Errorf("mapping exists for port %v:%v in service %s. Ignoring", pf.PodPort, pf.ServicePort, service.Name)
 		}
@@ -279,6 +343,24 @@ func (service *Service) AddPortForwarding(servicePort networking.ServiceBackendP
 	return nil
 }
 
+// MergePortForwarding adds the port forwarding to the service along with all its settings, unless it conflicts with an existing one.
+func (service *Service) MergePortForwarding(forwarding ServiceToPodPortForwarding) error {
+	if err := service.AddPortForwardingWithProtocol(forwarding.ServicePort, forwarding.PodPort, forwarding.ServiceRelPath, forwarding.Protocol); err != nil {
+		return err
+	}
+	forwarding.Protocol = forwarding.GetProtocol()
+	service.ServiceToPodPortForwardings[len(service.ServiceToPodPortForwardings)-1] = forwarding
+	return nil
+}
+
+// GetProtocol returns the protocol of the port forwarding, with TCP normalized to empty
+func (forwarding ServiceToPodPortForwarding) GetProtocol() core.Protocol {
+	if forwarding.Protocol == core.ProtocolTCP {
+		return ""
+	}
+	return forwarding.Protocol
+}
+
 // AddVolume adds a volume to a service
 func (service *Service) AddVolume(volume core.Volume) {
 	merged := false
@@ -336,6 +418,25 @@ func (c *ContainerBuild) Merge(newc ContainerBuild) bool {
 	if c.ContextPath == "" {
 		c.ContextPath = newc.ContextPath
 	}
+	if c.Target == "" {
+		c.Target = newc.Target
+	}
+	for k, v := range newc.BuildArgs {
+		if c.BuildArgs == nil {
+			c.BuildArgs = map[string]*string{}
+		}
+		if _, ok := c.BuildArgs[k]; !ok {
+			c.BuildArgs[k] = v
+		}
+	}
+	for k, v := range newc.Labels {
+		if c.Labels == nil {
+			c.Labels = map[string]string{}
+		}
+		if _, ok := c.Labels[k]; !ok {
+			c.Labels[k] = v
+		}
+	}
 	return true
 }
 
@@ -0,0 +1,98 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package ir
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/common/deepcopy"
+	"github.com/konveyor/move2kube/types"
+)
+
+// IRDumpKind defines kind of the IR dump
+const IRDumpKind types.Kind = "IntermediateRepresentation"
+
+// IRDump stores the IR so that it can be inspected and modified by external tools
+type IRDump struct {
+	types.TypeMeta   `yaml:",inline" json:",inline"`
+	types.ObjectMeta `yaml:"metadata,omitempty" json:"metadata,omitempty"`
+	Spec             IR `yaml:"spec,omitempty" json:"spec,omitempty"`
+}
+
+// NewIRDump creates a new IR dump containing a copy of the IR
+func NewIRDump(ir IR) IRDump {
+	irCopy := deepcopy.DeepCopy(ir).(IR)
+	irCopy.SchemaVersion = IRSchemaVersion
+	return IRDump{
+		TypeMeta: types.TypeMeta{
+			Kind:       string(IRDumpKind),
+			APIVersion: types.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: types.ObjectMeta{Name: ir.Name},
+		Spec:       irCopy,
+	}
+}
+
+// WriteIRDump writes the IR to the path as json if the path has a .json extension and as yaml otherwise.
+// The IR types do not have yaml tags, so the IR is always encoded using the json field names.
+func WriteIRDump(path string, ir IR) error {
+	dump := NewIRDump(ir)
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return common.WriteJSON(path, dump)
+	}
+	jsonBytes, err := json.Marshal(dump)
+	if err != nil {
+		return fmt.Errorf("failed to marshal the IR to json. Error: %w", err)
+	}
+	dumpMap := map[string]interface{}{}
+	if err := json.Unmarshal(jsonBytes, &dumpMap); err != nil {
+		return fmt.Errorf("failed to unmarshal the IR json into a map. Error: %w", err)
+	}
+	if err := common.WriteYaml(path, dumpMap); err != nil {
+		return fmt.Errorf("failed to write the IR to the path '%s' . Error: %w", path, err)
+	}
+	return nil
+}
+
+// ReadIRDump reads and validates the IR written by WriteIRDump
+func ReadIRDump(path string) (IR, error) {
+	dump := IRDump{}
+	if err := common.ReadMove2KubeYamlStrict(path, &dump, string(IRDumpKind)); err != nil {
+		return IR{}, fmt.Errorf("failed to read the IR from the path '%s' . Error: %w", path, err)
+	}
+	ir := dump.Spec
+	if ir.Name == "" {
+		ir.Name = dump.Name
+	}
+	if ir.ContainerImages == nil {
+		ir.ContainerImages = map[string]ContainerImage{}
+	}
+	if ir.Services == nil {
+		ir.Services = map[string]Service{}
+	}
+	if ir.Storages == nil {
+		ir.Storages = []Storage{}
+	}
+	if err := ir.Validate(); err != nil {
+		return ir, fmt.Errorf("the IR at the path '%s' is invalid. Error: %w", path, err)
+	}
+	return ir, nil
+}
@@ -0,0 +1,84 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package ir
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/api/resource"
+	core "k8s.io/kubernetes/pkg/apis/core"
+	networking "k8s.io/kubernetes/pkg/apis/networking"
+)
+
+func TestIRDump(t *testing.T) {
+	getIR := func() IR {
+		ir := NewIR()
+		ir.Name = "myproject"
+		service := NewServiceWithName("web")
+		service.Containers = []core.Container{{
+			Name:      "web",
+			Image:     "web:latest",
+			Ports:     []core.ContainerPort{{ContainerPort: 8080, Protocol: core.ProtocolTCP}},
+			Resources: core.ResourceRequirements{Limits: core.ResourceList{core.ResourceMemory: resource.MustParse("512Mi")}},
+		}}
+		service.AddPortForwarding(networking.ServiceBackendPort{Number: 80}, networking.ServiceBackendPort{Number: 8080}, "/web")
+		service.SourceFiles = []string{"docker-compose.yaml"}
+		ir.AddService(service)
+		ir.AddContainer("web:latest", ContainerImage{ExposedPorts: []int32{8080}, Build: ContainerBuild{ContainerBuildType: DockerfileContainerBuildType, ContextPath: "web"}})
+		ir.AddStorage(Storage{Name: "web-secret", StorageType: SecretKind, Content: map[string][]byte{"password": []byte("hunter2")}})
+		return ir
+	}
+
+	for _, fileName := range []string{"ir.yaml", "ir.json"} {
+		t.Run("round trip through "+fileName, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), fileName)
+			ir := getIR()
+			if err := WriteIRDump(path, ir); err != nil {
+				t.Fatalf("failed to write the IR dump. Error: %q", err)
+			}
+			actual, err := ReadIRDump(path)
+			if err != nil {
+				t.Fatalf("failed to read the IR dump. Error: %q", err)
+			}
+			ir.SchemaVersion = IRSchemaVersion
+			if !cmp.Equal(actual, ir) {
+				t.Fatalf("the IR changed after the round trip. Difference:\n%s", cmp.Diff(ir, actual))
+			}
+		})
+	}
+
+	t.Run("invalid IR dumps are rejected", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "ir.yaml")
+		if err := WriteIRDump(path, getIR()); err != nil {
+			t.Fatalf("failed to write the IR dump. Error: %q", err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("failed to read the IR dump. Error: %q", err)
+		}
+		if err := os.WriteFile(path, []byte(strings.Replace(string(data), "ContainerPort: 8080", "ContainerPort: 0", 1)), 0644); err != nil {
+			t.Fatalf("failed to modify the IR dump. Error: %q", err)
+		}
+		if _, err := ReadIRDump(path); err == nil || !strings.Contains(err.Error(), "services[web].containers[0].ports[0].containerPort") {
+			t.Fatalf("expected the modified IR dump to be invalid. Actual: %v", err)
+		}
+	})
+}
@@ -0,0 +1,137 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package ir
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+// RenameServiceReferences updates the references to the renamed services in the service, like the backend service
+// and the DNS names of the other services in the env values, e.g. DB_HOST=db or DATABASE_URL=postgres://db:5432/app.
+// The dependency wait init containers refer to their dependencies using env values too.
+func (service *Service) RenameServiceReferences(serviceNames map[string]string) {
+	hostRegex := getServiceHostRegex(serviceNames)
+	if hostRegex == nil {
+		return
+	}
+	if newName, ok := serviceNames[service.BackendServiceName]; ok {
+		service.BackendServiceName = newName
+	}
+	for _, containers := range [][]core.Container{service.Containers, service.InitContainers} {
+		for i := range containers {
+			for j, env := range containers[i].Env {
+				if env.Value != "" {
+					containers[i].Env[j].Value = renameServiceHosts(env.Value, hostRegex, serviceNames)
+				}
+			}
+		}
+	}
+}
+
+// RenameStorageReferences updates the references to the renamed config maps, secrets and persistent volume claims in the pod spec
+func (podSpec *PodSpec) RenameStorageReferences(storageNames map[string]string) {
+	rename := func(name *string) {
+		if newName, ok := storageNames[*name]; ok {
+			*name = newName
+		}
+	}
+	for i := range podSpec.ImagePullSecrets {
+		rename(&podSpec.ImagePullSecrets[i].Name)
+	}
+	for _, volume := range podSpec.Volumes {
+		if volume.PersistentVolumeClaim != nil {
+			rename(&volume.PersistentVolumeClaim.ClaimName)
+		}
+		if volume.ConfigMap != nil {
+			rename(&volume.ConfigMap.Name)
+		}
+		if volume.Secret != nil {
+			rename(&volume.Secret.SecretName)
+		}
+		if volume.Projected != nil {
+			for _, source := range volume.Projected.Sources {
+				if source.ConfigMap != nil {
+					rename(&source.ConfigMap.Name)
+				}
+				if source.Secret != nil {
+					rename(&source.Secret.Name)
+				}
+			}
+		}
+	}
+	for _, containers := range [][]core.Container{podSpec.Containers, podSpec.InitContainers} {
+		for i := range containers {
+			for _, env := range containers[i].Env {
+				if env.ValueFrom == nil {
+					continue
+				}
+				if env.ValueFrom.ConfigMapKeyRef != nil {
+					rename(&env.ValueFrom.ConfigMapKeyRef.Name)
+				}
+				if env.ValueFrom.SecretKeyRef != nil {
+					rename(&env.ValueFrom.SecretKeyRef.Name)
+				}
+			}
+			for _, envFrom := range containers[i].EnvFrom {
+				if envFrom.ConfigMapRef != nil {
+					rename(&envFrom.ConfigMapRef.Name)
+				}
+				if envFrom.SecretRef != nil {
+					rename(&envFrom.SecretRef.Name)
+				}
+			}
+		}
+	}
+}
+
+// getServiceHostRegex returns the regex matching the renamed services used as hosts: the whole value, the host of a url,
+// or the host of a host:port pair. It returns nil if no service is renamed.
+func getServiceHostRegex(serviceNames map[string]string) *regexp.Regexp {
+	oldNames := []string{}
+	for oldName, newName := range serviceNames {
+		if oldName != "" && oldName != newName {
+			oldNames = append(oldNames, regexp.QuoteMeta(oldName))
+		}
+	}
+	if len(oldNames) == 0 {
+		return nil
+	}
+	// the longer names come first, so that a name is not matched by a shorter name it starts with
+	sort.Slice(oldNames, func(i, j int) bool {
+		if len(oldNames[i]) != len(oldNames[j]) {
+			return len(oldNames[i]) > len(oldNames[j])
+		}
+		return oldNames[i] < oldNames[j]
+	})
+	return regexp.MustCompile(`(^|://|@)(` + strings.Join(oldNames, "|") + `)(:|/|$)`)
+}
+
+// renameServiceHosts replaces the renamed services used as hosts in each of the comma separated parts of the value
+func renameServiceHosts(value string, hostRegex *regexp.Regexp, serviceNames map[string]string) string {
+	parts := strings.Split(value, ",")
+	for i, part := range parts {
+		parts[i] = hostRegex.ReplaceAllStringFunc(part, func(match string) string {
+			subMatches := hostRegex.FindStringSubmatch(match)
+			return subMatches[1] + serviceNames[subMatches[2]] + subMatches[3]
+		})
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,47 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package ir
+
+import (
+	"testing"
+)
+
+func TestRenameServiceHosts(t *testing.T) {
+	serviceNames := map[string]string{"db": "shop-db", "cache": "shop-cache", "web": "web"}
+	hostRegex := getServiceHostRegex(serviceNames)
+	testCases := map[string]string{
+		"db":                           "shop-db",
+		"db:5432":                      "shop-db:5432",
+		"postgres://db:5432/app":       "postgres://shop-db:5432/app",
+		"redis://:secret@cache:6379/0": "redis://:secret@shop-cache:6379/0",
+		"db:5432,cache:6379":           "shop-db:5432,shop-cache:6379",
+		"http://web/":                  "http://web/",
+		"dbadmin":                      "dbadmin",
+		"db-secret":                    "db-secret",
+		"mydb":                         "mydb",
+	}
+	for value, want := range testCases {
+		t.Run(value, func(t *testing.T) {
+			if actual := renameServiceHosts(value, hostRegex, serviceNames); actual != want {
+				t.Fatalf("wrong value. Expected: %s Actual: %s", want, actual)
+			}
+		})
+	}
+	if getServiceHostRegex(map[string]string{"web": "web"}) != nil {
+		t.Fatalf("expected no regex when no service is renamed")
+	}
+}
@@ -0,0 +1,176 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package ir
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+// IRSchemaVersion is the current version of the IR schema
+const IRSchemaVersion = "v1alpha1"
+
+var supportedStorageKinds = []string{string(SecretKind), string(ConfigMapKind), string(PVCKind), string(PullSecretKind)}
+
+// Validate validates the IR against the IR schema.
+// All the problems are returned together, each one prefixed with the path of the offending field.
+func (ir *IR) Validate() error {
+	allErrs := field.ErrorList{}
+	if ir.SchemaVersion != "" && ir.SchemaVersion != IRSchemaVersion {
+		allErrs = append(allErrs, field.NotSupported(field.NewPath("schemaVersion"), ir.SchemaVersion, []string{IRSchemaVersion}))
+	}
+	servicesPath := field.NewPath("services")
+	for _, serviceName := range ir.GetSortedServiceNames() {
+		service := ir.Services[serviceName]
+		allErrs = append(allErrs, service.validate(servicesPath.Key(serviceName), serviceName)...)
+	}
+	imagesPath := field.NewPath("containerImages")
+	for imageName, image := range ir.ContainerImages {
+		imagePath := imagesPath.Key(imageName)
+		if strings.TrimSpace(imageName) == "" {
+			allErrs = append(allErrs, field.Required(imagePath, "the image name must not be empty"))
+		}
+		for i, port := range image.ExposedPorts {
+			allErrs = append(allErrs, validatePortNumber(imagePath.Child("ports").Index(i), port)...)
+		}
+	}
+	storagesPath := field.NewPath("storages")
+	storageNames := map[string]bool{}
+	for i, storage := range ir.Storages {
+		storagePath := storagesPath.Index(i)
+		if storage.Name == "" {
+			allErrs = append(allErrs, field.Required(storagePath.Child("name"), ""))
+		} else {
+			for _, msg := range validation.IsDNS1123Subdomain(storage.Name) {
+				allErrs = append(allErrs, field.Invalid(storagePath.Child("name"), storage.Name, msg))
+			}
+			key := string(storage.StorageType) + "/" + storage.Name
+			if storageNames[key] {
+				allErrs = append(allErrs, field.Duplicate(storagePath.Child("name"), storage.Name))
+			}
+			storageNames[key] = true
+		}
+		if !isStorageKindSupported(storage.StorageType) {
+			allErrs = append(allErrs, field.NotSupported(storagePath.Child("storageType"), storage.StorageType, supportedStorageKinds))
+		}
+	}
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("the IR '%s' is invalid. Error: %w", ir.Name, allErrs.ToAggregate())
+}
+
+// validate validates the service stored against the key in the services of the IR
+func (service *Service) validate(servicePath *field.Path, key string) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if service.Name == "" {
+		allErrs = append(allErrs, field.Required(servicePath.Child("name"), ""))
+	} else {
+		if service.Name != key {
+			allErrs = append(allErrs, field.Invalid(servicePath.Child("name"), service.Name, "must be the same as the key of the service"))
+		}
+		for _, msg := range validation.IsDNS1123Subdomain(service.Name) {
+			allErrs = append(allErrs, field.Invalid(servicePath.Child("name"), service.Name, msg))
+		}
+	}
+	if service.Replicas < 0 {
+		allErrs = append(allErrs, field.Invalid(servicePath.Child("replicas"), service.Replicas, "must not be negative"))
+	}
+	containerNames := map[string]bool{}
+	containerPorts := map[string]bool{}
+	validateContainers := func(containersPath *field.Path, containers []core.Container) {
+		for i, container := range containers {
+			containerPath := containersPath.Index(i)
+			if container.Name == "" {
+				allErrs = append(allErrs, field.Required(containerPath.Child("name"), ""))
+			} else {
+				// the container names are normalized like the metadata names, which may contain dots
+				for _, msg := range validation.IsDNS1123Subdomain(container.Name) {
+					allErrs = append(allErrs, field.Invalid(containerPath.Child("name"), container.Name, msg))
+				}
+				if containerNames[container.Name] {
+					allErrs = append(allErrs, field.Duplicate(containerPath.Child("name"), container.Name))
+				}
+				containerNames[container.Name] = true
+			}
+			for j, port := range container.Ports {
+				portPath := containerPath.Child("ports").Index(j)
+				allErrs = append(allErrs, validatePortNumber(portPath.Child("containerPort"), port.ContainerPort)...)
+				protocol := port.Protocol
+				if protocol == "" {
+					protocol = core.ProtocolTCP
+				}
+				key := fmt.Sprintf("%d/%s", port.ContainerPort, protocol)
+				if containerPorts[key] {
+					allErrs = append(allErrs, field.Duplicate(portPath.Child("containerPort"), key))
+				}
+				containerPorts[key] = true
+			}
+			for j, env := range container.Env {
+				if env.Name == "" {
+					allErrs = append(allErrs, field.Required(containerPath.Child("env").Index(j).Child("name"), ""))
+				}
+			}
+		}
+	}
+	validateContainers(servicePath.Child("initContainers"), service.InitContainers)
+	validateContainers(servicePath.Child("containers"), service.Containers)
+	forwardingsPath := servicePath.Child("serviceToPodPortForwardings")
+	servicePorts := map[string]bool{}
+	for i, forwarding := range service.ServiceToPodPortForwardings {
+		forwardingPath := forwardingsPath.Index(i)
+		allErrs = append(allErrs, validatePortNumber(forwardingPath.Child("servicePort", "number"), forwarding.ServicePort.Number)...)
+		protocol := forwarding.Protocol
+		if protocol == "" {
+			protocol = core.ProtocolTCP
+		}
+		key := fmt.Sprintf("%d/%s", forwarding.ServicePort.Number, protocol)
+		if servicePorts[key] {
+			allErrs = append(allErrs, field.Duplicate(forwardingPath.Child("servicePort", "number"), key))
+		}
+		servicePorts[key] = true
+		if forwarding.PodPort.Number == 0 {
+			if forwarding.PodPort.Name == "" {
+				allErrs = append(allErrs, field.Required(forwardingPath.Child("podPort"), "either the number or the name of the pod port must be specified"))
+			}
+		} else {
+			allErrs = append(allErrs, validatePortNumber(forwardingPath.Child("podPort", "number"), forwarding.PodPort.Number)...)
+		}
+	}
+	return allErrs
+}
+
+func validatePortNumber(portPath *field.Path, port int32) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for _, msg := range validation.IsValidPortNum(int(port)) {
+		allErrs = append(allErrs, field.Invalid(portPath, port, msg))
+	}
+	return allErrs
+}
+
+func isStorageKindSupported(kind StorageKindType) bool {
+	for _, supportedKind := range supportedStorageKinds {
+		if string(kind) == supportedKind {
+			return true
+		}
+	}
+	return false
+}
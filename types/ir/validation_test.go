@@ -0,0 +1,96 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package ir
+
+import (
+	"strings"
+	"testing"
+
+	core "k8s.io/kubernetes/pkg/apis/core"
+	networking "k8s.io/kubernetes/pkg/apis/networking"
+)
+
+func TestValidate(t *testing.T) {
+	getValidIR := func() IR {
+		ir := NewIR()
+		ir.Name = "myproject"
+		service := NewServiceWithName("web")
+		service.Containers = []core.Container{{Name: "web", Image: "web:latest", Ports: []core.ContainerPort{{ContainerPort: 8080}}}}
+		service.AddPortForwarding(networking.ServiceBackendPort{Number: 80}, networking.ServiceBackendPort{Number: 8080}, "/web")
+		ir.AddService(service)
+		ir.AddStorage(Storage{Name: "web-config", StorageType: ConfigMapKind})
+		return ir
+	}
+
+	t.Run("valid IR", func(t *testing.T) {
+		ir := getValidIR()
+		if err := ir.Validate(); err != nil {
+			t.Fatalf("expected the IR to be valid. Error: %q", err)
+		}
+	})
+
+	t.Run("all the problems are reported with the paths of the fields", func(t *testing.T) {
+		ir := getValidIR()
+		ir.SchemaVersion = "v2"
+		service := ir.Services["web"]
+		service.Containers = append(service.Containers, core.Container{Name: "", Ports: []core.ContainerPort{{ContainerPort: 8080}, {ContainerPort: 70000}}})
+		service.ServiceToPodPortForwardings = append(service.ServiceToPodPortForwardings, ServiceToPodPortForwarding{ServicePort: networking.ServiceBackendPort{Number: 80}})
+		ir.Services["web"] = service
+		ir.Storages = append(ir.Storages, Storage{Name: "Bad_Name", StorageType: "Volume"})
+		err := ir.Validate()
+		if err == nil {
+			t.Fatalf("expected the IR to be invalid")
+		}
+		for _, expected := range []string{
+			"schemaVersion: Unsupported value: \"v2\"",
+			"services[web].containers[1].name: Required value",
+			"services[web].containers[1].ports[0].containerPort: Duplicate value: \"8080/TCP\"",
+			"services[web].containers[1].ports[1].containerPort: Invalid value: 70000",
+			"services[web].serviceToPodPortForwardings[1].servicePort.number: Duplicate value: \"80/TCP\"",
+			"services[web].serviceToPodPortForwardings[1].podPort: Required value",
+			"storages[1].name: Invalid value: \"Bad_Name\"",
+			"storages[1].storageType: Unsupported value: \"Volume\"",
+		} {
+			if !strings.Contains(err.Error(), expected) {
+				t.Fatalf("expected the error to contain %q . Actual: %q", expected, err)
+			}
+		}
+	})
+
+	t.Run("service names must match their keys", func(t *testing.T) {
+		ir := getValidIR()
+		ir.Services["api"] = ir.Services["web"]
+		if err := ir.Validate(); err == nil || !strings.Contains(err.Error(), "services[api].name: Invalid value: \"web\"") {
+			t.Fatalf("expected the mismatched service name to be reported. Actual: %v", err)
+		}
+	})
+	t.Run("the same port number with different protocols and dotted container names are valid", func(t *testing.T) {
+		ir := getValidIR()
+		service := ir.Services["web"]
+		service.Containers[0].Name = "web.api"
+		service.Containers[0].Ports = append(service.Containers[0].Ports, core.ContainerPort{ContainerPort: 8080, Protocol: core.ProtocolUDP})
+		service.ServiceToPodPortForwardings = append(service.ServiceToPodPortForwardings, ServiceToPodPortForwarding{
+			ServicePort: networking.ServiceBackendPort{Number: 80},
+			PodPort:     networking.ServiceBackendPort{Number: 8080},
+			Protocol:    core.ProtocolUDP,
+		})
+		ir.Services["web"] = service
+		if err := ir.Validate(); err != nil {
+			t.Fatalf("expected the IR to be valid. Error: %q", err)
+		}
+	})
+}
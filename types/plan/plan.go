@@ -17,6 +17,8 @@
 package plan
 
 import (
+	"fmt"
+
 	"github.com/konveyor/move2kube/common"
 	"github.com/konveyor/move2kube/types"
 	transformertypes "github.com/konveyor/move2kube/types/transformer"
@@ -39,6 +41,8 @@ type Spec struct {
 	CustomizationsDir string `yaml:"customizationsDir,omitempty"`
 
 	Services map[string][]PlanArtifact `yaml:"services"` //[servicename]
+	// DisabledServices are the detected services that are not transformed
+	DisabledServices map[string][]PlanArtifact `yaml:"disabledServices,omitempty"` //[servicename]
 
 	TransformerSelector          metav1.LabelSelector `yaml:"transformerSelector,omitempty"`
 	Transformers                 map[string]string    `yaml:"transformers,omitempty" m2kpath:"normal"` //[name]filepath
@@ -73,6 +77,81 @@ func NewPlan() Plan {
 	return plan
 }
 
+// SetServiceEnabled moves the service between the services that are transformed and the disabled services
+func (p *Plan) SetServiceEnabled(serviceName string, enabled bool) error {
+	from, to := &p.Spec.DisabledServices, &p.Spec.Services
+	if !enabled {
+		from, to = to, from
+	}
+	options, ok := (*from)[serviceName]
+	if !ok {
+		if _, ok := (*to)[serviceName]; ok {
+			return nil
+		}
+		return fmt.Errorf("the service '%s' is not in the plan", serviceName)
+	}
+	delete(*from, serviceName)
+	if *to == nil {
+		*to = map[string][]PlanArtifact{}
+	}
+	(*to)[serviceName] = options
+	return nil
+}
+
+// SelectServiceOption makes the transformation option at the index the first option of the service.
+// The first valid option of a service is used during transformation.
+func (p *Plan) SelectServiceOption(serviceName string, index int) error {
+	options, ok := p.Spec.Services[serviceName]
+	if !ok {
+		if options, ok = p.Spec.DisabledServices[serviceName]; !ok {
+			return fmt.Errorf("the service '%s' is not in the plan", serviceName)
+		}
+	}
+	if index < 0 || index >= len(options) {
+		return fmt.Errorf("the service '%s' has %d transformation options. Actual index: %d", serviceName, len(options), index)
+	}
+	selected := options[index]
+	copy(options[1:index+1], options[:index])
+	options[0] = selected
+	return nil
+}
+
+// SetTransformerEnabled moves the transformer between the transformers that are used and the disabled transformers
+func (p *Plan) SetTransformerEnabled(transformerName string, enabled bool) error {
+	from, to := &p.Spec.DisabledTransformers, &p.Spec.Transformers
+	if !enabled {
+		from, to = to, from
+	}
+	transformerPath, ok := (*from)[transformerName]
+	if !ok {
+		if _, ok := (*to)[transformerName]; ok {
+			return nil
+		}
+		return fmt.Errorf("the transformer '%s' is not in the plan", transformerName)
+	}
+	delete(*from, transformerName)
+	if *to == nil {
+		*to = map[string]string{}
+	}
+	(*to)[transformerName] = transformerPath
+	return nil
+}
+
+// GetServicesWithDisabledTransformers returns the transformed services whose selected transformation option uses a disabled transformer,
+// along with the name of that transformer. The first option whose transformer is enabled is used instead during transformation.
+func (p *Plan) GetServicesWithDisabledTransformers() map[string]string {
+	services := map[string]string{}
+	for serviceName, options := range p.Spec.Services {
+		if len(options) == 0 {
+			continue
+		}
+		if _, ok := p.Spec.DisabledTransformers[options[0].TransformerName]; ok {
+			services[serviceName] = options[0].TransformerName
+		}
+	}
+	return services
+}
+
 // MergeServices merges two service maps
 func MergeServices(s1 map[string][]PlanArtifact, s2 map[string][]PlanArtifact) map[string][]PlanArtifact {
 	if s1 == nil {
@@ -17,6 +17,7 @@
 package plan_test
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/konveyor/move2kube/types/plan"
@@ -28,3 +29,72 @@ func TestNewPlan(t *testing.T) {
 		t.Error("Failed to instantiate the plan fields properly. Actual:", p)
 	}
 }
+
+func TestSetServiceEnabled(t *testing.T) {
+	p := plan.NewPlan()
+	p.Spec.Services["svc1"] = []plan.PlanArtifact{{TransformerName: "Nodejs-Dockerfile"}}
+	if err := p.SetServiceEnabled("svc1", false); err != nil {
+		t.Fatalf("failed to disable the service. Error: %q", err)
+	}
+	if _, ok := p.Spec.Services["svc1"]; ok {
+		t.Fatalf("the disabled service is still transformed. Actual: %+v", p.Spec)
+	}
+	if len(p.Spec.DisabledServices["svc1"]) != 1 {
+		t.Fatalf("the service was not disabled. Actual: %+v", p.Spec)
+	}
+	if err := p.SetServiceEnabled("svc1", true); err != nil {
+		t.Fatalf("failed to enable the service. Error: %q", err)
+	}
+	if len(p.Spec.Services["svc1"]) != 1 || len(p.Spec.DisabledServices) != 0 {
+		t.Fatalf("the service was not enabled. Actual: %+v", p.Spec)
+	}
+	if err := p.SetServiceEnabled("svc2", true); err == nil {
+		t.Fatalf("expected an error for a service that is not in the plan")
+	}
+}
+
+func TestSelectServiceOption(t *testing.T) {
+	p := plan.NewPlan()
+	p.Spec.Services["svc1"] = []plan.PlanArtifact{{TransformerName: "a"}, {TransformerName: "b"}, {TransformerName: "c"}}
+	if err := p.SelectServiceOption("svc1", 2); err != nil {
+		t.Fatalf("failed to select the transformation option. Error: %q", err)
+	}
+	got := []string{}
+	for _, option := range p.Spec.Services["svc1"] {
+		got = append(got, option.TransformerName)
+	}
+	if want := []string{"c", "a", "b"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("the transformation options are in the wrong order. Expected: %+v Actual: %+v", want, got)
+	}
+	if err := p.SelectServiceOption("svc1", 3); err == nil {
+		t.Fatalf("expected an error for an index that is out of range")
+	}
+}
+
+func TestSetTransformerEnabled(t *testing.T) {
+	p := plan.NewPlan()
+	p.Spec.Transformers["Knative"] = "transformers/knative/transformer.yaml"
+	if err := p.SetTransformerEnabled("Knative", false); err != nil {
+		t.Fatalf("failed to disable the transformer. Error: %q", err)
+	}
+	if _, ok := p.Spec.Transformers["Knative"]; ok || p.Spec.DisabledTransformers["Knative"] != "transformers/knative/transformer.yaml" {
+		t.Fatalf("the transformer was not disabled. Actual: %+v", p.Spec)
+	}
+}
+
+func TestGetServicesWithDisabledTransformers(t *testing.T) {
+	p := plan.NewPlan()
+	p.Spec.Transformers["Nodejs-Dockerfile"] = "transformers/nodejs/transformer.yaml"
+	p.Spec.Transformers["Knative"] = "transformers/knative/transformer.yaml"
+	p.Spec.Services["svc1"] = []plan.PlanArtifact{{TransformerName: "Nodejs-Dockerfile"}}
+	p.Spec.Services["svc2"] = []plan.PlanArtifact{{TransformerName: "Knative"}, {TransformerName: "Nodejs-Dockerfile"}}
+	if got := p.GetServicesWithDisabledTransformers(); len(got) != 0 {
+		t.Fatalf("expected no services with disabled transformers. Actual: %+v", got)
+	}
+	if err := p.SetTransformerEnabled("Knative", false); err != nil {
+		t.Fatalf("failed to disable the transformer. Error: %q", err)
+	}
+	if got, want := p.GetServicesWithDisabledTransformers(), map[string]string{"svc2": "Knative"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("wrong services with disabled transformers. Expected: %+v Actual: %+v", want, got)
+	}
+}
@@ -19,18 +19,64 @@ package commonqa
 import (
 	"fmt"
 	"net/url"
+	"regexp"
 	"strconv"
 	"strings"
 
 	dockercliconfig "github.com/docker/cli/cli/config"
 	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment/container"
 	"github.com/konveyor/move2kube/qaengine"
 	"github.com/konveyor/move2kube/types/ir"
 	qatypes "github.com/konveyor/move2kube/types/qaengine"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cast"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
+const (
+	// KeepImageTagPolicy keeps the tag of each new image
+	KeepImageTagPolicy = "Keep the tag of each image"
+	// SameImageTagPolicy uses the same tag for all the new images
+	SameImageTagPolicy = "Use the same tag for all the images"
+	// NoResourcePreset keeps the resource requests and limits generated for a service
+	NoResourcePreset = "none"
+	// SmallResourcePreset is the preset for services that need few resources
+	SmallResourcePreset = "small"
+	// MediumResourcePreset is the preset for services that need a moderate amount of resources
+	MediumResourcePreset = "medium"
+	// LargeResourcePreset is the preset for services that need a lot of resources
+	LargeResourcePreset = "large"
+	// NoPodAntiAffinity does not spread the replicas of a service
+	NoPodAntiAffinity = "none"
+	// HostnameTopology spreads the replicas of a service across the nodes
+	HostnameTopology = "hostname"
+	// ZoneTopology spreads the replicas of a service across the zones
+	ZoneTopology = "zone"
+	// defaultTopologySpreadMaxSkew is the default maximum difference in the number of replicas between the topology domains
+	defaultTopologySpreadMaxSkew = 1
+	// NoPriorityClass keeps the default priority of a service
+	NoPriorityClass = "none"
+	// maxPriorityClassValue is the highest value of the priority classes that are not reserved for the system
+	maxPriorityClassValue = 1000000000
+	// NoInstrumentation does not instrument the services
+	NoInstrumentation = "none"
+	// OperatorInstrumentation annotates the services for the auto-instrumentation of the OpenTelemetry Operator
+	OperatorInstrumentation = "operator"
+	// SidecarInstrumentation injects an OpenTelemetry collector sidecar into the services
+	SidecarInstrumentation = "sidecar"
+	// SnapshotBackupMethod backs up the persistent volumes by taking snapshots of them
+	SnapshotBackupMethod = "snapshot"
+	// FileSystemBackupMethod backs up the persistent volumes by copying their files
+	FileSystemBackupMethod = "fsb"
+	// RuntimeDefaultSeccompProfile is the seccomp profile of the container runtime
+	RuntimeDefaultSeccompProfile = "RuntimeDefault"
+	// NoSeccompProfile does not set a seccomp profile on the containers
+	NoSeccompProfile = "none"
+)
+
+var imageTagRegex = regexp.MustCompile(`^[a-zA-Z0-9_][a-zA-Z0-9_.-]{0,127}$`)
+
 // ImageRegistry returns Image Registry URL
 func ImageRegistry() string {
 	// DefaultRegistryURL points to the default registry url that will be used
@@ -78,6 +124,371 @@ func ImageRegistryNamespace() string {
 	return qaengine.FetchStringAnswer(common.ConfigImageRegistryNamespaceKey, "Enter the namespace where the new images should be pushed : ", []string{"Ex : " + common.ProjectName}, common.ProjectName, nil)
 }
 
+// ImageTag returns the tag to use for all the new images. An empty string means the tag of each image is kept.
+func ImageTag() string {
+	policy := qaengine.FetchSelectAnswer(
+		common.ConfigImageTagPolicyKey,
+		"How should the new images be tagged?",
+		[]string{"Using the same tag for all the images lets you switch all of them to a new version with a single change."},
+		KeepImageTagPolicy,
+		[]string{KeepImageTagPolicy, SameImageTagPolicy},
+		nil,
+	)
+	if policy != SameImageTagPolicy {
+		return ""
+	}
+	return qaengine.FetchStringAnswer(common.ConfigImageTagKey, "Enter the tag for the new images : ", []string{"Ex : v1.0.0"}, "latest", func(tag interface{}) error {
+		tagStr, ok := tag.(string)
+		if !ok {
+			return fmt.Errorf("the tag should be a string. Actual value %+v is of type %T", tag, tag)
+		}
+		if !imageTagRegex.MatchString(tagStr) {
+			return fmt.Errorf("the tag '%s' is not a valid image tag", tagStr)
+		}
+		return nil
+	})
+}
+
+// DockerContext returns the docker context the generated scripts use. An empty string means the default context is used.
+func DockerContext() string {
+	hints := []string{"Leave it empty to use the context that is current when the scripts are run."}
+	if !common.IgnoreEnvironment {
+		if contextName := container.GetDockerContext(); contextName != "" {
+			hints = append(hints, "The current docker context is "+contextName)
+		}
+	}
+	return qaengine.FetchStringAnswer(common.ConfigDockerContextKey, "Enter the docker context the scripts should use : ", hints, "", nil)
+}
+
+// PodmanConnection returns the podman system connection the generated scripts use. An empty string means the default connection is used.
+func PodmanConnection() string {
+	hints := []string{"Leave it empty to use the connection that is the default when the scripts are run."}
+	if !common.IgnoreEnvironment {
+		if connectionName := container.GetPodmanConnection(); connectionName != "" {
+			hints = append(hints, "The current podman connection is "+connectionName)
+		}
+	}
+	return qaengine.FetchStringAnswer(common.ConfigPodmanConnectionKey, "Enter the podman connection the scripts should use : ", hints, "", nil)
+}
+
+// GetTaggedImageName returns the image with its tag replaced by the given tag. An empty tag keeps the tag of the image.
+func GetTaggedImageName(image, tag string) string {
+	if tag == "" {
+		return image
+	}
+	name := image
+	if idx := strings.LastIndex(image, ":"); idx > strings.LastIndex(image, "/") {
+		name = image[:idx]
+	}
+	return name + ":" + tag
+}
+
+// ResourcePreset returns the preset for the resource requests and limits of the service. An empty string means no preset is used.
+func ResourcePreset(serviceName string) string {
+	preset := qaengine.FetchSelectAnswer(
+		common.JoinQASubKeys(common.ConfigServicesKey, `"`+serviceName+`"`, common.ConfigResourcePresetForServiceKeySegment),
+		fmt.Sprintf("Select the resource preset for the service '%s' :", serviceName),
+		[]string{
+			"The requests and limits of the preset are exposed as parameters shared by all the services using it.",
+			"Choose " + NoResourcePreset + " to keep the requests and limits generated from the source.",
+		},
+		NoResourcePreset,
+		[]string{NoResourcePreset, SmallResourcePreset, MediumResourcePreset, LargeResourcePreset},
+		nil,
+	)
+	if preset == NoResourcePreset {
+		return ""
+	}
+	return preset
+}
+
+// PodAntiAffinity returns the topology across which the replicas of the service should preferably be spread.
+// An empty string means no pod anti-affinity is used.
+func PodAntiAffinity(serviceName string) string {
+	topology := qaengine.FetchSelectAnswer(
+		common.JoinQASubKeys(common.ConfigServicesKey, `"`+serviceName+`"`, common.ConfigPodAntiAffinityForServiceKeySegment),
+		fmt.Sprintf("Select the topology across which the replicas of the service '%s' should be spread :", serviceName),
+		[]string{
+			"A preferred pod anti-affinity keeps the replicas from landing on the same node or zone when possible.",
+			"Choose " + NoPodAntiAffinity + " to not add a pod anti-affinity.",
+		},
+		NoPodAntiAffinity,
+		[]string{NoPodAntiAffinity, HostnameTopology, ZoneTopology},
+		nil,
+	)
+	if topology == NoPodAntiAffinity {
+		return ""
+	}
+	return topology
+}
+
+// TopologySpread returns the topologies across which the replicas of the service should be evenly spread
+func TopologySpread(serviceName string) []string {
+	return qaengine.FetchMultiSelectAnswer(
+		common.JoinQASubKeys(common.ConfigServicesKey, `"`+serviceName+`"`, common.ConfigTopologySpreadForServiceKeySegment, "topologies"),
+		fmt.Sprintf("Select the topologies across which the replicas of the service '%s' should be evenly spread :", serviceName),
+		[]string{"A topology spread constraint is added for each of the selected topologies. Select none to not add any."},
+		[]string{},
+		[]string{ZoneTopology, HostnameTopology},
+		nil,
+	)
+}
+
+// TopologySpreadMaxSkew returns the maximum difference in the number of replicas of the service between the topology domains
+func TopologySpreadMaxSkew(serviceName string) int32 {
+	maxSkewStr := qaengine.FetchStringAnswer(
+		common.JoinQASubKeys(common.ConfigServicesKey, `"`+serviceName+`"`, common.ConfigTopologySpreadForServiceKeySegment, "maxskew"),
+		fmt.Sprintf("Enter the maximum difference in the number of replicas of the service '%s' between the topology domains :", serviceName),
+		[]string{"A larger value allows a less even spread"},
+		cast.ToString(defaultTopologySpreadMaxSkew),
+		func(maxSkew interface{}) error {
+			maxSkewI, err := cast.ToIntE(maxSkew)
+			if err != nil {
+				return err
+			}
+			if maxSkewI < 1 {
+				return fmt.Errorf("the max skew should be at least 1")
+			}
+			return nil
+		},
+	)
+	maxSkew, err := cast.ToInt32E(maxSkewStr)
+	if err != nil || maxSkew < 1 {
+		logrus.Errorf("The max skew %s is not a positive number. Reverting to the default %d", maxSkewStr, defaultTopologySpreadMaxSkew)
+		return defaultTopologySpreadMaxSkew
+	}
+	return maxSkew
+}
+
+// PriorityClasses returns the priority classes that can be assigned to the services.
+// The user is asked for them if there are none in the cluster.
+func PriorityClasses(clusterPriorityClasses []string) []string {
+	if len(clusterPriorityClasses) != 0 {
+		return clusterPriorityClasses
+	}
+	names := qaengine.FetchStringAnswer(
+		common.ConfigPriorityClassNamesKey,
+		"Enter the names of the priority classes that can be assigned to the services, separated by commas :",
+		[]string{"No priority classes were collected from the cluster. Leave it empty to not assign priority classes."},
+		"",
+		func(names interface{}) error {
+			for _, name := range strings.Split(cast.ToString(names), ",") {
+				name = strings.TrimSpace(name)
+				if name == "" {
+					continue
+				}
+				if errs := validation.IsDNS1123Subdomain(name); len(errs) != 0 {
+					return fmt.Errorf("the priority class name '%s' is invalid. %s", name, strings.Join(errs, ". "))
+				}
+			}
+			return nil
+		},
+	)
+	priorityClasses := []string{}
+	for _, name := range strings.Split(names, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			priorityClasses = common.AppendIfNotPresent(priorityClasses, name)
+		}
+	}
+	return priorityClasses
+}
+
+// PriorityClassName returns the priority class of the service. An empty string means no priority class is assigned.
+func PriorityClassName(serviceName string, priorityClasses []string) string {
+	priorityClassName := qaengine.FetchSelectAnswer(
+		common.JoinQASubKeys(common.ConfigServicesKey, `"`+serviceName+`"`, common.ConfigPriorityClassNameForServiceKeySegment),
+		fmt.Sprintf("Select the priority class for the service '%s' :", serviceName),
+		[]string{"The pods of the services with a higher priority are evicted last.", "Choose " + NoPriorityClass + " to keep the default priority."},
+		NoPriorityClass,
+		append([]string{NoPriorityClass}, priorityClasses...),
+		nil,
+	)
+	if priorityClassName == NoPriorityClass {
+		return ""
+	}
+	return priorityClassName
+}
+
+// GeneratePriorityClasses returns true if the priority classes that are not in the cluster should be generated
+func GeneratePriorityClasses() bool {
+	return qaengine.FetchBoolAnswer(
+		common.ConfigPriorityClassesGenerateKey,
+		"Generate the priority classes that were assigned to the services?",
+		[]string{"Say no if the priority classes already exist in the cluster."},
+		false,
+		nil,
+	)
+}
+
+// PriorityClassValue returns the value of the priority class that gets generated
+func PriorityClassValue(priorityClassName string, defaultValue int32) int32 {
+	valueStr := qaengine.FetchStringAnswer(
+		common.JoinQASubKeys(common.ConfigPriorityClassValuesKey, `"`+priorityClassName+`"`),
+		fmt.Sprintf("Enter the value of the priority class '%s' :", priorityClassName),
+		[]string{"The pods with a higher value are scheduled first and evicted last. The values above 1000000000 are reserved for the system."},
+		cast.ToString(defaultValue),
+		func(value interface{}) error {
+			valueI, err := cast.ToInt32E(value)
+			if err != nil {
+				return err
+			}
+			if valueI > maxPriorityClassValue {
+				return fmt.Errorf("the value should be at most %d", maxPriorityClassValue)
+			}
+			return nil
+		},
+	)
+	value, err := cast.ToInt32E(valueStr)
+	if err != nil || value > maxPriorityClassValue {
+		logrus.Errorf("The value %s of the priority class %s is invalid. Reverting to the default %d", valueStr, priorityClassName, defaultValue)
+		return defaultValue
+	}
+	return value
+}
+
+// Instrumentation returns the way the services should be instrumented with OpenTelemetry. An empty string means no instrumentation.
+func Instrumentation() string {
+	instrumentation := qaengine.FetchSelectAnswer(
+		common.ConfigMonitoringInstrumentationKey,
+		"Select the OpenTelemetry instrumentation of the services :",
+		[]string{
+			"Choose " + OperatorInstrumentation + " to annotate the workloads for the auto-instrumentation of the OpenTelemetry Operator.",
+			"Choose " + SidecarInstrumentation + " to inject an OpenTelemetry collector sidecar into the workloads.",
+		},
+		NoInstrumentation,
+		[]string{NoInstrumentation, OperatorInstrumentation, SidecarInstrumentation},
+		nil,
+	)
+	if instrumentation == NoInstrumentation {
+		return ""
+	}
+	return instrumentation
+}
+
+// CollectorEndpoint returns the OTLP endpoint the OpenTelemetry collector sidecars export the telemetry to
+func CollectorEndpoint() string {
+	return qaengine.FetchStringAnswer(
+		common.ConfigMonitoringCollectorEndpointKey,
+		"Enter the OTLP endpoint the OpenTelemetry collector sidecars should export to :",
+		[]string{"This is usually the gRPC endpoint of a central OpenTelemetry collector or of a tracing backend."},
+		"otel-collector.observability:4317",
+		nil,
+	)
+}
+
+// VeleroBackup returns true if the persistent volumes of the services should be backed up with Velero
+func VeleroBackup() bool {
+	return qaengine.FetchBoolAnswer(
+		common.ConfigBackupEnableKey,
+		"Do you want to back up the persistent volumes of the services with Velero?",
+		[]string{"A Velero Schedule is generated to periodically back up the services that use persistent volumes."},
+		false,
+		nil,
+	)
+}
+
+// VeleroBackupMethod returns the way Velero should back up the persistent volumes
+func VeleroBackupMethod() string {
+	return qaengine.FetchSelectAnswer(
+		common.ConfigBackupMethodKey,
+		"Select the way Velero should back up the persistent volumes :",
+		[]string{
+			"Choose " + SnapshotBackupMethod + " if the storage provider of the cluster supports volume snapshots.",
+			"Choose " + FileSystemBackupMethod + " to copy the files of the volumes using the file system backup of Velero.",
+		},
+		SnapshotBackupMethod,
+		[]string{SnapshotBackupMethod, FileSystemBackupMethod},
+		nil,
+	)
+}
+
+// SecurityBaselineSeccompProfile returns the seccomp profile of the containers that do not have one. An empty string means no profile is set.
+func SecurityBaselineSeccompProfile() string {
+	profile := qaengine.FetchSelectAnswer(
+		common.ConfigSecurityBaselineSeccompProfileKey,
+		"Select the seccomp profile of the containers :",
+		[]string{"The RuntimeDefault profile blocks the system calls the container runtime considers dangerous."},
+		RuntimeDefaultSeccompProfile,
+		[]string{RuntimeDefaultSeccompProfile, NoSeccompProfile},
+		nil,
+	)
+	if profile == NoSeccompProfile {
+		return ""
+	}
+	return profile
+}
+
+// SecurityBaselineDropAllCapabilities returns true if the containers should drop all the capabilities they do not explicitly add
+func SecurityBaselineDropAllCapabilities() bool {
+	return qaengine.FetchBoolAnswer(
+		common.ConfigSecurityBaselineDropAllCapabilitiesKey,
+		"Drop all the capabilities of the containers, other than the ones added in the source?",
+		[]string{"The capabilities added in the source, like the cap_add of the compose services, are kept."},
+		true,
+		nil,
+	)
+}
+
+// PodSecurityRestricted returns true if the pods should comply with the restricted Pod Security Standard
+func PodSecurityRestricted() bool {
+	return qaengine.FetchBoolAnswer(
+		common.ConfigPodSecurityRestrictedKey,
+		"Do you want the pods to comply with the restricted Pod Security Standard?",
+		[]string{"The containers run as non-root with the RuntimeDefault seccomp profile, without privilege escalation and with all the capabilities dropped."},
+		false,
+		nil,
+	)
+}
+
+// DownwardAPIEnv returns true if the env vars with the pod and node information should be injected into the containers of the service
+func DownwardAPIEnv(serviceName string) bool {
+	return qaengine.FetchBoolAnswer(
+		common.JoinQASubKeys(common.ConfigServicesKey, `"`+serviceName+`"`, common.ConfigDownwardAPIEnvForServiceKeySegment),
+		fmt.Sprintf("Inject the env vars POD_NAME, POD_NAMESPACE, POD_IP and NODE_NAME into the containers of the service '%s'?", serviceName),
+		[]string{"Many apps use them for clustering and peer discovery."},
+		false,
+		nil,
+	)
+}
+
+// ExpandPortRange returns true if all the ports in a huge port range of the service should be exposed, instead of only the first one
+func ExpandPortRange(serviceName string, portRange string, size int) bool {
+	return qaengine.FetchBoolAnswer(
+		common.JoinQASubKeys(common.ConfigServicesKey, `"`+serviceName+`"`, common.ConfigPortRangeForServiceKeySegment, `"`+portRange+`"`),
+		fmt.Sprintf("The port range %s of the service '%s' has %d ports. Create a container port and a service port for each of them?", portRange, serviceName, size),
+		[]string{"Otherwise only the first port of the range is exposed."},
+		false,
+		nil,
+	)
+}
+
+// PreStopDelay returns the seconds the containers of the service should wait before stopping, so that the in-flight requests are drained.
+// Zero means the containers stop immediately.
+func PreStopDelay(serviceName string) int64 {
+	delayStr := qaengine.FetchStringAnswer(
+		common.JoinQASubKeys(common.ConfigServicesKey, `"`+serviceName+`"`, common.ConfigPreStopDelayForServiceKeySegment),
+		fmt.Sprintf("Enter the seconds the containers of the service '%s' should wait before stopping :", serviceName),
+		[]string{"This gives the load balancers time to stop sending requests to the stopping pods. Enter 0 to stop immediately."},
+		"0",
+		func(delay interface{}) error {
+			delayI, err := cast.ToInt64E(delay)
+			if err != nil {
+				return err
+			}
+			if delayI < 0 {
+				return fmt.Errorf("the delay should not be negative")
+			}
+			return nil
+		},
+	)
+	delay, err := cast.ToInt64E(delayStr)
+	if err != nil || delay < 0 {
+		logrus.Errorf("The delay %s is not a valid number of seconds. Not adding a delay.", delayStr)
+		return 0
+	}
+	return delay
+}
+
 // IngressHost returns Ingress host
 func IngressHost(defaulthost string, clusterQaLabel string) string {
 	key := common.JoinQASubKeys(common.ConfigTargetKey, `"`+clusterQaLabel+`"`, common.ConfigIngressHostKeySuffix)
@@ -98,6 +509,60 @@ func MinimumReplicaCount(defaultminreplicas string) string {
 	})
 }
 
+// Namespace returns the namespace the generated resources are deployed to. An empty string means no namespace is set.
+func Namespace(defaultNamespace string) string {
+	return qaengine.FetchStringAnswer(common.ConfigNamespaceKey, "Enter the namespace to deploy the generated resources to :", []string{"Leave it empty to deploy them to the namespace of the current context."}, defaultNamespace, func(namespace interface{}) error {
+		namespaceStr := cast.ToString(namespace)
+		if namespaceStr == "" {
+			return nil
+		}
+		if errs := validation.IsDNS1123Label(namespaceStr); len(errs) != 0 {
+			return fmt.Errorf("the namespace name '%s' is invalid. %s", namespaceStr, strings.Join(errs, ". "))
+		}
+		return nil
+	})
+}
+
+// ProjectNamePrefix returns whether to prefix the names of the services of the project, and of the storages they use, with the project name
+func ProjectNamePrefix(project string, defaultPrefix bool) bool {
+	key := common.JoinQASubKeys(common.ConfigNamingProjectsKey, `"`+project+`"`, common.ConfigPrefixForProjectKeySegment)
+	return qaengine.FetchBoolAnswer(key, fmt.Sprintf("Prefix the names of the services of the project '%s' with the project name?", project), []string{"This keeps the same named services of multiple projects from being merged into one service.", "The services are then reachable using the prefixed names."}, defaultPrefix, nil)
+}
+
+// NamePrefix returns the prefix to add to the names of the generated resources
+func NamePrefix() string {
+	return qaengine.FetchStringAnswer(common.ConfigNamingPrefixKey, "Enter the prefix to add to the names of the generated resources :", []string{"Leave it empty to not add a prefix."}, "", nil)
+}
+
+// NameSuffix returns the suffix to add to the names of the generated resources
+func NameSuffix() string {
+	return qaengine.FetchStringAnswer(common.ConfigNamingSuffixKey, "Enter the suffix to add to the names of the generated resources :", []string{"Leave it empty to not add a suffix."}, "", nil)
+}
+
+// NameSeparator returns the separator between the prefix, the name and the suffix of the generated resources
+func NameSeparator(defaultSeparator string) string {
+	return qaengine.FetchStringAnswer(common.ConfigNamingSeparatorKey, "Enter the separator to use between the prefix, the name and the suffix :", []string{"The names can only contain lowercase alphanumeric characters and '-'"}, defaultSeparator, func(separator interface{}) error {
+		if !regexp.MustCompile(`^-*$`).MatchString(cast.ToString(separator)) {
+			return fmt.Errorf("the separator can only contain '-'")
+		}
+		return nil
+	})
+}
+
+// NameMaxLength returns the maximum length of the names of the generated resources
+func NameMaxLength(defaultMaxLength string) string {
+	return qaengine.FetchStringAnswer(common.ConfigNamingMaxLengthKey, "Enter the maximum length of the names of the generated resources :", []string{"Longer names are shortened and a hash is added to keep them unique."}, defaultMaxLength, func(maxLength interface{}) error {
+		maxLengthI, err := cast.ToIntE(maxLength)
+		if err != nil {
+			return err
+		}
+		if maxLengthI < 16 || maxLengthI > 63 {
+			return fmt.Errorf("the maximum length should be between 16 and 63")
+		}
+		return nil
+	})
+}
+
 // GetPortsForService returns ports used by a service
 func GetPortsForService(detectedPorts []int32, qaSubKey string) []int32 {
 	var selectedPortsStr, detectedPortsStr []string
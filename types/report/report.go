@@ -0,0 +1,87 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package report
+
+import (
+	"github.com/konveyor/move2kube/types"
+)
+
+// MigrationReportKind defines kind of the migration report
+const MigrationReportKind types.Kind = "MigrationReport"
+
+// MigrationReport lists what every source artifact was converted to, what was dropped and what is left to do by hand
+type MigrationReport struct {
+	types.TypeMeta   `yaml:",inline" json:",inline"`
+	types.ObjectMeta `yaml:"metadata,omitempty" json:"metadata,omitempty"`
+	Spec             MigrationReportSpec `yaml:"spec,omitempty" json:"spec,omitempty"`
+}
+
+// MigrationReportSpec stores the data
+type MigrationReportSpec struct {
+	GeneratedAt        string              `yaml:"generatedAt,omitempty" json:"generatedAt,omitempty"`
+	Move2KubeVersion   string              `yaml:"move2kubeVersion,omitempty" json:"move2kubeVersion,omitempty"`
+	Services           []ServiceReport     `yaml:"services" json:"services"`
+	GeneratedArtifacts []GeneratedArtifact `yaml:"generatedArtifacts" json:"generatedArtifacts"`
+	DroppedFields      []DroppedField      `yaml:"droppedFields" json:"droppedFields"`
+	QAAnswers          []QAAnswer          `yaml:"qaAnswers" json:"qaAnswers"`
+	FollowUps          []FollowUp          `yaml:"followUps" json:"followUps"`
+}
+
+// ServiceReport lists the source artifacts of a service and the transformer that converted them
+type ServiceReport struct {
+	Name        string   `yaml:"name" json:"name"`
+	Transformer string   `yaml:"transformer" json:"transformer"`
+	SourcePaths []string `yaml:"sourcePaths,omitempty" json:"sourcePaths,omitempty"`
+}
+
+// GeneratedArtifact is an artifact produced by a transformer
+type GeneratedArtifact struct {
+	Transformer string   `yaml:"transformer" json:"transformer"`
+	Name        string   `yaml:"name" json:"name"`
+	Type        string   `yaml:"type" json:"type"`
+	Paths       []string `yaml:"paths,omitempty" json:"paths,omitempty"`
+}
+
+// DroppedField is a field of a source artifact that was not converted
+type DroppedField struct {
+	Service string `yaml:"service" json:"service"`
+	Field   string `yaml:"field" json:"field"`
+	Reason  string `yaml:"reason" json:"reason"`
+}
+
+// QAAnswer is a question asked during the transformation along with the answer that was used
+type QAAnswer struct {
+	ID       string      `yaml:"id" json:"id"`
+	Question string      `yaml:"question,omitempty" json:"question,omitempty"`
+	Answer   interface{} `yaml:"answer" json:"answer"`
+}
+
+// FollowUp is a manual step that must be done before deploying the output
+type FollowUp struct {
+	Service     string `yaml:"service,omitempty" json:"service,omitempty"`
+	Description string `yaml:"description" json:"description"`
+}
+
+// NewMigrationReport creates a new instance of MigrationReport
+func NewMigrationReport() MigrationReport {
+	return MigrationReport{
+		TypeMeta: types.TypeMeta{
+			Kind:       string(MigrationReportKind),
+			APIVersion: types.SchemeGroupVersion.String(),
+		},
+	}
+}
@@ -0,0 +1,348 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+// If this file is updated, protoc needs to be installed and the following command needs to be executed again in this directory
+// protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative move2kube.proto
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: move2kube.proto
+
+package servergrpc
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Move2Kube_CreatePlan_FullMethodName      = "/servergrpc.Move2Kube/CreatePlan"
+	Move2Kube_GetJob_FullMethodName          = "/servergrpc.Move2Kube/GetJob"
+	Move2Kube_StartTransform_FullMethodName  = "/servergrpc.Move2Kube/StartTransform"
+	Move2Kube_StreamQuestions_FullMethodName = "/servergrpc.Move2Kube/StreamQuestions"
+	Move2Kube_FetchArtifacts_FullMethodName  = "/servergrpc.Move2Kube/FetchArtifacts"
+)
+
+// Move2KubeClient is the client API for Move2Kube service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type Move2KubeClient interface {
+	// CreatePlan uploads the source archive and returns the job once the planning has finished
+	CreatePlan(ctx context.Context, in *CreatePlanRequest, opts ...grpc.CallOption) (*Job, error)
+	// GetJob returns the status of a job
+	GetJob(ctx context.Context, in *JobRequest, opts ...grpc.CallOption) (*Job, error)
+	// StartTransform starts transforming a planned job
+	StartTransform(ctx context.Context, in *StartTransformRequest, opts ...grpc.CallOption) (*Job, error)
+	// StreamQuestions sends the questions asked during the transformation and receives their solutions.
+	// The first message on the stream should only contain the job id.
+	StreamQuestions(ctx context.Context, opts ...grpc.CallOption) (Move2Kube_StreamQuestionsClient, error)
+	// FetchArtifacts streams the transformed output as a zip archive
+	FetchArtifacts(ctx context.Context, in *JobRequest, opts ...grpc.CallOption) (Move2Kube_FetchArtifactsClient, error)
+}
+
+type move2KubeClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewMove2KubeClient(cc grpc.ClientConnInterface) Move2KubeClient {
+	return &move2KubeClient{cc}
+}
+
+func (c *move2KubeClient) CreatePlan(ctx context.Context, in *CreatePlanRequest, opts ...grpc.CallOption) (*Job, error) {
+	out := new(Job)
+	err := c.cc.Invoke(ctx, Move2Kube_CreatePlan_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *move2KubeClient) GetJob(ctx context.Context, in *JobRequest, opts ...grpc.CallOption) (*Job, error) {
+	out := new(Job)
+	err := c.cc.Invoke(ctx, Move2Kube_GetJob_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *move2KubeClient) StartTransform(ctx context.Context, in *StartTransformRequest, opts ...grpc.CallOption) (*Job, error) {
+	out := new(Job)
+	err := c.cc.Invoke(ctx, Move2Kube_StartTransform_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *move2KubeClient) StreamQuestions(ctx context.Context, opts ...grpc.CallOption) (Move2Kube_StreamQuestionsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Move2Kube_ServiceDesc.Streams[0], Move2Kube_StreamQuestions_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &move2KubeStreamQuestionsClient{stream}
+	return x, nil
+}
+
+type Move2Kube_StreamQuestionsClient interface {
+	Send(*Solution) error
+	Recv() (*Question, error)
+	grpc.ClientStream
+}
+
+type move2KubeStreamQuestionsClient struct {
+	grpc.ClientStream
+}
+
+func (x *move2KubeStreamQuestionsClient) Send(m *Solution) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *move2KubeStreamQuestionsClient) Recv() (*Question, error) {
+	m := new(Question)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *move2KubeClient) FetchArtifacts(ctx context.Context, in *JobRequest, opts ...grpc.CallOption) (Move2Kube_FetchArtifactsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Move2Kube_ServiceDesc.Streams[1], Move2Kube_FetchArtifacts_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &move2KubeFetchArtifactsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Move2Kube_FetchArtifactsClient interface {
+	Recv() (*ArtifactChunk, error)
+	grpc.ClientStream
+}
+
+type move2KubeFetchArtifactsClient struct {
+	grpc.ClientStream
+}
+
+func (x *move2KubeFetchArtifactsClient) Recv() (*ArtifactChunk, error) {
+	m := new(ArtifactChunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Move2KubeServer is the server API for Move2Kube service.
+// All implementations must embed UnimplementedMove2KubeServer
+// for forward compatibility
+type Move2KubeServer interface {
+	// CreatePlan uploads the source archive and returns the job once the planning has finished
+	CreatePlan(context.Context, *CreatePlanRequest) (*Job, error)
+	// GetJob returns the status of a job
+	GetJob(context.Context, *JobRequest) (*Job, error)
+	// StartTransform starts transforming a planned job
+	StartTransform(context.Context, *StartTransformRequest) (*Job, error)
+	// StreamQuestions sends the questions asked during the transformation and receives their solutions.
+	// The first message on the stream should only contain the job id.
+	StreamQuestions(Move2Kube_StreamQuestionsServer) error
+	// FetchArtifacts streams the transformed output as a zip archive
+	FetchArtifacts(*JobRequest, Move2Kube_FetchArtifactsServer) error
+	mustEmbedUnimplementedMove2KubeServer()
+}
+
+// UnimplementedMove2KubeServer must be embedded to have forward compatible implementations.
+type UnimplementedMove2KubeServer struct {
+}
+
+func (UnimplementedMove2KubeServer) CreatePlan(context.Context, *CreatePlanRequest) (*Job, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreatePlan not implemented")
+}
+func (UnimplementedMove2KubeServer) GetJob(context.Context, *JobRequest) (*Job, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetJob not implemented")
+}
+func (UnimplementedMove2KubeServer) StartTransform(context.Context, *StartTransformRequest) (*Job, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartTransform not implemented")
+}
+func (UnimplementedMove2KubeServer) StreamQuestions(Move2Kube_StreamQuestionsServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamQuestions not implemented")
+}
+func (UnimplementedMove2KubeServer) FetchArtifacts(*JobRequest, Move2Kube_FetchArtifactsServer) error {
+	return status.Errorf(codes.Unimplemented, "method FetchArtifacts not implemented")
+}
+func (UnimplementedMove2KubeServer) mustEmbedUnimplementedMove2KubeServer() {}
+
+// UnsafeMove2KubeServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to Move2KubeServer will
+// result in compilation errors.
+type UnsafeMove2KubeServer interface {
+	mustEmbedUnimplementedMove2KubeServer()
+}
+
+func RegisterMove2KubeServer(s grpc.ServiceRegistrar, srv Move2KubeServer) {
+	s.RegisterService(&Move2Kube_ServiceDesc, srv)
+}
+
+func _Move2Kube_CreatePlan_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreatePlanRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(Move2KubeServer).CreatePlan(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Move2Kube_CreatePlan_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(Move2KubeServer).CreatePlan(ctx, req.(*CreatePlanRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Move2Kube_GetJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(Move2KubeServer).GetJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Move2Kube_GetJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(Move2KubeServer).GetJob(ctx, req.(*JobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Move2Kube_StartTransform_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StartTransformRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(Move2KubeServer).StartTransform(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Move2Kube_StartTransform_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(Move2KubeServer).StartTransform(ctx, req.(*StartTransformRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Move2Kube_StreamQuestions_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(Move2KubeServer).StreamQuestions(&move2KubeStreamQuestionsServer{stream})
+}
+
+type Move2Kube_StreamQuestionsServer interface {
+	Send(*Question) error
+	Recv() (*Solution, error)
+	grpc.ServerStream
+}
+
+type move2KubeStreamQuestionsServer struct {
+	grpc.ServerStream
+}
+
+func (x *move2KubeStreamQuestionsServer) Send(m *Question) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *move2KubeStreamQuestionsServer) Recv() (*Solution, error) {
+	m := new(Solution)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Move2Kube_FetchArtifacts_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(JobRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(Move2KubeServer).FetchArtifacts(m, &move2KubeFetchArtifactsServer{stream})
+}
+
+type Move2Kube_FetchArtifactsServer interface {
+	Send(*ArtifactChunk) error
+	grpc.ServerStream
+}
+
+type move2KubeFetchArtifactsServer struct {
+	grpc.ServerStream
+}
+
+func (x *move2KubeFetchArtifactsServer) Send(m *ArtifactChunk) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// Move2Kube_ServiceDesc is the grpc.ServiceDesc for Move2Kube service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Move2Kube_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "servergrpc.Move2Kube",
+	HandlerType: (*Move2KubeServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreatePlan",
+			Handler:    _Move2Kube_CreatePlan_Handler,
+		},
+		{
+			MethodName: "GetJob",
+			Handler:    _Move2Kube_GetJob_Handler,
+		},
+		{
+			MethodName: "StartTransform",
+			Handler:    _Move2Kube_StartTransform_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamQuestions",
+			Handler:       _Move2Kube_StreamQuestions_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "FetchArtifacts",
+			Handler:       _Move2Kube_FetchArtifacts_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "move2kube.proto",
+}
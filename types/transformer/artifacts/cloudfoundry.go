@@ -25,6 +25,10 @@ const (
 	CfManifestPathType transformertypes.PathType = "CfManifest"
 	// CfRunningManifestPathType defines the source artifact type of a manifest of a running instance
 	CfRunningManifestPathType transformertypes.PathType = "CfRunningManifest"
+	// CfServicesPathType defines the source artifact type of the collected cf services containing the service bindings of the app
+	CfServicesPathType transformertypes.PathType = "CfServices"
+	// CfVcapServicesPathType defines the source artifact type of an exported VCAP_SERVICES of the app
+	CfVcapServicesPathType transformertypes.PathType = "CfVcapServices"
 )
 
 const (
@@ -34,4 +34,14 @@ const (
 const (
 	// DockerfileTemplateConfigConfigType stores the imagename for the dockerfile
 	DockerfileTemplateConfigConfigType transformertypes.ConfigType = "DockerfileTemplateConfig"
+	// DockerfileBuildOptionsConfigType stores the options for building the image from the dockerfile
+	DockerfileBuildOptionsConfigType transformertypes.ConfigType = "DockerfileBuildOptions"
 )
+
+// DockerfileBuildOptions are the build args, the target stage and the labels used when building the image from the dockerfile.
+// A build arg without a value is taken from the environment of the build.
+type DockerfileBuildOptions struct {
+	BuildArgs map[string]*string `yaml:"buildArgs,omitempty" json:"buildArgs,omitempty"`
+	Target    string             `yaml:"target,omitempty" json:"target,omitempty"`
+	Labels    map[string]string  `yaml:"labels,omitempty" json:"labels,omitempty"`
+}
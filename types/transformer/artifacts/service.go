@@ -29,4 +29,6 @@ const ServiceConfigType transformertypes.ConfigType = "Service"
 // ServiceConfig stores config related to service
 type ServiceConfig struct {
 	ServiceName string `yaml:"serviceName"`
+	// Language is the language of the app, detected by the transformer that generated its Dockerfile
+	Language string `yaml:"language,omitempty"`
 }
@@ -0,0 +1,57 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"github.com/konveyor/move2kube/types"
+)
+
+// MetadataInjectionKind represents the MetadataInjection kind
+const MetadataInjectionKind = "MetadataInjection"
+
+// MetadataInjection lets the user add labels and annotations to the generated k8s resources
+type MetadataInjection struct {
+	types.TypeMeta   `yaml:",inline" json:",inline"`
+	types.ObjectMeta `yaml:"metadata,omitempty" json:"metadata,omitempty"`
+	Spec             MetadataInjectionSpec `yaml:"spec,omitempty" json:"spec,omitempty"`
+}
+
+// MetadataInjectionSpec stores the rules for adding labels and annotations
+type MetadataInjectionSpec struct {
+	Rules []MetadataInjectionRule `yaml:"rules,omitempty" json:"rules,omitempty"`
+}
+
+// MetadataInjectionRule adds the labels and annotations to the resources that match its selectors.
+// The injected values take precedence over the ones generated by the transformers.
+type MetadataInjectionRule struct {
+	// Kinds is a list of kinds (case insensitive) to match. An empty list matches all the kinds.
+	Kinds []string `yaml:"kinds,omitempty" json:"kinds,omitempty"`
+	// Names is a list of regular expressions that must match the whole name. An empty list matches all the names.
+	Names       []string          `yaml:"names,omitempty" json:"names,omitempty"`
+	Labels      map[string]string `yaml:"labels,omitempty" json:"labels,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty" json:"annotations,omitempty"`
+}
+
+// NewMetadataInjection creates a new instance of metadata injection
+func NewMetadataInjection() MetadataInjection {
+	return MetadataInjection{
+		TypeMeta: types.TypeMeta{
+			Kind:       MetadataInjectionKind,
+			APIVersion: types.SchemeGroupVersion.String(),
+		},
+	}
+}
@@ -0,0 +1,54 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"github.com/konveyor/move2kube/types"
+)
+
+// TransformerOrderingKind represents the TransformerOrdering kind
+const TransformerOrderingKind = "TransformerOrdering"
+
+// TransformerOrdering lets the user override the order in which transformers run and disable transformers
+type TransformerOrdering struct {
+	types.TypeMeta   `yaml:",inline" json:",inline"`
+	types.ObjectMeta `yaml:"metadata,omitempty" json:"metadata,omitempty"`
+	Spec             TransformerOrderingSpec `yaml:"spec,omitempty" json:"spec,omitempty"`
+}
+
+// TransformerOrderingSpec stores the ordering overrides
+type TransformerOrderingSpec struct {
+	// Order is a list of transformer names. Each transformer runs after the ones before it in the list.
+	Order []string `yaml:"order,omitempty" json:"order,omitempty"`
+	// RunAfter maps a transformer name to the names of the transformers that must run before it
+	RunAfter map[string][]string `yaml:"runAfter,omitempty" json:"runAfter,omitempty"`
+	// Disabled is a list of transformer names that should not be run
+	Disabled []string `yaml:"disabled,omitempty" json:"disabled,omitempty"`
+}
+
+// NewTransformerOrdering creates a new instance of transformer ordering
+func NewTransformerOrdering() TransformerOrdering {
+	return TransformerOrdering{
+		TypeMeta: types.TypeMeta{
+			Kind:       TransformerOrderingKind,
+			APIVersion: types.SchemeGroupVersion.String(),
+		},
+		Spec: TransformerOrderingSpec{
+			RunAfter: map[string][]string{},
+		},
+	}
+}
@@ -0,0 +1,69 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package transformer
+
+import (
+	"github.com/konveyor/move2kube/types"
+)
+
+// ResourcePatchKind represents the ResourcePatch kind
+const ResourcePatchKind = "ResourcePatch"
+
+// ResourcePatchType is the type of a patch
+type ResourcePatchType string
+
+const (
+	// StrategicMergeResourcePatchType is a strategic merge patch. Resources that are not built-in k8s kinds use a json merge patch instead.
+	StrategicMergeResourcePatchType ResourcePatchType = "strategic"
+	// JSON6902ResourcePatchType is a json patch https://tools.ietf.org/html/rfc6902
+	JSON6902ResourcePatchType ResourcePatchType = "json6902"
+)
+
+// ResourcePatch lets the user patch the generated k8s resources
+type ResourcePatch struct {
+	types.TypeMeta   `yaml:",inline" json:",inline"`
+	types.ObjectMeta `yaml:"metadata,omitempty" json:"metadata,omitempty"`
+	Spec             ResourcePatchSpec `yaml:"spec,omitempty" json:"spec,omitempty"`
+}
+
+// ResourcePatchSpec stores the patches
+type ResourcePatchSpec struct {
+	Patches []ResourcePatchRule `yaml:"patches,omitempty" json:"patches,omitempty"`
+}
+
+// ResourcePatchRule applies the patch to the resources that match its selectors.
+// The patches are applied in order after all the resources have been generated.
+type ResourcePatchRule struct {
+	// Kinds is a list of kinds (case insensitive) to match. An empty list matches all the kinds.
+	Kinds []string `yaml:"kinds,omitempty" json:"kinds,omitempty"`
+	// Names is a list of regular expressions that must match the whole name. An empty list matches all the names.
+	Names []string          `yaml:"names,omitempty" json:"names,omitempty"`
+	Type  ResourcePatchType `yaml:"type,omitempty" json:"type,omitempty"`
+	// Path is the path of the patch file, relative to the directory containing the resource patch config
+	Path string `yaml:"path,omitempty" json:"path,omitempty"`
+	// Patch is the patch in yaml. It is used when no path is specified.
+	Patch string `yaml:"patch,omitempty" json:"patch,omitempty"`
+}
+
+// NewResourcePatch creates a new instance of resource patch
+func NewResourcePatch() ResourcePatch {
+	return ResourcePatch{
+		TypeMeta: types.TypeMeta{
+			Kind:       ResourcePatchKind,
+			APIVersion: types.SchemeGroupVersion.String(),
+		},
+	}
+}
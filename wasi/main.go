@@ -0,0 +1,106 @@
+//go:build wasip1
+
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+// Package main is the headless WASI build of move2kube.
+// It plans and transforms the source directory into the output directory without asking any questions:
+//
+//	move2kube.wasm <source directory> <output directory> [project name]
+//
+// Both directories must be preopened by the wasm runtime, for example `wasmtime --dir . move2kube.wasm src out`.
+// The engine runs sandboxed, so no executables or containers are run and remote content is only read
+// from the cache unless the host sets the M2K_ALLOW_NETWORK environment variable to true.
+// A m2kconfig.yaml in the output directory is used to answer the questions.
+// The git repos are not supported, so the source and output directories can not be git urls.
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/konveyor/move2kube/assets"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/lib"
+	"github.com/konveyor/move2kube/qaengine"
+	plantypes "github.com/konveyor/move2kube/types/plan"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+const allowNetworkEnvVar = "M2K_ALLOW_NETWORK"
+
+func main() {
+	if len(os.Args) < 3 {
+		logrus.Fatalf("Usage: %s <source directory> <output directory> [project name]", filepath.Base(os.Args[0]))
+	}
+	srcPath, outPath, prjName := os.Args[1], os.Args[2], common.DefaultProjectName
+	if len(os.Args) > 3 {
+		prjName = os.Args[3]
+	}
+	allowNetwork, _ := strconv.ParseBool(os.Getenv(allowNetworkEnvVar))
+	if err := run(srcPath, outPath, prjName, allowNetwork); err != nil {
+		logrus.Fatalf("%s", err)
+	}
+	logrus.Infof("Transformed target artifacts can be found at [%s].", outPath)
+}
+
+// run plans and transforms the source directory into the output directory
+func run(srcPath, outPath, prjName string, allowNetwork bool) error {
+	assetsFilePermissions := map[string]int{}
+	if err := yaml.Unmarshal([]byte(assets.AssetFilePermissions), &assetsFilePermissions); err != nil {
+		return fmt.Errorf("failed to unmarshal the assets permissions file as YAML. Error: %w", err)
+	}
+	assetsPath, tempPath, remoteTempPath, err := common.CreateAssetsData(assets.AssetsDir, assetsFilePermissions)
+	if err != nil {
+		return fmt.Errorf("failed to create the assets directory. Error: %w", err)
+	}
+	common.TempPath = tempPath
+	common.AssetsPath = assetsPath
+	common.RemoteTempPath = remoteTempPath
+	defer os.RemoveAll(tempPath)
+	defer os.RemoveAll(remoteTempPath)
+	lib.ApplySandbox(allowNetwork)
+	if err := os.MkdirAll(outPath, common.DefaultDirectoryPermission); err != nil {
+		return fmt.Errorf("failed to create the output directory at path %s Error: %w", outPath, err)
+	}
+	qaengine.StartEngine(true, 0, true)
+	configPath := filepath.Join(outPath, common.ConfigFile)
+	configFiles := []string{}
+	if _, err := os.Stat(configPath); err == nil {
+		configFiles = append(configFiles, configPath)
+	}
+	qaengine.SetupConfigFile(configPath, nil, configFiles, []string{lib.SandboxedPreset}, false)
+	ctx := context.Background()
+	plan, err := lib.CreatePlan(ctx, srcPath, outPath, "", "", prjName)
+	if err != nil {
+		return fmt.Errorf("failed to create the plan. Error: %w", err)
+	}
+	if err := lib.Transform(ctx, plan, false, outPath, "", -1); err != nil {
+		return fmt.Errorf("failed to transform. Error: %w", err)
+	}
+	// the plan is written after the transformation, since the transformation replaces the contents of the output directory
+	if err := plantypes.WritePlan(filepath.Join(outPath, common.DefaultPlanFile), plan); err != nil {
+		return fmt.Errorf("failed to write the plan. Error: %w", err)
+	}
+	if err := qaengine.WriteStoresToDisk(); err != nil {
+		logrus.Warnf("failed to write the config to disk. Error: %q", err)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+//go:build wasip1
+
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/sirupsen/logrus"
+)
+
+const testComposeFile = `version: "3.8"
+services:
+  web:
+    image: nginx:1.25
+    ports:
+      - "8080:80"
+`
+
+func TestRun(t *testing.T) {
+	logrus.SetLevel(logrus.WarnLevel)
+	// the graph of the transformation is written to the working directory
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get the working directory. Error: %q", err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatalf("failed to change the working directory. Error: %q", err)
+	}
+	defer os.Chdir(wd)
+	srcPath := filepath.Join(t.TempDir(), "app")
+	if err := os.MkdirAll(srcPath, common.DefaultDirectoryPermission); err != nil {
+		t.Fatalf("failed to create the source directory. Error: %q", err)
+	}
+	if err := os.WriteFile(filepath.Join(srcPath, "docker-compose.yaml"), []byte(testComposeFile), common.DefaultFilePermission); err != nil {
+		t.Fatalf("failed to write the compose file. Error: %q", err)
+	}
+	outPath := filepath.Join(t.TempDir(), "myproject")
+	if err := run(srcPath, outPath, "myproject", false); err != nil {
+		t.Fatalf("failed to plan and transform the source directory. Error: %q", err)
+	}
+	if _, err := os.Stat(filepath.Join(outPath, common.DefaultPlanFile)); err != nil {
+		t.Fatalf("expected the plan to be written to the output directory. Error: %q", err)
+	}
+	deploymentPath := filepath.Join(outPath, "deploy", "yamls", "web-deployment.yaml")
+	if _, err := os.Stat(deploymentPath); err != nil {
+		t.Fatalf("expected the deployment of the service 'web' at %s . Error: %q", deploymentPath, err)
+	}
+}
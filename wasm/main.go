@@ -0,0 +1,194 @@
+//go:build js && wasm
+
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+// Package main is the browser build of move2kube.
+// The Go js/wasm runtime performs all the file operations through the global `fs` object of the host page,
+// so the page must load memfs.js, which installs an in-memory filesystem as `globalThis.fs`, before wasm_exec.js.
+// The module then exposes the `move2kube` global object with the following functions:
+//
+//	move2kube.version(long?: boolean): string
+//	move2kube.plan(archive: Uint8Array, archiveName: string, projectName?: string, transformerSelector?: string): Promise<string>
+//	move2kube.transform(archive: Uint8Array, archiveName: string, plan: string, transformerSelector?: string, maxIterations?: number): Promise<Uint8Array>
+//
+// The archive is a zip or tar(.gz) of the sources, the plan is the yaml returned by plan and
+// transform resolves to a zip of the generated output. All the questions are answered with their defaults.
+// The engine runs sandboxed, so no executables or containers are run and remote content is only read from the cache.
+// The calls run one at a time, since the engine keeps its state in globals. The WASM and CloudFoundry transformers are not available.
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"syscall/js"
+
+	"github.com/konveyor/move2kube/assets"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/lib"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/transformer"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// engineMutex serializes the calls, since each call resets the global state of the engine
+var engineMutex sync.Mutex
+
+func main() {
+	cleanup, err := setup()
+	if err != nil {
+		logrus.Fatalf("failed to set up the engine. Error: %q", err)
+	}
+	defer cleanup()
+	js.Global().Set("move2kube", js.ValueOf(map[string]interface{}{
+		"version":   js.FuncOf(version),
+		"plan":      js.FuncOf(plan),
+		"transform": js.FuncOf(transform),
+	}))
+	select {}
+}
+
+// setup creates the assets directory and sandboxes the engine. The returned function removes the temporary directories.
+func setup() (func(), error) {
+	assetsFilePermissions := map[string]int{}
+	if err := yaml.Unmarshal([]byte(assets.AssetFilePermissions), &assetsFilePermissions); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the assets permissions file as YAML. Error: %w", err)
+	}
+	assetsPath, tempPath, remoteTempPath, err := common.CreateAssetsData(assets.AssetsDir, assetsFilePermissions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the assets directory. Error: %w", err)
+	}
+	common.TempPath = tempPath
+	common.AssetsPath = assetsPath
+	common.RemoteTempPath = remoteTempPath
+	lib.ApplySandbox(false)
+	return func() {
+		os.RemoveAll(tempPath)
+		os.RemoveAll(remoteTempPath)
+	}, nil
+}
+
+func version(_ js.Value, args []js.Value) interface{} {
+	return lib.GetVersion(getBoolArg(args, 0))
+}
+
+func plan(_ js.Value, args []js.Value) interface{} {
+	if len(args) < 2 {
+		return rejected(fmt.Errorf("plan expects the source archive and its name"))
+	}
+	archive := getBytesArg(args, 0)
+	archiveName := args[1].String()
+	prjName := getStringArg(args, 2, common.DefaultProjectName)
+	transformerSelector := getStringArg(args, 3, "")
+	return promise(func() (interface{}, error) {
+		planYaml, err := planArchive(archive, archiveName, transformerSelector, prjName)
+		if err != nil {
+			return nil, err
+		}
+		return string(planYaml), nil
+	})
+}
+
+func transform(_ js.Value, args []js.Value) interface{} {
+	if len(args) < 3 {
+		return rejected(fmt.Errorf("transform expects the source archive, its name and the plan"))
+	}
+	archive := getBytesArg(args, 0)
+	archiveName := args[1].String()
+	planYaml := []byte(args[2].String())
+	transformerSelector := getStringArg(args, 3, "")
+	maxIterations := -1
+	if len(args) > 4 && args[4].Type() == js.TypeNumber {
+		maxIterations = args[4].Int()
+	}
+	return promise(func() (interface{}, error) {
+		output, err := transformArchive(archive, archiveName, planYaml, transformerSelector, maxIterations)
+		if err != nil {
+			return nil, err
+		}
+		outputArray := js.Global().Get("Uint8Array").New(len(output))
+		js.CopyBytesToJS(outputArray, output)
+		return outputArray, nil
+	})
+}
+
+// planArchive plans the source archive, after the previous call has finished
+func planArchive(archive []byte, archiveName, transformerSelector, prjName string) ([]byte, error) {
+	engineMutex.Lock()
+	defer engineMutex.Unlock()
+	resetGlobalState()
+	return lib.PlanArchive(context.Background(), archive, archiveName, transformerSelector, prjName)
+}
+
+// transformArchive transforms the source archive using the plan, after the previous call has finished
+func transformArchive(archive []byte, archiveName string, planYaml []byte, transformerSelector string, maxIterations int) ([]byte, error) {
+	engineMutex.Lock()
+	defer engineMutex.Unlock()
+	resetGlobalState()
+	return lib.TransformArchive(context.Background(), archive, archiveName, planYaml, transformerSelector, maxIterations)
+}
+
+// resetGlobalState clears the transformers and QA engines left behind by the previous call.
+// It must be called with the engineMutex held.
+func resetGlobalState() {
+	transformer.Reset()
+	qaengine.ResetEngines()
+	qaengine.StartEngine(true, 0, true)
+	qaengine.SetupConfigFile("", nil, nil, []string{lib.SandboxedPreset}, false)
+}
+
+// promise runs the function asynchronously and returns a JS promise settled with its result
+func promise(run func() (interface{}, error)) js.Value {
+	var handler js.Func
+	handler = js.FuncOf(func(_ js.Value, args []js.Value) interface{} {
+		resolve, reject := args[0], args[1]
+		go func() {
+			defer handler.Release()
+			result, err := run()
+			if err != nil {
+				reject.Invoke(js.Global().Get("Error").New(err.Error()))
+				return
+			}
+			resolve.Invoke(result)
+		}()
+		return nil
+	})
+	return js.Global().Get("Promise").New(handler)
+}
+
+func rejected(err error) js.Value {
+	return js.Global().Get("Promise").Call("reject", js.Global().Get("Error").New(err.Error()))
+}
+
+func getBytesArg(args []js.Value, idx int) []byte {
+	data := make([]byte, args[idx].Get("length").Int())
+	js.CopyBytesToGo(data, args[idx])
+	return data
+}
+
+func getStringArg(args []js.Value, idx int, def string) string {
+	if len(args) <= idx || args[idx].Type() != js.TypeString || args[idx].String() == "" {
+		return def
+	}
+	return args[idx].String()
+}
+
+func getBoolArg(args []js.Value, idx int) bool {
+	return len(args) > idx && args[idx].Type() == js.TypeBoolean && args[idx].Bool()
+}
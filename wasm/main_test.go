@@ -0,0 +1,114 @@
+//go:build js && wasm
+
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+const testComposeFile = `version: "3.8"
+services:
+  web:
+    image: nginx:1.25
+    ports:
+      - "8080:80"
+`
+
+func TestMain(m *testing.M) {
+	logrus.SetLevel(logrus.WarnLevel)
+	cleanup, err := setup()
+	if err != nil {
+		logrus.Fatalf("failed to set up the engine. Error: %q", err)
+	}
+	code := m.Run()
+	cleanup()
+	os.Exit(code)
+}
+
+func getTestArchive(t *testing.T) []byte {
+	archive := bytes.Buffer{}
+	w := zip.NewWriter(&archive)
+	f, err := w.Create("app/docker-compose.yaml")
+	if err != nil {
+		t.Fatalf("failed to add the compose file to the archive. Error: %q", err)
+	}
+	if _, err := io.WriteString(f, testComposeFile); err != nil {
+		t.Fatalf("failed to write the compose file to the archive. Error: %q", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("failed to close the archive. Error: %q", err)
+	}
+	return archive.Bytes()
+}
+
+func TestPlanAndTransformArchive(t *testing.T) {
+	archive := getTestArchive(t)
+	planYaml, err := planArchive(archive, "app.zip", "", "myproject")
+	if err != nil {
+		t.Fatalf("failed to plan the archive. Error: %q", err)
+	}
+	if !strings.Contains(string(planYaml), "web:") {
+		t.Fatalf("expected the plan to contain the service 'web'. Actual:\n%s", planYaml)
+	}
+	output, err := transformArchive(archive, "app.zip", planYaml, "", -1)
+	if err != nil {
+		t.Fatalf("failed to transform the archive. Error: %q", err)
+	}
+	r, err := zip.NewReader(bytes.NewReader(output), int64(len(output)))
+	if err != nil {
+		t.Fatalf("failed to read the output archive. Error: %q", err)
+	}
+	for _, f := range r.File {
+		if strings.HasSuffix(f.Name, "web-deployment.yaml") {
+			return
+		}
+	}
+	t.Fatalf("expected the output to contain the deployment of the service 'web'")
+}
+
+func TestConcurrentCallsAreSerialized(t *testing.T) {
+	archive := getTestArchive(t)
+	wg := sync.WaitGroup{}
+	planYamls := make([][]byte, 3)
+	errs := make([]error, len(planYamls))
+	for i := range planYamls {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			planYamls[i], errs[i] = planArchive(archive, "app.zip", "", "myproject")
+		}(i)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("failed to plan the archive in call %d . Error: %q", i, err)
+		}
+		if !bytes.Equal(planYamls[i], planYamls[0]) {
+			t.Fatalf("expected the concurrent calls to create the same plan. Expected:\n%s\nActual:\n%s", planYamls[0], planYamls[i])
+		}
+	}
+}
@@ -0,0 +1,458 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+// memfs.js installs an in-memory filesystem as `globalThis.fs`, along with the `process.cwd/chdir` and `path.resolve`
+// functions which the Go js/wasm runtime uses for the relative paths. Load it before wasm_exec.js.
+// Only the callback based functions called by the Go syscall package are implemented.
+// The standard output and error are written to the console.
+
+"use strict";
+
+(() => {
+	const constants = { O_RDONLY: 0, O_WRONLY: 1, O_RDWR: 2, O_CREAT: 64, O_EXCL: 128, O_TRUNC: 512, O_APPEND: 1024, O_DIRECTORY: 65536 };
+	const S_IFMT = 0o170000, S_IFREG = 0o100000, S_IFDIR = 0o040000, S_IFLNK = 0o120000;
+	const MAX_SYMLINK_HOPS = 40;
+
+	const fsError = (code, syscall, path) => {
+		const err = new Error(`${code}: ${syscall} '${path}'`);
+		err.code = code;
+		return err;
+	};
+
+	let nextIno = 1;
+	const newNode = (kind, perm) => {
+		const now = Date.now();
+		return {
+			ino: nextIno++,
+			mode: kind | (perm & 0o7777),
+			nlink: 1,
+			data: kind === S_IFREG ? new Uint8Array(0) : undefined,
+			size: 0,
+			entries: kind === S_IFDIR ? new Map() : undefined,
+			target: undefined,
+			atimeMs: now,
+			mtimeMs: now,
+			ctimeMs: now,
+		};
+	};
+	const isDir = (node) => (node.mode & S_IFMT) === S_IFDIR;
+	const isLink = (node) => (node.mode & S_IFMT) === S_IFLNK;
+
+	const root = newNode(S_IFDIR, 0o755);
+	for (const dir of ["tmp", "home"]) {
+		root.entries.set(dir, newNode(S_IFDIR, 0o777));
+	}
+	let cwd = "/";
+
+	// splitPath returns the components of the absolute path, resolving the "." and ".." components
+	const splitPath = (path) => {
+		const parts = [];
+		for (const part of (path.startsWith("/") ? path : cwd + "/" + path).split("/")) {
+			if (part === "" || part === ".") {
+				continue;
+			}
+			if (part === "..") {
+				parts.pop();
+				continue;
+			}
+			parts.push(part);
+		}
+		return parts;
+	};
+
+	// lookup resolves the path, following the symbolic links in all the components except the last one, unless followLast is set.
+	// It returns the parent directory, the name of the last component and its node, which is undefined if it does not exist.
+	const lookup = (syscall, path, followLast) => {
+		let parts = splitPath(path);
+		let hops = 0;
+		for (;;) {
+			let parent = root;
+			let node = root;
+			let restarted = false;
+			for (let i = 0; i < parts.length; i++) {
+				if (!isDir(node)) {
+					throw fsError("ENOTDIR", syscall, path);
+				}
+				parent = node;
+				node = parent.entries.get(parts[i]);
+				const last = i === parts.length - 1;
+				if (node === undefined) {
+					if (last) {
+						return { parent, name: parts[i], node };
+					}
+					throw fsError("ENOENT", syscall, path);
+				}
+				if (isLink(node) && (!last || followLast)) {
+					if (++hops > MAX_SYMLINK_HOPS) {
+						throw fsError("ELOOP", syscall, path);
+					}
+					const base = node.target.startsWith("/") ? "" : "/" + parts.slice(0, i).join("/") + "/";
+					parts = splitPath(base + node.target + "/" + parts.slice(i + 1).join("/"));
+					restarted = true;
+					break;
+				}
+			}
+			if (!restarted) {
+				return { parent, name: parts[parts.length - 1], node };
+			}
+		}
+	};
+
+	const getNode = (syscall, path, followLast) => {
+		const { node } = lookup(syscall, path, followLast);
+		if (node === undefined) {
+			throw fsError("ENOENT", syscall, path);
+		}
+		return node;
+	};
+
+	const files = new Map();
+	let nextFd = 3;
+	const getFile = (syscall, fd) => {
+		const file = files.get(fd);
+		if (file === undefined) {
+			throw fsError("EBADF", syscall, fd);
+		}
+		return file;
+	};
+
+	const toStat = (node) => ({
+		dev: 0,
+		ino: node.ino,
+		mode: node.mode,
+		nlink: node.nlink,
+		uid: 0,
+		gid: 0,
+		rdev: 0,
+		size: isLink(node) ? node.target.length : node.size,
+		blksize: 4096,
+		blocks: Math.ceil(node.size / 512),
+		atimeMs: node.atimeMs,
+		mtimeMs: node.mtimeMs,
+		ctimeMs: node.ctimeMs,
+		isDirectory() { return isDir(node); },
+	});
+
+	const resize = (node, size) => {
+		if (size > node.data.length) {
+			const data = new Uint8Array(Math.max(size, node.data.length * 2));
+			data.set(node.data.subarray(0, node.size));
+			node.data = data;
+		} else if (size < node.size) {
+			node.data.fill(0, size, node.size);
+		}
+		node.size = size;
+		node.mtimeMs = Date.now();
+	};
+
+	const decoder = new TextDecoder("utf-8");
+	const outputBufs = { 1: "", 2: "" };
+	const writeOutput = (fd, buf) => {
+		outputBufs[fd] += decoder.decode(buf);
+		const nl = outputBufs[fd].lastIndexOf("\n");
+		if (nl !== -1) {
+			(fd === 1 ? console.log : console.error)(outputBufs[fd].substring(0, nl));
+			outputBufs[fd] = outputBufs[fd].substring(nl + 1);
+		}
+		return buf.length;
+	};
+
+	// call runs the function and passes its result, or the error it throws, to the callback
+	const call = (callback, fn) => {
+		let result;
+		try {
+			result = fn();
+		} catch (err) {
+			callback(err);
+			return;
+		}
+		callback(null, result);
+	};
+
+	const rename = (from, to) => {
+		const src = lookup("rename", from, false);
+		if (src.node === undefined) {
+			throw fsError("ENOENT", "rename", from);
+		}
+		const dst = lookup("rename", to, false);
+		if (src.node === dst.node) {
+			return;
+		}
+		if (isDir(src.node)) {
+			// a directory cannot be moved into itself
+			let node = root;
+			for (const part of splitPath(to).slice(0, -1)) {
+				node = node.entries.get(part);
+				if (node === src.node) {
+					throw fsError("EINVAL", "rename", to);
+				}
+				if (node === undefined || !isDir(node)) {
+					break;
+				}
+			}
+		}
+		if (dst.node !== undefined) {
+			if (isDir(dst.node) && !isDir(src.node)) {
+				throw fsError("EISDIR", "rename", to);
+			}
+			if (!isDir(dst.node) && isDir(src.node)) {
+				throw fsError("ENOTDIR", "rename", to);
+			}
+			if (isDir(dst.node) && dst.node.entries.size > 0) {
+				throw fsError("ENOTEMPTY", "rename", to);
+			}
+			dst.node.nlink--;
+		}
+		src.parent.entries.delete(src.name);
+		dst.parent.entries.set(dst.name, src.node);
+	};
+
+	globalThis.fs = {
+		constants,
+		writeSync(fd, buf) {
+			if (fd === 1 || fd === 2) {
+				return writeOutput(fd, buf);
+			}
+			const file = getFile("write", fd);
+			const pos = file.append ? file.node.size : file.pos;
+			if (pos + buf.length > file.node.size) {
+				resize(file.node, pos + buf.length);
+			}
+			file.node.data.set(buf, pos);
+			file.node.mtimeMs = Date.now();
+			file.pos = pos + buf.length;
+			return buf.length;
+		},
+		open(path, flags, mode, callback) {
+			call(callback, () => {
+				const exclusive = (flags & constants.O_CREAT) && (flags & constants.O_EXCL);
+				const { parent, name, node: existing } = lookup("open", path, !exclusive);
+				let node = existing;
+				if (node === undefined) {
+					if (!(flags & constants.O_CREAT)) {
+						throw fsError("ENOENT", "open", path);
+					}
+					node = newNode(S_IFREG, mode);
+					parent.entries.set(name, node);
+				} else if (exclusive) {
+					throw fsError("EEXIST", "open", path);
+				}
+				const writable = (flags & (constants.O_WRONLY | constants.O_RDWR)) !== 0;
+				if (isDir(node) && writable) {
+					throw fsError("EISDIR", "open", path);
+				}
+				if (!isDir(node) && (flags & constants.O_DIRECTORY)) {
+					throw fsError("ENOTDIR", "open", path);
+				}
+				if (writable && (flags & constants.O_TRUNC)) {
+					resize(node, 0);
+				}
+				const fd = nextFd++;
+				files.set(fd, { node, pos: 0, append: (flags & constants.O_APPEND) !== 0 });
+				return fd;
+			});
+		},
+		close(fd, callback) {
+			call(callback, () => {
+				getFile("close", fd);
+				files.delete(fd);
+			});
+		},
+		read(fd, buffer, offset, length, position, callback) {
+			call(callback, () => {
+				const file = getFile("read", fd);
+				if (isDir(file.node)) {
+					throw fsError("EISDIR", "read", fd);
+				}
+				const pos = position === null || position === undefined ? file.pos : position;
+				const n = Math.max(0, Math.min(length, file.node.size - pos));
+				buffer.set(file.node.data.subarray(pos, pos + n), offset);
+				if (position === null || position === undefined) {
+					file.pos = pos + n;
+				}
+				file.node.atimeMs = Date.now();
+				return n;
+			});
+		},
+		write(fd, buffer, offset, length, position, callback) {
+			call(callback, () => {
+				const buf = buffer.subarray(offset, offset + length);
+				if (position === null || position === undefined) {
+					return this.writeSync(fd, buf);
+				}
+				const file = getFile("write", fd);
+				if (position + length > file.node.size) {
+					resize(file.node, position + length);
+				}
+				file.node.data.set(buf, position);
+				file.node.mtimeMs = Date.now();
+				return length;
+			});
+		},
+		fsync(fd, callback) { callback(null); },
+		stat(path, callback) { call(callback, () => toStat(getNode("stat", path, true))); },
+		lstat(path, callback) { call(callback, () => toStat(getNode("lstat", path, false))); },
+		fstat(fd, callback) { call(callback, () => toStat(getFile("fstat", fd).node)); },
+		mkdir(path, perm, callback) {
+			call(callback, () => {
+				const { parent, name, node } = lookup("mkdir", path, false);
+				if (node !== undefined || name === undefined) {
+					throw fsError("EEXIST", "mkdir", path);
+				}
+				parent.entries.set(name, newNode(S_IFDIR, perm));
+				parent.mtimeMs = Date.now();
+			});
+		},
+		readdir(path, callback) {
+			call(callback, () => {
+				const node = getNode("readdir", path, true);
+				if (!isDir(node)) {
+					throw fsError("ENOTDIR", "readdir", path);
+				}
+				return Array.from(node.entries.keys());
+			});
+		},
+		unlink(path, callback) {
+			call(callback, () => {
+				const { parent, name, node } = lookup("unlink", path, false);
+				if (node === undefined) {
+					throw fsError("ENOENT", "unlink", path);
+				}
+				if (isDir(node)) {
+					throw fsError("EISDIR", "unlink", path);
+				}
+				parent.entries.delete(name);
+				node.nlink--;
+			});
+		},
+		rmdir(path, callback) {
+			call(callback, () => {
+				const { parent, name, node } = lookup("rmdir", path, false);
+				if (node === undefined) {
+					throw fsError("ENOENT", "rmdir", path);
+				}
+				if (node === root) {
+					throw fsError("EBUSY", "rmdir", path);
+				}
+				if (!isDir(node)) {
+					throw fsError("ENOTDIR", "rmdir", path);
+				}
+				if (node.entries.size > 0) {
+					throw fsError("ENOTEMPTY", "rmdir", path);
+				}
+				parent.entries.delete(name);
+			});
+		},
+		rename(from, to, callback) { call(callback, () => rename(from, to)); },
+		symlink(target, path, callback) {
+			call(callback, () => {
+				const { parent, name, node } = lookup("symlink", path, false);
+				if (node !== undefined) {
+					throw fsError("EEXIST", "symlink", path);
+				}
+				const link = newNode(S_IFLNK, 0o777);
+				link.target = target;
+				parent.entries.set(name, link);
+			});
+		},
+		readlink(path, callback) {
+			call(callback, () => {
+				const node = getNode("readlink", path, false);
+				if (!isLink(node)) {
+					throw fsError("EINVAL", "readlink", path);
+				}
+				return node.target;
+			});
+		},
+		link(existingPath, path, callback) {
+			call(callback, () => {
+				const existing = getNode("link", existingPath, false);
+				if (isDir(existing)) {
+					throw fsError("EPERM", "link", existingPath);
+				}
+				const { parent, name, node } = lookup("link", path, false);
+				if (node !== undefined) {
+					throw fsError("EEXIST", "link", path);
+				}
+				parent.entries.set(name, existing);
+				existing.nlink++;
+			});
+		},
+		chmod(path, mode, callback) {
+			call(callback, () => {
+				const node = getNode("chmod", path, true);
+				node.mode = (node.mode & S_IFMT) | (mode & 0o7777);
+			});
+		},
+		fchmod(fd, mode, callback) {
+			call(callback, () => {
+				const node = getFile("fchmod", fd).node;
+				node.mode = (node.mode & S_IFMT) | (mode & 0o7777);
+			});
+		},
+		chown(path, uid, gid, callback) { call(callback, () => { getNode("chown", path, true); }); },
+		fchown(fd, uid, gid, callback) { call(callback, () => { getFile("fchown", fd); }); },
+		lchown(path, uid, gid, callback) { call(callback, () => { getNode("lchown", path, false); }); },
+		utimes(path, atime, mtime, callback) {
+			call(callback, () => {
+				const node = getNode("utimes", path, true);
+				node.atimeMs = atime * 1000;
+				node.mtimeMs = mtime * 1000;
+			});
+		},
+		truncate(path, length, callback) {
+			call(callback, () => {
+				const node = getNode("truncate", path, true);
+				if (isDir(node)) {
+					throw fsError("EISDIR", "truncate", path);
+				}
+				resize(node, length);
+			});
+		},
+		ftruncate(fd, length, callback) { call(callback, () => resize(getFile("ftruncate", fd).node, length)); },
+	};
+
+	globalThis.path = {
+		resolve(...pathSegments) {
+			return "/" + splitPath(pathSegments.join("/")).join("/");
+		},
+	};
+
+	const cwdFunctions = {
+		cwd() { return cwd; },
+		chdir(path) {
+			if (!isDir(getNode("chdir", path, true))) {
+				throw fsError("ENOTDIR", "chdir", path);
+			}
+			cwd = globalThis.path.resolve(path);
+		},
+	};
+	if (globalThis.process) {
+		Object.assign(globalThis.process, cwdFunctions);
+	} else {
+		globalThis.process = {
+			getuid() { return -1; },
+			getgid() { return -1; },
+			geteuid() { return -1; },
+			getegid() { return -1; },
+			getgroups() { return []; },
+			pid: -1,
+			ppid: -1,
+			umask() { return 0o022; },
+			...cwdFunctions,
+		};
+	}
+})();
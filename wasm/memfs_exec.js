@@ -0,0 +1,58 @@
+/*
+ *  Copyright IBM Corporation 2023
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+// memfs_exec.js runs a js/wasm binary in Node.js on the in-memory filesystem of memfs.js, like it runs in the browser.
+// It is used to run the tests of the browser build:
+//
+//	GOOS=js GOARCH=wasm go test -exec "node $PWD/wasm/memfs_exec.js" ./wasm
+
+"use strict";
+
+if (process.argv.length < 3) {
+	console.error("usage: memfs_exec.js [wasm binary] [arguments]");
+	process.exit(1);
+}
+
+const childProcess = require("child_process");
+const nodeFS = require("fs");
+const nodePath = require("path");
+
+const wasm = nodeFS.readFileSync(process.argv[2]);
+const goRoot = process.env.GOROOT || childProcess.execFileSync("go", ["env", "GOROOT"]).toString().trim();
+const wasmExecPath = [nodePath.join(goRoot, "lib", "wasm", "wasm_exec.js"), nodePath.join(goRoot, "misc", "wasm", "wasm_exec.js")].find((p) => nodeFS.existsSync(p));
+
+globalThis.performance ??= require("perf_hooks").performance;
+globalThis.crypto ??= require("crypto");
+require("./memfs");
+require(wasmExecPath);
+
+const go = new Go();
+go.argv = process.argv.slice(2);
+go.env = { TMPDIR: "/tmp", HOME: "/home" };
+go.exit = process.exit;
+WebAssembly.instantiate(wasm, go.importObject).then((result) => {
+	process.on("exit", (code) => { // Node.js exits if no event handler is pending
+		if (code === 0 && !go.exited) {
+			// deadlock, make Go print error and stack traces
+			go._pendingEvent = { id: 0 };
+			go._resume();
+		}
+	});
+	return go.run(result.instance);
+}).catch((err) => {
+	console.error(err);
+	process.exit(1);
+});